@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,13 +13,13 @@ import (
 
 	_ "event-ticketing-backend/docs"
 	"event-ticketing-backend/internal/database"
-	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/internal/redis"
 	"event-ticketing-backend/internal/routes"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/internal/validators"
 	"event-ticketing-backend/internal/workers"
 	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
 )
 
 // @title Event Ticketing API
@@ -40,15 +41,28 @@ import (
 // @in header
 // @name Authorization
 // @description JWT token authentication. Use the 'Bearer' prefix followed by a space and the access token. Example: Bearer eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+// @securityDefinitions.apikey OrganizationAPIKey
+// @in header
+// @name X-API-Key
+// @description Organization API key, accepted by organization endpoints within the key's scopes. Example: etk_3f9a1c0d...
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	redis.SetNamespace(cfg.Redis.KeyPrefix)
+
+	skipMigrations := flag.Bool("skip-migrations", cfg.Database.SkipMigrations, "Start without running database migrations (overrides DB_SKIP_MIGRATIONS)")
+	flag.Parse()
 
 	log.Printf("Starting %s v%s in %s mode", cfg.App.Name, cfg.App.Version, cfg.App.Env)
 
+	// A missing or mismatched signing key would fail every login, so refuse to start instead
+	if err := utils.NewJWTService(&cfg.JWT).Err(); err != nil {
+		log.Fatalf("Invalid JWT signing keys: %v", err)
+	}
+
 	// Initialize validators
 	validators.Initialize()
 
@@ -66,28 +80,35 @@ func main() {
 		defer redis.Close()
 	}
 
-	// Run migrations
-	log.Println("Running database migrations...")
-
-	// Migrate tables in the correct order (tables without foreign keys first)
-	if err := database.Migrate(
-		// First migrate tables that don't depend on others
-		&models.Organization{},
-		&models.Role{},
-		&models.Permission{},
-		&models.Event{},
-		// Then migrate tables with foreign keys
-		&models.User{},
-		&models.Token{},
-	); err != nil {
-		log.Fatalf("Failed to migrate database: %v", err)
+	// Run migrations, unless they run as a separate deploy job
+	if *skipMigrations {
+		log.Println("Skipping database migrations")
+	} else {
+		log.Println("Running database migrations...")
+		if err := database.Migrate(database.Models()...); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Println("Database migrations completed")
+	}
+
+	// Refuse to serve on a schema this build cannot use
+	schemaReport, err := database.CheckSchema()
+	if schemaReport != nil {
+		for _, drift := range schemaReport.Drift {
+			log.Printf("Warning: schema drift: %s", drift)
+		}
 	}
-	log.Println("Database migrations completed")
+	if err != nil {
+		log.Fatalf("Schema check failed: %v", err)
+	}
+	log.Printf("Database schema at version %d (build requires %d)", schemaReport.Version, database.SchemaVersion)
 
 	// Initialize background workers
 	emailService := services.NewEmailService(cfg)
 	emailWorker := workers.NewEmailWorker(cfg, emailService)
-	workerManager := workers.NewWorkerManager(emailWorker)
+	integrationWorker := workers.NewIntegrationWorker(cfg)
+	ticketingWorker := workers.NewTicketingWorker(cfg)
+	workerManager := workers.NewWorkerManager(emailWorker, integrationWorker, ticketingWorker)
 
 	// Start background workers
 	log.Println("Starting background workers...")
@@ -105,6 +126,12 @@ func main() {
 		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
+	// Serve HTTP/2 without TLS (h2c) next to HTTP/1.1, for clients and load balancers that speak
+	// it to the API directly. TLS is terminated in front of the API.
+	srv.Protocols = new(http.Protocols)
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server listening on %s:%s", cfg.App.Host, cfg.App.Port)
@@ -0,0 +1,27 @@
+// Command openapi writes the OpenAPI 3 document built from the generated Swagger docs to a file,
+// for client SDK generation. Run it after regenerating the Swagger docs.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	_ "event-ticketing-backend/docs"
+	"event-ticketing-backend/internal/openapi"
+)
+
+func main() {
+	output := flag.String("o", "docs/openapi.json", "Output file")
+	flag.Parse()
+
+	spec, err := openapi.Spec()
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI document: %v", err)
+	}
+
+	if err := os.WriteFile(*output, append(spec, '\n'), 0o644); err != nil {
+		log.Fatalf("Failed to write OpenAPI document: %v", err)
+	}
+	log.Printf("OpenAPI document written to %s", *output)
+}
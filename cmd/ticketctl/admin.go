@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+)
+
+// runAdminCreate creates an administrator account. The password is read from
+// TICKETCTL_ADMIN_PASSWORD or standard input so it stays out of the shell history.
+func runAdminCreate(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("admin create", flag.ExitOnError)
+	email := fs.String("email", "", "Email address of the administrator (required)")
+	firstName := fs.String("first-name", "Admin", "First name")
+	lastName := fs.String("last-name", "User", "Last name")
+	fs.Parse(args)
+
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	password := os.Getenv("TICKETCTL_ADMIN_PASSWORD")
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("failed to read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if len(password) < 8 {
+		return errors.New("password must be at least 8 characters")
+	}
+
+	if err := connectDatabase(cfg); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	user, err := services.NewAuthService(cfg).CreateAdmin(context.Background(), &models.CreateUserRequest{
+		Email:     *email,
+		Password:  password,
+		FirstName: *firstName,
+		LastName:  *lastName,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Administrator ready: %s (%s)\n", user.Email, user.ID)
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+)
+
+// runEmailsList prints the dead-letter email jobs
+func runEmailsList(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("emails list", flag.ExitOnError)
+	limit := fs.Int("limit", 50, "Maximum number of jobs per queue")
+	fs.Parse(args)
+
+	deadLetters := services.NewDeadLetterService(cfg)
+	defer deadLetters.Close()
+
+	tasks, err := deadLetters.ListDeadLetterEmails(*limit)
+	if err != nil {
+		return err
+	}
+	if len(tasks) == 0 {
+		fmt.Println("No dead-letter emails")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "QUEUE\tID\tRETRIED\tFAILED AT\tLAST ERROR")
+	for _, task := range tasks {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", task.Queue, task.ID, task.Retried, task.LastFailedAt.Format(time.RFC3339), task.LastErr)
+	}
+	return w.Flush()
+}
+
+// runEmailsRequeue moves one or all dead-letter email jobs back to their queues
+func runEmailsRequeue(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("emails requeue", flag.ExitOnError)
+	queue := fs.String("queue", "", "Queue of the job to requeue, as shown by 'emails list'")
+	id := fs.String("id", "", "ID of the job to requeue")
+	all := fs.Bool("all", false, "Requeue every dead-letter email")
+	fs.Parse(args)
+
+	deadLetters := services.NewDeadLetterService(cfg)
+	defer deadLetters.Close()
+
+	switch {
+	case *all:
+		n, err := deadLetters.RequeueAllDeadLetterEmails()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Requeued %d emails\n", n)
+	case *queue != "" && *id != "":
+		if err := deadLetters.RequeueDeadLetterEmail(*queue, *id); err != nil {
+			return err
+		}
+		fmt.Printf("Requeued email %s\n", *id)
+	default:
+		return errors.New("pass -all, or -queue and -id")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+)
+
+// runHealth prints the health of a running API, or with -direct checks the database and Redis
+// from this machine. It fails when anything is unhealthy, so it can back scripted checks.
+func runHealth(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ExitOnError)
+	apiURL := fs.String("api", cfg.Security.PublicURL, "Base URL of the API")
+	direct := fs.Bool("direct", false, "Check the database and Redis directly instead of asking the API")
+	fs.Parse(args)
+
+	var status *services.SimpleHealthStatus
+	if *direct {
+		status = checkDirect(cfg)
+	} else {
+		var err error
+		if status, err = checkAPI(*apiURL); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Status: %s\n", status.Status)
+	if status.Uptime != "" {
+		fmt.Printf("Uptime: %s\n", status.Uptime)
+	}
+	names := make([]string, 0, len(status.Services))
+	for name := range status.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-10s %s\n", name, status.Services[name])
+	}
+
+	if status.Status != "healthy" {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// checkAPI fetches the health endpoint of a running API
+func checkAPI(apiURL string) (*services.SimpleHealthStatus, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Get(strings.TrimRight(apiURL, "/") + "/health")
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var status services.SimpleHealthStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("unexpected health response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	return &status, nil
+}
+
+// checkDirect connects to the database and Redis and reports their health
+func checkDirect(cfg *config.Config) *services.SimpleHealthStatus {
+	status := &services.SimpleHealthStatus{Status: "healthy", Services: map[string]string{}}
+
+	if err := connectDatabase(cfg); err != nil {
+		status.Status = "unhealthy"
+		status.Services["database"] = err.Error()
+	} else {
+		defer database.Close()
+		status.Services["database"] = "up"
+	}
+
+	if err := redis.Connect(cfg); err != nil {
+		status.Status = "unhealthy"
+		status.Services["redis"] = err.Error()
+	} else {
+		defer redis.Close()
+		status.Services["redis"] = "up"
+	}
+
+	return status
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+)
+
+// runJWTRotate prints the settings of a new signing key and of the retired keys to deploy with
+// it. Tokens signed with a retired key keep working until they expire; -revoke-sessions also
+// revokes every refresh token, for when the old key leaked.
+//
+// With HS256 a new secret is generated. With RS256 or EdDSA a new private key is written to
+// -key-out, and the public keys of the current and retired key pairs to -previous-keys-out, which
+// becomes JWT_PREVIOUS_KEYS_FILE. Switching from HS256 retires the current secret to
+// JWT_PREVIOUS_SECRETS.
+func runJWTRotate(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("jwt rotate", flag.ExitOnError)
+	algorithm := fs.String("algorithm", cfg.JWT.Algorithm, "Algorithm of the new key: HS256, RS256 or EdDSA (defaults to JWT_ALGORITHM)")
+	keyOut := fs.String("key-out", "", "File the new private key is written to (RS256 and EdDSA)")
+	previousKeysOut := fs.String("previous-keys-out", "", "File the retired public keys are written to (RS256 and EdDSA)")
+	revokeSessions := fs.Bool("revoke-sessions", false, "Revoke all refresh tokens so every user signs in again")
+	fs.Parse(args)
+
+	if *revokeSessions {
+		if err := connectDatabase(cfg); err != nil {
+			return err
+		}
+		defer database.Close()
+		revoked, err := services.NewAuthService(cfg).RevokeAllSessions(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Revoked %d refresh tokens\n", revoked)
+	}
+
+	switch *algorithm {
+	case "", utils.JWTAlgorithmHS256:
+		if cfg.JWT.Algorithm == utils.JWTAlgorithmRS256 || cfg.JWT.Algorithm == utils.JWTAlgorithmEdDSA {
+			return errors.New("switching from a key pair back to HS256 would reject every issued token; rotate the key pair instead")
+		}
+		return rotateJWTSecret(cfg)
+	case utils.JWTAlgorithmRS256, utils.JWTAlgorithmEdDSA:
+		return rotateJWTKeyPair(cfg, *algorithm, *keyOut, *previousKeysOut)
+	default:
+		return fmt.Errorf("unsupported algorithm: %s", *algorithm)
+	}
+}
+
+// rotateJWTSecret prints a new signing secret and the retired secrets to deploy with it
+func rotateJWTSecret(cfg *config.Config) error {
+	key := make([]byte, 48)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate secret: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(key)
+
+	fmt.Fprintf(os.Stderr, "New key ID %s. Deploy these settings to every API instance, then drop retired\n", utils.JWTKeyID(secret))
+	fmt.Fprintf(os.Stderr, "secrets from JWT_PREVIOUS_SECRETS once %s (the refresh token lifetime) has passed.\n", cfg.JWT.RefreshTokenTTL)
+	fmt.Printf("JWT_SECRET=%s\n", secret)
+	fmt.Printf("JWT_PREVIOUS_SECRETS=%s\n", strings.Join(retiredJWTSecrets(cfg), ","))
+	return nil
+}
+
+// rotateJWTKeyPair writes a new private key and the retired public keys, and prints the settings
+// pointing at them
+func rotateJWTKeyPair(cfg *config.Config, algorithm, keyOut, previousKeysOut string) error {
+	if keyOut == "" || previousKeysOut == "" {
+		return errors.New("-key-out and -previous-keys-out are required for key pairs")
+	}
+
+	var signer crypto.Signer
+	var err error
+	if algorithm == utils.JWTAlgorithmRS256 {
+		signer, err = rsa.GenerateKey(rand.Reader, 3072)
+	} else {
+		_, signer, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	keyID, err := utils.JWTPublicKeyID(signer.Public())
+	if err != nil {
+		return err
+	}
+
+	// The current key pair is retired first; older ones stay until they are removed by hand
+	var retired []crypto.PublicKey
+	if cfg.JWT.Algorithm == utils.JWTAlgorithmRS256 || cfg.JWT.Algorithm == utils.JWTAlgorithmEdDSA {
+		current, err := utils.LoadPEMPrivateKey(cfg.JWT.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load current signing key: %w", err)
+		}
+		retired = append(retired, current.Public())
+	}
+	if cfg.JWT.PreviousKeysFile != "" {
+		previous, err := utils.LoadPEMPublicKeys(cfg.JWT.PreviousKeysFile)
+		if err != nil {
+			return fmt.Errorf("failed to load retired keys: %w", err)
+		}
+		retired = append(retired, previous...)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return fmt.Errorf("failed to encode key: %w", err)
+	}
+	if err := os.WriteFile(keyOut, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600); err != nil {
+		return fmt.Errorf("failed to write key: %w", err)
+	}
+
+	var previousPEM bytes.Buffer
+	for _, publicKey := range retired {
+		publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+		if err != nil {
+			return fmt.Errorf("failed to encode retired key: %w", err)
+		}
+		pem.Encode(&previousPEM, &pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	}
+	if err := os.WriteFile(previousKeysOut, previousPEM.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write retired keys: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "New key ID %s. Deploy both files and these settings to every API instance, then drop\n", keyID)
+	fmt.Fprintf(os.Stderr, "retired keys and secrets once %s (the refresh token lifetime) has passed.\n", cfg.JWT.RefreshTokenTTL)
+	fmt.Printf("JWT_ALGORITHM=%s\n", algorithm)
+	fmt.Printf("JWT_PRIVATE_KEY_FILE=%s\n", keyOut)
+	fmt.Printf("JWT_PREVIOUS_KEYS_FILE=%s\n", previousKeysOut)
+	if cfg.JWT.Algorithm == "" || cfg.JWT.Algorithm == utils.JWTAlgorithmHS256 {
+		fmt.Printf("JWT_PREVIOUS_SECRETS=%s\n", strings.Join(retiredJWTSecrets(cfg), ","))
+	} else if cfg.JWT.PreviousSecrets != "" {
+		fmt.Printf("JWT_PREVIOUS_SECRETS=%s\n", cfg.JWT.PreviousSecrets)
+	}
+	return nil
+}
+
+// retiredJWTSecrets returns the current secret followed by the secrets retired before it
+func retiredJWTSecrets(cfg *config.Config) []string {
+	previous := []string{cfg.JWT.Secret}
+	for _, old := range strings.Split(cfg.JWT.PreviousSecrets, ",") {
+		if old = strings.TrimSpace(old); old != "" && old != cfg.JWT.Secret {
+			previous = append(previous, old)
+		}
+	}
+	return previous
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/pkg/config"
+)
+
+// runMigrate applies the schema migrations the API runs at startup
+func runMigrate(cfg *config.Config, args []string) error {
+	if err := connectDatabase(cfg); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if err := database.Migrate(database.Models()...); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+	fmt.Println("Database migrations completed")
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+)
+
+// runReconcile queues a reconciliation of the given period, by default the previous UTC day
+func runReconcile(cfg *config.Config, args []string) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	from := fs.String("from", today.AddDate(0, 0, -1).Format("2006-01-02"), "Start of the period (YYYY-MM-DD or RFC 3339)")
+	to := fs.String("to", today.Format("2006-01-02"), "End of the period, exclusive (YYYY-MM-DD or RFC 3339)")
+	fs.Parse(args)
+
+	start, err := parseTime(*from)
+	if err != nil {
+		return fmt.Errorf("invalid -from: %w", err)
+	}
+	end, err := parseTime(*to)
+	if err != nil {
+		return fmt.Errorf("invalid -to: %w", err)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("-to must be after -from")
+	}
+
+	if err := connectDatabase(cfg); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	req := &models.RunReconciliationRequest{PeriodStart: start, PeriodEnd: end}
+	if err := services.NewReconciliationService(cfg).QueueReconciliation(req); err != nil {
+		return err
+	}
+	fmt.Printf("Reconciliation of %s to %s queued\n", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	return nil
+}
+
+// parseTime accepts a date or an RFC 3339 timestamp
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/pkg/config"
+)
+
+// runSchemaCheck reports the schema version and drift, failing when this build cannot run on the
+// database. Deploy pipelines can run it before switching traffic to a new release.
+func runSchemaCheck(cfg *config.Config, args []string) error {
+	if err := connectDatabase(cfg); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	report, err := database.CheckSchema()
+	if report != nil {
+		fmt.Printf("Database schema version: %d (supports builds from %d)\n", report.Version, report.MinCompatibleVersion)
+		fmt.Printf("Build schema version:    %d\n", database.SchemaVersion)
+		for _, drift := range report.Drift {
+			fmt.Printf("Drift: %s\n", drift)
+		}
+	}
+	return err
+}
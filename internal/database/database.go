@@ -1,8 +1,10 @@
 package database
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/pkg/config"
@@ -21,9 +23,17 @@ type Token = models.Token
 
 var DB *gorm.DB
 
+// appVersion is the application version recorded with each migration run
+var appVersion string
+
 func Connect(cfg *config.Config) error {
 	dsn := cfg.GetDSN()
 
+	// Register field-level encryption before any model is parsed
+	if err := setupEncryption(cfg); err != nil {
+		return err
+	}
+
 	// Configure GORM logger
 	gormConfig := &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
@@ -39,6 +49,16 @@ func Connect(cfg *config.Config) error {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
 
+	// Scope organization-owned tables to the organization in the statement context
+	if err := registerTenancyCallbacks(db); err != nil {
+		return fmt.Errorf("failed to register tenancy guard: %w", err)
+	}
+
+	// Count statements per request for the request log
+	if err := registerQueryCountCallbacks(db); err != nil {
+		return fmt.Errorf("failed to register query counter: %w", err)
+	}
+
 	// Get underlying SQL DB
 	sqlDB, err := db.DB()
 	if err != nil {
@@ -50,6 +70,7 @@ func Connect(cfg *config.Config) error {
 	sqlDB.SetMaxOpenConns(100)
 
 	DB = db
+	appVersion = cfg.App.Version
 	log.Println("Database connected successfully")
 	return nil
 }
@@ -83,7 +104,42 @@ func Migrate(models ...interface{}) error {
 	}
 
 	// Seed default roles and permissions
-	return SeedRoles(DB)
+	if err := SeedRoles(DB); err != nil {
+		return err
+	}
+
+	// Give users from before multi-organization membership their memberships
+	if err := backfillOrganizationMembers(DB); err != nil {
+		return err
+	}
+
+	// Assign events from before organization ownership to their organizer's organization
+	if err := backfillEventOrganizations(DB); err != nil {
+		return err
+	}
+
+	// Move events from before the event lifecycle to its statuses
+	if err := backfillEventStatuses(DB); err != nil {
+		return err
+	}
+
+	// Drop target responses the hook delivery log no longer keeps
+	if err := clearHookResponseBodies(DB); err != nil {
+		return err
+	}
+
+	// Drop provider responses marketing integrations used to keep as their last error
+	if err := clearMarketingProviderErrors(DB); err != nil {
+		return err
+	}
+
+	// Drop payment keys tenants no longer hold
+	if err := clearTenantPaymentKeys(DB); err != nil {
+		return err
+	}
+
+	// Record the schema version so instances started without migrations can check compatibility
+	return recordSchemaVersion(DB)
 }
 
 func IsHealthy() bool {
@@ -91,7 +147,11 @@ func IsHealthy() bool {
 	if err != nil {
 		return false
 	}
-	return sqlDB.Ping() == nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return sqlDB.PingContext(ctx) == nil
 }
 
 func GetDB() *gorm.DB {
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"gorm.io/gorm/schema"
+)
+
+// Encryptor encrypts columns tagged with `gorm:"serializer:encrypted"`. It is nil when no keys
+// are configured, in which case those columns are stored in plain text.
+var Encryptor *utils.FieldEncryptor
+
+// setupEncryption loads the field encryption keyring and registers the encrypted serializer.
+// Keys are required in production.
+func setupEncryption(cfg *config.Config) error {
+	schema.RegisterSerializer("encrypted", EncryptedSerializer{})
+
+	if cfg.Security.EncryptionKeys == "" {
+		if cfg.App.Env == "production" {
+			return fmt.Errorf("ENCRYPTION_KEYS must be set in production")
+		}
+		log.Println("Warning: ENCRYPTION_KEYS not set, personal data will be stored unencrypted")
+		return nil
+	}
+
+	encryptor, err := utils.NewFieldEncryptor(cfg.Security.EncryptionKeys, cfg.Security.EncryptionActiveKey)
+	if err != nil {
+		return fmt.Errorf("failed to load encryption keys: %w", err)
+	}
+	Encryptor = encryptor
+	return nil
+}
+
+// EncryptedSerializer is a GORM serializer for string fields that encrypts values on write and
+// decrypts them on read
+type EncryptedSerializer struct{}
+
+// Scan decrypts a column value into the field
+func (EncryptedSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var stored string
+	switch v := dbValue.(type) {
+	case nil:
+	case string:
+		stored = v
+	case []byte:
+		stored = string(v)
+	default:
+		return fmt.Errorf("unsupported encrypted column value %T", dbValue)
+	}
+
+	value := stored
+	if utils.IsEncryptedValue(stored) {
+		if Encryptor == nil {
+			return fmt.Errorf("cannot decrypt %s: no encryption keys configured", field.Name)
+		}
+		decrypted, err := Encryptor.Decrypt(stored)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field.Name, err)
+		}
+		value = decrypted
+	}
+
+	return field.Set(ctx, dst, value)
+}
+
+// Value encrypts the field value for storage
+func (EncryptedSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	value, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("encrypted serializer only supports string fields, got %T", fieldValue)
+	}
+	if Encryptor == nil {
+		return value, nil
+	}
+	return Encryptor.Encrypt(value)
+}
@@ -0,0 +1,31 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// backfillEventOrganizations assigns events created before events belonged to organizations to the
+// first organization their organizer created. Events of organizers without one are left alone,
+// and assigned events are never reassigned, so the backfill is idempotent.
+func backfillEventOrganizations(db *gorm.DB) error {
+	result := db.Exec(`
+		UPDATE events SET organization_id = (
+			SELECT organizations.id FROM organizations
+			WHERE organizations.organizer_id = events.organizer_id AND organizations.deleted_at IS NULL
+			ORDER BY organizations.created_at LIMIT 1
+		)
+		WHERE events.organization_id IS NULL AND events.organizer_id IS NOT NULL AND EXISTS (
+			SELECT 1 FROM organizations
+			WHERE organizations.organizer_id = events.organizer_id AND organizations.deleted_at IS NULL
+		)`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill event organizations: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Assigned %d events to their organizer's organization", result.RowsAffected)
+	}
+	return nil
+}
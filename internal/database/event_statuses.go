@@ -0,0 +1,24 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// backfillEventStatuses moves events whose status predates the event lifecycle, "active" or any
+// free text, to published, or to completed when they have ended. Drafts and cancelled events keep
+// their status, so the backfill is idempotent.
+func backfillEventStatuses(db *gorm.DB) error {
+	result := db.Exec(`
+		UPDATE events SET status = CASE WHEN end_date <= NOW() THEN 'completed' ELSE 'published' END
+		WHERE status NOT IN ('draft', 'published', 'paused', 'cancelled', 'completed')`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to backfill event statuses: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Moved %d events to the published or completed status", result.RowsAffected)
+	}
+	return nil
+}
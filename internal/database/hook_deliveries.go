@@ -0,0 +1,25 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// clearHookResponseBodies removes the target responses the hook delivery log used to keep, which
+// could hold whatever an organizer's target URL returned. The column is left for older instances
+// still writing to it.
+func clearHookResponseBodies(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("hook_delivery_logs", "response_body") {
+		return nil
+	}
+	result := db.Exec(`UPDATE hook_delivery_logs SET response_body = NULL WHERE response_body IS NOT NULL`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear hook response bodies: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Cleared the response bodies of %d hook deliveries", result.RowsAffected)
+	}
+	return nil
+}
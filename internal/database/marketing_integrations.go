@@ -0,0 +1,25 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// clearMarketingProviderErrors removes the last errors of marketing integrations that quote a
+// provider's response, which could hold whatever the organizer's API key pointed at. The next
+// sync records its own error.
+func clearMarketingProviderErrors(db *gorm.DB) error {
+	if !db.Migrator().HasTable("marketing_integrations") {
+		return nil
+	}
+	result := db.Exec(`UPDATE marketing_integrations SET last_error = '' WHERE last_error LIKE '%responded with status %: %'`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear marketing provider errors: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Cleared the provider responses of %d marketing integrations", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package database
+
+import "event-ticketing-backend/internal/models"
+
+// Models returns every model managed by migrations, in migration order: tables without
+// foreign keys first, then tables that reference them
+func Models() []interface{} {
+	return []interface{}{
+		// First migrate tables that don't depend on others
+		&models.Organization{},
+		&models.Role{},
+		&models.Permission{},
+		&models.Event{},
+		// Then migrate tables with foreign keys
+		&models.User{},
+		&models.Token{},
+		&models.Order{},
+		&models.Ticket{},
+		&models.HookSubscription{},
+		&models.MarketingIntegration{},
+		&models.AccountingExport{},
+		&models.ChatWebhook{},
+		&models.InventoryAlert{},
+		&models.PricingRule{},
+		&models.TicketAllocation{},
+		&models.Payment{},
+		&models.Installment{},
+		&models.ReconciliationReport{},
+		&models.ReconciliationDiscrepancy{},
+		&models.AuditLog{},
+		&models.OrderAdjustment{},
+		&models.UserDevice{},
+		&models.WarehouseExport{},
+		&models.OrderInsurance{},
+		&models.FranchiseEvent{},
+		&models.Tenant{},
+		&models.CheckoutSession{},
+		&models.UsernameChange{},
+		&models.UserAvatar{},
+		&models.EventTemplate{},
+		&models.EventVersion{},
+		&models.NotificationLog{},
+		&models.CheckIn{},
+		&models.ScanAttempt{},
+		&models.TicketRevocation{},
+		&models.BulkJob{},
+		&models.BulkJobItem{},
+		&models.UserIdentity{},
+		&models.Presale{},
+		&models.PresaleAccessCode{},
+		&models.PresaleAllowlistEntry{},
+		&models.EventArchive{},
+		&models.ArchivedRecord{},
+		&models.ScannerDevice{},
+		&models.APIKey{},
+		&models.Venue{},
+		&models.Section{},
+		&models.Row{},
+		&models.Seat{},
+		&models.EventSeat{},
+		&models.OrganizationRole{},
+		&models.Refund{},
+		&models.RefundItem{},
+		&models.PromoCode{},
+		&models.PromoCodeRedemption{},
+		&models.AdminElevation{},
+		&models.OrganizationInvitation{},
+		&models.OrganizationMember{},
+		&models.Newsletter{},
+		&models.NewsletterRecipient{},
+		&models.NewsletterClick{},
+		&models.InternalNote{},
+		&models.InternalTag{},
+		&models.AttendeeSegment{},
+		&models.ContactMerge{},
+		&models.HookDeliveryLog{},
+		&models.EventBundle{},
+		&models.BundlePurchase{},
+	}
+}
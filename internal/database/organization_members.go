@@ -0,0 +1,44 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// backfillOrganizationMembers creates the memberships of organizations set up before users could
+// belong to several of them: organizers become organizer members of the organizations they
+// created, and users with an organization_id join it with the staff or manager role they hold.
+// Existing memberships are left alone, so the backfill is idempotent and runs on every migration.
+func backfillOrganizationMembers(db *gorm.DB) error {
+	organizers := db.Exec(`
+		INSERT INTO organization_members (id, organization_id, user_id, role_name, active, created_at, updated_at)
+		SELECT uuid_generate_v4(), organizations.id, organizations.organizer_id, 'organizer', true, NOW(), NOW()
+		FROM organizations
+		WHERE organizations.deleted_at IS NULL AND organizations.organizer_id IS NOT NULL
+		ON CONFLICT (organization_id, user_id) DO NOTHING`)
+	if organizers.Error != nil {
+		return fmt.Errorf("failed to backfill organizer memberships: %w", organizers.Error)
+	}
+
+	staff := db.Exec(`
+		INSERT INTO organization_members (id, organization_id, user_id, role_name, active, created_at, updated_at)
+		SELECT uuid_generate_v4(), users.organization_id, users.id,
+			CASE WHEN EXISTS (
+				SELECT 1 FROM user_roles JOIN roles ON roles.id = user_roles.role_id
+				WHERE user_roles.user_id = users.id AND roles.name = 'manager'
+			) THEN 'manager' ELSE 'staff' END,
+			true, users.created_at, NOW()
+		FROM users
+		WHERE users.deleted_at IS NULL AND users.organization_id IS NOT NULL
+		ON CONFLICT (organization_id, user_id) DO NOTHING`)
+	if staff.Error != nil {
+		return fmt.Errorf("failed to backfill staff memberships: %w", staff.Error)
+	}
+
+	if added := organizers.RowsAffected + staff.RowsAffected; added > 0 {
+		log.Printf("Backfilled %d organization memberships", added)
+	}
+	return nil
+}
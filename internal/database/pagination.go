@@ -0,0 +1,25 @@
+package database
+
+import (
+	"event-ticketing-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Paginate loads the requested page of a filtered and ordered query into dest and counts the
+// items across all pages. Order by a unique column last so pages neither skip nor repeat rows.
+func Paginate(query *gorm.DB, page models.PageQuery, dest interface{}) (*models.PageMeta, error) {
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	meta := models.NewPageMeta(page, total)
+	if total == 0 {
+		return meta, nil
+	}
+	if err := query.Offset(page.Offset()).Limit(meta.Limit).Find(dest).Error; err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
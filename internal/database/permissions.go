@@ -0,0 +1,105 @@
+package database
+
+// RoleDefinition declares a role seeded on startup
+type RoleDefinition struct {
+	Name        string
+	Description string
+}
+
+// PermissionDefinition declares a permission and the roles granted it by default. Admin is
+// granted every permission and is not listed.
+type PermissionDefinition struct {
+	Name        string
+	Description string
+	Resource    string
+	Action      string
+	Roles       []string
+}
+
+// AdminRole is granted every permission on each startup
+const AdminRole = "admin"
+
+// AuditorRole can read the data of every organization but never change anything; writes are
+// rejected for it when requests are authenticated
+const AuditorRole = "auditor"
+
+// DefaultRoles are the roles seeded on startup
+var DefaultRoles = []RoleDefinition{
+	{Name: AdminRole, Description: "Administrator with all permissions"},
+	{Name: "organizer", Description: "Event organizer with event management permissions"},
+	{Name: "manager", Description: "Organization manager with expanded permissions"},
+	{Name: "staff", Description: "Staff with limited event permissions"},
+	{Name: "user", Description: "Regular user with basic permissions"},
+	{Name: AuditorRole, Description: "Read-only auditor of all organizations"},
+}
+
+// PermissionRegistry declares every permission of the system. New permissions are added here;
+// SeedRoles creates them on the next startup and grants them to their default roles.
+var PermissionRegistry = []PermissionDefinition{
+	// Events
+	{Name: "create:event", Description: "Create events", Resource: "events", Action: "create", Roles: []string{"organizer", "manager"}},
+	{Name: "read:event", Description: "View events", Resource: "events", Action: "read", Roles: []string{"organizer", "manager", "staff", "user", "auditor"}},
+	{Name: "update:event", Description: "Update events", Resource: "events", Action: "update", Roles: []string{"organizer", "manager"}},
+	{Name: "delete:event", Description: "Delete events", Resource: "events", Action: "delete", Roles: []string{"organizer"}},
+
+	// Users
+	{Name: "create:user", Description: "Create users", Resource: "users", Action: "create"},
+	{Name: "read:user", Description: "View users", Resource: "users", Action: "read", Roles: []string{"manager", "staff", "auditor"}},
+	{Name: "update:user", Description: "Update users", Resource: "users", Action: "update"},
+	{Name: "delete:user", Description: "Delete users", Resource: "users", Action: "delete"},
+
+	// Staff
+	{Name: "manage:staff", Description: "Manage staff members", Resource: "staff", Action: "manage", Roles: []string{"organizer", "manager"}},
+
+	// Orders
+	{Name: "create:order", Description: "Place orders on behalf of attendees", Resource: "orders", Action: "create", Roles: []string{"organizer", "manager", "staff"}},
+	{Name: "read:order", Description: "View orders", Resource: "orders", Action: "read", Roles: []string{"organizer", "manager", "staff", "auditor"}},
+	{Name: "update:order", Description: "Update and cancel orders", Resource: "orders", Action: "update", Roles: []string{"organizer", "manager"}},
+
+	// Tickets
+	{Name: "scan:ticket", Description: "Check attendees in at the door", Resource: "tickets", Action: "scan", Roles: []string{"organizer", "manager", "staff"}},
+	{Name: "read:ticket", Description: "View tickets and attendees", Resource: "tickets", Action: "read", Roles: []string{"organizer", "manager", "staff", "auditor"}},
+	{Name: "update:ticket", Description: "Update, resend, transfer and revoke tickets", Resource: "tickets", Action: "update", Roles: []string{"organizer", "manager"}},
+
+	// Internal notes and tags on orders and attendees, never shown to buyers or attendees
+	{Name: "read:note", Description: "View internal notes and tags on orders and attendees", Resource: "notes", Action: "read", Roles: []string{"organizer", "manager", "staff", "auditor"}},
+	{Name: "manage:note", Description: "Add internal notes and tags to orders and attendees", Resource: "notes", Action: "manage", Roles: []string{"organizer", "manager", "staff"}},
+
+	// Saved attendee segments
+	{Name: "read:segment", Description: "View attendee segments and export their attendees", Resource: "segments", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+	{Name: "manage:segment", Description: "Create, update and delete attendee segments and sync them to marketing", Resource: "segments", Action: "manage", Roles: []string{"organizer", "manager"}},
+
+	// Unique contacts across an organization's events
+	{Name: "read:contact", Description: "View and export contacts with their lifetime spend and attendance", Resource: "contacts", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+	{Name: "manage:contact", Description: "Merge and unmerge duplicate contact addresses", Resource: "contacts", Action: "manage", Roles: []string{"organizer", "manager"}},
+
+	// Payments
+	{Name: "read:payment", Description: "View payments and installment plans", Resource: "payments", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+	{Name: "manage:payment", Description: "Record payments and manage installment plans", Resource: "payments", Action: "manage", Roles: []string{"organizer"}},
+
+	// Refunds
+	{Name: "create:refund", Description: "Request refunds", Resource: "refunds", Action: "create", Roles: []string{"organizer", "manager"}},
+	{Name: "approve:refund", Description: "Approve refunds", Resource: "refunds", Action: "approve", Roles: []string{"organizer"}},
+
+	// Promo codes
+	{Name: "read:promo_code", Description: "View promo codes and their usage", Resource: "promo_codes", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+	{Name: "manage:promo_code", Description: "Create, update and delete promo codes", Resource: "promo_codes", Action: "manage", Roles: []string{"organizer", "manager"}},
+
+	// Presales
+	{Name: "read:presale", Description: "View presales, their access codes and allowlists", Resource: "presales", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+	{Name: "manage:presale", Description: "Manage presales, their access codes and allowlists", Resource: "presales", Action: "manage", Roles: []string{"organizer", "manager"}},
+
+	// Analytics
+	{Name: "read:analytics", Description: "View sales and attendance analytics", Resource: "analytics", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+
+	// Webhooks
+	{Name: "read:webhook", Description: "View webhook subscriptions and deliveries", Resource: "webhooks", Action: "read", Roles: []string{"organizer", "manager", "auditor"}},
+	{Name: "manage:webhook", Description: "Manage webhook subscriptions", Resource: "webhooks", Action: "manage", Roles: []string{"organizer"}},
+
+	// Audit log
+	{Name: "read:audit_log", Description: "View the audit log of privileged actions", Resource: "audit_logs", Action: "read", Roles: []string{"auditor"}},
+}
+
+// OrganizationRoleResources are the resources whose permissions organizers may compose custom
+// organization roles from. Users and staff are left out so custom roles cannot manage membership.
+var OrganizationRoleResources = []string{"events", "orders", "tickets", "notes", "segments", "contacts", "payments", "refunds", "promo_codes", "presales", "analytics", "webhooks"}
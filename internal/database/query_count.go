@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type queryCountContextKey struct{}
+
+// WithQueryCount returns a context that counts the database statements run with it, so the
+// request logger can report how many queries a request issued and N+1 patterns stand out
+func WithQueryCount(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryCountContextKey{}, new(atomic.Int64))
+}
+
+// QueryCount returns the number of statements run with a context from WithQueryCount
+func QueryCount(ctx context.Context) int64 {
+	if counter, ok := ctx.Value(queryCountContextKey{}).(*atomic.Int64); ok {
+		return counter.Load()
+	}
+	return 0
+}
+
+// registerQueryCountCallbacks counts every statement, including preloads, which GORM runs as
+// separate queries
+func registerQueryCountCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().After("gorm:query").Register("query_count:query", countQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Row().After("gorm:row").Register("query_count:row", countQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().After("gorm:raw").Register("query_count:raw", countQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Create().After("gorm:create").Register("query_count:create", countQuery); err != nil {
+		return err
+	}
+	if err := callbacks.Update().After("gorm:update").Register("query_count:update", countQuery); err != nil {
+		return err
+	}
+	return callbacks.Delete().After("gorm:delete").Register("query_count:delete", countQuery)
+}
+
+func countQuery(db *gorm.DB) {
+	if db.Statement.Context == nil {
+		return
+	}
+	if counter, ok := db.Statement.Context.Value(queryCountContextKey{}).(*atomic.Int64); ok {
+		counter.Add(1)
+	}
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"event-ticketing-backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Schema versions used to keep blue-green deployments safe while migrations run as a separate job.
+// Bump SchemaVersion when this code needs tables or columns that older schemas lack. Raise
+// MinCompatibleSchemaVersion to the current SchemaVersion when a migration removes or changes
+// something older code still uses, so that code refuses to start instead of failing at runtime.
+const (
+	SchemaVersion              = 46
+	MinCompatibleSchemaVersion = 33
+)
+
+// ErrIncompatibleSchema is returned when the database schema cannot serve this build
+var ErrIncompatibleSchema = errors.New("incompatible database schema")
+
+// SchemaReport describes how the database schema compares to this build
+type SchemaReport struct {
+	Version              int      // Recorded schema version, 0 when migrations never recorded one
+	MinCompatibleVersion int      // Oldest application schema version the database supports
+	Drift                []string // Tables and columns the models expect but the database lacks
+}
+
+// recordSchemaVersion stores the schema version a migration run produced. Repeated runs of the
+// same build add no rows, so the table stays a history of schema changes.
+func recordSchemaVersion(db *gorm.DB) error {
+	if err := db.AutoMigrate(&models.SchemaMigration{}); err != nil {
+		return err
+	}
+
+	var latest models.SchemaMigration
+	if err := db.Order("id DESC").Limit(1).Find(&latest).Error; err != nil {
+		return err
+	}
+	if latest.Version == SchemaVersion && latest.MinCompatibleVersion == MinCompatibleSchemaVersion && latest.AppVersion == appVersion {
+		return nil
+	}
+
+	return db.Create(&models.SchemaMigration{
+		Version:              SchemaVersion,
+		MinCompatibleVersion: MinCompatibleSchemaVersion,
+		AppVersion:           appVersion,
+	}).Error
+}
+
+// CheckSchema compares the database schema with the models of this build. Missing tables and
+// columns are reported as drift; a schema older than this build or too new for it returns
+// ErrIncompatibleSchema.
+func CheckSchema() (*SchemaReport, error) {
+	report := &SchemaReport{}
+	migrator := DB.Migrator()
+
+	if migrator.HasTable(&models.SchemaMigration{}) {
+		var latest models.SchemaMigration
+		if err := DB.Order("id DESC").Limit(1).Find(&latest).Error; err != nil {
+			return nil, fmt.Errorf("failed to read schema version: %w", err)
+		}
+		report.Version = latest.Version
+		report.MinCompatibleVersion = latest.MinCompatibleVersion
+	}
+
+	for _, model := range Models() {
+		stmt := &gorm.Statement{DB: DB}
+		if err := stmt.Parse(model); err != nil {
+			return nil, fmt.Errorf("failed to parse model: %w", err)
+		}
+		if !migrator.HasTable(stmt.Table) {
+			report.Drift = append(report.Drift, fmt.Sprintf("missing table %s", stmt.Table))
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			if !migrator.HasColumn(model, field.DBName) {
+				report.Drift = append(report.Drift, fmt.Sprintf("missing column %s.%s", stmt.Table, field.DBName))
+			}
+		}
+	}
+
+	switch {
+	case report.Version < SchemaVersion:
+		return report, fmt.Errorf("%w: database is at version %d, this build needs %d; run the migrations first", ErrIncompatibleSchema, report.Version, SchemaVersion)
+	case SchemaVersion < report.MinCompatibleVersion:
+		return report, fmt.Errorf("%w: database is at version %d and supports builds from version %d, this build is %d", ErrIncompatibleSchema, report.Version, report.MinCompatibleVersion, SchemaVersion)
+	}
+	return report, nil
+}
@@ -1,187 +1,112 @@
 package database
 
 import (
+	"errors"
 	"event-ticketing-backend/internal/models"
 	"log"
 
 	"gorm.io/gorm"
 )
 
-// SeedRoles creates default roles and permissions
+// SeedRoles syncs DefaultRoles and PermissionRegistry with the database. It is idempotent and
+// runs on every startup: missing roles and permissions are created and registry descriptions
+// kept up to date. A permission is granted to its default roles when it or the role is created,
+// so grants revoked later stay revoked. Admin is granted every permission, including ones
+// created through the API.
 func SeedRoles(db *gorm.DB) error {
-	log.Println("Seeding initial roles and permissions...")
+	log.Println("Syncing roles and permissions...")
 
-	// Define default permissions
-	eventPermissions := []models.Permission{
-		{Name: "create:event", Description: "Create events", Resource: "events", Action: "create"},
-		{Name: "read:event", Description: "View events", Resource: "events", Action: "read"},
-		{Name: "update:event", Description: "Update events", Resource: "events", Action: "update"},
-		{Name: "delete:event", Description: "Delete events", Resource: "events", Action: "delete"},
-	}
-
-	userPermissions := []models.Permission{
-		{Name: "create:user", Description: "Create users", Resource: "users", Action: "create"},
-		{Name: "read:user", Description: "View users", Resource: "users", Action: "read"},
-		{Name: "update:user", Description: "Update users", Resource: "users", Action: "update"},
-		{Name: "delete:user", Description: "Delete users", Resource: "users", Action: "delete"},
-	}
-
-	organizerPermissions := []models.Permission{
-		{Name: "manage:staff", Description: "Manage staff members", Resource: "staff", Action: "manage"},
-	}
-
-	// Create permissions
-	for _, perm := range append(append(eventPermissions, userPermissions...), organizerPermissions...) {
-		var existingPerm models.Permission
-		if err := db.Where("name = ?", perm.Name).First(&existingPerm).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
-				if err := db.Create(&perm).Error; err != nil {
-					return err
-				}
-			} else {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		roles := make(map[string]*models.Role, len(DefaultRoles))
+		newRoles := make(map[string]bool)
+		for _, def := range DefaultRoles {
+			role, created, err := syncRole(tx, def)
+			if err != nil {
 				return err
 			}
+			roles[def.Name] = role
+			newRoles[def.Name] = created
 		}
-	}
 
-	// Create admin role with all permissions
-	adminRole := models.Role{
-		Name:        "admin",
-		Description: "Administrator with all permissions",
-	}
-
-	var existingAdminRole models.Role
-	if err := db.Where("name = ?", adminRole.Name).First(&existingAdminRole).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if err := db.Create(&adminRole).Error; err != nil {
+		for _, def := range PermissionRegistry {
+			permission, created, err := syncPermission(tx, def)
+			if err != nil {
 				return err
 			}
-
-			// Add all permissions to admin
-			var allPermissions []models.Permission
-			if err := db.Find(&allPermissions).Error; err != nil {
-				return err
+			for _, name := range def.Roles {
+				role, ok := roles[name]
+				if !ok || !(created || newRoles[name]) {
+					continue
+				}
+				if err := tx.Model(role).Association("Permissions").Append(permission); err != nil {
+					return err
+				}
 			}
+		}
 
-			if err := db.Model(&adminRole).Association("Permissions").Replace(allPermissions); err != nil {
-				return err
-			}
-		} else {
+		var allPermissions []*models.Permission
+		if err := tx.Find(&allPermissions).Error; err != nil {
 			return err
 		}
+		return tx.Model(roles[AdminRole]).Association("Permissions").Append(allPermissions)
+	})
+	if err != nil {
+		return err
 	}
 
-	// Create organizer role with event and staff permissions
-	organizerRole := models.Role{
-		Name:        "organizer",
-		Description: "Event organizer with event management permissions",
-	}
-
-	var existingOrganizerRole models.Role
-	if err := db.Where("name = ?", organizerRole.Name).First(&existingOrganizerRole).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if err := db.Create(&organizerRole).Error; err != nil {
-				return err
-			}
-
-			// Add relevant permissions to organizer
-			var organizerPerms []models.Permission
-			if err := db.Where("resource IN ?", []string{"events", "staff"}).Find(&organizerPerms).Error; err != nil {
-				return err
-			}
+	log.Println("Roles and permissions synced successfully!")
+	return nil
+}
 
-			if err := db.Model(&organizerRole).Association("Permissions").Replace(organizerPerms); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
+// syncRole creates a role missing from the database
+func syncRole(tx *gorm.DB, def RoleDefinition) (*models.Role, bool, error) {
+	var role models.Role
+	err := tx.Where("name = ?", def.Name).First(&role).Error
+	if err == nil {
+		return &role, false, nil
 	}
-
-	// Create staff role
-	staffRole := models.Role{
-		Name:        "staff",
-		Description: "Staff with limited event permissions",
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
 	}
 
-	var existingStaffRole models.Role
-	if err := db.Where("name = ?", staffRole.Name).First(&existingStaffRole).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if err := db.Create(&staffRole).Error; err != nil {
-				return err
-			}
-
-			// Add read-only event permissions to staff
-			var staffPerms []models.Permission
-			if err := db.Where("name IN ?", []string{"read:event", "read:user"}).Find(&staffPerms).Error; err != nil {
-				return err
-			}
-
-			if err := db.Model(&staffRole).Association("Permissions").Replace(staffPerms); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
+	role = models.Role{Name: def.Name, Description: def.Description}
+	if err := tx.Create(&role).Error; err != nil {
+		return nil, false, err
 	}
+	log.Printf("Role created: %s", def.Name)
+	return &role, true, nil
+}
 
-	// Create manager role
-	managerRole := models.Role{
-		Name:        "manager",
-		Description: "Organization manager with expanded permissions",
+// syncPermission creates a registry permission missing from the database, or updates its
+// description, resource and action when they changed in the registry
+func syncPermission(tx *gorm.DB, def PermissionDefinition) (*models.Permission, bool, error) {
+	var permission models.Permission
+	err := tx.Where("name = ?", def.Name).First(&permission).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
 	}
 
-	var existingManagerRole models.Role
-	if err := db.Where("name = ?", managerRole.Name).First(&existingManagerRole).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if err := db.Create(&managerRole).Error; err != nil {
-				return err
-			}
-
-			// Add permissions to manager (more than staff, fewer than organizer)
-			var managerPerms []models.Permission
-			if err := db.Where("name IN ?",
-				[]string{
-					"read:event", "read:user", "update:event",
-					"create:event", "manage:staff"}).Find(&managerPerms).Error; err != nil {
-				return err
-			}
-
-			if err := db.Model(&managerRole).Association("Permissions").Replace(managerPerms); err != nil {
-				return err
-			}
-		} else {
-			return err
+	if err == nil {
+		if permission.Description == def.Description && permission.Resource == def.Resource && permission.Action == def.Action {
+			return &permission, false, nil
 		}
+		err := tx.Model(&permission).Updates(map[string]interface{}{
+			"description": def.Description,
+			"resource":    def.Resource,
+			"action":      def.Action,
+		}).Error
+		return &permission, false, err
 	}
 
-	// Create user role
-	userRole := models.Role{
-		Name:        "user",
-		Description: "Regular user with basic permissions",
+	permission = models.Permission{
+		Name:        def.Name,
+		Description: def.Description,
+		Resource:    def.Resource,
+		Action:      def.Action,
 	}
-
-	var existingUserRole models.Role
-	if err := db.Where("name = ?", userRole.Name).First(&existingUserRole).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			if err := db.Create(&userRole).Error; err != nil {
-				return err
-			}
-
-			// Add basic permissions to user
-			var userPerms []models.Permission
-			if err := db.Where("name = ?", "read:event").Find(&userPerms).Error; err != nil {
-				return err
-			}
-
-			if err := db.Model(&userRole).Association("Permissions").Replace(userPerms); err != nil {
-				return err
-			}
-		} else {
-			return err
-		}
+	if err := tx.Create(&permission).Error; err != nil {
+		return nil, false, err
 	}
-
-	log.Println("Roles and permissions seeded successfully!")
-	return nil
+	log.Printf("Permission created: %s", def.Name)
+	return &permission, true, nil
 }
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ErrCrossTenantWrite is returned when a statement running for one organization tries to
+// create a record belonging to another
+var ErrCrossTenantWrite = errors.New("record belongs to another organization")
+
+type tenantContextKey struct{}
+
+// skipTenancyKey is the statement setting that disables the tenancy guard
+const skipTenancyKey = "tenancy:skip"
+
+// tenantColumnField is the model field that marks a table as organization-owned
+const tenantColumnField = "OrganizationID"
+
+// tenancyExemptTables have an organization column that does not mean ownership. A user's
+// organization_id marks the organization they joined first; attendees have none and buy from
+// every organization.
+var tenancyExemptTables = map[string]bool{
+	"users": true,
+}
+
+// WithOrganization returns a context that scopes database statements to an organization.
+// Statements run with it through DB.WithContext only see and modify rows of that organization.
+func WithOrganization(ctx context.Context, orgID uuid.UUID) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, orgID)
+}
+
+// OrganizationFromContext returns the organization statements are scoped to, if any
+func OrganizationFromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	orgID, ok := ctx.Value(tenantContextKey{}).(uuid.UUID)
+	return orgID, ok && orgID != uuid.Nil
+}
+
+// SkipTenancy disables the tenancy guard for a statement, for the rare lookups that must span
+// organizations such as global uniqueness checks
+func SkipTenancy(db *gorm.DB) *gorm.DB {
+	return db.Set(skipTenancyKey, true)
+}
+
+// registerTenancyCallbacks installs the tenancy guard. Queries, updates and deletes on models with
+// an OrganizationID field get an organization_id condition when the statement context carries an
+// organization, and creates of another organization's records are rejected. Raw SQL is not guarded.
+func registerTenancyCallbacks(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("tenancy:scope_query", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("tenancy:scope_row", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("tenancy:scope_update", scopeToTenant); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("tenancy:scope_delete", scopeToTenant); err != nil {
+		return err
+	}
+	return callbacks.Create().Before("gorm:create").Register("tenancy:check_create", checkTenantOnCreate)
+}
+
+// tenantField returns the organization column of the statement's model and the organization the
+// statement is scoped to, or nil when the guard does not apply
+func tenantField(db *gorm.DB) (*schema.Field, uuid.UUID) {
+	if db.Statement.Schema == nil || tenancyExemptTables[db.Statement.Schema.Table] {
+		return nil, uuid.Nil
+	}
+	if skip, ok := db.Get(skipTenancyKey); ok && skip == true {
+		return nil, uuid.Nil
+	}
+	orgID, ok := OrganizationFromContext(db.Statement.Context)
+	if !ok {
+		return nil, uuid.Nil
+	}
+	field := db.Statement.Schema.LookUpField(tenantColumnField)
+	if field == nil || field.DBName == "" {
+		return nil, uuid.Nil
+	}
+	return field, orgID
+}
+
+// scopeToTenant restricts a statement to rows of the context organization
+func scopeToTenant(db *gorm.DB) {
+	field, orgID := tenantField(db)
+	if field == nil {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: orgID},
+	}})
+}
+
+// checkTenantOnCreate rejects creating records that belong to an organization other than the context one
+func checkTenantOnCreate(db *gorm.DB) {
+	field, orgID := tenantField(db)
+	if field == nil {
+		return
+	}
+
+	check := func(rv reflect.Value) {
+		value, zero := field.ValueOf(db.Statement.Context, rv)
+		if zero {
+			return
+		}
+		var owner uuid.UUID
+		switch v := value.(type) {
+		case uuid.UUID:
+			owner = v
+		case *uuid.UUID:
+			if v == nil {
+				return
+			}
+			owner = *v
+		default:
+			return
+		}
+		if owner != orgID {
+			db.AddError(ErrCrossTenantWrite)
+		}
+	}
+
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			check(reflect.Indirect(rv.Index(i)))
+		}
+	case reflect.Struct:
+		check(rv)
+	}
+}
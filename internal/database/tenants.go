@@ -0,0 +1,25 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// clearTenantPaymentKeys removes the payment provider keys tenants used to collect. Payments are
+// taken through the deployment's provider account only, so the secret keys were never used. The
+// columns are left for older instances still reading them.
+func clearTenantPaymentKeys(db *gorm.DB) error {
+	if !db.Migrator().HasColumn("tenants", "payment_secret_key") {
+		return nil
+	}
+	result := db.Exec(`UPDATE tenants SET payment_secret_key = NULL, payment_publishable_key = '' WHERE payment_secret_key IS NOT NULL OR payment_publishable_key <> ''`)
+	if result.Error != nil {
+		return fmt.Errorf("failed to clear tenant payment keys: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Cleared the payment keys of %d tenants", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// txState is the transaction bound to a context and the work waiting for it to commit
+type txState struct {
+	tx          *gorm.DB
+	afterCommit []func()
+}
+
+// WithinTx runs fn in a single database transaction. Statements issued through Conn with the
+// context passed to fn join the transaction, so multi-step flows spread over several service
+// methods commit or roll back together. A nested WithinTx joins the outer transaction instead of
+// starting its own. The transaction rolls back when fn returns an error or panics.
+func WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.tx = tx
+		return fn(context.WithValue(ctx, txContextKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// Conn returns the connection statements for ctx should use: the transaction started by WithinTx
+// when there is one, db otherwise. Either way ctx is the statement context, so cancellation and
+// tenancy scoping apply.
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return state.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// AfterCommit defers fn until the transaction bound to ctx commits, and drops it if the
+// transaction rolls back. Side effects outside the database, such as queueing emails, belong here
+// so they never announce changes that were not saved. Without a transaction fn runs right away.
+func AfterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
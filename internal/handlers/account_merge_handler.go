@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountMergeHandler struct {
+	accountMergeService *services.AccountMergeService
+}
+
+func NewAccountMergeHandler(accountMergeService *services.AccountMergeService) *AccountMergeHandler {
+	return &AccountMergeHandler{accountMergeService: accountMergeService}
+}
+
+// MergeAccounts godoc
+// @Summary Merge duplicate accounts
+// @Description Merges a duplicate account of the same person into the account that survives. Orders (including guest orders placed with the duplicate's email), tickets, checkout sessions, organized events and organizations, organization membership, roles, sessions and devices move to the surviving account, which also takes over the username and avatar if it has none. The duplicate is then deleted. Everything happens in one transaction and is recorded in the audit log. Send dry_run to preview the counts without changing anything. Requires an elevated access token (see /admin/elevations).
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.MergeAccountsRequest true "Accounts to merge"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AccountMergeResult}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/users/merge [post]
+func (h *AccountMergeHandler) MergeAccounts(c *gin.Context) {
+	adminID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.MergeAccountsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	result, err := h.accountMergeService.Merge(c.Request.Context(), adminID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to merge accounts", err)
+		return
+	}
+
+	message := "Accounts merged successfully"
+	if result.DryRun {
+		message = "Account merge previewed successfully"
+	}
+	utils.SuccessResponse(c, http.StatusOK, message, result)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAccountingExport godoc
+// @Summary Request an accounting export
+// @Description Queues a QuickBooks or Xero journal export of paid orders, fees and payouts between period_start and period_end, grouped into weekly or monthly settlement periods. Ledger account keys are sales, fees and clearing.
+// @Tags accounting
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.CreateAccountingExportRequest true "Export parameters"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response{data=models.AccountingExportResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/accounting/exports [post]
+func (h *IntegrationHandler) CreateAccountingExport(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.CreateAccountingExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	export, err := h.integrationService.CreateAccountingExport(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create accounting export", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Accounting export queued", export)
+}
+
+// ListAccountingExports godoc
+// @Summary List accounting exports
+// @Description Lists accounting exports requested for the organization, newest first
+// @Tags accounting
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.AccountingExportResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/accounting/exports [get]
+func (h *IntegrationHandler) ListAccountingExports(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	exports, err := h.integrationService.ListAccountingExports(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get accounting exports", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Accounting exports retrieved successfully", exports)
+}
+
+// GetAccountingExport godoc
+// @Summary Get an accounting export
+// @Description Returns the processing status of an accounting export
+// @Tags accounting
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param exportId path string true "Export ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AccountingExportResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/accounting/exports/{exportId} [get]
+func (h *IntegrationHandler) GetAccountingExport(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	exportID := middleware.UUIDParam(c, "exportId")
+
+	export, err := h.integrationService.GetAccountingExport(c.Request.Context(), orgID, exportID)
+	if err != nil {
+		utils.NotFoundErrorResponse(c, "Accounting export not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Accounting export retrieved successfully", export.ToResponse())
+}
+
+// DownloadAccountingExport godoc
+// @Summary Download an accounting export
+// @Description Downloads the generated CSV journal file of a completed accounting export
+// @Tags accounting
+// @Produce text/csv
+// @Param id path string true "Organization ID"
+// @Param exportId path string true "Export ID"
+// @Security ApiKeyAuth
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/accounting/exports/{exportId}/download [get]
+func (h *IntegrationHandler) DownloadAccountingExport(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	exportID := middleware.UUIDParam(c, "exportId")
+
+	export, err := h.integrationService.GetAccountingExport(c.Request.Context(), orgID, exportID)
+	if err != nil {
+		utils.NotFoundErrorResponse(c, "Accounting export not found", err)
+		return
+	}
+
+	if export.Status != models.ExportStatusCompleted {
+		utils.ConflictErrorResponse(c, "Accounting export is not ready", fmt.Errorf("export status is %s", export.Status))
+		return
+	}
+
+	filename := fmt.Sprintf("%s-journal-%s-%s.csv", export.Format,
+		export.PeriodStart.Format("20060102"), export.PeriodEnd.Format("20060102"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(export.Content))
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AllocationHandler struct {
+	allocationService *services.AllocationService
+}
+
+func NewAllocationHandler(allocationService *services.AllocationService) *AllocationHandler {
+	return &AllocationHandler{
+		allocationService: allocationService,
+	}
+}
+
+// CreateAllocation godoc
+// @Summary Reserve a hold or comp block
+// @Description Removes a block of tickets from general sale as a hold or comp allocation. Unused tickets return to general sale at release_at, if set.
+// @Tags allocations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.CreateAllocationRequest true "Allocation data"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.AllocationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/allocations [post]
+func (h *AllocationHandler) CreateAllocation(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.CreateAllocationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	allocation, err := h.allocationService.CreateAllocation(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create allocation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Allocation created successfully", allocation)
+}
+
+// ListAllocations godoc
+// @Summary List hold and comp allocations
+// @Description Lists the organization's allocations, optionally for a single event
+// @Tags allocations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int false "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.AllocationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/allocations [get]
+func (h *AllocationHandler) ListAllocations(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var eventID uint64
+	if raw := c.Query("event_id"); raw != "" {
+		var err error
+		if eventID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+			return
+		}
+	}
+
+	allocations, err := h.allocationService.ListAllocations(c.Request.Context(), orgID, uint(eventID))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get allocations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Allocations retrieved successfully", allocations)
+}
+
+// IssueComps godoc
+// @Summary Issue comp tickets
+// @Description Issues complimentary tickets from a comp allocation without payment and emails each recipient their ticket. Recipients are listed, or given as a saved attendee segment (segment_id), which issues one ticket per distinct attendee email it selects.
+// @Tags allocations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param allocationId path string true "Allocation ID"
+// @Param request body models.IssueCompsRequest true "Recipients or segment"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=[]models.AttendeeResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/allocations/{allocationId}/issue [post]
+func (h *AllocationHandler) IssueComps(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	allocationID := middleware.UUIDParam(c, "allocationId")
+
+	var req models.IssueCompsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	attendees, err := h.allocationService.IssueComps(c.Request.Context(), orgID, allocationID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to issue comp tickets", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Comp tickets issued successfully", attendees)
+}
+
+// ReleaseAllocation godoc
+// @Summary Release an allocation
+// @Description Returns the unused tickets of a hold or comp allocation to general sale immediately
+// @Tags allocations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param allocationId path string true "Allocation ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AllocationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/allocations/{allocationId}/release [post]
+func (h *AllocationHandler) ReleaseAllocation(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	allocationID := middleware.UUIDParam(c, "allocationId")
+
+	allocation, err := h.allocationService.ReleaseAllocation(c.Request.Context(), orgID, allocationID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to release allocation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Allocation released successfully", allocation)
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// ListAPIKeys godoc
+// @Summary List an organization's API keys
+// @Description Returns the organization's API keys with their scopes and last use, newest first, including revoked ones. Keys themselves are never returned after they are issued.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.APIKeyResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch API keys", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API keys fetched successfully", keys)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Issues an API key for integrators to call the organization's endpoints in the X-API-Key header instead of a user token. Scopes are permissions custom roles can be composed from, such as read:order; the key acts for the organizer creating it but gets only its scopes, in this organization only. The key is returned once, in key.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.APIKeyRequest true "Key name, scopes and expiry"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.APIKeyResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.APIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	key, err := h.apiKeyService.Create(c.Request.Context(), orgID, actorID, &req)
+	if err != nil {
+		apiKeyErrorResponse(c, "Failed to create API key", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "API key created successfully", key)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate an API key
+// @Description Replaces the key of an API key, keeping its name and scopes. The previous key stops working immediately; the new one is returned once, in key.
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param apiKeyId path string true "API key ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.APIKeyResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/api-keys/{apiKeyId}/rotate [post]
+func (h *APIKeyHandler) RotateAPIKey(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	key, err := h.apiKeyService.Rotate(c.Request.Context(), orgID, middleware.UUIDParam(c, "apiKeyId"), actorID)
+	if err != nil {
+		apiKeyErrorResponse(c, "Failed to rotate API key", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API key rotated successfully", key)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Cuts an API key off immediately, e.g. when an integration is removed or the key leaked
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param apiKeyId path string true "API key ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/api-keys/{apiKeyId} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), orgID, middleware.UUIDParam(c, "apiKeyId"), actorID); err != nil {
+		apiKeyErrorResponse(c, "Failed to revoke API key", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "API key revoked successfully", nil)
+}
+
+func apiKeyErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrAPIKeyNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	case errors.Is(err, services.ErrPermissionNotAssignable):
+		utils.BadRequestErrorResponse(c, message, err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
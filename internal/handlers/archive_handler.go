@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveHandler struct {
+	archiveService *services.ArchiveService
+}
+
+func NewArchiveHandler(archiveService *services.ArchiveService) *ArchiveHandler {
+	return &ArchiveHandler{archiveService: archiveService}
+}
+
+// ListArchives godoc
+// @Summary List event archives
+// @Description Returns a page of event archives, most recently archived first, with the totals each event had when its orders, tickets, check-ins and scan attempts were archived
+// @Tags admin
+// @Produce json
+// @Param event_id query int false "Event ID"
+// @Param status query string false "Status" Enums(archived, restored)
+// @Param page query int false "Page number, from 1" default(1)
+// @Param limit query int false "Archives per page, at most 100" default(20)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.EventArchive,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/archives [get]
+func (h *ArchiveHandler) ListArchives(c *gin.Context) {
+	query := c.MustGet("validatedQuery").(*models.EventArchiveQuery)
+
+	archives, meta, err := h.archiveService.ListArchives(c.Request.Context(), query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch event archives", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Event archives fetched successfully", archives, meta)
+}
+
+// GetArchive godoc
+// @Summary Get an event archive
+// @Description Returns an event archive with the number of records it holds of each kind
+// @Tags admin
+// @Produce json
+// @Param archiveId path string true "Archive ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.EventArchiveResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/archives/{archiveId} [get]
+func (h *ArchiveHandler) GetArchive(c *gin.Context) {
+	archive, err := h.archiveService.GetArchive(c.Request.Context(), middleware.UUIDParam(c, "archiveId"))
+	if err != nil {
+		archiveErrorResponse(c, "Failed to fetch event archive", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event archive fetched successfully", archive)
+}
+
+// ListArchivedRecords godoc
+// @Summary List the records of an event archive
+// @Description Returns a page of the archived rows, as they were in their table, so archived orders and tickets can be looked up without restoring them
+// @Tags admin
+// @Produce json
+// @Param archiveId path string true "Archive ID"
+// @Param kind query string false "Kind" Enums(orders, tickets, check_ins, scan_attempts)
+// @Param page query int false "Page number, from 1" default(1)
+// @Param limit query int false "Records per page, at most 100" default(20)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.ArchivedRecord,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/archives/{archiveId}/records [get]
+func (h *ArchiveHandler) ListArchivedRecords(c *gin.Context) {
+	query := c.MustGet("validatedQuery").(*models.ArchivedRecordQuery)
+
+	records, meta, err := h.archiveService.ListRecords(c.Request.Context(), middleware.UUIDParam(c, "archiveId"), query)
+	if err != nil {
+		archiveErrorResponse(c, "Failed to fetch archived records", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Archived records fetched successfully", records, meta)
+}
+
+// ArchiveEvent godoc
+// @Summary Archive an event
+// @Description Moves the orders, tickets, check-ins and scan attempts of an event that has ended out of the hot tables into an archive, keeping the event's sales and attendance totals. Events that ended more than ARCHIVE_AFTER_YEARS ago are archived by a scheduled sweep. Requires an elevated token.
+// @Tags admin
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.EventArchive}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/events/{id}/archive [post]
+func (h *ArchiveHandler) ArchiveEvent(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	archive, err := h.archiveService.ArchiveEvent(c.Request.Context(), &userID, uint(eventID))
+	if err != nil {
+		archiveErrorResponse(c, "Failed to archive event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event archived successfully", archive)
+}
+
+// RestoreArchive godoc
+// @Summary Restore an event archive
+// @Description Moves the archived orders, tickets, check-ins and scan attempts of an event back to the hot tables. The archive is kept, marked restored, and the policy does not archive the event again.
+// @Tags admin
+// @Produce json
+// @Param archiveId path string true "Archive ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.EventArchive}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/archives/{archiveId}/restore [post]
+func (h *ArchiveHandler) RestoreArchive(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	archive, err := h.archiveService.Restore(c.Request.Context(), userID, middleware.UUIDParam(c, "archiveId"))
+	if err != nil {
+		archiveErrorResponse(c, "Failed to restore event archive", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event archive restored successfully", archive)
+}
+
+func archiveErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrEventArchiveNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	case errors.Is(err, services.ErrEventAlreadyArchived), errors.Is(err, services.ErrEventArchiveRestored):
+		utils.ConflictErrorResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AttendeeSegmentHandler struct {
+	segmentService *services.AttendeeSegmentService
+}
+
+func NewAttendeeSegmentHandler(segmentService *services.AttendeeSegmentService) *AttendeeSegmentHandler {
+	return &AttendeeSegmentHandler{segmentService: segmentService}
+}
+
+// ListSegments godoc
+// @Summary List attendee segments
+// @Description Returns the organization's saved attendee segments by name, each with the tickets, attendees and opted-in attendees it selects now
+// @Tags segments
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.AttendeeSegmentResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/segments [get]
+func (h *AttendeeSegmentHandler) ListSegments(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	segments, err := h.segmentService.List(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch segments", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Segments fetched successfully", segments)
+}
+
+// CreateSegment godoc
+// @Summary Save an attendee segment
+// @Description Saves criteria selecting the organization's attendees by event, ticket type, hold/comp block, check-in, purchase date range and internal tags. Every criterion given must match; cancelled tickets are never selected. Segments are evaluated whenever they are used.
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.AttendeeSegmentRequest true "Segment"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.AttendeeSegmentResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/segments [post]
+func (h *AttendeeSegmentHandler) CreateSegment(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.AttendeeSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	segment, err := h.segmentService.Create(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		segmentErrorResponse(c, "Failed to save segment", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Segment saved successfully", segment)
+}
+
+// PreviewSegment godoc
+// @Summary Preview an attendee segment
+// @Description Counts the tickets, attendees and opted-in attendees the criteria select now, without saving them
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.SegmentCriteria true "Criteria"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.SegmentCounts}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/segments/preview [post]
+func (h *AttendeeSegmentHandler) PreviewSegment(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var criteria models.SegmentCriteria
+	if err := c.ShouldBindJSON(&criteria); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	counts, err := h.segmentService.Preview(c.Request.Context(), orgID, &criteria)
+	if err != nil {
+		segmentErrorResponse(c, "Failed to preview segment", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Segment previewed successfully", counts)
+}
+
+// GetSegment godoc
+// @Summary Get an attendee segment
+// @Description Returns a saved segment with the tickets, attendees and opted-in attendees it selects now
+// @Tags segments
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param segmentId path string true "Segment ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.AttendeeSegmentResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/segments/{segmentId} [get]
+func (h *AttendeeSegmentHandler) GetSegment(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	segment, err := h.segmentService.Get(c.Request.Context(), orgID, middleware.UUIDParam(c, "segmentId"))
+	if err != nil {
+		segmentErrorResponse(c, "Failed to fetch segment", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Segment fetched successfully", segment)
+}
+
+// UpdateSegment godoc
+// @Summary Update an attendee segment
+// @Description Renames a saved segment and replaces its criteria
+// @Tags segments
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param segmentId path string true "Segment ID"
+// @Param request body models.AttendeeSegmentRequest true "Segment"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.AttendeeSegmentResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/segments/{segmentId} [put]
+func (h *AttendeeSegmentHandler) UpdateSegment(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.AttendeeSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	segment, err := h.segmentService.Update(c.Request.Context(), orgID, middleware.UUIDParam(c, "segmentId"), &req)
+	if err != nil {
+		segmentErrorResponse(c, "Failed to update segment", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Segment updated successfully", segment)
+}
+
+// DeleteSegment godoc
+// @Summary Delete an attendee segment
+// @Description Deletes a saved segment; the attendees it selected are unchanged
+// @Tags segments
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param segmentId path string true "Segment ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/segments/{segmentId} [delete]
+func (h *AttendeeSegmentHandler) DeleteSegment(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	if err := h.segmentService.Delete(c.Request.Context(), orgID, middleware.UUIDParam(c, "segmentId")); err != nil {
+		segmentErrorResponse(c, "Failed to delete segment", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Segment deleted successfully", nil)
+}
+
+// ListSegmentAttendees godoc
+// @Summary List the attendees of a segment
+// @Description Returns a page of the tickets a saved segment selects now, newest first
+// @Tags segments
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param segmentId path string true "Segment ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.SegmentAttendee,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/segments/{segmentId}/attendees [get]
+func (h *AttendeeSegmentHandler) ListSegmentAttendees(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.PageQuery)
+
+	attendees, meta, err := h.segmentService.Attendees(c.Request.Context(), orgID, middleware.UUIDParam(c, "segmentId"), *query)
+	if err != nil {
+		segmentErrorResponse(c, "Failed to fetch segment attendees", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Segment attendees fetched successfully", attendees, meta)
+}
+
+// ExportSegment godoc
+// @Summary Export the attendees of a segment
+// @Description Downloads the tickets a saved segment selects now as CSV: ticket, order and event IDs, attendee name and email, status, purchase and check-in times
+// @Tags segments
+// @Produce text/csv
+// @Param id path string true "Organization ID"
+// @Param segmentId path string true "Segment ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {file} file
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/segments/{segmentId}/export [get]
+func (h *AttendeeSegmentHandler) ExportSegment(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	data, segment, err := h.segmentService.Export(c.Request.Context(), orgID, middleware.UUIDParam(c, "segmentId"))
+	if err != nil {
+		segmentErrorResponse(c, "Failed to export segment", err)
+		return
+	}
+
+	filename := fmt.Sprintf("segment-%s.csv", strings.ReplaceAll(strings.ToLower(segment.Name), " ", "-"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// SyncSegmentMarketing godoc
+// @Summary Sync a segment to marketing
+// @Description Queues a background upload of the segment's attendees who accepted marketing to the configured marketing audience, tagged "segment-" followed by the segment name, so campaigns can target them
+// @Tags segments
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param segmentId path string true "Segment ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/segments/{segmentId}/marketing-sync [post]
+func (h *AttendeeSegmentHandler) SyncSegmentMarketing(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	if err := h.segmentService.SyncMarketing(c.Request.Context(), orgID, middleware.UUIDParam(c, "segmentId")); err != nil {
+		segmentErrorResponse(c, "Failed to queue segment sync", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Segment sync queued", nil)
+}
+
+func segmentErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrSegmentNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary Search the audit log
+// @Description Returns the most recent audit log entries of privileged actions, optionally filtered by entity, actor or action
+// @Tags admin
+// @Produce json
+// @Param entity_type query string false "Entity type, e.g. order"
+// @Param entity_id query string false "Entity ID"
+// @Param actor_id query string false "User who performed the action"
+// @Param action query string false "Action, e.g. order_adjustment.applied"
+// @Param limit query int false "Maximum number of entries" default(50)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.AuditLog}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/audit-logs [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var filter models.AuditLogFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	entries, err := h.auditService.ListAuditLogs(c.Request.Context(), &filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve audit logs", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Audit logs retrieved successfully", entries)
+}
@@ -1,30 +1,33 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"event-ticketing-backend/internal/middleware"
 	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/config"
 	"event-ticketing-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
-	authService *services.AuthService
+	authService   *services.AuthService
+	countryHeader string
 }
 
 func NewAuthHandler(cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		authService: services.NewAuthService(cfg),
+		authService:   services.NewAuthService(cfg),
+		countryHeader: cfg.Security.CountryHeader,
 	}
 }
 
 // Register godoc
 // @Summary Register a new user
-// @Description Create a new user account
+// @Description Create a new user account with an email and password; name and phone are optional and can be completed later with PATCH /auth/profile. Passwords found in known data breaches are rejected with error code PASSWORD_BREACHED.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -40,9 +43,13 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.Register(&req)
+	user, err := h.authService.Register(c.Request.Context(), &req)
 	if err != nil {
-		// You can now use specific error types
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			utils.HandleAppError(c, appErr)
+			return
+		}
 		utils.BadRequestErrorResponse(c, "Registration failed", err)
 		return
 	}
@@ -52,11 +59,12 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary Authenticate user
-// @Description Login with email and password to get JWT tokens
+// @Description Login with email and password to get JWT tokens. Logins from a new device or country send a security alert email.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body models.LoginRequest true "Login credentials"
+// @Param X-Device-ID header string false "Stable client-generated device identifier"
 // @Success 200 {object} utils.Response{data=models.TokenResponse}
 // @Failure 400 {object} utils.Response
 // @Failure 401 {object} utils.Response
@@ -69,7 +77,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.Login(&req)
+	client := &models.LoginContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		DeviceID:  c.GetHeader("X-Device-ID"),
+		Country:   c.GetHeader(h.countryHeader),
+	}
+
+	tokens, err := h.authService.Login(c.Request.Context(), &req, client)
 	if err != nil {
 		utils.UnauthorizedErrorResponse(c, err.Error(), nil)
 		return
@@ -78,6 +93,56 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Login successful", tokens)
 }
 
+// ReportUnrecognizedLogin godoc
+// @Summary Report a sign-in as not yours
+// @Description One-click link from a new-device alert email. Signs the account out of all sessions and requires a password reset before the next login.
+// @Tags auth
+// @Produce json
+// @Param token query string true "Token from the alert email"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /auth/not-me [get]
+func (h *AuthHandler) ReportUnrecognizedLogin(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		utils.BadRequestErrorResponse(c, "Token is required", nil)
+		return
+	}
+
+	if err := h.authService.ReportUnrecognizedLogin(c.Request.Context(), token); err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to secure account", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "All sessions have been signed out. Please reset your password to sign in again.", nil)
+}
+
+// ListDevices godoc
+// @Summary List signed-in devices
+// @Description Returns the devices the current user has signed in from
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.UserDevice}
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /auth/devices [get]
+func (h *AuthHandler) ListDevices(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	devices, err := h.authService.ListDevices(c.Request.Context(), userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve devices", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Devices retrieved successfully", devices)
+}
+
 // RefreshToken godoc
 // @Summary Refresh access token
 // @Description Get new access and refresh tokens using a valid refresh token
@@ -97,7 +162,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	tokens, err := h.authService.RefreshToken(&req)
+	tokens, err := h.authService.RefreshToken(c.Request.Context(), &req)
 	if err != nil {
 		utils.UnauthorizedErrorResponse(c, "Token refresh failed", err)
 		return
@@ -108,11 +173,11 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 
 // Logout godoc
 // @Summary Logout user
-// @Description Revoke user's refresh tokens
+// @Description Revoke the access token of the request right away. With all=true, also revoke the user's refresh tokens and every access token issued to them.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param all query boolean false "Revoke all refresh tokens for the user"
+// @Param all query boolean false "Sign out of every session of the user"
 // @Security ApiKeyAuth
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
@@ -121,7 +186,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 // @Router /auth/logout [post]
 func (h *AuthHandler) Logout(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
@@ -130,8 +195,9 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 	// Parse the "all" query parameter
 	all := c.DefaultQuery("all", "false") == "true"
 
-	// Logout
-	err := h.authService.Logout(userID.(uuid.UUID), all)
+	// Logout; the claims identify the access token to revoke
+	claims, _ := middleware.AccessTokenClaims(c)
+	err := h.authService.Logout(c.Request.Context(), userID, claims, all)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Logout failed", err)
 		return
@@ -150,6 +216,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
+// @Failure 503 {object} utils.Response
 // @Router /auth/reset-password-request [post]
 func (h *AuthHandler) ResetPasswordRequest(c *gin.Context) {
 	var req models.ResetPasswordRequest
@@ -159,7 +226,11 @@ func (h *AuthHandler) ResetPasswordRequest(c *gin.Context) {
 	}
 
 	// Always return success for security reasons, even if email doesn't exist
-	if err := h.authService.SendPasswordResetEmail(&req); err != nil {
+	if err := h.authService.SendPasswordResetEmail(c.Request.Context(), &req); err != nil {
+		if errors.Is(err, services.ErrOTPUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, services.ErrOTPUnavailable.Error(), nil)
+			return
+		}
 		// Log the error but don't expose it to the client
 		c.Error(err)
 	}
@@ -177,6 +248,7 @@ func (h *AuthHandler) ResetPasswordRequest(c *gin.Context) {
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
+// @Failure 503 {object} utils.Response
 // @Router /auth/reset-password [post]
 func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	var req models.UpdatePasswordRequest
@@ -185,7 +257,16 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.ResetPassword(&req); err != nil {
+	if err := h.authService.ResetPassword(c.Request.Context(), &req); err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			utils.HandleAppError(c, appErr)
+			return
+		}
+		if errors.Is(err, services.ErrOTPUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, services.ErrOTPUnavailable.Error(), nil)
+			return
+		}
 		utils.BadRequestErrorResponse(c, "Password reset failed", err)
 		return
 	}
@@ -205,13 +286,13 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 // @Router /auth/profile [get]
 func (h *AuthHandler) GetProfile(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
 	}
 
-	user, err := h.authService.GetUserByID(userID.(uuid.UUID))
+	user, err := h.authService.GetUserByID(c.Request.Context(), userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get user profile", err)
 		return
@@ -235,7 +316,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 // @Router /auth/profile [put]
 func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
@@ -247,7 +328,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	updatedProfile, err := h.authService.UpdateProfile(userID.(uuid.UUID), &req)
+	updatedProfile, err := h.authService.UpdateProfile(c.Request.Context(), userID, &req)
 	if err != nil {
 		utils.BadRequestErrorResponse(c, "Failed to update profile", err)
 		return
@@ -256,9 +337,43 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", updatedProfile)
 }
 
+// PatchProfile godoc
+// @Summary Complete user profile
+// @Description Fills in or changes only the profile fields present in the request, so users who registered with an email and password can complete their profile step by step. The response's profile_complete and missing_profile_fields tell the frontend what to prompt for next; actions such as running an organization are rejected with error code PROFILE_INCOMPLETE until the profile is complete.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.PatchProfileRequest true "Profile fields to update"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.UserProfileResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/profile [patch]
+func (h *AuthHandler) PatchProfile(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.PatchProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	profile, err := h.authService.PatchProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to update profile", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Profile updated successfully", profile)
+}
+
 // ChangePassword godoc
 // @Summary Change user password
-// @Description Change authenticated user's password
+// @Description Change authenticated user's password. Signs out every session, revoking refresh and access tokens.
 // @Tags auth
 // @Accept json
 // @Produce json
@@ -271,7 +386,7 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 // @Router /auth/change-password [post]
 func (h *AuthHandler) ChangePassword(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
@@ -283,8 +398,13 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	err := h.authService.ChangePassword(userID.(uuid.UUID), &req)
+	err := h.authService.ChangePassword(c.Request.Context(), userID, &req)
 	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			utils.HandleAppError(c, appErr)
+			return
+		}
 		utils.BadRequestErrorResponse(c, "Failed to change password", err)
 		return
 	}
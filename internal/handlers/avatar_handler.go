@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvatarHandler struct {
+	avatarService *services.AvatarService
+}
+
+func NewAvatarHandler(avatarService *services.AvatarService) *AvatarHandler {
+	return &AvatarHandler{avatarService: avatarService}
+}
+
+// UploadAvatar godoc
+// @Summary Upload avatar
+// @Description Replaces the signed-in user's avatar. The image is center-cropped to a square and stored in 64, 128 and 256 pixel sizes, whose URLs are returned as avatar_urls in the profile and in organization member lists. Accepts PNG, JPEG or GIF images of at least 64x64 pixels and at most IMAGE_PROXY_MAX_BYTES.
+// @Tags auth
+// @Accept multipart/form-data
+// @Produce json
+// @Param avatar formData file true "Avatar image"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.UserProfileResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/avatar [put]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Avatar file is required", err)
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to read avatar", err)
+		return
+	}
+	defer file.Close()
+
+	profile, err := h.avatarService.Upload(c.Request.Context(), userID, file)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to upload avatar", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Avatar uploaded successfully", profile)
+}
+
+// DeleteAvatar godoc
+// @Summary Delete avatar
+// @Description Removes the signed-in user's avatar
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.UserProfileResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /auth/avatar [delete]
+func (h *AvatarHandler) DeleteAvatar(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	profile, err := h.avatarService.Delete(c.Request.Context(), userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to delete avatar", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Avatar deleted successfully", profile)
+}
+
+// GetUserAvatar godoc
+// @Summary Get a user's avatar
+// @Description Serves a user's square avatar as PNG or JPEG with cache headers. Use the URLs from avatar_urls, which change with every upload.
+// @Tags images
+// @Produce png
+// @Produce jpeg
+// @Param id path string true "User ID"
+// @Param size query int false "Size in pixels (64, 128 or 256, default 128)"
+// @Success 200 {file} file
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /images/users/{id}/avatar [get]
+func (h *AvatarHandler) GetUserAvatar(c *gin.Context) {
+	userID := middleware.UUIDParam(c, "id")
+
+	size := models.DefaultAvatarSize
+	if raw := c.Query("size"); raw != "" {
+		var err error
+		if size, err = strconv.Atoi(raw); err != nil || !slices.Contains(models.AvatarSizes, size) {
+			utils.BadRequestErrorResponse(c, fmt.Sprintf("Size must be one of %v", models.AvatarSizes), err)
+			return
+		}
+	}
+
+	image, err := h.avatarService.Avatar(c.Request.Context(), userID, size)
+	serveImage(c, image, err)
+}
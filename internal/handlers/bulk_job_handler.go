@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BulkJobHandler struct {
+	bulkJobService *services.BulkJobService
+}
+
+func NewBulkJobHandler(bulkJobService *services.BulkJobService) *BulkJobHandler {
+	return &BulkJobHandler{bulkJobService: bulkJobService}
+}
+
+// BulkRevokeTickets godoc
+// @Summary Revoke tickets in bulk
+// @Description Queues a job revoking up to 5000 tickets with the same reason and note, as the single revoke endpoint would, and returns it at once. Follow its progress and each ticket's outcome at GET /organizations/{id}/jobs/{jobId}.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.BulkRevokeTicketsRequest true "Tickets, reason and internal note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 202 {object} utils.Response{data=models.BulkJob}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/bulk/tickets/revoke [post]
+func (h *BulkJobHandler) BulkRevokeTickets(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkRevokeTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	job, err := h.bulkJobService.RevokeTickets(c.Request.Context(), orgID, actorID, &req)
+	if err != nil {
+		bulkJobErrorResponse(c, "Failed to queue ticket revocations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Ticket revocations queued", job)
+}
+
+// BulkResendTickets godoc
+// @Summary Resend ticket emails in bulk
+// @Description Queues a job emailing tickets to their attendees again: up to 5000 listed tickets, or every valid and checked-in ticket of an event that is not revoked. Cancelled and revoked tickets are reported as failed items.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.BulkResendTicketsRequest true "Tickets, or an event"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 202 {object} utils.Response{data=models.BulkJob}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/bulk/tickets/resend [post]
+func (h *BulkJobHandler) BulkResendTickets(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkResendTicketsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	job, err := h.bulkJobService.ResendTickets(c.Request.Context(), orgID, actorID, &req)
+	if err != nil {
+		bulkJobErrorResponse(c, "Failed to queue ticket emails", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Ticket emails queued", job)
+}
+
+// BulkIssueComps godoc
+// @Summary Issue comp tickets in bulk
+// @Description Queues a job issuing a comp ticket from a comp allocation to each of up to 5000 recipients and emailing it. The allocation must have a ticket left for every recipient. Each item's result is the issued ticket's ID.
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.BulkIssueCompsRequest true "Allocation and recipients"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response{data=models.BulkJob}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/bulk/comps [post]
+func (h *BulkJobHandler) BulkIssueComps(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkIssueCompsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	job, err := h.bulkJobService.IssueComps(c.Request.Context(), orgID, actorID, &req)
+	if err != nil {
+		bulkJobErrorResponse(c, "Failed to queue comp tickets", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Comp tickets queued", job)
+}
+
+// ListBulkJobs godoc
+// @Summary List bulk jobs
+// @Description Returns a page of the organization's bulk jobs with their progress, newest first, without their items
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param operation query string false "revoke_tickets, resend_tickets or issue_comps"
+// @Param status query string false "pending, running, completed or failed"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.BulkJob,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/jobs [get]
+func (h *BulkJobHandler) ListBulkJobs(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.BulkJobQuery)
+
+	jobs, meta, err := h.bulkJobService.List(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch jobs", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Jobs fetched successfully", jobs, meta)
+}
+
+// GetBulkJob godoc
+// @Summary Get a bulk job
+// @Description Returns a bulk job with its progress (total, processed, succeeded and failed items) and every item in order with its status, result and error. A job is completed once every item was processed, whatever their outcome.
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param jobId path string true "Job ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.BulkJob}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/jobs/{jobId} [get]
+func (h *BulkJobHandler) GetBulkJob(c *gin.Context) {
+	job, err := h.bulkJobService.Get(c.Request.Context(), middleware.UUIDParam(c, "id"), middleware.UUIDParam(c, "jobId"))
+	if err != nil {
+		bulkJobErrorResponse(c, "Failed to fetch job", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Job fetched successfully", job)
+}
+
+func bulkJobErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrBulkJobNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
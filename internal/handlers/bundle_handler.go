@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BundleHandler struct {
+	bundleService *services.BundleService
+}
+
+func NewBundleHandler(bundleService *services.BundleService) *BundleHandler {
+	return &BundleHandler{bundleService: bundleService}
+}
+
+// GetBundle godoc
+// @Summary Get a bundle
+// @Description Returns an active bundle with its events, the share of the bundle price each event's ticket sells at current prices, and how many bundles can still be bought
+// @Tags bundles
+// @Produce json
+// @Param bundleId path string true "Bundle ID"
+// @Success 200 {object} utils.Response{data=models.EventBundleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /bundles/{bundleId} [get]
+func (h *BundleHandler) GetBundle(c *gin.Context) {
+	bundle, err := h.bundleService.GetBundle(c.Request.Context(), middleware.UUIDParam(c, "bundleId"))
+	if err != nil {
+		bundleErrorResponse(c, "Failed to fetch bundle", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bundle fetched successfully", bundle)
+}
+
+// PurchaseBundle godoc
+// @Summary Buy a bundle
+// @Description Places one pending card order per event of the bundle for the signed-in buyer, each priced at the event's share of the bundle price and linked by bundle_purchase_id. Tickets of every event are held, or none when any event is short. Orders left unpaid are cancelled after ORDER_PENDING_TTL; refunding one event's tickets returns that event's share.
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Param bundleId path string true "Bundle ID"
+// @Param request body models.BundlePurchaseRequest true "Quantity"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.BundlePurchaseResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /bundles/{bundleId}/purchase [post]
+func (h *BundleHandler) PurchaseBundle(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.BundlePurchaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	purchase, err := h.bundleService.Purchase(c.Request.Context(), userID, middleware.UUIDParam(c, "bundleId"), &req)
+	if err != nil {
+		bundleErrorResponse(c, "Failed to buy bundle", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Bundle purchased successfully", purchase)
+}
+
+// ListBundles godoc
+// @Summary List an organization's bundles
+// @Description Returns the organization's bundles, active or not, newest first, with their events and availability
+// @Tags bundles
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.EventBundleResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/bundles [get]
+func (h *BundleHandler) ListBundles(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	bundles, err := h.bundleService.ListBundles(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch bundles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bundles fetched successfully", bundles)
+}
+
+// CreateBundle godoc
+// @Summary Create a bundle
+// @Description Creates a bundle, such as a festival pass, selling a ticket to each of two or more of the organization's events at a combined price. Events with reserved seating cannot be bundled. The price is split over the events in proportion to their current ticket prices, or equally when they are free.
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.EventBundleRequest true "Bundle"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.EventBundleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/bundles [post]
+func (h *BundleHandler) CreateBundle(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.EventBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	bundle, err := h.bundleService.CreateBundle(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		bundleErrorResponse(c, "Failed to create bundle", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Bundle created successfully", bundle)
+}
+
+// UpdateBundle godoc
+// @Summary Update a bundle
+// @Description Replaces a bundle's name, description, events and price; set active to false to stop selling it. Orders placed already keep their prices.
+// @Tags bundles
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param bundleId path string true "Bundle ID"
+// @Param request body models.EventBundleRequest true "Bundle"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.EventBundleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/bundles/{bundleId} [put]
+func (h *BundleHandler) UpdateBundle(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.EventBundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	bundle, err := h.bundleService.UpdateBundle(c.Request.Context(), orgID, middleware.UUIDParam(c, "bundleId"), &req)
+	if err != nil {
+		bundleErrorResponse(c, "Failed to update bundle", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Bundle updated successfully", bundle)
+}
+
+func bundleErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrBundleNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{calendarService: calendarService}
+}
+
+// GetEventCalendar godoc
+// @Summary Add an event to a calendar
+// @Description Returns an iCalendar file with the event, for Apple Calendar, Outlook and other calendar apps. It is built from the current event on every request, and marks cancelled events as cancelled. Order responses link to it, next to a Google Calendar link.
+// @Tags events
+// @Produce text/calendar
+// @Param id path int true "Event ID"
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /events/{id}/calendar.ics [get]
+func (h *CalendarHandler) GetEventCalendar(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	calendar, err := h.calendarService.EventCalendar(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrCalendarEventNotFound) {
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to build calendar file", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("event-%d.ics", id)))
+	c.Data(http.StatusOK, services.CalendarContentType+"; charset=utf-8", calendar)
+}
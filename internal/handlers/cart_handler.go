@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CartHandler struct {
+	cartService *services.CartService
+}
+
+func NewCartHandler(cartService *services.CartService) *CartHandler {
+	return &CartHandler{cartService: cartService}
+}
+
+// CreateCart godoc
+// @Summary Hold tickets in a cart
+// @Description Takes the selected tickets off sale for the signed-in buyer for CHECKOUT_CART_TTL and returns them priced. Either every selection is held or, when any is short, none. Lapsed carts are put back on sale by a background sweep. Events with reserved seating cannot be held in carts.
+// @Tags carts
+// @Accept json
+// @Produce json
+// @Param request body models.CartRequest true "Ticket selections"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.CartResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /carts [post]
+func (h *CartHandler) CreateCart(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.CartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	cart, err := h.cartService.CreateCart(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to hold tickets", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tickets held successfully", cart)
+}
+
+// GetCart godoc
+// @Summary Get a cart
+// @Description Returns a cart of the signed-in buyer with its expiry time
+// @Tags carts
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.CartResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /carts/{cartId} [get]
+func (h *CartHandler) GetCart(c *gin.Context) {
+	userID, cartID, ok := cartParams(c)
+	if !ok {
+		return
+	}
+
+	cart, err := h.cartService.GetCart(c.Request.Context(), userID, cartID)
+	if err != nil {
+		h.handleError(c, "Failed to fetch cart", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cart fetched successfully", cart)
+}
+
+// ReleaseCart godoc
+// @Summary Release a cart
+// @Description Empties a cart of the signed-in buyer and puts its tickets back on sale
+// @Tags carts
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /carts/{cartId} [delete]
+func (h *CartHandler) ReleaseCart(c *gin.Context) {
+	userID, cartID, ok := cartParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.cartService.ReleaseCart(c.Request.Context(), userID, cartID); err != nil {
+		h.handleError(c, "Failed to release cart", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Cart released successfully", nil)
+}
+
+// CheckoutCart godoc
+// @Summary Check out a cart
+// @Description Converts a cart of the signed-in buyer into pending card orders, one per event, priced at current prices. The held tickets move to the orders; orders left unpaid are cancelled after ORDER_PENDING_TTL.
+// @Tags carts
+// @Produce json
+// @Param cartId path string true "Cart ID"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.CartCheckoutResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /carts/{cartId}/checkout [post]
+func (h *CartHandler) CheckoutCart(c *gin.Context) {
+	userID, cartID, ok := cartParams(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.cartService.Checkout(c.Request.Context(), userID, cartID)
+	if err != nil {
+		h.handleError(c, "Failed to check out cart", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Cart checked out successfully", resp)
+}
+
+func (h *CartHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrCartNotFound) {
+		utils.NotFoundErrorResponse(c, "Cart not found", err)
+		return
+	}
+	utils.BadRequestErrorResponse(c, message, err)
+}
+
+// cartParams returns the signed-in user and the cart ID of the path, responding when either is missing
+func cartParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	cartID := middleware.UUIDParam(c, "cartId")
+	return userID, cartID, true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateChatWebhook godoc
+// @Summary Add a Slack/Discord webhook
+// @Description Connects a Slack or Discord incoming webhook that receives a message when tickets are sold, an event sells out or a refund is issued. Leave notifications empty to receive all of them.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.ChatWebhookRequest true "Webhook settings"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.ChatWebhookResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/integrations/chat-webhooks [post]
+func (h *IntegrationHandler) CreateChatWebhook(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.ChatWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	webhook, err := h.integrationService.CreateChatWebhook(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to add chat webhook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Chat webhook added successfully", webhook)
+}
+
+// ListChatWebhooks godoc
+// @Summary List Slack/Discord webhooks
+// @Description Lists the chat webhooks receiving sale notifications for the organization
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.ChatWebhookResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/integrations/chat-webhooks [get]
+func (h *IntegrationHandler) ListChatWebhooks(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	webhooks, err := h.integrationService.ListChatWebhooks(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get chat webhooks", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Chat webhooks retrieved successfully", webhooks)
+}
+
+// UpdateChatWebhook godoc
+// @Summary Update a Slack/Discord webhook
+// @Description Replaces the settings of a chat webhook
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param webhookId path string true "Chat webhook ID"
+// @Param request body models.ChatWebhookRequest true "Webhook settings"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.ChatWebhookResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/integrations/chat-webhooks/{webhookId} [put]
+func (h *IntegrationHandler) UpdateChatWebhook(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	webhookID := middleware.UUIDParam(c, "webhookId")
+
+	var req models.ChatWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	webhook, err := h.integrationService.UpdateChatWebhook(c.Request.Context(), orgID, webhookID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to update chat webhook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Chat webhook updated successfully", webhook)
+}
+
+// DeleteChatWebhook godoc
+// @Summary Remove a Slack/Discord webhook
+// @Description Stops sale notifications to a chat webhook
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param webhookId path string true "Chat webhook ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/integrations/chat-webhooks/{webhookId} [delete]
+func (h *IntegrationHandler) DeleteChatWebhook(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	webhookID := middleware.UUIDParam(c, "webhookId")
+
+	if err := h.integrationService.DeleteChatWebhook(c.Request.Context(), orgID, webhookID); err != nil {
+		utils.NotFoundErrorResponse(c, "Chat webhook not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Chat webhook removed successfully", nil)
+}
+
+// TestChatWebhook godoc
+// @Summary Send a test message
+// @Description Queues a test message to a chat webhook so organizers can confirm the channel is connected
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param webhookId path string true "Chat webhook ID"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/integrations/chat-webhooks/{webhookId}/test [post]
+func (h *IntegrationHandler) TestChatWebhook(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	webhookID := middleware.UUIDParam(c, "webhookId")
+
+	if err := h.integrationService.SendTestChatMessage(c.Request.Context(), orgID, webhookID); err != nil {
+		utils.NotFoundErrorResponse(c, "Chat webhook not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Test message queued", nil)
+}
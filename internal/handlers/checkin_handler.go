@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CheckInHandler struct {
+	checkInService *services.CheckInService
+}
+
+func NewCheckInHandler(checkInService *services.CheckInService) *CheckInHandler {
+	return &CheckInHandler{checkInService: checkInService}
+}
+
+// CheckIn godoc
+// @Summary Check an attendee in
+// @Description Checks the holder of a ticket in to the event by ticket ID, for staff admitting attendees without a scanner. Requires the ticket scan permission; staff can check in to events of their organization's organizer.
+// @Tags check-ins
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body models.CheckInRequest true "Ticket to check in"
+// @Param X-Device-ID header string false "Stable identifier of the staff device checking in, for the scan audit trail"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.CheckIn}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/checkins [post]
+func (h *CheckInHandler) CheckIn(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.CheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	checkIn, err := h.checkInService.CheckIn(c.Request.Context(), uint(eventID), userID, contextRoles(c), scanDevice(c), &req)
+	if err != nil {
+		h.handleError(c, "Failed to check in attendee", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Attendee checked in successfully", checkIn)
+}
+
+// UndoCheckIn godoc
+// @Summary Undo a check-in
+// @Description Returns a checked-in ticket to valid, e.g. after it was scanned by mistake. The check-in is kept, marked as undone.
+// @Tags check-ins
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param ticketId path string true "Ticket ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/checkins/{ticketId} [delete]
+func (h *CheckInHandler) UndoCheckIn(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	ticketID := middleware.UUIDParam(c, "ticketId")
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	if err := h.checkInService.UndoCheckIn(c.Request.Context(), uint(eventID), userID, contextRoles(c), ticketID); err != nil {
+		h.handleError(c, "Failed to undo check-in", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Check-in undone successfully", nil)
+}
+
+// GetStats godoc
+// @Summary Get an event's attendance
+// @Description Returns live attendance of the event counted from its tickets: tickets issued, attendees checked in and not arrived yet, undone check-ins and check-ins per gate
+// @Tags check-ins
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AttendanceStats}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/events/{id}/checkins/stats [get]
+func (h *CheckInHandler) GetStats(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	stats, err := h.checkInService.Stats(c.Request.Context(), uint(eventID), userID, contextRoles(c))
+	if err != nil {
+		h.handleError(c, "Failed to fetch attendance", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendance fetched successfully", stats)
+}
+
+func (h *CheckInHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrEventAccessDenied):
+		utils.ForbiddenErrorResponse(c, message, err)
+	case errors.Is(err, services.ErrTicketNotFound):
+		utils.NotFoundErrorResponse(c, "Ticket not found", err)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
+
+// scanDevice returns what scanned the codes of a request: the paired scanner ScannerOrStaffAuth
+// authenticated, or the staff device named in the X-Device-ID header
+func scanDevice(c *gin.Context) models.ScanDevice {
+	device := models.ScanDevice{DeviceID: strings.TrimSpace(c.GetHeader("X-Device-ID"))}
+	if len(device.DeviceID) > 100 {
+		device.DeviceID = device.DeviceID[:100]
+	}
+	if id, ok := c.Get("scannerDeviceID"); ok {
+		if id, ok := id.(uuid.UUID); ok {
+			device.ScannerDeviceID = &id
+		}
+	}
+	return device
+}
+
+// contextRoles returns the role names AuthMiddleware put in the context
+func contextRoles(c *gin.Context) []string {
+	roles, _ := c.Get("roles")
+	names, _ := roles.([]string)
+	return names
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CheckoutHandler struct {
+	checkoutService *services.CheckoutService
+}
+
+func NewCheckoutHandler(checkoutService *services.CheckoutService) *CheckoutHandler {
+	return &CheckoutHandler{checkoutService: checkoutService}
+}
+
+// StartCheckout godoc
+// @Summary Start a checkout session
+// @Description Saves the buyer's ticket selections and contact details so the checkout can be resumed later, and returns them priced with the resume token. Buyers who opted in get one reminder email if they have not ordered within CHECKOUT_REMINDER_DELAY while the tickets are still available. Signed-in buyers are linked to the session.
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param request body models.CheckoutSessionRequest true "Selections and contact details"
+// @Success 201 {object} utils.Response{data=models.CheckoutSessionResponse}
+// @Failure 400 {object} utils.Response
+// @Router /checkout/sessions [post]
+func (h *CheckoutHandler) StartCheckout(c *gin.Context) {
+	var req models.CheckoutSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	var userID *uuid.UUID
+	if id, exists := middleware.CurrentUserID(c); exists {
+		userID = &id
+	}
+
+	session, err := h.checkoutService.StartSession(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to start checkout", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Checkout started successfully", session)
+}
+
+// ResumeCheckout godoc
+// @Summary Resume a checkout session
+// @Description Returns a saved checkout with its selections priced at current prices. When they can no longer be bought, e.g. because they sold out, the quote is omitted and quote_error explains why. Sessions are marked completed once the buyer orders any of the selected events.
+// @Tags checkout
+// @Produce json
+// @Param token path string true "Resume token"
+// @Success 200 {object} utils.Response{data=models.CheckoutSessionResponse}
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /checkout/sessions/{token} [get]
+func (h *CheckoutHandler) ResumeCheckout(c *gin.Context) {
+	session, err := h.checkoutService.ResumeSession(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrCheckoutSessionNotFound) {
+			utils.NotFoundErrorResponse(c, "Checkout not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to resume checkout", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Checkout retrieved successfully", session)
+}
+
+// UpdateCheckout godoc
+// @Summary Update a checkout session
+// @Description Replaces the selections and contact details of an open checkout as the buyer progresses, and returns them priced
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param token path string true "Resume token"
+// @Param request body models.CheckoutSessionRequest true "Selections and contact details"
+// @Success 200 {object} utils.Response{data=models.CheckoutSessionResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /checkout/sessions/{token} [put]
+func (h *CheckoutHandler) UpdateCheckout(c *gin.Context) {
+	var req models.CheckoutSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	session, err := h.checkoutService.UpdateSession(c.Request.Context(), c.Param("token"), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrCheckoutSessionNotFound) {
+			utils.NotFoundErrorResponse(c, "Checkout not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to update checkout", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Checkout updated successfully", session)
+}
+
+// HoldCheckoutSeats godoc
+// @Summary Select seats in a checkout
+// @Description Holds seats of an event with a seat map for the checkout for CHECKOUT_SEAT_HOLD_TTL, at most one per ticket selected for the event. The selection replaces the seats held for the event before; an empty one releases them. When any seat is taken none are held and the previous selection stays.
+// @Tags checkout
+// @Accept json
+// @Produce json
+// @Param token path string true "Resume token"
+// @Param request body models.SeatHoldRequest true "Selected seats"
+// @Success 200 {object} utils.Response{data=models.SeatHoldResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /checkout/sessions/{token}/seats [put]
+func (h *CheckoutHandler) HoldCheckoutSeats(c *gin.Context) {
+	var req models.SeatHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	hold, err := h.checkoutService.HoldSeats(c.Request.Context(), c.Param("token"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCheckoutSessionNotFound):
+			utils.NotFoundErrorResponse(c, "Checkout not found", err)
+		case errors.Is(err, services.ErrSeatsUnavailable):
+			utils.ConflictErrorResponse(c, "Seats are not available", err)
+		default:
+			utils.BadRequestErrorResponse(c, "Failed to select seats", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Seats held successfully", hold)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ConfirmationEmailHandler struct {
+	confirmationEmailService *services.ConfirmationEmailService
+}
+
+func NewConfirmationEmailHandler(confirmationEmailService *services.ConfirmationEmailService) *ConfirmationEmailHandler {
+	return &ConfirmationEmailHandler{confirmationEmailService: confirmationEmailService}
+}
+
+// GetConfirmationEmail godoc
+// @Summary Get an event's confirmation email content
+// @Description Returns the content blocks appended to the event's ticket confirmation emails, such as directions, door times or prohibited items
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.ConfirmationEmailResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /events/{id}/confirmation-email [get]
+func (h *ConfirmationEmailHandler) GetConfirmationEmail(c *gin.Context) {
+	id, userID, ok := confirmationEmailParams(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.confirmationEmailService.Get(c.Request.Context(), id, userID, contextRoles(c))
+	if err != nil {
+		confirmationEmailErrorResponse(c, "Failed to fetch confirmation email content", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Confirmation email content fetched successfully", content)
+}
+
+// UpdateConfirmationEmail godoc
+// @Summary Set an event's confirmation email content
+// @Description Replaces the content blocks appended to the event's ticket confirmation emails. Content is HTML reduced to basic formatting: paragraphs, headings, lists, emphasis and http(s), mailto and tel links are kept, other elements are removed with their text kept, and scripts, styles and attributes are dropped. The response holds the blocks as saved; tickets sent from then on carry them. An empty list removes every block.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body models.ConfirmationEmailRequest true "Content blocks"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.ConfirmationEmailResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /events/{id}/confirmation-email [put]
+func (h *ConfirmationEmailHandler) UpdateConfirmationEmail(c *gin.Context) {
+	id, userID, ok := confirmationEmailParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmationEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	content, err := h.confirmationEmailService.Update(c.Request.Context(), id, userID, contextRoles(c), &req)
+	if err != nil {
+		confirmationEmailErrorResponse(c, "Failed to save confirmation email content", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Confirmation email content saved successfully", content)
+}
+
+// PreviewConfirmationEmail godoc
+// @Summary Preview an event's confirmation email
+// @Description Renders the event's ticket confirmation email for a sample attendee with the given content blocks, sanitized as they would be saved, without saving them
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body models.ConfirmationEmailRequest true "Content blocks"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.ConfirmationEmailPreview}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /events/{id}/confirmation-email/preview [post]
+func (h *ConfirmationEmailHandler) PreviewConfirmationEmail(c *gin.Context) {
+	id, userID, ok := confirmationEmailParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ConfirmationEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	preview, err := h.confirmationEmailService.Preview(c.Request.Context(), id, userID, contextRoles(c), &req)
+	if err != nil {
+		confirmationEmailErrorResponse(c, "Failed to preview confirmation email", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Confirmation email previewed successfully", preview)
+}
+
+// confirmationEmailParams reads the event ID and the caller, responding with an error when either is missing
+func confirmationEmailParams(c *gin.Context) (uint, uuid.UUID, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return 0, uuid.Nil, false
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return 0, uuid.Nil, false
+	}
+	return uint(id), userID, true
+}
+
+func confirmationEmailErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		utils.NotFoundErrorResponse(c, "Event not found", err)
+	case errors.Is(err, services.ErrEventAccessDenied):
+		utils.ForbiddenErrorResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
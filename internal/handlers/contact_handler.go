@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ContactHandler struct {
+	contactService *services.ContactService
+}
+
+func NewContactHandler(contactService *services.ContactService) *ContactHandler {
+	return &ContactHandler{contactService: contactService}
+}
+
+// ListContacts godoc
+// @Summary List an organization's contacts
+// @Description Returns a page of the unique attendees and buyers across all of the organization's events, with lifetime spend (paid orders net of refunds), orders, tickets, events ticketed and attended, and last activity. Addresses differing only in case or spaces are one contact, and merged addresses count towards the contact they were merged into. Cancelled tickets and orders are left out.
+// @Tags contacts
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param q query string false "Search email addresses and names"
+// @Param sort query string false "last_activity (default), lifetime_spend or events_attended, largest first"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.Contact,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/contacts [get]
+func (h *ContactHandler) ListContacts(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.ContactQuery)
+
+	contacts, meta, err := h.contactService.List(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch contacts", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Contacts fetched successfully", contacts, meta)
+}
+
+// ExportContacts godoc
+// @Summary Export an organization's contacts
+// @Description Downloads every contact matching the search as CSV, in the requested order: email, name, merged addresses separated by semicolons, lifetime spend, orders, tickets, events, events attended, marketing opt-in, first seen and last activity
+// @Tags contacts
+// @Produce text/csv
+// @Param id path string true "Organization ID"
+// @Param q query string false "Search email addresses and names"
+// @Param sort query string false "last_activity (default), lifetime_spend or events_attended, largest first"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/contacts/export [get]
+func (h *ContactHandler) ExportContacts(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.ContactQuery)
+
+	data, err := h.contactService.Export(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to export contacts", err)
+		return
+	}
+
+	filename := fmt.Sprintf("contacts-%s.csv", time.Now().UTC().Format(time.DateOnly))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", data)
+}
+
+// ListContactMerges godoc
+// @Summary List merged contact addresses
+// @Description Returns the addresses merged into other contacts of the organization, by the contact they are part of
+// @Tags contacts
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.ContactMerge}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/contacts/merges [get]
+func (h *ContactHandler) ListContactMerges(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	merges, err := h.contactService.ListMerges(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch contact merges", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact merges fetched successfully", merges)
+}
+
+// MergeContacts godoc
+// @Summary Merge a duplicate contact address
+// @Description Folds the tickets and orders of email into the contact of into_email, such as an attendee's work and personal addresses. Both addresses must hold tickets or have placed orders at the organization. Addresses already merged into email move along with it. Tickets and orders themselves are unchanged.
+// @Tags contacts
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.ContactMergeRequest true "Addresses"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.ContactMerge}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/contacts/merges [post]
+func (h *ContactHandler) MergeContacts(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.ContactMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	merge, err := h.contactService.Merge(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		contactErrorResponse(c, "Failed to merge contacts", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Contacts merged successfully", merge)
+}
+
+// UnmergeContact godoc
+// @Summary Unmerge a contact address
+// @Description Makes a merged address its own contact again
+// @Tags contacts
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param mergeId path string true "Contact merge ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/contacts/merges/{mergeId} [delete]
+func (h *ContactHandler) UnmergeContact(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	if err := h.contactService.Unmerge(c.Request.Context(), orgID, middleware.UUIDParam(c, "mergeId")); err != nil {
+		contactErrorResponse(c, "Failed to unmerge contact", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Contact unmerged successfully", nil)
+}
+
+func contactErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrContactNotFound), errors.Is(err, services.ErrContactMergeNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
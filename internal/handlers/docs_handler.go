@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/openapi"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DocsHandler struct{}
+
+func NewDocsHandler() *DocsHandler {
+	return &DocsHandler{}
+}
+
+// OpenAPISpec serves the OpenAPI 3 document generated from the Swagger annotations (not itself documented)
+func (h *DocsHandler) OpenAPISpec(c *gin.Context) {
+	spec, err := openapi.Spec()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to build OpenAPI document", err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+}
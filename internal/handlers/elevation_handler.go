@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ElevationHandler struct {
+	elevationService *services.ElevationService
+}
+
+func NewElevationHandler(elevationService *services.ElevationService) *ElevationHandler {
+	return &ElevationHandler{elevationService: elevationService}
+}
+
+// RequestElevation godoc
+// @Summary Request elevated access
+// @Description Requests time-boxed break-glass access for destructive admin actions: deleting events, organizations and tenants, merging accounts and rotating encryption keys. Unless a second admin's approval is required the elevation is active at once and the response carries an access_token with the elevated scope, valid until the elevation expires.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.ElevationRequest true "Reason and duration"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.AdminElevation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/elevations [post]
+func (h *ElevationHandler) RequestElevation(c *gin.Context) {
+	adminID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.ElevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	elevation, err := h.elevationService.RequestElevation(c.Request.Context(), adminID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to request elevation", err)
+		return
+	}
+
+	message := "Elevation granted"
+	if elevation.Status == models.ElevationStatusPendingApproval {
+		message = "Elevation requested, awaiting approval by another admin"
+	}
+	utils.SuccessResponse(c, http.StatusCreated, message, elevation)
+}
+
+// ListElevations godoc
+// @Summary List elevations
+// @Description Returns the most recent elevations of admins, optionally of one admin and/or in one status
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "Admin user ID"
+// @Param status query string false "Status (pending_approval, active, rejected, revoked, expired)"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.AdminElevation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/elevations [get]
+func (h *ElevationHandler) ListElevations(c *gin.Context) {
+	var userID *uuid.UUID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.BadRequestErrorResponse(c, "Invalid user ID", err)
+			return
+		}
+		userID = &id
+	}
+
+	elevations, err := h.elevationService.ListElevations(c.Request.Context(), userID, c.Query("status"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch elevations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Elevations fetched successfully", elevations)
+}
+
+// ApproveElevation godoc
+// @Summary Approve an elevation
+// @Description Grants a pending elevation of another admin for its requested duration, starting now. The requester then fetches the elevated access token.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param elevationId path string true "Elevation ID"
+// @Param request body models.ReviewElevationRequest false "Review note"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AdminElevation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/elevations/{elevationId}/approve [post]
+func (h *ElevationHandler) ApproveElevation(c *gin.Context) {
+	h.reviewElevation(c, true)
+}
+
+// RejectElevation godoc
+// @Summary Reject an elevation
+// @Description Rejects a pending elevation of another admin
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param elevationId path string true "Elevation ID"
+// @Param request body models.ReviewElevationRequest false "Review note"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AdminElevation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/elevations/{elevationId}/reject [post]
+func (h *ElevationHandler) RejectElevation(c *gin.Context) {
+	h.reviewElevation(c, false)
+}
+
+// IssueElevatedToken godoc
+// @Summary Get an elevated access token
+// @Description Returns a new access token with the elevated scope for an active elevation of the signed-in admin, valid until the elevation expires
+// @Tags admin
+// @Produce json
+// @Param elevationId path string true "Elevation ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AdminElevation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/elevations/{elevationId}/token [post]
+func (h *ElevationHandler) IssueElevatedToken(c *gin.Context) {
+	adminID, elevationID, ok := h.elevationParams(c)
+	if !ok {
+		return
+	}
+
+	elevation, err := h.elevationService.IssueToken(c.Request.Context(), elevationID, adminID)
+	if err != nil {
+		h.handleError(c, "Failed to issue elevated token", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Elevated token issued", elevation)
+}
+
+// RevokeElevation godoc
+// @Summary Revoke an elevation
+// @Description Ends a pending or active elevation early; its elevated access tokens stop working at once
+// @Tags admin
+// @Produce json
+// @Param elevationId path string true "Elevation ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.AdminElevation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/elevations/{elevationId} [delete]
+func (h *ElevationHandler) RevokeElevation(c *gin.Context) {
+	adminID, elevationID, ok := h.elevationParams(c)
+	if !ok {
+		return
+	}
+
+	elevation, err := h.elevationService.RevokeElevation(c.Request.Context(), elevationID, adminID)
+	if err != nil {
+		h.handleError(c, "Failed to revoke elevation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Elevation revoked", elevation)
+}
+
+func (h *ElevationHandler) reviewElevation(c *gin.Context, approve bool) {
+	adminID, elevationID, ok := h.elevationParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReviewElevationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
+			return
+		}
+	}
+
+	if !approve {
+		elevation, err := h.elevationService.RejectElevation(c.Request.Context(), elevationID, adminID, req.Note)
+		if err != nil {
+			h.handleError(c, "Failed to reject elevation", err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Elevation rejected", elevation)
+		return
+	}
+
+	elevation, err := h.elevationService.ApproveElevation(c.Request.Context(), elevationID, adminID, req.Note)
+	if err != nil {
+		h.handleError(c, "Failed to approve elevation", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Elevation approved", elevation)
+}
+
+// elevationParams returns the signed-in admin and the elevation of the path, responding when
+// either is missing
+func (h *ElevationHandler) elevationParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	adminID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	elevationID := middleware.UUIDParam(c, "elevationId")
+	return adminID, elevationID, true
+}
+
+func (h *ElevationHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrElevationNotFound) {
+		utils.NotFoundErrorResponse(c, "Elevation not found", err)
+		return
+	}
+	utils.BadRequestErrorResponse(c, message, err)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailHandler struct {
+	emailService *services.EmailService
+}
+
+func NewEmailHandler(emailService *services.EmailService) *EmailHandler {
+	return &EmailHandler{emailService: emailService}
+}
+
+// SendTestEmail godoc
+// @Summary Send a test email of a template
+// @Description Renders an email template with the supplied data and sends it right away to the given address. Every variable the template requires must be in data, unless use_sample_data fills missing ones with placeholders; otherwise the request fails with the missing variables in error.fields. Templates reading fields emails do not provide are rejected the same way. The response lists the template's variables.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.TestEmailRequest true "Template, recipient and data"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.TestEmailResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /admin/emails/test-send [post]
+func (h *EmailHandler) SendTestEmail(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	var req models.TestEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	result, err := h.emailService.SendTestEmail(c.Request.Context(), userID, &req)
+	if err != nil {
+		var appErr *utils.AppError
+		switch {
+		case errors.As(err, &appErr):
+			utils.HandleAppError(c, appErr)
+		case errors.Is(err, services.ErrEmailTemplateNotFound):
+			utils.NotFoundErrorResponse(c, err.Error(), err)
+		default:
+			utils.ServiceUnavailableErrorResponse(c, "Failed to send test email", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Test email sent successfully", result)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EncryptionHandler struct {
+	encryptionService *services.EncryptionService
+}
+
+func NewEncryptionHandler(encryptionService *services.EncryptionService) *EncryptionHandler {
+	return &EncryptionHandler{
+		encryptionService: encryptionService,
+	}
+}
+
+// RotateKeys godoc
+// @Summary Re-encrypt personal data under the active key
+// @Description Queues a background job re-encrypting user personal data stored in plain text or under a retired key. Run it after changing ENCRYPTION_ACTIVE_KEY, and keep retired keys in ENCRYPTION_KEYS until it completes. Requires an elevated access token (see /admin/elevations).
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/encryption/rotate [post]
+func (h *EncryptionHandler) RotateKeys(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	if err := h.encryptionService.QueueKeyRotation(userID); err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to queue key rotation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Key rotation queued", nil)
+}
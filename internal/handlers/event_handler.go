@@ -1,33 +1,41 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"event-ticketing-backend/internal/middleware"
 	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type EventHandler struct {
-	service *services.EventService
+	service      *services.EventService
+	usageService *services.UsageService
 }
 
-func NewEventHandler(service *services.EventService) *EventHandler {
-	return &EventHandler{service: service}
+func NewEventHandler(service *services.EventService, usageService *services.UsageService) *EventHandler {
+	return &EventHandler{service: service, usageService: usageService}
 }
 
 // CreateEvent godoc
 // @Summary Create a new event
-// @Description Create a new event with the provided details
+// @Description Create a new event with the provided details, run by the given organization or else the first one the organizer manages. Events are created as drafts, hidden from listings and sales until published with POST /events/{id}/publish.
 // @Tags events
 // @Accept json
 // @Produce json
 // @Param event body models.EventCreateRequest true "Event details"
 // @Success 201 {object} utils.Response{data=models.Event}
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /api/v1/events [post]
 func (h *EventHandler) CreateEvent(c *gin.Context) {
@@ -37,36 +45,63 @@ func (h *EventHandler) CreateEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.service.CreateEvent(&req)
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	event, err := h.service.CreateEvent(c.Request.Context(), userID, contextRoles(c), &req)
 	if err != nil {
+		if errors.Is(err, services.ErrEventAccessDenied) {
+			utils.ForbiddenErrorResponse(c, "You do not manage this organization", err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to create event", err)
 		return
 	}
 
+	h.usageService.RecordEventCreated(c.Request.Context(), userID)
+
 	utils.SuccessResponse(c, http.StatusCreated, "Event created successfully", event)
 }
 
 // GetAllEvents godoc
 // @Summary Get all events
-// @Description Get a list of all events
+// @Description Get a page of published events, soonest first, optionally filtered by text, category, status, location, organization, start date and price. The response meta holds the total count and the next page.
 // @Tags events
 // @Produce json
-// @Success 200 {object} utils.Response{data=[]models.Event}
+// @Param q query string false "Text in the title, performer or location"
+// @Param category query string false "Category"
+// @Param status query string false "Status (published, paused, cancelled, completed)"
+// @Param location query string false "Text in the location"
+// @Param organization_id query string false "Organization running the events"
+// @Param starts_from query string false "Events starting on or after this day (YYYY-MM-DD)"
+// @Param starts_to query string false "Events starting on or before this day (YYYY-MM-DD)"
+// @Param min_price query number false "Minimum ticket price"
+// @Param max_price query number false "Maximum ticket price"
+// @Param sort query string false "Sort field, prefixed with - for descending order" Enums(start_date, -start_date, price, -price, title, -title, created_at, -created_at)
+// @Param page query int false "Page number, from 1" default(1)
+// @Param limit query int false "Events per page, at most 100" default(20)
+// @Success 200 {object} utils.Response{data=[]models.Event,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /api/v1/events [get]
 func (h *EventHandler) GetAllEvents(c *gin.Context) {
-	events, err := h.service.GetAllEvents()
+	filter := c.MustGet("validatedQuery").(*models.EventFilterQuery)
+
+	events, meta, err := h.service.GetAllEvents(c.Request.Context(), filter)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to fetch events", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "Events fetched successfully", events)
+	utils.PaginatedResponse(c, "Events fetched successfully", events, meta)
 }
 
 // GetEventByID godoc
 // @Summary Get event by ID
-// @Description Get details of a specific event by ID
+// @Description Get details of a specific event by ID, including its refund policy so buyers can see it before purchase
 // @Tags events
 // @Produce json
 // @Param id path int true "Event ID"
@@ -81,7 +116,7 @@ func (h *EventHandler) GetEventByID(c *gin.Context) {
 		return
 	}
 
-	event, err := h.service.GetEventByID(uint(id))
+	event, err := h.service.GetEventByID(c.Request.Context(), uint(id))
 	if err != nil {
 		utils.NotFoundErrorResponse(c, "Event not found", err)
 		return
@@ -92,7 +127,7 @@ func (h *EventHandler) GetEventByID(c *gin.Context) {
 
 // UpdateEvent godoc
 // @Summary Update an event
-// @Description Update event details by ID
+// @Description Update event details by ID. Only admins, the organizer who created the event and organizers of its organization may update it. Each change is recorded in the event's history.
 // @Tags events
 // @Accept json
 // @Produce json
@@ -100,6 +135,7 @@ func (h *EventHandler) GetEventByID(c *gin.Context) {
 // @Param event body models.EventUpdateRequest true "Updated event details"
 // @Success 200 {object} utils.Response{data=models.Event}
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /api/v1/events/{id} [put]
@@ -116,9 +152,22 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 		return
 	}
 
-	event, err := h.service.UpdateEvent(uint(id), &req)
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	event, err := h.service.UpdateEvent(c.Request.Context(), uint(id), userID, contextRoles(c), &req)
 	if err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to update event", err)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to update event", err)
+		}
 		return
 	}
 
@@ -127,12 +176,14 @@ func (h *EventHandler) UpdateEvent(c *gin.Context) {
 
 // DeleteEvent godoc
 // @Summary Delete an event
-// @Description Delete an event by ID
+// @Description Delete an event by ID. Requires an elevated access token (see /admin/elevations).
 // @Tags events
 // @Produce json
 // @Param id path int true "Event ID"
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /api/v1/events/{id} [delete]
 func (h *EventHandler) DeleteEvent(c *gin.Context) {
@@ -142,10 +193,391 @@ func (h *EventHandler) DeleteEvent(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.DeleteEvent(uint(id)); err != nil {
-		utils.InternalServerErrorResponse(c, "Failed to delete event", err)
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	if err := h.service.DeleteEvent(c.Request.Context(), uint(id), userID, contextRoles(c)); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to delete event", err)
+		}
 		return
 	}
 
 	utils.SuccessResponse(c, http.StatusOK, "Event deleted successfully", nil)
 }
+
+// ListOrganizationEvents godoc
+// @Summary List an organization's events
+// @Description Returns the events the organization runs, including unpublished drafts, soonest first
+// @Tags events
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param status query string false "Event status" Enums(draft, published, paused, cancelled, completed)
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.Event}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api/v1/organizations/{id}/events [get]
+func (h *EventHandler) ListOrganizationEvents(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	events, err := h.service.ListOrganizationEvents(c.Request.Context(), orgID, c.Query("status"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch events", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Events fetched successfully", events)
+}
+
+// CreateEventDraft godoc
+// @Summary Start an event draft
+// @Description Creates an empty, unpublished event for the organizer UI to autosave into with PATCH /events/{id}/draft. The event stays hidden from listings and sales until it is published.
+// @Tags events
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.EventDraftResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /api/v1/events/drafts [post]
+func (h *EventHandler) CreateEventDraft(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	event, err := h.service.CreateDraft(c.Request.Context(), userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to create event draft", err)
+		return
+	}
+
+	h.usageService.RecordEventCreated(c.Request.Context(), userID)
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event draft created successfully", models.EventDraftResponse{Event: event, Draft: map[string]interface{}{}})
+}
+
+// GetEventDraft godoc
+// @Summary Get an event draft
+// @Description Returns an event, including unpublished ones, with the changes autosaved for it
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.EventDraftResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/draft [get]
+func (h *EventHandler) GetEventDraft(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	draft, err := h.service.GetDraft(c.Request.Context(), uint(id), userID, contextRoles(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to fetch event draft", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event draft fetched successfully", draft)
+}
+
+// SaveEventDraft godoc
+// @Summary Autosave an event draft
+// @Description Merges a partial payload with any of the event creation fields into the event's draft. Nothing is validated until the draft is published, so the organizer UI can autosave incomplete forms. Fields sent as null are removed from the draft; unknown fields are ignored.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param draft body object true "Partial event fields"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.EventDraftResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/draft [patch]
+func (h *EventHandler) SaveEventDraft(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	var changes map[string]interface{}
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		utils.BadRequestErrorResponse(c, "Draft must be a JSON object", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	draft, err := h.service.SaveDraft(c.Request.Context(), uint(id), userID, contextRoles(c), changes)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to save event draft", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event draft saved successfully", draft)
+}
+
+// PublishEventDraft godoc
+// @Summary Publish an event
+// @Description Validates the event, with its draft applied over its current details, as a complete event and applies it. Drafts and paused events are published and go on sale; published events take over the drafted changes. Cancelled and completed events cannot be published. Validation errors list every invalid field.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/publish [post]
+func (h *EventHandler) PublishEventDraft(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	event, err := h.service.PublishDraft(c.Request.Context(), uint(id), userID, contextRoles(c))
+	if err != nil {
+		var validationErrs validator.ValidationErrors
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		case errors.As(err, &validationErrs):
+			utils.ValidationErrorResponse(c, "Draft is incomplete", err)
+		default:
+			utils.BadRequestErrorResponse(c, "Failed to publish event", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event published successfully", event)
+}
+
+// PauseEvent godoc
+// @Summary Pause an event's ticket sales
+// @Description Moves a published event to paused. It stays listed, but no tickets are sold until it is published again.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/pause [post]
+func (h *EventHandler) PauseEvent(c *gin.Context) {
+	h.transitionEvent(c, h.service.PauseEvent, "Event paused successfully")
+}
+
+// CancelEvent godoc
+// @Summary Cancel an event
+// @Description Moves a draft, published or paused event to cancelled, ending its sales for good. Tickets already sold are not refunded automatically.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/cancel [post]
+func (h *EventHandler) CancelEvent(c *gin.Context) {
+	h.transitionEvent(c, h.service.CancelEvent, "Event cancelled successfully")
+}
+
+// CompleteEvent godoc
+// @Summary Complete an event
+// @Description Moves a published or paused event that has started to completed, ending its sales for good.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/complete [post]
+func (h *EventHandler) CompleteEvent(c *gin.Context) {
+	h.transitionEvent(c, h.service.CompleteEvent, "Event completed successfully")
+}
+
+// transitionEvent moves the event in the path to another status with one of the service's
+// transitions
+func (h *EventHandler) transitionEvent(c *gin.Context, transition func(context.Context, uint, uuid.UUID, []string) (*models.Event, error), message string) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	event, err := transition(c.Request.Context(), uint(id), userID, contextRoles(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		case errors.Is(err, services.ErrEventTransition):
+			utils.BadRequestErrorResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to change event status", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, message, event)
+}
+
+// GetEventHistory godoc
+// @Summary Get an event's change history
+// @Description Lists the recorded versions of an event, newest first, with who made each change and the fields that changed from the version before. Events created before history was kept start with a baseline version recorded on their first change.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.EventVersion}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/history [get]
+func (h *EventHandler) GetEventHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	versions, err := h.service.GetEventHistory(c.Request.Context(), uint(id), userID, contextRoles(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to fetch event history", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event history fetched successfully", versions)
+}
+
+// RollbackEvent godoc
+// @Summary Roll an event back to an earlier version
+// @Description Restores the details the event had at the given version and records the result as a new version. The status is kept, and capacity changes keep the tickets already sold.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param version path int true "Version to restore"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /api/v1/events/{id}/history/{version}/rollback [post]
+func (h *EventHandler) RollbackEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		utils.BadRequestErrorResponse(c, "Invalid version", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	event, err := h.service.RollbackEvent(c.Request.Context(), uint(id), version, userID, contextRoles(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, err.Error(), err)
+		case errors.Is(err, services.ErrEventVersionNotFound):
+			utils.NotFoundErrorResponse(c, "Event version not found", err)
+		default:
+			utils.BadRequestErrorResponse(c, "Failed to roll back event", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event rolled back successfully", event)
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EventTemplateHandler struct {
+	templateService *services.EventTemplateService
+}
+
+func NewEventTemplateHandler(templateService *services.EventTemplateService) *EventTemplateHandler {
+	return &EventTemplateHandler{templateService: templateService}
+}
+
+// SaveEventTemplate godoc
+// @Summary Save an event as a template
+// @Description Saves an event's description, price, capacity, settings and refund policy, together with the organization's price tiers and hold/comp blocks for it, as a reusable template
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.SaveEventTemplateRequest true "Event to save and template name"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.EventTemplate}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/templates [post]
+func (h *EventTemplateHandler) SaveEventTemplate(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.SaveEventTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	template, err := h.templateService.Save(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to save event template", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event template saved successfully", template)
+}
+
+// ListEventTemplates godoc
+// @Summary List event templates
+// @Description Lists the organization's event templates, newest first
+// @Tags templates
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.EventTemplate}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/templates [get]
+func (h *EventTemplateHandler) ListEventTemplates(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	templates, err := h.templateService.List(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get event templates", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event templates retrieved successfully", templates)
+}
+
+// DeleteEventTemplate godoc
+// @Summary Delete an event template
+// @Description Deletes a template; events already created from it are not affected
+// @Tags templates
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param templateId path string true "Template ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/templates/{templateId} [delete]
+func (h *EventTemplateHandler) DeleteEventTemplate(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	templateID := middleware.UUIDParam(c, "templateId")
+
+	if err := h.templateService.Delete(c.Request.Context(), orgID, templateID); err != nil {
+		if errors.Is(err, services.ErrEventTemplateNotFound) {
+			utils.NotFoundErrorResponse(c, "Event template not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to delete event template", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event template deleted successfully", nil)
+}
+
+// InstantiateEventTemplate godoc
+// @Summary Create an event from a template
+// @Description Creates an event from the template starting at the given date. The end date follows from the template's duration, date price tiers keep their distance to the start, and the hold/comp blocks are reserved for the organization. Title, location and performer can be overridden.
+// @Tags templates
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param templateId path string true "Template ID"
+// @Param request body models.InstantiateEventTemplateRequest true "Start date and overrides"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.Event}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/templates/{templateId}/instantiate [post]
+func (h *EventTemplateHandler) InstantiateEventTemplate(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	templateID := middleware.UUIDParam(c, "templateId")
+
+	var req models.InstantiateEventTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	event, err := h.templateService.Instantiate(c.Request.Context(), orgID, templateID, userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrEventTemplateNotFound) {
+			utils.NotFoundErrorResponse(c, "Event template not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to create event from template", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event created successfully", event)
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeedHandler struct {
+	feedService *services.FeedService
+}
+
+func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
+	return &FeedHandler{feedService: feedService}
+}
+
+// GetEventsFeed godoc
+// @Summary Get the Atom feed of published events
+// @Description Returns upcoming published events as an Atom feed, newest first, so aggregators and newsletters can syndicate new events. The feed can be narrowed to a category or to the events of one organizer. It is cached and rebuilt as soon as an event is published, updated or deleted; readers can poll it with the ETag.
+// @Tags public
+// @Produce application/atom+xml
+// @Param category query string false "Event category"
+// @Param organizer query string false "Organizer user ID"
+// @Param X-Tenant header string false "Tenant slug"
+// @Success 200 {object} models.AtomFeed
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /public/events.atom [get]
+func (h *FeedHandler) GetEventsFeed(c *gin.Context) {
+	var filter models.EventFeedFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid filter", err)
+		return
+	}
+
+	body, err := h.feedService.EventsFeed(c.Request.Context(), &filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to build event feed", err)
+		return
+	}
+
+	// Feeds change as soon as an event does, so readers revalidate with the ETag on every poll
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	c.Header("Cache-Control", "public, no-cache")
+	c.Header("Vary", "X-Tenant, Origin")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/atom+xml; charset=utf-8", body)
+}
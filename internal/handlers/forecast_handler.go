@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForecastHandler struct {
+	forecastService *services.ForecastService
+}
+
+func NewForecastHandler(forecastService *services.ForecastService) *ForecastHandler {
+	return &ForecastHandler{forecastService: forecastService}
+}
+
+// GetEventForecast godoc
+// @Summary Get an event's sales forecast
+// @Description Estimates when the event sells out and how many people will attend, from the sales curves and check-in rates of past events with similar capacity and price. Falls back to the event's own sales pace when there is no comparable history. Forecasts are refreshed periodically and may be up to FORECAST_CACHE_TTL old.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.EventForecast}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /events/{id}/forecast [get]
+func (h *ForecastHandler) GetEventForecast(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	forecast, err := h.forecastService.Forecast(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrForecastEventNotFound) {
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to forecast event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Forecast retrieved successfully", forecast)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FranchiseHandler struct {
+	franchiseService *services.FranchiseService
+}
+
+func NewFranchiseHandler(franchiseService *services.FranchiseService) *FranchiseHandler {
+	return &FranchiseHandler{franchiseService: franchiseService}
+}
+
+// PushFranchiseEvent godoc
+// @Summary Push a template event to child organizations
+// @Description Copies an event, with its branding, refund policy and the organization's hold and comp blocks for it, to child organizations as drafts they can localize and publish. Admins may push to any organization. Children that already hold an unpublished draft of the event are skipped.
+// @Tags franchise
+// @Accept json
+// @Produce json
+// @Param id path string true "Parent organization ID"
+// @Param request body models.PushFranchiseEventRequest true "Template event and child organizations"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=[]models.FranchiseEvent}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/franchise/push [post]
+func (h *FranchiseHandler) PushFranchiseEvent(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.PushFranchiseEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	roles, _ := c.Get("roles")
+	userRoles, _ := roles.([]string)
+	isAdmin := slices.Contains(userRoles, "admin")
+
+	drafts, err := h.franchiseService.Push(c.Request.Context(), orgID, userID, isAdmin, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to push event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Event pushed successfully", drafts)
+}
+
+// ListFranchiseEvents godoc
+// @Summary List events pushed to the organization
+// @Description Lists template events pushed to the organization by its franchise parent or an admin
+// @Tags franchise
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param status query string false "Filter by status" Enums(draft, published)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.FranchiseEvent}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/franchise/events [get]
+func (h *FranchiseHandler) ListFranchiseEvents(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	status := c.Query("status")
+	if status != "" && status != string(models.FranchiseEventDraft) && status != string(models.FranchiseEventPublished) {
+		utils.BadRequestErrorResponse(c, "Invalid status", nil)
+		return
+	}
+
+	events, err := h.franchiseService.List(c.Request.Context(), orgID, status)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get franchise events", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Franchise events retrieved successfully", events)
+}
+
+// LocalizeFranchiseEvent godoc
+// @Summary Localize a pushed event
+// @Description Adapts a draft's title, description, venue and dates before publishing. Empty fields are left unchanged.
+// @Tags franchise
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param franchiseEventId path string true "Franchise event ID"
+// @Param request body models.LocalizeFranchiseEventRequest true "Localized fields"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.FranchiseEvent}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/franchise/events/{franchiseEventId} [put]
+func (h *FranchiseHandler) LocalizeFranchiseEvent(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	franchiseEventID := middleware.UUIDParam(c, "franchiseEventId")
+
+	var req models.LocalizeFranchiseEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	event, err := h.franchiseService.Localize(c.Request.Context(), orgID, franchiseEventID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrFranchiseEventNotFound) {
+			utils.NotFoundErrorResponse(c, "Franchise event not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to localize event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event localized successfully", event)
+}
+
+// PublishFranchiseEvent godoc
+// @Summary Publish a pushed event
+// @Description Publishes a draft as a live event of the organization and recreates the template's hold and comp blocks as the organization's allocations
+// @Tags franchise
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param franchiseEventId path string true "Franchise event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.FranchiseEvent}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/franchise/events/{franchiseEventId}/publish [post]
+func (h *FranchiseHandler) PublishFranchiseEvent(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	franchiseEventID := middleware.UUIDParam(c, "franchiseEventId")
+
+	event, err := h.franchiseService.Publish(c.Request.Context(), orgID, franchiseEventID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrFranchiseEventNotFound) {
+			utils.NotFoundErrorResponse(c, "Franchise event not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to publish event", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Event published successfully", event)
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImageHandler struct {
+	imageService *services.ImageProxyService
+}
+
+func NewImageHandler(imageService *services.ImageProxyService) *ImageHandler {
+	return &ImageHandler{
+		imageService: imageService,
+	}
+}
+
+// GetOrganizationLogo godoc
+// @Summary Get an organization's logo
+// @Description Serves the organization's logo resized and re-encoded as PNG or JPEG with cache headers, for use in emails and wallet passes instead of hotlinking the organizer's site
+// @Tags images
+// @Produce png
+// @Produce jpeg
+// @Param id path string true "Organization ID"
+// @Param w query int false "Maximum width in pixels (16-1200, default 200)"
+// @Success 200 {file} file
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 502 {object} utils.Response
+// @Router /images/organizations/{id}/logo [get]
+func (h *ImageHandler) GetOrganizationLogo(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	width, ok := imageWidth(c, services.DefaultLogoWidth)
+	if !ok {
+		return
+	}
+
+	image, err := h.imageService.OrganizationLogo(c.Request.Context(), orgID, width)
+	serveImage(c, image, err)
+}
+
+// GetEventCover godoc
+// @Summary Get an event's cover image
+// @Description Serves the event's cover image resized and re-encoded as PNG or JPEG with cache headers, for use in emails and wallet passes instead of hotlinking the organizer's site
+// @Tags images
+// @Produce png
+// @Produce jpeg
+// @Param id path int true "Event ID"
+// @Param w query int false "Maximum width in pixels (16-1200, default 600)"
+// @Success 200 {file} file
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 502 {object} utils.Response
+// @Router /images/events/{id}/cover [get]
+func (h *ImageHandler) GetEventCover(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+	width, ok := imageWidth(c, services.DefaultCoverWidth)
+	if !ok {
+		return
+	}
+
+	image, err := h.imageService.EventCover(c.Request.Context(), uint(id), width)
+	serveImage(c, image, err)
+}
+
+// imageWidth parses the w query parameter, writing a bad request response when it is invalid
+func imageWidth(c *gin.Context, defaultWidth int) (int, bool) {
+	raw := c.Query("w")
+	if raw == "" {
+		return defaultWidth, true
+	}
+	width, err := strconv.Atoi(raw)
+	if err != nil || width < services.MinImageWidth || width > services.MaxImageWidth {
+		utils.BadRequestErrorResponse(c, fmt.Sprintf("Width must be between %d and %d", services.MinImageWidth, services.MaxImageWidth), err)
+		return 0, false
+	}
+	return width, true
+}
+
+// serveImage writes a proxied image with cache headers, answering conditional requests with 304
+func serveImage(c *gin.Context, image *services.ProxiedImage, err error) {
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImageNotFound):
+			utils.NotFoundErrorResponse(c, "Image not found", err)
+		case errors.Is(err, services.ErrImageUnavailable):
+			utils.ErrorResponse(c, http.StatusBadGateway, "Image could not be loaded", err)
+		default:
+			utils.InternalServerErrorResponse(c, "Failed to load image", err)
+		}
+		return
+	}
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(image.MaxAge.Seconds())))
+	c.Header("ETag", image.ETag)
+	if c.GetHeader("If-None-Match") == image.ETag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, image.ContentType, image.Data)
+}
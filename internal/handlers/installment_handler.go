@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InstallmentHandler struct {
+	installmentService *services.InstallmentService
+}
+
+func NewInstallmentHandler(installmentService *services.InstallmentService) *InstallmentHandler {
+	return &InstallmentHandler{
+		installmentService: installmentService,
+	}
+}
+
+// CreatePlan godoc
+// @Summary Split an order into installments
+// @Description Splits a pending order into scheduled installments charged automatically to the buyer's saved payment method. The first installment is charged immediately and the last must fall before the event starts. Failed charges are retried with dunning emails; orders not fully paid by event start are cancelled and their tickets voided.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Param request body models.InstallmentPlanRequest true "Installment plan"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.InstallmentPlanResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/installment-plan [post]
+func (h *InstallmentHandler) CreatePlan(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	var req models.InstallmentPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	plan, err := h.installmentService.CreatePlan(c.Request.Context(), orgID, orderID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create installment plan", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Installment plan created successfully", plan)
+}
+
+// GetPlan godoc
+// @Summary Get an order's installment plan
+// @Description Returns the installments of an order with their payment status
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.InstallmentPlanResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/installments [get]
+func (h *InstallmentHandler) GetPlan(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	plan, err := h.installmentService.GetPlan(c.Request.Context(), orgID, orderID)
+	if err != nil {
+		utils.NotFoundErrorResponse(c, "Installment plan not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Installment plan retrieved successfully", plan)
+}
@@ -0,0 +1,365 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IntegrationHandler struct {
+	integrationService *services.IntegrationService
+}
+
+func NewIntegrationHandler(cfg *config.Config) *IntegrationHandler {
+	return &IntegrationHandler{
+		integrationService: services.NewIntegrationService(cfg),
+	}
+}
+
+// PollNewOrders godoc
+// @Summary Poll new orders
+// @Description Returns orders created after the given cursor, oldest first. Pass next_cursor from the previous page as "since" to resume.
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param since query string false "Cursor returned by a previous call"
+// @Param limit query int false "Maximum number of items (1-100, default 50)"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PollingPage{items=[]models.OrderResponse}}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/integrations/orders [get]
+func (h *IntegrationHandler) PollNewOrders(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var query models.PollingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	page, err := h.integrationService.ListNewOrders(c.Request.Context(), orgID, &query)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to poll orders", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Orders retrieved successfully", page)
+}
+
+// PollNewAttendees godoc
+// @Summary Poll new attendees
+// @Description Returns attendees (ticket holders) created after the given cursor, oldest first. Pass next_cursor from the previous page as "since" to resume.
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param since query string false "Cursor returned by a previous call"
+// @Param limit query int false "Maximum number of items (1-100, default 50)"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PollingPage{items=[]models.AttendeeResponse}}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/integrations/attendees [get]
+func (h *IntegrationHandler) PollNewAttendees(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var query models.PollingQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	page, err := h.integrationService.ListNewAttendees(c.Request.Context(), orgID, &query)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to poll attendees", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendees retrieved successfully", page)
+}
+
+// SubscribeHook godoc
+// @Summary Subscribe a REST hook
+// @Description Registers a target URL that receives a POST whenever the trigger fires: order.created, attendee.created, ticket.checked_in, event.updated or refund.processed (Zapier REST hook pattern). The response carries the signing secret, shown only once: each delivery is signed in the X-Hook-Signature header as "t=<unix time>,v1=<hex HMAC-SHA256 of time.body>", with the event in X-Hook-Event and the delivery ID in X-Hook-Delivery. Failed deliveries are retried with growing delays; targets answering 410 Gone are unsubscribed automatically. Targets must be http or https URLs resolving to public addresses.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.HookSubscribeRequest true "Subscription data"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.HookSubscriptionResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/integrations/hooks [post]
+func (h *IntegrationHandler) SubscribeHook(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.HookSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	subscription, err := h.integrationService.Subscribe(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		hookErrorResponse(c, "Failed to subscribe hook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Hook subscribed successfully", subscription)
+}
+
+// ListHooks godoc
+// @Summary List REST hooks
+// @Description Lists REST hook subscriptions registered for the organization
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.HookSubscriptionResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/integrations/hooks [get]
+func (h *IntegrationHandler) ListHooks(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	subscriptions, err := h.integrationService.ListSubscriptions(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get hooks", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Hooks retrieved successfully", subscriptions)
+}
+
+// UnsubscribeHook godoc
+// @Summary Unsubscribe a REST hook
+// @Description Removes a REST hook subscription from the organization
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param hookId path string true "Hook subscription ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/integrations/hooks/{hookId} [delete]
+func (h *IntegrationHandler) UnsubscribeHook(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	hookID := middleware.UUIDParam(c, "hookId")
+
+	if err := h.integrationService.Unsubscribe(c.Request.Context(), orgID, hookID); err != nil {
+		hookErrorResponse(c, "Failed to unsubscribe hook", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Hook unsubscribed successfully", nil)
+}
+
+// RotateHookSecret godoc
+// @Summary Rotate a REST hook's signing secret
+// @Description Replaces the secret deliveries of the hook are signed with and returns the new one, shown only once. Hooks subscribed before deliveries were signed get their first secret this way. Deliveries still being retried are signed with the new secret.
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param hookId path string true "Hook subscription ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.HookSubscriptionResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/integrations/hooks/{hookId}/rotate-secret [post]
+func (h *IntegrationHandler) RotateHookSecret(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	subscription, err := h.integrationService.RotateHookSecret(c.Request.Context(), orgID, middleware.UUIDParam(c, "hookId"))
+	if err != nil {
+		hookErrorResponse(c, "Failed to rotate hook secret", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Hook secret rotated successfully", subscription)
+}
+
+// ListHookDeliveries godoc
+// @Summary List REST hook deliveries
+// @Description Returns a page of the organization's hook deliveries, newest first, for debugging endpoints: the payload as sent, the attempts made, and the status, error and duration of the latest attempt. Pending deliveries are queued or waiting for a retry.
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param hook_id query string false "Hook subscription ID"
+// @Param event query string false "Hook event"
+// @Param status query string false "pending, delivered or failed"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.HookDeliveryLog,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/integrations/hooks/deliveries [get]
+func (h *IntegrationHandler) ListHookDeliveries(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.HookDeliveryQuery)
+
+	deliveries, meta, err := h.integrationService.ListHookDeliveries(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch hook deliveries", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Hook deliveries fetched successfully", deliveries, meta)
+}
+
+func hookErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrHookNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	case errors.Is(err, services.ErrHookTargetNotPublic):
+		utils.BadRequestErrorResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
+
+// GetMarketingIntegration godoc
+// @Summary Get marketing contact sync settings
+// @Description Returns the organization's Mailchimp/Brevo contact sync configuration (API key is never returned)
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.MarketingIntegrationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/integrations/marketing [get]
+func (h *IntegrationHandler) GetMarketingIntegration(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	integration, err := h.integrationService.GetMarketingIntegration(c.Request.Context(), orgID)
+	if err != nil {
+		utils.NotFoundErrorResponse(c, "Marketing integration not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Marketing integration retrieved successfully", integration)
+}
+
+// ConfigureMarketingIntegration godoc
+// @Summary Configure marketing contact sync
+// @Description Connects the organization to a Mailchimp audience or Brevo list. Field mapping keys are first_name, last_name, full_name, event_title and event_date; values are provider merge fields/attributes.
+// @Tags integrations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.MarketingIntegrationRequest true "Integration settings"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.MarketingIntegrationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/integrations/marketing [put]
+func (h *IntegrationHandler) ConfigureMarketingIntegration(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.MarketingIntegrationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	integration, err := h.integrationService.ConfigureMarketingIntegration(c.Request.Context(), orgID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to configure marketing integration", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Marketing integration configured successfully", integration)
+}
+
+// DeleteMarketingIntegration godoc
+// @Summary Remove marketing contact sync
+// @Description Disconnects the organization from its Mailchimp/Brevo audience
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/integrations/marketing [delete]
+func (h *IntegrationHandler) DeleteMarketingIntegration(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	if err := h.integrationService.DeleteMarketingIntegration(c.Request.Context(), orgID); err != nil {
+		utils.NotFoundErrorResponse(c, "Marketing integration not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Marketing integration removed successfully", nil)
+}
+
+// SyncMarketingContacts godoc
+// @Summary Sync marketing contacts now
+// @Description Queues a background sync of opted-in attendees to the configured marketing audience
+// @Tags integrations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/integrations/marketing/sync [post]
+func (h *IntegrationHandler) SyncMarketingContacts(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	if _, err := h.integrationService.GetMarketingIntegration(c.Request.Context(), orgID); err != nil {
+		utils.NotFoundErrorResponse(c, "Marketing integration not found", err)
+		return
+	}
+
+	if err := h.integrationService.QueueContactSync(orgID); err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to queue contact sync", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Contact sync queued", nil)
+}
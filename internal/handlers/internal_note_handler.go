@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InternalNoteHandler struct {
+	internalNoteService *services.InternalNoteService
+}
+
+func NewInternalNoteHandler(internalNoteService *services.InternalNoteService) *InternalNoteHandler {
+	return &InternalNoteHandler{internalNoteService: internalNoteService}
+}
+
+// GetOrderAnnotations godoc
+// @Summary Get an order's internal notes and tags
+// @Description Returns the internal tags and notes organization members keep on the order, newest notes first. They are never shown to buyers or attendees.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.InternalAnnotationsResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/annotations [get]
+func (h *InternalNoteHandler) GetOrderAnnotations(c *gin.Context) {
+	h.getAnnotations(c, models.InternalSubjectOrder, "orderId")
+}
+
+// AddOrderNote godoc
+// @Summary Add an internal note to an order
+// @Description Adds a note, such as "refund requested by phone", that only organization members allowed to read notes see
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Param request body models.InternalNoteRequest true "Note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.InternalNote}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/notes [post]
+func (h *InternalNoteHandler) AddOrderNote(c *gin.Context) {
+	h.addNote(c, models.InternalSubjectOrder, "orderId")
+}
+
+// SetOrderTags godoc
+// @Summary Set an order's internal tags
+// @Description Replaces the order's internal tags, such as "vip". Tags are stored lowercase without duplicates; an empty list removes them all.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Param request body models.InternalTagsRequest true "Tags"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.InternalAnnotationsResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/tags [put]
+func (h *InternalNoteHandler) SetOrderTags(c *gin.Context) {
+	h.setTags(c, models.InternalSubjectOrder, "orderId")
+}
+
+// GetAttendeeAnnotations godoc
+// @Summary Get an attendee's internal notes and tags
+// @Description Returns the internal tags and notes organization members keep on the attendee of a ticket, newest notes first. They are never shown to buyers or attendees.
+// @Tags tickets
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param ticketId path string true "Ticket ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.InternalAnnotationsResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/attendees/{ticketId}/annotations [get]
+func (h *InternalNoteHandler) GetAttendeeAnnotations(c *gin.Context) {
+	h.getAnnotations(c, models.InternalSubjectTicket, "ticketId")
+}
+
+// AddAttendeeNote godoc
+// @Summary Add an internal note to an attendee
+// @Description Adds a note on the attendee of a ticket that only organization members allowed to read notes see
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param ticketId path string true "Ticket ID"
+// @Param request body models.InternalNoteRequest true "Note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.InternalNote}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/attendees/{ticketId}/notes [post]
+func (h *InternalNoteHandler) AddAttendeeNote(c *gin.Context) {
+	h.addNote(c, models.InternalSubjectTicket, "ticketId")
+}
+
+// SetAttendeeTags godoc
+// @Summary Set an attendee's internal tags
+// @Description Replaces the internal tags of the attendee of a ticket. Tags are stored lowercase without duplicates; an empty list removes them all.
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param ticketId path string true "Ticket ID"
+// @Param request body models.InternalTagsRequest true "Tags"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.InternalAnnotationsResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/attendees/{ticketId}/tags [put]
+func (h *InternalNoteHandler) SetAttendeeTags(c *gin.Context) {
+	h.setTags(c, models.InternalSubjectTicket, "ticketId")
+}
+
+// DeleteNote godoc
+// @Summary Delete an internal note
+// @Description Deletes an internal note of the organization's orders or attendees
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param noteId path string true "Note ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/notes/{noteId} [delete]
+func (h *InternalNoteHandler) DeleteNote(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	noteID := middleware.UUIDParam(c, "noteId")
+
+	if err := h.internalNoteService.DeleteNote(c.Request.Context(), orgID, noteID); err != nil {
+		internalNoteErrorResponse(c, "Failed to delete note", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Note deleted successfully", nil)
+}
+
+// SearchAnnotations godoc
+// @Summary Search orders and attendees by internal notes and tags
+// @Description Returns a page of the organization's orders and attendees whose notes contain q and that carry tag, most recently annotated first, each with all of its tags and notes
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param q query string false "Text in a note"
+// @Param tag query string false "Tag"
+// @Param subject_type query string false "Only orders or attendees" Enums(order, ticket)
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.InternalAnnotationsResponse,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/annotations [get]
+func (h *InternalNoteHandler) SearchAnnotations(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.InternalAnnotationQuery)
+
+	results, meta, err := h.internalNoteService.Search(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to search notes", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Annotations fetched successfully", results, meta)
+}
+
+func (h *InternalNoteHandler) getAnnotations(c *gin.Context, subjectType models.InternalSubjectType, param string) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	annotations, err := h.internalNoteService.Annotations(c.Request.Context(), orgID, subjectType, middleware.UUIDParam(c, param))
+	if err != nil {
+		internalNoteErrorResponse(c, "Failed to fetch notes", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Annotations fetched successfully", annotations)
+}
+
+func (h *InternalNoteHandler) addNote(c *gin.Context, subjectType models.InternalSubjectType, param string) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.InternalNoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	note, err := h.internalNoteService.AddNote(c.Request.Context(), orgID, userID, subjectType, middleware.UUIDParam(c, param), &req)
+	if err != nil {
+		internalNoteErrorResponse(c, "Failed to add note", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Note added successfully", note)
+}
+
+func (h *InternalNoteHandler) setTags(c *gin.Context, subjectType models.InternalSubjectType, param string) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.InternalTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	annotations, err := h.internalNoteService.SetTags(c.Request.Context(), orgID, userID, subjectType, middleware.UUIDParam(c, param), &req)
+	if err != nil {
+		internalNoteErrorResponse(c, "Failed to set tags", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tags saved successfully", annotations)
+}
+
+func internalNoteErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrInternalSubjectNotFound), errors.Is(err, services.ErrInternalNoteNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryAlertHandler struct {
+	inventoryAlertService *services.InventoryAlertService
+}
+
+func NewInventoryAlertHandler(inventoryAlertService *services.InventoryAlertService) *InventoryAlertHandler {
+	return &InventoryAlertHandler{
+		inventoryAlertService: inventoryAlertService,
+	}
+}
+
+// CreateAlert godoc
+// @Summary Create an inventory alert
+// @Description Notifies the organizer through chat and/or email when the share of an event's capacity sold crosses the threshold (100 = sold out), optionally opening the waitlist
+// @Tags inventory-alerts
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.InventoryAlertRequest true "Alert data"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.InventoryAlertResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/inventory-alerts [post]
+func (h *InventoryAlertHandler) CreateAlert(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.InventoryAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	alert, err := h.inventoryAlertService.CreateAlert(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create inventory alert", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Inventory alert created successfully", alert)
+}
+
+// ListAlerts godoc
+// @Summary List inventory alerts
+// @Description Lists the inventory alerts configured by the organization
+// @Tags inventory-alerts
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.InventoryAlertResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/inventory-alerts [get]
+func (h *InventoryAlertHandler) ListAlerts(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	alerts, err := h.inventoryAlertService.ListAlerts(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get inventory alerts", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Inventory alerts retrieved successfully", alerts)
+}
+
+// DeleteAlert godoc
+// @Summary Delete an inventory alert
+// @Description Removes an inventory alert from the organization
+// @Tags inventory-alerts
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param alertId path string true "Alert ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/inventory-alerts/{alertId} [delete]
+func (h *InventoryAlertHandler) DeleteAlert(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	alertID := middleware.UUIDParam(c, "alertId")
+
+	if err := h.inventoryAlertService.DeleteAlert(c.Request.Context(), orgID, alertID); err != nil {
+		utils.NotFoundErrorResponse(c, "Inventory alert not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Inventory alert deleted successfully", nil)
+}
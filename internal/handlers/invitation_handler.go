@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvitationHandler struct {
+	invitationService *services.InvitationService
+}
+
+func NewInvitationHandler(invitationService *services.InvitationService) *InvitationHandler {
+	return &InvitationHandler{invitationService: invitationService}
+}
+
+// CreateInvitation godoc
+// @Summary Invite someone to an organization
+// @Description Emails an invitation to join the organization with a staff or manager role. The invitee sets their own password when accepting it. Earlier pending invitations of the same email are revoked. Invitations expire after INVITATION_TTL.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.CreateInvitationRequest true "Invitee"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.OrganizationInvitation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/invitations [post]
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.CreateInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	invitation, err := h.invitationService.Invite(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvitationEmailTaken) {
+			utils.ConflictErrorResponse(c, err.Error(), err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to create invitation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Invitation sent successfully", invitation)
+}
+
+// ListInvitations godoc
+// @Summary List an organization's invitations
+// @Description Returns the organization's invitations, newest first
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param status query string false "Invitation status" Enums(pending, accepted, declined, revoked)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.OrganizationInvitation}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/invitations [get]
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	invitations, err := h.invitationService.ListInvitations(c.Request.Context(), orgID, c.Query("status"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to list invitations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invitations retrieved successfully", invitations)
+}
+
+// RevokeInvitation godoc
+// @Summary Revoke an invitation
+// @Description Withdraws a pending invitation so its emailed link no longer works
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param invitationId path string true "Invitation ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.OrganizationInvitation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/invitations/{invitationId} [delete]
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	orgID := middleware.UUIDParam(c, "id")
+	invitationID := middleware.UUIDParam(c, "invitationId")
+
+	invitation, err := h.invitationService.RevokeInvitation(c.Request.Context(), orgID, invitationID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvitationNotFound) {
+			utils.NotFoundErrorResponse(c, "Invitation not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to revoke invitation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invitation revoked successfully", invitation)
+}
+
+// GetInvitation godoc
+// @Summary Get an invitation
+// @Description Returns the organization, role and invitee details of an open invitation, for the page its emailed link opens
+// @Tags invitations
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Success 200 {object} utils.Response{data=models.InvitationPreview}
+// @Failure 404 {object} utils.Response
+// @Router /invitations/{token} [get]
+func (h *InvitationHandler) GetInvitation(c *gin.Context) {
+	preview, err := h.invitationService.PreviewInvitation(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvitationNotFound) {
+			utils.NotFoundErrorResponse(c, "Invitation not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve invitation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invitation retrieved successfully", preview)
+}
+
+// AcceptInvitation godoc
+// @Summary Accept an invitation
+// @Description Creates the invitee's account with the password they chose and adds it to the organization with the invited role. The invitee can sign in right away.
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Param request body models.AcceptInvitationRequest true "Password and optional name corrections"
+// @Success 201 {object} utils.Response{data=models.UserResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /invitations/{token}/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req models.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	user, err := h.invitationService.AcceptInvitation(c.Request.Context(), c.Param("token"), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvitationNotFound):
+			utils.NotFoundErrorResponse(c, "Invitation not found", err)
+		case errors.Is(err, services.ErrInvitationEmailTaken):
+			utils.ConflictErrorResponse(c, err.Error(), err)
+		default:
+			utils.BadRequestErrorResponse(c, "Failed to accept invitation", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Invitation accepted successfully", user)
+}
+
+// DeclineInvitation godoc
+// @Summary Decline an invitation
+// @Description Turns down an open invitation; its link stops working
+// @Tags invitations
+// @Produce json
+// @Param token path string true "Invitation token"
+// @Success 200 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /invitations/{token}/decline [post]
+func (h *InvitationHandler) DeclineInvitation(c *gin.Context) {
+	if err := h.invitationService.DeclineInvitation(c.Request.Context(), c.Param("token")); err != nil {
+		if errors.Is(err, services.ErrInvitationNotFound) {
+			utils.NotFoundErrorResponse(c, "Invitation not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to decline invitation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Invitation declined", nil)
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	jwtService *utils.JWTService
+}
+
+func NewJWKSHandler(cfg *config.Config) *JWKSHandler {
+	return &JWKSHandler{jwtService: utils.NewJWTService(&cfg.JWT)}
+}
+
+// GetJWKS godoc
+// @Summary Get the token signing keys
+// @Description Returns the public keys access tokens are signed with, as a JSON Web Key Set, so other services can validate tokens without calling the API. Tokens name their key in the kid header; keys retired by rotation stay listed until JWT_PREVIOUS_KEYS_FILE drops them. Empty while tokens are signed with a shared secret (JWT_ALGORITHM=HS256).
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.JSONWebKeySet
+// @Failure 500 {object} utils.Response
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	jwks, err := h.jwtService.JWKS()
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to load signing keys", err)
+		return
+	}
+
+	// Validators cache the keys, refetching them when a token names a key they do not know
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
@@ -0,0 +1,398 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// trackingPixel is a transparent 1x1 GIF, served to every open of a tracked email or newsletter
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type NewsletterHandler struct {
+	newsletterService *services.NewsletterService
+}
+
+func NewNewsletterHandler(newsletterService *services.NewsletterService) *NewsletterHandler {
+	return &NewsletterHandler{newsletterService: newsletterService}
+}
+
+// ListNewsletters godoc
+// @Summary List newsletters
+// @Description Returns a page of newsletters, newest first, optionally filtered by status
+// @Tags admin
+// @Produce json
+// @Param status query string false "Status" Enums(draft, scheduled, sending, sent)
+// @Param page query int false "Page number, from 1" default(1)
+// @Param limit query int false "Newsletters per page, at most 100" default(20)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.Newsletter,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/newsletters [get]
+func (h *NewsletterHandler) ListNewsletters(c *gin.Context) {
+	query := c.MustGet("validatedQuery").(*models.NewsletterListQuery)
+
+	newsletters, meta, err := h.newsletterService.ListNewsletters(c.Request.Context(), query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch newsletters", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Newsletters fetched successfully", newsletters, meta)
+}
+
+// CreateNewsletter godoc
+// @Summary Compose a newsletter
+// @Description Saves a newsletter as a draft. The content is HTML; its http(s) links are tracked when sent. Segments: all_users, category_attendees (users with paid orders for events of category) and inactive_users (users who have not signed in for inactive_days, by default NEWSLETTER_INACTIVE_AFTER). Unsubscribed users, unverified addresses and deleted accounts are never included. tenant_id sends it under a tenant's brand.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.NewsletterRequest true "Newsletter"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.Newsletter}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/newsletters [post]
+func (h *NewsletterHandler) CreateNewsletter(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	var req models.NewsletterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	newsletter, err := h.newsletterService.CreateNewsletter(c.Request.Context(), userID, &req)
+	if err != nil {
+		newsletterErrorResponse(c, "Failed to create newsletter", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Newsletter created successfully", newsletter)
+}
+
+// GetNewsletter godoc
+// @Summary Get a newsletter
+// @Description Returns a newsletter. Drafts and scheduled newsletters include the number of users their segment currently selects; newsletters that started sending include their recipients, opens, clicks per link and unsubscribes.
+// @Tags admin
+// @Produce json
+// @Param newsletterId path string true "Newsletter ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.NewsletterResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/newsletters/{newsletterId} [get]
+func (h *NewsletterHandler) GetNewsletter(c *gin.Context) {
+	newsletterID := middleware.UUIDParam(c, "newsletterId")
+
+	newsletter, err := h.newsletterService.GetNewsletter(c.Request.Context(), newsletterID)
+	if err != nil {
+		newsletterErrorResponse(c, "Failed to fetch newsletter", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter fetched successfully", newsletter)
+}
+
+// UpdateNewsletter godoc
+// @Summary Update a newsletter
+// @Description Replaces the content and segment of a newsletter that has not started sending. Scheduled newsletters stay scheduled.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param newsletterId path string true "Newsletter ID"
+// @Param request body models.NewsletterRequest true "Newsletter"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Newsletter}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/newsletters/{newsletterId} [put]
+func (h *NewsletterHandler) UpdateNewsletter(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	newsletterID := middleware.UUIDParam(c, "newsletterId")
+
+	var req models.NewsletterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	newsletter, err := h.newsletterService.UpdateNewsletter(c.Request.Context(), userID, newsletterID, &req)
+	if err != nil {
+		newsletterErrorResponse(c, "Failed to update newsletter", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter updated successfully", newsletter)
+}
+
+// DeleteNewsletter godoc
+// @Summary Delete a draft newsletter
+// @Description Deletes a draft. Scheduled newsletters must be cancelled first.
+// @Tags admin
+// @Produce json
+// @Param newsletterId path string true "Newsletter ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/newsletters/{newsletterId} [delete]
+func (h *NewsletterHandler) DeleteNewsletter(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	newsletterID := middleware.UUIDParam(c, "newsletterId")
+
+	if err := h.newsletterService.DeleteNewsletter(c.Request.Context(), userID, newsletterID); err != nil {
+		newsletterErrorResponse(c, "Failed to delete newsletter", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter deleted successfully", nil)
+}
+
+// ScheduleNewsletter godoc
+// @Summary Schedule a newsletter
+// @Description Schedules a newsletter that has not started sending for send_at, replacing any earlier schedule, or sends it right away without send_at. It goes to the users its segment selects at that time.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param newsletterId path string true "Newsletter ID"
+// @Param request body models.ScheduleNewsletterRequest false "Send time"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Newsletter}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/newsletters/{newsletterId}/schedule [post]
+func (h *NewsletterHandler) ScheduleNewsletter(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	newsletterID := middleware.UUIDParam(c, "newsletterId")
+
+	var req models.ScheduleNewsletterRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
+			return
+		}
+	}
+
+	newsletter, err := h.newsletterService.ScheduleNewsletter(c.Request.Context(), userID, newsletterID, &req)
+	if err != nil {
+		newsletterErrorResponse(c, "Failed to schedule newsletter", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter scheduled successfully", newsletter)
+}
+
+// CancelNewsletter godoc
+// @Summary Cancel a scheduled newsletter
+// @Description Takes a scheduled newsletter back to draft before it starts sending
+// @Tags admin
+// @Produce json
+// @Param newsletterId path string true "Newsletter ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Newsletter}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /admin/newsletters/{newsletterId}/cancel [post]
+func (h *NewsletterHandler) CancelNewsletter(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	newsletterID := middleware.UUIDParam(c, "newsletterId")
+
+	newsletter, err := h.newsletterService.CancelNewsletter(c.Request.Context(), userID, newsletterID)
+	if err != nil {
+		newsletterErrorResponse(c, "Failed to cancel newsletter", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter cancelled successfully", newsletter)
+}
+
+// TrackOpen godoc
+// @Summary Newsletter open pixel
+// @Description Transparent image embedded in each newsletter copy, recording its first open. Always answers with the image.
+// @Tags public
+// @Produce image/gif
+// @Param token path string true "Recipient token from the newsletter"
+// @Success 200 {file} binary
+// @Router /newsletters/t/{token}/open.gif [get]
+func (h *NewsletterHandler) TrackOpen(c *gin.Context) {
+	if err := h.newsletterService.RecordOpen(c.Request.Context(), c.Param("token")); err != nil && !errors.Is(err, utils.ErrInvalidNewsletterToken) {
+		_ = c.Error(err)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/gif", trackingPixel)
+}
+
+// TrackClick godoc
+// @Summary Follow a newsletter link
+// @Description Tracked link of a newsletter copy. Records the click and redirects to the link's URL in the newsletter content.
+// @Tags public
+// @Param token path string true "Recipient token from the newsletter"
+// @Param link path int true "Position of the link in the newsletter"
+// @Success 302
+// @Failure 404 {object} utils.Response
+// @Router /newsletters/t/{token}/click/{link} [get]
+func (h *NewsletterHandler) TrackClick(c *gin.Context) {
+	link, err := strconv.Atoi(c.Param("link"))
+	if err != nil {
+		utils.NotFoundErrorResponse(c, services.ErrNewsletterLinkNotFound.Error(), err)
+		return
+	}
+
+	target, err := h.newsletterService.RecordClick(c.Request.Context(), c.Param("token"), link)
+	if err != nil {
+		newsletterErrorResponse(c, "Failed to follow newsletter link", err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
+
+// Unsubscribe godoc
+// @Summary Unsubscribe from newsletters
+// @Description Unsubscribe link of a newsletter copy. Unsubscribes its recipient from all newsletters. Also answers the one-click POST mail clients send for the List-Unsubscribe header.
+// @Tags public
+// @Produce json
+// @Param token path string true "Recipient token from the newsletter"
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Router /newsletters/t/{token}/unsubscribe [get]
+// @Router /newsletters/t/{token}/unsubscribe [post]
+func (h *NewsletterHandler) Unsubscribe(c *gin.Context) {
+	if err := h.newsletterService.Unsubscribe(c.Request.Context(), c.Param("token")); err != nil {
+		if errors.Is(err, utils.ErrInvalidNewsletterToken) {
+			utils.BadRequestErrorResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to unsubscribe", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "You have been unsubscribed from newsletters", nil)
+}
+
+// GetNewsletterPreference godoc
+// @Summary Get my newsletter subscription
+// @Description Returns whether the caller receives newsletters
+// @Tags me
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.NewsletterPreferenceResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /me/newsletter [get]
+func (h *NewsletterHandler) GetNewsletterPreference(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	preference, err := h.newsletterService.GetPreference(c.Request.Context(), userID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to fetch newsletter subscription", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter subscription fetched successfully", preference)
+}
+
+// UpdateNewsletterPreference godoc
+// @Summary Subscribe to or unsubscribe from newsletters
+// @Description Sets whether the caller receives newsletters. Newsletters already queued for the caller are still delivered.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param request body models.NewsletterPreferenceRequest true "Subscription"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.NewsletterPreferenceResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /me/newsletter [put]
+func (h *NewsletterHandler) UpdateNewsletterPreference(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	var req models.NewsletterPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	preference, err := h.newsletterService.SetPreference(c.Request.Context(), userID, *req.Subscribed)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to update newsletter subscription", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Newsletter subscription updated successfully", preference)
+}
+
+// newsletterErrorResponse answers a failed newsletter operation
+func newsletterErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrNewsletterNotFound), errors.Is(err, services.ErrNewsletterLinkNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	case errors.Is(err, services.ErrNewsletterNotEditable):
+		utils.ConflictErrorResponse(c, err.Error(), err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// GetWhatsAppOptIn godoc
+// @Summary Get my WhatsApp opt-in
+// @Description Returns whether the caller opted in to receiving tickets and event reminders on WhatsApp, and on which number
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.WhatsAppOptInResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /me/whatsapp [get]
+func (h *NotificationHandler) GetWhatsAppOptIn(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	optIn, err := h.notificationService.GetWhatsAppOptIn(c.Request.Context(), userID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to fetch WhatsApp opt-in", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "WhatsApp opt-in fetched successfully", optIn)
+}
+
+// OptInWhatsApp godoc
+// @Summary Opt in to WhatsApp messages
+// @Description Records the caller's consent to receive the tickets of their orders and reminders of their events on WhatsApp at the given number. Opting in again replaces the number.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.WhatsAppOptInRequest true "WhatsApp number"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.WhatsAppOptInResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /me/whatsapp [put]
+func (h *NotificationHandler) OptInWhatsApp(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.WhatsAppOptInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	optIn, err := h.notificationService.OptInWhatsApp(c.Request.Context(), userID, req.Phone)
+	if err != nil {
+		if errors.Is(err, services.ErrWhatsAppUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, "WhatsApp messages are not available", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to opt in to WhatsApp messages", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Opted in to WhatsApp messages", optIn)
+}
+
+// OptOutWhatsApp godoc
+// @Summary Opt out of WhatsApp messages
+// @Description Withdraws the caller's WhatsApp consent and forgets the number. Messages already queued are not sent.
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /me/whatsapp [delete]
+func (h *NotificationHandler) OptOutWhatsApp(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	if err := h.notificationService.OptOutWhatsApp(c.Request.Context(), userID); err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to opt out of WhatsApp messages", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Opted out of WhatsApp messages", nil)
+}
+
+// TrackEmailOpen godoc
+// @Summary Email open pixel
+// @Description Transparent image embedded in tracked reminder emails, counting their opens. Always answers with the image.
+// @Tags public
+// @Produce image/gif
+// @Param token path string true "Tracking token from the email"
+// @Success 200 {file} binary
+// @Router /emails/t/{token}/open.gif [get]
+func (h *NotificationHandler) TrackEmailOpen(c *gin.Context) {
+	if err := h.notificationService.RecordEmailOpen(c.Request.Context(), c.Param("token")); err != nil && !errors.Is(err, utils.ErrInvalidEmailTrackingToken) {
+		_ = c.Error(err)
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "image/gif", trackingPixel)
+}
+
+// TrackEmailClick godoc
+// @Summary Follow a tracked email link
+// @Description Tracked link of a reminder email. Counts the click and redirects to the link's URL, which must be signed for the email.
+// @Tags public
+// @Param token path string true "Tracking token from the email"
+// @Param url query string true "Link URL"
+// @Param sig query string true "Link signature"
+// @Success 302
+// @Failure 404 {object} utils.Response
+// @Router /emails/t/{token}/click [get]
+func (h *NotificationHandler) TrackEmailClick(c *gin.Context) {
+	target, err := h.notificationService.RecordEmailClick(c.Request.Context(), c.Param("token"), c.Query("url"), c.Query("sig"))
+	if err != nil {
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Redirect(http.StatusFound, target)
+}
+
+// GetNotificationStats godoc
+// @Summary Get notification analytics
+// @Description Counts the emails, texts and WhatsApp messages sent for the organization's orders per channel and type: queued, sent and failed, and for tracked reminder emails the share opened and clicked. Notifications are counted by the day they were queued.
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.NotificationStats}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/analytics/notifications [get]
+func (h *NotificationHandler) GetNotificationStats(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var filter models.NotificationStatsFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	stats, err := h.notificationService.OrganizationStats(c.Request.Context(), orgID, &filter)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to get notification analytics", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Notification analytics retrieved successfully", stats)
+}
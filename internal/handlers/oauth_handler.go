@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthBindingCookie holds the binding of the sign-in a browser started, until the callback
+const oauthBindingCookie = "oauth_binding"
+
+type OAuthHandler struct {
+	oauthService  *services.OAuthService
+	countryHeader string
+	stateTTL      time.Duration
+}
+
+func NewOAuthHandler(cfg *config.Config) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService:  services.NewOAuthService(cfg),
+		countryHeader: cfg.Security.CountryHeader,
+		stateTTL:      cfg.OAuth.StateTTL,
+	}
+}
+
+// StartOAuth godoc
+// @Summary Start a social login
+// @Description Redirects to the provider's sign-in page and sets an HttpOnly cookie tying the sign-in to this browser. The provider redirects back to the callback endpoint, which signs the user in. Providers without configured credentials are not available.
+// @Tags auth
+// @Param provider path string true "google, facebook or apple"
+// @Success 302
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /auth/oauth/{provider}/start [get]
+func (h *OAuthHandler) StartOAuth(c *gin.Context) {
+	provider := models.OAuthProvider(c.Param("provider"))
+	authURL, binding, err := h.oauthService.Start(c.Request.Context(), provider)
+	if err != nil {
+		if errors.Is(err, services.ErrOAuthProviderUnavailable) {
+			utils.NotFoundErrorResponse(c, err.Error(), err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to start sign-in", err)
+		return
+	}
+
+	h.setBindingCookie(c, provider, binding, int(h.stateTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// OAuthCallback godoc
+// @Summary Complete a social login
+// @Description Called by the provider after the user signed in (Apple posts a form). Only completes sign-ins started in the same browser, checked with the cookie set by the start endpoint. Links the provider account to the user with the same verified email, or registers a verified user, and issues the same tokens as a password login. When OAUTH_FRONTEND_URL is set, redirects there with the tokens, or an error, in the URL fragment instead of responding with JSON.
+// @Tags auth
+// @Produce json
+// @Param provider path string true "google, facebook or apple"
+// @Param code query string false "Authorization code"
+// @Param state query string false "State from the start endpoint"
+// @Success 200 {object} utils.Response{data=models.TokenResponse}
+// @Success 302
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /auth/oauth/{provider}/callback [get]
+// @Router /auth/oauth/{provider}/callback [post]
+func (h *OAuthHandler) OAuthCallback(c *gin.Context) {
+	provider := models.OAuthProvider(c.Param("provider"))
+
+	var form url.Values
+	if c.Request.Method == http.MethodPost {
+		if err := c.Request.ParseForm(); err != nil {
+			h.callbackError(c, http.StatusBadRequest, "Invalid callback data", err)
+			return
+		}
+		form = c.Request.PostForm
+	} else {
+		form = c.Request.URL.Query()
+	}
+
+	if form.Get("error") != "" {
+		h.callbackError(c, http.StatusUnauthorized, "Sign-in was cancelled", errors.New(form.Get("error")))
+		return
+	}
+
+	client := &models.LoginContext{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		DeviceID:  c.GetHeader("X-Device-ID"),
+		Country:   c.GetHeader(h.countryHeader),
+	}
+
+	// The binding is single use, like the state it belongs to
+	binding, _ := c.Cookie(oauthBindingCookie)
+	h.setBindingCookie(c, provider, "", -1)
+
+	tokens, err := h.oauthService.Callback(c.Request.Context(), provider, form.Get("code"), form.Get("state"), binding, form, client)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOAuthProviderUnavailable):
+			h.callbackError(c, http.StatusNotFound, err.Error(), err)
+		case errors.Is(err, services.ErrOAuthStateInvalid):
+			h.callbackError(c, http.StatusBadRequest, err.Error(), err)
+		default:
+			h.callbackError(c, http.StatusUnauthorized, "Sign-in failed", err)
+		}
+		return
+	}
+
+	if frontendURL := h.oauthService.FrontendURL(); frontendURL != "" {
+		c.Redirect(http.StatusFound, frontendURL+"#"+url.Values{
+			"access_token":  {tokens.AccessToken},
+			"refresh_token": {tokens.RefreshToken},
+		}.Encode())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Login successful", tokens)
+}
+
+// setBindingCookie stores the binding of a started sign-in, or clears it with a negative maxAge.
+// The cookie is only sent to the callback. Apple posts the callback from its own site, which
+// browsers only do with SameSite=None cookies; those must be Secure, so Apple needs an https
+// callback.
+func (h *OAuthHandler) setBindingCookie(c *gin.Context, provider models.OAuthProvider, binding string, maxAge int) {
+	secure := h.oauthService.SecureCookies()
+	sameSite := http.SameSiteLaxMode
+	if provider == models.OAuthProviderApple && secure {
+		sameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     oauthBindingCookie,
+		Value:    binding,
+		Path:     "/api/v1/auth/oauth/" + string(provider) + "/callback",
+		MaxAge:   maxAge,
+		Secure:   secure,
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+}
+
+// callbackError sends a failed sign-in back to the frontend when one is configured, and
+// responds with the error otherwise
+func (h *OAuthHandler) callbackError(c *gin.Context, status int, message string, err error) {
+	if frontendURL := h.oauthService.FrontendURL(); frontendURL != "" {
+		c.Redirect(http.StatusFound, frontendURL+"#"+url.Values{"error": {message}}.Encode())
+		return
+	}
+	utils.ErrorResponse(c, status, message, err)
+}
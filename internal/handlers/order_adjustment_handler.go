@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestOrderAdjustment godoc
+// @Summary Adjust an order
+// @Description Applies a partial refund, price correction or comp conversion to an order. Adjustments at or above the approval threshold are held until a second admin approves them. Partial refunds must respect the event's refund policy: none under a no-refunds policy or after the refund deadline, and no more than the refundable amount less the policy's fee. Every step is recorded in the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param orderId path string true "Order ID"
+// @Param request body models.OrderAdjustmentRequest true "Adjustment"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.OrderAdjustment}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/orders/{orderId}/adjustments [post]
+func (h *OrderHandler) RequestOrderAdjustment(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	var req models.OrderAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	adjustment, err := h.orderService.RequestOrderAdjustment(c.Request.Context(), orderID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to adjust order", err)
+		return
+	}
+
+	message := "Order adjusted successfully"
+	if adjustment.Status == models.AdjustmentStatusPendingApproval {
+		message = "Adjustment submitted for approval"
+	}
+	utils.SuccessResponse(c, http.StatusCreated, message, adjustment)
+}
+
+// ListOrderAdjustments godoc
+// @Summary List an order's adjustments
+// @Description Returns the manual adjustments made to an order
+// @Tags admin
+// @Produce json
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.OrderAdjustment}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/orders/{orderId}/adjustments [get]
+func (h *OrderHandler) ListOrderAdjustments(c *gin.Context) {
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	adjustments, err := h.orderService.ListOrderAdjustments(c.Request.Context(), &orderID, "")
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve adjustments", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Adjustments retrieved successfully", adjustments)
+}
+
+// ListAdjustments godoc
+// @Summary List order adjustments
+// @Description Returns recent order adjustments, e.g. those awaiting a second admin's approval
+// @Tags admin
+// @Produce json
+// @Param status query string false "Filter by status" Enums(pending_approval, applied, rejected, failed)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.OrderAdjustment}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/adjustments [get]
+func (h *OrderHandler) ListAdjustments(c *gin.Context) {
+	adjustments, err := h.orderService.ListOrderAdjustments(c.Request.Context(), nil, c.Query("status"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve adjustments", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Adjustments retrieved successfully", adjustments)
+}
+
+// ApproveAdjustment godoc
+// @Summary Approve an order adjustment
+// @Description Approves and applies an adjustment awaiting approval. The approver must be a different admin from the requester.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param adjustmentId path string true "Adjustment ID"
+// @Param request body models.ReviewAdjustmentRequest false "Review note"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.OrderAdjustment}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/adjustments/{adjustmentId}/approve [post]
+func (h *OrderHandler) ApproveAdjustment(c *gin.Context) {
+	h.reviewAdjustment(c, true)
+}
+
+// RejectAdjustment godoc
+// @Summary Reject an order adjustment
+// @Description Rejects an adjustment awaiting approval; the order is left unchanged
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param adjustmentId path string true "Adjustment ID"
+// @Param request body models.ReviewAdjustmentRequest false "Review note"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.OrderAdjustment}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/adjustments/{adjustmentId}/reject [post]
+func (h *OrderHandler) RejectAdjustment(c *gin.Context) {
+	h.reviewAdjustment(c, false)
+}
+
+// reviewAdjustment handles both approval and rejection of an adjustment
+func (h *OrderHandler) reviewAdjustment(c *gin.Context, approve bool) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	adjustmentID := middleware.UUIDParam(c, "adjustmentId")
+
+	var req models.ReviewAdjustmentRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
+			return
+		}
+	}
+
+	if approve {
+		adjustment, err := h.orderService.ApproveOrderAdjustment(c.Request.Context(), adjustmentID, userID, req.Note)
+		if err != nil {
+			utils.BadRequestErrorResponse(c, "Failed to approve adjustment", err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Adjustment approved and applied", adjustment)
+		return
+	}
+
+	adjustment, err := h.orderService.RejectOrderAdjustment(c.Request.Context(), adjustmentID, userID, req.Note)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to reject adjustment", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Adjustment rejected", adjustment)
+}
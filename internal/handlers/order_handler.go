@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler struct {
+	orderService *services.OrderService
+}
+
+func NewOrderHandler(orderService *services.OrderService) *OrderHandler {
+	return &OrderHandler{
+		orderService: orderService,
+	}
+}
+
+// CreateStaffOrder godoc
+// @Summary Place an order on behalf of an attendee
+// @Description Lets organization staff take a phone or box-office order for a named attendee, paid by invoice or cash. Tickets are emailed to the attendee, and with sms_phone set a link to each ticket is also texted to that number when an SMS provider is configured; invoice orders stay pending until marked paid. With insurance set, ticket insurance is quoted, added to the total and bound once the order is placed.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.StaffOrderRequest true "Order data"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.OrderDetailResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/orders [post]
+func (h *OrderHandler) CreateStaffOrder(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.StaffOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	order, err := h.orderService.CreateStaffOrder(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Order created successfully", order)
+}
+
+// QuoteOrder godoc
+// @Summary Price ticket selections before checkout
+// @Description Returns the full price breakdown of ticket selections at their current prices: the lines, subtotal, discounts, service fees, taxes and total, so frontends can show accurate totals before checkout. Nothing is created or reserved. Only USD pricing is supported.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param request body models.OrderQuoteRequest true "Ticket selections"
+// @Success 200 {object} utils.Response{data=models.OrderQuote}
+// @Failure 400 {object} utils.Response
+// @Router /orders/quote [post]
+func (h *OrderHandler) QuoteOrder(c *gin.Context) {
+	var req models.OrderQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	quote, err := h.orderService.QuoteOrder(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to quote order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order quoted successfully", quote)
+}
+
+// QuoteInsurance godoc
+// @Summary Quote ticket insurance for an order
+// @Description Prices ticket insurance from the insurance provider for tickets of an event at the current price, so staff can offer it before placing the order. The order re-quotes when placed with insurance.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int true "Event ID"
+// @Param quantity query int true "Number of tickets"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.InsuranceQuoteResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /organizations/{id}/orders/insurance-quote [get]
+func (h *OrderHandler) QuoteInsurance(c *gin.Context) {
+	var req models.InsuranceQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	quote, err := h.orderService.QuoteInsurance(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrInsuranceUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, "Ticket insurance is not available", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to quote insurance", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Insurance quoted successfully", quote)
+}
+
+// MarkOrderPaid godoc
+// @Summary Mark an invoice order as paid
+// @Description Records payment of a pending invoice order and emails the buyer a receipt
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.OrderResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/mark-paid [post]
+func (h *OrderHandler) MarkOrderPaid(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	order, err := h.orderService.MarkOrderPaid(c.Request.Context(), orgID, orderID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to mark order as paid", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order marked as paid", order)
+}
+
+// ListOrderNotifications godoc
+// @Summary List an order's ticket deliveries
+// @Description Returns the ticket emails and texts sent for an order, oldest first, with their delivery status: queued, sent or failed
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.NotificationLog}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/notifications [get]
+func (h *OrderHandler) ListOrderNotifications(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	notifications, err := h.orderService.ListOrderNotifications(c.Request.Context(), orgID, orderID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to fetch order notifications", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order notifications fetched successfully", notifications)
+}
+
+// CancelOrder godoc
+// @Summary Cancel a pending order
+// @Description Cancels a pending invoice order, voids its tickets and puts them back on sale
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.OrderResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/cancel [post]
+func (h *OrderHandler) CancelOrder(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	order, err := h.orderService.CancelOrder(c.Request.Context(), orgID, orderID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to cancel order", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Order cancelled", order)
+}
+
+// ResendTickets godoc
+// @Summary Resend the ticket emails of my order
+// @Description Queues the ticket emails of an order placed by or for the signed-in user again, at most ORDER_TICKET_RESEND_LIMIT times per order per hour
+// @Tags orders
+// @Produce json
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response{data=models.TicketResendResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /orders/{orderId}/resend-tickets [post]
+func (h *OrderHandler) ResendTickets(c *gin.Context) {
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	resp, err := h.orderService.ResendTickets(c.Request.Context(), orderID, userID)
+	if err != nil {
+		h.resendError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Ticket emails will be resent shortly", resp)
+}
+
+// AdminResendTickets godoc
+// @Summary Resend the ticket emails of an order
+// @Description Queues the ticket emails of any order again for support cases, sharing the buyer's limit of ORDER_TICKET_RESEND_LIMIT resends per order per hour. Recorded in the audit log.
+// @Tags admin
+// @Produce json
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response{data=models.TicketResendResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /admin/orders/{orderId}/resend-tickets [post]
+func (h *OrderHandler) AdminResendTickets(c *gin.Context) {
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	adminID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	resp, err := h.orderService.AdminResendTickets(c.Request.Context(), orderID, adminID)
+	if err != nil {
+		h.resendError(c, err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Ticket emails will be resent shortly", resp)
+}
+
+func (h *OrderHandler) resendError(c *gin.Context, err error) {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		utils.HandleAppError(c, appErr)
+		return
+	}
+	utils.BadRequestErrorResponse(c, "Failed to resend tickets", err)
+}
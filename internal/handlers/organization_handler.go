@@ -1,15 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
+	"event-ticketing-backend/internal/middleware"
 	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/config"
 	"event-ticketing-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 )
 
 type OrganizationHandler struct {
@@ -25,7 +26,7 @@ func NewOrganizationHandler(cfg *config.Config) *OrganizationHandler {
 
 // CreateOrganization godoc
 // @Summary Create a new organization
-// @Description Creates a new organization with the current user as the organizer
+// @Description Creates a new organization with the current user as the organizer. The organizer's profile must be complete, otherwise the request fails with error code PROFILE_INCOMPLETE.
 // @Tags organizations
 // @Accept json
 // @Produce json
@@ -34,11 +35,12 @@ func NewOrganizationHandler(cfg *config.Config) *OrganizationHandler {
 // @Success 201 {object} utils.Response{data=models.OrganizationResponse}
 // @Failure 400 {object} utils.Response
 // @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
 // @Failure 500 {object} utils.Response
 // @Router /organizations [post]
 func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
@@ -52,8 +54,13 @@ func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
 	}
 
 	// Create organization
-	org, err := h.orgService.CreateOrganization(userID.(uuid.UUID), &req)
+	org, err := h.orgService.CreateOrganization(c.Request.Context(), userID, &req)
 	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			utils.HandleAppError(c, appErr)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to create organization", err)
 		return
 	}
@@ -63,7 +70,7 @@ func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
 
 // CreateOrganizationUser godoc
 // @Summary Create a new user in organization
-// @Description Creates a new user with staff or manager role within the organization
+// @Description Creates a new user with staff or manager role within the organization, with a password the organizer passes on to them; no email is sent. Prefer POST /organizations/{id}/invitations, which lets users choose their own password.
 // @Tags organizations
 // @Accept json
 // @Produce json
@@ -78,18 +85,14 @@ func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
 // @Router /organizations/{id}/users [post]
 func (h *OrganizationHandler) CreateOrganizationUser(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userID, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
 	}
 
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Parse request body
 	var req models.CreateOrgUserRequest
@@ -99,7 +102,7 @@ func (h *OrganizationHandler) CreateOrganizationUser(c *gin.Context) {
 	}
 
 	// Create user
-	user, err := h.orgService.CreateOrgUser(userID.(uuid.UUID), orgID, &req)
+	user, err := h.orgService.CreateOrgUser(c.Request.Context(), userID, orgID, &req)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to create user", err)
 		return
@@ -108,6 +111,43 @@ func (h *OrganizationHandler) CreateOrganizationUser(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusCreated, "Organization user created successfully", user)
 }
 
+// AddOrganizationMember godoc
+// @Summary Add an existing account to an organization
+// @Description Makes an existing account a member of the organization with a staff, manager or organizer role that applies to this organization only. Users can belong to several organizations. Adding a current member changes their role and reactivates them. People without an account are invited instead.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.AddOrganizationMemberRequest true "Member"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.UserResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/members [post]
+func (h *OrganizationHandler) AddOrganizationMember(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.AddOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	user, err := h.orgService.AddOrganizationMember(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to add organization member", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Organization member added successfully", user)
+}
+
 // This duplicate GetUserOrganizations method has been removed to fix compilation errors
 
 // GetOrganizationByID godoc
@@ -126,14 +166,10 @@ func (h *OrganizationHandler) CreateOrganizationUser(c *gin.Context) {
 // @Router /organizations/{id} [get]
 func (h *OrganizationHandler) GetOrganizationByID(c *gin.Context) {
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Get organization
-	org, err := h.orgService.GetOrganizationByID(orgID)
+	org, err := h.orgService.GetOrganizationByID(c.Request.Context(), orgID)
 	if err != nil {
 		utils.NotFoundErrorResponse(c, "Organization not found", err)
 		return
@@ -158,20 +194,16 @@ func (h *OrganizationHandler) GetOrganizationByID(c *gin.Context) {
 // @Router /organizations/{id}/users [get]
 func (h *OrganizationHandler) GetOrganizationUsers(c *gin.Context) {
 	// Check if user is authenticated (auth middleware already handles this)
-	if _, exists := c.Get("userID"); !exists {
+	if _, exists := middleware.CurrentUserID(c); !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
 	}
 
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Get users in organization
-	users, err := h.orgService.GetOrganizationUsers(orgID)
+	users, err := h.orgService.GetOrganizationUsers(c.Request.Context(), orgID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get organization users", err)
 		return
@@ -180,9 +212,67 @@ func (h *OrganizationHandler) GetOrganizationUsers(c *gin.Context) {
 	utils.SuccessResponse(c, http.StatusOK, "Organization users retrieved successfully", users)
 }
 
+// GetChildOrganizations godoc
+// @Summary Get sub-organizations
+// @Description Retrieves the direct sub-organizations of the specified organization
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.OrganizationResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/children [get]
+func (h *OrganizationHandler) GetChildOrganizations(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	children, err := h.orgService.GetChildOrganizations(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get sub-organizations", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Sub-organizations retrieved successfully", children)
+}
+
+// GetOrganizationRollup godoc
+// @Summary Get roll-up sales analytics
+// @Description Sums the paid orders of the organization and all its sub-organizations at any depth, per organization and currency, with per-currency totals for the whole hierarchy. Orders are counted by the day they were paid; cancelled orders are left out, and refunded orders only count towards the refunded amount and its offsetting gross.
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param from query string false "First day (YYYY-MM-DD)"
+// @Param to query string false "Last day (YYYY-MM-DD)"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.OrganizationRollup}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/analytics/rollup [get]
+func (h *OrganizationHandler) GetOrganizationRollup(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var filter models.OrganizationRollupFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	rollup, err := h.orgService.Rollup(c.Request.Context(), orgID, &filter)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to get roll-up analytics", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Roll-up analytics retrieved successfully", rollup)
+}
+
 // UpdateOrganizationUser godoc
 // @Summary Update a user in organization
-// @Description Updates role or status of a user within the organization
+// @Description Updates the role or status of a member within the organization. Both apply to this organization only.
 // @Tags organizations
 // @Accept json
 // @Produce json
@@ -199,18 +289,10 @@ func (h *OrganizationHandler) GetOrganizationUsers(c *gin.Context) {
 // @Router /organizations/{id}/users/{userId} [put]
 func (h *OrganizationHandler) UpdateOrganizationUser(c *gin.Context) {
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Parse user ID
-	userID, err := uuid.Parse(c.Param("userId"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid user ID", err)
-		return
-	}
+	userID := middleware.UUIDParam(c, "userId")
 
 	// Parse request body
 	var req models.UpdateOrgUserRequest
@@ -220,8 +302,12 @@ func (h *OrganizationHandler) UpdateOrganizationUser(c *gin.Context) {
 	}
 
 	// Update user
-	user, err := h.orgService.UpdateOrganizationUser(orgID, userID, &req)
+	user, err := h.orgService.UpdateOrganizationUser(c.Request.Context(), orgID, userID, &req)
 	if err != nil {
+		if errors.Is(err, services.ErrNotOrganizationMember) {
+			utils.NotFoundErrorResponse(c, err.Error(), err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to update organization user", err)
 		return
 	}
@@ -231,7 +317,7 @@ func (h *OrganizationHandler) UpdateOrganizationUser(c *gin.Context) {
 
 // DeleteOrganizationUser godoc
 // @Summary Delete a user from organization
-// @Description Removes a user from the organization
+// @Description Removes a member from the organization along with the custom roles they held in it. Their account and other memberships are kept.
 // @Tags organizations
 // @Accept json
 // @Produce json
@@ -247,21 +333,17 @@ func (h *OrganizationHandler) UpdateOrganizationUser(c *gin.Context) {
 // @Router /organizations/{id}/users/{userId} [delete]
 func (h *OrganizationHandler) DeleteOrganizationUser(c *gin.Context) {
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Parse user ID
-	userID, err := uuid.Parse(c.Param("userId"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid user ID", err)
-		return
-	}
+	userID := middleware.UUIDParam(c, "userId")
 
 	// Delete user from organization
-	if err := h.orgService.DeleteOrganizationUser(orgID, userID); err != nil {
+	if err := h.orgService.DeleteOrganizationUser(c.Request.Context(), orgID, userID); err != nil {
+		if errors.Is(err, services.ErrNotOrganizationMember) {
+			utils.NotFoundErrorResponse(c, err.Error(), err)
+			return
+		}
 		utils.InternalServerErrorResponse(c, "Failed to delete organization user", err)
 		return
 	}
@@ -287,11 +369,7 @@ func (h *OrganizationHandler) DeleteOrganizationUser(c *gin.Context) {
 // @Router /organizations/{id} [put]
 func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Parse request body
 	var req models.UpdateOrganizationRequest
@@ -301,7 +379,7 @@ func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
 	}
 
 	// Update organization
-	org, err := h.orgService.UpdateOrganization(orgID, &req)
+	org, err := h.orgService.UpdateOrganization(c.Request.Context(), orgID, &req)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update organization", err)
 		return
@@ -312,7 +390,7 @@ func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
 
 // DeleteOrganization godoc
 // @Summary Delete an organization
-// @Description Deletes an organization and all associated data
+// @Description Deletes an organization and all associated data. Requires an elevated access token (see /admin/elevations).
 // @Tags organizations
 // @Accept json
 // @Produce json
@@ -327,14 +405,10 @@ func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
 // @Router /organizations/{id} [delete]
 func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "id")
 
 	// Delete organization
-	if err := h.orgService.DeleteOrganization(orgID); err != nil {
+	if err := h.orgService.DeleteOrganization(c.Request.Context(), orgID); err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to delete organization", err)
 		return
 	}
@@ -359,19 +433,14 @@ func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
 // @Router /organizations/{orgId}/users/role [put]
 func (h *OrganizationHandler) UpdateUserRole(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userIDValue, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
 	}
-	userID := userIDValue.(uuid.UUID)
 
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("orgId"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "orgId")
 
 	// Parse request body
 	var req models.UpdateUserRoleRequest
@@ -381,7 +450,7 @@ func (h *OrganizationHandler) UpdateUserRole(c *gin.Context) {
 	}
 
 	// Update role
-	err = h.orgService.UpdateOrgUserRole(userID, orgID, &req)
+	err := h.orgService.UpdateOrgUserRole(c.Request.Context(), userID, orgID, &req)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to update user role", err)
 		return
@@ -405,20 +474,16 @@ func (h *OrganizationHandler) UpdateUserRole(c *gin.Context) {
 // @Router /organizations/{orgId}/users [get]
 func (h *OrganizationHandler) GetOrgUsers(c *gin.Context) {
 	// Check if user is authenticated
-	if _, exists := c.Get("userID"); !exists {
+	if _, exists := middleware.CurrentUserID(c); !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
 	}
 
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("orgId"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "orgId")
 
 	// Get users
-	users, err := h.orgService.GetOrganizationUsers(orgID)
+	users, err := h.orgService.GetOrganizationUsers(c.Request.Context(), orgID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get users", err)
 		return
@@ -439,15 +504,14 @@ func (h *OrganizationHandler) GetOrgUsers(c *gin.Context) {
 // @Router /organizations/mine [get]
 func (h *OrganizationHandler) GetUserOrganizations(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
-	userIDValue, exists := c.Get("userID")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
 		return
 	}
-	userID := userIDValue.(uuid.UUID)
 
 	// Get organizations
-	orgs, err := h.orgService.GetUserOrganizations(userID)
+	orgs, err := h.orgService.GetUserOrganizations(c.Request.Context(), userID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get organizations", err)
 		return
@@ -471,14 +535,10 @@ func (h *OrganizationHandler) GetUserOrganizations(c *gin.Context) {
 // @Router /organizations/{orgId} [get]
 func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
 	// Parse organization ID
-	orgID, err := uuid.Parse(c.Param("orgId"))
-	if err != nil {
-		utils.BadRequestErrorResponse(c, "Invalid organization ID", err)
-		return
-	}
+	orgID := middleware.UUIDParam(c, "orgId")
 
 	// Get organization
-	org, err := h.orgService.GetOrganizationByID(orgID)
+	org, err := h.orgService.GetOrganizationByID(c.Request.Context(), orgID)
 	if err != nil {
 		utils.InternalServerErrorResponse(c, "Failed to get organization", err)
 		return
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationRoleHandler struct {
+	roleService *services.OrganizationRoleService
+}
+
+func NewOrganizationRoleHandler(roleService *services.OrganizationRoleService) *OrganizationRoleHandler {
+	return &OrganizationRoleHandler{roleService: roleService}
+}
+
+// ListAssignablePermissions godoc
+// @Summary List permissions for custom roles
+// @Description Returns the permissions organizers can compose custom organization roles from. Permissions over users and staff are not assignable.
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.PermissionResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/permissions [get]
+func (h *OrganizationRoleHandler) ListAssignablePermissions(c *gin.Context) {
+	permissions, err := h.roleService.ListAssignablePermissions(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch permissions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Permissions fetched successfully", permissions)
+}
+
+// ListRoles godoc
+// @Summary List custom roles
+// @Description Returns the custom roles of an organization with their permissions and the members holding them
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.OrganizationRoleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/roles [get]
+func (h *OrganizationRoleHandler) ListRoles(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	roles, err := h.roleService.ListRoles(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch roles", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Roles fetched successfully", roles)
+}
+
+// CreateRole godoc
+// @Summary Create a custom role
+// @Description Creates an organization role composed of assignable permissions, e.g. "Finance" with read:order and create:refund. Members assigned the role gain its permissions in this organization only.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.OrganizationRoleRequest true "Role name and permissions"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.OrganizationRoleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/roles [post]
+func (h *OrganizationRoleHandler) CreateRole(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.OrganizationRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), orgID, actorID, &req)
+	if err != nil {
+		h.handleError(c, "Failed to create role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Role created successfully", role)
+}
+
+// UpdateRole godoc
+// @Summary Update a custom role
+// @Description Renames a custom organization role and replaces its permissions. Members holding the role keep it and get the new permissions immediately.
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param roleId path string true "Role ID"
+// @Param request body models.OrganizationRoleRequest true "Role name and permissions"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.OrganizationRoleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/roles/{roleId} [put]
+func (h *OrganizationRoleHandler) UpdateRole(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	roleID := middleware.UUIDParam(c, "roleId")
+
+	var req models.OrganizationRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.Request.Context(), orgID, roleID, actorID, &req)
+	if err != nil {
+		h.handleError(c, "Failed to update role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role updated successfully", role)
+}
+
+// DeleteRole godoc
+// @Summary Delete a custom role
+// @Description Deletes a custom organization role, taking its permissions away from every member holding it
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param roleId path string true "Role ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/roles/{roleId} [delete]
+func (h *OrganizationRoleHandler) DeleteRole(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	roleID := middleware.UUIDParam(c, "roleId")
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), orgID, roleID, actorID); err != nil {
+		h.handleError(c, "Failed to delete role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role deleted successfully", nil)
+}
+
+// AssignRoleMember godoc
+// @Summary Assign a custom role
+// @Description Gives a member of the organization a custom role
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param roleId path string true "Role ID"
+// @Param request body models.OrganizationRoleMemberRequest true "Member to assign"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/roles/{roleId}/members [post]
+func (h *OrganizationRoleHandler) AssignRoleMember(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	roleID := middleware.UUIDParam(c, "roleId")
+
+	var req models.OrganizationRoleMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	if err := h.roleService.AssignMember(c.Request.Context(), orgID, roleID, actorID, req.UserID); err != nil {
+		h.handleError(c, "Failed to assign role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role assigned successfully", nil)
+}
+
+// UnassignRoleMember godoc
+// @Summary Unassign a custom role
+// @Description Takes a custom role away from a member of the organization
+// @Tags organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param roleId path string true "Role ID"
+// @Param userId path string true "User ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/roles/{roleId}/members/{userId} [delete]
+func (h *OrganizationRoleHandler) UnassignRoleMember(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	roleID := middleware.UUIDParam(c, "roleId")
+	userID := middleware.UUIDParam(c, "userId")
+
+	if err := h.roleService.UnassignMember(c.Request.Context(), orgID, roleID, actorID, userID); err != nil {
+		h.handleError(c, "Failed to unassign role", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Role unassigned successfully", nil)
+}
+
+func (h *OrganizationRoleHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrOrganizationRoleNotFound) {
+		utils.NotFoundErrorResponse(c, "Role not found", err)
+		return
+	}
+	utils.BadRequestErrorResponse(c, message, err)
+}
+
+// organizationRoleActor returns the organization of the path and the signed-in user, responding
+// when either is missing
+func organizationRoleActor(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, userID, true
+}
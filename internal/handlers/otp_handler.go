@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
@@ -19,6 +22,7 @@ import (
 // @Success 200 {object} utils.Response
 // @Failure 400 {object} utils.Response
 // @Failure 500 {object} utils.Response
+// @Failure 503 {object} utils.Response
 // @Router /auth/verify-otp [post]
 func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 	var req models.OTPVerifyRequest
@@ -27,7 +31,11 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.VerifyOTP(&req); err != nil {
+	if err := h.authService.VerifyOTP(c.Request.Context(), &req); err != nil {
+		if errors.Is(err, services.ErrOTPUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, services.ErrOTPUnavailable.Error(), nil)
+			return
+		}
 		utils.BadRequestErrorResponse(c, "OTP verification failed", err)
 		return
 	}
@@ -50,14 +58,16 @@ func (h *AuthHandler) VerifyOTP(c *gin.Context) {
 
 // SendOTP godoc
 // @Summary Send OTP code
-// @Description Send a one-time password code for various purposes (registration, password reset, 2FA)
+// @Description Send a one-time password code for various purposes (registration, password reset, 2FA). Sends are limited per client IP and per identifier; the response is the same whether or not a code was sent.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body models.OTPSendRequest true "OTP send request"
 // @Success 200 {object} utils.Response{data=models.OTPResponse}
 // @Failure 400 {object} utils.Response
+// @Failure 429 {object} utils.Response
 // @Failure 500 {object} utils.Response
+// @Failure 503 {object} utils.Response
 // @Router /auth/send-otp [post]
 func (h *AuthHandler) SendOTP(c *gin.Context) {
 	var req models.OTPSendRequest
@@ -66,11 +76,49 @@ func (h *AuthHandler) SendOTP(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.GenerateAndSendOTP(&req)
+	response, err := h.authService.GenerateAndSendOTP(c.Request.Context(), &req, c.ClientIP())
 	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			utils.HandleAppError(c, appErr)
+			return
+		}
+		if errors.Is(err, services.ErrOTPUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, services.ErrOTPUnavailable.Error(), nil)
+			return
+		}
 		utils.BadRequestErrorResponse(c, "Failed to send OTP", err)
 		return
 	}
 
-	utils.SuccessResponse(c, http.StatusOK, "OTP sent successfully", response)
+	utils.SuccessResponse(c, http.StatusOK, response.Message, response)
+}
+
+// GetOTPMetrics godoc
+// @Summary Get OTP issuance metrics
+// @Description Returns hourly counts of OTP codes issued, requests suppressed by identifier quotas or eligibility, and requests rejected by the per-IP quota
+// @Tags admin
+// @Produce json
+// @Param hours query int false "Number of hours to return" default(24)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.OTPMetricsResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /admin/otp/metrics [get]
+func (h *AuthHandler) GetOTPMetrics(c *gin.Context) {
+	hours, _ := strconv.Atoi(c.DefaultQuery("hours", "24"))
+
+	metrics, err := h.authService.GetOTPMetrics(c.Request.Context(), hours)
+	if err != nil {
+		if errors.Is(err, services.ErrOTPUnavailable) {
+			utils.ServiceUnavailableErrorResponse(c, "OTP metrics are unavailable while Redis is unreachable", nil)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to retrieve OTP metrics", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "OTP metrics retrieved successfully", metrics)
 }
@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PresaleHandler struct {
+	presaleService *services.PresaleService
+}
+
+func NewPresaleHandler(presaleService *services.PresaleService) *PresaleHandler {
+	return &PresaleHandler{presaleService: presaleService}
+}
+
+// CreatePresale godoc
+// @Summary Create a presale
+// @Description Adds a presale window to an event of the organization. From starts_at until ends_at the event is only sold to buyers entering one of the presale's access codes or whose email is on its allowlist; outside its presale windows it is on general sale. Buyers are checked when starting a checkout session or cart and again when the orders are placed.
+// @Tags presales
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.PresaleRequest true "Event, name and window"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.Presale}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/presales [post]
+func (h *PresaleHandler) CreatePresale(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	presale, err := h.presaleService.CreatePresale(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to create presale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Presale created successfully", presale)
+}
+
+// ListPresales godoc
+// @Summary List presales
+// @Description Lists the organization's presales in start order with their access codes and usage, optionally only those of an event
+// @Tags presales
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int false "Event ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.Presale}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/presales [get]
+func (h *PresaleHandler) ListPresales(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.PresaleQuery)
+
+	presales, err := h.presaleService.ListPresales(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch presales", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Presales fetched successfully", presales)
+}
+
+// GetPresale godoc
+// @Summary Get a presale
+// @Description Returns a presale with its access codes, their usage and the size of its allowlist
+// @Tags presales
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.Presale}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId} [get]
+func (h *PresaleHandler) GetPresale(c *gin.Context) {
+	presale, err := h.presaleService.GetPresale(c.Request.Context(), middleware.UUIDParam(c, "id"), middleware.UUIDParam(c, "presaleId"))
+	if err != nil {
+		presaleErrorResponse(c, "Failed to fetch presale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Presale fetched successfully", presale)
+}
+
+// UpdatePresale godoc
+// @Summary Update a presale
+// @Description Replaces the event, name and window of a presale; active false puts its window on general sale. Access codes, their uses and the allowlist are kept.
+// @Tags presales
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Param request body models.PresaleRequest true "Event, name and window"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.Presale}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId} [put]
+func (h *PresaleHandler) UpdatePresale(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	presale, err := h.presaleService.UpdatePresale(c.Request.Context(), orgID, middleware.UUIDParam(c, "presaleId"), userID, &req)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to update presale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Presale updated successfully", presale)
+}
+
+// DeletePresale godoc
+// @Summary Delete a presale
+// @Description Removes a presale with its access codes and allowlist, putting its window on general sale. Orders already placed keep their presale in analytics.
+// @Tags presales
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId} [delete]
+func (h *PresaleHandler) DeletePresale(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	if err := h.presaleService.DeletePresale(c.Request.Context(), orgID, middleware.UUIDParam(c, "presaleId"), userID); err != nil {
+		presaleErrorResponse(c, "Failed to delete presale", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Presale deleted successfully", nil)
+}
+
+// CreatePresaleAccessCode godoc
+// @Summary Add a presale access code
+// @Description Adds an access code admitting buyers to the presale. Codes are matched case-insensitively; each order placed with a code uses it once, up to max_uses (0 for unlimited). Uses of orders cancelled before payment are given back.
+// @Tags presales
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Param request body models.PresaleAccessCodeRequest true "Code and usage cap"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.PresaleAccessCode}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId}/codes [post]
+func (h *PresaleHandler) CreatePresaleAccessCode(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresaleAccessCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	code, err := h.presaleService.CreateAccessCode(c.Request.Context(), orgID, middleware.UUIDParam(c, "presaleId"), userID, &req)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to create access code", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Access code created successfully", code)
+}
+
+// DeletePresaleAccessCode godoc
+// @Summary Delete a presale access code
+// @Description Stops accepting an access code. Orders already placed with it are kept.
+// @Tags presales
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Param codeId path string true "Access code ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId}/codes/{codeId} [delete]
+func (h *PresaleHandler) DeletePresaleAccessCode(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	err := h.presaleService.DeleteAccessCode(c.Request.Context(), orgID, middleware.UUIDParam(c, "presaleId"), middleware.UUIDParam(c, "codeId"), userID)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to delete access code", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Access code deleted successfully", nil)
+}
+
+// ListPresaleAllowlist godoc
+// @Summary List a presale's allowlist
+// @Description Returns a page of the emails admitted to the presale without an access code, in alphabetical order
+// @Tags presales
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Param q query string false "Part of the email"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.PresaleAllowlistEntry,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId}/allowlist [get]
+func (h *PresaleHandler) ListPresaleAllowlist(c *gin.Context) {
+	query := c.MustGet("validatedQuery").(*models.PresaleAllowlistQuery)
+
+	entries, meta, err := h.presaleService.ListAllowlist(c.Request.Context(), middleware.UUIDParam(c, "id"), middleware.UUIDParam(c, "presaleId"), query)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to fetch allowlist", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Allowlist fetched successfully", entries, meta)
+}
+
+// AddToPresaleAllowlist godoc
+// @Summary Add emails to a presale's allowlist
+// @Description Admits up to 1000 emails at a time to the presale without an access code. Emails are matched case-insensitively against the checkout email, or the signed-in buyer's email for carts; emails already on the allowlist are skipped.
+// @Tags presales
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Param request body models.PresaleAllowlistRequest true "Emails"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PresaleAllowlistResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId}/allowlist [post]
+func (h *PresaleHandler) AddToPresaleAllowlist(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresaleAllowlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	resp, err := h.presaleService.AddToAllowlist(c.Request.Context(), orgID, middleware.UUIDParam(c, "presaleId"), userID, &req)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to update allowlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Allowlist updated successfully", resp)
+}
+
+// RemoveFromPresaleAllowlist godoc
+// @Summary Remove emails from a presale's allowlist
+// @Description Takes up to 1000 emails at a time off the presale's allowlist; emails not on it are skipped
+// @Tags presales
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param presaleId path string true "Presale ID"
+// @Param request body models.PresaleAllowlistRequest true "Emails"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PresaleAllowlistResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/presales/{presaleId}/allowlist [delete]
+func (h *PresaleHandler) RemoveFromPresaleAllowlist(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.PresaleAllowlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	resp, err := h.presaleService.RemoveFromAllowlist(c.Request.Context(), orgID, middleware.UUIDParam(c, "presaleId"), userID, &req)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to update allowlist", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Allowlist updated successfully", resp)
+}
+
+// GetPresaleAnalytics godoc
+// @Summary Presale analytics of an event
+// @Description Compares an event's presale with its general sale: checkout sessions started and completed with their conversion rate, and orders, tickets and revenue (order totals less refunds, cancelled orders excluded). Lists each presale window's orders by how buyers were admitted, and the usage of every access code.
+// @Tags presales
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int true "Event ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PresaleAnalytics}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/analytics/presales [get]
+func (h *PresaleHandler) GetPresaleAnalytics(c *gin.Context) {
+	query := c.MustGet("validatedQuery").(*models.PresaleAnalyticsQuery)
+
+	analytics, err := h.presaleService.Analytics(c.Request.Context(), middleware.UUIDParam(c, "id"), query.EventID)
+	if err != nil {
+		presaleErrorResponse(c, "Failed to fetch presale analytics", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Presale analytics fetched successfully", analytics)
+}
+
+func presaleErrorResponse(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrPresaleNotFound), errors.Is(err, services.ErrPresaleAccessCodeNotFound):
+		utils.NotFoundErrorResponse(c, err.Error(), err)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
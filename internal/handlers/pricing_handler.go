@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingHandler struct {
+	pricingService *services.PricingService
+}
+
+func NewPricingHandler(pricingService *services.PricingService) *PricingHandler {
+	return &PricingHandler{
+		pricingService: pricingService,
+	}
+}
+
+// GetPricingPreview godoc
+// @Summary Preview event pricing
+// @Description Returns the ticket price that applies right now and the schedule of upcoming price changes
+// @Tags pricing
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=models.PricingPreview}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /events/{id}/pricing [get]
+func (h *PricingHandler) GetPricingPreview(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	preview, err := h.pricingService.Preview(c.Request.Context(), uint(id))
+	if err != nil {
+		utils.NotFoundErrorResponse(c, "Event not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Pricing retrieved successfully", preview)
+}
+
+// CreatePricingRule godoc
+// @Summary Create a pricing rule
+// @Description Adds a price change to an event that applies after a number of tickets is sold or from a date. The highest price among active rules and the base price applies at checkout.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.PricingRuleRequest true "Rule data"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.PricingRuleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/pricing-rules [post]
+func (h *PricingHandler) CreatePricingRule(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.PricingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	rule, err := h.pricingService.CreateRule(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create pricing rule", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Pricing rule created successfully", rule)
+}
+
+// ListPricingRules godoc
+// @Summary List pricing rules
+// @Description Lists the organization's pricing rules, optionally for a single event
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int false "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.PricingRuleResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/pricing-rules [get]
+func (h *PricingHandler) ListPricingRules(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var eventID uint64
+	if raw := c.Query("event_id"); raw != "" {
+		var err error
+		if eventID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+			return
+		}
+	}
+
+	rules, err := h.pricingService.ListRules(c.Request.Context(), orgID, uint(eventID))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get pricing rules", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Pricing rules retrieved successfully", rules)
+}
+
+// DeletePricingRule godoc
+// @Summary Delete a pricing rule
+// @Description Removes a pricing rule from the organization
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param ruleId path string true "Pricing rule ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/pricing-rules/{ruleId} [delete]
+func (h *PricingHandler) DeletePricingRule(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	ruleID := middleware.UUIDParam(c, "ruleId")
+
+	if err := h.pricingService.DeleteRule(c.Request.Context(), orgID, ruleID); err != nil {
+		utils.NotFoundErrorResponse(c, "Pricing rule not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Pricing rule deleted successfully", nil)
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromoCodeHandler struct {
+	promoCodeService *services.PromoCodeService
+}
+
+func NewPromoCodeHandler(promoCodeService *services.PromoCodeService) *PromoCodeHandler {
+	return &PromoCodeHandler{promoCodeService: promoCodeService}
+}
+
+// CreatePromoCode godoc
+// @Summary Create a promo code
+// @Description Adds a promo code buyers can enter at checkout. Percentage codes take value percent off each covered ticket; fixed codes take value off the covered tickets of an order. Codes cover the organization's events, or only event_ids when given, and can be limited in total uses, uses per buyer and to a validity window. Each order placed with a code uses it once.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.PromoCodeRequest true "Promo code data"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.PromoCodeResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/promo-codes [post]
+func (h *PromoCodeHandler) CreatePromoCode(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	promo, err := h.promoCodeService.CreatePromoCode(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create promo code", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Promo code created successfully", promo)
+}
+
+// ListPromoCodes godoc
+// @Summary List promo codes
+// @Description Lists the organization's promo codes with their usage, optionally only those covering an event
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int false "Event ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.PromoCodeResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/promo-codes [get]
+func (h *PromoCodeHandler) ListPromoCodes(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var eventID uint64
+	if raw := c.Query("event_id"); raw != "" {
+		var err error
+		if eventID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+			return
+		}
+	}
+
+	promos, err := h.promoCodeService.ListPromoCodes(c.Request.Context(), orgID, uint(eventID))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get promo codes", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promo codes retrieved successfully", promos)
+}
+
+// GetPromoCode godoc
+// @Summary Get a promo code
+// @Description Returns a promo code of the organization with its usage
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param promoCodeId path string true "Promo code ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PromoCodeResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/promo-codes/{promoCodeId} [get]
+func (h *PromoCodeHandler) GetPromoCode(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	promoID := middleware.UUIDParam(c, "promoCodeId")
+
+	promo, err := h.promoCodeService.GetPromoCode(c.Request.Context(), orgID, promoID)
+	if err != nil {
+		h.handleError(c, "Failed to get promo code", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promo code retrieved successfully", promo)
+}
+
+// UpdatePromoCode godoc
+// @Summary Update a promo code
+// @Description Replaces the settings of a promo code; set active to false to stop accepting it. Uses so far count towards the new limits, and orders already placed keep their discount.
+// @Tags pricing
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param promoCodeId path string true "Promo code ID"
+// @Param request body models.PromoCodeRequest true "Promo code data"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.PromoCodeResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/promo-codes/{promoCodeId} [put]
+func (h *PromoCodeHandler) UpdatePromoCode(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	promoID := middleware.UUIDParam(c, "promoCodeId")
+
+	var req models.PromoCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	promo, err := h.promoCodeService.UpdatePromoCode(c.Request.Context(), orgID, promoID, userID, &req)
+	if err != nil {
+		h.handleError(c, "Failed to update promo code", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promo code updated successfully", promo)
+}
+
+// DeletePromoCode godoc
+// @Summary Delete a promo code
+// @Description Removes a promo code from the organization. Orders already placed with it keep their discount.
+// @Tags pricing
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param promoCodeId path string true "Promo code ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/promo-codes/{promoCodeId} [delete]
+func (h *PromoCodeHandler) DeletePromoCode(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	promoID := middleware.UUIDParam(c, "promoCodeId")
+
+	if err := h.promoCodeService.DeletePromoCode(c.Request.Context(), orgID, promoID, userID); err != nil {
+		h.handleError(c, "Failed to delete promo code", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Promo code deleted successfully", nil)
+}
+
+func (h *PromoCodeHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrPromoCodeNotFound) {
+		utils.NotFoundErrorResponse(c, "Promo code not found", err)
+		return
+	}
+	utils.BadRequestErrorResponse(c, message, err)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PublicStatsHandler struct {
+	statsService *services.PublicStatsService
+}
+
+func NewPublicStatsHandler(statsService *services.PublicStatsService) *PublicStatsHandler {
+	return &PublicStatsHandler{statsService: statsService}
+}
+
+// GetPublicStats godoc
+// @Summary Get platform statistics
+// @Description Returns platform-wide totals of events hosted, tickets issued and organizers onboarded for the marketing site's live counters. Totals are aggregated across all organizations and cached for PUBLIC_STATS_CACHE_TTL.
+// @Tags public
+// @Produce json
+// @Success 200 {object} utils.Response{data=models.PublicStats}
+// @Failure 500 {object} utils.Response
+// @Router /public/stats [get]
+func (h *PublicStatsHandler) GetPublicStats(c *gin.Context) {
+	stats, err := h.statsService.Stats(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve statistics", err)
+		return
+	}
+
+	// Let browsers and CDNs in front of the marketing site cache the counters too
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(h.statsService.CacheTTL().Seconds())))
+	utils.SuccessResponse(c, http.StatusOK, "Statistics retrieved successfully", stats)
+}
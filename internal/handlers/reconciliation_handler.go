@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// ListReports godoc
+// @Summary List payment reconciliation reports
+// @Description Returns the most recent reconciliation runs matching payment provider transactions against internal payments
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum number of reports" default(30)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.ReconciliationReport}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/reconciliation/reports [get]
+func (h *ReconciliationHandler) ListReports(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "30"))
+
+	reports, err := h.reconciliationService.ListReports(c.Request.Context(), limit)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve reconciliation reports", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reconciliation reports retrieved successfully", reports)
+}
+
+// GetReport godoc
+// @Summary Get a payment reconciliation report
+// @Description Returns a reconciliation report with its discrepancies
+// @Tags admin
+// @Produce json
+// @Param reportId path string true "Report ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.ReconciliationReport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/reconciliation/reports/{reportId} [get]
+func (h *ReconciliationHandler) GetReport(c *gin.Context) {
+	reportID := middleware.UUIDParam(c, "reportId")
+
+	report, err := h.reconciliationService.GetReport(c.Request.Context(), reportID)
+	if err != nil {
+		utils.NotFoundErrorResponse(c, "Reconciliation report not found", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Reconciliation report retrieved successfully", report)
+}
+
+// RunReconciliation godoc
+// @Summary Reconcile a payment period on demand
+// @Description Queues a reconciliation of the given period in addition to the nightly run. The report appears in the report list once complete.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.RunReconciliationRequest true "Period to reconcile"
+// @Security ApiKeyAuth
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/reconciliation/reports [post]
+func (h *ReconciliationHandler) RunReconciliation(c *gin.Context) {
+	var req models.RunReconciliationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	if err := h.reconciliationService.QueueReconciliation(&req); err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to queue reconciliation", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Reconciliation queued", nil)
+}
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RefundHandler struct {
+	refundService *services.RefundService
+}
+
+func NewRefundHandler(refundService *services.RefundService) *RefundHandler {
+	return &RefundHandler{refundService: refundService}
+}
+
+// RequestRefund godoc
+// @Summary Request a refund
+// @Description Asks the organizer to refund tickets of an order placed by or for the signed-in user. Without ticket_ids every unused ticket is refunded. Each ticket is worth an equal share of the order; the event's refund policy applies, and its refund fee is kept from the amount.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param orderId path string true "Order ID"
+// @Param request body models.CreateRefundRequest true "Tickets to refund and reason"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /orders/{orderId}/refunds [post]
+func (h *RefundHandler) RequestRefund(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	var req models.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	refund, err := h.refundService.RequestBuyerRefund(c.Request.Context(), userID, orderID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to request refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Refund requested successfully", refund)
+}
+
+// ListOrderRefunds godoc
+// @Summary List an order's refunds
+// @Description Returns the refunds of an order placed by or for the signed-in user, newest first
+// @Tags orders
+// @Produce json
+// @Param orderId path string true "Order ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /orders/{orderId}/refunds [get]
+func (h *RefundHandler) ListOrderRefunds(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	refunds, err := h.refundService.ListBuyerRefunds(c.Request.Context(), userID, orderID)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to fetch refunds", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Refunds fetched successfully", refunds)
+}
+
+// RequestStaffRefund godoc
+// @Summary Request a refund of an order
+// @Description Records a refund of an organization's order for approval. ticket_ids refunds and cancels those tickets, at most their share of the order or the given amount; an amount alone returns part of the money and keeps the tickets valid; neither refunds the whole order. The event's refund policy does not apply.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param orderId path string true "Order ID"
+// @Param request body models.CreateRefundRequest true "Tickets and/or amount to refund and reason"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 201 {object} utils.Response{data=models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/orders/{orderId}/refunds [post]
+func (h *RefundHandler) RequestStaffRefund(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	orderID := middleware.UUIDParam(c, "orderId")
+
+	var req models.CreateRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	refund, err := h.refundService.RequestStaffRefund(c.Request.Context(), orgID, orderID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to request refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Refund requested successfully", refund)
+}
+
+// ListRefunds godoc
+// @Summary List refunds
+// @Description Returns the organization's refunds, newest first, optionally filtered by order and status
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param order_id query string false "Order ID"
+// @Param status query string false "Status (requested, approved, processed, rejected, failed)"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/refunds [get]
+func (h *RefundHandler) ListRefunds(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	var orderID *uuid.UUID
+	if raw := c.Query("order_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			utils.BadRequestErrorResponse(c, "Invalid order ID", err)
+			return
+		}
+		orderID = &id
+	}
+
+	refunds, err := h.refundService.ListRefunds(c.Request.Context(), orgID, orderID, c.Query("status"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch refunds", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Refunds fetched successfully", refunds)
+}
+
+// ApproveRefund godoc
+// @Summary Approve a refund
+// @Description Approves a requested refund and processes it: the money goes back to the buyer's card, and the refunded tickets are cancelled and put back on sale. Parts paid in cash or by invoice are reported as manual_amount to be settled offline. The buyer and the holders of cancelled tickets are emailed.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param refundId path string true "Refund ID"
+// @Param request body models.ReviewRefundRequest false "Review note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/refunds/{refundId}/approve [post]
+func (h *RefundHandler) ApproveRefund(c *gin.Context) {
+	h.reviewRefund(c, true)
+}
+
+// RejectRefund godoc
+// @Summary Reject a refund
+// @Description Rejects a requested refund without changing the order. Buyers who asked for the refund are emailed the note.
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param refundId path string true "Refund ID"
+// @Param request body models.ReviewRefundRequest false "Review note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/refunds/{refundId}/reject [post]
+func (h *RefundHandler) RejectRefund(c *gin.Context) {
+	h.reviewRefund(c, false)
+}
+
+// ProcessRefund godoc
+// @Summary Retry a failed refund
+// @Description Processes a refund whose processing failed again, e.g. after a payment provider outage. Money returned by earlier attempts is not returned twice.
+// @Tags orders
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param refundId path string true "Refund ID"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.Refund}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/refunds/{refundId}/process [post]
+func (h *RefundHandler) ProcessRefund(c *gin.Context) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	refundID := middleware.UUIDParam(c, "refundId")
+
+	refund, err := h.refundService.ProcessRefund(c.Request.Context(), orgID, refundID, userID)
+	if err != nil {
+		h.handleError(c, "Failed to process refund", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Refund processed successfully", refund)
+}
+
+func (h *RefundHandler) reviewRefund(c *gin.Context, approve bool) {
+	orgID, userID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+	refundID := middleware.UUIDParam(c, "refundId")
+
+	var req models.ReviewRefundRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
+			return
+		}
+	}
+
+	if !approve {
+		refund, err := h.refundService.RejectRefund(c.Request.Context(), orgID, refundID, userID, req.Note)
+		if err != nil {
+			h.handleError(c, "Failed to reject refund", err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Refund rejected", refund)
+		return
+	}
+
+	refund, err := h.refundService.ApproveRefund(c.Request.Context(), orgID, refundID, userID, req.Note)
+	if err != nil {
+		h.handleError(c, "Failed to process refund", err)
+		return
+	}
+	utils.SuccessResponse(c, http.StatusOK, "Refund approved and processed", refund)
+}
+
+func (h *RefundHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrRefundNotFound) {
+		utils.NotFoundErrorResponse(c, "Refund not found", err)
+		return
+	}
+	utils.BadRequestErrorResponse(c, message, err)
+}
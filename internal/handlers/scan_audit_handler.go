@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScanAuditHandler struct {
+	scanAuditService *services.ScanAuditService
+}
+
+func NewScanAuditHandler(scanAuditService *services.ScanAuditService) *ScanAuditHandler {
+	return &ScanAuditHandler{scanAuditService: scanAuditService}
+}
+
+// ListScanAttempts godoc
+// @Summary List scan attempts
+// @Description Returns a page of the organization's door scans, newest first, whatever their result: admissions as well as duplicates, tickets of other events, cancelled and revoked tickets, unknown tickets and invalid codes, with the gate, the staff member, and the staff device (X-Device-ID) or paired scanner that scanned
+// @Tags tickets
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int false "Event the codes were scanned for"
+// @Param ticket_id query string false "Ticket ID"
+// @Param result query string false "admitted, already_checked_in, duplicate, cancelled, revoked, wrong_event, not_found or invalid_code"
+// @Param gate query string false "Gate"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.ScanAttempt,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/scans [get]
+func (h *ScanAuditHandler) ListScanAttempts(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.ScanAttemptQuery)
+
+	attempts, meta, err := h.scanAuditService.List(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch scan attempts", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Scan attempts fetched successfully", attempts, meta)
+}
+
+// GetScanAnomalies godoc
+// @Summary Find suspicious scans
+// @Description Returns the tickets of an event whose scans suggest sharing or forgery, most recently scanned first: the same ticket scanned at two gates within the window (multiple_gates), a used ticket rejected min_attempts times or more (repeated_rejections), and cancelled, refunded or revoked tickets presented at the door (cancelled_ticket). A ticket matching several patterns is listed once per pattern.
+// @Tags tickets
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int true "Event ID"
+// @Param window query int false "Seconds between scans at different gates (1-3600, default 60)"
+// @Param min_attempts query int false "Rejections of a used ticket to report (2-100, default 3)"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.ScanAnomaly}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/scans/anomalies [get]
+func (h *ScanAuditHandler) GetScanAnomalies(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.ScanAnomalyQuery)
+
+	anomalies, err := h.scanAuditService.Anomalies(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to find suspicious scans", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Suspicious scans fetched successfully", anomalies)
+}
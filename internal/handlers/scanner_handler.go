@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScannerHandler struct {
+	scannerService *services.ScannerService
+}
+
+func NewScannerHandler(scannerService *services.ScannerService) *ScannerHandler {
+	return &ScannerHandler{scannerService: scannerService}
+}
+
+// CreatePairing godoc
+// @Summary Generate a scanner pairing code
+// @Description Generates a short-lived code, also returned as a QR payload, that a door scanner device exchanges for a device token valid only for this event's check-in endpoints. Only the event's organizer can pair scanners.
+// @Tags check-ins
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body models.ScannerPairingRequest false "Device label"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.ScannerPairingResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/events/{id}/scanner-pairings [post]
+func (h *ScannerHandler) CreatePairing(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.ScannerPairingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
+			return
+		}
+	}
+
+	pairing, err := h.scannerService.CreatePairing(c.Request.Context(), uint(eventID), userID, &req)
+	if err != nil {
+		h.handleError(c, "Failed to create scanner pairing", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Scanner pairing code created successfully", pairing)
+}
+
+// Pair godoc
+// @Summary Pair a scanner device
+// @Description Exchanges a pairing code, typed or scanned from its QR code, for a device token. The token is sent as a bearer token to the check-in endpoints of the paired event only. Each code can be used once.
+// @Tags check-ins
+// @Accept json
+// @Produce json
+// @Param request body models.ScannerPairRequest true "Pairing code and app version"
+// @Success 200 {object} utils.Response{data=models.ScannerTokenResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /api/v1/scanner/pair [post]
+func (h *ScannerHandler) Pair(c *gin.Context) {
+	var req models.ScannerPairRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	token, err := h.scannerService.Pair(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to pair scanner", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scanner paired successfully", token)
+}
+
+// ListDevices godoc
+// @Summary List an event's scanners
+// @Description Returns the scanner devices paired or being paired to the event, newest first, with when each was last seen
+// @Tags check-ins
+// @Produce json
+// @Param id path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.ScannerDevice}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/events/{id}/scanner-devices [get]
+func (h *ScannerHandler) ListDevices(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	devices, err := h.scannerService.ListDevices(c.Request.Context(), uint(eventID), userID)
+	if err != nil {
+		h.handleError(c, "Failed to fetch scanners", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scanners fetched successfully", devices)
+}
+
+// RevokeDevice godoc
+// @Summary Revoke a scanner
+// @Description Unpairs a scanner device, e.g. one that was lost. Its token stops working immediately.
+// @Tags check-ins
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param deviceId path string true "Scanner device ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /api/v1/events/{id}/scanner-devices/{deviceId} [delete]
+func (h *ScannerHandler) RevokeDevice(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	deviceID := middleware.UUIDParam(c, "deviceId")
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	if err := h.scannerService.RevokeDevice(c.Request.Context(), uint(eventID), deviceID, userID); err != nil {
+		h.handleError(c, "Failed to revoke scanner", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scanner revoked successfully", nil)
+}
+
+// ListOrganizationDevices godoc
+// @Summary List an organization's scanners
+// @Description Returns the scanner devices paired to the organization's events, newest first, with their name, app version and when each was last seen
+// @Tags check-ins
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param active query bool false "Leave out revoked scanners"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.ScannerDevice}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/scanner-devices [get]
+func (h *ScannerHandler) ListOrganizationDevices(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	activeOnly := c.DefaultQuery("active", "false") == "true"
+	devices, err := h.scannerService.ListOrganizationDevices(c.Request.Context(), orgID, activeOnly)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to fetch scanners", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scanners fetched successfully", devices)
+}
+
+// RenameDevice godoc
+// @Summary Rename a scanner
+// @Description Changes the label an organization's scanner device is listed under
+// @Tags check-ins
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param deviceId path string true "Scanner device ID"
+// @Param request body models.ScannerDeviceUpdateRequest true "New name"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.ScannerDevice}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/scanner-devices/{deviceId} [patch]
+func (h *ScannerHandler) RenameDevice(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	deviceID := middleware.UUIDParam(c, "deviceId")
+
+	var req models.ScannerDeviceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	device, err := h.scannerService.RenameDevice(c.Request.Context(), orgID, deviceID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to rename scanner", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scanner renamed successfully", device)
+}
+
+// RevokeOrganizationDevice godoc
+// @Summary Revoke an organization's scanner
+// @Description Cuts a scanner device off from the check-in endpoints immediately, e.g. a lost phone. Recorded in the audit log.
+// @Tags check-ins
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param deviceId path string true "Scanner device ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/scanner-devices/{deviceId} [delete]
+func (h *ScannerHandler) RevokeOrganizationDevice(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	deviceID := middleware.UUIDParam(c, "deviceId")
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	if err := h.scannerService.RevokeOrganizationDevice(c.Request.Context(), orgID, deviceID, userID); err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to revoke scanner", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Scanner revoked successfully", nil)
+}
+
+func (h *ScannerHandler) handleError(c *gin.Context, message string, err error) {
+	if errors.Is(err, services.ErrEventAccessDenied) {
+		utils.ForbiddenErrorResponse(c, message, err)
+		return
+	}
+	utils.BadRequestErrorResponse(c, message, err)
+}
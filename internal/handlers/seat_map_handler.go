@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SeatMapHandler struct {
+	seatMapService *services.SeatMapService
+}
+
+func NewSeatMapHandler(seatMapService *services.SeatMapService) *SeatMapHandler {
+	return &SeatMapHandler{seatMapService: seatMapService}
+}
+
+// CreateVenue godoc
+// @Summary Define a venue
+// @Description Defines a venue of the organization with its reserved seating: sections of rows, each row either with its seats listed or with seat_count seats labelled 1 to seat_count
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.VenueRequest true "Venue and seat map"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.Venue}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/venues [post]
+func (h *SeatMapHandler) CreateVenue(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	venue, err := h.seatMapService.CreateVenue(c.Request.Context(), orgID, userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Venue created successfully", venue)
+}
+
+// ListVenues godoc
+// @Summary List an organization's venues
+// @Description Returns the organization's venues by name, without their seat maps
+// @Tags venues
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.Venue}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/venues [get]
+func (h *SeatMapHandler) ListVenues(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	venues, err := h.seatMapService.ListVenues(c.Request.Context(), orgID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch venues", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venues fetched successfully", venues)
+}
+
+// GetVenue godoc
+// @Summary Get a venue
+// @Description Returns a venue of the organization with its sections, rows and seats in seat map order
+// @Tags venues
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param venueId path string true "Venue ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.Venue}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /organizations/{id}/venues/{venueId} [get]
+func (h *SeatMapHandler) GetVenue(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	venueID := middleware.UUIDParam(c, "venueId")
+
+	venue, err := h.seatMapService.GetVenue(c.Request.Context(), orgID, venueID)
+	if err != nil {
+		if errors.Is(err, services.ErrVenueNotFound) {
+			utils.NotFoundErrorResponse(c, "Venue not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to fetch venue", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Venue fetched successfully", venue)
+}
+
+// AttachSeatMap godoc
+// @Summary Attach a seat map to an event
+// @Description Puts the seats of one of the organizer's venues on sale for their event, replacing any seat map attached before. The event's capacity becomes the number of seats and tickets are then issued for selected seats. Only possible before any ticket is sold.
+// @Tags venues
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param request body models.AttachSeatMapRequest true "Venue"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.SeatMapResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /events/{id}/seat-map [put]
+func (h *SeatMapHandler) AttachSeatMap(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.AttachSeatMapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	seatMap, err := h.seatMapService.AttachSeatMap(c.Request.Context(), uint(eventID), userID, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrEventAccessDenied):
+			utils.ForbiddenErrorResponse(c, "Failed to attach seat map", err)
+		case errors.Is(err, services.ErrVenueNotFound):
+			utils.NotFoundErrorResponse(c, "Venue not found", err)
+		default:
+			utils.BadRequestErrorResponse(c, "Failed to attach seat map", err)
+		}
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Seat map attached successfully", seatMap)
+}
+
+// GetEventSeats godoc
+// @Summary Get an event's seat map
+// @Description Returns the seat map of an event with reserved seating, by section and row, with each seat available, held by a checkout or sold
+// @Tags venues
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} utils.Response{data=models.SeatMapResponse}
+// @Failure 400 {object} utils.Response
+// @Router /events/{id}/seats [get]
+func (h *SeatMapHandler) GetEventSeats(c *gin.Context) {
+	eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	seatMap, err := h.seatMapService.GetEventSeats(c.Request.Context(), uint(eventID))
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to fetch seat map", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Seat map fetched successfully", seatMap)
+}
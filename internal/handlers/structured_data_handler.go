@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StructuredDataHandler struct {
+	structuredDataService *services.StructuredDataService
+}
+
+func NewStructuredDataHandler(structuredDataService *services.StructuredDataService) *StructuredDataHandler {
+	return &StructuredDataHandler{structuredDataService: structuredDataService}
+}
+
+// GetEventJSONLD godoc
+// @Summary Get an event's JSON-LD structured data
+// @Description Returns the event as schema.org Event structured data with its offer, location and performer, unwrapped so frontends can embed it as-is in a script tag of type application/ld+json for search engine rich results. It is built from the current event on every request; caches must revalidate it with the ETag.
+// @Tags public
+// @Produce application/ld+json
+// @Param id path int true "Event ID"
+// @Success 200 {object} models.EventJSONLD
+// @Success 304 "Not modified"
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /public/events/{id}/jsonld [get]
+func (h *StructuredDataHandler) GetEventJSONLD(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	data, err := h.structuredDataService.EventJSONLD(c.Request.Context(), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrStructuredDataEventNotFound) {
+			utils.NotFoundErrorResponse(c, "Event not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to build structured data", err)
+		return
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to build structured data", err)
+		return
+	}
+
+	// Changes to the event change the ETag, so revalidating caches never serve stale data
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+	c.Header("Cache-Control", "public, no-cache")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/ld+json; charset=utf-8", body)
+}
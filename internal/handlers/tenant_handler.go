@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TenantHandler struct {
+	tenantService *services.TenantService
+}
+
+func NewTenantHandler(tenantService *services.TenantService) *TenantHandler {
+	return &TenantHandler{tenantService: tenantService}
+}
+
+// GetTenantConfig godoc
+// @Summary Get the frontend's branding
+// @Description Returns the app name, support email and color scheme of the tenant the request resolved to. The tenant is selected by the X-Tenant header, or matched by the domain of the calling frontend. Requests matching no tenant get the default brand.
+// @Tags public
+// @Produce json
+// @Param X-Tenant header string false "Tenant slug"
+// @Success 200 {object} utils.Response{data=models.TenantConfigResponse}
+// @Router /public/tenant [get]
+func (h *TenantHandler) GetTenantConfig(c *gin.Context) {
+	tenant, ok := services.TenantFromContext(c.Request.Context())
+	if !ok {
+		tenant = h.tenantService.Resolve(c.Request.Context(), c.Request.Host, c.GetHeader("X-Tenant"))
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tenant configuration retrieved successfully", tenant.ToConfigResponse())
+}
+
+// ListTenants godoc
+// @Summary List tenants
+// @Description Lists all white-label tenants. Payment secret keys are never returned.
+// @Tags admin
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.TenantResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/tenants [get]
+func (h *TenantHandler) ListTenants(c *gin.Context) {
+	tenants, err := h.tenantService.ListTenants(c.Request.Context())
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get tenants", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tenants retrieved successfully", tenants)
+}
+
+// CreateTenant godoc
+// @Summary Create a tenant
+// @Description Creates a white-label tenant served from its own domain or selected with the X-Tenant header.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body models.CreateTenantRequest true "Tenant settings"
+// @Security ApiKeyAuth
+// @Success 201 {object} utils.Response{data=models.TenantResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/tenants [post]
+func (h *TenantHandler) CreateTenant(c *gin.Context) {
+	var req models.CreateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	tenant, err := h.tenantService.CreateTenant(c.Request.Context(), &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to create tenant", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, "Tenant created successfully", tenant)
+}
+
+// UpdateTenant godoc
+// @Summary Update a tenant
+// @Description Updates a tenant's settings. Empty fields are left unchanged. Other instances pick up the change within TENANT_CACHE_TTL.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Param request body models.UpdateTenantRequest true "Tenant settings"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.TenantResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /admin/tenants/{tenantId} [put]
+func (h *TenantHandler) UpdateTenant(c *gin.Context) {
+	tenantID := middleware.UUIDParam(c, "tenantId")
+
+	var req models.UpdateTenantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	tenant, err := h.tenantService.UpdateTenant(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrTenantNotFound) {
+			utils.NotFoundErrorResponse(c, "Tenant not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to update tenant", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tenant updated successfully", tenant)
+}
+
+// DeleteTenant godoc
+// @Summary Delete a tenant
+// @Description Deletes a tenant; its frontend falls back to the default brand. Requires an elevated access token (see /admin/elevations).
+// @Tags admin
+// @Produce json
+// @Param tenantId path string true "Tenant ID"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /admin/tenants/{tenantId} [delete]
+func (h *TenantHandler) DeleteTenant(c *gin.Context) {
+	tenantID := middleware.UUIDParam(c, "tenantId")
+
+	if err := h.tenantService.DeleteTenant(c.Request.Context(), tenantID); err != nil {
+		if errors.Is(err, services.ErrTenantNotFound) {
+			utils.NotFoundErrorResponse(c, "Tenant not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to delete tenant", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tenant deleted successfully", nil)
+}
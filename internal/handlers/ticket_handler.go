@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// checkInWriteTimeout bounds each websocket write, so a stalled client is dropped instead of
+// holding its subscription open
+const checkInWriteTimeout = 10 * time.Second
+
+type TicketHandler struct {
+	ticketService *services.TicketService
+	statsService  *services.CheckInStatsService
+}
+
+func NewTicketHandler(ticketService *services.TicketService, statsService *services.CheckInStatsService) *TicketHandler {
+	return &TicketHandler{ticketService: ticketService, statsService: statsService}
+}
+
+// ValidateTicketBatch godoc
+// @Summary Validate a batch of scanned tickets
+// @Description Checks in up to SCAN_MAX_BATCH_SIZE scanned ticket codes for an event at once, as turnstiles queue them, and returns a result per code in request order. Codes scanned again within SCAN_DUPLICATE_WINDOW are reported as duplicates.
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param request body models.TicketValidationBatchRequest true "Scanned codes"
+// @Param X-Device-ID header string false "Stable identifier of the scanning device, for the scan audit trail"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.TicketValidationBatchResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /organizations/{id}/tickets/validate/batch [post]
+func (h *TicketHandler) ValidateTicketBatch(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+
+	orgID := middleware.UUIDParam(c, "id")
+
+	var req models.TicketValidationBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	response, err := h.ticketService.ValidateBatch(c.Request.Context(), orgID, userID, scanDevice(c), &req)
+	if err != nil {
+		var appErr *utils.AppError
+		if errors.As(err, &appErr) {
+			utils.HandleAppError(c, appErr)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to validate tickets", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Tickets validated successfully", response)
+}
+
+// ValidateTicket godoc
+// @Summary Validate a scanned ticket QR code
+// @Description Verifies the signed payload of a ticket QR code and checks the ticket in for the event, exactly once: later scans report it as already checked in, and scans within SCAN_DUPLICATE_WINDOW as duplicates. Staff can validate tickets of organizations they organize or belong to; admins any.
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param request body models.TicketValidationRequest true "Scanned code"
+// @Param X-Device-ID header string false "Stable identifier of the scanning device, for the scan audit trail"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.TicketValidationResult}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /tickets/validate [post]
+func (h *TicketHandler) ValidateTicket(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	var req models.TicketValidationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	result, err := h.ticketService.Validate(c.Request.Context(), userID, contextRoles(c), scanDevice(c), &req)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to validate ticket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket validated successfully", result)
+}
+
+// DownloadTicketPDF godoc
+// @Summary Download a ticket as PDF
+// @Description Returns a printable PDF of a ticket held by the signed-in user or bought in one of their orders, with the event details, order number and the QR code scanned at the entrance
+// @Tags tickets
+// @Produce application/pdf
+// @Param id path string true "Ticket ID"
+// @Security ApiKeyAuth
+// @Success 200 {file} file
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tickets/{id}/pdf [get]
+func (h *TicketHandler) DownloadTicketPDF(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	ticketID := middleware.UUIDParam(c, "id")
+
+	ticket, pdf, err := h.ticketService.TicketPDF(c.Request.Context(), ticketID, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrTicketNotFound) {
+			utils.NotFoundErrorResponse(c, "Ticket not found", err)
+			return
+		}
+		utils.BadRequestErrorResponse(c, "Failed to generate ticket PDF", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", services.TicketPDFFilename(ticket)))
+	c.Data(http.StatusOK, services.TicketPDFContentType, pdf)
+}
+
+// StreamCheckInStats godoc
+// @Summary Stream live check-in statistics
+// @Description Upgrades to a websocket that pushes the event's check-in statistics as JSON whenever tickets are checked in, and every 30 seconds otherwise: total and per-gate entries, and entries per minute and gate for the last 30 minutes. Messages from the client are ignored.
+// @Tags tickets
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param eventId path int true "Event ID"
+// @Security ApiKeyAuth
+// @Success 101 {object} models.CheckInStats
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /organizations/{id}/events/{eventId}/check-ins/live [get]
+func (h *TicketHandler) StreamCheckInStats(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+
+	eventID, err := strconv.ParseUint(c.Param("eventId"), 10, 32)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+		return
+	}
+
+	if !strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+		utils.BadRequestErrorResponse(c, "Websocket upgrade required", nil)
+		return
+	}
+
+	// Fail before upgrading when the statistics cannot be read
+	if _, err := h.statsService.Snapshot(c.Request.Context(), orgID, uint(eventID)); err != nil {
+		utils.ErrorResponse(c, http.StatusServiceUnavailable, "Live check-in statistics are unavailable", err)
+		return
+	}
+
+	// The stream outlives the request timeout; it ends when the client goes away
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+	defer cancel()
+
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+
+		// Clear the deadlines the HTTP server set for the upgrade request
+		_ = ws.SetDeadline(time.Time{})
+
+		// Reading is what notices the client closing the connection
+		go func() {
+			defer cancel()
+			var discard []byte
+			for {
+				if err := websocket.Message.Receive(ws, &discard); err != nil {
+					return
+				}
+			}
+		}()
+
+		err := h.statsService.Watch(ctx, orgID, uint(eventID), func(stats *models.CheckInStats) error {
+			if err := ws.SetWriteDeadline(time.Now().Add(checkInWriteTimeout)); err != nil {
+				return err
+			}
+			return websocket.JSON.Send(ws, stats)
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("Check-in stream for event %d ended: %v", eventID, err)
+		}
+	}}
+
+	server.ServeHTTP(c.Writer, c.Request)
+}
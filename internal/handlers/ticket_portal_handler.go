@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketPortalHandler struct {
+	portalService *services.TicketPortalService
+}
+
+func NewTicketPortalHandler(portalService *services.TicketPortalService) *TicketPortalHandler {
+	return &TicketPortalHandler{portalService: portalService}
+}
+
+// GetTicket godoc
+// @Summary View a ticket from its email link
+// @Description Returns the ticket and event for the token in the ticket email link, without signing in, along with whether the attendee name can still be changed
+// @Tags tickets
+// @Produce json
+// @Param token path string true "Ticket token from the ticket email"
+// @Success 200 {object} utils.Response{data=models.TicketPortalResponse}
+// @Failure 404 {object} utils.Response
+// @Router /tickets/manage/{token} [get]
+func (h *TicketPortalHandler) GetTicket(c *gin.Context) {
+	ticket, err := h.portalService.GetTicket(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, "Failed to fetch ticket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket fetched successfully", ticket)
+}
+
+// UpdateAttendee godoc
+// @Summary Correct the attendee name of a ticket
+// @Description Changes the name on a valid ticket from its email link. Names can be changed TICKET_NAME_CHANGE_LIMIT times, until TICKET_NAME_CHANGE_CUTOFF before the event starts. Changes are recorded in the audit log.
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param token path string true "Ticket token from the ticket email"
+// @Param request body models.TicketAttendeeUpdateRequest true "New attendee name"
+// @Success 200 {object} utils.Response{data=models.TicketPortalResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Router /tickets/manage/{token}/attendee [put]
+func (h *TicketPortalHandler) UpdateAttendee(c *gin.Context) {
+	var req models.TicketAttendeeUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	ticket, err := h.portalService.UpdateAttendeeName(c.Request.Context(), c.Param("token"), &req)
+	if err != nil {
+		h.handleError(c, "Failed to update attendee name", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Attendee name updated successfully", ticket)
+}
+
+// ResendEmail godoc
+// @Summary Resend a ticket email
+// @Description Sends the ticket email again to the attendee's address, at most once per TICKET_RESEND_COOLDOWN
+// @Tags tickets
+// @Produce json
+// @Param token path string true "Ticket token from the ticket email"
+// @Success 202 {object} utils.Response
+// @Failure 400 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 429 {object} utils.Response
+// @Router /tickets/manage/{token}/resend [post]
+func (h *TicketPortalHandler) ResendEmail(c *gin.Context) {
+	if err := h.portalService.ResendEmail(c.Request.Context(), c.Param("token")); err != nil {
+		h.handleError(c, "Failed to resend ticket email", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusAccepted, "Ticket email will be resent shortly", nil)
+}
+
+// GetCalendar godoc
+// @Summary Add a ticket's event to a calendar
+// @Description Returns an iCalendar file with the event of the ticket, for calendar apps
+// @Tags tickets
+// @Produce text/calendar
+// @Param token path string true "Ticket token from the ticket email"
+// @Success 200 {file} file
+// @Failure 404 {object} utils.Response
+// @Router /tickets/manage/{token}/calendar.ics [get]
+func (h *TicketPortalHandler) GetCalendar(c *gin.Context) {
+	calendar, err := h.portalService.Calendar(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		h.handleError(c, "Failed to build calendar file", err)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="event.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", calendar)
+}
+
+func (h *TicketPortalHandler) handleError(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.Is(err, services.ErrTicketNotFound):
+		utils.NotFoundErrorResponse(c, "Ticket not found", err)
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TicketRevocationHandler struct {
+	revocationService *services.TicketRevocationService
+}
+
+func NewTicketRevocationHandler(revocationService *services.TicketRevocationService) *TicketRevocationHandler {
+	return &TicketRevocationHandler{revocationService: revocationService}
+}
+
+// RevokeTicket godoc
+// @Summary Revoke a ticket
+// @Description Revokes a ticket after a chargeback, fraud or a policy violation. Its QR code is refused at the door with the revoked result straight away, by scanners and manual check-in alike, and the ticket can no longer be downloaded or added to a wallet. The holder is emailed the reason unless silent is set; the note stays internal. The ticket keeps its status and can be reinstated.
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param ticketId path string true "Ticket ID"
+// @Param request body models.TicketRevokeRequest true "Reason and internal note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.TicketRevocation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/tickets/{ticketId}/revoke [post]
+func (h *TicketRevocationHandler) RevokeTicket(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.TicketRevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	revocation, err := h.revocationService.Revoke(c.Request.Context(), orgID, middleware.UUIDParam(c, "ticketId"), actorID, &req)
+	if err != nil {
+		ticketRevocationErrorResponse(c, "Failed to revoke ticket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket revoked successfully", revocation)
+}
+
+// ReinstateTicket godoc
+// @Summary Reinstate a revoked ticket
+// @Description Lifts the revocation of a ticket, e.g. when a chargeback is won, so its existing QR code admits the holder again. The holder is emailed unless silent is set.
+// @Tags tickets
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param ticketId path string true "Ticket ID"
+// @Param request body models.TicketReinstateRequest false "Internal note"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=models.TicketRevocation}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 409 {object} utils.Response
+// @Router /organizations/{id}/tickets/{ticketId}/reinstate [post]
+func (h *TicketRevocationHandler) ReinstateTicket(c *gin.Context) {
+	orgID, actorID, ok := organizationRoleActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.TicketReinstateRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
+			return
+		}
+	}
+
+	revocation, err := h.revocationService.Reinstate(c.Request.Context(), orgID, middleware.UUIDParam(c, "ticketId"), actorID, &req)
+	if err != nil {
+		ticketRevocationErrorResponse(c, "Failed to reinstate ticket", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Ticket reinstated successfully", revocation)
+}
+
+// ListTicketRevocations godoc
+// @Summary List ticket revocations
+// @Description Returns a page of the organization's ticket revocations with their tickets, most recent first. Revocations that were lifted are kept with who reinstated the ticket and when.
+// @Tags tickets
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param event_id query int false "Event ID"
+// @Param reason query string false "chargeback, fraud, policy_violation or other"
+// @Param active query bool false "true for revocations in force, false for lifted ones"
+// @Param page query int false "Page number"
+// @Param limit query int false "Page size"
+// @Security ApiKeyAuth
+// @Security OrganizationAPIKey
+// @Success 200 {object} utils.Response{data=[]models.TicketRevocation,meta=models.PageMeta}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /organizations/{id}/ticket-revocations [get]
+func (h *TicketRevocationHandler) ListTicketRevocations(c *gin.Context) {
+	orgID := middleware.UUIDParam(c, "id")
+	query := c.MustGet("validatedQuery").(*models.TicketRevocationQuery)
+
+	revocations, meta, err := h.revocationService.List(c.Request.Context(), orgID, query)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to fetch ticket revocations", err)
+		return
+	}
+
+	utils.PaginatedResponse(c, "Ticket revocations fetched successfully", revocations, meta)
+}
+
+func ticketRevocationErrorResponse(c *gin.Context, message string, err error) {
+	var appErr *utils.AppError
+	switch {
+	case errors.As(err, &appErr):
+		utils.HandleAppError(c, appErr)
+	case errors.Is(err, services.ErrTicketNotFound):
+		utils.NotFoundErrorResponse(c, "Ticket not found", err)
+	default:
+		utils.InternalServerErrorResponse(c, message, err)
+	}
+}
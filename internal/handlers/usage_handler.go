@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsageHandler struct {
+	usageService *services.UsageService
+}
+
+func NewUsageHandler(usageService *services.UsageService) *UsageHandler {
+	return &UsageHandler{usageService: usageService}
+}
+
+// GetLimits godoc
+// @Summary Get the caller's rate limit and usage
+// @Description Returns the caller's current rate limit allowance, the same figures as the X-RateLimit-* response headers, and this month's metered usage, so integrators can throttle themselves before requests are rejected
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.LimitsResponse}
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /me/limits [get]
+func (h *UsageHandler) GetLimits(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	usage, err := h.usageService.MonthlyUsage(c.Request.Context(), userID, c.GetString("email"))
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve usage", err)
+		return
+	}
+
+	response := models.LimitsResponse{Usage: *usage}
+	if status, ok := middleware.CurrentRateLimit(c); ok {
+		response.RateLimit = &status
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Limits retrieved successfully", response)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UsernameHandler struct {
+	usernameService *services.UsernameService
+}
+
+func NewUsernameHandler(usernameService *services.UsernameService) *UsernameHandler {
+	return &UsernameHandler{usernameService: usernameService}
+}
+
+// CheckAvailability godoc
+// @Summary Check username availability
+// @Description Tells whether a username can be taken. Usernames are 3 to 20 letters, digits or underscores and case-insensitive. Unavailable usernames come with a reason: taken, reserved, or recently_used by another user who dropped it within the last 30 days.
+// @Tags users
+// @Produce json
+// @Param username query string true "Username to check"
+// @Success 200 {object} utils.Response{data=models.UsernameAvailability}
+// @Failure 400 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /usernames/available [get]
+func (h *UsernameHandler) CheckAvailability(c *gin.Context) {
+	var req models.UsernameAvailabilityRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid username", err)
+		return
+	}
+
+	availability, err := h.usernameService.Availability(c.Request.Context(), req.Username)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to check username", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Username availability retrieved successfully", availability)
+}
+
+// ChangeUsername godoc
+// @Summary Change username
+// @Description Takes, changes or, with an empty username, removes the user's public handle. Usernames can be changed once per day. The previous username keeps resolving to the user's public profile, and stays reserved for them, for 30 days.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangeUsernameRequest true "New username"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.UserProfileResponse}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Router /auth/username [put]
+func (h *UsernameHandler) ChangeUsername(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	var req models.ChangeUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid request data", err)
+		return
+	}
+
+	profile, err := h.usernameService.ChangeUsername(c.Request.Context(), userID, &req)
+	if err != nil {
+		utils.BadRequestErrorResponse(c, "Failed to change username", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Username changed successfully", profile)
+}
+
+// GetUsernameHistory godoc
+// @Summary Get username history
+// @Description Lists the user's username changes, newest first
+// @Tags auth
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.UsernameChange}
+// @Failure 401 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /auth/username/history [get]
+func (h *UsernameHandler) GetUsernameHistory(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "Unauthorized", nil)
+		return
+	}
+
+	changes, err := h.usernameService.History(c.Request.Context(), userID)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to get username history", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Username history retrieved successfully", changes)
+}
+
+// GetPublicProfile godoc
+// @Summary Get a public profile
+// @Description Returns the public profile behind a username, for profile pages and resolving @mentions. A username its owner changed within the last 30 days still resolves, with renamed set so the frontend can redirect to the current username.
+// @Tags public
+// @Produce json
+// @Param username path string true "Username"
+// @Success 200 {object} utils.Response{data=models.PublicProfile}
+// @Failure 404 {object} utils.Response
+// @Failure 500 {object} utils.Response
+// @Router /public/users/{username} [get]
+func (h *UsernameHandler) GetPublicProfile(c *gin.Context) {
+	profile, err := h.usernameService.PublicProfile(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if errors.Is(err, services.ErrUsernameNotFound) {
+			utils.NotFoundErrorResponse(c, "User not found", err)
+			return
+		}
+		utils.InternalServerErrorResponse(c, "Failed to get profile", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Profile retrieved successfully", profile)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletService *services.WalletService
+}
+
+func NewWalletHandler(walletService *services.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// GetWalletPass godoc
+// @Summary Add a ticket to a phone wallet
+// @Description Returns a ticket held by the signed-in user or bought in one of their orders for a phone wallet, with the same QR code as the ticket PDF. For apple the response is a signed .pkpass file to open on the device; for google it is a link that saves the ticket to Google Wallet.
+// @Tags tickets
+// @Produce application/vnd.apple.pkpass
+// @Produce json
+// @Param id path string true "Ticket ID"
+// @Param provider path string true "Wallet" Enums(apple, google)
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=models.WalletPassLink}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 404 {object} utils.Response
+// @Failure 503 {object} utils.Response
+// @Router /tickets/{id}/wallet/{provider} [get]
+func (h *WalletHandler) GetWalletPass(c *gin.Context) {
+	userID, exists := middleware.CurrentUserID(c)
+	if !exists {
+		utils.UnauthorizedErrorResponse(c, "User not authenticated", nil)
+		return
+	}
+	ticketID := middleware.UUIDParam(c, "id")
+
+	switch c.Param("provider") {
+	case models.WalletProviderApple:
+		ticket, pass, err := h.walletService.ApplePass(c.Request.Context(), ticketID, userID)
+		if err != nil {
+			h.handleError(c, "Failed to generate Apple Wallet pass", err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", services.WalletPassFilename(ticket)))
+		c.Data(http.StatusOK, services.WalletPassContentType, pass)
+	case models.WalletProviderGoogle:
+		link, err := h.walletService.GoogleSaveLink(c.Request.Context(), ticketID, userID)
+		if err != nil {
+			h.handleError(c, "Failed to generate Google Wallet pass", err)
+			return
+		}
+		utils.SuccessResponse(c, http.StatusOK, "Google Wallet link generated", link)
+	default:
+		utils.BadRequestErrorResponse(c, "Unsupported wallet, use apple or google", nil)
+	}
+}
+
+func (h *WalletHandler) handleError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrTicketNotFound):
+		utils.NotFoundErrorResponse(c, "Ticket not found", err)
+	case errors.Is(err, services.ErrAppleWalletNotConfigured), errors.Is(err, services.ErrGoogleWalletNotConfigured):
+		utils.ServiceUnavailableErrorResponse(c, err.Error(), nil)
+	default:
+		utils.BadRequestErrorResponse(c, message, err)
+	}
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WarehouseHandler struct {
+	warehouseService *services.WarehouseExportService
+}
+
+func NewWarehouseHandler(warehouseService *services.WarehouseExportService) *WarehouseHandler {
+	return &WarehouseHandler{
+		warehouseService: warehouseService,
+	}
+}
+
+// ListWarehousePartitions godoc
+// @Summary List warehouse export partitions
+// @Description Returns the manifest of exported daily partitions of the anonymized orders, tickets and check-ins fact tables, newest first, with their S3 location and status
+// @Tags admin
+// @Produce json
+// @Param dataset query string false "Dataset" Enums(orders, tickets, check_ins)
+// @Param from query string false "First partition date (YYYY-MM-DD)"
+// @Param to query string false "Last partition date (YYYY-MM-DD)"
+// @Security ApiKeyAuth
+// @Success 200 {object} utils.Response{data=[]models.WarehouseExport}
+// @Failure 400 {object} utils.Response
+// @Failure 401 {object} utils.Response
+// @Failure 403 {object} utils.Response
+// @Router /admin/warehouse/partitions [get]
+func (h *WarehouseHandler) ListWarehousePartitions(c *gin.Context) {
+	var filter models.WarehouseExportFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.ValidationErrorResponse(c, "Invalid query parameters", err)
+		return
+	}
+
+	partitions, err := h.warehouseService.ListPartitions(c.Request.Context(), &filter)
+	if err != nil {
+		utils.InternalServerErrorResponse(c, "Failed to retrieve warehouse partitions", err)
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, "Warehouse partitions retrieved successfully", partitions)
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiKeyKey is the context key of the organization API key a request authenticated with
+const apiKeyKey = "apiKey"
+
+// APIKeyOrAuth authenticates requests with an organization API key in the X-API-Key header, or
+// with a user token like AuthMiddleware when there is none. Requests made with a key act for the
+// organizer who created it, without their roles, and only get through
+// OrganizationPermissionRequired within the key's organization and scopes.
+func APIKeyOrAuth(cfg *config.Config, apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	auth := newTokenAuth(cfg)
+
+	return func(c *gin.Context) {
+		secret := c.GetHeader("X-API-Key")
+		if secret == "" {
+			if authenticate(c, auth) {
+				c.Next()
+			}
+			return
+		}
+
+		key, err := apiKeyService.Authenticate(c.Request.Context(), secret)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				utils.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
+			} else {
+				utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to authenticate API key", nil)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(userIDKey, key.CreatedBy)
+		c.Set("roles", []string{})
+		c.Set(apiKeyKey, key)
+		c.Next()
+	}
+}
+
+// CurrentAPIKey returns the organization API key a request authenticated with, or false when it
+// was made with a user token
+func CurrentAPIKey(c *gin.Context) (*models.APIKey, bool) {
+	value, exists := c.Get(apiKeyKey)
+	if !exists {
+		return nil, false
+	}
+	key, ok := value.(*models.APIKey)
+	return key, ok
+}
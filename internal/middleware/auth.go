@@ -1,9 +1,13 @@
 package middleware
 
 import (
+	"errors"
+	"log"
 	"net/http"
+	"slices"
 	"strings"
 
+	"event-ticketing-backend/internal/database"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/config"
 	"event-ticketing-backend/pkg/utils"
@@ -12,45 +16,117 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrTokenRevoked is returned for access tokens revoked before they expired
+var ErrTokenRevoked = errors.New("Token has been revoked")
+
+// tokenAuth verifies user access tokens: their signature and expiry, and that they were not
+// revoked by a logout, a password change or a locked account
+type tokenAuth struct {
+	jwtService *utils.JWTService
+	denylist   *services.AccessTokenDenylistService
+}
+
+func newTokenAuth(cfg *config.Config) *tokenAuth {
+	return &tokenAuth{
+		jwtService: utils.NewJWTService(&cfg.JWT),
+		denylist:   services.NewAccessTokenDenylistService(cfg),
+	}
+}
+
+// validate returns the claims of a valid access token that was not revoked. Refresh tokens are
+// rejected, as they outlive the revocations of their user. While Redis is unreachable
+// revocations cannot be checked, and tokens stay valid until they expire.
+func (a *tokenAuth) validate(c *gin.Context, tokenString string) (*utils.Claims, error) {
+	claims, err := a.jwtService.ValidateAccessToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := a.denylist.IsRevoked(c.Request.Context(), claims)
+	if err != nil {
+		log.Printf("Failed to check access token denylist: User=%s, Error=%v", claims.UserID, err)
+		return claims, nil
+	}
+	if revoked {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
 // AuthMiddleware is a middleware that verifies JWT tokens
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
-	jwtService := utils.NewJWTService(&cfg.JWT)
+	auth := newTokenAuth(cfg)
 
 	return func(c *gin.Context) {
-		// Get Authorization header
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header missing", nil)
-			c.Abort()
+		if !authenticate(c, auth) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Check if it's a Bearer token
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format", nil)
-			c.Abort()
-			return
-		}
+// authenticate verifies the bearer JWT of a request and sets the user info in the context. It
+// responds and aborts the request when the token is missing, invalid or revoked.
+func authenticate(c *gin.Context, auth *tokenAuth) bool {
+	// Get Authorization header
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Authorization header missing", nil)
+		c.Abort()
+		return false
+	}
 
-		// Extract token
-		tokenString := parts[1]
+	// Check if it's a Bearer token
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid authorization format", nil)
+		c.Abort()
+		return false
+	}
 
-		// Validate token
-		claims, err := jwtService.ValidateToken(tokenString)
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
-			c.Abort()
-			return
-		}
+	// Extract token
+	tokenString := parts[1]
 
-		// Set user info in context
-		c.Set("userID", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Set("roles", claims.Roles)
+	// Validate token
+	claims, err := auth.validate(c, tokenString)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+		c.Abort()
+		return false
+	}
 
-		c.Next()
+	// Set user info in context
+	c.Set(userIDKey, claims.UserID)
+	c.Set(tokenClaimsKey, claims)
+	c.Set("email", claims.Email)
+	c.Set("roles", claims.Roles)
+	if claims.Scope == utils.ScopeElevated && claims.ElevationID != nil {
+		c.Set("elevationID", *claims.ElevationID)
+	}
+	return allowWrite(c, claims.Roles)
+}
+
+// auditorWrites are the writes auditors may still make: managing their own session and password
+var auditorWrites = map[string]bool{
+	"/api/v1/auth/logout":          true,
+	"/api/v1/auth/change-password": true,
+}
+
+// allowWrite keeps auditors read-only. It runs whenever a request is authenticated, so write
+// endpoints added later are closed to auditors without opting in. It responds and aborts the
+// request when an auditor attempts a write.
+func allowWrite(c *gin.Context, roles []string) bool {
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if !slices.Contains(roles, database.AuditorRole) || auditorWrites[c.FullPath()] {
+		return true
 	}
+
+	utils.ErrorResponse(c, http.StatusForbidden, "Permission denied: auditors have read-only access", nil)
+	c.Abort()
+	return false
 }
 
 // RoleRequired middleware checks if the user has a specific role
@@ -82,33 +158,31 @@ func RoleRequired(role string) gin.HandlerFunc {
 // PermissionRequired middleware checks if the user has a specific permission
 func PermissionRequired(resource, action string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get user ID from context
-		userID, exists := c.Get("userID")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized", nil)
-			c.Abort()
-			return
-		}
-
-		// Get user with roles and permissions
-		authService := services.NewAuthService(nil) // This isn't ideal, should be injected
-		user, err := authService.GetUserByID(userID.(uuid.UUID))
-		if err != nil {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load user data", nil)
-			c.Abort()
+		if !requirePermission(c, resource, action) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		// Check if user has the required permission
-		if utils.HasPermission(user, resource, action) {
-			c.Next()
-			return
-		}
+// requirePermission checks that the authenticated user has a permission. It responds and aborts
+// the request when they do not.
+func requirePermission(c *gin.Context, resource, action string) bool {
+	// Get user with roles and permissions
+	user, ok := requireCurrentUser(c)
+	if !ok {
+		return false
+	}
 
-		// User doesn't have the required permission
-		utils.ErrorResponse(c, http.StatusForbidden, "Permission denied: Required permission not found", nil)
-		c.Abort()
+	// Check if user has the required permission
+	if utils.HasPermission(user, resource, action) {
+		return true
 	}
+
+	// User doesn't have the required permission
+	utils.ErrorResponse(c, http.StatusForbidden, "Permission denied: Required permission not found", nil)
+	c.Abort()
+	return false
 }
 
 // AnyRoleRequired middleware checks if the user has any of the specified roles
@@ -149,9 +223,47 @@ func IsAdmin() gin.HandlerFunc {
 	return RoleRequired("admin")
 }
 
+// IsAdminOrAuditor checks if the user is an admin or an auditor. Auditors only get through for
+// reads, as authentication rejects their writes.
+func IsAdminOrAuditor() gin.HandlerFunc {
+	return AnyRoleRequired(database.AdminRole, database.AuditorRole)
+}
+
+// ElevationRequired guards destructive admin actions behind break-glass elevation: the request
+// must carry an elevated access token whose elevation is still active. Each use is audited.
+func ElevationRequired(elevationService *services.ElevationService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := CurrentUserID(c)
+		if !ok {
+			utils.ErrorResponse(c, http.StatusUnauthorized, "Unauthorized", nil)
+			c.Abort()
+			return
+		}
+		elevationID, elevated := c.Get("elevationID")
+		if !elevated {
+			utils.ErrorResponse(c, http.StatusForbidden, services.ErrElevationRequired.Error(), nil)
+			c.Abort()
+			return
+		}
+
+		action := c.Request.Method + " " + c.FullPath()
+		err := elevationService.AuthorizeElevated(c.Request.Context(), elevationID.(uuid.UUID), userID, action)
+		if err != nil {
+			if errors.Is(err, services.ErrElevationRequired) {
+				utils.ErrorResponse(c, http.StatusForbidden, err.Error(), nil)
+			} else {
+				utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check elevation", err)
+			}
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // GetUserFromToken extracts user info from token and attaches to the context
 func GetUserFromToken(cfg *config.Config) gin.HandlerFunc {
-	jwtService := utils.NewJWTService(&cfg.JWT)
+	auth := newTokenAuth(cfg)
 
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -169,18 +281,22 @@ func GetUserFromToken(cfg *config.Config) gin.HandlerFunc {
 		}
 
 		tokenString := parts[1]
-		claims, err := jwtService.ValidateToken(tokenString)
+		claims, err := auth.validate(c, tokenString)
 		if err != nil {
-			// Invalid token, continue as unauthenticated
+			// Invalid or revoked token, continue as unauthenticated
 			c.Next()
 			return
 		}
 
 		// Set user info in context
-		c.Set("userID", claims.UserID)
+		c.Set(userIDKey, claims.UserID)
+		c.Set(tokenClaimsKey, claims)
 		c.Set("email", claims.Email)
 		c.Set("roles", claims.Roles)
 		c.Set("authenticated", true)
+		if !allowWrite(c, claims.Roles) {
+			return
+		}
 
 		c.Next()
 	}
@@ -19,7 +19,7 @@ func CORS() gin.HandlerFunc {
 		}
 
 		allowedMethods := "GET,POST,PUT,DELETE,OPTIONS,PATCH"
-		allowedHeaders := "Content-Type,Content-Length,Accept-Encoding,X-CSRF-Token,Authorization,accept,origin,Cache-Control,X-Requested-With"
+		allowedHeaders := "Content-Type,Content-Length,Accept-Encoding,X-CSRF-Token,Authorization,accept,origin,Cache-Control,X-Requested-With,Idempotency-Key,X-Tenant"
 
 		// Check if the request origin is in the allowed origins list
 		origin := c.Request.Header.Get("Origin")
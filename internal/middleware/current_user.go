@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Context keys of the authenticated user. Authentication sets userIDKey and tokenClaimsKey from
+// the token; userKey caches the user record once CurrentUser has loaded it.
+const (
+	userIDKey      = "userID"
+	userKey        = "user"
+	tokenClaimsKey = "tokenClaims"
+)
+
+// ErrNotAuthenticated is returned by CurrentUser for requests without a valid token
+var ErrNotAuthenticated = errors.New("User not authenticated")
+
+// CurrentUserID returns the ID of the authenticated user, or false when the request is not
+// authenticated
+func CurrentUserID(c *gin.Context) (uuid.UUID, bool) {
+	value, exists := c.Get(userIDKey)
+	if !exists {
+		return uuid.Nil, false
+	}
+	id, ok := value.(uuid.UUID)
+	return id, ok && id != uuid.Nil
+}
+
+// AccessTokenClaims returns the claims of the access token a request authenticated with, or false
+// when the request did not authenticate with one
+func AccessTokenClaims(c *gin.Context) (*utils.Claims, bool) {
+	value, exists := c.Get(tokenClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*utils.Claims)
+	return claims, ok
+}
+
+// CurrentUser returns the authenticated user with their roles and permissions. The user is loaded
+// on first use and cached on the context for the rest of the request.
+func CurrentUser(c *gin.Context) (*models.User, error) {
+	if cached, exists := c.Get(userKey); exists {
+		if user, ok := cached.(*models.User); ok {
+			return user, nil
+		}
+	}
+
+	userID, ok := CurrentUserID(c)
+	if !ok {
+		return nil, ErrNotAuthenticated
+	}
+
+	var user models.User
+	if err := database.DB.WithContext(c.Request.Context()).Preload("Roles.Permissions").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotAuthenticated
+		}
+		return nil, err
+	}
+	c.Set(userKey, &user)
+	return &user, nil
+}
+
+// LoadCurrentUser returns a middleware that loads the authenticated user for handlers and later
+// middleware, which read it with CurrentUser. It must run after authentication and rejects
+// requests whose account no longer exists.
+func LoadCurrentUser() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := requireCurrentUser(c); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+// requireCurrentUser returns the authenticated user. It responds and aborts the request when
+// there is none or it cannot be loaded.
+func requireCurrentUser(c *gin.Context) (*models.User, bool) {
+	user, err := CurrentUser(c)
+	if err != nil {
+		if errors.Is(err, ErrNotAuthenticated) {
+			utils.ErrorResponse(c, http.StatusUnauthorized, err.Error(), nil)
+		} else {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to load user data", err)
+		}
+		c.Abort()
+		return nil, false
+	}
+	return user, true
+}
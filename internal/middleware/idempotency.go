@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader carries a client-chosen key identifying one logical request
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	idempotencyTTL        = 24 * time.Hour
+	idempotencyLockTTL    = time.Minute
+	idempotencyMaxKeySize = 255
+	idempotencyMaxBody    = 1 << 20
+)
+
+// idempotentResponse is the stored outcome of a request. A zero status marks a request still in progress.
+type idempotentResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+	RequestHash string `json:"request_hash"`
+}
+
+// idempotencyRecorder captures the response body so it can be replayed
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the stored response of POST and PATCH requests retried with the same
+// Idempotency-Key, so clients can safely retry writes after a timeout. Keys are scoped to the
+// caller's credentials, method and path. Server errors are not stored so the request can be retried.
+// Without Redis the middleware lets requests through unchanged.
+func Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || (c.Request.Method != http.MethodPost && c.Request.Method != http.MethodPatch) || redis.Client == nil {
+			c.Next()
+			return
+		}
+		if len(key) > idempotencyMaxKeySize {
+			utils.HandleAppError(c, utils.NewValidationError("Idempotency-Key is too long", nil))
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, idempotencyMaxBody))
+		if err != nil {
+			utils.BadRequestErrorResponse(c, "Failed to read request body", err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := sha256.Sum256([]byte(c.GetHeader("Authorization") + "|" + c.Request.Method + "|" + c.Request.URL.Path + "|" + key))
+		cacheKey := "idempotency:" + hex.EncodeToString(scope[:])
+		bodySum := sha256.Sum256(body)
+		requestHash := hex.EncodeToString(bodySum[:])
+		ctx := c.Request.Context()
+
+		pending, _ := json.Marshal(idempotentResponse{RequestHash: requestHash})
+		claimed, err := redis.Client.SetNX(ctx, cacheKey, pending, idempotencyLockTTL).Result()
+		if err != nil {
+			log.Printf("Idempotency check failed, processing request: %v", err)
+			c.Next()
+			return
+		}
+
+		if !claimed {
+			var stored idempotentResponse
+			raw, err := redis.Client.Get(ctx, cacheKey).Bytes()
+			if err != nil || json.Unmarshal(raw, &stored) != nil {
+				utils.HandleAppError(c, utils.NewConflictError("A request with this Idempotency-Key is being processed"))
+				c.Abort()
+				return
+			}
+			switch {
+			case stored.RequestHash != requestHash:
+				utils.HandleAppError(c, utils.NewConflictError("Idempotency-Key was already used with a different request body"))
+			case stored.Status == 0:
+				utils.HandleAppError(c, utils.NewConflictError("A request with this Idempotency-Key is being processed"))
+			default:
+				c.Header("Idempotent-Replayed", "true")
+				c.Data(stored.Status, stored.ContentType, stored.Body)
+			}
+			c.Abort()
+			return
+		}
+
+		recorder := &idempotencyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			redis.Client.Del(ctx, cacheKey)
+			return
+		}
+
+		stored, _ := json.Marshal(idempotentResponse{
+			Status:      status,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+			RequestHash: requestHash,
+		})
+		if err := redis.Client.Set(ctx, cacheKey, stored, idempotencyTTL).Err(); err != nil {
+			log.Printf("Failed to store idempotent response: %v", err)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"time"
 
+	"event-ticketing-backend/internal/database"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -13,11 +15,15 @@ func Logger() gin.HandlerFunc {
 		path := c.Request.URL.Path
 		method := c.Request.Method
 
+		// Count the database statements the request runs
+		ctx := database.WithQueryCount(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 
 		duration := time.Since(start)
 		statusCode := c.Writer.Status()
 
-		log.Printf("[%s] %s - %d - %v", method, path, statusCode, duration)
+		log.Printf("[%s] %s - %d - %v - %d queries", method, path, statusCode, duration, database.QueryCount(ctx))
 	}
 }
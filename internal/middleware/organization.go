@@ -2,59 +2,44 @@ package middleware
 
 import (
 	"net/http"
+	"slices"
 
 	"event-ticketing-backend/internal/database"
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// IsOrganizerOfOrganization returns a middleware that checks if the user is an organizer
-// and if they're associated with the organization specified in the URL parameter
+// IsOrganizerOfOrganization returns a middleware that lets through admins, the organizer of the
+// organization specified in the URL parameter or of an ancestor it inherits permissions from, and
+// active members holding the organizer role in that organization. Roles are resolved per
+// organization, so organizing one organization grants nothing in another.
 func IsOrganizerOfOrganization() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get user from context (set by AuthMiddleware)
-		userInterface, exists := c.Get("user")
-		if !exists {
-			utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated", nil)
-			c.Abort()
-			return
-		}
-
-		user, ok := userInterface.(*models.User)
+		user, ok := requireCurrentUser(c)
 		if !ok {
-			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get user information", nil)
-			c.Abort()
 			return
 		}
 
-		// Check if user has organizer or admin role
+		// Check if user has organizer or admin role. Auditors read every organization like admins;
+		// their writes never get here.
 		hasOrganizerRole := false
 		hasAdminRole := false
 		for _, role := range user.Roles {
 			if role.Name == "organizer" {
 				hasOrganizerRole = true
 			}
-			if role.Name == "admin" {
+			if role.Name == "admin" || role.Name == database.AuditorRole {
 				hasAdminRole = true
 			}
 		}
 
-		// Check if user is an organizer or admin
-		if !hasOrganizerRole && !hasAdminRole {
-			utils.ErrorResponse(c, http.StatusForbidden, "Access denied: requires organizer role", nil)
-			c.Abort()
-			return
-		}
-
 		// Get organization ID from URL parameters
-		orgID := c.Param("id")
-		if orgID == "" {
-			utils.ErrorResponse(c, http.StatusBadRequest, "Organization ID is required", nil)
-			c.Abort()
-			return
-		}
+		orgID := UUIDParam(c, "id")
 
 		// If user is admin, allow access to any organization
 		if hasAdminRole {
@@ -66,7 +51,7 @@ func IsOrganizerOfOrganization() gin.HandlerFunc {
 		var organization models.Organization
 
 		// Use the database connection from the service layer
-		db := database.DB
+		db := database.DB.WithContext(c.Request.Context())
 
 		result := db.First(&organization, "id = ?", orgID)
 		if result.Error != nil {
@@ -75,8 +60,18 @@ func IsOrganizerOfOrganization() gin.HandlerFunc {
 			return
 		}
 
-		// Check if user is the organizer for this organization
-		if organization.OrganizerID != user.ID {
+		// Check if user is the organizer for this organization, or of an ancestor it inherits
+		// permissions from, or holds the organizer role in it
+		organizes := hasOrganizerRole && (organization.OrganizerID == user.ID || organizesAncestor(db, &organization, user.ID))
+		if !organizes {
+			var err error
+			if organizes, err = isOrganizerMember(db, orgID, user.ID); err != nil {
+				utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check organization membership", err)
+				c.Abort()
+				return
+			}
+		}
+		if !organizes {
 			utils.ErrorResponse(c, http.StatusForbidden, "Access denied: you are not the organizer of this organization", nil)
 			c.Abort()
 			return
@@ -87,3 +82,101 @@ func IsOrganizerOfOrganization() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OrganizationTenant scopes the request context to the organization specified in the URL
+// parameter, so database statements run with c.Request.Context() only reach that organization's
+// rows. The parameter must be validated by ValidateUUIDParam first.
+func OrganizationTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		orgID := UUIDParam(c, "id")
+		c.Request = c.Request.WithContext(database.WithOrganization(c.Request.Context(), orgID))
+		c.Next()
+	}
+}
+
+// isOrganizerMember reports whether the user is an active member of the organization with the
+// organizer role
+func isOrganizerMember(db *gorm.DB, orgID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ? AND role_name = ? AND active", orgID, userID, models.MemberRoleOrganizer).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// organizesAncestor reports whether the user is the organizer of an ancestor organization whose
+// permissions reach org. Permissions pass down only while each organization on the way inherits them.
+func organizesAncestor(db *gorm.DB, org *models.Organization, userID uuid.UUID) bool {
+	for org.InheritPermissions && org.ParentID != nil {
+		var parent models.Organization
+		if err := db.First(&parent, "id = ?", *org.ParentID).Error; err != nil {
+			return false
+		}
+		if parent.OrganizerID == userID {
+			return true
+		}
+		org = &parent
+	}
+	return false
+}
+
+// OrganizationPermissionRequired returns a middleware that lets through the organizers of the
+// organization specified in the URL parameter, organizers of ancestors it inherits permissions
+// from, admins, members holding the permission through a global role or a custom role of the
+// organization, and API keys of the organization scoped to the permission
+func OrganizationPermissionRequired(roleService *services.OrganizationRoleService, resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := CurrentUserID(c)
+		if !ok {
+			utils.ErrorResponse(c, http.StatusUnauthorized, ErrNotAuthenticated.Error(), nil)
+			c.Abort()
+			return
+		}
+
+		orgID := UUIDParam(c, "id")
+		db := database.DB.WithContext(c.Request.Context())
+
+		var organization models.Organization
+		if err := db.First(&organization, "id = ?", orgID).Error; err != nil {
+			utils.ErrorResponse(c, http.StatusNotFound, "Organization not found", err)
+			c.Abort()
+			return
+		}
+
+		if key, ok := CurrentAPIKey(c); ok {
+			if key.OrganizationID != orgID || !key.Allows(resource, action) {
+				utils.ErrorResponse(c, http.StatusForbidden, "Permission denied: API key is not scoped to this permission", nil)
+				c.Abort()
+				return
+			}
+			c.Set("organization", organization)
+			c.Next()
+			return
+		}
+
+		roles, _ := c.Get("roles")
+		roleNames, _ := roles.([]string)
+		if slices.Contains(roleNames, "admin") ||
+			organization.OrganizerID == userID ||
+			organizesAncestor(db, &organization, userID) {
+			c.Set("organization", organization)
+			c.Next()
+			return
+		}
+
+		allowed, err := roleService.MemberHasPermission(c.Request.Context(), orgID, userID, resource, action)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to check permissions", err)
+			c.Abort()
+			return
+		}
+		if !allowed {
+			utils.ErrorResponse(c, http.StatusForbidden, "Permission denied: Required permission not found", nil)
+			c.Abort()
+			return
+		}
+
+		c.Set("organization", organization)
+		c.Next()
+	}
+}
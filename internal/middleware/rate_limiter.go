@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"math"
 	"net"
 	"net/http"
 	"os"
@@ -9,6 +10,8 @@ import (
 	"sync"
 	"time"
 
+	"event-ticketing-backend/internal/models"
+
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -139,7 +142,9 @@ func InitRateLimiters() {
 	authLimiter = NewIPRateLimiter(rateLimit/5, int(requests/25), 1*time.Hour)
 }
 
-// RateLimiterMiddleware returns a middleware that limits request rate based on client IP
+// RateLimiterMiddleware returns a middleware that limits request rate based on client IP. Every
+// response carries X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers, and
+// rejected requests a Retry-After header.
 func RateLimiterMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip, _, err := net.SplitHostPort(c.Request.RemoteAddr)
@@ -167,7 +172,15 @@ func RateLimiterMiddleware() gin.HandlerFunc {
 			limiter = standardLimiter.GetLimiter(ip)
 		}
 
-		if !limiter.Allow() {
+		allowed := limiter.Allow()
+		status := rateLimitStatus(limiter, time.Now())
+		setRateLimitHeaders(c, status)
+		c.Set("rateLimit", status)
+
+		if !allowed {
+			// Time until one request's worth of allowance is back
+			retryAfter := time.Duration(float64(time.Second) / float64(limiter.Limit()))
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":   "Too many requests",
 				"message": "Rate limit exceeded. Please try again later.",
@@ -180,6 +193,39 @@ func RateLimiterMiddleware() gin.HandlerFunc {
 	}
 }
 
+// rateLimitStatus reports how much of a limiter's burst is left and when it will be full again
+func rateLimitStatus(limiter *rate.Limiter, now time.Time) models.RateLimitStatus {
+	burst := limiter.Burst()
+	tokens := min(limiter.TokensAt(now), float64(burst))
+	status := models.RateLimitStatus{
+		Limit:     burst,
+		Remaining: max(int(tokens), 0),
+		ResetAt:   now,
+	}
+	if missing := float64(burst) - tokens; missing > 0 && limiter.Limit() > 0 {
+		status.ResetAt = now.Add(time.Duration(missing / float64(limiter.Limit()) * float64(time.Second)))
+	}
+	return status
+}
+
+// setRateLimitHeaders advertises the caller's remaining allowance on every response, so clients
+// can slow down before they are rejected
+func setRateLimitHeaders(c *gin.Context, status models.RateLimitStatus) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(status.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(status.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(status.ResetAt.Unix(), 10))
+}
+
+// CurrentRateLimit returns the rate limit status RateLimiterMiddleware recorded for the request
+func CurrentRateLimit(c *gin.Context) (models.RateLimitStatus, bool) {
+	value, exists := c.Get("rateLimit")
+	if !exists {
+		return models.RateLimitStatus{}, false
+	}
+	status, ok := value.(models.RateLimitStatus)
+	return status, ok
+}
+
 // StrictRateLimiter is a more restrictive rate limiter for sensitive operations
 func StrictRateLimiter() gin.HandlerFunc {
 	// Create a new limiter for each call with very restrictive settings
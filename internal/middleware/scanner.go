@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScannerOrStaffAuth authenticates the check-in endpoints of the event in the :id parameter. It
+// accepts the device token of a scanner paired to that event, which acts for the organizer who
+// paired it, or a user token holding the ticket scan permission. Scanners report their app version
+// in the X-App-Version header.
+func ScannerOrStaffAuth(cfg *config.Config, scannerService *services.ScannerService) gin.HandlerFunc {
+	auth := newTokenAuth(cfg)
+
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(token, services.ScannerTokenPrefix) {
+			if authenticate(c, auth) && requirePermission(c, "tickets", "scan") {
+				c.Next()
+			}
+			return
+		}
+
+		eventID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			utils.BadRequestErrorResponse(c, "Invalid event ID", err)
+			c.Abort()
+			return
+		}
+
+		device, err := scannerService.Authenticate(c.Request.Context(), token, uint(eventID), c.GetHeader("X-App-Version"))
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrInvalidScannerToken):
+				utils.ErrorResponse(c, http.StatusUnauthorized, "Invalid or expired token", err)
+			case errors.Is(err, services.ErrEventAccessDenied):
+				utils.ErrorResponse(c, http.StatusForbidden, "Scanner is not paired to this event", nil)
+			default:
+				utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to authenticate scanner", nil)
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(userIDKey, device.PairedBy)
+		c.Set("roles", []string{})
+		c.Set("scannerDeviceID", device.ID)
+		c.Next()
+	}
+}
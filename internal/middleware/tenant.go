@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/url"
+
+	"event-ticketing-backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResolveTenant resolves the white-label tenant of each request and stores it in the request
+// context, where services.TenantFromContext finds it. The X-Tenant header selects a tenant by
+// slug; otherwise the tenant is matched by the domain of the calling frontend, taken from the
+// Origin header, or by the host the API was reached on.
+func ResolveTenant(tenantService *services.TenantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		host := c.Request.Host
+		if origin, err := url.Parse(c.GetHeader("Origin")); err == nil && origin.Host != "" {
+			host = origin.Host
+		}
+
+		tenant := tenantService.Resolve(c.Request.Context(), host, c.GetHeader("X-Tenant"))
+		c.Request = c.Request.WithContext(services.WithTenant(c.Request.Context(), tenant))
+		c.Next()
+	}
+}
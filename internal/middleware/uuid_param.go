@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ValidateUUIDParam parses the named path parameters of the route as UUIDs and stores them for
+// UUIDParam, responding with INVALID_ID when one is malformed. Names the route does not have are
+// skipped, so a group can list the parameters of all its routes.
+func ValidateUUIDParam(names ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, name := range names {
+			raw, ok := c.Params.Get(name)
+			if !ok {
+				continue
+			}
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				utils.InvalidIDErrorResponse(c, name)
+				c.Abort()
+				return
+			}
+			c.Set(uuidParamKey(name), id)
+		}
+		c.Next()
+	}
+}
+
+// UUIDParam returns a path parameter ValidateUUIDParam parsed. It panics when the route does not
+// validate the parameter, which is a routing mistake.
+func UUIDParam(c *gin.Context, name string) uuid.UUID {
+	return c.MustGet(uuidParamKey(name)).(uuid.UUID)
+}
+
+func uuidParamKey(name string) string {
+	return "uuidParam." + name
+}
@@ -1,9 +1,9 @@
 package middleware
 
 import (
-	"net/http"
+	"reflect"
 
-	"event-ticketing-backend/internal/validators"
+	"event-ticketing-backend/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,23 +11,16 @@ import (
 // Validate middleware validates request data against struct validation rules
 func Validate(model interface{}) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Bind request data to the model
-		if err := c.ShouldBindJSON(model); err != nil {
-			// Format validation errors
-			validationErrors := validators.FormatErrors(err)
-
-			// Return validation errors
-			c.JSON(http.StatusBadRequest, gin.H{
-				"status":  "error",
-				"message": "Validation failed",
-				"errors":  validationErrors.Errors,
-			})
+		// Bind request data to a fresh model
+		data := newModel(model)
+		if err := c.ShouldBindJSON(data); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid request data", err)
 			c.Abort()
 			return
 		}
 
 		// Set model in context for controllers to use
-		c.Set("validatedData", model)
+		c.Set("validatedData", data)
 		c.Next()
 	}
 }
@@ -35,23 +28,16 @@ func Validate(model interface{}) gin.HandlerFunc {
 // ValidateQuery middleware validates query parameters
 func ValidateQuery(model interface{}) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Bind query parameters to the model
-		if err := c.ShouldBindQuery(model); err != nil {
-			// Format validation errors
-			validationErrors := validators.FormatErrors(err)
-
-			// Return validation errors
-			c.JSON(http.StatusBadRequest, gin.H{
-				"status":  "error",
-				"message": "Query validation failed",
-				"errors":  validationErrors.Errors,
-			})
+		// Bind query parameters to a fresh model
+		query := newModel(model)
+		if err := c.ShouldBindQuery(query); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid query parameters", err)
 			c.Abort()
 			return
 		}
 
 		// Set model in context for controllers to use
-		c.Set("validatedQuery", model)
+		c.Set("validatedQuery", query)
 		c.Next()
 	}
 }
@@ -59,23 +45,22 @@ func ValidateQuery(model interface{}) gin.HandlerFunc {
 // ValidateURI middleware validates URI parameters
 func ValidateURI(model interface{}) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Bind URI parameters to the model
-		if err := c.ShouldBindUri(model); err != nil {
-			// Format validation errors
-			validationErrors := validators.FormatErrors(err)
-
-			// Return validation errors
-			c.JSON(http.StatusBadRequest, gin.H{
-				"status":  "error",
-				"message": "URI validation failed",
-				"errors":  validationErrors.Errors,
-			})
+		// Bind URI parameters to a fresh model
+		uri := newModel(model)
+		if err := c.ShouldBindUri(uri); err != nil {
+			utils.ValidationErrorResponse(c, "Invalid URI parameters", err)
 			c.Abort()
 			return
 		}
 
 		// Set model in context for controllers to use
-		c.Set("validatedURI", model)
+		c.Set("validatedURI", uri)
 		c.Next()
 	}
 }
+
+// newModel returns a new zero value of the type model points to, so concurrent requests never
+// share or inherit bound values
+func newModel(model interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(model).Elem()).Interface()
+}
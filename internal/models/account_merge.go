@@ -0,0 +1,33 @@
+package models
+
+import "github.com/google/uuid"
+
+// MergeAccountsRequest is the request structure for merging a duplicate account into the account
+// that survives. With dry_run set, nothing is changed and the response previews the merge.
+type MergeAccountsRequest struct {
+	SourceUserID uuid.UUID `json:"source_user_id" binding:"required" example:"0f8fad5b-d9cb-469f-a165-70867728950e"` // Duplicate account, deleted by the merge
+	TargetUserID uuid.UUID `json:"target_user_id" binding:"required" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"` // Account that survives
+	DryRun       bool      `json:"dry_run" example:"true"`
+}
+
+// AccountMergeResult counts what a merge moved, or would move for a dry run, from the duplicate
+// account to the surviving one
+type AccountMergeResult struct {
+	SourceUserID     uuid.UUID `json:"source_user_id"`
+	SourceEmail      string    `json:"source_email"`
+	TargetUserID     uuid.UUID `json:"target_user_id"`
+	TargetEmail      string    `json:"target_email"`
+	DryRun           bool      `json:"dry_run"`
+	Orders           int64     `json:"orders"`  // Including guest orders placed with the duplicate's email
+	Tickets          int64     `json:"tickets"` // Tickets issued to the duplicate's email
+	CheckoutSessions int64     `json:"checkout_sessions"`
+	Events           int64     `json:"events"`        // Events the duplicate organized
+	Organizations    int64     `json:"organizations"` // Organizations the duplicate is the organizer of
+	Membership       bool      `json:"membership"`    // Whether the source's primary organization became the target's
+	Memberships      int64     `json:"memberships"`   // Organization memberships the surviving account gained
+	Roles            int64     `json:"roles"`         // Roles the surviving account gained
+	Tokens           int64     `json:"tokens"`        // Sessions that now refresh into the surviving account
+	Devices          int64     `json:"devices"`
+	Username         bool      `json:"username"` // Whether the username moved
+	Avatar           bool      `json:"avatar"`   // Whether the avatar moved
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountingFormat identifies the accounting package an export is shaped for
+type AccountingFormat string
+
+const (
+	AccountingFormatQuickBooks AccountingFormat = "quickbooks"
+	AccountingFormatXero       AccountingFormat = "xero"
+)
+
+// ExportStatus represents the processing state of a background export
+type ExportStatus string
+
+const (
+	ExportStatusPending   ExportStatus = "pending"
+	ExportStatusCompleted ExportStatus = "completed"
+	ExportStatusFailed    ExportStatus = "failed"
+)
+
+// AccountingExport is a journal export of paid orders, fees and payouts for an organization,
+// grouped into settlement periods
+type AccountingExport struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID         `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Format         AccountingFormat  `gorm:"not null" json:"format"`
+	Granularity    string            `gorm:"not null" json:"granularity"` // weekly or monthly settlement periods
+	PeriodStart    time.Time         `gorm:"not null" json:"period_start"`
+	PeriodEnd      time.Time         `gorm:"not null" json:"period_end"`
+	Accounts       map[string]string `gorm:"serializer:json" json:"accounts"`
+	Status         ExportStatus      `gorm:"not null;default:'pending'" json:"status"`
+	Content        string            `gorm:"type:text" json:"-"`
+	RowCount       int               `json:"row_count"`
+	Error          string            `json:"error,omitempty"`
+	CreatedBy      *uuid.UUID        `gorm:"type:uuid" json:"created_by,omitempty"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// Ledger accounts referenced by accounting exports
+const (
+	AccountingAccountSales    = "sales"
+	AccountingAccountFees     = "fees"
+	AccountingAccountClearing = "clearing"
+)
+
+// CreateAccountingExportRequest is the request structure for requesting an accounting export
+type CreateAccountingExportRequest struct {
+	Format      string            `json:"format" binding:"required,oneof=quickbooks xero" example:"xero"`
+	Granularity string            `json:"granularity" binding:"omitempty,oneof=weekly monthly" example:"monthly"`
+	PeriodStart time.Time         `json:"period_start" binding:"required" example:"2025-01-01T00:00:00Z"`
+	PeriodEnd   time.Time         `json:"period_end" binding:"required,gtfield=PeriodStart" example:"2025-04-01T00:00:00Z"`
+	Accounts    map[string]string `json:"accounts" example:"sales:Ticket Sales,fees:Merchant Fees,clearing:Payouts Clearing"`
+}
+
+// AccountingExportResponse is the response structure for an accounting export
+type AccountingExportResponse struct {
+	ID          uuid.UUID        `json:"id"`
+	Format      AccountingFormat `json:"format"`
+	Granularity string           `json:"granularity"`
+	PeriodStart time.Time        `json:"period_start"`
+	PeriodEnd   time.Time        `json:"period_end"`
+	Status      ExportStatus     `json:"status"`
+	RowCount    int              `json:"row_count"`
+	Error       string           `json:"error,omitempty"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *AccountingExport) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.Status == "" {
+		a.Status = ExportStatusPending
+	}
+	return nil
+}
+
+// ToResponse converts an AccountingExport model to an AccountingExportResponse
+func (a *AccountingExport) ToResponse() AccountingExportResponse {
+	return AccountingExportResponse{
+		ID:          a.ID,
+		Format:      a.Format,
+		Granularity: a.Granularity,
+		PeriodStart: a.PeriodStart,
+		PeriodEnd:   a.PeriodEnd,
+		Status:      a.Status,
+		RowCount:    a.RowCount,
+		Error:       a.Error,
+		CompletedAt: a.CompletedAt,
+		CreatedAt:   a.CreatedAt,
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ElevationStatus represents the state of an admin's elevated access
+type ElevationStatus string
+
+const (
+	ElevationStatusPendingApproval ElevationStatus = "pending_approval"
+	ElevationStatusActive          ElevationStatus = "active" // Granted until ExpiresAt
+	ElevationStatusRejected        ElevationStatus = "rejected"
+	ElevationStatusRevoked         ElevationStatus = "revoked" // Ended before ExpiresAt
+	ElevationStatusExpired         ElevationStatus = "expired"
+)
+
+// AdminElevation is a time-boxed grant of break-glass access an admin requests before destructive
+// actions. While it is active the admin can obtain access tokens carrying the elevated scope.
+type AdminElevation struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      uuid.UUID       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Reason      string          `gorm:"not null" json:"reason"`
+	Duration    int             `gorm:"not null" json:"duration_minutes"` // Length of the grant once active
+	Status      ElevationStatus `gorm:"size:20;not null;index" json:"status"`
+	ReviewedBy  *uuid.UUID      `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewNote  string          `json:"review_note,omitempty"`
+	ReviewedAt  *time.Time      `json:"reviewed_at,omitempty"`
+	GrantedAt   *time.Time      `json:"granted_at,omitempty"`
+	ExpiresAt   *time.Time      `gorm:"index" json:"expires_at,omitempty"`
+	RevokedBy   *uuid.UUID      `gorm:"type:uuid" json:"revoked_by,omitempty"`
+	RevokedAt   *time.Time      `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time       `gorm:"index" json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	AccessToken string          `gorm:"-" json:"access_token,omitempty"` // Elevated access token, returned when the elevation is granted to its requester
+}
+
+// ElevationRequest is the request structure for requesting elevated access
+type ElevationRequest struct {
+	Reason          string `json:"reason" binding:"required,max=500" example:"Delete the duplicate organization reported in SUP-1234"`
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=1" example:"15"`
+}
+
+// ReviewElevationRequest is the request structure for approving or rejecting an elevation
+type ReviewElevationRequest struct {
+	Note string `json:"note" binding:"max=500" example:"Confirmed with the requester"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (e *AdminElevation) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the elevation grants access at now
+func (e *AdminElevation) IsActive(now time.Time) bool {
+	return e.Status == ElevationStatusActive && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
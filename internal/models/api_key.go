@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey lets an integrator call an organization's endpoints without a user token. It is sent in
+// the X-API-Key header and grants only its scopes, permissions such as read:order, within its
+// organization; requests made with it act for the organizer who created it. Only a hash of the
+// key is stored, so the key itself is returned once, when it is created or rotated.
+type APIKey struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID     `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name           string        `gorm:"size:100;not null" json:"name"`
+	Prefix         string        `gorm:"size:16;not null" json:"prefix"` // Start of the key, to tell keys apart
+	KeyHash        string        `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Scopes         []*Permission `gorm:"many2many:api_key_permissions;" json:"-"`
+	CreatedBy      uuid.UUID     `gorm:"type:uuid;not null" json:"created_by"`
+	ExpiresAt      *time.Time    `json:"expires_at,omitempty"`
+	LastUsedAt     *time.Time    `json:"last_used_at,omitempty"`
+	RotatedAt      *time.Time    `json:"rotated_at,omitempty"`
+	RevokedAt      *time.Time    `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// APIKeyRequest is the request structure for creating an organization API key. Scopes are the
+// names of permissions custom organization roles can be composed from, such as "read:order".
+type APIKeyRequest struct {
+	Name      string     `json:"name" binding:"required,max=100" example:"Zapier"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1,dive,required" example:"read:order,read:ticket"`
+	ExpiresAt *time.Time `json:"expires_at" example:"2027-01-01T00:00:00Z"` // Never expires when omitted
+}
+
+// APIKeyResponse is the response structure for an organization API key
+type APIKeyResponse struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix" example:"etk_3f9a1c0d"`
+	Scopes         []string   `json:"scopes" example:"read:order,read:ticket"`
+	Key            string     `json:"key,omitempty"` // Only returned when the key is created or rotated
+	CreatedBy      uuid.UUID  `json:"created_by"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	RotatedAt      *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Allows reports whether one of the key's scopes grants an action on a resource
+func (k *APIKey) Allows(resource, action string) bool {
+	for _, scope := range k.Scopes {
+		if scope.Resource == resource && (scope.Action == action || scope.Action == "*") {
+			return true
+		}
+	}
+	return false
+}
+
+// ToResponse converts an APIKey model to an APIKeyResponse
+func (k *APIKey) ToResponse() APIKeyResponse {
+	scopes := make([]string, len(k.Scopes))
+	for i, scope := range k.Scopes {
+		scopes[i] = scope.Name
+	}
+
+	return APIKeyResponse{
+		ID:             k.ID,
+		OrganizationID: k.OrganizationID,
+		Name:           k.Name,
+		Prefix:         k.Prefix,
+		Scopes:         scopes,
+		CreatedBy:      k.CreatedBy,
+		ExpiresAt:      k.ExpiresAt,
+		LastUsedAt:     k.LastUsedAt,
+		RotatedAt:      k.RotatedAt,
+		RevokedAt:      k.RevokedAt,
+		CreatedAt:      k.CreatedAt,
+	}
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
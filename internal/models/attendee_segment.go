@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SegmentTicketType selects tickets by how they were issued
+type SegmentTicketType string
+
+const (
+	SegmentTicketTypeGeneral SegmentTicketType = "general_admission" // Tickets sold or placed as orders
+	SegmentTicketTypeComp    SegmentTicketType = "complimentary"     // Tickets issued from comp allocations
+)
+
+// SegmentCriteria selects attendees of an organization. Every criterion given must match;
+// cancelled tickets are never selected.
+type SegmentCriteria struct {
+	EventIDs        []uint              `json:"event_ids,omitempty" binding:"omitempty,max=50" example:"1,2"`
+	TicketTypes     []SegmentTicketType `json:"ticket_types,omitempty" binding:"omitempty,dive,oneof=general_admission complimentary" example:"complimentary"`
+	AllocationIDs   []uuid.UUID         `json:"allocation_ids,omitempty" binding:"omitempty,max=50"` // Hold/comp blocks the tickets were issued from
+	CheckedIn       *bool               `json:"checked_in,omitempty" example:"true"`
+	PurchasedAfter  *time.Time          `json:"purchased_after,omitempty" example:"2026-01-01T00:00:00Z"`
+	PurchasedBefore *time.Time          `json:"purchased_before,omitempty" example:"2026-07-01T00:00:00Z"`
+	Tags            []string            `json:"tags,omitempty" binding:"omitempty,max=20,dive,required,max=50" example:"vip"` // Internal tags on the ticket or its order; any of them matches
+}
+
+// AttendeeSegment is a saved selection of an organization's attendees, evaluated whenever it is
+// used, so it always reflects the current tickets. Segments drive attendee exports, marketing
+// syncs and comp issuance.
+type AttendeeSegment struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex:idx_attendee_segment_name" json:"organization_id"`
+	Name           string          `gorm:"size:100;not null;uniqueIndex:idx_attendee_segment_name" json:"name"`
+	Criteria       SegmentCriteria `gorm:"serializer:json" json:"criteria"`
+	CreatedBy      uuid.UUID       `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// AttendeeSegmentRequest is the request structure for saving an attendee segment
+type AttendeeSegmentRequest struct {
+	Name     string          `json:"name" binding:"required,max=100" example:"Checked-in VIPs"`
+	Criteria SegmentCriteria `json:"criteria"`
+}
+
+// SegmentCounts is the size of a segment as evaluated now
+type SegmentCounts struct {
+	Tickets   int64 `json:"tickets"`
+	Attendees int64 `json:"attendees"` // Distinct attendee email addresses
+	OptedIn   int64 `json:"opted_in"`  // Distinct addresses that accepted marketing, reached by marketing syncs
+}
+
+// AttendeeSegmentResponse is a saved segment with its current counts
+type AttendeeSegmentResponse struct {
+	AttendeeSegment
+	Counts SegmentCounts `json:"counts"`
+}
+
+// SegmentAttendee is a ticket selected by a segment, as listed and exported
+type SegmentAttendee struct {
+	AttendeeResponse
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (s *AttendeeSegment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
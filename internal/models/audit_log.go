@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditLog is an append-only record of a privileged action
+type AuditLog struct {
+	ID             uuid.UUID              `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ActorID        *uuid.UUID             `gorm:"type:uuid;index" json:"actor_id,omitempty"` // Nil for system actions
+	Action         string                 `gorm:"not null;index" json:"action"`
+	EntityType     string                 `gorm:"not null;index:idx_audit_entity" json:"entity_type"`
+	EntityID       string                 `gorm:"not null;index:idx_audit_entity" json:"entity_id"`
+	OrganizationID *uuid.UUID             `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	Details        map[string]interface{} `gorm:"serializer:json" json:"details,omitempty"`
+	CreatedAt      time.Time              `gorm:"index" json:"created_at"`
+}
+
+// AuditLogFilter is the query structure for searching the audit log
+type AuditLogFilter struct {
+	EntityType string `form:"entity_type" example:"order"`
+	EntityID   string `form:"entity_id" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	ActorID    string `form:"actor_id" binding:"omitempty,uuid"`
+	Action     string `form:"action" example:"order_adjustment.applied"`
+	Limit      int    `form:"limit" binding:"omitempty,min=1,max=200" example:"50"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AvatarSizes are the square sizes in pixels every avatar is stored in
+var AvatarSizes = []int{64, 128, 256}
+
+// DefaultAvatarSize is the size served when none is requested
+const DefaultAvatarSize = 128
+
+// UserAvatar is one size of a user's avatar, cropped square and re-encoded on upload
+type UserAvatar struct {
+	UserID    uuid.UUID `gorm:"type:uuid;primaryKey" json:"user_id"`
+	Size      int       `gorm:"primaryKey" json:"size"`
+	Data      []byte    `gorm:"not null" json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,115 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BulkOperation is an operation a bulk job applies to each of its items
+type BulkOperation string
+
+const (
+	BulkOperationRevokeTickets BulkOperation = "revoke_tickets" // Items are ticket IDs
+	BulkOperationResendTickets BulkOperation = "resend_tickets" // Items are ticket IDs
+	BulkOperationIssueComps    BulkOperation = "issue_comps"    // Items are recipient emails
+)
+
+// BulkJobStatus represents the processing state of a bulk job
+type BulkJobStatus string
+
+const (
+	BulkJobPending   BulkJobStatus = "pending"
+	BulkJobRunning   BulkJobStatus = "running"
+	BulkJobCompleted BulkJobStatus = "completed" // Every item was processed, whether it succeeded or not
+	BulkJobFailed    BulkJobStatus = "failed"    // The job gave up; items not processed are left pending
+)
+
+// BulkItemStatus represents the outcome of one item of a bulk job
+type BulkItemStatus string
+
+const (
+	BulkItemPending   BulkItemStatus = "pending"
+	BulkItemSucceeded BulkItemStatus = "succeeded"
+	BulkItemFailed    BulkItemStatus = "failed"
+)
+
+// BulkJob applies an operation to many items of an organization in the background. It is
+// returned as soon as it is queued; its counters and items report progress as it runs.
+type BulkJob struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID         `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Operation      BulkOperation     `gorm:"size:30;not null" json:"operation"`
+	Options        map[string]string `gorm:"serializer:json" json:"options,omitempty"` // Settings of the operation shared by all items, such as a revocation reason
+	Status         BulkJobStatus     `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Total          int               `gorm:"not null" json:"total"`
+	Processed      int               `gorm:"not null;default:0" json:"processed"`
+	Succeeded      int               `gorm:"not null;default:0" json:"succeeded"`
+	Failed         int               `gorm:"not null;default:0" json:"failed"`
+	Error          string            `json:"error,omitempty"` // Why the job gave up
+	CreatedBy      uuid.UUID         `gorm:"type:uuid;not null" json:"created_by"`
+	StartedAt      *time.Time        `json:"started_at,omitempty"`
+	CompletedAt    *time.Time        `json:"completed_at,omitempty"`
+	CreatedAt      time.Time         `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+	Items          []BulkJobItem     `gorm:"foreignKey:JobID" json:"items,omitempty"`
+}
+
+// BulkJobItem is one item of a bulk job and its outcome
+type BulkJobItem struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	JobID       uuid.UUID      `gorm:"type:uuid;not null;index:idx_bulk_job_item_position,priority:1" json:"-"`
+	Position    int            `gorm:"not null;index:idx_bulk_job_item_position,priority:2" json:"position"`
+	Reference   string         `gorm:"size:320;not null" json:"reference"` // Ticket ID or recipient email, by operation
+	Name        string         `gorm:"size:255" json:"name,omitempty"`     // Recipient name of a comp
+	Status      BulkItemStatus `gorm:"size:20;not null;default:'pending'" json:"status"`
+	Result      string         `gorm:"size:100" json:"result,omitempty"` // ID of what the item produced, such as an issued ticket
+	Error       string         `gorm:"size:500" json:"error,omitempty"`
+	ProcessedAt *time.Time     `json:"processed_at,omitempty"`
+}
+
+// BulkRevokeTicketsRequest is the request structure for revoking many tickets at once
+type BulkRevokeTicketsRequest struct {
+	TicketIDs []uuid.UUID            `json:"ticket_ids" binding:"required,min=1,max=5000"`
+	Reason    TicketRevocationReason `json:"reason" binding:"required,oneof=chargeback fraud policy_violation other" example:"fraud"`
+	Note      string                 `json:"note" binding:"omitempty,max=500" example:"Tickets resold by a banned tout"`
+	Silent    bool                   `json:"silent"` // Skip emailing the holders
+}
+
+// BulkResendTicketsRequest is the request structure for emailing many tickets to their attendees
+// again, either the listed tickets or every admitting ticket of an event
+type BulkResendTicketsRequest struct {
+	TicketIDs []uuid.UUID `json:"ticket_ids" binding:"required_without=EventID,excluded_with=EventID,omitempty,min=1,max=5000"`
+	EventID   uint        `json:"event_id" example:"1"`
+}
+
+// BulkIssueCompsRequest is the request structure for issuing comp tickets from an allocation to
+// more recipients than IssueCompsRequest takes at once
+type BulkIssueCompsRequest struct {
+	AllocationID uuid.UUID       `json:"allocation_id" binding:"required"`
+	Recipients   []CompRecipient `json:"recipients" binding:"required,min=1,max=5000,dive"`
+}
+
+// BulkJobQuery filters the bulk jobs of an organization
+type BulkJobQuery struct {
+	PageQuery
+	Operation string `form:"operation" binding:"omitempty,oneof=revoke_tickets resend_tickets issue_comps" example:"revoke_tickets"`
+	Status    string `form:"status" binding:"omitempty,oneof=pending running completed failed" example:"running"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (j *BulkJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (i *BulkJobItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cart is a buyer's temporary hold on tickets. The tickets are taken off sale until the cart is
+// checked out, released or expires. Carts are kept in Redis, not the database.
+type Cart struct {
+	ID         uuid.UUID        `json:"id"`
+	UserID     uuid.UUID        `json:"user_id"`
+	Items      []OrderQuoteItem `json:"items"`
+	PromoCode  string           `json:"promo_code,omitempty"`  // Applied again when the cart is checked out
+	AccessCode string           `json:"access_code,omitempty"` // Presale access code, used when the cart is checked out
+	ExpiresAt  time.Time        `json:"expires_at"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// CartRequest is the request structure for holding tickets in a cart
+type CartRequest struct {
+	Items      []OrderQuoteItem `json:"items" binding:"required,min=1,max=20,dive"`
+	PromoCode  string           `json:"promo_code" binding:"omitempty,max=50" example:"SUMMER10"`
+	AccessCode string           `json:"access_code" binding:"omitempty,max=50" example:"FANCLUB25"` // Needed for events in presale unless the buyer's email is allowlisted
+}
+
+// CartResponse is a cart with its tickets priced when they were held
+type CartResponse struct {
+	Cart
+	Quote *OrderQuote `json:"quote,omitempty"`
+}
+
+// CartCheckoutResponse is the orders a cart was converted into, one per event
+type CartCheckoutResponse struct {
+	CartID uuid.UUID             `json:"cart_id"`
+	Orders []OrderDetailResponse `json:"orders"`
+}
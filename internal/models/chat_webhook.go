@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ChatProvider identifies the chat platform an incoming webhook posts to
+type ChatProvider string
+
+const (
+	ChatProviderSlack   ChatProvider = "slack"
+	ChatProviderDiscord ChatProvider = "discord"
+)
+
+// ChatNotification identifies a sales activity organizers can be notified about
+type ChatNotification string
+
+const (
+	ChatNotificationTicketsSold   ChatNotification = "tickets.sold"
+	ChatNotificationEventSoldOut  ChatNotification = "event.sold_out"
+	ChatNotificationLowInventory  ChatNotification = "event.low_inventory"
+	ChatNotificationOrderRefunded ChatNotification = "order.refunded"
+)
+
+// ChatWebhook is an organization's Slack or Discord incoming webhook that receives sale notifications
+type ChatWebhook struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID          `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Provider       ChatProvider       `gorm:"not null" json:"provider"`
+	Name           string             `gorm:"size:100" json:"name"`
+	WebhookURL     string             `gorm:"not null" json:"-"`
+	Notifications  []ChatNotification `gorm:"serializer:json" json:"notifications"`
+	Enabled        bool               `gorm:"default:true" json:"enabled"`
+	CreatedBy      *uuid.UUID         `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// ChatWebhookRequest is the request structure for adding or updating a chat webhook.
+// An empty notifications list subscribes the webhook to every notification.
+type ChatWebhookRequest struct {
+	Provider      string   `json:"provider" binding:"required,oneof=slack discord" example:"slack"`
+	Name          string   `json:"name" binding:"max=100" example:"#sales"`
+	WebhookURL    string   `json:"webhook_url" binding:"required,url" example:"https://hooks.slack.com/services/T000/B000/XXXX"`
+	Notifications []string `json:"notifications" binding:"omitempty,dive,oneof=tickets.sold event.sold_out event.low_inventory order.refunded" example:"tickets.sold,event.sold_out"`
+	Enabled       *bool    `json:"enabled" example:"true"`
+}
+
+// ChatWebhookResponse is the response structure for a chat webhook (the webhook URL is never returned)
+type ChatWebhookResponse struct {
+	ID            uuid.UUID          `json:"id"`
+	Provider      ChatProvider       `json:"provider"`
+	Name          string             `json:"name"`
+	Notifications []ChatNotification `json:"notifications"`
+	Enabled       bool               `json:"enabled"`
+	CreatedAt     time.Time          `json:"created_at"`
+}
+
+// ChatMessage is the payload of a queued chat notification task
+type ChatMessage struct {
+	WebhookID  uuid.UUID    `json:"webhook_id"`
+	Provider   ChatProvider `json:"provider"`
+	WebhookURL string       `json:"webhook_url"`
+	Text       string       `json:"text"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (w *ChatWebhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// Receives reports whether the webhook is subscribed to a notification
+func (w *ChatWebhook) Receives(notification ChatNotification) bool {
+	if !w.Enabled {
+		return false
+	}
+	if len(w.Notifications) == 0 {
+		return true
+	}
+	for _, n := range w.Notifications {
+		if n == notification {
+			return true
+		}
+	}
+	return false
+}
+
+// ToResponse converts a ChatWebhook model to a ChatWebhookResponse
+func (w *ChatWebhook) ToResponse() ChatWebhookResponse {
+	return ChatWebhookResponse{
+		ID:            w.ID,
+		Provider:      w.Provider,
+		Name:          w.Name,
+		Notifications: w.Notifications,
+		Enabled:       w.Enabled,
+		CreatedAt:     w.CreatedAt,
+	}
+}
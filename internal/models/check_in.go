@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CheckIn records an attendee entering an event. Undone check-ins are kept, marked with who
+// undid them and when, so door mistakes stay traceable.
+type CheckIn struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	TicketID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"ticket_id"`
+	EventID        uint       `gorm:"not null;index" json:"event_id"`
+	OrganizationID *uuid.UUID `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	Gate           string     `gorm:"size:50" json:"gate,omitempty"`
+	CheckedInBy    *uuid.UUID `gorm:"type:uuid" json:"checked_in_by,omitempty"` // Staff member who scanned or admitted the ticket
+	CheckedInAt    time.Time  `gorm:"not null;index" json:"checked_in_at"`
+	UndoneAt       *time.Time `json:"undone_at,omitempty"`
+	UndoneBy       *uuid.UUID `gorm:"type:uuid" json:"undone_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// CheckInRequest is the request structure for checking an attendee in by ticket
+type CheckInRequest struct {
+	TicketID uuid.UUID `json:"ticket_id" binding:"required"`
+	Gate     string    `json:"gate" binding:"omitempty,max=50" example:"north"` // Entrance the attendee came through
+}
+
+// AttendanceStats is the attendance of an event, counted from its tickets
+type AttendanceStats struct {
+	EventID       uint             `json:"event_id"`
+	Capacity      int              `json:"capacity"`
+	Issued        int64            `json:"issued"`      // Tickets not cancelled
+	CheckedIn     int64            `json:"checked_in"`  // Attendees inside, after undone check-ins
+	NotArrived    int64            `json:"not_arrived"` // Issued tickets not checked in yet
+	Undone        int64            `json:"undone"`      // Check-ins that were undone
+	Gates         map[string]int64 `json:"gates"`       // Check-ins per gate
+	LastCheckInAt *time.Time       `json:"last_check_in_at,omitempty"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (c *CheckIn) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
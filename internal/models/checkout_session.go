@@ -0,0 +1,97 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CheckoutSessionStatus represents the state of a checkout session
+type CheckoutSessionStatus string
+
+const (
+	CheckoutSessionOpen      CheckoutSessionStatus = "open"
+	CheckoutSessionCompleted CheckoutSessionStatus = "completed" // The buyer placed an order for the selected events
+)
+
+// CheckoutSession is a buyer's checkout in progress. It keeps the ticket selections so the buyer
+// can resume from another visit or from the reminder email.
+type CheckoutSession struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	TokenHash      string                `gorm:"size:64;not null;uniqueIndex" json:"-"` // SHA-256 of the resume token, which is only handed to the buyer
+	UserID         *uuid.UUID            `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Email          string                `gorm:"size:255;not null;index" json:"email"`
+	Name           string                `gorm:"size:200" json:"name"`
+	Items          []OrderQuoteItem      `gorm:"serializer:json" json:"items"`
+	PromoCode      string                `gorm:"size:50" json:"promo_code,omitempty"`
+	AccessCode     string                `gorm:"size:50" json:"access_code,omitempty"`        // Presale access code entered by the buyer
+	PresaleID      *uuid.UUID            `gorm:"type:uuid;index" json:"presale_id,omitempty"` // Presale the buyer was admitted to when starting the checkout
+	Currency       string                `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	MarketingOptIn bool                  `gorm:"not null;default:false" json:"marketing_opt_in"` // Reminders are only sent to buyers who opted in
+	Status         CheckoutSessionStatus `gorm:"size:20;not null;default:'open';index" json:"status"`
+	ReminderSentAt *time.Time            `json:"reminder_sent_at,omitempty"`
+	CompletedAt    *time.Time            `json:"completed_at,omitempty"`
+	ExpiresAt      time.Time             `gorm:"not null" json:"expires_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// CheckoutSessionRequest is the request structure for starting or updating a checkout session
+type CheckoutSessionRequest struct {
+	Items          []OrderQuoteItem `json:"items" binding:"required,min=1,max=20,dive"`
+	PromoCode      string           `json:"promo_code" binding:"omitempty,max=50" example:"SUMMER10"`
+	AccessCode     string           `json:"access_code" binding:"omitempty,max=50" example:"FANCLUB25"` // Needed for events in presale unless the email is allowlisted
+	Currency       string           `json:"currency" binding:"omitempty,len=3" example:"USD"`
+	Email          string           `json:"email" binding:"required,email" example:"jane@example.com"`
+	Name           string           `json:"name" binding:"omitempty,max=200" example:"Jane Doe"`
+	MarketingOptIn bool             `json:"marketing_opt_in" example:"true"`
+}
+
+// CheckoutSessionResponse is a checkout session with its selections priced at current prices
+type CheckoutSessionResponse struct {
+	ID             uuid.UUID             `json:"id"`
+	ResumeToken    string                `json:"resume_token,omitempty"` // Identifies the session when resuming it; only returned when the session is started
+	Email          string                `json:"email"`
+	Name           string                `json:"name"`
+	Items          []OrderQuoteItem      `json:"items"`
+	PromoCode      string                `json:"promo_code,omitempty"`
+	AccessCode     string                `json:"access_code,omitempty"`
+	PresaleID      *uuid.UUID            `json:"presale_id,omitempty"`
+	MarketingOptIn bool                  `json:"marketing_opt_in"`
+	Status         CheckoutSessionStatus `json:"status"`
+	Quote          *OrderQuote           `json:"quote,omitempty"` // Absent when the selections can no longer be priced, e.g. sold out
+	QuoteError     string                `json:"quote_error,omitempty"`
+	ExpiresAt      time.Time             `json:"expires_at"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (s *CheckoutSession) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.Status == "" {
+		s.Status = CheckoutSessionOpen
+	}
+	return nil
+}
+
+// ToResponse converts a CheckoutSession model to a CheckoutSessionResponse
+func (s *CheckoutSession) ToResponse() CheckoutSessionResponse {
+	return CheckoutSessionResponse{
+		ID:             s.ID,
+		Email:          s.Email,
+		Name:           s.Name,
+		Items:          s.Items,
+		PromoCode:      s.PromoCode,
+		AccessCode:     s.AccessCode,
+		PresaleID:      s.PresaleID,
+		MarketingOptIn: s.MarketingOptIn,
+		Status:         s.Status,
+		ExpiresAt:      s.ExpiresAt,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ContactMerge folds the tickets and orders of one email address into another contact of an
+// organization, such as an attendee's work and personal addresses. Addresses differing only in
+// case or surrounding spaces are the same contact without a merge.
+type ContactMerge struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_contact_merge_email" json:"organization_id"`
+	Email          string    `gorm:"size:255;not null;uniqueIndex:idx_contact_merge_email" json:"email"` // Merged address, lowercase
+	IntoEmail      string    `gorm:"size:255;not null;index" json:"into_email"`                          // Address of the contact it is part of, lowercase
+	MergedBy       uuid.UUID `gorm:"type:uuid;not null" json:"merged_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ContactMergeRequest is the request structure for merging a duplicate email address into a contact
+type ContactMergeRequest struct {
+	Email     string `json:"email" binding:"required,email,max=255" example:"jane.doe@work.example.com"`
+	IntoEmail string `json:"into_email" binding:"required,email,max=255" example:"jane@example.com"`
+}
+
+// Contact is a unique person across an organization's events, aggregated from the tickets they
+// hold and the orders they placed
+type Contact struct {
+	Email          string    `json:"email"`
+	Name           string    `json:"name"`                    // Name on their latest ticket or order
+	MergedEmails   []string  `json:"merged_emails,omitempty"` // Other addresses merged into this contact
+	LifetimeSpend  float64   `json:"lifetime_spend"`          // Paid orders net of refunds
+	Orders         int64     `json:"orders"`
+	Tickets        int64     `json:"tickets"`
+	Events         int64     `json:"events"`          // Events they hold tickets for
+	EventsAttended int64     `json:"events_attended"` // Events they were checked in at
+	MarketingOptIn bool      `json:"marketing_opt_in"`
+	FirstSeenAt    time.Time `json:"first_seen_at"`
+	LastActivityAt time.Time `json:"last_activity_at"` // Latest order, payment, ticket or check-in
+}
+
+// ContactQuery filters and sorts the contacts of an organization
+type ContactQuery struct {
+	PageQuery
+	Q    string `form:"q" binding:"omitempty,max=100" example:"jane"` // Matches email addresses and names
+	Sort string `form:"sort" binding:"omitempty,oneof=last_activity lifetime_spend events_attended" example:"lifetime_spend"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (m *ContactMerge) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -35,6 +35,7 @@ const (
 	EmailTypeTicketRefund       EmailJobType = "ticket_refund"
 	EmailTypeTicketTransfer     EmailJobType = "ticket_transfer"
 	EmailTypeTicketReminder     EmailJobType = "ticket_reminder"
+	EmailTypeTicketRevoked      EmailJobType = "ticket_revoked"
 
 	// Payment & Billing
 	EmailTypePaymentConfirmation EmailJobType = "payment_confirmation"
@@ -74,8 +75,43 @@ type EmailJob struct {
 	EventID        string                 `json:"event_id,omitempty"`        // Associated event ID
 	TicketID       string                 `json:"ticket_id,omitempty"`       // Associated ticket ID
 	PaymentID      string                 `json:"payment_id,omitempty"`      // Associated payment ID
+	NotificationID string                 `json:"notification_id,omitempty"` // Notification log entry tracking the delivery
 	Tags           []string               `json:"tags,omitempty"`            // Tags for categorization
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`        // Additional metadata
+
+	Attachments []EmailAttachment `json:"attachments,omitempty"` // Files attached to the email
+	Headers     map[string]string `json:"headers,omitempty"`     // Extra message headers, e.g. List-Unsubscribe
+	Tracked     bool              `json:"tracked,omitempty"`     // Opens and clicks are recorded on the notification log entry
+}
+
+// Trackable reports whether opens and clicks of emails of a type may be tracked. Only reminders and
+// marketing are; security, account, ticket and payment emails never carry tracking. Newsletters
+// are tracked by their own recipients.
+func (t EmailJobType) Trackable() bool {
+	switch t {
+	case EmailTypeReminder, EmailTypePaymentReminder, EmailTypeTicketReminder, EmailTypeEventReminder,
+		EmailTypeEventNotification, EmailTypeMarketing:
+		return true
+	}
+	return false
+}
+
+// EmailAttachment is a file attached to an email. Small files are carried in the job; larger ones,
+// such as invoices, are referenced by their key in the attachment bucket and read when sending.
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Content     []byte `json:"content,omitempty"`     // Base64 in the queued job
+	StorageKey  string `json:"storage_key,omitempty"` // Object key in the attachment bucket, used when Content is empty
+	Size        int64  `json:"size,omitempty"`        // Size of the stored object in bytes, checked against the limits when queueing
+}
+
+// ContentSize returns the size of an attachment, as carried or as declared for a stored file
+func (a EmailAttachment) ContentSize() int64 {
+	if a.StorageKey != "" && len(a.Content) == 0 {
+		return a.Size
+	}
+	return int64(len(a.Content))
 }
 
 // Priority levels
@@ -0,0 +1,30 @@
+package models
+
+// EmailTemplateVariable is a variable an email template reads. Variables under Data come from the
+// data the email is sent with; the others are fields every email fills in or reads from that data.
+type EmailTemplateVariable struct {
+	Name     string `json:"name" example:"Data.EventName"`
+	Required bool   `json:"required"` // False for variables the template only tests with if or with
+	Present  bool   `json:"present"`  // Whether the email provides it, from its own fields or the data
+}
+
+// TestEmailRequest is the request structure for sending a test email of a template
+type TestEmailRequest struct {
+	Template string `json:"template" binding:"required,max=100" example:"ticket_confirmation.html"`
+	To       string `json:"to" binding:"required,email" example:"qa@example.com"`
+	Subject  string `json:"subject" binding:"omitempty,max=200" example:"Your tickets"` // Defaults to "Test: <template>"
+	// Template data, as queued emails carry it: keys of .Data variables, plus Title, Message,
+	// RecipientName and OTP
+	Data map[string]interface{} `json:"data"`
+	// Fill required variables missing from data with placeholders instead of rejecting the request
+	UseSampleData bool `json:"use_sample_data"`
+}
+
+// TestEmailResponse reports the variables of a template and the test email sent with it
+type TestEmailResponse struct {
+	Template  string                  `json:"template"`
+	To        string                  `json:"to"`
+	Subject   string                  `json:"subject"`
+	Variables []EmailTemplateVariable `json:"variables"`
+	Sampled   []string                `json:"sampled,omitempty"` // Variables filled with placeholders
+}
@@ -1,25 +1,63 @@
 package models
 
 import (
+	"slices"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// EventStatus is the lifecycle state of an event. Events are created as drafts, go on sale when
+// published and may be paused and resumed until they are cancelled or completed.
+type EventStatus string
+
+const (
+	EventStatusDraft     EventStatus = "draft"     // Hidden from listings until published
+	EventStatusPublished EventStatus = "published" // Listed and on sale
+	EventStatusPaused    EventStatus = "paused"    // Listed, with sales halted until published again
+	EventStatusCancelled EventStatus = "cancelled"
+	EventStatusCompleted EventStatus = "completed" // Closed by the organizer once it took place
+)
+
+// eventTransitions lists the statuses each status may move to
+var eventTransitions = map[EventStatus][]EventStatus{
+	EventStatusDraft:     {EventStatusPublished, EventStatusCancelled},
+	EventStatusPublished: {EventStatusPaused, EventStatusCancelled, EventStatusCompleted},
+	EventStatusPaused:    {EventStatusPublished, EventStatusCancelled, EventStatusCompleted},
+}
+
+// CanTransitionTo reports whether an event in this status may move to next
+func (s EventStatus) CanTransitionTo(next EventStatus) bool {
+	return slices.Contains(eventTransitions[s], next)
+}
+
 type Event struct {
-	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"not null;size:200" json:"title" binding:"required"`
-	Description string         `gorm:"type:text" json:"description"`
-	Location    string         `gorm:"size:200" json:"location"`
-	StartDate   time.Time      `gorm:"not null" json:"start_date" binding:"required"`
-	EndDate     time.Time      `gorm:"not null" json:"end_date" binding:"required"`
-	Price       float64        `gorm:"not null" json:"price" binding:"required,min=0"`
-	Capacity    int            `gorm:"not null" json:"capacity" binding:"required,min=1"`
-	Available   int            `gorm:"not null" json:"available"`
-	Status      string         `gorm:"not null;default:'active'" json:"status"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uint                   `gorm:"primaryKey" json:"id"`
+	Title          string                 `gorm:"not null;size:200" json:"title" binding:"required"`
+	Description    string                 `gorm:"type:text" json:"description"`
+	Location       string                 `gorm:"size:200" json:"location"`
+	StartDate      time.Time              `gorm:"not null" json:"start_date" binding:"required"`
+	EndDate        time.Time              `gorm:"not null" json:"end_date" binding:"required"`
+	Price          float64                `gorm:"not null" json:"price" binding:"required,min=0"`
+	Capacity       int                    `gorm:"not null" json:"capacity" binding:"required,min=1"`
+	Available      int                    `gorm:"not null" json:"available"`
+	Status         EventStatus            `gorm:"not null;default:'draft';index" json:"status"`
+	WaitlistOpen   bool                   `gorm:"default:false" json:"waitlist_open"`
+	CoverURL       string                 `gorm:"size:500" json:"cover_url"`
+	Performer      string                 `gorm:"size:200" json:"performer"` // Headlining artist or group, shown in search results
+	Category       string                 `gorm:"size:50;index" json:"category"`
+	OrganizerID    *uuid.UUID             `gorm:"type:uuid;index" json:"organizer_id,omitempty"`    // User who created the event
+	OrganizationID *uuid.UUID             `gorm:"type:uuid;index" json:"organization_id,omitempty"` // Organization running the event; its organizers may manage it
+	VenueID        *uuid.UUID             `gorm:"type:uuid;index" json:"venue_id,omitempty"`        // Venue whose seat map is attached; seats are then selected when buying
+	RefundPolicy   RefundPolicy           `gorm:"embedded" json:"refund_policy"`
+	Draft          map[string]interface{} `gorm:"serializer:json" json:"-"` // Unvalidated changes autosaved by the organizer UI
+	EmailBlocks    []EmailContentBlock    `gorm:"serializer:json" json:"-"` // Sanitized sections appended to ticket confirmation emails
+	DraftSavedAt   *time.Time             `json:"-"`
+	ArchivedAt     *time.Time             `gorm:"index" json:"archived_at,omitempty"` // Orders, tickets and check-ins moved to the archive; see EventArchive
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt         `gorm:"index" json:"-"`
 }
 
 type EventCreateRequest struct {
@@ -30,6 +68,13 @@ type EventCreateRequest struct {
 	EndDate     time.Time `json:"end_date" binding:"required"`
 	Price       float64   `json:"price" binding:"required,min=0"`
 	Capacity    int       `json:"capacity" binding:"required,min=1"`
+	CoverURL    string    `json:"cover_url" binding:"omitempty,url"`
+	Performer   string    `json:"performer" binding:"omitempty,max=200"`
+	Category    string    `json:"category" binding:"omitempty,max=50" example:"music"`
+	// Organization running the event. Defaults to the first organization the organizer manages.
+	OrganizationID *uuid.UUID `json:"organization_id"`
+	// Defaults to flexible refunds until the event starts
+	RefundPolicy *RefundPolicy `json:"refund_policy"`
 }
 
 type EventUpdateRequest struct {
@@ -40,13 +85,110 @@ type EventUpdateRequest struct {
 	EndDate     time.Time `json:"end_date"`
 	Price       float64   `json:"price" binding:"omitempty,min=0"`
 	Capacity    int       `json:"capacity" binding:"omitempty,min=1"`
-	Status      string    `json:"status"`
+	CoverURL    string    `json:"cover_url" binding:"omitempty,url"`
+	Performer   string    `json:"performer" binding:"omitempty,max=200"`
+	Category    string    `json:"category" binding:"omitempty,max=50" example:"music"`
+	// Replaces the whole policy; applies to refunds of tickets already sold as well
+	RefundPolicy *RefundPolicy `json:"refund_policy"`
+}
+
+// EventFilterQuery is the query structure for listing published events. Dates are the inclusive
+// days the events start on; prices bound the ticket price. Sort names a field, prefixed with "-"
+// for descending order.
+type EventFilterQuery struct {
+	PageQuery
+	Search         string   `form:"q" binding:"omitempty,max=100" example:"jazz"`
+	Category       string   `form:"category" binding:"omitempty,max=50" example:"music"`
+	Status         string   `form:"status" binding:"omitempty,oneof=published paused cancelled completed" example:"published"`
+	Location       string   `form:"location" binding:"omitempty,max=200" example:"Berlin"`
+	OrganizationID string   `form:"organization_id" binding:"omitempty,uuid" example:"5f0c7a3e-8d2b-4c1e-9a6f-2b3d4e5f6a7b"`
+	StartsFrom     string   `form:"starts_from" binding:"omitempty,datetime=2006-01-02" example:"2025-06-01"`
+	StartsTo       string   `form:"starts_to" binding:"omitempty,datetime=2006-01-02,range_end=StartsFrom" example:"2025-06-30"`
+	MinPrice       *float64 `form:"min_price" binding:"omitempty,min=0" example:"10"`
+	MaxPrice       *float64 `form:"max_price" binding:"omitempty,min=0,range_end=MinPrice" example:"50"`
+	Sort           string   `form:"sort" binding:"omitempty,oneof=start_date -start_date price -price title -title created_at -created_at" example:"-price"`
+}
+
+// EventDraftFields are the JSON fields an event draft may hold, those of EventCreateRequest
+var EventDraftFields = []string{
+	"title", "description", "location", "start_date", "end_date", "price", "capacity",
+	"cover_url", "performer", "category", "refund_policy",
+}
+
+// EventDraftResponse is an event with the changes autosaved for it. New events are created as
+// drafts (status "draft") and stay hidden until published; drafts of listed events are pending edits.
+type EventDraftResponse struct {
+	Event   *Event                 `json:"event"`
+	Draft   map[string]interface{} `json:"draft"`
+	SavedAt *time.Time             `json:"saved_at,omitempty"`
 }
 
 func (e *Event) BeforeCreate(tx *gorm.DB) error {
 	e.Available = e.Capacity
 	if e.Status == "" {
-		e.Status = "active"
+		e.Status = EventStatusDraft
+	}
+	if e.RefundPolicy.Type == "" {
+		e.RefundPolicy.Type = RefundPolicyFlexible
 	}
 	return nil
 }
+
+// RefundPolicyType says whether and until when an event's tickets can be refunded
+type RefundPolicyType string
+
+const (
+	RefundPolicyFlexible        RefundPolicyType = "flexible"          // Refunds until the event starts
+	RefundPolicyUntilDaysBefore RefundPolicyType = "until_days_before" // Refunds until DaysBefore days before the event starts
+	RefundPolicyNone            RefundPolicyType = "none"              // No refunds
+)
+
+// RefundPolicy is an event's refund terms as set by the organizer. It is shown on the public event
+// details so buyers see it before purchase, and enforced when a refund is issued.
+type RefundPolicy struct {
+	Type       RefundPolicyType `gorm:"column:refund_policy;size:20;not null;default:'flexible'" json:"type" binding:"required,oneof=flexible until_days_before none" example:"until_days_before"`
+	DaysBefore int              `gorm:"column:refund_days_before;not null;default:0" json:"days_before" binding:"required_if=Type until_days_before,min=0,max=365" example:"7"`
+	FeePercent float64          `gorm:"column:refund_fee_percent;not null;default:0" json:"fee_percent" binding:"min=0,max=100" example:"10"` // Share of a refund withheld as a fee
+}
+
+// Deadline returns when refunds close for an event starting at start, or nil when they are never
+// allowed
+func (p RefundPolicy) Deadline(start time.Time) *time.Time {
+	switch p.Type {
+	case RefundPolicyNone:
+		return nil
+	case RefundPolicyUntilDaysBefore:
+		deadline := start.AddDate(0, 0, -p.DaysBefore)
+		return &deadline
+	default:
+		return &start
+	}
+}
+
+// ForecastBasis says what a sales forecast was extrapolated from
+type ForecastBasis string
+
+const (
+	ForecastBasisSimilarEvents ForecastBasis = "similar_events" // Sales curves of past events with similar capacity and price
+	ForecastBasisCurrentPace   ForecastBasis = "current_pace"   // The event's own average daily sales so far
+)
+
+// EventForecast estimates how an event's sales will end up
+type EventForecast struct {
+	EventID            uint          `json:"event_id"`
+	Capacity           int           `json:"capacity"`
+	Sold               int           `json:"sold"`
+	ProjectedSold      int           `json:"projected_sold"`              // Tickets expected to be sold by the start of the event
+	SoldOut            bool          `json:"sold_out"`                    // Already sold out
+	SellOutAt          *time.Time    `json:"sell_out_at,omitempty"`       // When the event is expected to sell out; omitted when it is not expected to
+	ExpectedAttendance int           `json:"expected_attendance"`         // Ticket holders expected to show up
+	ShowUpRate         float64       `json:"show_up_rate" example:"0.92"` // Share of sold tickets checked in at similar events
+	Basis              ForecastBasis `json:"basis"`                       // What the projection was extrapolated from
+	SimilarEvents      int           `json:"similar_events"`              // Past events the projection is based on
+	ComputedAt         time.Time     `json:"computed_at"`
+}
+
+// OnSale reports whether tickets to the event may be sold: it is published and has not ended
+func (e *Event) OnSale() bool {
+	return e.Status == EventStatusPublished && e.EndDate.After(time.Now())
+}
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventArchiveStatus represents whether an archive still holds an event's records
+type EventArchiveStatus string
+
+const (
+	EventArchiveStatusArchived EventArchiveStatus = "archived"
+	EventArchiveStatusRestored EventArchiveStatus = "restored" // Records moved back to the hot tables
+)
+
+// Kinds of records moved to an archive, named after the tables they come from
+const (
+	ArchivedKindOrders       = "orders"
+	ArchivedKindTickets      = "tickets"
+	ArchivedKindCheckIns     = "check_ins"
+	ArchivedKindScanAttempts = "scan_attempts"
+)
+
+// EventArchive records the orders, tickets, check-ins and scan attempts of a past event being
+// moved out of the hot tables, with the event's totals at that time so its sales can still be
+// reported on. Payments and refunds stay in place for accounting.
+type EventArchive struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EventID        uint               `gorm:"not null;index" json:"event_id"`
+	OrganizationID *uuid.UUID         `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	EventTitle     string             `gorm:"size:200" json:"event_title"`
+	EventEndDate   time.Time          `json:"event_end_date"`
+	Status         EventArchiveStatus `gorm:"not null;default:'archived';index" json:"status"`
+	Orders         int64              `gorm:"not null;default:0" json:"orders"`     // Orders that were not cancelled
+	Tickets        int64              `gorm:"not null;default:0" json:"tickets"`    // Tickets that were not cancelled
+	CheckedIn      int64              `gorm:"not null;default:0" json:"checked_in"` // Tickets checked in
+	GrossAmount    float64            `gorm:"not null;default:0" json:"gross_amount"`
+	FeeAmount      float64            `gorm:"not null;default:0" json:"fee_amount"`
+	RefundedAmount float64            `gorm:"not null;default:0" json:"refunded_amount"`
+	Currency       string             `gorm:"size:3" json:"currency"`
+	RecordCount    int64              `gorm:"not null;default:0" json:"record_count"` // Rows moved, of every kind
+	ArchivedBy     *uuid.UUID         `gorm:"type:uuid" json:"archived_by,omitempty"` // Empty when archived by the policy
+	ArchivedAt     time.Time          `gorm:"not null;index" json:"archived_at"`
+	RestoredBy     *uuid.UUID         `gorm:"type:uuid" json:"restored_by,omitempty"`
+	RestoredAt     *time.Time         `json:"restored_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// ArchivedRecord is one row moved out of a hot table, kept as its JSON image so it can be
+// restored after the table's columns changed
+type ArchivedRecord struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ArchiveID uuid.UUID       `gorm:"type:uuid;not null;index:idx_archived_record_kind" json:"archive_id"`
+	Kind      string          `gorm:"size:20;not null;index:idx_archived_record_kind" json:"kind"`
+	RecordID  string          `gorm:"size:36;not null" json:"record_id"`
+	Data      json.RawMessage `gorm:"type:jsonb;not null" json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// EventArchiveQuery filters the event archives
+type EventArchiveQuery struct {
+	PageQuery
+	EventID uint               `form:"event_id" example:"1"`
+	Status  EventArchiveStatus `form:"status" binding:"omitempty,oneof=archived restored" example:"archived"`
+}
+
+// ArchivedRecordQuery pages through the records of an archive
+type ArchivedRecordQuery struct {
+	PageQuery
+	Kind string `form:"kind" binding:"omitempty,oneof=orders tickets check_ins scan_attempts" example:"orders"`
+}
+
+// EventArchiveResponse is an archive with the number of records it holds of each kind
+type EventArchiveResponse struct {
+	EventArchive
+	Records map[string]int64 `json:"records"`
+}
+
+// ArchiveRunResult reports an archival sweep
+type ArchiveRunResult struct {
+	Archived []uint `json:"archived"` // Events archived
+	Failed   []uint `json:"failed"`   // Events left in place after an error; retried by the next sweep
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *EventArchive) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *ArchivedRecord) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventBundle sells admission to several events of an organization at a combined price, such as a
+// festival pass. Buying it places one order per event; each order is priced at the event's share
+// of the bundle price, so refunds of one event's tickets return that share.
+type EventBundle struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name           string    `gorm:"size:200;not null" json:"name"`
+	Description    string    `gorm:"type:text" json:"description,omitempty"`
+	EventIDs       []uint    `gorm:"serializer:json;not null" json:"event_ids"`
+	Price          float64   `gorm:"not null" json:"price"` // Combined price of one ticket to every event
+	Currency       string    `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	Active         bool      `gorm:"not null;default:true;index" json:"active"` // Inactive bundles can no longer be bought
+	CreatedBy      uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// BundlePurchase is a buyer's purchase of a bundle, linking the orders it placed
+type BundlePurchase struct {
+	ID             uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	BundleID       uuid.UUID `gorm:"type:uuid;not null;index" json:"bundle_id"`
+	OrganizationID uuid.UUID `gorm:"type:uuid;not null;index" json:"organization_id"`
+	UserID         uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	Quantity       int       `gorm:"not null" json:"quantity"`
+	TotalAmount    float64   `gorm:"not null" json:"total_amount"`
+	Currency       string    `gorm:"size:3;not null" json:"currency"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// EventBundleRequest is the request structure for creating or updating a bundle
+type EventBundleRequest struct {
+	Name        string  `json:"name" binding:"required,max=200" example:"Summer Festival Weekend Pass"`
+	Description string  `json:"description" binding:"max=5000" example:"Friday, Saturday and Sunday nights"`
+	EventIDs    []uint  `json:"event_ids" binding:"required,min=2,max=20,unique" example:"1,2,3"`
+	Price       float64 `json:"price" binding:"gte=0" example:"120.00"`
+	Active      *bool   `json:"active" example:"true"`
+}
+
+// BundlePurchaseRequest is the request structure for buying a bundle
+type BundlePurchaseRequest struct {
+	Quantity int `json:"quantity" binding:"required,min=1,max=10" example:"2"`
+}
+
+// BundleEventShare is an event of a bundle with the part of the bundle price its tickets sell at
+type BundleEventShare struct {
+	EventID   uint      `json:"event_id"`
+	Title     string    `json:"title"`
+	StartDate time.Time `json:"start_date"`
+	Share     float64   `json:"share"` // Price of this event's ticket within one bundle, at current prices
+}
+
+// EventBundleResponse is a bundle with its events and how many can still be bought
+type EventBundleResponse struct {
+	EventBundle
+	Events    []BundleEventShare `json:"events"`
+	Available int                `json:"available"` // Bundles left: the fewest tickets available for any of its events
+	OnSale    bool               `json:"on_sale"`   // Active, with every event on sale
+}
+
+// BundlePurchaseResponse is a bundle purchase with the pending orders it placed, one per event
+type BundlePurchaseResponse struct {
+	BundlePurchase
+	Orders []OrderDetailResponse `json:"orders"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (b *EventBundle) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (p *BundlePurchase) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package models
+
+// EmailContentBlock is a section an organizer adds to the emails of their event, such as
+// directions, door times or prohibited items. Its content is sanitized HTML.
+type EmailContentBlock struct {
+	Title   string `json:"title" binding:"omitempty,max=100" example:"Getting there"`
+	Content string `json:"content" binding:"required,max=10000" example:"<p>Doors open at <strong>7 PM</strong>. Take the U2 to Alexanderplatz.</p>"`
+}
+
+// ConfirmationEmailRequest is the request structure for saving or previewing the content blocks
+// appended to an event's ticket confirmation email
+type ConfirmationEmailRequest struct {
+	Blocks []EmailContentBlock `json:"blocks" binding:"max=10,dive"`
+}
+
+// ConfirmationEmailResponse is the content an event adds to its ticket confirmation email
+type ConfirmationEmailResponse struct {
+	Blocks []EmailContentBlock `json:"blocks"` // Content as saved, after sanitizing
+}
+
+// ConfirmationEmailPreview is an event's ticket confirmation email rendered for a sample attendee
+type ConfirmationEmailPreview struct {
+	Subject string              `json:"subject"`
+	HTML    string              `json:"html"`
+	Blocks  []EmailContentBlock `json:"blocks"` // Blocks as they would be saved, after sanitizing
+}
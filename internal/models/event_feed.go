@@ -0,0 +1,60 @@
+package models
+
+import "encoding/xml"
+
+// AtomNamespace is the XML namespace of Atom feeds (RFC 4287)
+const AtomNamespace = "http://www.w3.org/2005/Atom"
+
+// EventFeedFilter narrows the event feed to a category or an organizer
+type EventFeedFilter struct {
+	Category  string `form:"category" binding:"omitempty,max=50" example:"music"`
+	Organizer string `form:"organizer" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+}
+
+// AtomFeed is an Atom feed of published events
+type AtomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []AtomLink  `xml:"link"`
+	Author   AtomPerson  `xml:"author"`
+	Entries  []AtomEntry `xml:"entry"`
+}
+
+// AtomEntry is one event in an Atom feed
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    AtomText       `xml:"summary"`
+	Categories []AtomCategory `xml:"category,omitempty"`
+}
+
+// AtomLink is a link of an Atom feed or entry
+type AtomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// AtomPerson is the author of an Atom feed
+type AtomPerson struct {
+	Name  string `xml:"name"`
+	Email string `xml:"email,omitempty"`
+}
+
+// AtomText is a text construct of an Atom feed
+type AtomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+// AtomCategory is a category of an Atom entry
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
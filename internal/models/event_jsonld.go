@@ -0,0 +1,50 @@
+package models
+
+// schema.org vocabulary used in event structured data
+const (
+	SchemaOrgContext            = "https://schema.org"
+	SchemaEventScheduled        = "https://schema.org/EventScheduled"
+	SchemaEventCancelled        = "https://schema.org/EventCancelled"
+	SchemaOfflineAttendanceMode = "https://schema.org/OfflineEventAttendanceMode"
+	SchemaInStock               = "https://schema.org/InStock"
+	SchemaSoldOut               = "https://schema.org/SoldOut"
+)
+
+// EventJSONLD is an event as schema.org Event structured data, for frontends to embed in a
+// <script type="application/ld+json"> tag so search engines can show it as a rich result
+type EventJSONLD struct {
+	Context             string           `json:"@context"`
+	Type                string           `json:"@type"`
+	Name                string           `json:"name"`
+	Description         string           `json:"description,omitempty"`
+	StartDate           string           `json:"startDate"` // ISO 8601
+	EndDate             string           `json:"endDate"`
+	EventStatus         string           `json:"eventStatus"`
+	EventAttendanceMode string           `json:"eventAttendanceMode"`
+	Location            *JSONLDPlace     `json:"location,omitempty"`
+	Image               []string         `json:"image,omitempty"`
+	Performer           *JSONLDPerformer `json:"performer,omitempty"`
+	Offers              JSONLDOffer      `json:"offers"`
+}
+
+// JSONLDPlace is a schema.org Place where an event is held
+type JSONLDPlace struct {
+	Type    string `json:"@type"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+}
+
+// JSONLDPerformer is a schema.org PerformingGroup headlining an event
+type JSONLDPerformer struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// JSONLDOffer is a schema.org Offer for an event's tickets
+type JSONLDOffer struct {
+	Type          string `json:"@type"`
+	Price         string `json:"price"`
+	PriceCurrency string `json:"priceCurrency"`
+	Availability  string `json:"availability"`
+	ValidFrom     string `json:"validFrom"`
+}
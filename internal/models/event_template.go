@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TemplatePricingRule is a price tier of an event template. Date tiers are stored relative to the
+// event start, so each event created from the template gets them at the same distance.
+type TemplatePricingRule struct {
+	Name              string         `json:"name"`
+	Trigger           PricingTrigger `json:"trigger"`
+	SoldThreshold     int            `json:"sold_threshold,omitempty"`
+	StartsBeforeHours int            `json:"starts_before_hours,omitempty"` // Date tiers: hours before the event starts
+	Price             float64        `json:"price"`
+}
+
+// EventTemplate is an event an organization saved for reuse: its description, ticket price and
+// capacity, settings, price tiers and hold/comp blocks. Events created from it only need a date.
+type EventTemplate struct {
+	ID              uuid.UUID             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID  uuid.UUID             `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name            string                `gorm:"size:100;not null" json:"name"`
+	SourceEventID   *uint                 `json:"source_event_id,omitempty"` // Event the template was saved from
+	CreatedBy       *uuid.UUID            `gorm:"type:uuid" json:"created_by,omitempty"`
+	Title           string                `gorm:"not null;size:200" json:"title"`
+	Description     string                `gorm:"type:text" json:"description"`
+	Location        string                `gorm:"size:200" json:"location"`
+	DurationMinutes int                   `gorm:"not null" json:"duration_minutes"`
+	Price           float64               `gorm:"not null" json:"price"`
+	Capacity        int                   `gorm:"not null" json:"capacity"`
+	WaitlistOpen    bool                  `json:"waitlist_open"`
+	CoverURL        string                `gorm:"size:500" json:"cover_url"`
+	Performer       string                `gorm:"size:200" json:"performer"`
+	Category        string                `gorm:"size:50" json:"category"`
+	RefundPolicy    RefundPolicy          `gorm:"embedded" json:"refund_policy"`
+	PricingRules    []TemplatePricingRule `gorm:"serializer:json" json:"pricing_rules"`
+	Allocations     []FranchiseAllocation `gorm:"serializer:json" json:"allocations"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// SaveEventTemplateRequest is the request structure for saving an event as a template
+type SaveEventTemplateRequest struct {
+	EventID uint   `json:"event_id" binding:"required" example:"1"`
+	Name    string `json:"name" binding:"required,max=100" example:"Friday comedy night"`
+}
+
+// InstantiateEventTemplateRequest is the request structure for creating an event from a template.
+// The end date follows from the template's duration; empty fields keep the template's values.
+type InstantiateEventTemplateRequest struct {
+	StartDate time.Time `json:"start_date" binding:"required" example:"2025-09-05T20:00:00Z"`
+	Title     string    `json:"title" binding:"omitempty,max=200" example:"Friday comedy night - September"`
+	Location  string    `json:"location" binding:"omitempty,max=200" example:"The Basement, Austin"`
+	Performer string    `json:"performer" binding:"omitempty,max=200" example:"Jane Doe"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (t *EventTemplate) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
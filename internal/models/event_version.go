@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EventVersionAction says what produced a version of an event
+type EventVersionAction string
+
+const (
+	EventVersionCreated    EventVersionAction = "created"
+	EventVersionUpdated    EventVersionAction = "updated"
+	EventVersionPublished  EventVersionAction = "published"   // Draft changes applied
+	EventVersionStatus     EventVersionAction = "status"      // Paused, cancelled or completed
+	EventVersionRolledBack EventVersionAction = "rolled_back" // Details restored from an earlier version
+	EventVersionBaseline   EventVersionAction = "baseline"    // State of an event created before history was kept, recorded on its first change
+)
+
+// EventSnapshot holds the organizer-editable details of an event at one point in time. Ticket
+// availability is left out since it changes with every sale.
+type EventSnapshot struct {
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Location     string       `json:"location"`
+	StartDate    time.Time    `json:"start_date"`
+	EndDate      time.Time    `json:"end_date"`
+	Price        float64      `json:"price"`
+	Capacity     int          `json:"capacity"`
+	Status       EventStatus  `json:"status"`
+	CoverURL     string       `json:"cover_url"`
+	Performer    string       `json:"performer"`
+	Category     string       `json:"category"`
+	RefundPolicy RefundPolicy `json:"refund_policy"`
+}
+
+// EventFieldChange is a field whose value differs from the previous version of an event
+type EventFieldChange struct {
+	Field string      `json:"field" example:"price"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// EventVersion is a snapshot of an event recorded each time its details change, with the
+// changes from the version before it
+type EventVersion struct {
+	ID              uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EventID         uint               `gorm:"not null;uniqueIndex:idx_event_version" json:"event_id"`
+	Version         int                `gorm:"not null;uniqueIndex:idx_event_version" json:"version"`
+	Action          EventVersionAction `gorm:"size:20;not null" json:"action"`
+	ChangedBy       *uuid.UUID         `gorm:"type:uuid" json:"changed_by,omitempty"` // Nil for baselines
+	RestoredVersion *int               `json:"restored_version,omitempty"`            // Version a rollback restored
+	Snapshot        EventSnapshot      `gorm:"serializer:json" json:"snapshot"`       // Event details after the change
+	Changes         []EventFieldChange `gorm:"serializer:json" json:"changes"`        // Empty for the first version
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+// SnapshotOf returns the organizer-editable details of an event, with dates in UTC so snapshots
+// compare equal whatever time zone the event was loaded in
+func SnapshotOf(event *Event) EventSnapshot {
+	return EventSnapshot{
+		Title:        event.Title,
+		Description:  event.Description,
+		Location:     event.Location,
+		StartDate:    event.StartDate.UTC(),
+		EndDate:      event.EndDate.UTC(),
+		Price:        event.Price,
+		Capacity:     event.Capacity,
+		Status:       event.Status,
+		CoverURL:     event.CoverURL,
+		Performer:    event.Performer,
+		Category:     event.Category,
+		RefundPolicy: event.RefundPolicy,
+	}
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (v *EventVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FranchiseEventStatus tracks a pushed template event through localization to publication
+type FranchiseEventStatus string
+
+const (
+	FranchiseEventDraft     FranchiseEventStatus = "draft"     // Pushed to the child organization, awaiting localization
+	FranchiseEventPublished FranchiseEventStatus = "published" // Published as a live event of the child organization
+)
+
+// FranchiseAllocation is a hold or comp block of the template event, recreated for the child's event
+type FranchiseAllocation struct {
+	Type     AllocationType `json:"type"`
+	Label    string         `json:"label"`
+	Quantity int            `json:"quantity"`
+}
+
+// FranchiseEvent is a copy of a parent organization's template event pushed to a child organization.
+// The child localizes the copy, e.g. its dates and venue, and publishing it creates the child's own event.
+type FranchiseEvent struct {
+	ID              uuid.UUID             `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID  uuid.UUID             `gorm:"type:uuid;not null;index" json:"organization_id"` // Child organization the event was pushed to
+	TemplateEventID uint                  `gorm:"not null;index" json:"template_event_id"`
+	PushedBy        *uuid.UUID            `gorm:"type:uuid" json:"pushed_by,omitempty"`
+	Title           string                `gorm:"not null;size:200" json:"title"`
+	Description     string                `gorm:"type:text" json:"description"`
+	Location        string                `gorm:"size:200" json:"location"`
+	StartDate       time.Time             `gorm:"not null" json:"start_date"`
+	EndDate         time.Time             `gorm:"not null" json:"end_date"`
+	Price           float64               `gorm:"not null" json:"price"`
+	Capacity        int                   `gorm:"not null" json:"capacity"`
+	CoverURL        string                `gorm:"size:500" json:"cover_url"`
+	Performer       string                `gorm:"size:200" json:"performer"`
+	Category        string                `gorm:"size:50" json:"category"`
+	RefundPolicy    RefundPolicy          `gorm:"embedded" json:"refund_policy"`
+	Allocations     []FranchiseAllocation `gorm:"serializer:json" json:"allocations"`
+	Status          FranchiseEventStatus  `gorm:"size:20;not null;default:'draft';index" json:"status"`
+	EventID         *uint                 `gorm:"index" json:"event_id,omitempty"` // Child's event once published
+	PublishedAt     *time.Time            `json:"published_at,omitempty"`
+	CreatedAt       time.Time             `json:"created_at"`
+	UpdatedAt       time.Time             `json:"updated_at"`
+}
+
+// PushFranchiseEventRequest is the request structure for pushing a template event to child organizations
+type PushFranchiseEventRequest struct {
+	EventID         uint        `json:"event_id" binding:"required" example:"1"`
+	OrganizationIDs []uuid.UUID `json:"organization_ids" binding:"required,min=1,max=100"`
+}
+
+// LocalizeFranchiseEventRequest is the request structure for adapting a pushed event before publishing.
+// Empty fields are left unchanged.
+type LocalizeFranchiseEventRequest struct {
+	Title       string     `json:"title" binding:"omitempty,max=200" example:"Summer Tour - Lisbon"`
+	Description string     `json:"description"`
+	Location    string     `json:"location" binding:"omitempty,max=200" example:"Altice Arena, Lisbon"`
+	StartDate   *time.Time `json:"start_date" example:"2025-07-12T19:00:00Z"`
+	EndDate     *time.Time `json:"end_date" example:"2025-07-12T23:00:00Z"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (f *FranchiseEvent) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	if f.Status == "" {
+		f.Status = FranchiseEventDraft
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InstallmentStatus represents the payment state of a single installment
+type InstallmentStatus string
+
+const (
+	InstallmentStatusScheduled InstallmentStatus = "scheduled"
+	InstallmentStatusPaid      InstallmentStatus = "paid"
+	InstallmentStatusFailed    InstallmentStatus = "failed" // Retries exhausted
+	InstallmentStatusCancelled InstallmentStatus = "cancelled"
+)
+
+// Installment is one scheduled part-payment of an order paid in installments
+type Installment struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID        uuid.UUID         `gorm:"type:uuid;not null;index" json:"order_id"`
+	OrganizationID *uuid.UUID        `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	Sequence       int               `gorm:"not null" json:"sequence"`
+	Amount         float64           `gorm:"not null" json:"amount"`
+	DueDate        time.Time         `gorm:"not null;index" json:"due_date"`
+	Status         InstallmentStatus `gorm:"not null;default:'scheduled'" json:"status"`
+	Attempts       int               `gorm:"not null;default:0" json:"attempts"`
+	LastError      string            `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time        `json:"next_attempt_at,omitempty"`
+	PaidAt         *time.Time        `json:"paid_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// InstallmentPlanRequest is the request structure for splitting a pending order into installments.
+// The first installment is charged immediately and the last must fall before the event starts.
+type InstallmentPlanRequest struct {
+	Installments     int    `json:"installments" binding:"required,min=2,max=12" example:"3"`
+	Interval         string `json:"interval" binding:"required,oneof=weekly monthly" example:"monthly"`
+	CustomerRef      string `json:"customer_ref" binding:"required" example:"cus_NffrFeUfNV2Hib"`
+	PaymentMethodRef string `json:"payment_method_ref" binding:"required" example:"pm_1MqLiJLkdIwHu7ixUEgbFdYF"`
+}
+
+// InstallmentPlanResponse is the response structure for an order's installment plan
+type InstallmentPlanResponse struct {
+	OrderID      uuid.UUID     `json:"order_id"`
+	OrderStatus  OrderStatus   `json:"order_status"`
+	TotalAmount  float64       `json:"total_amount"`
+	PaidAmount   float64       `json:"paid_amount"`
+	Currency     string        `json:"currency"`
+	Installments []Installment `json:"installments"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (i *Installment) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	if i.Status == "" {
+		i.Status = InstallmentStatusScheduled
+	}
+	return nil
+}
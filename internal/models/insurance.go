@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InsuranceStatus represents the state of an order's ticket insurance with the provider
+type InsuranceStatus string
+
+const (
+	InsuranceStatusPending   InsuranceStatus = "pending"   // Quoted and charged, not yet bound
+	InsuranceStatusBound     InsuranceStatus = "bound"     // Policy issued, the buyer is covered
+	InsuranceStatusFailed    InsuranceStatus = "failed"    // The provider refused to bind; the premium is refundable
+	InsuranceStatusCancelled InsuranceStatus = "cancelled" // Policy cancelled after the order was refunded
+)
+
+// OrderInsurance is the ticket insurance bought with an order. The premium is part of the order
+// total; once the policy is bound it is not refunded with the tickets unless the policy is cancelled.
+type OrderInsurance struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID        uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	OrganizationID *uuid.UUID      `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	Provider       string          `gorm:"size:50;not null" json:"provider"`
+	QuoteRef       string          `gorm:"not null" json:"quote_ref"`
+	PolicyRef      string          `json:"policy_ref,omitempty"`
+	Premium        float64         `gorm:"not null" json:"premium"`
+	CoveredAmount  float64         `gorm:"not null" json:"covered_amount"` // Ticket value the policy insures
+	Currency       string          `gorm:"size:3;not null" json:"currency"`
+	Status         InsuranceStatus `gorm:"not null;index" json:"status"`
+	Error          string          `json:"error,omitempty"`
+	BoundAt        *time.Time      `json:"bound_at,omitempty"`
+	CancelledAt    *time.Time      `json:"cancelled_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// InsuranceQuoteRequest is the query structure for quoting ticket insurance before placing an order
+type InsuranceQuoteRequest struct {
+	EventID  uint `form:"event_id" binding:"required" example:"1"`
+	Quantity int  `form:"quantity" binding:"required,min=1,max=50" example:"2"`
+}
+
+// InsuranceQuoteResponse is a provider's offer to insure an order's tickets
+type InsuranceQuoteResponse struct {
+	Provider      string    `json:"provider"`
+	Premium       float64   `json:"premium"`
+	CoveredAmount float64   `json:"covered_amount"`
+	Currency      string    `json:"currency"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	TermsURL      string    `json:"terms_url,omitempty"` // Policy wording to show the buyer before they opt in
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (i *OrderInsurance) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	if i.Status == "" {
+		i.Status = InsuranceStatusPending
+	}
+	return nil
+}
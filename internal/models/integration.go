@@ -0,0 +1,142 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// HookEvent identifies the trigger a REST hook subscription listens for
+type HookEvent string
+
+const (
+	HookEventOrderCreated    HookEvent = "order.created"
+	HookEventAttendeeCreated HookEvent = "attendee.created"
+	HookEventTicketCheckedIn HookEvent = "ticket.checked_in"
+	HookEventEventUpdated    HookEvent = "event.updated"
+	HookEventRefundProcessed HookEvent = "refund.processed"
+)
+
+// HookSubscription is a webhook endpoint registered by an organization, or a REST hook of an
+// integration platform (Zapier, Make). Payloads are POSTed to TargetURL when the trigger fires,
+// signed with the subscription's secret.
+type HookSubscription struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Event          HookEvent  `gorm:"not null;index" json:"event"`
+	TargetURL      string     `gorm:"not null" json:"target_url"`
+	Secret         string     `gorm:"serializer:encrypted" json:"-"` // HMAC-SHA256 signing key, encrypted at rest; empty on hooks subscribed before signing
+	CreatedBy      *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// HookSubscribeRequest is the request structure for subscribing a REST hook
+type HookSubscribeRequest struct {
+	TargetURL string `json:"target_url" binding:"required,url" example:"https://hooks.zapier.com/hooks/standard/123/abc"`
+	Event     string `json:"event" binding:"required,oneof=order.created attendee.created ticket.checked_in event.updated refund.processed" example:"order.created"`
+}
+
+// HookSubscriptionResponse is the response structure for a REST hook subscription
+type HookSubscriptionResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Event     HookEvent `json:"event"`
+	TargetURL string    `json:"target_url"`
+	Signed    bool      `json:"signed"`           // Deliveries carry an X-Hook-Signature header
+	Secret    string    `json:"secret,omitempty"` // Signing secret, only returned when it is created or rotated
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HookDelivery is the payload of a queued REST hook delivery task
+type HookDelivery struct {
+	ID             uuid.UUID       `json:"id"` // Delivery log entry, also sent as X-Hook-Delivery
+	SubscriptionID uuid.UUID       `json:"subscription_id"`
+	TargetURL      string          `json:"target_url"`
+	Event          HookEvent       `json:"event"`
+	Data           json.RawMessage `json:"data"` // Request body, signed and sent as-is
+}
+
+// HookDeliveryStatus is the outcome of a hook delivery so far
+type HookDeliveryStatus string
+
+const (
+	HookDeliveryPending   HookDeliveryStatus = "pending"   // Queued, or failed and waiting for a retry
+	HookDeliveryDelivered HookDeliveryStatus = "delivered" // The target answered 2xx
+	HookDeliveryFailed    HookDeliveryStatus = "failed"    // Retries exhausted, or the target is gone
+)
+
+// HookDeliveryLog records a hook delivery and its latest attempt, for debugging endpoints
+type HookDeliveryLog struct {
+	ID             uuid.UUID          `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID          `gorm:"type:uuid;not null;index:idx_hook_delivery_org" json:"organization_id"`
+	SubscriptionID uuid.UUID          `gorm:"type:uuid;not null;index" json:"subscription_id"`
+	Event          HookEvent          `gorm:"size:50;not null" json:"event"`
+	TargetURL      string             `gorm:"not null" json:"target_url"`
+	Payload        string             `gorm:"type:text;not null" json:"payload"` // Request body as sent
+	Status         HookDeliveryStatus `gorm:"size:20;not null;index" json:"status"`
+	Attempts       int                `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int                `json:"response_status,omitempty"` // HTTP status of the latest attempt
+	Error          string             `json:"error,omitempty"`           // Why the latest attempt failed
+	DurationMs     int64              `json:"duration_ms"`               // Time the latest attempt took
+	LastAttemptAt  *time.Time         `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time         `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time          `gorm:"index:idx_hook_delivery_org" json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// HookDeliveryQuery filters an organization's hook delivery log
+type HookDeliveryQuery struct {
+	PageQuery
+	HookID string             `form:"hook_id" binding:"omitempty,uuid" example:"5f1c7a52-2f43-4f2e-9a59-0f5b0b7f3c11"`
+	Event  HookEvent          `form:"event" binding:"omitempty,oneof=order.created attendee.created ticket.checked_in event.updated refund.processed" example:"order.created"`
+	Status HookDeliveryStatus `form:"status" binding:"omitempty,oneof=pending delivered failed" example:"failed"`
+}
+
+// HookCheckIn is the payload of a ticket.checked_in hook
+type HookCheckIn struct {
+	AttendeeResponse
+	CheckedInAt *time.Time `json:"checked_in_at"`
+	CheckInGate string     `json:"check_in_gate,omitempty"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (h *HookSubscription) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (l *HookDeliveryLog) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
+
+// ToResponse converts a HookSubscription model to a HookSubscriptionResponse
+func (h *HookSubscription) ToResponse() HookSubscriptionResponse {
+	return HookSubscriptionResponse{
+		ID:        h.ID,
+		Event:     h.Event,
+		TargetURL: h.TargetURL,
+		Signed:    h.Secret != "",
+		CreatedAt: h.CreatedAt,
+	}
+}
+
+// PollingQuery holds the query parameters accepted by polling trigger endpoints
+type PollingQuery struct {
+	Since string `form:"since"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// PollingPage is a cursor-paginated page of items returned by polling trigger endpoints
+type PollingPage struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"next_cursor"`
+	HasMore    bool        `json:"has_more"`
+}
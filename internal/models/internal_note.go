@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InternalSubjectType names what internal notes and tags are attached to
+type InternalSubjectType string
+
+const (
+	InternalSubjectOrder  InternalSubjectType = "order"
+	InternalSubjectTicket InternalSubjectType = "ticket" // An attendee
+)
+
+// InternalNote is a note organization members keep on an order or attendee, such as "refund
+// requested by phone". Notes are only shown to members allowed to read them and never to buyers
+// or attendees.
+type InternalNote struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID           `gorm:"type:uuid;not null;index" json:"organization_id"`
+	SubjectType    InternalSubjectType `gorm:"size:20;not null;index:idx_internal_note_subject" json:"subject_type"`
+	SubjectID      uuid.UUID           `gorm:"type:uuid;not null;index:idx_internal_note_subject" json:"subject_id"`
+	Body           string              `gorm:"type:text;not null" json:"body"`
+	AuthorID       uuid.UUID           `gorm:"type:uuid;not null" json:"author_id"`
+	CreatedAt      time.Time           `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// InternalTag is a label organization members put on an order or attendee, such as "vip". Tags are
+// stored lowercase and, like notes, only shown to members allowed to read them.
+type InternalTag struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID           `gorm:"type:uuid;not null;index:idx_internal_tag_name" json:"organization_id"`
+	SubjectType    InternalSubjectType `gorm:"size:20;not null;uniqueIndex:idx_internal_tag" json:"subject_type"`
+	SubjectID      uuid.UUID           `gorm:"type:uuid;not null;uniqueIndex:idx_internal_tag" json:"subject_id"`
+	Name           string              `gorm:"size:50;not null;uniqueIndex:idx_internal_tag;index:idx_internal_tag_name" json:"name"`
+	CreatedBy      uuid.UUID           `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt      time.Time           `json:"created_at"`
+}
+
+// InternalNoteRequest is the request structure for adding an internal note
+type InternalNoteRequest struct {
+	Body string `json:"body" binding:"required,max=5000" example:"Refund requested by phone, call back after the event"`
+}
+
+// InternalTagsRequest is the request structure for replacing the internal tags of an order or attendee
+type InternalTagsRequest struct {
+	Tags []string `json:"tags" binding:"max=20,dive,required,max=50" example:"vip,press"`
+}
+
+// InternalAnnotationsResponse holds the internal tags and notes of an order or attendee, newest
+// notes first
+type InternalAnnotationsResponse struct {
+	SubjectType InternalSubjectType `json:"subject_type"`
+	SubjectID   uuid.UUID           `json:"subject_id"`
+	Tags        []string            `json:"tags"`
+	Notes       []InternalNote      `json:"notes"`
+}
+
+// InternalAnnotationQuery searches the orders and attendees of an organization by their internal
+// notes and tags. Q matches text in notes; both filters must match when given.
+type InternalAnnotationQuery struct {
+	PageQuery
+	Q           string              `form:"q" binding:"omitempty,max=100" example:"phone"`
+	Tag         string              `form:"tag" binding:"omitempty,max=50" example:"vip"`
+	SubjectType InternalSubjectType `form:"subject_type" binding:"omitempty,oneof=order ticket" example:"ticket"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (n *InternalNote) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (t *InternalTag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Channels an inventory alert can be delivered through
+const (
+	AlertChannelChat  = "chat"  // The organization's Slack/Discord webhooks
+	AlertChannelEmail = "email" // The organization's organizer
+)
+
+// InventoryAlert is an organizer-defined threshold on the share of an event's capacity that has been sold.
+// A threshold of 100 is a sold-out alert.
+type InventoryAlert struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"organization_id"`
+	EventID          uint       `gorm:"not null;index" json:"event_id"`
+	ThresholdPercent int        `gorm:"not null" json:"threshold_percent"`
+	Channels         []string   `gorm:"serializer:json" json:"channels"`
+	AutoOpenWaitlist bool       `gorm:"default:false" json:"auto_open_waitlist"`
+	LastTriggeredAt  *time.Time `json:"last_triggered_at,omitempty"`
+	CreatedBy        *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// InventoryAlertRequest is the request structure for creating an inventory alert.
+// Channels default to chat and email.
+type InventoryAlertRequest struct {
+	EventID          uint     `json:"event_id" binding:"required" example:"1"`
+	ThresholdPercent int      `json:"threshold_percent" binding:"required,min=1,max=100" example:"90"`
+	Channels         []string `json:"channels" binding:"omitempty,dive,oneof=chat email" example:"chat,email"`
+	AutoOpenWaitlist bool     `json:"auto_open_waitlist" example:"false"`
+}
+
+// InventoryAlertResponse is the response structure for an inventory alert
+type InventoryAlertResponse struct {
+	ID               uuid.UUID  `json:"id"`
+	EventID          uint       `json:"event_id"`
+	ThresholdPercent int        `json:"threshold_percent"`
+	Channels         []string   `json:"channels"`
+	AutoOpenWaitlist bool       `json:"auto_open_waitlist"`
+	LastTriggeredAt  *time.Time `json:"last_triggered_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *InventoryAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// ToResponse converts an InventoryAlert model to an InventoryAlertResponse
+func (a *InventoryAlert) ToResponse() InventoryAlertResponse {
+	return InventoryAlertResponse{
+		ID:               a.ID,
+		EventID:          a.EventID,
+		ThresholdPercent: a.ThresholdPercent,
+		Channels:         a.Channels,
+		AutoOpenWaitlist: a.AutoOpenWaitlist,
+		LastTriggeredAt:  a.LastTriggeredAt,
+		CreatedAt:        a.CreatedAt,
+	}
+}
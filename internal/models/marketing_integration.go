@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MarketingProvider identifies an email marketing platform contacts can be synced to
+type MarketingProvider string
+
+const (
+	MarketingProviderMailchimp MarketingProvider = "mailchimp"
+	MarketingProviderBrevo     MarketingProvider = "brevo"
+)
+
+// Contact fields that can be mapped onto provider merge fields / attributes
+const (
+	ContactFieldFirstName  = "first_name"
+	ContactFieldLastName   = "last_name"
+	ContactFieldFullName   = "full_name"
+	ContactFieldEventTitle = "event_title"
+	ContactFieldEventDate  = "event_date"
+)
+
+// MarketingIntegration holds an organization's connection to its Mailchimp or Brevo audience
+type MarketingIntegration struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex" json:"organization_id"`
+	Provider       MarketingProvider `gorm:"not null" json:"provider"`
+	APIKey         string            `gorm:"not null" json:"-"`
+	AudienceID     string            `gorm:"not null" json:"audience_id"` // Mailchimp list ID or Brevo list ID
+	FieldMapping   map[string]string `gorm:"serializer:json" json:"field_mapping"`
+	TagByEvent     bool              `gorm:"default:true" json:"tag_by_event"`
+	Enabled        bool              `gorm:"default:true" json:"enabled"`
+	LastSyncedAt   *time.Time        `json:"last_synced_at"`
+	LastError      string            `json:"last_error,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// MarketingIntegrationRequest is the request structure for configuring a contact sync integration
+type MarketingIntegrationRequest struct {
+	Provider     string            `json:"provider" binding:"required,oneof=mailchimp brevo" example:"mailchimp"`
+	APIKey       string            `json:"api_key" binding:"required" example:"0123456789abcdef-us21"`
+	AudienceID   string            `json:"audience_id" binding:"required" example:"a1b2c3d4e5"`
+	FieldMapping map[string]string `json:"field_mapping" example:"first_name:FNAME,last_name:LNAME"`
+	TagByEvent   *bool             `json:"tag_by_event" example:"true"`
+	Enabled      *bool             `json:"enabled" example:"true"`
+}
+
+// MarketingIntegrationResponse is the response structure for a contact sync integration
+type MarketingIntegrationResponse struct {
+	ID           uuid.UUID         `json:"id"`
+	Provider     MarketingProvider `json:"provider"`
+	AudienceID   string            `json:"audience_id"`
+	FieldMapping map[string]string `json:"field_mapping"`
+	TagByEvent   bool              `json:"tag_by_event"`
+	Enabled      bool              `json:"enabled"`
+	LastSyncedAt *time.Time        `json:"last_synced_at"`
+	LastError    string            `json:"last_error,omitempty"`
+}
+
+// MarketingContact is a single attendee prepared for upload to a marketing provider
+type MarketingContact struct {
+	Email  string
+	Fields map[string]string // Provider field name -> value, after mapping
+	Tags   []string
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (m *MarketingIntegration) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// ToResponse converts a MarketingIntegration model to a MarketingIntegrationResponse
+func (m *MarketingIntegration) ToResponse() MarketingIntegrationResponse {
+	return MarketingIntegrationResponse{
+		ID:           m.ID,
+		Provider:     m.Provider,
+		AudienceID:   m.AudienceID,
+		FieldMapping: m.FieldMapping,
+		TagByEvent:   m.TagByEvent,
+		Enabled:      m.Enabled,
+		LastSyncedAt: m.LastSyncedAt,
+		LastError:    m.LastError,
+	}
+}
+
+// DefaultMarketingFieldMapping returns the conventional merge fields for a provider
+func DefaultMarketingFieldMapping(provider MarketingProvider) map[string]string {
+	switch provider {
+	case MarketingProviderBrevo:
+		return map[string]string{
+			ContactFieldFirstName: "FIRSTNAME",
+			ContactFieldLastName:  "LASTNAME",
+		}
+	default:
+		return map[string]string{
+			ContactFieldFirstName: "FNAME",
+			ContactFieldLastName:  "LNAME",
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NewsletterSegment selects the users a newsletter is sent to. Every segment leaves out deleted
+// accounts, unverified email addresses and users who unsubscribed.
+type NewsletterSegment string
+
+const (
+	NewsletterSegmentAllUsers          NewsletterSegment = "all_users"
+	NewsletterSegmentCategoryAttendees NewsletterSegment = "category_attendees" // Users with paid orders for events of a category
+	NewsletterSegmentInactiveUsers     NewsletterSegment = "inactive_users"     // Users who have not signed in for a period
+)
+
+// NewsletterStatus represents the sending state of a newsletter
+type NewsletterStatus string
+
+const (
+	NewsletterStatusDraft     NewsletterStatus = "draft"
+	NewsletterStatusScheduled NewsletterStatus = "scheduled"
+	NewsletterStatusSending   NewsletterStatus = "sending" // Recipients are being resolved and queued
+	NewsletterStatusSent      NewsletterStatus = "sent"
+)
+
+// Newsletter is an email composed by admins and sent to a segment of users, now or at a scheduled
+// time. Opens and clicks of each recipient are tracked while email tracking is enabled.
+type Newsletter struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Subject        string            `gorm:"size:200;not null" json:"subject"`
+	PreviewText    string            `gorm:"size:200" json:"preview_text,omitempty"` // Shown after the subject by most mail clients
+	Content        string            `gorm:"type:text;not null" json:"content"`      // HTML body; its http(s) links are tracked
+	TenantID       *uuid.UUID        `gorm:"type:uuid" json:"tenant_id,omitempty"`   // Brand the email is sent under; the default brand when nil
+	Segment        NewsletterSegment `gorm:"size:30;not null" json:"segment"`
+	Category       string            `gorm:"size:50" json:"category,omitempty"`       // Event category of the category_attendees segment
+	InactiveDays   int               `gorm:"not null;default:0" json:"inactive_days"` // Period of the inactive_users segment; 0 uses NEWSLETTER_INACTIVE_AFTER
+	Status         NewsletterStatus  `gorm:"size:20;not null;default:'draft';index" json:"status"`
+	ScheduledAt    *time.Time        `gorm:"index" json:"scheduled_at,omitempty"`
+	SentAt         *time.Time        `json:"sent_at,omitempty"`
+	RecipientCount int               `gorm:"not null;default:0" json:"recipient_count"`
+	Tracked        bool              `gorm:"not null;default:false" json:"tracked"` // Opens and clicks were tracked; set when sending starts
+	CreatedBy      uuid.UUID         `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt      time.Time         `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// NewsletterRecipient is a user a newsletter was sent to, with their opens and clicks
+type NewsletterRecipient struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	NewsletterID   uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_newsletter_recipient" json:"newsletter_id"`
+	UserID         uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_newsletter_recipient;index" json:"user_id"`
+	Email          string     `gorm:"serializer:encrypted" json:"-"` // Address the newsletter was sent to, encrypted at rest
+	QueuedAt       *time.Time `json:"queued_at,omitempty"`
+	OpenedAt       *time.Time `json:"opened_at,omitempty"` // First open, from the tracking pixel or a click
+	ClickedAt      *time.Time `json:"clicked_at,omitempty"`
+	UnsubscribedAt *time.Time `json:"unsubscribed_at,omitempty"` // When the recipient unsubscribed from this newsletter's link
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// NewsletterClick is a click on a tracked link of a newsletter
+type NewsletterClick struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	NewsletterID uuid.UUID `gorm:"type:uuid;not null;index" json:"newsletter_id"`
+	RecipientID  uuid.UUID `gorm:"type:uuid;not null;index" json:"recipient_id"`
+	Link         int       `gorm:"not null" json:"link"` // Position of the link in the content
+	URL          string    `gorm:"not null" json:"url"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// NewsletterRequest is the request structure for composing a newsletter
+type NewsletterRequest struct {
+	Subject      string            `json:"subject" binding:"required,max=200" example:"New events this month"`
+	PreviewText  string            `json:"preview_text" binding:"omitempty,max=200" example:"Concerts, comedy and more near you"`
+	Content      string            `json:"content" binding:"required,max=200000" example:"<h2>This month</h2><p>See <a href=\"https://example.com/events\">what's on</a>.</p>"`
+	TenantID     *uuid.UUID        `json:"tenant_id"`
+	Segment      NewsletterSegment `json:"segment" binding:"required,oneof=all_users category_attendees inactive_users" example:"category_attendees"`
+	Category     string            `json:"category" binding:"omitempty,max=50" example:"music"` // Required by the category_attendees segment
+	InactiveDays int               `json:"inactive_days" binding:"omitempty,min=1,max=3650" example:"90"`
+}
+
+// ScheduleNewsletterRequest is the request structure for scheduling a newsletter
+type ScheduleNewsletterRequest struct {
+	SendAt *time.Time `json:"send_at" example:"2026-11-01T09:00:00Z"` // Sent right away when omitted
+}
+
+// NewsletterListQuery filters the newsletter listing
+type NewsletterListQuery struct {
+	PageQuery
+	Status NewsletterStatus `form:"status" binding:"omitempty,oneof=draft scheduled sending sent" example:"sent"`
+}
+
+// NewsletterLinkStats counts the clicks on one tracked link of a newsletter
+type NewsletterLinkStats struct {
+	Link         int    `json:"link"`
+	URL          string `json:"url"`
+	Clicks       int64  `json:"clicks"`
+	UniqueClicks int64  `json:"unique_clicks"` // Recipients who clicked it
+}
+
+// NewsletterStats summarizes the engagement with a sent newsletter
+type NewsletterStats struct {
+	Recipients   int64                 `json:"recipients"`
+	Opened       int64                 `json:"opened"`
+	Clicked      int64                 `json:"clicked"`
+	Unsubscribed int64                 `json:"unsubscribed"`
+	OpenRate     float64               `json:"open_rate"`  // Share of recipients who opened it, 0 to 1
+	ClickRate    float64               `json:"click_rate"` // Share of recipients who clicked a link, 0 to 1
+	Links        []NewsletterLinkStats `json:"links"`
+}
+
+// NewsletterResponse is a newsletter with the size of its audience before it is sent and its
+// engagement after
+type NewsletterResponse struct {
+	Newsletter
+	AudienceSize *int64           `json:"audience_size,omitempty"` // Users the segment currently selects, for drafts and scheduled newsletters
+	Stats        *NewsletterStats `json:"stats,omitempty"`
+}
+
+// NewsletterPreferenceRequest is the request structure for subscribing to or unsubscribing from
+// newsletters
+type NewsletterPreferenceRequest struct {
+	Subscribed *bool `json:"subscribed" binding:"required" example:"false"`
+}
+
+// NewsletterPreferenceResponse is the caller's newsletter subscription
+type NewsletterPreferenceResponse struct {
+	Subscribed     bool       `json:"subscribed"`
+	UnsubscribedAt *time.Time `json:"unsubscribed_at,omitempty"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (n *Newsletter) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	if n.Status == "" {
+		n.Status = NewsletterStatusDraft
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *NewsletterRecipient) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (c *NewsletterClick) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
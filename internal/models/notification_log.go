@@ -0,0 +1,104 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationChannel is how a notification reaches its recipient
+type NotificationChannel string
+
+const (
+	NotificationChannelEmail    NotificationChannel = "email"
+	NotificationChannelSMS      NotificationChannel = "sms"
+	NotificationChannelWhatsApp NotificationChannel = "whatsapp"
+)
+
+// NotificationStatus represents the delivery state of a notification
+type NotificationStatus string
+
+const (
+	NotificationStatusQueued NotificationStatus = "queued"
+	NotificationStatusSent   NotificationStatus = "sent"   // Accepted by the mail server, SMS provider or WhatsApp
+	NotificationStatusFailed NotificationStatus = "failed" // Last attempt failed; retried until the job gives up
+)
+
+// NotificationLog tracks the delivery of a ticket notification or event reminder, by email, SMS or
+// WhatsApp
+type NotificationLog struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Channel        NotificationChannel `gorm:"size:10;not null" json:"channel"`
+	Type           string              `gorm:"size:50;not null" json:"type"`             // What was sent, e.g. ticket_confirmation
+	Recipient      string              `gorm:"serializer:encrypted" json:"recipient"`    // Email address or phone number, encrypted at rest
+	UserID         *uuid.UUID          `gorm:"type:uuid;index" json:"user_id,omitempty"` // Recipient's account, for channels requiring their opt-in
+	OrganizationID *uuid.UUID          `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	OrderID        *uuid.UUID          `gorm:"type:uuid;index" json:"order_id,omitempty"`
+	TicketID       *uuid.UUID          `gorm:"type:uuid;index" json:"ticket_id,omitempty"`
+	Status         NotificationStatus  `gorm:"size:20;not null;default:'queued';index" json:"status"`
+	Attempts       int                 `gorm:"not null;default:0" json:"attempts"`
+	ProviderRef    string              `gorm:"size:100" json:"provider_ref,omitempty"` // Message ID assigned by the SMS provider or WhatsApp
+	LastError      string              `json:"last_error,omitempty"`
+	SentAt         *time.Time          `json:"sent_at,omitempty"`
+	Tracked        bool                `gorm:"not null;default:false" json:"tracked"` // Email sent with a tracking pixel and tracked links
+	Opens          int                 `gorm:"not null;default:0" json:"opens"`
+	Clicks         int                 `gorm:"not null;default:0" json:"clicks"`
+	OpenedAt       *time.Time          `json:"opened_at,omitempty"` // First open, from the tracking pixel or a click
+	ClickedAt      *time.Time          `json:"clicked_at,omitempty"`
+	CreatedAt      time.Time           `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+}
+
+// NotificationStatsFilter is the query structure for an organization's notification analytics.
+// Notifications are counted by the day they were queued; both dates are inclusive.
+type NotificationStatsFilter struct {
+	From string `form:"from" binding:"omitempty,datetime=2006-01-02" example:"2025-01-01"`
+	To   string `form:"to" binding:"omitempty,datetime=2006-01-02" example:"2025-12-31"`
+}
+
+// NotificationTypeStats is the delivery and engagement of one type of notification on one channel
+type NotificationTypeStats struct {
+	Channel   NotificationChannel `json:"channel"`
+	Type      string              `json:"type"`
+	Total     int64               `json:"total"`
+	Sent      int64               `json:"sent"`
+	Failed    int64               `json:"failed"`
+	Tracked   int64               `json:"tracked"` // Emails sent with open and click tracking
+	Opened    int64               `json:"opened"`
+	Clicked   int64               `json:"clicked"`
+	OpenRate  float64             `json:"open_rate"`  // Share of tracked emails opened, 0 to 1
+	ClickRate float64             `json:"click_rate"` // Share of tracked emails with a clicked link, 0 to 1
+}
+
+// NotificationStats is the delivery and engagement of the notifications an organization's
+// attendees and buyers were sent
+type NotificationStats struct {
+	OrganizationID uuid.UUID               `json:"organization_id"`
+	From           string                  `json:"from,omitempty"`
+	To             string                  `json:"to,omitempty"`
+	Types          []NotificationTypeStats `json:"types"`
+}
+
+// WhatsAppOptInRequest is the request structure for opting in to WhatsApp messages
+type WhatsAppOptInRequest struct {
+	Phone string `json:"phone" binding:"required,phone" example:"+12345678901"` // WhatsApp number in international format
+}
+
+// WhatsAppOptInResponse is the caller's WhatsApp opt-in
+type WhatsAppOptInResponse struct {
+	OptedIn   bool       `json:"opted_in"`
+	Phone     string     `json:"phone,omitempty"`
+	OptedInAt *time.Time `json:"opted_in_at,omitempty"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (n *NotificationLog) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	if n.Status == "" {
+		n.Status = NotificationStatusQueued
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderStatus represents the lifecycle state of an order
+type OrderStatus string
+
+const (
+	OrderStatusPending       OrderStatus = "pending"
+	OrderStatusPaid          OrderStatus = "paid"
+	OrderStatusPartiallyPaid OrderStatus = "partially_paid" // Installment plan in progress
+	OrderStatusCancelled     OrderStatus = "cancelled"
+	OrderStatusRefunded      OrderStatus = "refunded"
+)
+
+// DefaultCurrency is the currency orders are priced in
+const DefaultCurrency = "USD"
+
+// Payment methods an order can be settled with
+const (
+	PaymentMethodCard         = "card"
+	PaymentMethodInvoice      = "invoice"      // Billed to the attendee, paid later
+	PaymentMethodCash         = "cash"         // Collected by staff at the box office
+	PaymentMethodComp         = "comp"         // Complimentary, no payment taken
+	PaymentMethodInstallments = "installments" // Split into scheduled installments charged automatically
+)
+
+// Order represents a ticket purchase for an event
+type Order struct {
+	ID                  uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EventID             uint            `gorm:"not null;index" json:"event_id"`
+	Event               *Event          `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	OrganizationID      *uuid.UUID      `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	UserID              *uuid.UUID      `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	BuyerEmail          string          `gorm:"not null" json:"buyer_email"`
+	BuyerName           string          `json:"buyer_name"`
+	Quantity            int             `gorm:"not null" json:"quantity"`
+	TotalAmount         float64         `gorm:"not null" json:"total_amount"`
+	FeeAmount           float64         `gorm:"not null;default:0" json:"fee_amount"`       // Platform and processing fees withheld from the payout
+	InsuranceAmount     float64         `gorm:"not null;default:0" json:"insurance_amount"` // Ticket insurance premium included in the total
+	RefundedAmount      float64         `gorm:"not null;default:0" json:"refunded_amount"`
+	Currency            string          `gorm:"size:3;not null;default:'USD'" json:"currency"`
+	Status              OrderStatus     `gorm:"not null;default:'pending'" json:"status"`
+	PaymentMethod       string          `gorm:"size:20;not null;default:'card'" json:"payment_method"`
+	CreatedBy           *uuid.UUID      `gorm:"type:uuid" json:"created_by,omitempty"`               // Staff member who placed the order on the buyer's behalf
+	BundlePurchaseID    *uuid.UUID      `gorm:"type:uuid;index" json:"bundle_purchase_id,omitempty"` // Bundle purchase that placed the order with the orders for the bundle's other events
+	PresaleID           *uuid.UUID      `gorm:"type:uuid;index" json:"presale_id,omitempty"`         // Presale the buyer was admitted to when ordering during one
+	PresaleAccessCodeID *uuid.UUID      `gorm:"type:uuid" json:"presale_access_code_id,omitempty"`   // Access code that admitted the buyer; empty for allowlisted buyers
+	CustomerRef         string          `json:"-"`                                                   // Payment provider customer ID
+	PaymentRef          string          `json:"-"`                                                   // Payment provider saved payment method ID
+	SMSPhone            string          `gorm:"serializer:encrypted" json:"-"`                       // Phone number ticket links are texted to, encrypted at rest
+	Tickets             []*Ticket       `gorm:"foreignKey:OrderID" json:"tickets,omitempty"`
+	Insurance           *OrderInsurance `gorm:"foreignKey:OrderID" json:"insurance,omitempty"`
+	PaidAt              *time.Time      `gorm:"index" json:"paid_at,omitempty"`
+	CreatedAt           time.Time       `gorm:"index" json:"created_at"`
+	UpdatedAt           time.Time       `json:"updated_at"`
+}
+
+// OrderResponse is the response structure for order data
+type OrderResponse struct {
+	ID               uuid.UUID   `json:"id"`
+	EventID          uint        `json:"event_id"`
+	OrganizationID   *uuid.UUID  `json:"organization_id,omitempty"`
+	BuyerEmail       string      `json:"buyer_email"`
+	BuyerName        string      `json:"buyer_name"`
+	Quantity         int         `json:"quantity"`
+	TotalAmount      float64     `json:"total_amount"`
+	FeeAmount        float64     `json:"fee_amount"`
+	InsuranceAmount  float64     `json:"insurance_amount"`
+	RefundedAmount   float64     `json:"refunded_amount"`
+	Currency         string      `json:"currency"`
+	Status           OrderStatus `json:"status"`
+	PaymentMethod    string      `json:"payment_method"`
+	BundlePurchaseID *uuid.UUID  `json:"bundle_purchase_id,omitempty"`
+	PresaleID        *uuid.UUID  `json:"presale_id,omitempty"`
+	PaidAt           *time.Time  `json:"paid_at,omitempty"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (o *Order) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	if o.Status == "" {
+		o.Status = OrderStatusPending
+	}
+	if o.PaymentMethod == "" {
+		o.PaymentMethod = PaymentMethodCard
+	}
+	return nil
+}
+
+// ToResponse converts an Order model to an OrderResponse
+func (o *Order) ToResponse() OrderResponse {
+	return OrderResponse{
+		ID:               o.ID,
+		EventID:          o.EventID,
+		OrganizationID:   o.OrganizationID,
+		BuyerEmail:       o.BuyerEmail,
+		BuyerName:        o.BuyerName,
+		Quantity:         o.Quantity,
+		TotalAmount:      o.TotalAmount,
+		FeeAmount:        o.FeeAmount,
+		InsuranceAmount:  o.InsuranceAmount,
+		RefundedAmount:   o.RefundedAmount,
+		Currency:         o.Currency,
+		Status:           o.Status,
+		PaymentMethod:    o.PaymentMethod,
+		BundlePurchaseID: o.BundlePurchaseID,
+		PresaleID:        o.PresaleID,
+		PaidAt:           o.PaidAt,
+		CreatedAt:        o.CreatedAt,
+	}
+}
+
+// StaffOrderRequest is the request structure for staff placing an order on an attendee's behalf (e.g. phone orders)
+type StaffOrderRequest struct {
+	EventID        uint        `json:"event_id" binding:"required" example:"1"`
+	Quantity       int         `json:"quantity" binding:"required,min=1,max=50" example:"2"`
+	AttendeeName   string      `json:"attendee_name" binding:"required,max=200" example:"Jane Doe"`
+	AttendeeEmail  string      `json:"attendee_email" binding:"required,email" example:"jane@example.com"`
+	PaymentMethod  string      `json:"payment_method" binding:"required,oneof=invoice cash" example:"cash"`
+	MarketingOptIn bool        `json:"marketing_opt_in" example:"false"`
+	Insurance      bool        `json:"insurance" example:"false"`                                  // Add ticket insurance, quoted by the insurance provider at order time
+	SMSPhone       string      `json:"sms_phone" binding:"omitempty,phone" example:"+12345678901"` // Also text a link to each ticket to this number
+	SeatIDs        []uuid.UUID `json:"seat_ids" binding:"omitempty,max=50"`                        // One seat per ticket, required at events with a seat map
+}
+
+// OrderDetailResponse is the response structure for an order with its tickets
+type OrderDetailResponse struct {
+	OrderResponse
+	Tickets   []AttendeeResponse `json:"tickets"`
+	Insurance *OrderInsurance    `json:"insurance,omitempty"`
+	Calendar  *CalendarLinks     `json:"calendar,omitempty"` // Links adding the order's event to a calendar
+}
+
+// CalendarLinks are links adding an event to a calendar app
+type CalendarLinks struct {
+	ICS    string `json:"ics"`    // iCalendar file of the event, for Apple Calendar, Outlook and others
+	Google string `json:"google"` // Google Calendar page with the event filled in
+}
+
+// TicketResendResponse is the response structure for resending an order's ticket emails
+type TicketResendResponse struct {
+	OrderID       uuid.UUID `json:"order_id"`
+	TicketsResent int       `json:"tickets_resent"`
+	ResendsLeft   *int      `json:"resends_left,omitempty"` // Resends still allowed this hour; omitted when resends are not being counted
+}
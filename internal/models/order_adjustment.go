@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AdjustmentType identifies a manual correction an admin can make to an order
+type AdjustmentType string
+
+const (
+	AdjustmentPartialRefund   AdjustmentType = "partial_refund"
+	AdjustmentPriceCorrection AdjustmentType = "price_correction"
+	AdjustmentCompConversion  AdjustmentType = "comp_conversion" // Convert a paid order to complimentary, refunding what was paid
+)
+
+// AdjustmentStatus represents the approval and processing state of an adjustment
+type AdjustmentStatus string
+
+const (
+	AdjustmentStatusPendingApproval AdjustmentStatus = "pending_approval"
+	AdjustmentStatusApplied         AdjustmentStatus = "applied"
+	AdjustmentStatusRejected        AdjustmentStatus = "rejected"
+	AdjustmentStatusFailed          AdjustmentStatus = "failed"
+)
+
+// OrderAdjustment is a manual change to an order's amount requested by an admin. Adjustments
+// above the approval threshold are applied only after a second admin approves them.
+type OrderAdjustment struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID        uuid.UUID        `gorm:"type:uuid;not null;index" json:"order_id"`
+	OrganizationID *uuid.UUID       `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	Type           AdjustmentType   `gorm:"not null" json:"type"`
+	Amount         float64          `gorm:"not null" json:"amount"` // Money returned to the buyer or written off
+	NewTotal       *float64         `json:"new_total,omitempty"`    // Corrected order total for price corrections
+	Currency       string           `gorm:"size:3;not null" json:"currency"`
+	Reason         string           `gorm:"not null" json:"reason"`
+	Status         AdjustmentStatus `gorm:"not null;index" json:"status"`
+	RequestedBy    uuid.UUID        `gorm:"type:uuid;not null" json:"requested_by"`
+	ReviewedBy     *uuid.UUID       `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewNote     string           `json:"review_note,omitempty"`
+	ReviewedAt     *time.Time       `json:"reviewed_at,omitempty"`
+	RefundRefs     []string         `gorm:"serializer:json" json:"refund_refs,omitempty"` // Provider refund IDs
+	Error          string           `json:"error,omitempty"`
+	AppliedAt      *time.Time       `json:"applied_at,omitempty"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// OrderAdjustmentRequest is the request structure for adjusting an order.
+// Amount is required for partial refunds and NewTotal for price corrections.
+type OrderAdjustmentRequest struct {
+	Type     string   `json:"type" binding:"required,oneof=partial_refund price_correction comp_conversion" example:"partial_refund"`
+	Amount   float64  `json:"amount" binding:"omitempty,gt=0" example:"25.00"`
+	NewTotal *float64 `json:"new_total" binding:"omitempty,gte=0" example:"80.00"`
+	Reason   string   `json:"reason" binding:"required,max=500" example:"Seat downgrade agreed with the customer"`
+}
+
+// ReviewAdjustmentRequest is the request structure for approving or rejecting an adjustment
+type ReviewAdjustmentRequest struct {
+	Note string `json:"note" binding:"max=500" example:"Confirmed with the organizer"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *OrderAdjustment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import "github.com/google/uuid"
+
+// OrderQuoteItem is a ticket selection to price
+type OrderQuoteItem struct {
+	EventID  uint `json:"event_id" binding:"required" example:"1"`
+	Quantity int  `json:"quantity" binding:"required,min=1,max=50" example:"2"`
+}
+
+// OrderQuoteRequest is the request structure for pricing ticket selections before checkout
+type OrderQuoteRequest struct {
+	Items     []OrderQuoteItem `json:"items" binding:"required,min=1,max=20,dive"`
+	PromoCode string           `json:"promo_code" binding:"omitempty,max=50" example:"SUMMER10"`
+	Currency  string           `json:"currency" binding:"omitempty,len=3" example:"USD"` // Defaults to USD
+}
+
+// OrderQuoteLine is the price of one ticket selection
+type OrderQuoteLine struct {
+	EventID        uint       `json:"event_id"`
+	Title          string     `json:"title"`
+	Quantity       int        `json:"quantity"`
+	UnitPrice      float64    `json:"unit_price"`
+	Amount         float64    `json:"amount"`
+	PricingRuleID  *uuid.UUID `json:"pricing_rule_id,omitempty"` // Pricing rule that set the unit price, if any
+	DiscountAmount float64    `json:"discount_amount"`           // Part of the amount taken off by the promo code
+	PromoCodeID    *uuid.UUID `json:"promo_code_id,omitempty"`   // Promo code that discounted the line, if any
+}
+
+// OrderQuoteDiscount is a discount applied to a quote
+type OrderQuoteDiscount struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// OrderQuote is the full price breakdown of ticket selections. Nothing is reserved; prices are
+// recomputed at checkout.
+type OrderQuote struct {
+	Currency       string               `json:"currency"`
+	Lines          []OrderQuoteLine     `json:"lines"`
+	Subtotal       float64              `json:"subtotal"`
+	Discounts      []OrderQuoteDiscount `json:"discounts"`
+	DiscountAmount float64              `json:"discount_amount"`
+	FeeAmount      float64              `json:"fee_amount"`
+	TaxAmount      float64              `json:"tax_amount"`
+	Total          float64              `json:"total"`
+}
@@ -13,19 +13,29 @@ type CreateOrgUserRequest struct {
 // UpdateUserRoleRequest is the request structure for updating a user's role
 type UpdateUserRoleRequest struct {
 	UserID   string `json:"user_id" binding:"required,uuid4" example:"123e4567-e89b-12d3-a456-426614174000"`
-	RoleName string `json:"role_name" binding:"required,oneof=staff manager" example:"manager"` // Only allow staff or manager roles
+	RoleName string `json:"role_name" binding:"required,oneof=staff manager organizer" example:"manager"` // Role within the organization
 }
 
 // UpdateOrgUserRequest is used to update a user's role within an organization
 type UpdateOrgUserRequest struct {
-	RoleType string `json:"role_type" binding:"required,oneof=staff manager" example:"manager"` // Only allow staff or manager roles
+	RoleType string `json:"role_type" binding:"required,oneof=staff manager organizer" example:"manager"` // Role within the organization
 	Active   *bool  `json:"active" example:"true"`
 }
 
+// AddOrganizationMemberRequest is the request structure for adding an existing account to an organization
+type AddOrganizationMemberRequest struct {
+	Email    string `json:"email" binding:"required,email" example:"staff@example.com"`
+	RoleName string `json:"role_name" binding:"required,oneof=staff manager organizer" example:"staff"` // Role within the organization
+}
+
 // UpdateOrganizationRequest is used to update an organization
 type UpdateOrganizationRequest struct {
 	Name        string `json:"name" binding:"omitempty,min=3,max=100" example:"Updated Event Company"`
 	Description string `json:"description" binding:"omitempty,max=1000" example:"Updated description for the organization"`
 	WebsiteURL  string `json:"website_url" binding:"omitempty,url" example:"https://updated-events.com"`
 	LogoURL     string `json:"logo_url" binding:"omitempty,url" example:"https://updated-events.com/new-logo.png"`
+	// Parent organization; an empty string makes the organization top-level
+	ParentID *string `json:"parent_id" binding:"omitempty,uuid" example:"123e4567-e89b-12d3-a456-426614174000"`
+	// Whether organizers of ancestor organizations may manage this organization
+	InheritPermissions *bool `json:"inherit_permissions" example:"true"`
 }
@@ -16,10 +16,13 @@ type Organization struct {
 	WebsiteURL  string     `json:"website_url"`
 	OrganizerID uuid.UUID  `gorm:"type:uuid" json:"organizer_id"`
 	Organizer   *User      `gorm:"foreignKey:OrganizerID" json:"organizer,omitempty"`
-	Members     []*User    `gorm:"foreignKey:OrganizationID" json:"members,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
-	DeletedAt   *time.Time `gorm:"index" json:"-"`
+	ParentID    *uuid.UUID `gorm:"type:uuid;index" json:"parent_id,omitempty"` // Parent organization, e.g. the national promoter owning this regional one
+	// Lets the organizers of ancestor organizations manage this organization
+	InheritPermissions bool                  `gorm:"not null;default:false" json:"inherit_permissions"`
+	Members            []*OrganizationMember `gorm:"foreignKey:OrganizationID" json:"members,omitempty"`
+	CreatedAt          time.Time             `json:"created_at"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+	DeletedAt          *time.Time            `gorm:"index" json:"-"`
 }
 
 // CreateOrganizationRequest is the request structure for creating a new organization
@@ -32,14 +35,16 @@ type CreateOrganizationRequest struct {
 
 // OrganizationResponse is the response structure for organization data
 type OrganizationResponse struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	LogoURL     string    `json:"logo_url"`
-	WebsiteURL  string    `json:"website_url"`
-	OrganizerID uuid.UUID `json:"organizer_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	LogoURL            string     `json:"logo_url"`
+	WebsiteURL         string     `json:"website_url"`
+	OrganizerID        uuid.UUID  `json:"organizer_id"`
+	ParentID           *uuid.UUID `json:"parent_id,omitempty"`
+	InheritPermissions bool       `json:"inherit_permissions"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
 }
 
 // BeforeCreate is a GORM hook to set a UUID before creating a record
@@ -53,13 +58,15 @@ func (o *Organization) BeforeCreate(tx *gorm.DB) error {
 // ToResponse converts an Organization model to an OrganizationResponse
 func (o *Organization) ToResponse() OrganizationResponse {
 	return OrganizationResponse{
-		ID:          o.ID,
-		Name:        o.Name,
-		Description: o.Description,
-		LogoURL:     o.LogoURL,
-		WebsiteURL:  o.WebsiteURL,
-		OrganizerID: o.OrganizerID,
-		CreatedAt:   o.CreatedAt,
-		UpdatedAt:   o.UpdatedAt,
+		ID:                 o.ID,
+		Name:               o.Name,
+		Description:        o.Description,
+		LogoURL:            o.LogoURL,
+		WebsiteURL:         o.WebsiteURL,
+		OrganizerID:        o.OrganizerID,
+		ParentID:           o.ParentID,
+		InheritPermissions: o.InheritPermissions,
+		CreatedAt:          o.CreatedAt,
+		UpdatedAt:          o.UpdatedAt,
 	}
 }
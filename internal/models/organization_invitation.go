@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvitationStatus represents the state of an organization invitation
+type InvitationStatus string
+
+const (
+	InvitationStatusPending  InvitationStatus = "pending"
+	InvitationStatusAccepted InvitationStatus = "accepted" // The invitee set a password and joined the organization
+	InvitationStatusDeclined InvitationStatus = "declined"
+	InvitationStatusRevoked  InvitationStatus = "revoked" // Withdrawn by an organizer or replaced by a newer invitation
+)
+
+// OrganizationInvitation invites someone by email to join an organization with a staff or manager
+// role. The invitee chooses their own password when accepting it from the emailed link.
+type OrganizationInvitation struct {
+	ID             uuid.UUID        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID        `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Organization   *Organization    `gorm:"foreignKey:OrganizationID" json:"-"`
+	Email          string           `gorm:"size:255;not null;index" json:"email"`
+	FirstName      string           `gorm:"size:50" json:"first_name"`
+	LastName       string           `gorm:"size:50" json:"last_name"`
+	RoleName       string           `gorm:"size:50;not null" json:"role_name"`
+	TokenHash      string           `gorm:"size:64;not null;uniqueIndex" json:"-"` // SHA-256 of the invitation token, which is only emailed to the invitee
+	Status         InvitationStatus `gorm:"size:20;not null;default:'pending';index" json:"status"`
+	InvitedBy      uuid.UUID        `gorm:"type:uuid;not null" json:"invited_by"`
+	AcceptedUserID *uuid.UUID       `gorm:"type:uuid" json:"accepted_user_id,omitempty"`
+	AcceptedAt     *time.Time       `json:"accepted_at,omitempty"`
+	ExpiresAt      time.Time        `gorm:"not null" json:"expires_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// IsOpen reports whether the invitation can still be accepted
+func (i *OrganizationInvitation) IsOpen() bool {
+	return i.Status == InvitationStatusPending && time.Now().Before(i.ExpiresAt)
+}
+
+// CreateInvitationRequest is the request structure for inviting someone to an organization
+type CreateInvitationRequest struct {
+	Email     string `json:"email" binding:"required,email" example:"staff@example.com"`
+	FirstName string `json:"first_name" binding:"omitempty,min=2,max=50" example:"Jane"`
+	LastName  string `json:"last_name" binding:"omitempty,min=2,max=50" example:"Smith"`
+	RoleName  string `json:"role_name" binding:"required,oneof=staff manager" example:"staff"` // Only allow staff or manager roles
+}
+
+// AcceptInvitationRequest is the request structure for accepting an invitation. Names left empty
+// keep the ones the organizer entered.
+type AcceptInvitationRequest struct {
+	Password  string `json:"password" binding:"required,min=8" example:"StaffPass123!"`
+	FirstName string `json:"first_name" binding:"omitempty,min=2,max=50" example:"Jane"`
+	LastName  string `json:"last_name" binding:"omitempty,min=2,max=50" example:"Smith"`
+}
+
+// InvitationPreview is what the invitee sees before accepting an invitation
+type InvitationPreview struct {
+	OrganizationName string    `json:"organization_name"`
+	Email            string    `json:"email"`
+	FirstName        string    `json:"first_name"`
+	LastName         string    `json:"last_name"`
+	RoleName         string    `json:"role_name"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Roles a member can hold within an organization. They name the global roles whose permissions
+// the member gets in that organization only.
+const (
+	MemberRoleStaff     = "staff"
+	MemberRoleManager   = "manager"
+	MemberRoleOrganizer = "organizer" // Manages the organization like the organizer who created it
+)
+
+// OrganizationMember makes a user a member of an organization with a role that applies to that
+// organization only. Users can belong to any number of organizations; User.OrganizationID remains
+// the organization they joined first, for clients that only know one.
+type OrganizationMember struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_organization_member" json:"organization_id"`
+	Organization   *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	UserID         uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_organization_member;index" json:"user_id"`
+	User           *User         `gorm:"foreignKey:UserID" json:"-"`
+	RoleName       string        `gorm:"size:50;not null" json:"role_name"`
+	Active         bool          `gorm:"not null;default:true" json:"active"` // Inactive members keep their membership but get no permissions
+	CreatedBy      *uuid.UUID    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
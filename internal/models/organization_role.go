@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationRole is a custom role an organizer composes from permissions, e.g. "Finance" with
+// read:order and create:refund. It is assigned to members of the organization and grants its
+// permissions within that organization only.
+type OrganizationRole struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID     `gorm:"type:uuid;not null;uniqueIndex:idx_organization_role_name" json:"organization_id"`
+	Name           string        `gorm:"size:50;not null;uniqueIndex:idx_organization_role_name" json:"name"`
+	Description    string        `gorm:"size:255" json:"description"`
+	Permissions    []*Permission `gorm:"many2many:organization_role_permissions;" json:"-"`
+	Members        []*User       `gorm:"many2many:organization_role_members;" json:"-"`
+	CreatedBy      *uuid.UUID    `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// OrganizationRoleRequest is the request structure for creating or updating a custom organization
+// role. Permissions are permission names such as "read:order".
+type OrganizationRoleRequest struct {
+	Name        string   `json:"name" binding:"required,max=50" example:"Finance"`
+	Description string   `json:"description" binding:"omitempty,max=255" example:"Reviews orders and issues refunds"`
+	Permissions []string `json:"permissions" binding:"required,min=1,dive,required" example:"read:order,create:refund"`
+}
+
+// OrganizationRoleMemberRequest is the request structure for assigning a custom role to a member
+type OrganizationRoleMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// OrganizationRoleResponse is the response structure for a custom organization role
+type OrganizationRoleResponse struct {
+	ID             uuid.UUID            `json:"id"`
+	OrganizationID uuid.UUID            `json:"organization_id"`
+	Name           string               `json:"name"`
+	Description    string               `json:"description"`
+	Permissions    []PermissionResponse `json:"permissions"`
+	MemberIDs      []uuid.UUID          `json:"member_ids"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
+
+// ToResponse converts an OrganizationRole model to an OrganizationRoleResponse
+func (r *OrganizationRole) ToResponse() OrganizationRoleResponse {
+	permissions := make([]PermissionResponse, len(r.Permissions))
+	for i, permission := range r.Permissions {
+		permissions[i] = permission.ToResponse()
+	}
+	memberIDs := make([]uuid.UUID, len(r.Members))
+	for i, member := range r.Members {
+		memberIDs[i] = member.ID
+	}
+
+	return OrganizationRoleResponse{
+		ID:             r.ID,
+		OrganizationID: r.OrganizationID,
+		Name:           r.Name,
+		Description:    r.Description,
+		Permissions:    permissions,
+		MemberIDs:      memberIDs,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}
+}
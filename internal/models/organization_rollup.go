@@ -0,0 +1,46 @@
+package models
+
+import "github.com/google/uuid"
+
+// OrganizationRollupFilter is the query structure for an organization's roll-up analytics.
+// Orders are counted by the day they were paid; both dates are inclusive.
+type OrganizationRollupFilter struct {
+	From string `form:"from" binding:"omitempty,datetime=2006-01-02" example:"2025-01-01"`
+	To   string `form:"to" binding:"omitempty,datetime=2006-01-02" example:"2025-12-31"`
+}
+
+// OrganizationRollupRow is the sales of one organization of a hierarchy in one currency. An
+// organization without sales has a single row with an empty currency.
+type OrganizationRollupRow struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+	Depth          int        `json:"depth"` // 0 for the organization the roll-up is for
+	Currency       string     `json:"currency"`
+	Orders         int        `json:"orders"`  // Paid orders, not counting refunded ones
+	Tickets        int        `json:"tickets"` // Tickets of the paid orders
+	GrossAmount    float64    `json:"gross_amount"`
+	FeeAmount      float64    `json:"fee_amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	NetAmount      float64    `json:"net_amount"` // Gross less fees and refunds
+}
+
+// OrganizationRollupTotal is the combined sales of a hierarchy in one currency
+type OrganizationRollupTotal struct {
+	Currency       string  `json:"currency"`
+	Orders         int     `json:"orders"`
+	Tickets        int     `json:"tickets"`
+	GrossAmount    float64 `json:"gross_amount"`
+	FeeAmount      float64 `json:"fee_amount"`
+	RefundedAmount float64 `json:"refunded_amount"`
+	NetAmount      float64 `json:"net_amount"`
+}
+
+// OrganizationRollup is the sales of an organization and all its sub-organizations
+type OrganizationRollup struct {
+	OrganizationID uuid.UUID                 `json:"organization_id"`
+	From           string                    `json:"from,omitempty"`
+	To             string                    `json:"to,omitempty"`
+	Organizations  []OrganizationRollupRow   `json:"organizations"`
+	Totals         []OrganizationRollupTotal `json:"totals"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // OTPVerifyRequest is the request structure for verifying an OTP
 type OTPVerifyRequest struct {
 	Identifier string `json:"identifier" binding:"required" example:"user@example.com"` // Email, phone, or user ID
@@ -19,3 +21,17 @@ type OTPResponse struct {
 	Message   string `json:"message"`
 	ExpiresIn int    `json:"expires_in,omitempty"` // Time in seconds until OTP expires
 }
+
+// OTPMetricsBucket counts OTP send outcomes for one hour
+type OTPMetricsBucket struct {
+	Hour        time.Time `json:"hour"`
+	Issued      int64     `json:"issued"`       // Codes generated and emailed
+	Suppressed  int64     `json:"suppressed"`   // Requests answered without sending (quota, cooldown or ineligible identifier)
+	IPThrottled int64     `json:"ip_throttled"` // Requests rejected by the per-IP quota
+}
+
+// OTPMetricsResponse is the response structure for OTP issuance metrics
+type OTPMetricsResponse struct {
+	AlertHourlyThreshold int                `json:"alert_hourly_threshold"`
+	Buckets              []OTPMetricsBucket `json:"buckets"`
+}
@@ -0,0 +1,65 @@
+package models
+
+// Page sizes of paginated lists
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// PageQuery holds the page/limit query parameters of a paginated list. Embed it in a list's
+// query struct; pages are numbered from 1.
+type PageQuery struct {
+	Page  int `form:"page" binding:"omitempty,min=1" example:"1"`
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100" example:"20"`
+}
+
+// PageNumber returns the requested page, the first one by default
+func (q PageQuery) PageNumber() int {
+	if q.Page < 1 {
+		return 1
+	}
+	return q.Page
+}
+
+// PageSize returns the requested number of items per page, DefaultPageLimit by default
+func (q PageQuery) PageSize() int {
+	switch {
+	case q.Limit < 1:
+		return DefaultPageLimit
+	case q.Limit > MaxPageLimit:
+		return MaxPageLimit
+	}
+	return q.Limit
+}
+
+// Offset returns the number of items before the requested page
+func (q PageQuery) Offset() int {
+	return (q.PageNumber() - 1) * q.PageSize()
+}
+
+// PageMeta describes where a page sits in its list, returned in the meta of the response envelope
+type PageMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`       // Items matching the filters across all pages
+	TotalPages int   `json:"total_pages"` // 0 when nothing matches
+	HasMore    bool  `json:"has_more"`
+	NextPage   *int  `json:"next_page,omitempty"` // Absent on the last page
+}
+
+// NewPageMeta describes the requested page of a list of total items
+func NewPageMeta(q PageQuery, total int64) *PageMeta {
+	limit := q.PageSize()
+	meta := &PageMeta{
+		Page:       q.PageNumber(),
+		Limit:      limit,
+		Total:      total,
+		TotalPages: int((total + int64(limit) - 1) / int64(limit)),
+	}
+	if meta.Page < meta.TotalPages {
+		next := meta.Page + 1
+		meta.HasMore = true
+		meta.NextPage = &next
+	}
+	return meta
+}
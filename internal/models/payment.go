@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PaymentStatus represents the outcome of a charge attempt
+type PaymentStatus string
+
+const (
+	PaymentStatusSucceeded PaymentStatus = "succeeded"
+	PaymentStatusFailed    PaymentStatus = "failed"
+	PaymentStatusRefunded  PaymentStatus = "refunded" // Refund of the parent payment
+)
+
+// Payment records a single charge attempt made through the payment provider
+type Payment struct {
+	ID             uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID        uuid.UUID     `gorm:"type:uuid;not null;index" json:"order_id"`
+	OrganizationID *uuid.UUID    `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	InstallmentID  *uuid.UUID    `gorm:"type:uuid;index" json:"installment_id,omitempty"`
+	RefundOfID     *uuid.UUID    `gorm:"type:uuid;index" json:"refund_of_id,omitempty"` // Charge a refund payment returns funds from
+	Provider       string        `gorm:"not null" json:"provider"`
+	ProviderRef    string        `gorm:"index" json:"provider_ref"` // Provider's charge / payment intent ID
+	Amount         float64       `gorm:"not null" json:"amount"`
+	Currency       string        `gorm:"size:3;not null" json:"currency"`
+	Status         PaymentStatus `gorm:"not null" json:"status"`
+	FailureReason  string        `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time     `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (p *Payment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
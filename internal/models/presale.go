@@ -0,0 +1,155 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Presale is a window in which an event's tickets are only sold to buyers entering one of its
+// access codes or whose email is on its allowlist. Outside its presale windows an event is on
+// general sale.
+type Presale struct {
+	ID             uuid.UUID           `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID           `gorm:"type:uuid;not null;index" json:"organization_id"`
+	EventID        uint                `gorm:"not null;index" json:"event_id"`
+	Name           string              `gorm:"size:100;not null" json:"name"`
+	StartsAt       time.Time           `gorm:"not null" json:"starts_at"`
+	EndsAt         time.Time           `gorm:"not null" json:"ends_at"` // General sale opens, unless another presale window follows
+	Active         bool                `gorm:"not null" json:"active"`
+	CreatedBy      *uuid.UUID          `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at"`
+	AccessCodes    []PresaleAccessCode `gorm:"foreignKey:PresaleID" json:"access_codes,omitempty"`
+	AllowlistCount int64               `gorm:"-" json:"allowlist_count"`
+}
+
+// PresaleAccessCode admits buyers to a presale. Each order placed with a code uses it once.
+type PresaleAccessCode struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PresaleID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_presale_access_code" json:"presale_id"`
+	Code      string    `gorm:"size:50;not null;uniqueIndex:idx_presale_access_code" json:"code"` // Stored upper case
+	MaxUses   int       `gorm:"not null;default:0" json:"max_uses"`                               // Orders the code can be used on in total; 0 for unlimited
+	UsedCount int       `gorm:"not null;default:0" json:"used_count"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PresaleAllowlistEntry admits a buyer email to a presale without an access code
+type PresaleAllowlistEntry struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PresaleID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_presale_allowlist_email" json:"-"`
+	Email     string    `gorm:"size:255;not null;uniqueIndex:idx_presale_allowlist_email" json:"email"` // Stored lower case
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PresaleRequest is the request structure for creating or updating a presale
+type PresaleRequest struct {
+	EventID  uint      `json:"event_id" binding:"required" example:"1"`
+	Name     string    `json:"name" binding:"required,max=100" example:"Fan club presale"`
+	StartsAt time.Time `json:"starts_at" binding:"required" example:"2025-06-01T10:00:00Z"`
+	EndsAt   time.Time `json:"ends_at" binding:"required" example:"2025-06-03T10:00:00Z"`
+	Active   *bool     `json:"active" example:"true"` // Defaults to true
+}
+
+// PresaleAccessCodeRequest is the request structure for adding an access code to a presale
+type PresaleAccessCodeRequest struct {
+	Code    string `json:"code" binding:"required,min=3,max=50,alphanum" example:"FANCLUB25"`
+	MaxUses int    `json:"max_uses" binding:"min=0" example:"500"`
+}
+
+// PresaleAllowlistRequest is the request structure for adding emails to, or removing them from,
+// a presale's allowlist
+type PresaleAllowlistRequest struct {
+	Emails []string `json:"emails" binding:"required,min=1,max=1000,dive,email" example:"jane@example.com"`
+}
+
+// PresaleAllowlistResponse reports how an allowlist changed
+type PresaleAllowlistResponse struct {
+	Changed        int   `json:"changed"` // Emails added or removed; emails already in the requested state are skipped
+	AllowlistCount int64 `json:"allowlist_count"`
+}
+
+// PresaleQuery filters the presales of an organization
+type PresaleQuery struct {
+	EventID uint `form:"event_id" example:"1"`
+}
+
+// PresaleAllowlistQuery pages through a presale's allowlist
+type PresaleAllowlistQuery struct {
+	PageQuery
+	Search string `form:"q" binding:"omitempty,max=100" example:"example.com"`
+}
+
+// PresaleAnalyticsQuery selects the event presale analytics are reported for
+type PresaleAnalyticsQuery struct {
+	EventID uint `form:"event_id" binding:"required" example:"1"`
+}
+
+// SaleChannelStats are the checkouts and orders of an event in one sales phase. Checkouts count
+// checkout sessions selecting the event; orders exclude cancelled ones.
+type SaleChannelStats struct {
+	CheckoutsStarted   int64   `json:"checkouts_started"`
+	CheckoutsCompleted int64   `json:"checkouts_completed"`
+	ConversionRate     float64 `json:"conversion_rate"` // Completed checkouts per started checkout, from 0 to 1
+	Orders             int64   `json:"orders"`
+	Tickets            int64   `json:"tickets"`
+	Revenue            float64 `json:"revenue"` // Order totals less refunds
+}
+
+// PresaleAnalytics compares an event's presale with its general sale
+type PresaleAnalytics struct {
+	EventID     uint                `json:"event_id"`
+	Presale     SaleChannelStats    `json:"presale"`
+	GeneralSale SaleChannelStats    `json:"general_sale"`
+	AccessCodes []PresaleCodeUsage  `json:"access_codes"`
+	Presales    []PresaleWindowStat `json:"presales"`
+}
+
+// PresaleCodeUsage is the usage of one access code of an event's presales
+type PresaleCodeUsage struct {
+	PresaleID uuid.UUID `json:"presale_id"`
+	Code      string    `json:"code"`
+	MaxUses   int       `json:"max_uses"`
+	UsedCount int       `json:"used_count"`
+}
+
+// PresaleWindowStat is the orders placed in one presale window, by how buyers were admitted
+type PresaleWindowStat struct {
+	PresaleID       uuid.UUID `json:"presale_id"`
+	Name            string    `json:"name"`
+	Orders          int64     `json:"orders"`
+	CodeOrders      int64     `json:"code_orders"`      // Admitted with an access code
+	AllowlistOrders int64     `json:"allowlist_orders"` // Admitted by their email
+	Tickets         int64     `json:"tickets"`
+	Revenue         float64   `json:"revenue"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (p *Presale) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (c *PresaleAccessCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (e *PresaleAllowlistEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// OpenAt reports whether the presale admits buyers at a time
+func (p *Presale) OpenAt(now time.Time) bool {
+	return p.Active && !now.Before(p.StartsAt) && now.Before(p.EndsAt)
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PricingTrigger identifies the condition that activates a pricing rule
+type PricingTrigger string
+
+const (
+	PricingTriggerTicketsSold PricingTrigger = "tickets_sold" // Active once SoldThreshold tickets have been sold
+	PricingTriggerDate        PricingTrigger = "date"         // Active from StartsAt
+)
+
+// PricingRule is an organizer-defined price change for an event. When several rules
+// are active the highest price applies, so rules model escalating price tiers.
+type PricingRule struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID      `gorm:"type:uuid;not null;index" json:"organization_id"`
+	EventID        uint           `gorm:"not null;index" json:"event_id"`
+	Name           string         `gorm:"size:100" json:"name"`
+	Trigger        PricingTrigger `gorm:"not null" json:"trigger"`
+	SoldThreshold  int            `json:"sold_threshold,omitempty"`
+	StartsAt       *time.Time     `json:"starts_at,omitempty"`
+	Price          float64        `gorm:"not null" json:"price"`
+	CreatedBy      *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// PricingRuleRequest is the request structure for creating a pricing rule.
+// tickets_sold rules require sold_threshold and date rules require starts_at.
+type PricingRuleRequest struct {
+	EventID       uint       `json:"event_id" binding:"required" example:"1"`
+	Name          string     `json:"name" binding:"max=100" example:"Regular"`
+	Trigger       string     `json:"trigger" binding:"required,oneof=tickets_sold date" example:"tickets_sold"`
+	SoldThreshold int        `json:"sold_threshold" binding:"omitempty,min=1" example:"100"`
+	StartsAt      *time.Time `json:"starts_at" example:"2025-06-01T00:00:00Z"`
+	Price         float64    `json:"price" binding:"min=0" example:"45.00"`
+}
+
+// PricingRuleResponse is the response structure for a pricing rule
+type PricingRuleResponse struct {
+	ID            uuid.UUID      `json:"id"`
+	EventID       uint           `json:"event_id"`
+	Name          string         `json:"name"`
+	Trigger       PricingTrigger `json:"trigger"`
+	SoldThreshold int            `json:"sold_threshold,omitempty"`
+	StartsAt      *time.Time     `json:"starts_at,omitempty"`
+	Price         float64        `json:"price"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// PriceChange is an upcoming price change in a pricing preview
+type PriceChange struct {
+	RuleID           uuid.UUID      `json:"rule_id"`
+	Name             string         `json:"name"`
+	Trigger          PricingTrigger `json:"trigger"`
+	Price            float64        `json:"price"`
+	StartsAt         *time.Time     `json:"starts_at,omitempty"`
+	TicketsRemaining *int           `json:"tickets_remaining,omitempty"` // Tickets left to sell before a tickets_sold rule applies
+}
+
+// PricingPreview shows the price that applies to an event right now and the scheduled changes
+type PricingPreview struct {
+	EventID      uint          `json:"event_id"`
+	BasePrice    float64       `json:"base_price"`
+	CurrentPrice float64       `json:"current_price"`
+	ActiveRuleID *uuid.UUID    `json:"active_rule_id,omitempty"`
+	TicketsSold  int           `json:"tickets_sold"`
+	Upcoming     []PriceChange `json:"upcoming"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *PricingRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive reports whether the rule applies given the tickets sold and the current time
+func (r *PricingRule) IsActive(ticketsSold int, now time.Time) bool {
+	switch r.Trigger {
+	case PricingTriggerTicketsSold:
+		return ticketsSold >= r.SoldThreshold
+	case PricingTriggerDate:
+		return r.StartsAt != nil && !now.Before(*r.StartsAt)
+	}
+	return false
+}
+
+// ToResponse converts a PricingRule model to a PricingRuleResponse
+func (r *PricingRule) ToResponse() PricingRuleResponse {
+	return PricingRuleResponse{
+		ID:            r.ID,
+		EventID:       r.EventID,
+		Name:          r.Name,
+		Trigger:       r.Trigger,
+		SoldThreshold: r.SoldThreshold,
+		StartsAt:      r.StartsAt,
+		Price:         r.Price,
+		CreatedAt:     r.CreatedAt,
+	}
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DiscountType identifies how a promo code reduces the price of tickets
+type DiscountType string
+
+const (
+	DiscountTypePercentage DiscountType = "percentage" // Value percent off each eligible ticket
+	DiscountTypeFixed      DiscountType = "fixed"      // Value off the eligible tickets of an order, at most their price
+)
+
+// PromoCode is an organizer-defined discount buyers enter at checkout. Codes apply to the
+// organization's events, or only to EventIDs when set. Codes are unique within an organization
+// and matched case-insensitively.
+type PromoCode struct {
+	ID              uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID  uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_promo_code_organization_code" json:"organization_id"`
+	Code            string       `gorm:"size:50;not null;uniqueIndex:idx_promo_code_organization_code" json:"code"` // Stored upper case
+	Description     string       `gorm:"size:200" json:"description"`
+	DiscountType    DiscountType `gorm:"size:20;not null" json:"discount_type"`
+	Value           float64      `gorm:"not null" json:"value"`
+	EventIDs        []uint       `gorm:"serializer:json" json:"event_ids,omitempty"` // Events the code applies to; empty for every event of the organization
+	MaxUses         int          `gorm:"not null;default:0" json:"max_uses"`         // Orders the code can be used on in total; 0 for unlimited
+	MaxUsesPerBuyer int          `gorm:"not null;default:0" json:"max_uses_per_buyer"`
+	UsedCount       int          `gorm:"not null;default:0" json:"used_count"`
+	StartsAt        *time.Time   `json:"starts_at,omitempty"`
+	EndsAt          *time.Time   `json:"ends_at,omitempty"`
+	Active          bool         `gorm:"not null" json:"active"`
+	CreatedBy       *uuid.UUID   `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// PromoCodeRedemption records the use of a promo code on an order. Redemptions of orders that
+// are cancelled before being paid are removed, giving the use back.
+type PromoCodeRedemption struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	PromoCodeID uuid.UUID `gorm:"type:uuid;not null;index" json:"promo_code_id"`
+	OrderID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	BuyerEmail  string    `gorm:"size:255;not null;index" json:"buyer_email"`
+	Amount      float64   `gorm:"not null" json:"amount"` // Discount given on the order
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PromoCodeRequest is the request structure for creating or updating a promo code. Percentage
+// values are at most 100.
+type PromoCodeRequest struct {
+	Code            string     `json:"code" binding:"required,min=3,max=50,alphanum" example:"SUMMER10"`
+	Description     string     `json:"description" binding:"max=200" example:"Summer sale"`
+	DiscountType    string     `json:"discount_type" binding:"required,oneof=percentage fixed" example:"percentage"`
+	Value           float64    `json:"value" binding:"required,gt=0" example:"10"`
+	EventIDs        []uint     `json:"event_ids" binding:"omitempty,max=100" example:"1,2"`
+	MaxUses         int        `json:"max_uses" binding:"min=0" example:"100"`
+	MaxUsesPerBuyer int        `json:"max_uses_per_buyer" binding:"min=0" example:"1"`
+	StartsAt        *time.Time `json:"starts_at" example:"2025-06-01T00:00:00Z"`
+	EndsAt          *time.Time `json:"ends_at" example:"2025-06-30T23:59:59Z"`
+	Active          *bool      `json:"active" example:"true"` // Defaults to true
+}
+
+// PromoCodeResponse is the response structure for a promo code
+type PromoCodeResponse struct {
+	ID              uuid.UUID    `json:"id"`
+	Code            string       `json:"code"`
+	Description     string       `json:"description"`
+	DiscountType    DiscountType `json:"discount_type"`
+	Value           float64      `json:"value"`
+	EventIDs        []uint       `json:"event_ids"`
+	MaxUses         int          `json:"max_uses"`
+	MaxUsesPerBuyer int          `json:"max_uses_per_buyer"`
+	UsedCount       int          `json:"used_count"`
+	StartsAt        *time.Time   `json:"starts_at,omitempty"`
+	EndsAt          *time.Time   `json:"ends_at,omitempty"`
+	Active          bool         `json:"active"`
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (p *PromoCode) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *PromoCodeRedemption) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// AppliesTo reports whether the code covers an event. Codes without events cover every event of
+// their organization, which the caller checks.
+func (p *PromoCode) AppliesTo(eventID uint) bool {
+	if len(p.EventIDs) == 0 {
+		return true
+	}
+	for _, id := range p.EventIDs {
+		if id == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// ToResponse converts a PromoCode model to a PromoCodeResponse
+func (p *PromoCode) ToResponse() PromoCodeResponse {
+	eventIDs := p.EventIDs
+	if eventIDs == nil {
+		eventIDs = []uint{}
+	}
+	return PromoCodeResponse{
+		ID:              p.ID,
+		Code:            p.Code,
+		Description:     p.Description,
+		DiscountType:    p.DiscountType,
+		Value:           p.Value,
+		EventIDs:        eventIDs,
+		MaxUses:         p.MaxUses,
+		MaxUsesPerBuyer: p.MaxUsesPerBuyer,
+		UsedCount:       p.UsedCount,
+		StartsAt:        p.StartsAt,
+		EndsAt:          p.EndsAt,
+		Active:          p.Active,
+		CreatedAt:       p.CreatedAt,
+		UpdatedAt:       p.UpdatedAt,
+	}
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PublicStats are platform-wide totals shown on the marketing site. They are aggregates across
+// all organizations and never break down by organization.
+type PublicStats struct {
+	EventsHosted        int64     `json:"events_hosted"`        // Events that were not cancelled
+	TicketsIssued       int64     `json:"tickets_issued"`       // Tickets that were not cancelled
+	OrganizersOnboarded int64     `json:"organizers_onboarded"` // Organizations on the platform
+	UpdatedAt           time.Time `json:"updated_at"`           // When the totals were counted
+}
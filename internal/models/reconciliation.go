@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReconciliationStatus represents the outcome of a reconciliation run
+type ReconciliationStatus string
+
+const (
+	ReconciliationStatusRunning       ReconciliationStatus = "running"
+	ReconciliationStatusMatched       ReconciliationStatus = "matched"
+	ReconciliationStatusDiscrepancies ReconciliationStatus = "discrepancies"
+	ReconciliationStatusFailed        ReconciliationStatus = "failed"
+)
+
+// DiscrepancyType classifies a mismatch between the provider and internal payments
+type DiscrepancyType string
+
+const (
+	DiscrepancyMissingInternal  DiscrepancyType = "missing_internal"  // Provider charge with no internal payment
+	DiscrepancyMissingProvider  DiscrepancyType = "missing_provider"  // Internal payment the provider has no record of
+	DiscrepancyAmountMismatch   DiscrepancyType = "amount_mismatch"   // Matched, but the amounts differ
+	DiscrepancyCurrencyMismatch DiscrepancyType = "currency_mismatch" // Matched, but the currencies differ
+)
+
+// ReconciliationReport is the result of matching one period of provider balance transactions
+// against internal payments
+type ReconciliationReport struct {
+	ID               uuid.UUID                   `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Provider         string                      `gorm:"not null" json:"provider"`
+	PeriodStart      time.Time                   `gorm:"not null;index" json:"period_start"`
+	PeriodEnd        time.Time                   `gorm:"not null" json:"period_end"`
+	Status           ReconciliationStatus        `gorm:"not null;default:'running'" json:"status"`
+	ProviderCount    int                         `json:"provider_count"`
+	InternalCount    int                         `json:"internal_count"`
+	MatchedCount     int                         `json:"matched_count"`
+	DiscrepancyCount int                         `json:"discrepancy_count"`
+	ProviderTotal    float64                     `json:"provider_total"`
+	InternalTotal    float64                     `json:"internal_total"`
+	FeeTotal         float64                     `json:"fee_total"`
+	Error            string                      `json:"error,omitempty"`
+	Discrepancies    []ReconciliationDiscrepancy `gorm:"foreignKey:ReportID" json:"discrepancies,omitempty"`
+	CompletedAt      *time.Time                  `json:"completed_at,omitempty"`
+	CreatedAt        time.Time                   `json:"created_at"`
+	UpdatedAt        time.Time                   `json:"updated_at"`
+}
+
+// ReconciliationDiscrepancy is a single mismatch found by a reconciliation run
+type ReconciliationDiscrepancy struct {
+	ID             uuid.UUID       `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	ReportID       uuid.UUID       `gorm:"type:uuid;not null;index" json:"report_id"`
+	Type           DiscrepancyType `gorm:"not null" json:"type"`
+	PaymentID      *uuid.UUID      `gorm:"type:uuid" json:"payment_id,omitempty"`
+	OrderID        *uuid.UUID      `gorm:"type:uuid" json:"order_id,omitempty"`
+	ProviderRef    string          `json:"provider_ref,omitempty"`
+	InternalAmount float64         `json:"internal_amount"`
+	ProviderAmount float64         `json:"provider_amount"`
+	Currency       string          `gorm:"size:3" json:"currency"`
+	Detail         string          `json:"detail"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// RunReconciliationRequest is the request structure for reconciling a specific period on demand
+type RunReconciliationRequest struct {
+	PeriodStart time.Time `json:"period_start" binding:"required" example:"2025-03-01T00:00:00Z"`
+	PeriodEnd   time.Time `json:"period_end" binding:"required,gtfield=PeriodStart" example:"2025-03-02T00:00:00Z"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *ReconciliationReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Status == "" {
+		r.Status = ReconciliationStatusRunning
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (d *ReconciliationDiscrepancy) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RefundStatus represents the approval and processing state of a refund
+type RefundStatus string
+
+const (
+	RefundStatusRequested RefundStatus = "requested"
+	RefundStatusApproved  RefundStatus = "approved" // Approved and being processed
+	RefundStatusProcessed RefundStatus = "processed"
+	RefundStatusRejected  RefundStatus = "rejected"
+	RefundStatusFailed    RefundStatus = "failed" // Processing failed; can be retried
+)
+
+// Refund returns money of an order to its buyer, requested by the buyer or the organization's
+// staff and processed once the organization approves it. Refunds of tickets cancel them; refunds
+// without tickets return part of the money and leave the tickets valid.
+type Refund struct {
+	ID             uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID        uuid.UUID    `gorm:"type:uuid;not null;index" json:"order_id"`
+	OrganizationID *uuid.UUID   `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	EventID        uint         `gorm:"not null;index" json:"event_id"`
+	Amount         float64      `gorm:"not null" json:"amount"`                   // Ticket money returned to the buyer
+	PremiumAmount  float64      `gorm:"not null;default:0" json:"premium_amount"` // Insurance premium the insurer returned when the refund closed the order
+	ManualAmount   float64      `gorm:"not null;default:0" json:"manual_amount"`  // Part not returned through the payment provider, to be settled offline
+	Currency       string       `gorm:"size:3;not null" json:"currency"`
+	Reason         string       `gorm:"not null" json:"reason"`
+	Status         RefundStatus `gorm:"not null;index" json:"status"`
+	Items          []RefundItem `gorm:"foreignKey:RefundID" json:"items,omitempty"`
+	RequestedBy    uuid.UUID    `gorm:"type:uuid;not null" json:"requested_by"`
+	ReviewedBy     *uuid.UUID   `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewNote     string       `json:"review_note,omitempty"`
+	ReviewedAt     *time.Time   `json:"reviewed_at,omitempty"`
+	RefundRefs     []string     `gorm:"serializer:json" json:"refund_refs,omitempty"` // Provider refund IDs
+	Attempts       int          `gorm:"not null;default:0" json:"attempts"`
+	Error          string       `json:"error,omitempty"`
+	ProcessedAt    *time.Time   `json:"processed_at,omitempty"`
+	CreatedAt      time.Time    `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// RefundItem is a ticket of the order cancelled by a refund and its share of the refunded amount
+type RefundItem struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RefundID uuid.UUID `gorm:"type:uuid;not null;index" json:"refund_id"`
+	TicketID uuid.UUID `gorm:"type:uuid;not null;index" json:"ticket_id"`
+	Amount   float64   `gorm:"not null" json:"amount"`
+}
+
+// CreateRefundRequest is the request structure for requesting a refund. Without tickets or an
+// amount the whole order is refunded. TicketIDs refunds and cancels those tickets; Amount alone
+// returns part of the money and keeps the tickets valid.
+type CreateRefundRequest struct {
+	TicketIDs []uuid.UUID `json:"ticket_ids" binding:"omitempty,max=50"`
+	Amount    *float64    `json:"amount" binding:"omitempty,gt=0" example:"25.00"` // Staff only; caps the refund of the selected tickets
+	Reason    string      `json:"reason" binding:"required,max=500" example:"Can no longer attend"`
+}
+
+// ReviewRefundRequest is the request structure for approving or rejecting a refund
+type ReviewRefundRequest struct {
+	Note string `json:"note" binding:"max=500" example:"Within the refund policy"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (i *RefundItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScanAttempt records one scan of a code at the door, or a manual check-in, whatever its result.
+// Rejected scans such as duplicates, tickets of another event and cancelled tickets are kept
+// alongside admissions, so the door stays auditable and patterns of ticket fraud can be spotted.
+type ScanAttempt struct {
+	ID              uuid.UUID        `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID  *uuid.UUID       `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	EventID         uint             `gorm:"not null;index:idx_scan_attempt_event_time" json:"event_id"` // Event the code was scanned for
+	TicketID        *uuid.UUID       `gorm:"type:uuid;index" json:"ticket_id,omitempty"`                 // Nil when the code is not a ticket
+	Result          TicketScanResult `gorm:"size:30;not null" json:"result"`
+	Gate            string           `gorm:"size:50" json:"gate,omitempty"`
+	DeviceID        string           `gorm:"size:100" json:"device_id,omitempty"`          // X-Device-ID header of the staff device that scanned
+	ScannerDeviceID *uuid.UUID       `gorm:"type:uuid" json:"scanner_device_id,omitempty"` // Paired scanner that scanned
+	ScannedBy       *uuid.UUID       `gorm:"type:uuid" json:"scanned_by,omitempty"`        // Staff member, or the organizer a paired scanner acts for
+	ScannedAt       time.Time        `gorm:"not null;index:idx_scan_attempt_event_time" json:"scanned_at"`
+}
+
+// ScanDevice identifies what scanned a code: a paired scanner, or a staff device by the
+// X-Device-ID header it sends
+type ScanDevice struct {
+	ScannerDeviceID *uuid.UUID
+	DeviceID        string
+}
+
+// ScanAttemptQuery filters the scan audit trail of an organization
+type ScanAttemptQuery struct {
+	PageQuery
+	EventID  uint   `form:"event_id" binding:"omitempty,min=1" example:"1"`
+	TicketID string `form:"ticket_id" binding:"omitempty,uuid"`
+	Result   string `form:"result" binding:"omitempty,oneof=admitted already_checked_in duplicate cancelled revoked wrong_event not_found invalid_code" example:"duplicate"`
+	Gate     string `form:"gate" binding:"omitempty,max=50" example:"north"`
+}
+
+// ScanAnomalyKind is a pattern of scans suggesting a ticket is being shared or forged
+type ScanAnomalyKind string
+
+const (
+	ScanAnomalyMultipleGates      ScanAnomalyKind = "multiple_gates"      // Ticket scanned at two gates within the window
+	ScanAnomalyRepeatedRejections ScanAnomalyKind = "repeated_rejections" // Ticket rejected as already used again and again, e.g. a shared screenshot
+	ScanAnomalyCancelledTicket    ScanAnomalyKind = "cancelled_ticket"    // Cancelled, refunded or revoked ticket presented at the door
+)
+
+// ScanAnomalyQuery selects the event to look for suspicious scans at, and how sensitive to be
+type ScanAnomalyQuery struct {
+	EventID     uint `form:"event_id" binding:"required,min=1" example:"1"`
+	Window      int  `form:"window" binding:"omitempty,min=1,max=3600" example:"60"`     // Seconds between scans at different gates, default 60
+	MinAttempts int  `form:"min_attempts" binding:"omitempty,min=2,max=100" example:"3"` // Rejections of a used ticket to report, default 3
+}
+
+// ScanAnomaly is a ticket whose scans match a suspicious pattern
+type ScanAnomaly struct {
+	Kind         ScanAnomalyKind `json:"kind"`
+	TicketID     uuid.UUID       `json:"ticket_id"`
+	AttendeeName string          `json:"attendee_name,omitempty"`
+	Scans        int64           `json:"scans"` // All scans of the ticket at the event
+	Gates        []string        `json:"gates,omitempty"`
+	Devices      []string        `json:"devices,omitempty"`     // Staff device IDs and paired scanner IDs involved
+	GapSeconds   *float64        `json:"gap_seconds,omitempty"` // Shortest time between scans at different gates
+	FirstScanAt  time.Time       `json:"first_scan_at"`
+	LastScanAt   time.Time       `json:"last_scan_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *ScanAttempt) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
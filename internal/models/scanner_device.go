@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScannerDevice is a door scanner paired to one event. The organizer generates a short-lived
+// pairing code, which the device exchanges for a device token that only works on the check-in
+// endpoints of that event. Devices are tracked per organization so a lost one can be revoked.
+type ScannerDevice struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EventID          uint       `gorm:"not null;index" json:"event_id"`
+	OrganizationID   *uuid.UUID `gorm:"type:uuid;index" json:"organization_id,omitempty"` // Organization of the organizer who paired it
+	Name             string     `gorm:"size:100" json:"name,omitempty"`
+	AppVersion       string     `gorm:"size:50" json:"app_version,omitempty"` // Scanner app version last reported by the device
+	PairedBy         uuid.UUID  `gorm:"type:uuid;not null" json:"paired_by"`  // Organizer who generated the pairing code; the device checks attendees in on their behalf
+	PairingCodeHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	PairingExpiresAt time.Time  `gorm:"not null" json:"pairing_expires_at"`
+	PairedAt         *time.Time `json:"paired_at,omitempty"` // When the device exchanged the code; nil while unpaired
+	TokenHash        *string    `gorm:"size:64;uniqueIndex" json:"-"`
+	TokenExpiresAt   *time.Time `json:"token_expires_at,omitempty"`
+	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+// ScannerPairingRequest is the request structure for generating a scanner pairing code
+type ScannerPairingRequest struct {
+	Name string `json:"name" binding:"omitempty,max=100" example:"North gate 1"` // Label to tell paired devices apart
+}
+
+// ScannerPairingResponse is a pairing code for a scanner device to exchange for its token
+type ScannerPairingResponse struct {
+	DeviceID  uuid.UUID `json:"device_id"`
+	Code      string    `json:"code" example:"K7QX-3MZP"` // For typing into the device
+	QRCode    string    `json:"qr_code"`                  // Payload to show as a QR code for the device to scan
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ScannerPairRequest is the request structure for a scanner device exchanging a pairing code
+type ScannerPairRequest struct {
+	Code       string `json:"code" binding:"required,max=64" example:"K7QX-3MZP"` // Code as typed or the scanned QR payload
+	AppVersion string `json:"app_version" binding:"omitempty,max=50" example:"2.4.1"`
+}
+
+// ScannerDeviceUpdateRequest is the request structure for renaming a scanner device
+type ScannerDeviceUpdateRequest struct {
+	Name string `json:"name" binding:"required,max=100" example:"North gate 1"`
+}
+
+// ScannerTokenResponse is the device token of a paired scanner
+type ScannerTokenResponse struct {
+	DeviceID  uuid.UUID `json:"device_id"`
+	EventID   uint      `json:"event_id"`
+	Token     string    `json:"token"` // Bearer token for the event's check-in endpoints
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (d *ScannerDevice) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SchemaMigration records a completed migration run. The latest row describes the schema the
+// database is on.
+type SchemaMigration struct {
+	ID                   uint      `gorm:"primaryKey" json:"id"`
+	Version              int       `gorm:"not null" json:"version"`                // Schema version the run migrated to
+	MinCompatibleVersion int       `gorm:"not null" json:"min_compatible_version"` // Oldest application schema version that can run on it
+	AppVersion           string    `json:"app_version"`
+	CreatedAt            time.Time `json:"created_at"`
+}
@@ -0,0 +1,189 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SeatStatus represents whether a seat of an event can still be sold
+type SeatStatus string
+
+const (
+	SeatStatusAvailable SeatStatus = "available"
+	SeatStatusHeld      SeatStatus = "held" // Selected in a checkout; available again once the hold expires
+	SeatStatusSold      SeatStatus = "sold"
+)
+
+// Venue is a place with a reserved seating layout, organized in sections of rows of seats. A
+// venue's seat map can be attached to any number of the organization's events.
+type Venue struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:uuid;not null;index" json:"organization_id"`
+	Name           string     `gorm:"size:200;not null" json:"name"`
+	Address        string     `gorm:"size:300" json:"address,omitempty"`
+	Sections       []*Section `gorm:"foreignKey:VenueID" json:"sections,omitempty"`
+	CreatedBy      *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Section is an area of a venue, e.g. stalls or balcony
+type Section struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	VenueID  uuid.UUID `gorm:"type:uuid;not null;index" json:"venue_id"`
+	Name     string    `gorm:"size:100;not null" json:"name"`
+	Position int       `gorm:"not null" json:"position"` // Order of the section on the seat map
+	Rows     []*Row    `gorm:"foreignKey:SectionID" json:"rows,omitempty"`
+}
+
+// Row is a row of seats in a section
+type Row struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	SectionID uuid.UUID `gorm:"type:uuid;not null;index" json:"section_id"`
+	Label     string    `gorm:"size:20;not null" json:"label"`
+	Position  int       `gorm:"not null" json:"position"`
+	Seats     []*Seat   `gorm:"foreignKey:RowID" json:"seats,omitempty"`
+}
+
+// Seat is a single seat of a row
+type Seat struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	RowID      uuid.UUID `gorm:"type:uuid;not null;index" json:"row_id"`
+	Label      string    `gorm:"size:20;not null" json:"label"`
+	Position   int       `gorm:"not null" json:"position"`
+	Accessible bool      `gorm:"not null;default:false" json:"accessible"` // Wheelchair accessible
+}
+
+// EventSeat is the sale state of a venue seat for one event. Rows are created for every seat of
+// the venue when its seat map is attached to the event.
+type EventSeat struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	EventID   uint       `gorm:"not null;uniqueIndex:idx_event_seat" json:"event_id"`
+	SeatID    uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_event_seat" json:"seat_id"`
+	Status    SeatStatus `gorm:"size:20;not null;default:'available'" json:"status"`
+	HoldID    *uuid.UUID `gorm:"type:uuid;index" json:"-"` // Checkout session holding the seat
+	HeldUntil *time.Time `json:"-"`
+	TicketID  *uuid.UUID `gorm:"type:uuid;index" json:"ticket_id,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// VenueRequest is the request structure for defining a venue and its seat map
+type VenueRequest struct {
+	Name     string           `json:"name" binding:"required,max=200" example:"Grand Theatre"`
+	Address  string           `json:"address" binding:"omitempty,max=300" example:"1 Main St, Springfield"`
+	Sections []SectionRequest `json:"sections" binding:"required,min=1,max=50,dive"`
+}
+
+// SectionRequest is a section of a venue being defined
+type SectionRequest struct {
+	Name string       `json:"name" binding:"required,max=100" example:"Stalls"`
+	Rows []RowRequest `json:"rows" binding:"required,min=1,max=200,dive"`
+}
+
+// RowRequest is a row of a section being defined, either with its seats listed or with a number
+// of seats labelled 1 to seat_count
+type RowRequest struct {
+	Label     string        `json:"label" binding:"required,max=20" example:"A"`
+	SeatCount int           `json:"seat_count" binding:"omitempty,min=1,max=500" example:"20"`
+	Seats     []SeatRequest `json:"seats" binding:"omitempty,max=500,dive"`
+}
+
+// SeatRequest is a seat of a row being defined
+type SeatRequest struct {
+	Label      string `json:"label" binding:"required,max=20" example:"12"`
+	Accessible bool   `json:"accessible" example:"false"`
+}
+
+// AttachSeatMapRequest is the request structure for attaching a venue's seat map to an event
+type AttachSeatMapRequest struct {
+	VenueID uuid.UUID `json:"venue_id" binding:"required"`
+}
+
+// SeatHoldRequest is the request structure for selecting seats of an event in a checkout
+type SeatHoldRequest struct {
+	EventID uint        `json:"event_id" binding:"required" example:"1"`
+	SeatIDs []uuid.UUID `json:"seat_ids" binding:"max=50"` // Replaces the checkout's seats for the event; empty releases them
+}
+
+// SeatHoldResponse is the seats a checkout holds for an event
+type SeatHoldResponse struct {
+	EventID   uint          `json:"event_id"`
+	Seats     []SeatMapSeat `json:"seats"`
+	HeldUntil *time.Time    `json:"held_until,omitempty"`
+}
+
+// SeatMapResponse is the seat map of an event with the availability of each seat
+type SeatMapResponse struct {
+	EventID   uint             `json:"event_id"`
+	VenueID   uuid.UUID        `json:"venue_id"`
+	VenueName string           `json:"venue_name"`
+	Available int              `json:"available"`
+	Sections  []SeatMapSection `json:"sections"`
+}
+
+// SeatMapSection is a section of an event's seat map
+type SeatMapSection struct {
+	Name string       `json:"name"`
+	Rows []SeatMapRow `json:"rows"`
+}
+
+// SeatMapRow is a row of an event's seat map
+type SeatMapRow struct {
+	Label string        `json:"label"`
+	Seats []SeatMapSeat `json:"seats"`
+}
+
+// SeatMapSeat is a seat of an event's seat map
+type SeatMapSeat struct {
+	ID         uuid.UUID  `json:"id"`
+	Section    string     `json:"section,omitempty"`
+	Row        string     `json:"row,omitempty"`
+	Label      string     `json:"label"`
+	Accessible bool       `json:"accessible"`
+	Status     SeatStatus `json:"status"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (v *Venue) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (s *Section) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *Row) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (s *Seat) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (s *EventSeat) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.Status == "" {
+		s.Status = SeatStatusAvailable
+	}
+	return nil
+}
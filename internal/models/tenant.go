@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TenantColors is a tenant's color scheme as CSS hex colors
+type TenantColors struct {
+	Primary    string `gorm:"size:7" json:"primary" binding:"omitempty,hexcolor" example:"#1a73e8"`
+	Secondary  string `gorm:"size:7" json:"secondary" binding:"omitempty,hexcolor" example:"#fbbc04"`
+	Background string `gorm:"size:7" json:"background" binding:"omitempty,hexcolor" example:"#ffffff"`
+	Text       string `gorm:"size:7" json:"text" binding:"omitempty,hexcolor" example:"#202124"`
+}
+
+// Tenant is a white-label brand served by this deployment. Each branded frontend is matched to
+// its tenant by the domain it is served from, or by the X-Tenant header carrying the slug.
+type Tenant struct {
+	ID           uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Slug         string       `gorm:"size:50;not null;uniqueIndex" json:"slug"`
+	Domain       string       `gorm:"size:255;uniqueIndex:idx_tenants_domain,where:domain <> ''" json:"domain"` // Host name of the branded frontend, e.g. tickets.acme.com
+	AppName      string       `gorm:"size:100;not null" json:"app_name"`
+	SupportEmail string       `gorm:"size:255" json:"support_email"`
+	SenderDomain string       `gorm:"size:255" json:"sender_domain"` // Domain outgoing email is sent from
+	Colors       TenantColors `gorm:"embedded;embeddedPrefix:color_" json:"colors"`
+	Active       bool         `gorm:"not null;default:true" json:"active"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// CreateTenantRequest is the request structure for creating a tenant
+type CreateTenantRequest struct {
+	Slug         string       `json:"slug" binding:"required,max=50,alphanum" example:"acme"`
+	Domain       string       `json:"domain" binding:"omitempty,hostname,max=255" example:"tickets.acme.com"`
+	AppName      string       `json:"app_name" binding:"required,max=100" example:"Acme Tickets"`
+	SupportEmail string       `json:"support_email" binding:"omitempty,email" example:"support@acme.com"`
+	SenderDomain string       `json:"sender_domain" binding:"omitempty,hostname,max=255" example:"mail.acme.com"`
+	Colors       TenantColors `json:"colors"`
+}
+
+// UpdateTenantRequest is the request structure for updating a tenant. Empty fields are left
+// unchanged; colors replace the whole scheme when given.
+type UpdateTenantRequest struct {
+	Domain       string        `json:"domain" binding:"omitempty,hostname,max=255" example:"tickets.acme.com"`
+	AppName      string        `json:"app_name" binding:"omitempty,max=100" example:"Acme Tickets"`
+	SupportEmail string        `json:"support_email" binding:"omitempty,email" example:"support@acme.com"`
+	SenderDomain string        `json:"sender_domain" binding:"omitempty,hostname,max=255" example:"mail.acme.com"`
+	Colors       *TenantColors `json:"colors"`
+	Active       *bool         `json:"active" example:"true"`
+}
+
+// TenantResponse is the response structure for a tenant, as seen by admins
+type TenantResponse struct {
+	ID           uuid.UUID    `json:"id"`
+	Slug         string       `json:"slug"`
+	Domain       string       `json:"domain"`
+	AppName      string       `json:"app_name"`
+	SupportEmail string       `json:"support_email"`
+	SenderDomain string       `json:"sender_domain"`
+	Colors       TenantColors `json:"colors"`
+	Active       bool         `json:"active"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// TenantConfigResponse is the public branding of the tenant a request resolved to, for the
+// frontend to render itself with
+type TenantConfigResponse struct {
+	Slug         string       `json:"slug,omitempty"` // Empty for the default brand
+	AppName      string       `json:"app_name"`
+	SupportEmail string       `json:"support_email"`
+	Colors       TenantColors `json:"colors"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (t *Tenant) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// ToResponse converts a Tenant model to a TenantResponse
+func (t *Tenant) ToResponse() TenantResponse {
+	return TenantResponse{
+		ID:           t.ID,
+		Slug:         t.Slug,
+		Domain:       t.Domain,
+		AppName:      t.AppName,
+		SupportEmail: t.SupportEmail,
+		SenderDomain: t.SenderDomain,
+		Colors:       t.Colors,
+		Active:       t.Active,
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+// ToConfigResponse converts a Tenant model to its public TenantConfigResponse
+func (t *Tenant) ToConfigResponse() TenantConfigResponse {
+	return TenantConfigResponse{
+		Slug:         t.Slug,
+		AppName:      t.AppName,
+		SupportEmail: t.SupportEmail,
+		Colors:       t.Colors,
+	}
+}
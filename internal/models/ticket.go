@@ -0,0 +1,161 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketStatus represents the state of an issued ticket
+type TicketStatus string
+
+const (
+	TicketStatusValid     TicketStatus = "valid"
+	TicketStatusCheckedIn TicketStatus = "checked_in"
+	TicketStatusCancelled TicketStatus = "cancelled"
+)
+
+// Ticket represents a single admission issued to an attendee as part of an order
+type Ticket struct {
+	ID             uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrderID        uuid.UUID    `gorm:"type:uuid;not null;index" json:"order_id"`
+	EventID        uint         `gorm:"not null;index" json:"event_id"`
+	Event          *Event       `gorm:"foreignKey:EventID" json:"event,omitempty"`
+	OrganizationID *uuid.UUID   `gorm:"type:uuid;index" json:"organization_id,omitempty"`
+	AllocationID   *uuid.UUID   `gorm:"type:uuid;index" json:"allocation_id,omitempty"` // Hold/comp block the ticket was issued from
+	SeatID         *uuid.UUID   `gorm:"type:uuid;index" json:"seat_id,omitempty"`       // Reserved seat, at events with a seat map
+	AttendeeName   string       `json:"attendee_name"`
+	AttendeeEmail  string       `gorm:"not null;index" json:"attendee_email"`
+	MarketingOptIn bool         `gorm:"default:false" json:"marketing_opt_in"`
+	Status         TicketStatus `gorm:"not null;default:'valid'" json:"status"`
+	CheckedInAt    *time.Time   `gorm:"index" json:"checked_in_at,omitempty"`
+	CheckInGate    string       `gorm:"size:50" json:"check_in_gate,omitempty"` // Entrance the ticket was scanned at
+	NameChanges    int          `gorm:"not null;default:0" json:"name_changes"` // Attendee name changes made from the ticket portal
+	RevokedAt      *time.Time   `json:"revoked_at,omitempty"`                   // Revoked by the organization; refused at the door until reinstated
+	CreatedAt      time.Time    `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// AttendeeResponse is the response structure for an attendee (ticket holder)
+type AttendeeResponse struct {
+	TicketID       uuid.UUID    `json:"ticket_id"`
+	OrderID        uuid.UUID    `json:"order_id"`
+	EventID        uint         `json:"event_id"`
+	OrganizationID *uuid.UUID   `json:"organization_id,omitempty"`
+	SeatID         *uuid.UUID   `json:"seat_id,omitempty"`
+	Name           string       `json:"name"`
+	Email          string       `json:"email"`
+	Status         TicketStatus `json:"status"`
+	RevokedAt      *time.Time   `json:"revoked_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// TicketPortalResponse is a ticket as shown to its holder on the no-login ticket page
+type TicketPortalResponse struct {
+	TicketID           uuid.UUID    `json:"ticket_id"`
+	Status             TicketStatus `json:"status"`
+	AttendeeName       string       `json:"attendee_name"`
+	AttendeeEmail      string       `json:"attendee_email"`
+	EventID            uint         `json:"event_id"`
+	EventTitle         string       `json:"event_title"`
+	EventLocation      string       `json:"event_location"`
+	StartDate          time.Time    `json:"start_date"`
+	EndDate            time.Time    `json:"end_date"`
+	CheckedInAt        *time.Time   `json:"checked_in_at,omitempty"`
+	RevokedAt          *time.Time   `json:"revoked_at,omitempty"` // The ticket does not admit its holder while revoked
+	QRCode             string       `json:"qr_code"`              // Payload to show as a QR code for scanning at the door
+	NameChangeAllowed  bool         `json:"name_change_allowed"`
+	NameChangesLeft    int          `json:"name_changes_left"`
+	NameChangeDeadline time.Time    `json:"name_change_deadline"` // Names can no longer be changed after this time
+}
+
+// TicketAttendeeUpdateRequest is the request structure for correcting the attendee name of a ticket
+type TicketAttendeeUpdateRequest struct {
+	AttendeeName string `json:"attendee_name" binding:"required,min=1,max=200" example:"Jane Doe"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (t *Ticket) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.Status == "" {
+		t.Status = TicketStatusValid
+	}
+	return nil
+}
+
+// ToAttendeeResponse converts a Ticket model to an AttendeeResponse
+func (t *Ticket) ToAttendeeResponse() AttendeeResponse {
+	return AttendeeResponse{
+		TicketID:       t.ID,
+		OrderID:        t.OrderID,
+		EventID:        t.EventID,
+		OrganizationID: t.OrganizationID,
+		SeatID:         t.SeatID,
+		Name:           t.AttendeeName,
+		Email:          t.AttendeeEmail,
+		Status:         t.Status,
+		RevokedAt:      t.RevokedAt,
+		CreatedAt:      t.CreatedAt,
+	}
+}
+
+// TicketScanResult is the outcome of validating one scanned code
+type TicketScanResult string
+
+const (
+	TicketScanAdmitted         TicketScanResult = "admitted"           // Valid ticket, now checked in
+	TicketScanAlreadyCheckedIn TicketScanResult = "already_checked_in" // Ticket was checked in by an earlier scan
+	TicketScanDuplicate        TicketScanResult = "duplicate"          // Same code scanned moments ago; not re-checked
+	TicketScanCancelled        TicketScanResult = "cancelled"          // Ticket was cancelled or refunded
+	TicketScanRevoked          TicketScanResult = "revoked"            // Ticket was revoked by the organization, e.g. after a chargeback
+	TicketScanWrongEvent       TicketScanResult = "wrong_event"        // Ticket is for another event
+	TicketScanNotFound         TicketScanResult = "not_found"          // No ticket of this organization has the code
+	TicketScanInvalidCode      TicketScanResult = "invalid_code"       // Code is not a ticket ID or signed ticket QR payload
+)
+
+// TicketValidationBatchRequest is the request structure for validating a burst of scanned codes
+type TicketValidationBatchRequest struct {
+	EventID uint     `json:"event_id" binding:"required" example:"1"`
+	Gate    string   `json:"gate" binding:"omitempty,max=50" example:"north"` // Entrance the scanner is at, for live check-in statistics
+	Codes   []string `json:"codes" binding:"required,min=1,dive,required"`    // Payloads read from ticket QR codes, or ticket IDs, in scan order
+}
+
+// TicketValidationRequest is the request structure for validating one scanned ticket QR code
+type TicketValidationRequest struct {
+	EventID uint   `json:"event_id" binding:"required" example:"1"`
+	Gate    string `json:"gate" binding:"omitempty,max=50" example:"north"` // Entrance the scanner is at, for live check-in statistics
+	Code    string `json:"code" binding:"required,max=200"`                 // Payload read from the ticket's QR code
+}
+
+// TicketValidationResult is the result for one scanned code
+type TicketValidationResult struct {
+	Code         string           `json:"code"`
+	Result       TicketScanResult `json:"result"`
+	TicketID     *uuid.UUID       `json:"ticket_id,omitempty"`
+	AttendeeName string           `json:"attendee_name,omitempty"`
+}
+
+// TicketValidationBatchResponse is the response structure for a validated batch, with results in request order
+type TicketValidationBatchResponse struct {
+	Results  []TicketValidationResult `json:"results"`
+	Admitted int                      `json:"admitted"`
+}
+
+// CheckInStats is a live summary of door check-ins for an event
+type CheckInStats struct {
+	EventID   uint             `json:"event_id"`
+	Total     int64            `json:"total"`
+	Gates     map[string]int64 `json:"gates"`   // Check-ins per gate since doors opened
+	Minutes   []CheckInMinute  `json:"minutes"` // Recent minutes, oldest first, including quiet ones
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// CheckInMinute counts the check-ins of one minute
+type CheckInMinute struct {
+	Minute  time.Time        `json:"minute"`
+	Entries int64            `json:"entries"`
+	Gates   map[string]int64 `json:"gates,omitempty"`
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AllocationType distinguishes inventory held back for later from complimentary tickets
+type AllocationType string
+
+const (
+	AllocationTypeHold AllocationType = "hold" // Reserved inventory, e.g. for sponsors or box office
+	AllocationTypeComp AllocationType = "comp" // Complimentary tickets issued without payment, e.g. press or guests
+)
+
+// TicketAllocation is a block of an event's inventory reserved by an organizer outside general sale.
+// Reserved tickets are removed from the event's available count until they are issued or released.
+type TicketAllocation struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	OrganizationID uuid.UUID      `gorm:"type:uuid;not null;index" json:"organization_id"`
+	EventID        uint           `gorm:"not null;index" json:"event_id"`
+	Type           AllocationType `gorm:"not null" json:"type"`
+	Label          string         `gorm:"size:100;not null" json:"label"`
+	Quantity       int            `gorm:"not null" json:"quantity"`
+	Issued         int            `gorm:"not null;default:0" json:"issued"`
+	ReleaseAt      *time.Time     `json:"release_at,omitempty"`
+	ReleasedAt     *time.Time     `json:"released_at,omitempty"`
+	CreatedBy      *uuid.UUID     `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// CreateAllocationRequest is the request structure for reserving a hold or comp block
+type CreateAllocationRequest struct {
+	EventID   uint       `json:"event_id" binding:"required" example:"1"`
+	Type      string     `json:"type" binding:"required,oneof=hold comp" example:"comp"`
+	Label     string     `json:"label" binding:"required,max=100" example:"Press"`
+	Quantity  int        `json:"quantity" binding:"required,min=1" example:"20"`
+	ReleaseAt *time.Time `json:"release_at" example:"2025-06-01T00:00:00Z"`
+}
+
+// CompRecipient is a guest receiving a complimentary ticket
+type CompRecipient struct {
+	Name  string `json:"name" binding:"required" example:"Jane Doe"`
+	Email string `json:"email" binding:"required,email" example:"jane@example.com"`
+}
+
+// IssueCompsRequest is the request structure for issuing comp tickets from an allocation, either
+// to the listed recipients or to each attendee of a saved segment
+type IssueCompsRequest struct {
+	Recipients []CompRecipient `json:"recipients" binding:"required_without=SegmentID,excluded_with=SegmentID,omitempty,min=1,max=100,dive"`
+	SegmentID  *uuid.UUID      `json:"segment_id"` // One ticket per distinct attendee email the segment selects
+}
+
+// AllocationResponse is the response structure for a ticket allocation
+type AllocationResponse struct {
+	ID         uuid.UUID      `json:"id"`
+	EventID    uint           `json:"event_id"`
+	Type       AllocationType `json:"type"`
+	Label      string         `json:"label"`
+	Quantity   int            `json:"quantity"`
+	Issued     int            `json:"issued"`
+	Remaining  int            `json:"remaining"`
+	ReleaseAt  *time.Time     `json:"release_at,omitempty"`
+	ReleasedAt *time.Time     `json:"released_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (a *TicketAllocation) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// Remaining returns the number of reserved tickets not yet issued or released
+func (a *TicketAllocation) Remaining() int {
+	if a.ReleasedAt != nil {
+		return 0
+	}
+	return a.Quantity - a.Issued
+}
+
+// ToResponse converts a TicketAllocation model to an AllocationResponse
+func (a *TicketAllocation) ToResponse() AllocationResponse {
+	return AllocationResponse{
+		ID:         a.ID,
+		EventID:    a.EventID,
+		Type:       a.Type,
+		Label:      a.Label,
+		Quantity:   a.Quantity,
+		Issued:     a.Issued,
+		Remaining:  a.Remaining(),
+		ReleaseAt:  a.ReleaseAt,
+		ReleasedAt: a.ReleasedAt,
+		CreatedAt:  a.CreatedAt,
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TicketRevocationReason is why an organization revoked a ticket
+type TicketRevocationReason string
+
+const (
+	TicketRevocationChargeback      TicketRevocationReason = "chargeback"       // The buyer disputed the payment with their bank
+	TicketRevocationFraud           TicketRevocationReason = "fraud"            // Bought with stolen details, or a forged resale
+	TicketRevocationPolicyViolation TicketRevocationReason = "policy_violation" // The holder broke the event's terms, e.g. touting
+	TicketRevocationOther           TicketRevocationReason = "other"
+)
+
+// TicketRevocation records an organization revoking a ticket, and reinstating it if it does. A
+// revoked ticket keeps its status but is refused at the door until it is reinstated; a ticket
+// revoked again gets a new record, so the history of a ticket is kept.
+type TicketRevocation struct {
+	ID             uuid.UUID              `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	TicketID       uuid.UUID              `gorm:"type:uuid;not null;index" json:"ticket_id"`
+	OrganizationID uuid.UUID              `gorm:"type:uuid;not null;index" json:"organization_id"`
+	EventID        uint                   `gorm:"not null" json:"event_id"`
+	Reason         TicketRevocationReason `gorm:"size:30;not null" json:"reason"`
+	Note           string                 `gorm:"size:500" json:"note,omitempty"` // Internal note, not sent to the holder
+	RevokedBy      uuid.UUID              `gorm:"type:uuid;not null" json:"revoked_by"`
+	RevokedAt      time.Time              `gorm:"not null;index" json:"revoked_at"`
+	ReinstatedBy   *uuid.UUID             `gorm:"type:uuid" json:"reinstated_by,omitempty"`
+	ReinstatedAt   *time.Time             `json:"reinstated_at,omitempty"`
+	ReinstateNote  string                 `gorm:"size:500" json:"reinstate_note,omitempty"`
+	Ticket         *Ticket                `gorm:"foreignKey:TicketID" json:"ticket,omitempty"`
+}
+
+// TicketRevokeRequest is the request structure for revoking a ticket
+type TicketRevokeRequest struct {
+	Reason TicketRevocationReason `json:"reason" binding:"required,oneof=chargeback fraud policy_violation other" example:"chargeback"`
+	Note   string                 `json:"note" binding:"omitempty,max=500" example:"Dispute opened by the card issuer"`
+	Silent bool                   `json:"silent"` // Skip emailing the holder
+}
+
+// TicketReinstateRequest is the request structure for reinstating a revoked ticket
+type TicketReinstateRequest struct {
+	Note   string `json:"note" binding:"omitempty,max=500" example:"Dispute resolved in our favour"`
+	Silent bool   `json:"silent"` // Skip emailing the holder
+}
+
+// TicketRevocationQuery filters the ticket revocations of an organization
+type TicketRevocationQuery struct {
+	PageQuery
+	EventID uint   `form:"event_id" binding:"omitempty,min=1" example:"1"`
+	Reason  string `form:"reason" binding:"omitempty,oneof=chargeback fraud policy_violation other" example:"fraud"`
+	Active  *bool  `form:"active"` // Only revocations still in force, or only reinstated ones when false
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (r *TicketRevocation) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
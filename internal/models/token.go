@@ -15,6 +15,8 @@ const (
 	AccessToken TokenType = "access"
 	// RefreshToken is a long-lived token used to get new access tokens
 	RefreshToken TokenType = "refresh"
+	// SecurityAlertToken backs the "this wasn't me" link in new-device login alerts
+	SecurityAlertToken TokenType = "security_alert"
 )
 
 // Token represents a JWT token in the database
@@ -37,6 +39,24 @@ type TokenResponse struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+// JSONWebKeySet is the JWKS document publishing the public keys access tokens are verified with
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// JSONWebKey is a public signing key in JWK form (RFC 7517). RSA keys carry n and e; Ed25519
+// keys carry crv and x.
+type JSONWebKey struct {
+	Kty string `json:"kty" example:"RSA"`
+	Kid string `json:"kid" example:"3f2a9c0d1e4b5a67"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"RS256"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty" example:"AQAB"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
 // BeforeCreate is a GORM hook to set a UUID before creating a record
 func (t *Token) BeforeCreate(tx *gorm.DB) error {
 	if t.ID == uuid.Nil {
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RateLimitStatus is a client's standing against the API rate limit after its current request
+type RateLimitStatus struct {
+	Limit     int       `json:"limit"`     // Requests allowed in a burst
+	Remaining int       `json:"remaining"` // Requests that can be made right now
+	ResetAt   time.Time `json:"reset_at"`  // When the allowance is back to its full burst
+}
+
+// MonthlyUsage counts a user's metered activity in one calendar month (UTC)
+type MonthlyUsage struct {
+	Period        string `json:"period" example:"2025-06"`
+	EmailsSent    int64  `json:"emails_sent"`    // Emails delivered to the user's address
+	EventsCreated int64  `json:"events_created"` // Events the user created
+}
+
+// LimitsResponse is the response structure for the caller's rate limit and usage
+type LimitsResponse struct {
+	RateLimit *RateLimitStatus `json:"rate_limit,omitempty"` // Omitted when rate limiting is not applied to the request
+	Usage     MonthlyUsage     `json:"usage"`
+}
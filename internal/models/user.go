@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,21 +12,28 @@ import (
 
 // User represents a system user
 type User struct {
-	ID               uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
-	Email            string        `gorm:"unique;not null" json:"email"`
-	PasswordHash     string        `gorm:"not null" json:"-"`
-	FirstName        string        `json:"first_name"`
-	LastName         string        `json:"last_name"`
-	Phone            string        `json:"phone"`
-	IsEmailVerified  bool          `gorm:"default:false" json:"is_email_verified"`
-	VerificationCode string        `gorm:"default:null" json:"-"`
-	OrganizationID   *uuid.UUID    `gorm:"type:uuid;index" json:"organization_id"`
-	Organization     *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
-	CreatedBy        *uuid.UUID    `gorm:"type:uuid" json:"created_by"`
-	Roles            []*Role       `gorm:"many2many:user_roles;" json:"roles"`
-	CreatedAt        time.Time     `json:"created_at"`
-	UpdatedAt        time.Time     `json:"updated_at"`
-	DeletedAt        *time.Time    `gorm:"index" json:"-"`
+	ID                       uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Email                    string        `gorm:"unique;not null" json:"email"`
+	Username                 *string       `gorm:"size:20;uniqueIndex" json:"username,omitempty"` // Public handle, stored lowercase
+	PasswordHash             string        `gorm:"not null" json:"-"`
+	FirstName                string        `json:"first_name"`
+	LastName                 string        `json:"last_name"`
+	Phone                    string        `gorm:"serializer:encrypted" json:"phone"`                   // Encrypted at rest
+	DateOfBirth              string        `gorm:"serializer:encrypted" json:"date_of_birth,omitempty"` // YYYY-MM-DD, encrypted at rest
+	WhatsAppPhone            string        `gorm:"serializer:encrypted" json:"-"`                       // Number the user opted in to WhatsApp messages on, encrypted at rest
+	WhatsAppOptInAt          *time.Time    `json:"whatsapp_opt_in_at,omitempty"`                        // When the user opted in to WhatsApp messages; nil when opted out
+	NewsletterUnsubscribedAt *time.Time    `json:"newsletter_unsubscribed_at,omitempty"`                // When the user unsubscribed from newsletters; nil while subscribed
+	AvatarUpdatedAt          *time.Time    `json:"-"`                                                   // When the avatar was last uploaded; nil without an avatar
+	IsEmailVerified          bool          `gorm:"default:false" json:"is_email_verified"`
+	VerificationCode         string        `gorm:"default:null" json:"-"`
+	PasswordResetRequired    bool          `gorm:"default:false" json:"-"` // Set when a login is reported as not the user's; cleared by a password reset
+	OrganizationID           *uuid.UUID    `gorm:"type:uuid;index" json:"organization_id"`
+	Organization             *Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	CreatedBy                *uuid.UUID    `gorm:"type:uuid" json:"created_by"`
+	Roles                    []*Role       `gorm:"many2many:user_roles;" json:"roles"`
+	CreatedAt                time.Time     `json:"created_at"`
+	UpdatedAt                time.Time     `json:"updated_at"`
+	DeletedAt                *time.Time    `gorm:"index" json:"-"`
 }
 
 // UserRole represents the many-to-many relationship between users and roles
@@ -34,12 +43,14 @@ type UserRole struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// CreateUserRequest is the request structure for creating a new user
+// CreateUserRequest is the request structure for creating a new user. Only the email and
+// password are required; the rest of the profile can be completed later.
 type CreateUserRequest struct {
 	Email     string `json:"email" binding:"required,email" example:"user@example.com"`
 	Password  string `json:"password" binding:"required" example:"Password123!"`
-	FirstName string `json:"first_name" binding:"required,min=2,max=50" example:"John"`
-	LastName  string `json:"last_name" binding:"required,min=2,max=50" example:"Doe"`
+	Username  string `json:"username" binding:"omitempty,username" example:"john_doe"`
+	FirstName string `json:"first_name" binding:"omitempty,min=2,max=50" example:"John"`
+	LastName  string `json:"last_name" binding:"omitempty,min=2,max=50" example:"Doe"`
 	Phone     string `json:"phone" binding:"omitempty" example:"+12345678901"`
 }
 
@@ -69,9 +80,19 @@ type UpdatePasswordRequest struct {
 
 // UpdateProfileRequest is the request structure for updating user profile
 type UpdateProfileRequest struct {
-	FirstName string `json:"first_name" binding:"required,min=2,max=50" example:"John"`
-	LastName  string `json:"last_name" binding:"required,min=2,max=50" example:"Doe"`
-	Phone     string `json:"phone" binding:"omitempty" example:"+12345678901"`
+	FirstName   string `json:"first_name" binding:"required,min=2,max=50" example:"John"`
+	LastName    string `json:"last_name" binding:"required,min=2,max=50" example:"Doe"`
+	Phone       string `json:"phone" binding:"omitempty" example:"+12345678901"`
+	DateOfBirth string `json:"date_of_birth" binding:"omitempty,datetime=2006-01-02" example:"1990-04-21"`
+}
+
+// PatchProfileRequest is the request structure for completing a profile step by step. Only the
+// fields present are updated.
+type PatchProfileRequest struct {
+	FirstName   *string `json:"first_name" binding:"omitempty,min=2,max=50" example:"John"`
+	LastName    *string `json:"last_name" binding:"omitempty,min=2,max=50" example:"Doe"`
+	Phone       *string `json:"phone" binding:"omitempty,phone" example:"+12345678901"`
+	DateOfBirth *string `json:"date_of_birth" binding:"omitempty,datetime=2006-01-02" example:"1990-04-21"`
 }
 
 // ChangePasswordRequest is the request structure for changing password (authenticated user)
@@ -88,28 +109,39 @@ type VerifyEmailRequest struct {
 
 // UserResponse is the response structure for user data
 type UserResponse struct {
-	ID              uuid.UUID             `json:"id"`
-	Email           string                `json:"email"`
-	FirstName       string                `json:"first_name"`
-	LastName        string                `json:"last_name"`
-	Phone           string                `json:"phone"`
-	IsEmailVerified bool                  `json:"is_email_verified"`
-	OrganizationID  *uuid.UUID            `json:"organization_id,omitempty"`
-	Organization    *OrganizationResponse `json:"organization,omitempty"`
-	CreatedBy       *uuid.UUID            `json:"created_by,omitempty"`
-	Roles           []RoleResponse        `json:"roles"`
-	CreatedAt       time.Time             `json:"created_at"`
-	UpdatedAt       time.Time             `json:"updated_at"`
+	ID               uuid.UUID             `json:"id"`
+	Email            string                `json:"email"`
+	Username         *string               `json:"username,omitempty"`
+	FirstName        string                `json:"first_name"`
+	LastName         string                `json:"last_name"`
+	Phone            string                `json:"phone"`
+	IsEmailVerified  bool                  `json:"is_email_verified"`
+	ProfileComplete  bool                  `json:"profile_complete"`
+	MissingFields    []string              `json:"missing_profile_fields,omitempty"` // Profile fields still to fill in
+	AvatarURLs       map[string]string     `json:"avatar_urls,omitempty"`            // Avatar URL per size in pixels
+	OrganizationID   *uuid.UUID            `json:"organization_id,omitempty"`
+	Organization     *OrganizationResponse `json:"organization,omitempty"`
+	OrganizationRole string                `json:"organization_role,omitempty"` // Role in the organization the user is listed for
+	MemberActive     *bool                 `json:"member_active,omitempty"`     // Whether that membership is active
+	CreatedBy        *uuid.UUID            `json:"created_by,omitempty"`
+	Roles            []RoleResponse        `json:"roles"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
 }
 
 // UserProfileResponse is the response structure for user profile data (without roles)
 type UserProfileResponse struct {
 	ID              uuid.UUID             `json:"id"`
 	Email           string                `json:"email"`
+	Username        *string               `json:"username,omitempty"`
 	FirstName       string                `json:"first_name"`
 	LastName        string                `json:"last_name"`
 	Phone           string                `json:"phone"`
+	DateOfBirth     string                `json:"date_of_birth,omitempty"`
 	IsEmailVerified bool                  `json:"is_email_verified"`
+	ProfileComplete bool                  `json:"profile_complete"`
+	MissingFields   []string              `json:"missing_profile_fields,omitempty"` // Profile fields still to fill in
+	AvatarURLs      map[string]string     `json:"avatar_urls,omitempty"`            // Avatar URL per size in pixels
 	OrganizationID  *uuid.UUID            `json:"organization_id,omitempty"`
 	Organization    *OrganizationResponse `json:"organization,omitempty"`
 	CreatedBy       *uuid.UUID            `json:"created_by,omitempty"`
@@ -141,6 +173,36 @@ func (u *User) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// MissingProfileFields returns the JSON names of the profile fields the user has yet to fill in.
+// Users can register with an email and password only; actions such as running an organization
+// need a complete profile.
+func (u *User) MissingProfileFields() []string {
+	var missing []string
+	if u.FirstName == "" {
+		missing = append(missing, "first_name")
+	}
+	if u.LastName == "" {
+		missing = append(missing, "last_name")
+	}
+	if u.Phone == "" {
+		missing = append(missing, "phone")
+	}
+	return missing
+}
+
+// AvatarURLs returns the API paths of the user's avatar in every size, keyed by the size in pixels,
+// or nil without an avatar. The paths change with every upload, so they can be cached forever.
+func (u *User) AvatarURLs() map[string]string {
+	if u.AvatarUpdatedAt == nil {
+		return nil
+	}
+	urls := make(map[string]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		urls[strconv.Itoa(size)] = fmt.Sprintf("/api/v1/images/users/%s/avatar?size=%d&v=%d", u.ID, size, u.AvatarUpdatedAt.Unix())
+	}
+	return urls
+}
+
 // ToResponse converts a User model to a UserResponse
 func (u *User) ToResponse() UserResponse {
 	roleResponses := make([]RoleResponse, len(u.Roles))
@@ -154,13 +216,18 @@ func (u *User) ToResponse() UserResponse {
 		orgResponse = &resp
 	}
 
+	missing := u.MissingProfileFields()
 	return UserResponse{
 		ID:              u.ID,
 		Email:           u.Email,
+		Username:        u.Username,
 		FirstName:       u.FirstName,
 		LastName:        u.LastName,
 		Phone:           u.Phone,
 		IsEmailVerified: u.IsEmailVerified,
+		ProfileComplete: len(missing) == 0,
+		MissingFields:   missing,
+		AvatarURLs:      u.AvatarURLs(),
 		OrganizationID:  u.OrganizationID,
 		Organization:    orgResponse,
 		CreatedBy:       u.CreatedBy,
@@ -178,13 +245,19 @@ func (u *User) ToProfileResponse() UserProfileResponse {
 		orgResponse = &resp
 	}
 
+	missing := u.MissingProfileFields()
 	return UserProfileResponse{
 		ID:              u.ID,
 		Email:           u.Email,
+		Username:        u.Username,
 		FirstName:       u.FirstName,
 		LastName:        u.LastName,
 		Phone:           u.Phone,
+		DateOfBirth:     u.DateOfBirth,
 		IsEmailVerified: u.IsEmailVerified,
+		ProfileComplete: len(missing) == 0,
+		MissingFields:   missing,
+		AvatarURLs:      u.AvatarURLs(),
 		OrganizationID:  u.OrganizationID,
 		Organization:    orgResponse,
 		CreatedBy:       u.CreatedBy,
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserDevice is a device a user has signed in from, identified by a fingerprint of its client
+type UserDevice struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_device" json:"user_id"`
+	Fingerprint string    `gorm:"not null;uniqueIndex:idx_user_device" json:"-"`
+	UserAgent   string    `json:"user_agent"`
+	LastIP      string    `json:"last_ip"`
+	Country     string    `gorm:"size:2" json:"country,omitempty"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+}
+
+// LoginContext describes the client a login request comes from
+type LoginContext struct {
+	IP        string
+	UserAgent string
+	DeviceID  string // Stable client-generated ID sent in the X-Device-ID header, if any
+	Country   string // ISO country code resolved by the CDN or proxy
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (d *UserDevice) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
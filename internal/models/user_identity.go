@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OAuthProvider identifies a provider users can sign in with instead of a password
+type OAuthProvider string
+
+const (
+	OAuthProviderGoogle   OAuthProvider = "google"
+	OAuthProviderFacebook OAuthProvider = "facebook"
+	OAuthProviderApple    OAuthProvider = "apple"
+)
+
+// UserIdentity links a user to their account at a social login provider. Later sign-ins with the
+// same provider account find the user by it, even after either email address changes.
+type UserIdentity struct {
+	ID          uuid.UUID     `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      uuid.UUID     `gorm:"type:uuid;not null;index" json:"user_id"`
+	Provider    OAuthProvider `gorm:"size:20;not null;uniqueIndex:idx_user_identity_subject" json:"provider"`
+	Subject     string        `gorm:"size:255;not null;uniqueIndex:idx_user_identity_subject" json:"-"` // The user's ID at the provider
+	Email       string        `json:"email,omitempty"`                                                  // Email address the provider last reported
+	LastLoginAt *time.Time    `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (i *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
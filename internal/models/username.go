@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UsernameChange records a user taking, changing or dropping their username. Previous usernames
+// stay reserved for their former owner for a while, so public profile links and @mentions keep
+// resolving to them.
+type UsernameChange struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	OldUsername string    `gorm:"size:20;index" json:"old_username,omitempty"`
+	NewUsername string    `gorm:"size:20" json:"new_username,omitempty"`
+	CreatedAt   time.Time `gorm:"index" json:"created_at"`
+}
+
+// ChangeUsernameRequest is the request structure for taking or changing a username. An empty
+// username removes it.
+type ChangeUsernameRequest struct {
+	Username string `json:"username" binding:"omitempty,username" example:"john_doe"`
+}
+
+// UsernameAvailabilityRequest is the query structure for checking whether a username can be taken
+type UsernameAvailabilityRequest struct {
+	Username string `form:"username" binding:"required,username" example:"john_doe"`
+}
+
+// UsernameAvailability tells whether a username can be taken and, if not, why
+type UsernameAvailability struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // "taken", "reserved" or "recently_used"
+}
+
+// PublicProfile is what anyone can see of a user through their username
+type PublicProfile struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	MemberSince time.Time `json:"member_since"`
+	Renamed     bool      `json:"renamed"` // Requested through a previous username; link to the current one instead
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (c *UsernameChange) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
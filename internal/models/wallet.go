@@ -0,0 +1,13 @@
+package models
+
+// Phone wallets tickets can be added to
+const (
+	WalletProviderApple  = "apple"
+	WalletProviderGoogle = "google"
+)
+
+// WalletPassLink is the link adding a ticket to Google Wallet
+type WalletPassLink struct {
+	Provider string `json:"provider" example:"google"`
+	SaveURL  string `json:"save_url" example:"https://pay.google.com/gp/v/save/eyJhbGciOiJSUzI1NiJ9..."`
+}
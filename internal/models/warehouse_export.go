@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Warehouse datasets exported for the data team, one partition per UTC day
+const (
+	WarehouseDatasetOrders   = "orders"    // Orders placed that day
+	WarehouseDatasetTickets  = "tickets"   // Tickets issued that day
+	WarehouseDatasetCheckIns = "check_ins" // Tickets checked in that day
+)
+
+// WarehouseExport records one exported partition of a warehouse dataset. Re-exporting a day
+// overwrites its object and updates the record.
+type WarehouseExport struct {
+	ID            uuid.UUID    `gorm:"type:uuid;primary_key;default:uuid_generate_v4()" json:"id"`
+	Dataset       string       `gorm:"size:20;not null;uniqueIndex:idx_warehouse_partition" json:"dataset"`
+	PartitionDate time.Time    `gorm:"type:date;not null;uniqueIndex:idx_warehouse_partition" json:"partition_date"`
+	Location      string       `gorm:"not null" json:"location"` // s3://bucket/key of the gzipped CSV file
+	Status        ExportStatus `gorm:"not null;default:'pending'" json:"status"`
+	RowCount      int          `json:"row_count"`
+	Bytes         int64        `json:"bytes"`
+	Error         string       `json:"error,omitempty"`
+	CompletedAt   *time.Time   `json:"completed_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// WarehouseExportFilter is the query structure for listing exported partitions
+type WarehouseExportFilter struct {
+	Dataset string `form:"dataset" binding:"omitempty,oneof=orders tickets check_ins" example:"orders"`
+	From    string `form:"from" binding:"omitempty,datetime=2006-01-02" example:"2025-06-01"`
+	To      string `form:"to" binding:"omitempty,datetime=2006-01-02" example:"2025-06-30"`
+}
+
+// BeforeCreate is a GORM hook to set a UUID before creating a record
+func (w *WarehouseExport) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
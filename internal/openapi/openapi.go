@@ -0,0 +1,418 @@
+// Package openapi builds an OpenAPI 3 document from the Swagger 2.0 document generated by swaggo,
+// adding typed schemas for the response envelope, error codes and REST hook payloads so client
+// SDKs can be generated from it.
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/swaggo/swag"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+var (
+	specOnce sync.Once
+	spec     []byte
+	specErr  error
+)
+
+// Spec returns the OpenAPI 3 document of the registered swaggo docs, built once
+func Spec() ([]byte, error) {
+	specOnce.Do(func() {
+		doc, err := swag.ReadDoc()
+		if err != nil {
+			specErr = fmt.Errorf("failed to read swagger docs: %w", err)
+			return
+		}
+		spec, specErr = Build([]byte(doc))
+	})
+	return spec, specErr
+}
+
+// Build converts a Swagger 2.0 document to OpenAPI 3 and adds the schemas that annotations can't express
+func Build(swagger2 []byte) ([]byte, error) {
+	var source map[string]interface{}
+	if err := json.Unmarshal(swagger2, &source); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+	if version, _ := source["swagger"].(string); version != "2.0" {
+		return nil, fmt.Errorf("unsupported swagger version %q", version)
+	}
+
+	doc := convert(source)
+	addTypedSchemas(doc)
+
+	return json.MarshalIndent(doc, "", "    ")
+}
+
+// convert maps a Swagger 2.0 document onto the OpenAPI 3 structure
+func convert(source map[string]interface{}) map[string]interface{} {
+	consumes := stringList(source["consumes"], "application/json")
+	produces := stringList(source["produces"], "application/json")
+
+	schemas, _ := source["definitions"].(map[string]interface{})
+	if schemas == nil {
+		schemas = map[string]interface{}{}
+	}
+	components := map[string]interface{}{"schemas": schemas}
+	if securityDefinitions, ok := source["securityDefinitions"].(map[string]interface{}); ok {
+		components["securitySchemes"] = securityDefinitions
+	}
+
+	doc := map[string]interface{}{
+		"openapi":    Version,
+		"info":       source["info"],
+		"servers":    servers(source),
+		"paths":      map[string]interface{}{},
+		"components": components,
+	}
+	for _, key := range []string{"tags", "security", "externalDocs"} {
+		if value, ok := source[key]; ok {
+			doc[key] = value
+		}
+	}
+
+	paths, _ := source["paths"].(map[string]interface{})
+	for route, item := range paths {
+		pathItem, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		converted := map[string]interface{}{}
+		for method, value := range pathItem {
+			if method == "parameters" {
+				converted[method], _ = convertParameters(value, consumes)
+				continue
+			}
+			operation, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			converted[method] = convertOperation(operation, consumes, produces)
+		}
+		doc["paths"].(map[string]interface{})[route] = converted
+	}
+
+	return rewriteSchemas(doc).(map[string]interface{})
+}
+
+// servers derives server URLs from the host, base path and schemes
+func servers(source map[string]interface{}) []interface{} {
+	host, _ := source["host"].(string)
+	basePath, _ := source["basePath"].(string)
+	if host == "" {
+		return []interface{}{map[string]interface{}{"url": basePath}}
+	}
+
+	var list []interface{}
+	for _, scheme := range stringList(source["schemes"], "https") {
+		list = append(list, map[string]interface{}{"url": scheme + "://" + host + basePath})
+	}
+	return list
+}
+
+// convertOperation moves body and form parameters to a request body and wraps response schemas in content
+func convertOperation(operation map[string]interface{}, consumes, produces []string) map[string]interface{} {
+	consumes = stringList(operation["consumes"], consumes...)
+	produces = stringList(operation["produces"], produces...)
+
+	converted := map[string]interface{}{}
+	for key, value := range operation {
+		switch key {
+		case "consumes", "produces", "parameters", "responses", "schemes":
+		default:
+			converted[key] = value
+		}
+	}
+
+	if params, ok := operation["parameters"]; ok {
+		parameters, requestBody := convertParameters(params, consumes)
+		if len(parameters) > 0 {
+			converted["parameters"] = parameters
+		}
+		if requestBody != nil {
+			converted["requestBody"] = requestBody
+		}
+	}
+
+	responses := map[string]interface{}{}
+	if source, ok := operation["responses"].(map[string]interface{}); ok {
+		for code, value := range source {
+			response, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			responses[code] = convertResponse(code, response, produces)
+		}
+	}
+	converted["responses"] = responses
+
+	return converted
+}
+
+// convertParameters returns the non-body parameters and the request body built from body or form parameters
+func convertParameters(value interface{}, consumes []string) ([]interface{}, map[string]interface{}) {
+	list, _ := value.([]interface{})
+
+	var parameters []interface{}
+	var requestBody map[string]interface{}
+	formProperties := map[string]interface{}{}
+	var formRequired []string
+	multipart := false
+
+	for _, item := range list {
+		param, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		switch param["in"] {
+		case "body":
+			content := map[string]interface{}{}
+			for _, mediaType := range consumes {
+				content[mediaType] = map[string]interface{}{"schema": param["schema"]}
+			}
+			requestBody = map[string]interface{}{"content": content}
+			copyKeys(requestBody, param, "description", "required")
+		case "formData":
+			name, _ := param["name"].(string)
+			schema := parameterSchema(param)
+			if param["type"] == "file" {
+				multipart = true
+			}
+			copyKeys(schema, param, "description")
+			formProperties[name] = schema
+			if required, _ := param["required"].(bool); required {
+				formRequired = append(formRequired, name)
+			}
+		default:
+			converted := map[string]interface{}{"schema": parameterSchema(param)}
+			copyKeys(converted, param, "name", "in", "description", "required")
+			switch param["collectionFormat"] {
+			case "multi":
+				converted["style"], converted["explode"] = "form", true
+			case "csv":
+				converted["style"], converted["explode"] = "form", false
+			}
+			parameters = append(parameters, converted)
+		}
+	}
+
+	if len(formProperties) > 0 {
+		schema := map[string]interface{}{"type": "object", "properties": formProperties}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		mediaType := "application/x-www-form-urlencoded"
+		if multipart {
+			mediaType = "multipart/form-data"
+		}
+		requestBody = map[string]interface{}{
+			"content": map[string]interface{}{mediaType: map[string]interface{}{"schema": schema}},
+		}
+	}
+
+	return parameters, requestBody
+}
+
+// parameterSchema extracts the schema keywords of a Swagger 2.0 non-body parameter
+func parameterSchema(param map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{}
+	copyKeys(schema, param, "type", "format", "items", "enum", "default", "minimum", "maximum",
+		"exclusiveMinimum", "exclusiveMaximum", "minLength", "maxLength", "pattern", "minItems", "maxItems", "uniqueItems")
+	return schema
+}
+
+// convertResponse wraps a response schema in content. Error responses documented with the generic
+// envelope point to the error envelope, whose error code is enumerated.
+func convertResponse(code string, response map[string]interface{}, produces []string) map[string]interface{} {
+	converted := map[string]interface{}{}
+	copyKeys(converted, response, "description")
+	if _, ok := converted["description"]; !ok {
+		converted["description"] = ""
+	}
+
+	if schema, ok := response["schema"].(map[string]interface{}); ok {
+		if status, err := strconv.Atoi(code); err == nil && status >= 400 && schema["$ref"] == "#/definitions/utils.Response" {
+			schema = map[string]interface{}{"$ref": "#/components/schemas/utils.ErrorResponse"}
+		}
+		content := map[string]interface{}{}
+		for _, mediaType := range produces {
+			content[mediaType] = map[string]interface{}{"schema": schema}
+		}
+		converted["content"] = content
+	}
+
+	if headers, ok := response["headers"].(map[string]interface{}); ok {
+		convertedHeaders := map[string]interface{}{}
+		for name, value := range headers {
+			header, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			convertedHeader := map[string]interface{}{"schema": parameterSchema(header)}
+			copyKeys(convertedHeader, header, "description")
+			convertedHeaders[name] = convertedHeader
+		}
+		converted["headers"] = convertedHeaders
+	}
+
+	return converted
+}
+
+// rewriteSchemas walks the document rewriting definition references and Swagger-only schema keywords
+func rewriteSchemas(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			v["$ref"] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+		}
+		if nullable, ok := v["x-nullable"]; ok {
+			v["nullable"] = nullable
+			delete(v, "x-nullable")
+		}
+		if v["type"] == "file" {
+			v["type"], v["format"] = "string", "binary"
+		}
+		for key, child := range v {
+			v[key] = rewriteSchemas(child)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = rewriteSchemas(child)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// addTypedSchemas adds schemas generated from Go types: the response envelopes with the enumerated
+// error codes, the pagination envelope, and the payloads of REST hook deliveries
+func addTypedSchemas(doc map[string]interface{}) {
+	components := doc["components"].(map[string]interface{})
+	generator := &schemaGenerator{schemas: components["schemas"].(map[string]interface{})}
+
+	// The typed Go definitions replace the annotated ones so nullability and required fields are exact
+	delete(generator.schemas, "utils.Response")
+	delete(generator.schemas, "utils.ErrorInfo")
+	delete(generator.schemas, "models.PollingPage")
+	generator.ref(reflect.TypeOf(utils.Response{}))
+	generator.ref(reflect.TypeOf(models.PollingPage{}))
+
+	codes := append([]string(nil), utils.ErrorCodes...)
+	sort.Strings(codes)
+	errorInfo := generator.schemas["utils.ErrorInfo"].(map[string]interface{})
+	errorInfo["properties"].(map[string]interface{})["code"] = map[string]interface{}{
+		"type":        "string",
+		"enum":        codes,
+		"description": "Machine-readable error code",
+	}
+
+	generator.schemas["utils.ErrorResponse"] = map[string]interface{}{
+		"description": "Envelope of every failed request",
+		"allOf": []interface{}{
+			map[string]interface{}{"$ref": "#/components/schemas/utils.Response"},
+			map[string]interface{}{
+				"type":     "object",
+				"required": []string{"error"},
+				"properties": map[string]interface{}{
+					"success": map[string]interface{}{"type": "boolean", "enum": []bool{false}},
+					"error":   map[string]interface{}{"$ref": "#/components/schemas/utils.ErrorInfo"},
+				},
+			},
+		},
+	}
+
+	webhooks := map[string]interface{}{}
+	for _, hook := range hookPayloads {
+		webhooks[string(hook.event)] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary":     hook.summary,
+				"description": "Sent to the target URL of REST hook subscriptions for this event. Answer 410 Gone to remove the subscription.",
+				"tags":        []string{"integrations"},
+				"parameters": []interface{}{
+					map[string]interface{}{
+						"name":     "X-Hook-Event",
+						"in":       "header",
+						"required": true,
+						"schema":   map[string]interface{}{"type": "string", "enum": []string{string(hook.event)}},
+					},
+					map[string]interface{}{
+						"name":        "X-Hook-Delivery",
+						"in":          "header",
+						"description": "ID of the delivery, the same on every retry",
+						"schema":      map[string]interface{}{"type": "string", "format": "uuid"},
+					},
+					map[string]interface{}{
+						"name":        "X-Hook-Signature",
+						"in":          "header",
+						"description": "t=<unix time>,v1=<hex HMAC-SHA256 of \"<unix time>.<body>\" under the subscription's secret>",
+						"schema":      map[string]interface{}{"type": "string"},
+					},
+				},
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": generator.ref(hook.payload)},
+					},
+				},
+				"responses": map[string]interface{}{
+					"2XX": map[string]interface{}{"description": "Delivery accepted"},
+					"410": map[string]interface{}{"description": "Subscription removed by the receiver"},
+				},
+			},
+		}
+	}
+	// OpenAPI 3.0 has no webhooks object; x-webhooks is understood by most documentation tools
+	doc["x-webhooks"] = webhooks
+}
+
+// hookPayloads are the bodies POSTed to REST hook subscribers for each event
+var hookPayloads = []struct {
+	event   models.HookEvent
+	summary string
+	payload reflect.Type
+}{
+	{models.HookEventOrderCreated, "An order was placed", reflect.TypeOf(models.OrderResponse{})},
+	{models.HookEventAttendeeCreated, "An attendee ticket was issued", reflect.TypeOf(models.AttendeeResponse{})},
+	{models.HookEventTicketCheckedIn, "A ticket was checked in", reflect.TypeOf(models.HookCheckIn{})},
+	{models.HookEventEventUpdated, "An event was created, updated, moved through its lifecycle or deleted", reflect.TypeOf(models.Event{})},
+	{models.HookEventRefundProcessed, "A refund was processed", reflect.TypeOf(models.Refund{})},
+}
+
+// stringList returns a list of strings from a JSON array, or the defaults when it is empty
+func stringList(value interface{}, defaults ...string) []string {
+	items, _ := value.([]interface{})
+	var list []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	if len(list) == 0 {
+		return defaults
+	}
+	return list
+}
+
+// copyKeys copies the given keys from src to dst when present
+func copyKeys(dst, src map[string]interface{}, keys ...string) {
+	for _, key := range keys {
+		if value, ok := src[key]; ok {
+			dst[key] = value
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLockNotHeld is returned when a lock expired or was taken over by another holder
+var ErrLockNotHeld = errors.New("lock not held")
+
+// refreshScript extends a lock only while it still belongs to the caller
+var refreshScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript deletes a lock only while it still belongs to the caller
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// Lock is a distributed lock held in Redis until it is released or its TTL passes
+type Lock struct {
+	key   string
+	token string
+}
+
+// AcquireLock tries to take the lock at key for ttl. It returns nil without an error when
+// another holder has it.
+func AcquireLock(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	if Client == nil {
+		return nil, errors.New("redis is not connected")
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(b)
+
+	acquired, err := Client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !acquired {
+		return nil, err
+	}
+	return &Lock{key: key, token: token}, nil
+}
+
+// Refresh extends the lock to ttl from now, failing with ErrLockNotHeld if it was lost
+func (l *Lock) Refresh(ctx context.Context, ttl time.Duration) error {
+	extended, err := refreshScript.Run(ctx, Client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if extended == 0 {
+		return ErrLockNotHeld
+	}
+	return nil
+}
+
+// Release frees the lock if the caller still holds it
+func (l *Lock) Release(ctx context.Context) error {
+	return releaseScript.Run(ctx, Client, []string{l.key}, l.token).Err()
+}
@@ -0,0 +1,18 @@
+package redis
+
+// namespace is REDIS_KEY_PREFIX, the namespace of this environment on a shared server. Keys are
+// prefixed by the clients; queue and channel names, which the clients cannot tell apart from
+// other arguments, are prefixed by QueueName and ChannelName.
+var namespace string
+
+// SetNamespace sets the namespace of queue and channel names. Entry points call it with
+// REDIS_KEY_PREFIX as soon as the configuration is loaded, before any queue or channel is used.
+func SetNamespace(prefix string) {
+	namespace = prefix
+}
+
+// ChannelName returns the name of a pub/sub channel within the namespace. Channels are not keys
+// and are shared by every database of a server, so they are not prefixed by the clients.
+func ChannelName(name string) string {
+	return namespace + name
+}
@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keylessCommands take no key, so their arguments are left alone. Pub/sub channels are not keys;
+// they are namespaced by ChannelName instead.
+var keylessCommands = map[string]bool{
+	"ping": true, "echo": true, "info": true, "time": true, "auth": true, "hello": true, "select": true,
+	"client": true, "cluster": true, "command": true, "config": true, "readonly": true, "readwrite": true,
+	"multi": true, "exec": true, "discard": true, "unwatch": true, "script": true, "function": true,
+	"publish": true, "spublish": true, "subscribe": true, "unsubscribe": true, "psubscribe": true, "punsubscribe": true,
+}
+
+// multiKeyCommands take only keys after their name
+var multiKeyCommands = map[string]bool{
+	"del": true, "unlink": true, "exists": true, "touch": true, "mget": true, "watch": true,
+}
+
+// scriptCommands take the number of keys after the script, followed by the keys
+var scriptCommands = map[string]bool{
+	"eval": true, "evalsha": true, "eval_ro": true, "evalsha_ro": true, "fcall": true, "fcall_ro": true,
+}
+
+// keyPrefixer prepends a prefix to the keys of every command, so stores sharing a database,
+// as they must in a cluster, cannot collide. Commands that read key names back, like SCAN or
+// KEYS, would return them prefixed and are not used by the stores.
+type keyPrefixer struct {
+	prefix string
+}
+
+func prefixHook(prefix string) redis.Hook {
+	return keyPrefixer{prefix: prefix}
+}
+
+func (p keyPrefixer) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (p keyPrefixer) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		p.prefixKeys(cmd.Args())
+		return next(ctx, cmd)
+	}
+}
+
+func (p keyPrefixer) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			p.prefixKeys(cmd.Args())
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// prefixKeys prefixes the key arguments of a command in place
+func (p keyPrefixer) prefixKeys(args []interface{}) {
+	if len(args) < 2 {
+		return
+	}
+	name, _ := args[0].(string)
+	name = strings.ToLower(name)
+
+	switch {
+	case keylessCommands[name]:
+	case multiKeyCommands[name]:
+		for i := 1; i < len(args); i++ {
+			p.prefixArg(args, i)
+		}
+	case scriptCommands[name]:
+		if len(args) < 3 {
+			return
+		}
+		numKeys, err := strconv.Atoi(argString(args[2]))
+		if err != nil {
+			return
+		}
+		for i := 3; i < 3+numKeys && i < len(args); i++ {
+			p.prefixArg(args, i)
+		}
+	default:
+		p.prefixArg(args, 1)
+	}
+}
+
+func (p keyPrefixer) prefixArg(args []interface{}, i int) {
+	if key, ok := args[i].(string); ok {
+		args[i] = p.prefix + key
+	}
+}
+
+func argString(arg interface{}) string {
+	switch v := arg.(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"fmt"
+
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/hibiken/asynq"
+)
+
+// QueueName returns the name of a job queue within the namespace set by SetNamespace. Asynq keys
+// everything of a queue by its name, so prefixing the name keeps environments sharing a server
+// apart. Enqueue tasks, configure workers and inspect queues with it.
+func QueueName(name string) string {
+	return namespace + name
+}
+
+// QueueConnOpt returns the connection of the background job queues for the configured topology.
+// Queues use their own database so flushing a cache never drops jobs.
+func QueueConnOpt(cfg *config.Config) asynq.RedisConnOpt {
+	switch cfg.Redis.Mode {
+	case config.RedisSentinel:
+		return asynq.RedisFailoverClientOpt{
+			MasterName:       cfg.Redis.MasterName,
+			SentinelAddrs:    addrs(cfg.Redis),
+			SentinelPassword: cfg.Redis.SentinelPassword,
+			Password:         cfg.Redis.Password,
+			DB:               cfg.Redis.Queue.DB,
+		}
+	case config.RedisCluster:
+		return asynq.RedisClusterClientOpt{
+			Addrs:    addrs(cfg.Redis),
+			Password: cfg.Redis.Password,
+		}
+	default:
+		return asynq.RedisClientOpt{
+			Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.Queue.DB,
+		}
+	}
+}
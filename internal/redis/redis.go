@@ -2,9 +2,11 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
+	"slices"
+	"strings"
 	"time"
 
 	"event-ticketing-backend/pkg/config"
@@ -12,50 +14,151 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-var Client *redis.Client
+// Clients of each use of Redis. They are the same client when their stores share a database and
+// key prefix.
+var (
+	Client    redis.UniversalClient // Caches, carts, idempotency keys, live statistics and locks
+	OTP       redis.UniversalClient // One-time passwords
+	RateLimit redis.UniversalClient // Send quotas and resend cooldowns
+)
 
-// Connect establishes a connection to Redis using the provided configuration
+// Connect establishes the connections to Redis using the provided configuration. The clients
+// stay set when Redis cannot be reached so they reconnect once it is back.
 func Connect(cfg *config.Config) error {
-	db, err := strconv.Atoi(cfg.Redis.DB)
-	if err != nil {
-		return fmt.Errorf("invalid Redis DB number: %w", err)
+	if err := validate(cfg.Redis); err != nil {
+		return err
 	}
 
-	Client = redis.NewClient(&redis.Options{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       db,
-	})
+	clients := make(map[config.RedisStoreConfig]redis.UniversalClient)
+	clientFor := func(store config.RedisStoreConfig) redis.UniversalClient {
+		if client, ok := clients[store]; ok {
+			return client
+		}
+		client := newClient(cfg.Redis, store)
+		clients[store] = client
+		return client
+	}
+	Client = clientFor(cfg.Redis.Cache)
+	OTP = clientFor(cfg.Redis.OTP)
+	RateLimit = clientFor(cfg.Redis.RateLimit)
 
 	// Test the connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	if err := Client.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("failed to connect to Redis: %w", err)
+	for _, client := range clients {
+		if err := client.Ping(ctx).Err(); err != nil {
+			return fmt.Errorf("failed to connect to Redis: %w", err)
+		}
 	}
 
-	log.Println("Redis connected successfully")
+	log.Printf("Redis connected successfully (%s)", cfg.Redis.Mode)
 	return nil
 }
 
-// Close closes the Redis connection
-func Close() error {
-	if Client == nil {
+// newClient creates the client of one store for the configured topology
+func newClient(cfg config.RedisConfig, store config.RedisStoreConfig) redis.UniversalClient {
+	opts := &redis.UniversalOptions{
+		Addrs:    addrs(cfg),
+		Password: cfg.Password,
+		DB:       store.DB,
+
+		ReadTimeout:  cfg.OperationTimeout,
+		WriteTimeout: cfg.OperationTimeout,
+		// Stop waiting on a command when the caller's context is cancelled or its deadline passes
+		ContextTimeoutEnabled: true,
+	}
+	switch cfg.Mode {
+	case config.RedisSentinel:
+		opts.MasterName = cfg.MasterName
+		opts.SentinelPassword = cfg.SentinelPassword
+	case config.RedisCluster:
+		opts.IsClusterMode = true
+	}
+
+	client := redis.NewUniversalClient(opts)
+	if prefix := cfg.KeyPrefix + store.Prefix; prefix != "" {
+		client.AddHook(prefixHook(prefix))
+	}
+	return client
+}
+
+// validate rejects topologies the clients cannot be built for
+func validate(cfg config.RedisConfig) error {
+	switch cfg.Mode {
+	case config.RedisStandalone:
 		return nil
+	case config.RedisSentinel:
+		if cfg.MasterName == "" {
+			return errors.New("REDIS_SENTINEL_MASTER is required in sentinel mode")
+		}
+	case config.RedisCluster:
+		// A cluster only has database 0; stores are told apart by their key prefix instead
+		for _, store := range []config.RedisStoreConfig{cfg.Cache, cfg.OTP, cfg.RateLimit, cfg.Queue} {
+			if store.DB != 0 {
+				return fmt.Errorf("Redis cluster only supports database 0, got %d", store.DB)
+			}
+		}
+	default:
+		return fmt.Errorf("invalid REDIS_MODE %q, expected standalone, sentinel or cluster", cfg.Mode)
 	}
-	return Client.Close()
+	if len(addrs(cfg)) == 0 {
+		return fmt.Errorf("REDIS_ADDRS is required in %s mode", cfg.Mode)
+	}
+	return nil
 }
 
-// IsHealthy checks if Redis is healthy by sending a PING command
+// addrs returns the server addresses: REDIS_HOST and REDIS_PORT in standalone mode, REDIS_ADDRS
+// otherwise
+func addrs(cfg config.RedisConfig) []string {
+	if cfg.Mode == config.RedisStandalone || cfg.Mode == "" {
+		return []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}
+	}
+
+	var addrs []string
+	for _, addr := range strings.Split(cfg.Addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// Close closes the Redis connections
+func Close() error {
+	var errs []error
+	for _, client := range distinctClients() {
+		errs = append(errs, client.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// IsHealthy checks if Redis is healthy by sending a PING command on each connection
 func IsHealthy() bool {
-	if Client == nil {
+	clients := distinctClients()
+	if len(clients) == 0 {
 		return false
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	err := Client.Ping(ctx).Err()
-	return err == nil
+	for _, client := range clients {
+		if err := client.Ping(ctx).Err(); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// distinctClients returns each connected client once
+func distinctClients() []redis.UniversalClient {
+	var clients []redis.UniversalClient
+	for _, client := range []redis.UniversalClient{Client, OTP, RateLimit} {
+		if client == nil || slices.Contains(clients, client) {
+			continue
+		}
+		clients = append(clients, client)
+	}
+	return clients
 }
@@ -2,10 +2,12 @@ package routes
 
 import (
 	"net/http"
+	"strings"
 
 	"event-ticketing-backend/docs" // Import generated docs
 	"event-ticketing-backend/internal/handlers"
 	"event-ticketing-backend/internal/middleware"
+	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/config"
 	"event-ticketing-backend/pkg/utils"
@@ -27,30 +29,159 @@ func SetupRouter() *gin.Engine {
 		panic(err)
 	}
 
+	// Only take client IPs from X-Forwarded-For when set by our own proxies
+	if cfg.Security.TrustedProxies != "" {
+		proxies := strings.FieldsFunc(cfg.Security.TrustedProxies, func(r rune) bool { return r == ',' || r == ' ' })
+		if err := router.SetTrustedProxies(proxies); err != nil {
+			panic(err)
+		}
+	}
+
 	// Initialize rate limiters
 	middleware.InitRateLimiters()
 
 	// Middleware
 	router.Use(middleware.RequestID()) // Add request ID to each request
+	router.Use(middleware.RequestTimeout(cfg.Server.RequestTimeout))
 	router.Use(middleware.Logger())
 	router.Use(middleware.CORS())
+	router.Use(middleware.Compression()) // Gzip JSON and text responses of 1KB or more
 	router.Use(middleware.RateLimiterMiddleware())
+	router.Use(middleware.Idempotency())        // Replay retried writes that carry an Idempotency-Key
 	router.Use(middleware.ErrorHandler())       // Custom panic recovery
 	router.Use(middleware.GlobalErrorHandler()) // Handle remaining errors
 
 	// Initialize services
 	eventService := services.NewEventService()
 	healthService := services.NewHealthService()
+	inventoryAlertService := services.NewInventoryAlertService(cfg)
+	pricingService := services.NewPricingService()
+	promoCodeService := services.NewPromoCodeService()
+	presaleService := services.NewPresaleService()
+	allocationService := services.NewAllocationService(cfg)
+	orderService := services.NewOrderService(cfg)
+	installmentService := services.NewInstallmentService(cfg)
+	reconciliationService := services.NewReconciliationService(cfg)
+	auditService := services.NewAuditService()
+	elevationService := services.NewElevationService(cfg)
+	encryptionService := services.NewEncryptionService(cfg)
+	imageProxyService := services.NewImageProxyService(cfg)
+	usageService := services.NewUsageService()
+	notificationService := services.NewNotificationService(cfg)
+	checkInStatsService := services.NewCheckInStatsService()
+	ticketService := services.NewTicketService(cfg, checkInStatsService)
+	checkInService := services.NewCheckInService(cfg, checkInStatsService)
+	scannerService := services.NewScannerService(cfg)
+	seatMapService := services.NewSeatMapService(cfg)
+	forecastService := services.NewForecastService(cfg)
+	warehouseService := services.NewWarehouseExportService(cfg)
+	archiveService := services.NewArchiveService(cfg)
+	publicStatsService := services.NewPublicStatsService(cfg)
+	franchiseService := services.NewFranchiseService()
+	tenantService := services.NewTenantService(cfg)
+	structuredDataService := services.NewStructuredDataService(cfg)
+	calendarService := services.NewCalendarService(cfg)
+	walletService := services.NewWalletService(cfg)
+	invitationService := services.NewInvitationService(cfg)
+	feedService := services.NewFeedService(cfg)
+	checkoutService := services.NewCheckoutService(cfg, orderService)
+	cartService := services.NewCartService(cfg, orderService)
+	bundleService := services.NewBundleService(orderService)
+	refundService := services.NewRefundService(orderService)
+	ticketPortalService := services.NewTicketPortalService(cfg)
+	usernameService := services.NewUsernameService()
+	avatarService := services.NewAvatarService(cfg)
+	accountMergeService := services.NewAccountMergeService()
+	eventTemplateService := services.NewEventTemplateService()
+	organizationRoleService := services.NewOrganizationRoleService()
+	emailService := services.NewEmailService(cfg)
+	newsletterService := services.NewNewsletterService(cfg)
+	confirmationEmailService := services.NewConfirmationEmailService(cfg)
+	internalNoteService := services.NewInternalNoteService()
+	segmentService := services.NewAttendeeSegmentService(cfg)
+	contactService := services.NewContactService()
+	apiKeyService := services.NewAPIKeyService()
+	scanAuditService := services.NewScanAuditService()
+	ticketRevocationService := services.NewTicketRevocationService(cfg)
+	bulkJobService := services.NewBulkJobService(cfg)
+
+	// Resolve the white-label tenant of every request
+	router.Use(middleware.ResolveTenant(tenantService))
+
+	// Evaluate inventory alerts whenever ticket inventory changes
+	services.RegisterInventoryHook(inventoryAlertService.EvaluateInventory)
+
+	// Rebuild the event feeds whenever an event is published, updated or deleted
+	services.RegisterEventHook(feedService.Invalidate)
+
+	// Deliver event.updated hooks to organizations subscribed to them
+	services.RegisterEventHook(services.NewIntegrationService(cfg).DispatchEventChanged)
 
 	// Initialize handlers
 	healthHandler := handlers.NewHealthHandler(healthService)
-	eventHandler := handlers.NewEventHandler(eventService)
+	eventHandler := handlers.NewEventHandler(eventService, usageService)
 	authHandler := handlers.NewAuthHandler(cfg)
+	oauthHandler := handlers.NewOAuthHandler(cfg)
 	organizationHandler := handlers.NewOrganizationHandler(cfg)
+	organizationRoleHandler := handlers.NewOrganizationRoleHandler(organizationRoleService)
+	integrationHandler := handlers.NewIntegrationHandler(cfg)
+	inventoryAlertHandler := handlers.NewInventoryAlertHandler(inventoryAlertService)
+	pricingHandler := handlers.NewPricingHandler(pricingService)
+	allocationHandler := handlers.NewAllocationHandler(allocationService)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	refundHandler := handlers.NewRefundHandler(refundService)
+	promoCodeHandler := handlers.NewPromoCodeHandler(promoCodeService)
+	presaleHandler := handlers.NewPresaleHandler(presaleService)
+	installmentHandler := handlers.NewInstallmentHandler(installmentService)
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	elevationHandler := handlers.NewElevationHandler(elevationService)
+	encryptionHandler := handlers.NewEncryptionHandler(encryptionService)
+	docsHandler := handlers.NewDocsHandler()
+	jwksHandler := handlers.NewJWKSHandler(cfg)
+	imageHandler := handlers.NewImageHandler(imageProxyService)
+	usageHandler := handlers.NewUsageHandler(usageService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	ticketHandler := handlers.NewTicketHandler(ticketService, checkInStatsService)
+	checkInHandler := handlers.NewCheckInHandler(checkInService)
+	scannerHandler := handlers.NewScannerHandler(scannerService)
+	seatMapHandler := handlers.NewSeatMapHandler(seatMapService)
+	forecastHandler := handlers.NewForecastHandler(forecastService)
+	warehouseHandler := handlers.NewWarehouseHandler(warehouseService)
+	archiveHandler := handlers.NewArchiveHandler(archiveService)
+	publicStatsHandler := handlers.NewPublicStatsHandler(publicStatsService)
+	franchiseHandler := handlers.NewFranchiseHandler(franchiseService)
+	tenantHandler := handlers.NewTenantHandler(tenantService)
+	structuredDataHandler := handlers.NewStructuredDataHandler(structuredDataService)
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	walletHandler := handlers.NewWalletHandler(walletService)
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	feedHandler := handlers.NewFeedHandler(feedService)
+	checkoutHandler := handlers.NewCheckoutHandler(checkoutService)
+	cartHandler := handlers.NewCartHandler(cartService)
+	bundleHandler := handlers.NewBundleHandler(bundleService)
+	usernameHandler := handlers.NewUsernameHandler(usernameService)
+	avatarHandler := handlers.NewAvatarHandler(avatarService)
+	accountMergeHandler := handlers.NewAccountMergeHandler(accountMergeService)
+	eventTemplateHandler := handlers.NewEventTemplateHandler(eventTemplateService)
+	ticketPortalHandler := handlers.NewTicketPortalHandler(ticketPortalService)
+	emailHandler := handlers.NewEmailHandler(emailService)
+	newsletterHandler := handlers.NewNewsletterHandler(newsletterService)
+	confirmationEmailHandler := handlers.NewConfirmationEmailHandler(confirmationEmailService)
+	internalNoteHandler := handlers.NewInternalNoteHandler(internalNoteService)
+	segmentHandler := handlers.NewAttendeeSegmentHandler(segmentService)
+	contactHandler := handlers.NewContactHandler(contactService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	scanAuditHandler := handlers.NewScanAuditHandler(scanAuditService)
+	ticketRevocationHandler := handlers.NewTicketRevocationHandler(ticketRevocationService)
+	bulkJobHandler := handlers.NewBulkJobHandler(bulkJobService)
 
 	// Health routes - single comprehensive endpoint
 	router.GET("/health", healthHandler.Health)
 
+	// Public keys for services validating access tokens on their own
+	router.GET("/.well-known/jwks.json", jwksHandler.GetJWKS)
+
 	// Swagger documentation - only available at /api/docs/ URL
 	router.GET("/api/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -59,6 +190,9 @@ func SetupRouter() *gin.Engine {
 		c.Redirect(http.StatusMovedPermanently, "/api/docs/index.html")
 	})
 
+	// OpenAPI 3 document for client SDK generation
+	router.GET("/api/openapi.json", docsHandler.OpenAPISpec)
+
 	// Test error handling endpoints (remove in production)
 	router.GET("/test/panic", func(c *gin.Context) {
 		panic("This is a test panic!")
@@ -75,6 +209,97 @@ func SetupRouter() *gin.Engine {
 		// Health route under API namespace
 		v1.GET("/health", healthHandler.Health)
 
+		// Anonymous platform-wide counters for the marketing site
+		v1.GET("/public/stats", publicStatsHandler.GetPublicStats)
+		v1.GET("/public/tenant", tenantHandler.GetTenantConfig)
+		v1.GET("/public/events/:id/jsonld", structuredDataHandler.GetEventJSONLD)
+		v1.GET("/public/events.atom", feedHandler.GetEventsFeed)
+		v1.GET("/public/users/:username", usernameHandler.GetPublicProfile)
+		v1.GET("/usernames/available", usernameHandler.CheckAvailability)
+
+		// Price breakdown shown before checkout
+		v1.POST("/orders/quote", orderHandler.QuoteOrder)
+
+		// Buyers' own orders
+		orders := v1.Group("/orders")
+		orders.Use(middleware.AuthMiddleware(cfg), middleware.ValidateUUIDParam("orderId"))
+		{
+			orders.POST("/:orderId/resend-tickets", orderHandler.ResendTickets)
+			orders.POST("/:orderId/refunds", refundHandler.RequestRefund)
+			orders.GET("/:orderId/refunds", refundHandler.ListOrderRefunds)
+		}
+
+		// Checkouts in progress, resumable by token; signing in is optional
+		checkout := v1.Group("/checkout")
+		checkout.Use(middleware.GetUserFromToken(cfg))
+		{
+			checkout.POST("/sessions", checkoutHandler.StartCheckout)
+			checkout.GET("/sessions/:token", checkoutHandler.ResumeCheckout)
+			checkout.PUT("/sessions/:token", checkoutHandler.UpdateCheckout)
+			checkout.PUT("/sessions/:token/seats", checkoutHandler.HoldCheckoutSeats)
+		}
+
+		// Organization invitations, opened from the emailed link without an account
+		invitations := v1.Group("/invitations")
+		{
+			invitations.GET("/:token", invitationHandler.GetInvitation)
+			invitations.POST("/:token/accept", invitationHandler.AcceptInvitation)
+			invitations.POST("/:token/decline", invitationHandler.DeclineInvitation)
+		}
+
+		// Carts holding tickets for a signed-in buyer until checkout or expiry
+		carts := v1.Group("/carts")
+		carts.Use(middleware.AuthMiddleware(cfg), middleware.ValidateUUIDParam("cartId"))
+		{
+			carts.POST("", cartHandler.CreateCart)
+			carts.GET("/:cartId", cartHandler.GetCart)
+			carts.DELETE("/:cartId", cartHandler.ReleaseCart)
+			carts.POST("/:cartId/checkout", cartHandler.CheckoutCart)
+		}
+
+		// Bundles of several events at a combined price, such as festival passes
+		bundles := v1.Group("/bundles/:bundleId")
+		bundles.Use(middleware.ValidateUUIDParam("bundleId"))
+		{
+			bundles.GET("", bundleHandler.GetBundle)
+			bundles.POST("/purchase", middleware.AuthMiddleware(cfg), bundleHandler.PurchaseBundle)
+		}
+
+		// Self-service ticket pages linked from ticket emails; the token grants access without signing in
+		portal := v1.Group("/tickets/manage/:token")
+		{
+			portal.GET("", ticketPortalHandler.GetTicket)
+			portal.PUT("/attendee", ticketPortalHandler.UpdateAttendee)
+			portal.POST("/resend", ticketPortalHandler.ResendEmail)
+			portal.GET("/calendar.ics", ticketPortalHandler.GetCalendar)
+		}
+
+		// Open, click and unsubscribe links of newsletter copies; the token identifies the recipient
+		newsletterTracking := v1.Group("/newsletters/t/:token")
+		{
+			newsletterTracking.GET("/open.gif", newsletterHandler.TrackOpen)
+			newsletterTracking.GET("/click/:link", newsletterHandler.TrackClick)
+			newsletterTracking.GET("/unsubscribe", newsletterHandler.Unsubscribe)
+			newsletterTracking.POST("/unsubscribe", newsletterHandler.Unsubscribe)
+		}
+
+		// Open and click links of tracked reminder emails
+		emailTracking := v1.Group("/emails/t/:token")
+		{
+			emailTracking.GET("/open.gif", notificationHandler.TrackEmailOpen)
+			emailTracking.GET("/click", notificationHandler.TrackEmailClick)
+		}
+
+		// Door scanners validating one ticket QR code at a time; access is checked against the ticket's organization
+		v1.POST("/tickets/validate", middleware.AuthMiddleware(cfg), middleware.AnyRoleRequired("admin", "organizer", "manager", "staff"), ticketHandler.ValidateTicket)
+
+		// Printable and phone wallet tickets of the signed-in user
+		v1.GET("/tickets/:id/pdf", middleware.AuthMiddleware(cfg), middleware.ValidateUUIDParam("id"), ticketHandler.DownloadTicketPDF)
+		v1.GET("/tickets/:id/wallet/:provider", middleware.AuthMiddleware(cfg), middleware.ValidateUUIDParam("id"), walletHandler.GetWalletPass)
+
+		// Scanner devices exchanging a pairing code for their event-scoped device token
+		v1.POST("/scanner/pair", middleware.StrictRateLimiter(), scannerHandler.Pair)
+
 		// Auth routes (public)
 		auth := v1.Group("/auth")
 		{
@@ -82,6 +307,11 @@ func SetupRouter() *gin.Engine {
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 
+			// Social login; Apple posts its callback as a form
+			auth.GET("/oauth/:provider/start", oauthHandler.StartOAuth)
+			auth.GET("/oauth/:provider/callback", oauthHandler.OAuthCallback)
+			auth.POST("/oauth/:provider/callback", oauthHandler.OAuthCallback)
+
 			// Sensitive auth operations use stricter rate limiting
 			sensitiveAuth := auth.Group("")
 			// uncomment when StrictRateLimiter is implemented
@@ -94,6 +324,9 @@ func SetupRouter() *gin.Engine {
 				// OTP-based verification endpoints
 				sensitiveAuth.POST("/verify-otp", authHandler.VerifyOTP)
 				sensitiveAuth.POST("/send-otp", authHandler.SendOTP)
+
+				// One-click link from new-device login alerts
+				sensitiveAuth.GET("/not-me", authHandler.ReportUnrecognizedLogin)
 			}
 
 			// Protected auth routes
@@ -103,16 +336,37 @@ func SetupRouter() *gin.Engine {
 				authProtected.POST("/logout", authHandler.Logout)
 				authProtected.GET("/profile", authHandler.GetProfile)
 				authProtected.PUT("/profile", authHandler.UpdateProfile)
+				authProtected.PATCH("/profile", authHandler.PatchProfile)
+				authProtected.PUT("/username", usernameHandler.ChangeUsername)
+				authProtected.GET("/username/history", usernameHandler.GetUsernameHistory)
+				authProtected.PUT("/avatar", avatarHandler.UploadAvatar)
+				authProtected.DELETE("/avatar", avatarHandler.DeleteAvatar)
 				authProtected.POST("/change-password", authHandler.ChangePassword)
+				authProtected.GET("/devices", authHandler.ListDevices)
 			}
 		}
 
+		// Caller's own rate limit, usage, WhatsApp opt-in and newsletter subscription
+		me := v1.Group("/me")
+		me.Use(middleware.AuthMiddleware(cfg))
+		{
+			me.GET("/limits", usageHandler.GetLimits)
+			me.GET("/whatsapp", notificationHandler.GetWhatsAppOptIn)
+			me.PUT("/whatsapp", notificationHandler.OptInWhatsApp)
+			me.DELETE("/whatsapp", notificationHandler.OptOutWhatsApp)
+			me.GET("/newsletter", newsletterHandler.GetNewsletterPreference)
+			me.PUT("/newsletter", newsletterHandler.UpdateNewsletterPreference)
+		}
+
 		// Event routes
 		events := v1.Group("/events")
 		{
 			// Public event routes
-			events.GET("", eventHandler.GetAllEvents)
+			events.GET("", middleware.ValidateQuery(&models.EventFilterQuery{}), eventHandler.GetAllEvents)
 			events.GET("/:id", eventHandler.GetEventByID)
+			events.GET("/:id/pricing", pricingHandler.GetPricingPreview)
+			events.GET("/:id/seats", seatMapHandler.GetEventSeats)
+			events.GET("/:id/calendar.ics", calendarHandler.GetEventCalendar)
 
 			// Protected event routes
 			eventsProtected := events.Group("")
@@ -121,27 +375,282 @@ func SetupRouter() *gin.Engine {
 				// Events can be created by organizers and admins
 				eventsProtected.POST("", middleware.IsOrganizer(), eventHandler.CreateEvent)
 				eventsProtected.PUT("/:id", middleware.IsOrganizer(), eventHandler.UpdateEvent)
-				eventsProtected.DELETE("/:id", middleware.IsAdmin(), eventHandler.DeleteEvent)
+				eventsProtected.DELETE("/:id", middleware.IsAdmin(), middleware.ElevationRequired(elevationService), eventHandler.DeleteEvent)
+
+				// Autosaved drafts from the organizer UI, validated when published
+				eventsProtected.POST("/drafts", middleware.IsOrganizer(), eventHandler.CreateEventDraft)
+				eventsProtected.GET("/:id/draft", middleware.IsOrganizer(), eventHandler.GetEventDraft)
+				eventsProtected.PATCH("/:id/draft", middleware.IsOrganizer(), eventHandler.SaveEventDraft)
+				eventsProtected.POST("/:id/publish", middleware.IsOrganizer(), eventHandler.PublishEventDraft)
+
+				// Lifecycle: draft -> published <-> paused -> cancelled or completed
+				eventsProtected.POST("/:id/pause", middleware.IsOrganizer(), eventHandler.PauseEvent)
+				eventsProtected.POST("/:id/cancel", middleware.IsOrganizer(), eventHandler.CancelEvent)
+				eventsProtected.POST("/:id/complete", middleware.IsOrganizer(), eventHandler.CompleteEvent)
+
+				// Content blocks appended to the event's ticket confirmation emails
+				eventsProtected.GET("/:id/confirmation-email", middleware.IsOrganizer(), confirmationEmailHandler.GetConfirmationEmail)
+				eventsProtected.PUT("/:id/confirmation-email", middleware.IsOrganizer(), confirmationEmailHandler.UpdateConfirmationEmail)
+				eventsProtected.POST("/:id/confirmation-email/preview", middleware.IsOrganizer(), confirmationEmailHandler.PreviewConfirmationEmail)
+
+				// Change history with field-level diffs and rollback
+				eventsProtected.GET("/:id/history", middleware.IsOrganizer(), eventHandler.GetEventHistory)
+				eventsProtected.POST("/:id/history/:version/rollback", middleware.IsOrganizer(), eventHandler.RollbackEvent)
+
+				// Sell-out and attendance forecast for organizer planning
+				eventsProtected.GET("/:id/forecast", middleware.IsOrganizer(), forecastHandler.GetEventForecast)
+
+				// Pairing door scanner devices to the organizer's event
+				eventsProtected.POST("/:id/scanner-pairings", middleware.IsOrganizer(), scannerHandler.CreatePairing)
+				eventsProtected.GET("/:id/scanner-devices", middleware.IsOrganizer(), scannerHandler.ListDevices)
+				eventsProtected.DELETE("/:id/scanner-devices/:deviceId", middleware.IsOrganizer(), middleware.ValidateUUIDParam("deviceId"), scannerHandler.RevokeDevice)
+
+				// Reserved seating from one of the organizer's venues
+				eventsProtected.PUT("/:id/seat-map", middleware.IsOrganizer(), seatMapHandler.AttachSeatMap)
+			}
+
+			// Door check-ins by staff with the ticket scan permission, or by scanners paired to the event
+			checkIns := events.Group("/:id/checkins")
+			checkIns.Use(middleware.ScannerOrStaffAuth(cfg, scannerService))
+			{
+				checkIns.POST("", checkInHandler.CheckIn)
+				checkIns.DELETE("/:ticketId", middleware.ValidateUUIDParam("ticketId"), checkInHandler.UndoCheckIn)
+				checkIns.GET("/stats", checkInHandler.GetStats)
 			}
 		}
 
+		// Image routes (public, linked from emails and wallet passes)
+		images := v1.Group("/images")
+		{
+			images.GET("/organizations/:id/logo", middleware.ValidateUUIDParam("id"), imageHandler.GetOrganizationLogo)
+			images.GET("/events/:id/cover", imageHandler.GetEventCover)
+			images.GET("/users/:id/avatar", middleware.ValidateUUIDParam("id"), avatarHandler.GetUserAvatar)
+		}
+
 		// Organization routes
 		organizations := v1.Group("/organizations")
 		organizations.Use(middleware.AuthMiddleware(cfg))
 		{
 			// Basic organization operations
 			organizations.GET("", organizationHandler.GetUserOrganizations)
-			organizations.GET("/:id", organizationHandler.GetOrganizationByID)
+			organizations.GET("/:id", middleware.ValidateUUIDParam("id"), organizationHandler.GetOrganizationByID)
 
 			// Organization user management (only organizers can manage their organization)
 			orgProtected := organizations.Group("/:id")
-			orgProtected.Use(middleware.IsOrganizerOfOrganization())
+			orgProtected.Use(
+				middleware.ValidateUUIDParam("id", "userId", "roleId", "deviceId", "venueId", "webhookId", "alertId",
+					"ruleId", "allocationId", "franchiseEventId", "templateId", "exportId", "invitationId", "apiKeyId"),
+				middleware.IsOrganizerOfOrganization(),
+				middleware.OrganizationTenant(),
+			)
 			{
 				// Endpoints for organizers to manage their organization users
 				orgProtected.POST("/users", organizationHandler.CreateOrganizationUser)
 				orgProtected.GET("/users", organizationHandler.GetOrganizationUsers)
 				orgProtected.PUT("/users/:userId", organizationHandler.UpdateOrganizationUser)
 				orgProtected.DELETE("/users/:userId", organizationHandler.DeleteOrganizationUser)
+				orgProtected.POST("/members", organizationHandler.AddOrganizationMember)
+
+				// Invitations letting staff and managers join with a password they choose
+				orgProtected.POST("/invitations", invitationHandler.CreateInvitation)
+				orgProtected.GET("/invitations", invitationHandler.ListInvitations)
+				orgProtected.DELETE("/invitations/:invitationId", invitationHandler.RevokeInvitation)
+
+				// Sub-organizations and roll-up analytics
+				orgProtected.GET("/children", organizationHandler.GetChildOrganizations)
+
+				// Custom roles composed from assignable permissions and assigned to members
+				orgProtected.GET("/permissions", organizationRoleHandler.ListAssignablePermissions)
+				orgProtected.GET("/roles", organizationRoleHandler.ListRoles)
+				orgProtected.POST("/roles", organizationRoleHandler.CreateRole)
+				orgProtected.PUT("/roles/:roleId", organizationRoleHandler.UpdateRole)
+				orgProtected.DELETE("/roles/:roleId", organizationRoleHandler.DeleteRole)
+				orgProtected.POST("/roles/:roleId/members", organizationRoleHandler.AssignRoleMember)
+				orgProtected.DELETE("/roles/:roleId/members/:userId", organizationRoleHandler.UnassignRoleMember)
+
+				// Door check-in from ticket scanners
+				orgProtected.POST("/tickets/validate/batch", ticketHandler.ValidateTicketBatch)
+				orgProtected.GET("/events/:eventId/check-ins/live", ticketHandler.StreamCheckInStats)
+
+				// Scanner devices paired to the organization's events
+				orgProtected.GET("/scanner-devices", scannerHandler.ListOrganizationDevices)
+				orgProtected.PATCH("/scanner-devices/:deviceId", scannerHandler.RenameDevice)
+				orgProtected.DELETE("/scanner-devices/:deviceId", scannerHandler.RevokeOrganizationDevice)
+
+				// Venues with reserved seating, attached to events as seat maps
+				orgProtected.POST("/venues", seatMapHandler.CreateVenue)
+				orgProtected.GET("/venues", seatMapHandler.ListVenues)
+				orgProtected.GET("/venues/:venueId", seatMapHandler.GetVenue)
+
+				// API keys for integrators calling the organization's endpoints without a user token
+				orgProtected.GET("/api-keys", apiKeyHandler.ListAPIKeys)
+				orgProtected.POST("/api-keys", apiKeyHandler.CreateAPIKey)
+				orgProtected.POST("/api-keys/:apiKeyId/rotate", apiKeyHandler.RotateAPIKey)
+				orgProtected.DELETE("/api-keys/:apiKeyId", apiKeyHandler.RevokeAPIKey)
+
+				// Marketing contact sync (Mailchimp, Brevo)
+				orgProtected.GET("/integrations/marketing", integrationHandler.GetMarketingIntegration)
+				orgProtected.PUT("/integrations/marketing", integrationHandler.ConfigureMarketingIntegration)
+				orgProtected.DELETE("/integrations/marketing", integrationHandler.DeleteMarketingIntegration)
+				orgProtected.POST("/integrations/marketing/sync", integrationHandler.SyncMarketingContacts)
+
+				// Slack/Discord sale notifications
+				orgProtected.GET("/integrations/chat-webhooks", integrationHandler.ListChatWebhooks)
+				orgProtected.POST("/integrations/chat-webhooks", integrationHandler.CreateChatWebhook)
+				orgProtected.PUT("/integrations/chat-webhooks/:webhookId", integrationHandler.UpdateChatWebhook)
+				orgProtected.DELETE("/integrations/chat-webhooks/:webhookId", integrationHandler.DeleteChatWebhook)
+				orgProtected.POST("/integrations/chat-webhooks/:webhookId/test", integrationHandler.TestChatWebhook)
+
+				// Sold-out and low-inventory alerts
+				orgProtected.GET("/inventory-alerts", inventoryAlertHandler.ListAlerts)
+				orgProtected.POST("/inventory-alerts", inventoryAlertHandler.CreateAlert)
+				orgProtected.DELETE("/inventory-alerts/:alertId", inventoryAlertHandler.DeleteAlert)
+
+				// Dynamic pricing rules
+				orgProtected.GET("/pricing-rules", pricingHandler.ListPricingRules)
+				orgProtected.POST("/pricing-rules", pricingHandler.CreatePricingRule)
+				orgProtected.DELETE("/pricing-rules/:ruleId", pricingHandler.DeletePricingRule)
+
+				// Hold and comp allocations
+				orgProtected.GET("/allocations", allocationHandler.ListAllocations)
+				orgProtected.POST("/allocations", allocationHandler.CreateAllocation)
+				orgProtected.POST("/allocations/:allocationId/issue", allocationHandler.IssueComps)
+				orgProtected.POST("/allocations/:allocationId/release", allocationHandler.ReleaseAllocation)
+				orgProtected.POST("/bulk/comps", bulkJobHandler.BulkIssueComps)
+
+				// Franchise template events
+				orgProtected.POST("/franchise/push", franchiseHandler.PushFranchiseEvent)
+				orgProtected.GET("/franchise/events", franchiseHandler.ListFranchiseEvents)
+				orgProtected.PUT("/franchise/events/:franchiseEventId", franchiseHandler.LocalizeFranchiseEvent)
+				orgProtected.POST("/franchise/events/:franchiseEventId/publish", franchiseHandler.PublishFranchiseEvent)
+
+				// Reusable event templates
+				orgProtected.GET("/templates", eventTemplateHandler.ListEventTemplates)
+				orgProtected.POST("/templates", eventTemplateHandler.SaveEventTemplate)
+				orgProtected.DELETE("/templates/:templateId", eventTemplateHandler.DeleteEventTemplate)
+				orgProtected.POST("/templates/:templateId/instantiate", eventTemplateHandler.InstantiateEventTemplate)
+
+				// Accounting exports
+				orgProtected.POST("/accounting/exports", integrationHandler.CreateAccountingExport)
+				orgProtected.GET("/accounting/exports", integrationHandler.ListAccountingExports)
+				orgProtected.GET("/accounting/exports/:exportId", integrationHandler.GetAccountingExport)
+				orgProtected.GET("/accounting/exports/:exportId/download", integrationHandler.DownloadAccountingExport)
+			}
+
+			// Operations members may perform through their role in the organization or custom organization
+			// roles, and integrators through the organization's API keys scoped to them
+			orgMembers := v1.Group("/organizations/:id")
+			orgMembers.Use(middleware.APIKeyOrAuth(cfg, apiKeyService), middleware.ValidateUUIDParam("id", "orderId", "ticketId", "refundId", "promoCodeId", "hookId", "noteId", "segmentId", "bundleId", "mergeId", "jobId", "presaleId", "codeId"), middleware.OrganizationTenant())
+			{
+				permission := func(resource, action string) gin.HandlerFunc {
+					return middleware.OrganizationPermissionRequired(organizationRoleService, resource, action)
+				}
+
+				// Roll-up analytics across sub-organizations
+				orgMembers.GET("/analytics/rollup", permission("analytics", "read"), organizationHandler.GetOrganizationRollup)
+
+				// Delivery, opens and clicks of the notifications sent for the organization's orders
+				orgMembers.GET("/analytics/notifications", permission("analytics", "read"), notificationHandler.GetNotificationStats)
+
+				// Events the organization runs
+				orgMembers.GET("/events", permission("events", "read"), eventHandler.ListOrganizationEvents)
+
+				// Orders placed by staff on behalf of attendees
+				orgMembers.POST("/orders", permission("orders", "create"), orderHandler.CreateStaffOrder)
+				orgMembers.POST("/orders/:orderId/mark-paid", permission("payments", "manage"), orderHandler.MarkOrderPaid)
+				orgMembers.POST("/orders/:orderId/cancel", permission("orders", "update"), orderHandler.CancelOrder)
+				orgMembers.GET("/orders/:orderId/notifications", permission("orders", "read"), orderHandler.ListOrderNotifications)
+				orgMembers.GET("/orders/insurance-quote", permission("orders", "create"), orderHandler.QuoteInsurance)
+
+				// Refunds requested by buyers or staff and approved by the organization
+				orgMembers.POST("/orders/:orderId/refunds", permission("refunds", "create"), refundHandler.RequestStaffRefund)
+				orgMembers.GET("/refunds", permission("orders", "read"), refundHandler.ListRefunds)
+				orgMembers.POST("/refunds/:refundId/approve", permission("refunds", "approve"), refundHandler.ApproveRefund)
+				orgMembers.POST("/refunds/:refundId/reject", permission("refunds", "approve"), refundHandler.RejectRefund)
+				orgMembers.POST("/refunds/:refundId/process", permission("refunds", "approve"), refundHandler.ProcessRefund)
+
+				// Promo codes applied at checkout
+				orgMembers.GET("/promo-codes", permission("promo_codes", "read"), promoCodeHandler.ListPromoCodes)
+				orgMembers.POST("/promo-codes", permission("promo_codes", "manage"), promoCodeHandler.CreatePromoCode)
+				orgMembers.GET("/promo-codes/:promoCodeId", permission("promo_codes", "read"), promoCodeHandler.GetPromoCode)
+				orgMembers.PUT("/promo-codes/:promoCodeId", permission("promo_codes", "manage"), promoCodeHandler.UpdatePromoCode)
+				orgMembers.DELETE("/promo-codes/:promoCodeId", permission("promo_codes", "manage"), promoCodeHandler.DeletePromoCode)
+
+				// Presale windows open to access code holders and allowlisted buyers
+				orgMembers.GET("/presales", permission("presales", "read"), middleware.ValidateQuery(&models.PresaleQuery{}), presaleHandler.ListPresales)
+				orgMembers.POST("/presales", permission("presales", "manage"), presaleHandler.CreatePresale)
+				orgMembers.GET("/presales/:presaleId", permission("presales", "read"), presaleHandler.GetPresale)
+				orgMembers.PUT("/presales/:presaleId", permission("presales", "manage"), presaleHandler.UpdatePresale)
+				orgMembers.DELETE("/presales/:presaleId", permission("presales", "manage"), presaleHandler.DeletePresale)
+				orgMembers.POST("/presales/:presaleId/codes", permission("presales", "manage"), presaleHandler.CreatePresaleAccessCode)
+				orgMembers.DELETE("/presales/:presaleId/codes/:codeId", permission("presales", "manage"), presaleHandler.DeletePresaleAccessCode)
+				orgMembers.GET("/presales/:presaleId/allowlist", permission("presales", "read"), middleware.ValidateQuery(&models.PresaleAllowlistQuery{}), presaleHandler.ListPresaleAllowlist)
+				orgMembers.POST("/presales/:presaleId/allowlist", permission("presales", "manage"), presaleHandler.AddToPresaleAllowlist)
+				orgMembers.DELETE("/presales/:presaleId/allowlist", permission("presales", "manage"), presaleHandler.RemoveFromPresaleAllowlist)
+				orgMembers.GET("/analytics/presales", permission("analytics", "read"), middleware.ValidateQuery(&models.PresaleAnalyticsQuery{}), presaleHandler.GetPresaleAnalytics)
+
+				// Installment payment plans
+				orgMembers.POST("/orders/:orderId/installment-plan", permission("payments", "manage"), installmentHandler.CreatePlan)
+				orgMembers.GET("/orders/:orderId/installments", permission("payments", "read"), installmentHandler.GetPlan)
+
+				// Integration triggers for no-code platforms (Zapier, Make)
+				orgMembers.GET("/integrations/orders", permission("orders", "read"), integrationHandler.PollNewOrders)
+				orgMembers.GET("/integrations/attendees", permission("tickets", "read"), integrationHandler.PollNewAttendees)
+
+				// Webhook subscriptions for organizers and no-code platforms (Zapier, Make)
+				orgMembers.GET("/integrations/hooks", permission("webhooks", "read"), integrationHandler.ListHooks)
+				orgMembers.POST("/integrations/hooks", permission("webhooks", "manage"), integrationHandler.SubscribeHook)
+				orgMembers.DELETE("/integrations/hooks/:hookId", permission("webhooks", "manage"), integrationHandler.UnsubscribeHook)
+				orgMembers.POST("/integrations/hooks/:hookId/rotate-secret", permission("webhooks", "manage"), integrationHandler.RotateHookSecret)
+				orgMembers.GET("/integrations/hooks/deliveries", permission("webhooks", "read"), middleware.ValidateQuery(&models.HookDeliveryQuery{}), integrationHandler.ListHookDeliveries)
+
+				// Internal notes and tags on orders and attendees, never shown to buyers or attendees
+				orgMembers.GET("/orders/:orderId/annotations", permission("notes", "read"), internalNoteHandler.GetOrderAnnotations)
+				orgMembers.POST("/orders/:orderId/notes", permission("notes", "manage"), internalNoteHandler.AddOrderNote)
+				orgMembers.PUT("/orders/:orderId/tags", permission("notes", "manage"), internalNoteHandler.SetOrderTags)
+				orgMembers.GET("/attendees/:ticketId/annotations", permission("notes", "read"), internalNoteHandler.GetAttendeeAnnotations)
+				orgMembers.POST("/attendees/:ticketId/notes", permission("notes", "manage"), internalNoteHandler.AddAttendeeNote)
+				orgMembers.PUT("/attendees/:ticketId/tags", permission("notes", "manage"), internalNoteHandler.SetAttendeeTags)
+				orgMembers.DELETE("/notes/:noteId", permission("notes", "manage"), internalNoteHandler.DeleteNote)
+				orgMembers.GET("/annotations", permission("notes", "read"), middleware.ValidateQuery(&models.InternalAnnotationQuery{}), internalNoteHandler.SearchAnnotations)
+
+				// Event bundles and festival passes
+				orgMembers.GET("/bundles", permission("events", "read"), bundleHandler.ListBundles)
+				orgMembers.POST("/bundles", permission("events", "update"), bundleHandler.CreateBundle)
+				orgMembers.PUT("/bundles/:bundleId", permission("events", "update"), bundleHandler.UpdateBundle)
+
+				// Saved attendee segments for exports, marketing syncs and comp issuance
+				orgMembers.GET("/segments", permission("segments", "read"), segmentHandler.ListSegments)
+				orgMembers.POST("/segments", permission("segments", "manage"), segmentHandler.CreateSegment)
+				orgMembers.POST("/segments/preview", permission("segments", "read"), segmentHandler.PreviewSegment)
+				orgMembers.GET("/segments/:segmentId", permission("segments", "read"), segmentHandler.GetSegment)
+				orgMembers.PUT("/segments/:segmentId", permission("segments", "manage"), segmentHandler.UpdateSegment)
+				orgMembers.DELETE("/segments/:segmentId", permission("segments", "manage"), segmentHandler.DeleteSegment)
+				orgMembers.GET("/segments/:segmentId/attendees", permission("segments", "read"), middleware.ValidateQuery(&models.PageQuery{}), segmentHandler.ListSegmentAttendees)
+				orgMembers.GET("/segments/:segmentId/export", permission("segments", "read"), segmentHandler.ExportSegment)
+				orgMembers.POST("/segments/:segmentId/marketing-sync", permission("segments", "manage"), segmentHandler.SyncSegmentMarketing)
+
+				// Audit trail of door scans and the suspicious patterns in it
+				orgMembers.GET("/scans", permission("tickets", "read"), middleware.ValidateQuery(&models.ScanAttemptQuery{}), scanAuditHandler.ListScanAttempts)
+				orgMembers.GET("/scans/anomalies", permission("tickets", "read"), middleware.ValidateQuery(&models.ScanAnomalyQuery{}), scanAuditHandler.GetScanAnomalies)
+
+				// Revoked tickets refused at the door, e.g. after a chargeback
+				orgMembers.POST("/tickets/:ticketId/revoke", permission("tickets", "update"), ticketRevocationHandler.RevokeTicket)
+				orgMembers.POST("/tickets/:ticketId/reinstate", permission("tickets", "update"), ticketRevocationHandler.ReinstateTicket)
+				orgMembers.GET("/ticket-revocations", permission("tickets", "read"), middleware.ValidateQuery(&models.TicketRevocationQuery{}), ticketRevocationHandler.ListTicketRevocations)
+
+				// Bulk operations run in the background, and the jobs reporting their progress
+				orgMembers.POST("/bulk/tickets/revoke", permission("tickets", "update"), bulkJobHandler.BulkRevokeTickets)
+				orgMembers.POST("/bulk/tickets/resend", permission("tickets", "update"), bulkJobHandler.BulkResendTickets)
+				orgMembers.GET("/jobs", permission("tickets", "read"), middleware.ValidateQuery(&models.BulkJobQuery{}), bulkJobHandler.ListBulkJobs)
+				orgMembers.GET("/jobs/:jobId", permission("tickets", "read"), bulkJobHandler.GetBulkJob)
+
+				// Unique contacts across the organization's events
+				orgMembers.GET("/contacts", permission("contacts", "read"), middleware.ValidateQuery(&models.ContactQuery{}), contactHandler.ListContacts)
+				orgMembers.GET("/contacts/export", permission("contacts", "read"), middleware.ValidateQuery(&models.ContactQuery{}), contactHandler.ExportContacts)
+				orgMembers.GET("/contacts/merges", permission("contacts", "read"), contactHandler.ListContactMerges)
+				orgMembers.POST("/contacts/merges", permission("contacts", "manage"), contactHandler.MergeContacts)
+				orgMembers.DELETE("/contacts/merges/:mergeId", permission("contacts", "manage"), contactHandler.UnmergeContact)
 			}
 
 			// Admin-only operations
@@ -149,10 +658,84 @@ func SetupRouter() *gin.Engine {
 			adminOrgRoutes.Use(middleware.IsAdmin())
 			{
 				adminOrgRoutes.POST("", organizationHandler.CreateOrganization)
-				adminOrgRoutes.PUT("/:id", organizationHandler.UpdateOrganization)
-				adminOrgRoutes.DELETE("/:id", organizationHandler.DeleteOrganization)
+				adminOrgRoutes.PUT("/:id", middleware.ValidateUUIDParam("id"), organizationHandler.UpdateOrganization)
+				adminOrgRoutes.DELETE("/:id", middleware.ValidateUUIDParam("id"), middleware.ElevationRequired(elevationService), organizationHandler.DeleteOrganization)
 			}
 		}
+
+		// Platform administration routes; auditors can read them
+		admin := v1.Group("/admin")
+		admin.Use(
+			middleware.AdminIPAllowlist(cfg.Security.AdminIPAllowlist, auditService),
+			middleware.AuthMiddleware(cfg),
+			middleware.LoadCurrentUser(), // Tokens of deleted accounts stop working right away
+			middleware.IsAdminOrAuditor(),
+			middleware.ValidateUUIDParam("reportId", "orderId", "adjustmentId", "elevationId", "tenantId", "newsletterId", "archiveId"),
+		)
+		{
+			// Payment provider reconciliation
+			admin.GET("/reconciliation/reports", reconciliationHandler.ListReports)
+			admin.POST("/reconciliation/reports", reconciliationHandler.RunReconciliation)
+			admin.GET("/reconciliation/reports/:reportId", reconciliationHandler.GetReport)
+
+			// Manual order adjustments with second-admin approval
+			admin.POST("/orders/:orderId/adjustments", orderHandler.RequestOrderAdjustment)
+			admin.GET("/orders/:orderId/adjustments", orderHandler.ListOrderAdjustments)
+			admin.GET("/adjustments", orderHandler.ListAdjustments)
+			admin.POST("/adjustments/:adjustmentId/approve", orderHandler.ApproveAdjustment)
+			admin.POST("/adjustments/:adjustmentId/reject", orderHandler.RejectAdjustment)
+
+			// Support resend of lost ticket emails
+			admin.POST("/orders/:orderId/resend-tickets", orderHandler.AdminResendTickets)
+
+			// Audit log of privileged actions
+			admin.GET("/audit-logs", middleware.PermissionRequired("audit_logs", "read"), auditHandler.ListAuditLogs)
+
+			// Break-glass elevation required by destructive actions
+			admin.POST("/elevations", elevationHandler.RequestElevation)
+			admin.GET("/elevations", elevationHandler.ListElevations)
+			admin.POST("/elevations/:elevationId/approve", elevationHandler.ApproveElevation)
+			admin.POST("/elevations/:elevationId/reject", elevationHandler.RejectElevation)
+			admin.POST("/elevations/:elevationId/token", elevationHandler.IssueElevatedToken)
+			admin.DELETE("/elevations/:elevationId", elevationHandler.RevokeElevation)
+
+			// OTP issuance monitoring
+			admin.GET("/otp/metrics", authHandler.GetOTPMetrics)
+
+			// Re-encryption of personal data after a key rotation
+			admin.POST("/encryption/rotate", middleware.ElevationRequired(elevationService), encryptionHandler.RotateKeys)
+
+			// Manifest of the data warehouse exports
+			admin.GET("/warehouse/partitions", warehouseHandler.ListWarehousePartitions)
+
+			// Archival of past events' orders and tickets out of the hot tables
+			admin.GET("/archives", middleware.ValidateQuery(&models.EventArchiveQuery{}), archiveHandler.ListArchives)
+			admin.GET("/archives/:archiveId", archiveHandler.GetArchive)
+			admin.GET("/archives/:archiveId/records", middleware.ValidateQuery(&models.ArchivedRecordQuery{}), archiveHandler.ListArchivedRecords)
+			admin.POST("/archives/:archiveId/restore", archiveHandler.RestoreArchive)
+			admin.POST("/events/:id/archive", middleware.ElevationRequired(elevationService), archiveHandler.ArchiveEvent)
+
+			// White-label tenants
+			admin.GET("/tenants", tenantHandler.ListTenants)
+			admin.POST("/tenants", tenantHandler.CreateTenant)
+			admin.PUT("/tenants/:tenantId", tenantHandler.UpdateTenant)
+			admin.DELETE("/tenants/:tenantId", middleware.ElevationRequired(elevationService), tenantHandler.DeleteTenant)
+
+			// Merging duplicate accounts of the same person
+			admin.POST("/users/merge", middleware.ElevationRequired(elevationService), accountMergeHandler.MergeAccounts)
+
+			// Checking email templates before they are used
+			admin.POST("/emails/test-send", emailHandler.SendTestEmail)
+
+			// Newsletters to segments of users, sent now or on a schedule
+			admin.GET("/newsletters", middleware.ValidateQuery(&models.NewsletterListQuery{}), newsletterHandler.ListNewsletters)
+			admin.POST("/newsletters", newsletterHandler.CreateNewsletter)
+			admin.GET("/newsletters/:newsletterId", newsletterHandler.GetNewsletter)
+			admin.PUT("/newsletters/:newsletterId", newsletterHandler.UpdateNewsletter)
+			admin.DELETE("/newsletters/:newsletterId", newsletterHandler.DeleteNewsletter)
+			admin.POST("/newsletters/:newsletterId/schedule", newsletterHandler.ScheduleNewsletter)
+			admin.POST("/newsletters/:newsletterId/cancel", newsletterHandler.CancelNewsletter)
+		}
 	}
 
 	return router
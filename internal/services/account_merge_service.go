@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditAccountsMerged is the audit action recorded for an account merge
+const AuditAccountsMerged = "user.merged"
+
+// errMergeDryRun rolls back the transaction of a dry run merge
+var errMergeDryRun = errors.New("dry run")
+
+// AccountMergeService merges duplicate accounts of the same person, such as one registered with a
+// work and one with a personal email. Everything the duplicate owns moves to the surviving
+// account and the duplicate is deleted, all in one transaction.
+type AccountMergeService struct {
+	db *gorm.DB
+}
+
+// NewAccountMergeService creates a new account merge service
+func NewAccountMergeService() *AccountMergeService {
+	return &AccountMergeService{
+		db: database.DB,
+	}
+}
+
+// Merge moves the source account's orders, tickets, checkout sessions, events, organization
+// memberships, roles, sessions, devices, username and avatar to the target account and deletes the
+// source. A dry run performs the same statements and rolls them back, so its counts are exactly
+// what the merge would move.
+func (s *AccountMergeService) Merge(ctx context.Context, adminID uuid.UUID, req *models.MergeAccountsRequest) (*models.AccountMergeResult, error) {
+	if req.SourceUserID == req.TargetUserID {
+		return nil, errors.New("An account cannot be merged into itself")
+	}
+
+	result := &models.AccountMergeResult{SourceUserID: req.SourceUserID, TargetUserID: req.TargetUserID, DryRun: req.DryRun}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Orders, tickets and checkout sessions belong to organizations; the merge spans them all
+		all := database.SkipTenancy(tx)
+
+		var source, target models.User
+		if err := tx.Preload("Roles").First(&source, "id = ?", req.SourceUserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Source account not found")
+			}
+			return err
+		}
+		if err := tx.Preload("Roles").First(&target, "id = ?", req.TargetUserID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Target account not found")
+			}
+			return err
+		}
+		result.SourceEmail, result.TargetEmail = source.Email, target.Email
+
+		targetUpdates := map[string]interface{}{}
+		if source.OrganizationID != nil && target.OrganizationID == nil {
+			targetUpdates["organization_id"] = source.OrganizationID
+			result.Membership = true
+		}
+
+		updated := all.Model(&models.Order{}).Where("user_id = ?", source.ID).Update("user_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move orders: %w", updated.Error)
+		}
+		result.Orders = updated.RowsAffected
+		updated = all.Model(&models.Order{}).Where("user_id IS NULL AND LOWER(buyer_email) = ?", source.Email).Update("user_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move guest orders: %w", updated.Error)
+		}
+		result.Orders += updated.RowsAffected
+
+		updated = all.Model(&models.Ticket{}).Where("LOWER(attendee_email) = ?", source.Email).Update("attendee_email", target.Email)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move tickets: %w", updated.Error)
+		}
+		result.Tickets = updated.RowsAffected
+
+		updated = all.Model(&models.CheckoutSession{}).Where("user_id = ?", source.ID).
+			Updates(map[string]interface{}{"user_id": target.ID, "email": target.Email})
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move checkout sessions: %w", updated.Error)
+		}
+		result.CheckoutSessions = updated.RowsAffected
+
+		updated = tx.Model(&models.Event{}).Where("organizer_id = ?", source.ID).Update("organizer_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move events: %w", updated.Error)
+		}
+		result.Events = updated.RowsAffected
+
+		updated = tx.Model(&models.Organization{}).Where("organizer_id = ?", source.ID).Update("organizer_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move organizations: %w", updated.Error)
+		}
+		result.Organizations = updated.RowsAffected
+
+		// Memberships of organizations the surviving account already belongs to keep its role there
+		updated = all.Model(&models.OrganizationMember{}).
+			Where("user_id = ? AND organization_id NOT IN (?)", source.ID, all.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", target.ID)).
+			Update("user_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move organization memberships: %w", updated.Error)
+		}
+		result.Memberships = updated.RowsAffected
+
+		held := make(map[uuid.UUID]bool, len(target.Roles))
+		for _, role := range target.Roles {
+			held[role.ID] = true
+		}
+		var gained []*models.Role
+		for _, role := range source.Roles {
+			if !held[role.ID] {
+				gained = append(gained, role)
+			}
+		}
+		if len(gained) > 0 {
+			if err := tx.Model(&target).Association("Roles").Append(gained); err != nil {
+				return fmt.Errorf("failed to move roles: %w", err)
+			}
+		}
+		result.Roles = int64(len(gained))
+
+		updated = tx.Model(&models.Token{}).Where("user_id = ?", source.ID).Update("user_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move sessions: %w", updated.Error)
+		}
+		result.Tokens = updated.RowsAffected
+
+		// Devices the surviving account already knows stay with it
+		updated = tx.Model(&models.UserDevice{}).
+			Where("user_id = ? AND fingerprint NOT IN (?)", source.ID, tx.Model(&models.UserDevice{}).Select("fingerprint").Where("user_id = ?", target.ID)).
+			Update("user_id", target.ID)
+		if updated.Error != nil {
+			return fmt.Errorf("failed to move devices: %w", updated.Error)
+		}
+		result.Devices = updated.RowsAffected
+
+		if err := tx.Model(&models.UsernameChange{}).Where("user_id = ?", source.ID).Update("user_id", target.ID).Error; err != nil {
+			return fmt.Errorf("failed to move username history: %w", err)
+		}
+		if source.Username != nil && target.Username == nil {
+			targetUpdates["username"] = *source.Username
+			result.Username = true
+		}
+		if source.AvatarUpdatedAt != nil && target.AvatarUpdatedAt == nil {
+			if err := tx.Model(&models.UserAvatar{}).Where("user_id = ?", source.ID).Update("user_id", target.ID).Error; err != nil {
+				return fmt.Errorf("failed to move avatar: %w", err)
+			}
+			targetUpdates["avatar_updated_at"] = source.AvatarUpdatedAt
+			result.Avatar = true
+		}
+
+		// Delete the duplicate before the target takes over its username
+		if err := tx.Where("user_id = ?", source.ID).Delete(&models.UserDevice{}).Error; err != nil {
+			return err
+		}
+		if err := all.Where("user_id = ?", source.ID).Delete(&models.OrganizationMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", source.ID).Delete(&models.UserAvatar{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&source).Association("Roles").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(&source).Error; err != nil {
+			return fmt.Errorf("failed to delete merged account: %w", err)
+		}
+		if len(targetUpdates) > 0 {
+			if err := tx.Model(&target).Updates(targetUpdates).Error; err != nil {
+				return fmt.Errorf("failed to update surviving account: %w", err)
+			}
+		}
+
+		if req.DryRun {
+			return errMergeDryRun
+		}
+		return writeAuditLog(tx, &adminID, AuditAccountsMerged, "user", target.ID.String(), target.OrganizationID, map[string]interface{}{
+			"memberships":       result.Memberships,
+			"source_user_id":    source.ID,
+			"source_email":      source.Email,
+			"orders":            result.Orders,
+			"tickets":           result.Tickets,
+			"checkout_sessions": result.CheckoutSessions,
+			"events":            result.Events,
+			"organizations":     result.Organizations,
+			"tokens":            result.Tokens,
+		})
+	})
+	if err != nil && !errors.Is(err, errMergeDryRun) {
+		return nil, err
+	}
+	return result, nil
+}
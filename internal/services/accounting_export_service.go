@@ -0,0 +1,294 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskAccountingExport is the asynq task type for accounting export generation
+const TaskAccountingExport = "accounting:export"
+
+// defaultAccountingAccounts are the ledger account names used when the organization does not supply its own
+var defaultAccountingAccounts = map[string]string{
+	models.AccountingAccountSales:    "Ticket Sales",
+	models.AccountingAccountFees:     "Merchant Fees",
+	models.AccountingAccountClearing: "Payouts Clearing",
+}
+
+// AccountingExportPayload is the payload of a queued accounting export task
+type AccountingExportPayload struct {
+	ExportID uuid.UUID `json:"export_id"`
+}
+
+// settlementPeriod aggregates paid orders settled within one period in one currency
+type settlementPeriod struct {
+	Start    time.Time
+	End      time.Time
+	Currency string
+	Orders   int
+	Gross    float64
+	Fees     float64
+}
+
+// CreateAccountingExport records an accounting export request and queues its generation
+func (s *IntegrationService) CreateAccountingExport(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.CreateAccountingExportRequest) (*models.AccountingExportResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	granularity := req.Granularity
+	if granularity == "" {
+		granularity = "monthly"
+	}
+
+	accounts := make(map[string]string, len(defaultAccountingAccounts))
+	for key, name := range defaultAccountingAccounts {
+		accounts[key] = name
+	}
+	for key, name := range req.Accounts {
+		if _, known := defaultAccountingAccounts[key]; !known {
+			return nil, fmt.Errorf("Unknown ledger account: %s", key)
+		}
+		if name != "" {
+			accounts[key] = name
+		}
+	}
+
+	export := models.AccountingExport{
+		OrganizationID: orgID,
+		Format:         models.AccountingFormat(req.Format),
+		Granularity:    granularity,
+		PeriodStart:    req.PeriodStart.UTC(),
+		PeriodEnd:      req.PeriodEnd.UTC(),
+		Accounts:       accounts,
+		CreatedBy:      &userID,
+	}
+
+	if err := db.Create(&export).Error; err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(AccountingExportPayload{ExportID: export.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal accounting export job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskAccountingExport, payload)
+	if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(IntegrationQueue)), asynq.MaxRetry(3)); err != nil {
+		return nil, fmt.Errorf("failed to enqueue accounting export: %w", err)
+	}
+
+	resp := export.ToResponse()
+	return &resp, nil
+}
+
+// ListAccountingExports returns the accounting exports of an organization, newest first
+func (s *IntegrationService) ListAccountingExports(ctx context.Context, orgID uuid.UUID) ([]models.AccountingExportResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var exports []models.AccountingExport
+	if err := db.Omit("content").Where("organization_id = ?", orgID).Order("created_at DESC").Find(&exports).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.AccountingExportResponse, len(exports))
+	for i, export := range exports {
+		responses[i] = export.ToResponse()
+	}
+	return responses, nil
+}
+
+// GetAccountingExport returns an accounting export of an organization, including its generated content
+func (s *IntegrationService) GetAccountingExport(ctx context.Context, orgID uuid.UUID, exportID uuid.UUID) (*models.AccountingExport, error) {
+	db := s.db.WithContext(ctx)
+
+	var export models.AccountingExport
+	if err := db.Where("id = ? AND organization_id = ?", exportID, orgID).First(&export).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Accounting export not found")
+		}
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GenerateAccountingExport builds the journal file of a queued accounting export
+func (s *IntegrationService) GenerateAccountingExport(ctx context.Context, exportID uuid.UUID) error {
+	var export models.AccountingExport
+	if err := s.db.WithContext(ctx).First(&export, "id = ?", exportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Export removed after the job was queued
+		}
+		return err
+	}
+
+	if export.Status == models.ExportStatusCompleted {
+		return nil
+	}
+
+	var orders []models.Order
+	err := s.db.WithContext(ctx).
+		Where("organization_id = ? AND status = ? AND paid_at >= ? AND paid_at < ?",
+			export.OrganizationID, models.OrderStatusPaid, export.PeriodStart, export.PeriodEnd).
+		Order("paid_at ASC").
+		Find(&orders).Error
+	if err != nil {
+		return err
+	}
+
+	periods := groupSettlementPeriods(orders, export.Granularity)
+
+	var content []byte
+	var rows int
+	switch export.Format {
+	case models.AccountingFormatQuickBooks:
+		content, rows, err = renderQuickBooksJournal(periods, export.Accounts)
+	case models.AccountingFormatXero:
+		content, rows, err = renderXeroJournal(periods, export.Accounts)
+	default:
+		err = fmt.Errorf("unsupported accounting format: %s", export.Format)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"completed_at": now}
+	if err != nil {
+		updates["status"] = models.ExportStatusFailed
+		updates["error"] = err.Error()
+	} else {
+		updates["status"] = models.ExportStatusCompleted
+		updates["content"] = string(content)
+		updates["row_count"] = rows
+		updates["error"] = ""
+	}
+	if updateErr := s.db.Model(&export).Updates(updates).Error; updateErr != nil {
+		return fmt.Errorf("failed to record accounting export: %w", updateErr)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Accounting export generated: ID=%s, Format=%s, Periods=%d, Rows=%d",
+		export.ID, export.Format, len(periods), rows)
+	return nil
+}
+
+// groupSettlementPeriods buckets paid orders into weekly (Monday-based) or monthly settlement periods per currency
+func groupSettlementPeriods(orders []models.Order, granularity string) []*settlementPeriod {
+	index := make(map[string]*settlementPeriod)
+	var periods []*settlementPeriod
+
+	for _, order := range orders {
+		if order.PaidAt == nil {
+			continue
+		}
+
+		paidAt := order.PaidAt.UTC()
+		day := time.Date(paidAt.Year(), paidAt.Month(), paidAt.Day(), 0, 0, 0, 0, time.UTC)
+
+		var start, end time.Time
+		if granularity == "weekly" {
+			offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+			start = day.AddDate(0, 0, -offset)
+			end = start.AddDate(0, 0, 7)
+		} else {
+			start = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+			end = start.AddDate(0, 1, 0)
+		}
+
+		key := start.Format("2006-01-02") + "|" + order.Currency
+		period, exists := index[key]
+		if !exists {
+			period = &settlementPeriod{Start: start, End: end, Currency: order.Currency}
+			index[key] = period
+			periods = append(periods, period)
+		}
+
+		period.Orders++
+		period.Gross += order.TotalAmount
+		period.Fees += order.FeeAmount
+	}
+
+	return periods
+}
+
+// journalReference returns the journal number of a settlement period
+func journalReference(period *settlementPeriod) string {
+	return fmt.Sprintf("SETTLE-%s-%s", period.Start.Format("20060102"), period.Currency)
+}
+
+// journalNarration describes a settlement period journal
+func journalNarration(period *settlementPeriod) string {
+	return fmt.Sprintf("Ticket sales settlement %s to %s (%d orders, %s)",
+		period.Start.Format("2006-01-02"), period.End.AddDate(0, 0, -1).Format("2006-01-02"),
+		period.Orders, period.Currency)
+}
+
+// formatAmount formats a currency amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// renderQuickBooksJournal renders settlement periods as a QuickBooks Online journal entry import file.
+// Each period balances gross sales (credit) against fees and the net payout (debits).
+func renderQuickBooksJournal(periods []*settlementPeriod, accounts map[string]string) ([]byte, int, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{{"JournalNo", "JournalDate", "Currency", "AccountName", "Debits", "Credits", "Description"}}
+	for _, period := range periods {
+		ref := journalReference(period)
+		date := period.End.AddDate(0, 0, -1).Format("01/02/2006")
+		narration := journalNarration(period)
+		net := period.Gross - period.Fees
+
+		rows = append(rows,
+			[]string{ref, date, period.Currency, accounts[models.AccountingAccountClearing], formatAmount(net), "", narration},
+			[]string{ref, date, period.Currency, accounts[models.AccountingAccountFees], formatAmount(period.Fees), "", narration},
+			[]string{ref, date, period.Currency, accounts[models.AccountingAccountSales], "", formatAmount(period.Gross), narration},
+		)
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), len(rows) - 1, nil
+}
+
+// renderXeroJournal renders settlement periods as a Xero manual journal import file.
+// Positive amounts are debits and negative amounts are credits.
+func renderXeroJournal(periods []*settlementPeriod, accounts map[string]string) ([]byte, int, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	rows := [][]string{{"*Narration", "*Date", "Description", "*AccountCode", "*TaxRate", "*Amount", "Reference"}}
+	for _, period := range periods {
+		ref := journalReference(period)
+		date := period.End.AddDate(0, 0, -1).Format("02/01/2006")
+		narration := journalNarration(period)
+		net := period.Gross - period.Fees
+
+		rows = append(rows,
+			[]string{narration, date, "Net payout", accounts[models.AccountingAccountClearing], "Tax Exempt", formatAmount(net), ref},
+			[]string{narration, date, "Platform and processing fees", accounts[models.AccountingAccountFees], "Tax Exempt", formatAmount(period.Fees), ref},
+			[]string{narration, date, "Gross ticket sales", accounts[models.AccountingAccountSales], "Tax Exempt", formatAmount(-period.Gross), ref},
+		)
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), len(rows) - 1, nil
+}
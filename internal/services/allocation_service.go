@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// TaskAllocationRelease is the asynq task type for scheduled hold releases
+	TaskAllocationRelease = "allocation:release"
+	// TicketingQueue is the asynq queue ticketing jobs are placed on
+	TicketingQueue = "queue:ticketing"
+)
+
+// AllocationReleasePayload is the payload of a scheduled hold release task
+type AllocationReleasePayload struct {
+	AllocationID uuid.UUID `json:"allocation_id"`
+}
+
+// AllocationService manages organizer holds and comp allocations
+type AllocationService struct {
+	db                 *gorm.DB
+	client             *asynq.Client
+	inventoryService   *InventoryService
+	emailQueueService  *EmailQueueService
+	integrationService *IntegrationService
+}
+
+// NewAllocationService creates a new allocation service
+func NewAllocationService(cfg *config.Config) *AllocationService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	return &AllocationService{
+		db:                 database.DB,
+		client:             asynq.NewClient(redisOpts),
+		inventoryService:   NewInventoryService(),
+		emailQueueService:  NewEmailQueueService(cfg),
+		integrationService: NewIntegrationService(cfg),
+	}
+}
+
+// CreateAllocation reserves a block of an event's inventory as a hold or comp allocation
+func (s *AllocationService) CreateAllocation(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.CreateAllocationRequest) (*models.AllocationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	if req.ReleaseAt != nil && !req.ReleaseAt.After(time.Now()) {
+		return nil, errors.New("release_at must be in the future")
+	}
+
+	allocation := models.TicketAllocation{
+		OrganizationID: orgID,
+		EventID:        req.EventID,
+		Type:           models.AllocationType(req.Type),
+		Label:          req.Label,
+		Quantity:       req.Quantity,
+		ReleaseAt:      req.ReleaseAt,
+		CreatedBy:      &userID,
+	}
+
+	var event models.Event
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, req.EventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Event not found")
+			}
+			return err
+		}
+
+		if err := s.inventoryService.Reserve(ctx, tx, &event, req.Quantity); err != nil {
+			return err
+		}
+
+		return tx.Create(&allocation).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: event.Available + req.Quantity, OrganizationID: &orgID})
+
+	if allocation.ReleaseAt != nil {
+		if err := s.scheduleRelease(&allocation); err != nil {
+			log.Printf("Failed to schedule hold release: Allocation=%s, Error=%v", allocation.ID, err)
+		}
+	}
+
+	resp := allocation.ToResponse()
+	return &resp, nil
+}
+
+// ListAllocations returns the allocations of an organization, optionally limited to one event
+func (s *AllocationService) ListAllocations(ctx context.Context, orgID uuid.UUID, eventID uint) ([]models.AllocationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Where("organization_id = ?", orgID)
+	if eventID != 0 {
+		query = query.Where("event_id = ?", eventID)
+	}
+
+	var allocations []models.TicketAllocation
+	if err := query.Order("created_at ASC").Find(&allocations).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.AllocationResponse, len(allocations))
+	for i, allocation := range allocations {
+		responses[i] = allocation.ToResponse()
+	}
+	return responses, nil
+}
+
+// IssueComps issues complimentary tickets from a comp allocation and emails them to the recipients,
+// or to each attendee a saved segment selects
+func (s *AllocationService) IssueComps(ctx context.Context, orgID uuid.UUID, allocationID uuid.UUID, req *models.IssueCompsRequest) ([]models.AttendeeResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	recipients := req.Recipients
+	if req.SegmentID != nil {
+		var err error
+		if recipients, err = segmentRecipients(db, orgID, *req.SegmentID); err != nil {
+			return nil, err
+		}
+		if len(recipients) == 0 {
+			return nil, errors.New("The segment selects no attendees")
+		}
+	}
+
+	var allocation models.TicketAllocation
+	var event models.Event
+	var tickets []*models.Ticket
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND organization_id = ?", allocationID, orgID).
+			First(&allocation).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Allocation not found")
+			}
+			return err
+		}
+
+		if allocation.Type != models.AllocationTypeComp {
+			return errors.New("Comp tickets can only be issued from comp allocations")
+		}
+		if allocation.Remaining() < len(recipients) {
+			return fmt.Errorf("Only %d comp tickets remain in this allocation", allocation.Remaining())
+		}
+
+		if err := tx.First(&event, allocation.EventID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, recipient := range recipients {
+			order := models.Order{
+				EventID:        event.ID,
+				OrganizationID: &orgID,
+				BuyerEmail:     recipient.Email,
+				BuyerName:      recipient.Name,
+				Quantity:       1,
+				TotalAmount:    0,
+				Status:         models.OrderStatusPaid,
+				PaymentMethod:  models.PaymentMethodComp,
+				PaidAt:         &now,
+			}
+			if err := tx.Create(&order).Error; err != nil {
+				return err
+			}
+
+			ticket := &models.Ticket{
+				OrderID:        order.ID,
+				EventID:        event.ID,
+				OrganizationID: &orgID,
+				AllocationID:   &allocation.ID,
+				AttendeeName:   recipient.Name,
+				AttendeeEmail:  recipient.Email,
+			}
+			if err := tx.Create(ticket).Error; err != nil {
+				return err
+			}
+			tickets = append(tickets, ticket)
+		}
+
+		allocation.Issued += len(tickets)
+		return tx.Model(&allocation).Update("issued", allocation.Issued).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.AttendeeResponse, len(tickets))
+	for i, ticket := range tickets {
+		if err := s.emailQueueService.QueueTicketEmail(ticket, &event, "Complimentary - "+allocation.Label); err != nil {
+			log.Printf("Failed to queue comp ticket email: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+		responses[i] = ticket.ToAttendeeResponse()
+		if err := s.integrationService.DispatchHook(orgID, models.HookEventAttendeeCreated, responses[i]); err != nil {
+			log.Printf("Failed to dispatch attendee hook: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+	}
+
+	return responses, nil
+}
+
+// ReleaseAllocation returns the unused tickets of an allocation to general sale
+func (s *AllocationService) ReleaseAllocation(ctx context.Context, orgID uuid.UUID, allocationID uuid.UUID) (*models.AllocationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var allocation models.TicketAllocation
+	if err := db.Where("id = ? AND organization_id = ?", allocationID, orgID).First(&allocation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Allocation not found")
+		}
+		return nil, err
+	}
+
+	if allocation.ReleasedAt != nil {
+		return nil, errors.New("Allocation has already been released")
+	}
+
+	released, err := s.release(ctx, allocation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := released.ToResponse()
+	return &resp, nil
+}
+
+// ReleaseScheduled releases an allocation whose scheduled release time has come. It is a no-op
+// when the allocation was released manually or its release time was moved.
+func (s *AllocationService) ReleaseScheduled(ctx context.Context, allocationID uuid.UUID) error {
+	var allocation models.TicketAllocation
+	if err := s.db.WithContext(ctx).First(&allocation, "id = ?", allocationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if allocation.ReleasedAt != nil || allocation.ReleaseAt == nil || allocation.ReleaseAt.After(time.Now()) {
+		return nil
+	}
+
+	_, err := s.release(ctx, allocation.ID)
+	return err
+}
+
+// release marks an allocation released and adds its unused tickets back to the event's availability
+func (s *AllocationService) release(ctx context.Context, allocationID uuid.UUID) (*models.TicketAllocation, error) {
+	var allocation models.TicketAllocation
+	var event models.Event
+	var unused int
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&allocation, "id = ?", allocationID).Error; err != nil {
+			return err
+		}
+		if allocation.ReleasedAt != nil {
+			return nil // Released concurrently
+		}
+
+		unused = allocation.Remaining()
+		now := time.Now()
+		allocation.ReleasedAt = &now
+		if err := tx.Model(&allocation).Update("released_at", now).Error; err != nil {
+			return err
+		}
+
+		event.ID = allocation.EventID
+		return s.inventoryService.Release(ctx, tx, &event, unused)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if unused > 0 {
+		InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: event.Available - unused, OrganizationID: &allocation.OrganizationID})
+		log.Printf("Allocation released: ID=%s, Event=%d, Tickets=%d", allocation.ID, event.ID, unused)
+	}
+
+	return &allocation, nil
+}
+
+// scheduleRelease queues the automatic release of an allocation at its release time
+func (s *AllocationService) scheduleRelease(allocation *models.TicketAllocation) error {
+	payload, err := json.Marshal(AllocationReleasePayload{AllocationID: allocation.ID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal allocation release job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskAllocationRelease, payload)
+	_, err = s.client.Enqueue(task,
+		asynq.Queue(redis.QueueName(TicketingQueue)),
+		asynq.ProcessAt(*allocation.ReleaseAt),
+		asynq.MaxRetry(5),
+	)
+	return err
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKeyPrefix marks organization API keys, telling them apart from other secrets in logs and scanners
+const APIKeyPrefix = "etk_"
+
+// apiKeyDisplayLength is how much of a key is kept in the clear to tell keys apart
+const apiKeyDisplayLength = len(APIKeyPrefix) + 8
+
+// apiKeyUseInterval is how often the last use of a key is recorded, sparing a write per request
+const apiKeyUseInterval = time.Minute
+
+var (
+	// ErrAPIKeyNotFound is returned for an API key the organization does not have, or one already revoked
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrInvalidAPIKey is returned for an API key that is unknown, revoked or expired
+	ErrInvalidAPIKey = errors.New("Invalid or expired API key")
+)
+
+// APIKeyService manages the API keys organizers give integrators in place of user tokens, and
+// authenticates requests made with them
+type APIKeyService struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyService creates a new API key service
+func NewAPIKeyService() *APIKeyService {
+	return &APIKeyService{db: database.DB}
+}
+
+// List returns the API keys of an organization with their scopes, newest first. Revoked keys are
+// included so their use can still be traced.
+func (s *APIKeyService) List(ctx context.Context, orgID uuid.UUID) ([]models.APIKeyResponse, error) {
+	var keys []models.APIKey
+	err := s.db.WithContext(ctx).Preload("Scopes").
+		Where("organization_id = ?", orgID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.APIKeyResponse, len(keys))
+	for i := range keys {
+		responses[i] = keys[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// Create issues an API key for an organization with the requested scopes. The key is only
+// returned now; the organization keeps its prefix to tell it apart.
+func (s *APIKeyService) Create(ctx context.Context, orgID, actorID uuid.UUID, req *models.APIKeyRequest) (*models.APIKeyResponse, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, utils.NewValidationError("Invalid API key", map[string]interface{}{
+			"expires_at": "must be in the future",
+		})
+	}
+
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+	key := models.APIKey{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Prefix:         secret[:apiKeyDisplayLength],
+		KeyHash:        utils.HashToken(secret),
+		CreatedBy:      actorID,
+		ExpiresAt:      req.ExpiresAt,
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		scopes, err := assignablePermissions(tx, req.Scopes)
+		if err != nil {
+			return err
+		}
+
+		key.Scopes = scopes
+		if err := tx.Create(&key).Error; err != nil {
+			return fmt.Errorf("failed to create API key: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "api_key.create", "api_key", key.ID.String(), &orgID, map[string]interface{}{
+			"name":   key.Name,
+			"scopes": req.Scopes,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := key.ToResponse()
+	resp.Key = secret
+	return &resp, nil
+}
+
+// Rotate replaces the secret of an API key, keeping its name and scopes. The previous secret
+// stops working immediately.
+func (s *APIKeyService) Rotate(ctx context.Context, orgID, keyID, actorID uuid.UUID) (*models.APIKeyResponse, error) {
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		return nil, err
+	}
+
+	var key models.APIKey
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Preload("Scopes").Where("id = ? AND organization_id = ? AND revoked_at IS NULL", keyID, orgID).First(&key).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAPIKeyNotFound
+			}
+			return err
+		}
+
+		now := time.Now()
+		key.Prefix = secret[:apiKeyDisplayLength]
+		key.KeyHash = utils.HashToken(secret)
+		key.RotatedAt = &now
+		if err := tx.Model(&key).Select("prefix", "key_hash", "rotated_at").Updates(&key).Error; err != nil {
+			return fmt.Errorf("failed to rotate API key: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "api_key.rotate", "api_key", key.ID.String(), &orgID, map[string]interface{}{
+			"name": key.Name,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := key.ToResponse()
+	resp.Key = secret
+	return &resp, nil
+}
+
+// Revoke cuts an API key off immediately, e.g. when an integration is removed or the key leaked
+func (s *APIKeyService) Revoke(ctx context.Context, orgID, keyID, actorID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+	result := db.Model(&models.APIKey{}).
+		Where("id = ? AND organization_id = ? AND revoked_at IS NULL", keyID, orgID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	recordAuditLog(db, &actorID, "api_key.revoke", "api_key", keyID.String(), &orgID, nil)
+	return nil
+}
+
+// Authenticate returns the API key a request presented, with its scopes, and records its use
+func (s *APIKeyService) Authenticate(ctx context.Context, secret string) (*models.APIKey, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	var key models.APIKey
+	err := db.Preload("Scopes").
+		Where("key_hash = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", utils.HashToken(secret), now).
+		First(&key).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyUseInterval {
+		if err := db.Model(&key).UpdateColumn("last_used_at", now).Error; err != nil {
+			log.Printf("Failed to record API key use: Key=%s, Error=%v", key.ID, err)
+		}
+	}
+	return &key, nil
+}
+
+// newAPIKeySecret generates a random API key
+func newAPIKeySecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	return APIKeyPrefix + hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskArchiveRun is the asynq task type for the archival sweep of past events
+const TaskArchiveRun = "archive:run"
+
+// Audit actions of event archival
+const (
+	AuditEventArchived = "event.archived"
+	AuditEventRestored = "event.archive_restored"
+)
+
+var (
+	// ErrEventArchiveNotFound is returned when an archive ID matches no archive
+	ErrEventArchiveNotFound = errors.New("Event archive not found")
+	// ErrEventAlreadyArchived is returned when archiving an event whose records are archived
+	ErrEventAlreadyArchived = errors.New("Event is already archived")
+	// ErrEventArchiveRestored is returned when restoring an archive that was restored before
+	ErrEventArchiveRestored = errors.New("Event archive has already been restored")
+)
+
+// archivedKinds are the tables whose rows of an event are archived, in the order they are
+// restored. They are archived in reverse, so check-ins never outlive their tickets in the hot
+// tables.
+var archivedKinds = []string{
+	models.ArchivedKindOrders,
+	models.ArchivedKindTickets,
+	models.ArchivedKindCheckIns,
+	models.ArchivedKindScanAttempts,
+}
+
+// ArchiveService keeps the hot tables small by moving the orders, tickets, check-ins and scan
+// attempts of events that ended years ago into an archive table, as JSON images of their rows.
+// The totals of each event are kept with its archive, and an archive can be restored in full.
+type ArchiveService struct {
+	db  *gorm.DB
+	cfg config.ArchiveConfig
+}
+
+// NewArchiveService creates a new archive service
+func NewArchiveService(cfg *config.Config) *ArchiveService {
+	return &ArchiveService{
+		db:  database.DB,
+		cfg: cfg.Archive,
+	}
+}
+
+// Enabled reports whether the archival policy archives events on its own
+func (s *ArchiveService) Enabled() bool {
+	return s.cfg.AfterYears > 0
+}
+
+// RunPolicy archives the events that ended more than ARCHIVE_AFTER_YEARS ago and were never
+// archived, oldest first, up to ARCHIVE_BATCH_SIZE per sweep. Each event is archived in its own transaction; events that fail
+// are left in place for the next sweep.
+func (s *ArchiveService) RunPolicy(ctx context.Context) (*models.ArchiveRunResult, error) {
+	result := &models.ArchiveRunResult{Archived: []uint{}, Failed: []uint{}}
+	if !s.Enabled() {
+		return result, nil
+	}
+
+	// Events restored by an admin are left in the hot tables until archived by hand again
+	cutoff := time.Now().AddDate(-s.cfg.AfterYears, 0, 0)
+	var eventIDs []uint
+	err := s.db.WithContext(ctx).Model(&models.Event{}).
+		Where("end_date < ? AND archived_at IS NULL", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM event_archives WHERE event_archives.event_id = events.id)").
+		Order("end_date, id").
+		Limit(s.cfg.BatchSize).
+		Pluck("id", &eventIDs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, eventID := range eventIDs {
+		if _, err := s.ArchiveEvent(ctx, nil, eventID); err != nil {
+			log.Printf("Failed to archive event: EventID=%d, Error=%v", eventID, err)
+			result.Failed = append(result.Failed, eventID)
+			continue
+		}
+		result.Archived = append(result.Archived, eventID)
+	}
+
+	if len(eventIDs) > 0 {
+		log.Printf("Archival sweep finished: Archived=%d, Failed=%d", len(result.Archived), len(result.Failed))
+	}
+	return result, nil
+}
+
+// ArchiveEvent moves the orders, tickets, check-ins and scan attempts of an event that has ended
+// to its archive, with the event's sales and attendance totals. actorID is nil when the policy
+// archives the event.
+func (s *ArchiveService) ArchiveEvent(ctx context.Context, actorID *uuid.UUID, eventID uint) (*models.EventArchive, error) {
+	var archive models.EventArchive
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		// Locking the event keeps a second sweep or admin from archiving it at the same time
+		var event models.Event
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NewNotFoundError("Event")
+		}
+		if err != nil {
+			return err
+		}
+		if event.ArchivedAt != nil {
+			return ErrEventAlreadyArchived
+		}
+		if event.EndDate.After(time.Now()) {
+			return utils.NewBusinessLogicError("Only events that have ended can be archived")
+		}
+
+		now := time.Now()
+		archive = models.EventArchive{
+			EventID:        event.ID,
+			OrganizationID: event.OrganizationID,
+			EventTitle:     event.Title,
+			EventEndDate:   event.EndDate,
+			Status:         models.EventArchiveStatusArchived,
+			ArchivedBy:     actorID,
+			ArchivedAt:     now,
+		}
+		if err := s.totals(tx, &archive); err != nil {
+			return err
+		}
+		if err := tx.Create(&archive).Error; err != nil {
+			return err
+		}
+
+		for i := len(archivedKinds) - 1; i >= 0; i-- {
+			moved, err := s.archiveRows(tx, archive.ID, archivedKinds[i], event.ID, now)
+			if err != nil {
+				return fmt.Errorf("failed to archive %s: %w", archivedKinds[i], err)
+			}
+			archive.RecordCount += moved
+		}
+
+		if err := tx.Model(&archive).Update("record_count", archive.RecordCount).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&event).Update("archived_at", now).Error; err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, actorID, AuditEventArchived, "event", fmt.Sprint(event.ID), event.OrganizationID, map[string]interface{}{
+			"archive_id":   archive.ID,
+			"record_count": archive.RecordCount,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// Restore moves the records of an archive back to the hot tables. Columns added since the records
+// were archived take their defaults, and columns dropped since are ignored.
+func (s *ArchiveService) Restore(ctx context.Context, actorID, archiveID uuid.UUID) (*models.EventArchive, error) {
+	var archive models.EventArchive
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&archive, "id = ?", archiveID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrEventArchiveNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if archive.Status != models.EventArchiveStatusArchived {
+			return ErrEventArchiveRestored
+		}
+
+		for _, kind := range archivedKinds {
+			if err := s.restoreRows(tx, archive.ID, kind); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", kind, err)
+			}
+		}
+		if err := tx.Where("archive_id = ?", archive.ID).Delete(&models.ArchivedRecord{}).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		archive.Status = models.EventArchiveStatusRestored
+		archive.RestoredBy = &actorID
+		archive.RestoredAt = &now
+		if err := tx.Save(&archive).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Event{}).Where("id = ?", archive.EventID).Update("archived_at", nil).Error; err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, &actorID, AuditEventRestored, "event", fmt.Sprint(archive.EventID), archive.OrganizationID, map[string]interface{}{
+			"archive_id":   archive.ID,
+			"record_count": archive.RecordCount,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// ListArchives returns a page of event archives, most recently archived first
+func (s *ArchiveService) ListArchives(ctx context.Context, query *models.EventArchiveQuery) ([]models.EventArchive, *models.PageMeta, error) {
+	db := s.db.WithContext(ctx).Model(&models.EventArchive{})
+	if query.EventID != 0 {
+		db = db.Where("event_id = ?", query.EventID)
+	}
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var archives []models.EventArchive
+	meta, err := database.Paginate(db.Order("archived_at DESC, id"), query.PageQuery, &archives)
+	if err != nil {
+		return nil, nil, err
+	}
+	return archives, meta, nil
+}
+
+// GetArchive returns an archive with the number of records it holds of each kind
+func (s *ArchiveService) GetArchive(ctx context.Context, archiveID uuid.UUID) (*models.EventArchiveResponse, error) {
+	var archive models.EventArchive
+	err := s.db.WithContext(ctx).First(&archive, "id = ?", archiveID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrEventArchiveNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []struct {
+		Kind  string
+		Count int64
+	}
+	err = s.db.WithContext(ctx).Model(&models.ArchivedRecord{}).
+		Select("kind, COUNT(*) AS count").
+		Where("archive_id = ?", archive.ID).
+		Group("kind").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.EventArchiveResponse{EventArchive: archive, Records: make(map[string]int64, len(archivedKinds))}
+	for _, kind := range archivedKinds {
+		response.Records[kind] = 0
+	}
+	for _, c := range counts {
+		response.Records[c.Kind] = c.Count
+	}
+	return response, nil
+}
+
+// ListRecords returns a page of the rows an archive holds, optionally of one kind, so archived
+// orders and tickets can be looked up without restoring them
+func (s *ArchiveService) ListRecords(ctx context.Context, archiveID uuid.UUID, query *models.ArchivedRecordQuery) ([]models.ArchivedRecord, *models.PageMeta, error) {
+	var count int64
+	if err := s.db.WithContext(ctx).Model(&models.EventArchive{}).Where("id = ?", archiveID).Count(&count).Error; err != nil {
+		return nil, nil, err
+	}
+	if count == 0 {
+		return nil, nil, ErrEventArchiveNotFound
+	}
+
+	db := s.db.WithContext(ctx).Model(&models.ArchivedRecord{}).Where("archive_id = ?", archiveID)
+	if query.Kind != "" {
+		db = db.Where("kind = ?", query.Kind)
+	}
+
+	var records []models.ArchivedRecord
+	meta, err := database.Paginate(db.Order("kind, record_id"), query.PageQuery, &records)
+	if err != nil {
+		return nil, nil, err
+	}
+	return records, meta, nil
+}
+
+// totals fills in an event's sales and attendance totals from its hot rows
+func (s *ArchiveService) totals(tx *gorm.DB, archive *models.EventArchive) error {
+	var orders struct {
+		Orders         int64
+		GrossAmount    float64
+		FeeAmount      float64
+		RefundedAmount float64
+		Currency       string
+	}
+	err := tx.Model(&models.Order{}).
+		Select(`COUNT(*) AS orders, COALESCE(SUM(total_amount), 0) AS gross_amount,
+			COALESCE(SUM(fee_amount), 0) AS fee_amount, COALESCE(SUM(refunded_amount), 0) AS refunded_amount,
+			COALESCE(MAX(currency), '') AS currency`).
+		Where("event_id = ? AND status <> ?", archive.EventID, models.OrderStatusCancelled).
+		Scan(&orders).Error
+	if err != nil {
+		return err
+	}
+
+	var tickets struct {
+		Tickets   int64
+		CheckedIn int64
+	}
+	err = tx.Model(&models.Ticket{}).
+		Select("COUNT(*) AS tickets, COUNT(*) FILTER (WHERE checked_in_at IS NOT NULL) AS checked_in").
+		Where("event_id = ? AND status <> ?", archive.EventID, models.TicketStatusCancelled).
+		Scan(&tickets).Error
+	if err != nil {
+		return err
+	}
+
+	archive.Orders = orders.Orders
+	archive.GrossAmount = orders.GrossAmount
+	archive.FeeAmount = orders.FeeAmount
+	archive.RefundedAmount = orders.RefundedAmount
+	archive.Currency = orders.Currency
+	archive.Tickets = tickets.Tickets
+	archive.CheckedIn = tickets.CheckedIn
+	return nil
+}
+
+// archiveRows copies an event's rows of one table into an archive and deletes them. Only the rows
+// copied are deleted, so a row written in between stays in the hot table.
+func (s *ArchiveService) archiveRows(tx *gorm.DB, archiveID uuid.UUID, kind string, eventID uint, now time.Time) (int64, error) {
+	copied := tx.Exec(`INSERT INTO archived_records (id, archive_id, kind, record_id, data, created_at)
+		SELECT uuid_generate_v4(), ?, ?, t.id::text, to_jsonb(t), ? FROM `+kind+` t WHERE t.event_id = ?`,
+		archiveID, kind, now, eventID)
+	if copied.Error != nil {
+		return 0, copied.Error
+	}
+
+	err := tx.Exec(`DELETE FROM `+kind+` WHERE event_id = ? AND id::text IN
+		(SELECT record_id FROM archived_records WHERE archive_id = ? AND kind = ?)`,
+		eventID, archiveID, kind).Error
+	if err != nil {
+		return 0, err
+	}
+	return copied.RowsAffected, nil
+}
+
+// restoreRows inserts an archive's rows of one table back, with the archived columns the table
+// still has. Rows whose ID is back in the table already are skipped.
+func (s *ArchiveService) restoreRows(tx *gorm.DB, archiveID uuid.UUID, kind string) error {
+	var keys []string
+	err := tx.Raw(`SELECT DISTINCT jsonb_object_keys(data) FROM archived_records WHERE archive_id = ? AND kind = ?`, archiveID, kind).
+		Scan(&keys).Error
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	columnTypes, err := tx.Migrator().ColumnTypes(kind)
+	if err != nil {
+		return err
+	}
+	current := make(map[string]bool, len(columnTypes))
+	for _, column := range columnTypes {
+		current[column.Name()] = true
+	}
+
+	var columns []string
+	for _, key := range keys {
+		if current[key] {
+			columns = append(columns, `"`+key+`"`)
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	list := strings.Join(columns, ", ")
+	return tx.Exec(`INSERT INTO `+kind+` (`+list+`)
+		SELECT `+prefixColumns("r.", columns)+` FROM archived_records a
+		CROSS JOIN LATERAL jsonb_populate_record(NULL::`+kind+`, a.data) r
+		WHERE a.archive_id = ? AND a.kind = ?
+		ON CONFLICT DO NOTHING`, archiveID, kind).Error
+}
+
+// prefixColumns qualifies a list of quoted column names with a table alias
+func prefixColumns(prefix string, columns []string) string {
+	qualified := make([]string, len(columns))
+	for i, column := range columns {
+		qualified[i] = prefix + column
+	}
+	return strings.Join(qualified, ", ")
+}
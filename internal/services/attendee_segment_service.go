@@ -0,0 +1,359 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrSegmentNotFound is returned for a segment the organization does not have
+var ErrSegmentNotFound = errors.New("Segment not found")
+
+// AttendeeSegmentService manages the saved attendee segments of organizations and evaluates them
+// against the current tickets
+type AttendeeSegmentService struct {
+	db                 *gorm.DB
+	integrationService *IntegrationService
+}
+
+func NewAttendeeSegmentService(cfg *config.Config) *AttendeeSegmentService {
+	return &AttendeeSegmentService{
+		db:                 database.DB,
+		integrationService: NewIntegrationService(cfg),
+	}
+}
+
+// List returns the segments of an organization by name, with their current counts
+func (s *AttendeeSegmentService) List(ctx context.Context, orgID uuid.UUID) ([]models.AttendeeSegmentResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var segments []models.AttendeeSegment
+	if err := db.Where("organization_id = ?", orgID).Order("name ASC").Find(&segments).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.AttendeeSegmentResponse, len(segments))
+	for i := range segments {
+		counts, err := segmentCounts(db, orgID, &segments[i].Criteria)
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = models.AttendeeSegmentResponse{AttendeeSegment: segments[i], Counts: *counts}
+	}
+	return responses, nil
+}
+
+// Get returns a segment of the organization with its current counts
+func (s *AttendeeSegmentService) Get(ctx context.Context, orgID, segmentID uuid.UUID) (*models.AttendeeSegmentResponse, error) {
+	db := s.db.WithContext(ctx)
+	segment, err := s.find(db, orgID, segmentID)
+	if err != nil {
+		return nil, err
+	}
+	return segmentResponse(db, segment)
+}
+
+// Create saves a segment for the organization
+func (s *AttendeeSegmentService) Create(ctx context.Context, orgID, userID uuid.UUID, req *models.AttendeeSegmentRequest) (*models.AttendeeSegmentResponse, error) {
+	db := s.db.WithContext(ctx)
+	criteria, err := normalizeSegmentCriteria(req.Criteria)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := ensureSegmentNameFree(db, orgID, name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	segment := &models.AttendeeSegment{
+		OrganizationID: orgID,
+		Name:           name,
+		Criteria:       *criteria,
+		CreatedBy:      userID,
+	}
+	if err := db.Create(segment).Error; err != nil {
+		return nil, err
+	}
+	return segmentResponse(db, segment)
+}
+
+// Update renames a segment of the organization and replaces its criteria
+func (s *AttendeeSegmentService) Update(ctx context.Context, orgID, segmentID uuid.UUID, req *models.AttendeeSegmentRequest) (*models.AttendeeSegmentResponse, error) {
+	db := s.db.WithContext(ctx)
+	segment, err := s.find(db, orgID, segmentID)
+	if err != nil {
+		return nil, err
+	}
+	criteria, err := normalizeSegmentCriteria(req.Criteria)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(req.Name)
+	if err := ensureSegmentNameFree(db, orgID, name, segment.ID); err != nil {
+		return nil, err
+	}
+
+	segment.Name = name
+	segment.Criteria = *criteria
+	if err := db.Model(segment).Select("name", "criteria").Updates(segment).Error; err != nil {
+		return nil, err
+	}
+	return segmentResponse(db, segment)
+}
+
+// Delete deletes a segment of the organization
+func (s *AttendeeSegmentService) Delete(ctx context.Context, orgID, segmentID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", segmentID, orgID).Delete(&models.AttendeeSegment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSegmentNotFound
+	}
+	return nil
+}
+
+// Preview counts the attendees the criteria select now, without saving them
+func (s *AttendeeSegmentService) Preview(ctx context.Context, orgID uuid.UUID, criteria *models.SegmentCriteria) (*models.SegmentCounts, error) {
+	normalized, err := normalizeSegmentCriteria(*criteria)
+	if err != nil {
+		return nil, err
+	}
+	return segmentCounts(s.db.WithContext(ctx), orgID, normalized)
+}
+
+// Attendees returns the requested page of the tickets a segment selects, newest first
+func (s *AttendeeSegmentService) Attendees(ctx context.Context, orgID, segmentID uuid.UUID, page models.PageQuery) ([]models.SegmentAttendee, *models.PageMeta, error) {
+	db := s.db.WithContext(ctx)
+	segment, err := s.find(db, orgID, segmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tickets []models.Ticket
+	query := segmentTickets(db, orgID, &segment.Criteria).Order("tickets.created_at DESC").Order("tickets.id ASC")
+	meta, err := database.Paginate(query, page, &tickets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attendees := make([]models.SegmentAttendee, len(tickets))
+	for i := range tickets {
+		attendees[i] = models.SegmentAttendee{AttendeeResponse: tickets[i].ToAttendeeResponse(), CheckedInAt: tickets[i].CheckedInAt}
+	}
+	return attendees, meta, nil
+}
+
+// Export renders the tickets a segment selects as CSV, in ticket ID order, and returns it with the
+// segment
+func (s *AttendeeSegmentService) Export(ctx context.Context, orgID, segmentID uuid.UUID) ([]byte, *models.AttendeeSegment, error) {
+	db := s.db.WithContext(ctx)
+	segment, err := s.find(db, orgID, segmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"ticket_id", "order_id", "event_id", "name", "email", "status", "purchased_at", "checked_in_at"}); err != nil {
+		return nil, nil, err
+	}
+
+	var batch []models.Ticket
+	err = segmentTickets(db, orgID, &segment.Criteria).
+		FindInBatches(&batch, warehouseBatchSize, func(tx *gorm.DB, _ int) error {
+			for _, t := range batch {
+				w.Write([]string{
+					t.ID.String(), t.OrderID.String(), strconv.FormatUint(uint64(t.EventID), 10),
+					t.AttendeeName, t.AttendeeEmail, string(t.Status),
+					formatTimestamp(&t.CreatedAt), formatTimestamp(t.CheckedInAt),
+				})
+			}
+			return w.Error()
+		}).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, nil, err
+	}
+	return buf.Bytes(), segment, nil
+}
+
+// SyncMarketing queues an upload of the segment's attendees who accepted marketing to the
+// organization's marketing audience, tagged with the segment so campaigns can target it
+func (s *AttendeeSegmentService) SyncMarketing(ctx context.Context, orgID, segmentID uuid.UUID) error {
+	if _, err := s.find(s.db.WithContext(ctx), orgID, segmentID); err != nil {
+		return err
+	}
+	if _, err := s.integrationService.GetMarketingIntegration(ctx, orgID); err != nil {
+		return utils.NewBusinessLogicError("Marketing integration not configured")
+	}
+	return s.integrationService.QueueSegmentSync(orgID, segmentID)
+}
+
+func (s *AttendeeSegmentService) find(db *gorm.DB, orgID, segmentID uuid.UUID) (*models.AttendeeSegment, error) {
+	var segment models.AttendeeSegment
+	if err := db.Where("id = ? AND organization_id = ?", segmentID, orgID).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSegmentNotFound
+		}
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func segmentResponse(db *gorm.DB, segment *models.AttendeeSegment) (*models.AttendeeSegmentResponse, error) {
+	counts, err := segmentCounts(db, segment.OrganizationID, &segment.Criteria)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AttendeeSegmentResponse{AttendeeSegment: *segment, Counts: *counts}, nil
+}
+
+// ensureSegmentNameFree rejects a segment name another segment of the organization already uses
+func ensureSegmentNameFree(db *gorm.DB, orgID uuid.UUID, name string, segmentID uuid.UUID) error {
+	var count int64
+	err := db.Model(&models.AttendeeSegment{}).
+		Where("organization_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", orgID, name, segmentID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return utils.NewConflictError(fmt.Sprintf("A segment named %q already exists", name))
+	}
+	return nil
+}
+
+// normalizeSegmentCriteria returns the criteria with tags trimmed, lowercased and deduplicated,
+// rejecting an empty purchase date range
+func normalizeSegmentCriteria(criteria models.SegmentCriteria) (*models.SegmentCriteria, error) {
+	if criteria.PurchasedAfter != nil && criteria.PurchasedBefore != nil && !criteria.PurchasedBefore.After(*criteria.PurchasedAfter) {
+		return nil, utils.NewValidationError("Invalid segment criteria", map[string]interface{}{
+			"criteria.purchased_before": "must be after purchased_after",
+		})
+	}
+
+	tags := make([]string, 0, len(criteria.Tags))
+	for _, tag := range criteria.Tags {
+		name := strings.ToLower(strings.TrimSpace(tag))
+		if name == "" {
+			return nil, utils.NewValidationError("Invalid segment criteria", map[string]interface{}{
+				"criteria.tags": "must not be blank",
+			})
+		}
+		if !slices.Contains(tags, name) {
+			tags = append(tags, name)
+		}
+	}
+	criteria.Tags = tags
+	if len(criteria.Tags) == 0 {
+		criteria.Tags = nil
+	}
+	return &criteria, nil
+}
+
+// segmentTickets selects the organization's tickets matching the criteria. Cancelled tickets are
+// never selected.
+func segmentTickets(db *gorm.DB, orgID uuid.UUID, criteria *models.SegmentCriteria) *gorm.DB {
+	query := db.Model(&models.Ticket{}).
+		Where("tickets.organization_id = ? AND tickets.status <> ?", orgID, models.TicketStatusCancelled)
+
+	if len(criteria.EventIDs) > 0 {
+		query = query.Where("tickets.event_id IN ?", criteria.EventIDs)
+	}
+	if len(criteria.AllocationIDs) > 0 {
+		query = query.Where("tickets.allocation_id IN ?", criteria.AllocationIDs)
+	}
+
+	comp := `EXISTS (SELECT 1 FROM ticket_allocations WHERE ticket_allocations.id = tickets.allocation_id AND ticket_allocations.type = ?)`
+	general := slices.Contains(criteria.TicketTypes, models.SegmentTicketTypeGeneral)
+	complimentary := slices.Contains(criteria.TicketTypes, models.SegmentTicketTypeComp)
+	switch {
+	case general && !complimentary:
+		query = query.Where("NOT "+comp, models.AllocationTypeComp)
+	case complimentary && !general:
+		query = query.Where(comp, models.AllocationTypeComp)
+	}
+
+	if criteria.CheckedIn != nil {
+		if *criteria.CheckedIn {
+			query = query.Where("tickets.checked_in_at IS NOT NULL")
+		} else {
+			query = query.Where("tickets.checked_in_at IS NULL")
+		}
+	}
+	if criteria.PurchasedAfter != nil {
+		query = query.Where("tickets.created_at >= ?", *criteria.PurchasedAfter)
+	}
+	if criteria.PurchasedBefore != nil {
+		query = query.Where("tickets.created_at < ?", *criteria.PurchasedBefore)
+	}
+	if len(criteria.Tags) > 0 {
+		query = query.Where(`EXISTS (SELECT 1 FROM internal_tags WHERE internal_tags.organization_id = tickets.organization_id
+			AND ((internal_tags.subject_type = ? AND internal_tags.subject_id = tickets.id)
+				OR (internal_tags.subject_type = ? AND internal_tags.subject_id = tickets.order_id))
+			AND internal_tags.name IN ?)`, models.InternalSubjectTicket, models.InternalSubjectOrder, criteria.Tags)
+	}
+	return query
+}
+
+// segmentCounts counts the tickets the criteria select and their distinct attendees
+func segmentCounts(db *gorm.DB, orgID uuid.UUID, criteria *models.SegmentCriteria) (*models.SegmentCounts, error) {
+	var counts models.SegmentCounts
+	err := segmentTickets(db, orgID, criteria).
+		Select(`COUNT(*) AS tickets,
+			COUNT(DISTINCT LOWER(tickets.attendee_email)) AS attendees,
+			COUNT(DISTINCT CASE WHEN tickets.marketing_opt_in THEN LOWER(tickets.attendee_email) END) AS opted_in`).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	return &counts, nil
+}
+
+// segmentRecipients returns one comp recipient per distinct attendee email a segment selects,
+// named as on their latest ticket
+func segmentRecipients(db *gorm.DB, orgID, segmentID uuid.UUID) ([]models.CompRecipient, error) {
+	var segment models.AttendeeSegment
+	if err := db.Where("id = ? AND organization_id = ?", segmentID, orgID).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSegmentNotFound
+		}
+		return nil, err
+	}
+
+	var tickets []models.Ticket
+	if err := segmentTickets(db, orgID, &segment.Criteria).Select("tickets.attendee_name", "tickets.attendee_email").
+		Order("tickets.created_at DESC").Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(tickets))
+	var recipients []models.CompRecipient
+	for _, ticket := range tickets {
+		email := strings.ToLower(strings.TrimSpace(ticket.AttendeeEmail))
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+		recipients = append(recipients, models.CompRecipient{Name: ticket.AttendeeName, Email: ticket.AttendeeEmail})
+	}
+	return recipients, nil
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AuditAdminIPDenied is the audit action of a request to an admin route from outside the admin IP allowlist
+const AuditAdminIPDenied = "admin.ip_denied"
+
+// AuditService reads the audit log of privileged actions
+type AuditService struct {
+	db *gorm.DB
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService() *AuditService {
+	return &AuditService{
+		db: database.DB,
+	}
+}
+
+// ListAuditLogs returns the most recent audit entries matching the filter
+func (s *AuditService) ListAuditLogs(ctx context.Context, filter *models.AuditLogFilter) ([]models.AuditLog, error) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.AuditLog{})
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("created_at DESC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// RecordDeniedAdminAccess records a request to an admin route that the admin IP allowlist turned
+// away. The request is not authenticated yet, so the entry has no actor.
+func (s *AuditService) RecordDeniedAdminAccess(ctx context.Context, ip, method, path, userAgent string) {
+	log.Printf("Admin route denied by IP allowlist: IP=%s, Request=%s %s", ip, method, path)
+	recordAuditLog(s.db.WithContext(ctx), nil, AuditAdminIPDenied, "admin_route", path, nil, map[string]interface{}{
+		"ip":         ip,
+		"method":     method,
+		"user_agent": userAgent,
+	})
+}
+
+// writeAuditLog appends an audit entry using tx, so the entry commits or rolls back with the
+// change it describes
+func writeAuditLog(tx *gorm.DB, actorID *uuid.UUID, action, entityType, entityID string, orgID *uuid.UUID, details map[string]interface{}) error {
+	return tx.Create(&models.AuditLog{
+		ActorID:        actorID,
+		Action:         action,
+		EntityType:     entityType,
+		EntityID:       entityID,
+		OrganizationID: orgID,
+		Details:        details,
+	}).Error
+}
+
+// recordAuditLog appends an audit entry outside of a transaction. Failures are logged, never returned.
+func recordAuditLog(db *gorm.DB, actorID *uuid.UUID, action, entityType, entityID string, orgID *uuid.UUID, details map[string]interface{}) {
+	if err := writeAuditLog(db, actorID, action, entityType, entityID, orgID, details); err != nil {
+		log.Printf("Failed to write audit log: Action=%s, Entity=%s/%s, Error=%v", action, entityType, entityID, err)
+	}
+}
@@ -1,8 +1,10 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
@@ -22,6 +24,10 @@ type AuthService struct {
 	jwtService        *utils.JWTService
 	emailQueueService *EmailQueueService
 	otpService        *OTPService
+	otpQuotaService   *OTPQuotaService
+	loginSecurity     *LoginSecurityService
+	passwordScreening *PasswordScreeningService
+	denylist          *AccessTokenDenylistService
 }
 
 // NewAuthService creates a new authentication service
@@ -33,15 +39,21 @@ func NewAuthService(cfg *config.Config) *AuthService {
 		jwtService:        utils.NewJWTService(&cfg.JWT),
 		emailQueueService: emailQueueService,
 		otpService:        NewOTPService(),
+		otpQuotaService:   NewOTPQuotaService(cfg, emailQueueService),
+		loginSecurity:     NewLoginSecurityService(cfg, emailQueueService),
+		passwordScreening: NewPasswordScreeningService(cfg),
+		denylist:          NewAccessTokenDenylistService(cfg),
 	}
 
 }
 
 // Register creates a new user account
-func (s *AuthService) Register(req *models.CreateUserRequest) (*models.UserResponse, error) {
+func (s *AuthService) Register(ctx context.Context, req *models.CreateUserRequest) (*models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check if user already exists
 	var existingUser models.User
-	if result := s.db.Where("email = ?", strings.ToLower(req.Email)).First(&existingUser); result.Error == nil {
+	if result := db.Where("email = ?", strings.ToLower(req.Email)).First(&existingUser); result.Error == nil {
 		return nil, errors.New("User with this email already exists")
 	} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
 		return nil, result.Error
@@ -54,54 +66,60 @@ func (s *AuthService) Register(req *models.CreateUserRequest) (*models.UserRespo
 		LastName:  req.LastName,
 	}
 
-	// Hash the password
-	if err := user.HashPassword(req.Password); err != nil {
-		return nil, err
-	}
-
-	// Get user role
-	var userRole models.Role
-	if err := s.db.Where("name = ?", "user").First(&userRole).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			// Create default user role if not exists
-			userRole = models.Role{
-				Name:        "user",
-				Description: "Default user role",
-			}
-			if err := s.db.Create(&userRole).Error; err != nil {
-				return nil, err
-			}
-		} else {
+	// Claim the username, if one was chosen
+	if req.Username != "" {
+		username := strings.ToLower(req.Username)
+		reason, err := usernameUnavailable(db, username, uuid.Nil)
+		if err != nil {
 			return nil, err
 		}
+		if reason != "" {
+			return nil, fmt.Errorf("Username is not available (%s)", reason)
+		}
+		user.Username = &username
 	}
 
-	// Assign user role
-	user.Roles = []*models.Role{&userRole}
-
-	// Save user to database in a transaction
-	tx := s.db.Begin()
-	if err := tx.Create(&user).Error; err != nil {
-		tx.Rollback()
+	// Reject passwords known from data breaches
+	if err := s.passwordScreening.CheckPassword(ctx, req.Password); err != nil {
 		return nil, err
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
+	// Hash the password
+	if err := user.HashPassword(req.Password); err != nil {
 		return nil, err
 	}
 
-	// Generate and send OTP for email verification
-	otp := s.otpService.GenerateOTP(6) // 6-digit OTP
-	if err := s.otpService.SaveOTP(user.Email, "registration", otp); err != nil {
-		// Log the error but don't fail the registration
-		fmt.Printf("Failed to save registration OTP: %v\n", err)
+	// Assign user role
+	userRole, err := defaultUserRole(db)
+	if err != nil {
+		return nil, err
 	}
+	user.Roles = []*models.Role{userRole}
 
-	// Send verification email with OTP
-	if err := s.sendVerificationOTPEmail(user.Email, otp); err != nil {
-		// Log the error but don't fail the registration
-		fmt.Printf("Failed to send verification email with OTP: %v\n", err)
+	// Save user to database in a transaction, sending the verification OTP once it commits
+	err = database.WithinTx(ctx, func(ctx context.Context) error {
+		if err := database.Conn(ctx, s.db).Create(&user).Error; err != nil {
+			return err
+		}
+
+		database.AfterCommit(ctx, func() {
+			// Generate and send OTP for email verification
+			otp := s.otpService.GenerateOTP(6) // 6-digit OTP
+			if err := s.otpService.SaveOTP(ctx, user.Email, "registration", otp); err != nil {
+				// Log the error but don't fail the registration
+				fmt.Printf("Failed to save registration OTP: %v\n", err)
+			}
+
+			// Send verification email with OTP
+			if err := s.sendVerificationOTPEmail(user.Email, otp); err != nil {
+				// Log the error but don't fail the registration
+				fmt.Printf("Failed to send verification email with OTP: %v\n", err)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Return user data (excluding sensitive information)
@@ -109,11 +127,14 @@ func (s *AuthService) Register(req *models.CreateUserRequest) (*models.UserRespo
 	return &resp, nil
 }
 
-// Login authenticates a user and returns JWT tokens
-func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenResponse, error) {
+// Login authenticates a user and returns JWT tokens. Logins from a new device or country
+// trigger a security alert email.
+func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, client *models.LoginContext) (*models.TokenResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Find user by email
 	var user models.User
-	if err := s.db.Preload("Roles.Permissions").Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
+	if err := db.Preload("Roles.Permissions").Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("Invalid email or password")
 		}
@@ -125,8 +146,19 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenResponse, er
 		return nil, errors.New("Invalid email or password")
 	}
 
+	// A login reported as unrecognized locks the account until the password is reset
+	if user.PasswordResetRequired {
+		return nil, errors.New("Password reset required, please reset your password to sign in")
+	}
+
+	return s.startSession(ctx, &user, client)
+}
+
+// startSession issues a signed-in user's token pair and records the login. The user's roles and
+// permissions must be loaded.
+func (s *AuthService) startSession(ctx context.Context, user *models.User, client *models.LoginContext) (*models.TokenResponse, error) {
 	// Generate tokens
-	tokenResponse, err := s.jwtService.GenerateTokens(&user)
+	tokenResponse, err := s.jwtService.GenerateTokens(user)
 	if err != nil {
 		return nil, err
 	}
@@ -138,20 +170,45 @@ func (s *AuthService) Login(req *models.LoginRequest) (*models.TokenResponse, er
 		TokenHash: refreshTokenHash,
 		Type:      models.RefreshToken,
 		ExpiresAt: time.Now().Add(s.jwtConfig.RefreshTokenTTL),
+		Device:    client.UserAgent,
+		IP:        client.IP,
 	}
-	if err := s.db.Create(&refreshToken).Error; err != nil {
+	if err := s.db.WithContext(ctx).Create(&refreshToken).Error; err != nil {
 		return nil, err
 	}
 
+	s.loginSecurity.RecordLogin(ctx, user, client)
+
 	return tokenResponse, nil
 }
 
+// defaultUserRole returns the role new accounts get, creating it if it does not exist yet
+func defaultUserRole(db *gorm.DB) (*models.Role, error) {
+	var userRole models.Role
+	if err := db.Where("name = ?", "user").First(&userRole).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		// Create default user role if not exists
+		userRole = models.Role{
+			Name:        "user",
+			Description: "Default user role",
+		}
+		if err := db.Create(&userRole).Error; err != nil {
+			return nil, err
+		}
+	}
+	return &userRole, nil
+}
+
 // RefreshToken generates new access and refresh tokens using a valid refresh token
-func (s *AuthService) RefreshToken(req *models.RefreshTokenRequest) (*models.TokenResponse, error) {
+func (s *AuthService) RefreshToken(ctx context.Context, req *models.RefreshTokenRequest) (*models.TokenResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check if token exists in database and is not revoked (primary validation)
 	refreshTokenHash := utils.HashToken(req.RefreshToken)
 	var token models.Token
-	if err := s.db.Where("token_hash = ? AND type = ? AND revoked = ? AND expires_at > ?",
+	if err := db.Where("token_hash = ? AND type = ? AND revoked = ? AND expires_at > ?",
 		refreshTokenHash,
 		models.RefreshToken,
 		false,
@@ -164,7 +221,7 @@ func (s *AuthService) RefreshToken(req *models.RefreshTokenRequest) (*models.Tok
 
 	// Get user using token's user ID
 	var user models.User
-	if err := s.db.Preload("Roles.Permissions").Where("id = ?", token.UserID).First(&user).Error; err != nil {
+	if err := db.Preload("Roles.Permissions").Where("id = ?", token.UserID).First(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -175,7 +232,7 @@ func (s *AuthService) RefreshToken(req *models.RefreshTokenRequest) (*models.Tok
 	}
 
 	// Revoke old refresh token
-	if err := s.db.Model(&token).Update("revoked", true).Error; err != nil {
+	if err := db.Model(&token).Update("revoked", true).Error; err != nil {
 		return nil, err
 	}
 
@@ -187,7 +244,7 @@ func (s *AuthService) RefreshToken(req *models.RefreshTokenRequest) (*models.Tok
 		Type:      models.RefreshToken,
 		ExpiresAt: time.Now().Add(s.jwtConfig.RefreshTokenTTL),
 	}
-	if err := s.db.Create(&newRefreshToken).Error; err != nil {
+	if err := db.Create(&newRefreshToken).Error; err != nil {
 		return nil, err
 	}
 
@@ -195,13 +252,15 @@ func (s *AuthService) RefreshToken(req *models.RefreshTokenRequest) (*models.Tok
 }
 
 // VerifyEmail verifies a user's email using the verification code
-func (s *AuthService) VerifyEmail(req *models.VerifyEmailRequest) error {
+func (s *AuthService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRequest) error {
+	db := s.db.WithContext(ctx)
+
 	// This method is kept for backward compatibility
 	// New code should use VerifyOTP instead
 
 	// Find user by verification code
 	var user models.User
-	if err := s.db.Where("verification_code = ?", req.VerificationCode).First(&user).Error; err != nil {
+	if err := db.Where("verification_code = ?", req.VerificationCode).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return errors.New("Invalid verification code")
 		}
@@ -212,7 +271,7 @@ func (s *AuthService) VerifyEmail(req *models.VerifyEmailRequest) error {
 	user.IsEmailVerified = true
 	user.VerificationCode = ""
 
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := db.Save(&user).Error; err != nil {
 		return err
 	}
 
@@ -220,9 +279,9 @@ func (s *AuthService) VerifyEmail(req *models.VerifyEmailRequest) error {
 }
 
 // VerifyOTP verifies an OTP for a given purpose
-func (s *AuthService) VerifyOTP(req *models.OTPVerifyRequest) error {
+func (s *AuthService) VerifyOTP(ctx context.Context, req *models.OTPVerifyRequest) error {
 	// Verify OTP
-	valid, err := s.otpService.VerifyOTP(req.Identifier, req.OTPType, req.OTPCode)
+	valid, err := s.otpService.VerifyOTP(ctx, req.Identifier, req.OTPType, req.OTPCode)
 	if err != nil {
 		return fmt.Errorf("error verifying OTP: %w", err)
 	}
@@ -234,7 +293,7 @@ func (s *AuthService) VerifyOTP(req *models.OTPVerifyRequest) error {
 	// Handle specific OTP types
 	switch req.OTPType {
 	case "registration":
-		return s.handleRegistrationOTPVerification(req.Identifier)
+		return s.handleRegistrationOTPVerification(ctx, req.Identifier)
 	case "password_reset":
 		return nil // Password reset requires additional steps, handled separately
 	default:
@@ -243,16 +302,18 @@ func (s *AuthService) VerifyOTP(req *models.OTPVerifyRequest) error {
 }
 
 // handleRegistrationOTPVerification marks the user's email as verified after OTP validation
-func (s *AuthService) handleRegistrationOTPVerification(email string) error {
+func (s *AuthService) handleRegistrationOTPVerification(ctx context.Context, email string) error {
+	db := s.db.WithContext(ctx)
+
 	var user models.User
-	if err := s.db.Where("email = ?", email).First(&user).Error; err != nil {
+	if err := db.Where("email = ?", email).First(&user).Error; err != nil {
 		return err
 	}
 
 	// Mark email as verified
 	user.IsEmailVerified = true
 
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := db.Save(&user).Error; err != nil {
 		return err
 	}
 
@@ -260,10 +321,17 @@ func (s *AuthService) handleRegistrationOTPVerification(email string) error {
 }
 
 // SendPasswordResetEmail sends a password reset OTP to the user's email
-func (s *AuthService) SendPasswordResetEmail(req *models.ResetPasswordRequest) error {
+func (s *AuthService) SendPasswordResetEmail(ctx context.Context, req *models.ResetPasswordRequest) error {
+	db := s.db.WithContext(ctx)
+
+	// Checked before the user lookup so the response doesn't reveal whether the email is registered
+	if err := s.otpService.Ready(ctx); err != nil {
+		return err
+	}
+
 	// Find user by email
 	var user models.User
-	if err := s.db.Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
+	if err := db.Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// For security reasons, don't reveal that the email doesn't exist
 			return nil
@@ -275,7 +343,7 @@ func (s *AuthService) SendPasswordResetEmail(req *models.ResetPasswordRequest) e
 	otp := s.otpService.GenerateOTP(6) // 6-digit OTP
 
 	// Save OTP to Redis with password_reset type
-	if err := s.otpService.SaveOTP(user.Email, "password_reset", otp); err != nil {
+	if err := s.otpService.SaveOTP(ctx, user.Email, "password_reset", otp); err != nil {
 		return fmt.Errorf("failed to save password reset OTP: %w", err)
 	}
 
@@ -293,10 +361,12 @@ func (s *AuthService) sendPasswordResetOTPEmail(email string, otp string) error
 }
 
 // ResetPassword resets a user's password using a reset token or OTP
-func (s *AuthService) ResetPassword(req *models.UpdatePasswordRequest) error {
+func (s *AuthService) ResetPassword(ctx context.Context, req *models.UpdatePasswordRequest) error {
+	db := s.db.WithContext(ctx)
+
 	// Check if this is a token-based reset (legacy)
 	var token models.Token
-	tokenErr := s.db.Where("token_hash = ? AND type = ? AND revoked = ? AND expires_at > ?",
+	tokenErr := db.Where("token_hash = ? AND type = ? AND revoked = ? AND expires_at > ?",
 		req.ResetToken,
 		"reset",
 		false,
@@ -306,35 +376,33 @@ func (s *AuthService) ResetPassword(req *models.UpdatePasswordRequest) error {
 	if tokenErr == nil {
 		// Find user
 		var user models.User
-		if err := s.db.Where("id = ?", token.UserID).First(&user).Error; err != nil {
+		if err := db.Where("id = ?", token.UserID).First(&user).Error; err != nil {
 			return err
 		}
 
 		// Update password
-		if err := user.HashPassword(req.NewPassword); err != nil {
-			return err
-		}
-
-		// Start transaction
-		tx := s.db.Begin()
-
-		// Save user
-		if err := tx.Save(&user).Error; err != nil {
-			tx.Rollback()
+		if err := s.passwordScreening.CheckPassword(ctx, req.NewPassword); err != nil {
 			return err
 		}
-
-		// Revoke token
-		if err := tx.Model(&token).Update("revoked", true).Error; err != nil {
-			tx.Rollback()
+		if err := user.HashPassword(req.NewPassword); err != nil {
 			return err
 		}
+		user.PasswordResetRequired = false
 
-		// Commit transaction
-		if err := tx.Commit().Error; err != nil {
+		// Save user, revoke the token and sign out its sessions together
+		if err := database.WithinTx(ctx, func(ctx context.Context) error {
+			tx := database.Conn(ctx, s.db)
+			if err := tx.Save(&user).Error; err != nil {
+				return err
+			}
+			if err := revokeRefreshTokens(tx, user.ID); err != nil {
+				return err
+			}
+			return tx.Model(&token).Update("revoked", true).Error
+		}); err != nil {
 			return err
 		}
-
+		s.revokeAccessTokens(ctx, user.ID)
 		return nil
 	}
 
@@ -344,6 +412,11 @@ func (s *AuthService) ResetPassword(req *models.UpdatePasswordRequest) error {
 		return errors.New("Email and OTP code are required for password reset")
 	}
 
+	// Screen the new password first so a rejected password doesn't consume the OTP
+	if err := s.passwordScreening.CheckPassword(ctx, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Verify the OTP before proceeding with password reset
 	otpReq := &models.OTPVerifyRequest{
 		Identifier: req.EmailToken,
@@ -351,13 +424,16 @@ func (s *AuthService) ResetPassword(req *models.UpdatePasswordRequest) error {
 		OTPType:    "password_reset",
 	}
 
-	if err := s.VerifyOTP(otpReq); err != nil {
+	if err := s.VerifyOTP(ctx, otpReq); err != nil {
+		if errors.Is(err, ErrOTPUnavailable) {
+			return ErrOTPUnavailable
+		}
 		return errors.New("Invalid or expired OTP code")
 	}
 
 	// OTP is valid, now proceed with password reset
 	var user models.User
-	if err := s.db.Where("email = ?", req.EmailToken).First(&user).Error; err != nil {
+	if err := db.Where("email = ?", req.EmailToken).First(&user).Error; err != nil {
 		return errors.New("User not found")
 	}
 
@@ -365,46 +441,155 @@ func (s *AuthService) ResetPassword(req *models.UpdatePasswordRequest) error {
 	if err := user.HashPassword(req.NewPassword); err != nil {
 		return err
 	}
+	user.PasswordResetRequired = false
 
-	// Save user
-	if err := s.db.Save(&user).Error; err != nil {
+	// Save user and sign out its sessions together
+	if err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, user.ID)
+	}); err != nil {
 		return err
 	}
-
+	s.revokeAccessTokens(ctx, user.ID)
 	return nil
 }
 
-// Logout revokes a user's refresh tokens
-func (s *AuthService) Logout(userID uuid.UUID, all bool) error {
+// ReportUnrecognizedLogin revokes all sessions of the user a login alert was sent to and
+// requires a password reset
+func (s *AuthService) ReportUnrecognizedLogin(ctx context.Context, token string) error {
+	return s.loginSecurity.ReportUnrecognizedLogin(ctx, token)
+}
+
+// ListDevices returns the devices the user has signed in from
+func (s *AuthService) ListDevices(ctx context.Context, userID uuid.UUID) ([]models.UserDevice, error) {
+	return s.loginSecurity.ListDevices(ctx, userID)
+}
+
+// Logout revokes the access token of the session signing out. Signing out of all sessions also
+// revokes the user's refresh tokens and every access token issued to them.
+func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID, claims *utils.Claims, all bool) error {
 	if all {
 		// Revoke all refresh tokens for the user
-		if err := s.db.Model(&models.Token{}).
-			Where("user_id = ? AND type = ? AND revoked = ?", userID, models.RefreshToken, false).
-			Update("revoked", true).Error; err != nil {
+		if err := revokeRefreshTokens(s.db.WithContext(ctx), userID); err != nil {
 			return err
 		}
-	} else {
-		// If token hash is provided, only revoke that specific token
-		// This feature would require passing the refresh token to the logout endpoint
+		s.revokeAccessTokens(ctx, userID)
+		return nil
 	}
 
+	if claims != nil {
+		if err := s.denylist.RevokeToken(ctx, claims); err != nil {
+			log.Printf("Failed to revoke access token: User=%s, Error=%v", userID, err)
+		}
+	}
 	return nil
 }
 
+// revokeRefreshTokens revokes every refresh token of a user
+func revokeRefreshTokens(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&models.Token{}).
+		Where("user_id = ? AND type = ? AND revoked = ?", userID, models.RefreshToken, false).
+		Update("revoked", true).Error
+}
+
+// revokeAccessTokens denylists every access token issued to a user so far. Failures are only
+// logged: without Redis, access tokens stay valid until they expire, as before the denylist.
+func (s *AuthService) revokeAccessTokens(ctx context.Context, userID uuid.UUID) {
+	if err := s.denylist.RevokeUserTokens(ctx, userID); err != nil {
+		log.Printf("Failed to revoke access tokens: User=%s, Error=%v", userID, err)
+	}
+}
+
+// CreateAdmin creates a verified account with the admin role, or grants the admin role to an
+// existing account with that email. Used by operators to bootstrap the first administrator.
+func (s *AuthService) CreateAdmin(ctx context.Context, req *models.CreateUserRequest) (*models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var adminRole models.Role
+	if err := db.Where("name = ?", "admin").First(&adminRole).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Admin role not found, run the migrations first")
+		}
+		return nil, err
+	}
+
+	var user models.User
+	err := db.Preload("Roles").Where("email = ?", strings.ToLower(req.Email)).First(&user).Error
+	switch {
+	case err == nil:
+		if err := db.Model(&user).Association("Roles").Append(&adminRole); err != nil {
+			return nil, fmt.Errorf("failed to grant admin role: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.passwordScreening.CheckPassword(ctx, req.Password); err != nil {
+			return nil, err
+		}
+		user = models.User{
+			Email:           strings.ToLower(req.Email),
+			FirstName:       req.FirstName,
+			LastName:        req.LastName,
+			Phone:           req.Phone,
+			IsEmailVerified: true,
+			Roles:           []*models.Role{&adminRole},
+		}
+		if err := user.HashPassword(req.Password); err != nil {
+			return nil, err
+		}
+		if err := db.Create(&user).Error; err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+
+	recordAuditLog(db, nil, "user.admin_granted", "user", user.ID.String(), nil, nil)
+
+	if err := db.Preload("Roles.Permissions").First(&user, "id = ?", user.ID).Error; err != nil {
+		return nil, err
+	}
+	resp := user.ToResponse()
+	return &resp, nil
+}
+
+// RevokeAllSessions revokes every active refresh token, signing all users out once their access
+// tokens expire. Used after rotating the JWT secret in response to a leak.
+func (s *AuthService) RevokeAllSessions(ctx context.Context) (int64, error) {
+	db := s.db.WithContext(ctx)
+
+	result := db.Model(&models.Token{}).
+		Where("type = ? AND revoked = ?", models.RefreshToken, false).
+		Update("revoked", true)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	recordAuditLog(db, nil, "auth.sessions_revoked", "token", "", nil, map[string]interface{}{
+		"revoked": result.RowsAffected,
+	})
+	return result.RowsAffected, nil
+}
+
 // GetUserByID retrieves a user by ID
-func (s *AuthService) GetUserByID(userID uuid.UUID) (*models.User, error) {
+func (s *AuthService) GetUserByID(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	db := s.db.WithContext(ctx)
+
 	var user models.User
-	if err := s.db.Preload("Roles.Permissions").Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := db.Preload("Roles.Permissions").Where("id = ?", userID).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // UpdateProfile updates user profile information
-func (s *AuthService) UpdateProfile(userID uuid.UUID, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error) {
+func (s *AuthService) UpdateProfile(ctx context.Context, userID uuid.UUID, req *models.UpdateProfileRequest) (*models.UserProfileResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Get user first
 	var user models.User
-	if err := s.db.Preload("Organization").Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := db.Preload("Organization").Where("id = ?", userID).First(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -412,9 +597,50 @@ func (s *AuthService) UpdateProfile(userID uuid.UUID, req *models.UpdateProfileR
 	user.FirstName = req.FirstName
 	user.LastName = req.LastName
 	user.Phone = req.Phone
+	user.DateOfBirth = req.DateOfBirth
 
 	// Save user
-	if err := s.db.Save(&user).Error; err != nil {
+	if err := db.Save(&user).Error; err != nil {
+		return nil, err
+	}
+
+	response := user.ToProfileResponse()
+	return &response, nil
+}
+
+// PatchProfile fills in or changes the profile fields present in the request, so users who
+// registered with an email and password only can complete their profile step by step
+func (s *AuthService) PatchProfile(ctx context.Context, userID uuid.UUID, req *models.PatchProfileRequest) (*models.UserProfileResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.Preload("Organization").Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	if req.FirstName != nil {
+		user.FirstName = strings.TrimSpace(*req.FirstName)
+		columns = append(columns, "first_name")
+	}
+	if req.LastName != nil {
+		user.LastName = strings.TrimSpace(*req.LastName)
+		columns = append(columns, "last_name")
+	}
+	if req.Phone != nil {
+		user.Phone = *req.Phone
+		columns = append(columns, "phone")
+	}
+	if req.DateOfBirth != nil {
+		user.DateOfBirth = *req.DateOfBirth
+		columns = append(columns, "date_of_birth")
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("No profile fields to update")
+	}
+
+	// Updating from the struct keeps the encrypted columns going through their serializer
+	if err := db.Model(&user).Select(columns).Updates(&user).Error; err != nil {
 		return nil, err
 	}
 
@@ -423,10 +649,12 @@ func (s *AuthService) UpdateProfile(userID uuid.UUID, req *models.UpdateProfileR
 }
 
 // ChangePassword changes user password (for authenticated users)
-func (s *AuthService) ChangePassword(userID uuid.UUID, req *models.ChangePasswordRequest) error {
+func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req *models.ChangePasswordRequest) error {
+	db := s.db.WithContext(ctx)
+
 	// Get user
 	var user models.User
-	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
 		return err
 	}
 
@@ -435,16 +663,27 @@ func (s *AuthService) ChangePassword(userID uuid.UUID, req *models.ChangePasswor
 		return errors.New("Current password is incorrect")
 	}
 
+	// Reject passwords known from data breaches
+	if err := s.passwordScreening.CheckPassword(ctx, req.NewPassword); err != nil {
+		return err
+	}
+
 	// Hash new password
 	if err := user.HashPassword(req.NewPassword); err != nil {
 		return err
 	}
 
-	// Save user
-	if err := s.db.Save(&user).Error; err != nil {
+	// Save user and sign out its sessions together
+	if err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, user.ID)
+	}); err != nil {
 		return err
 	}
-
+	s.revokeAccessTokens(ctx, user.ID)
 	return nil
 }
 
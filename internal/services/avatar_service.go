@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidAvatar is returned when an uploaded avatar is too large or not a usable image
+var ErrInvalidAvatar = errors.New("Avatar must be a PNG, JPEG or GIF image")
+
+// AvatarService runs uploaded profile pictures through the image pipeline: each upload is
+// center-cropped to a square, scaled down to every avatar size and re-encoded as PNG or JPEG.
+// The sizes are stored with the user and served from this API.
+type AvatarService struct {
+	db       *gorm.DB
+	maxBytes int64
+	cacheTTL time.Duration
+}
+
+// NewAvatarService creates a new avatar service
+func NewAvatarService(cfg *config.Config) *AvatarService {
+	return &AvatarService{
+		db:       database.DB,
+		maxBytes: cfg.ImageProxy.MaxBytes,
+		cacheTTL: cfg.ImageProxy.CacheTTL,
+	}
+}
+
+// Upload replaces a user's avatar with an uploaded image
+func (s *AvatarService) Upload(ctx context.Context, userID uuid.UUID, upload io.Reader) (*models.UserProfileResponse, error) {
+	source, err := io.ReadAll(io.LimitReader(upload, s.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	if int64(len(source)) > s.maxBytes {
+		return nil, fmt.Errorf("Avatar must not exceed %d bytes", s.maxBytes)
+	}
+
+	src, err := decodeImage(source)
+	if err != nil {
+		log.Printf("Rejected avatar of user %s: %v", userID, err)
+		return nil, ErrInvalidAvatar
+	}
+	bounds := src.Bounds()
+	if min(bounds.Dx(), bounds.Dy()) < models.AvatarSizes[0] {
+		return nil, fmt.Errorf("Avatar must be at least %dx%d pixels", models.AvatarSizes[0], models.AvatarSizes[0])
+	}
+
+	square := cropSquare(src)
+	avatars := make([]models.UserAvatar, len(models.AvatarSizes))
+	for i, size := range models.AvatarSizes {
+		data, err := encodeImage(scaleDown(square, size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode avatar: %w", err)
+		}
+		avatars[i] = models.UserAvatar{UserID: userID, Size: size, Data: data}
+	}
+
+	var user models.User
+	err = database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Preload("Organization").First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserAvatar{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&avatars).Error; err != nil {
+			return fmt.Errorf("failed to save avatar: %w", err)
+		}
+
+		now := time.Now()
+		user.AvatarUpdatedAt = &now
+		return tx.Model(&user).Update("avatar_updated_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := user.ToProfileResponse()
+	return &resp, nil
+}
+
+// Delete removes a user's avatar
+func (s *AvatarService) Delete(ctx context.Context, userID uuid.UUID) (*models.UserProfileResponse, error) {
+	var user models.User
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Preload("Organization").First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserAvatar{}).Error; err != nil {
+			return err
+		}
+
+		user.AvatarUpdatedAt = nil
+		return tx.Model(&user).Update("avatar_updated_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := user.ToProfileResponse()
+	return &resp, nil
+}
+
+// Avatar returns one of the stored sizes of a user's avatar
+func (s *AvatarService) Avatar(ctx context.Context, userID uuid.UUID, size int) (*ProxiedImage, error) {
+	var avatar models.UserAvatar
+	if err := s.db.WithContext(ctx).First(&avatar, "user_id = ? AND size = ?", userID, size).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+
+	return newProxiedImage(avatar.Data, s.cacheTTL), nil
+}
+
+// cropSquare cuts the largest centered square out of an image
+func cropSquare(src image.Image) image.Image {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+	rect := image.Rect(x0, y0, x0+side, y0+side)
+
+	if sub, ok := src.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			square.Set(x, y, src.At(x0+x, y0+y))
+		}
+	}
+	return square
+}
@@ -0,0 +1,424 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskBulkJob is the asynq task type for processing a bulk job
+const TaskBulkJob = "bulk:process"
+
+const (
+	// maxBulkJobItems is the most items one bulk job takes
+	maxBulkJobItems = 5000
+	// bulkJobChunkSize is how many items are processed between progress updates
+	bulkJobChunkSize = 50
+)
+
+// ErrBulkJobNotFound is returned for a bulk job the organization does not have
+var ErrBulkJobNotFound = errors.New("Job not found")
+
+// BulkJobPayload is the payload of a queued bulk job task
+type BulkJobPayload struct {
+	JobID uuid.UUID `json:"job_id"`
+}
+
+// BulkJobService queues operations on many tickets or recipients of an organization, applies them
+// item by item in the background and reports their progress. Each item is applied like its
+// single-item endpoint would, so one failing item does not stop the others.
+type BulkJobService struct {
+	db                *gorm.DB
+	client            *asynq.Client
+	revocationService *TicketRevocationService
+	allocationService *AllocationService
+	emailQueueService *EmailQueueService
+}
+
+// NewBulkJobService creates a new bulk job service
+func NewBulkJobService(cfg *config.Config) *BulkJobService {
+	return &BulkJobService{
+		db:                database.DB,
+		client:            asynq.NewClient(redis.QueueConnOpt(cfg)),
+		revocationService: NewTicketRevocationService(cfg),
+		allocationService: NewAllocationService(cfg),
+		emailQueueService: NewEmailQueueService(cfg),
+	}
+}
+
+// RevokeTickets queues a job revoking tickets of an organization with the same reason
+func (s *BulkJobService) RevokeTickets(ctx context.Context, orgID, actorID uuid.UUID, req *models.BulkRevokeTicketsRequest) (*models.BulkJob, error) {
+	options := map[string]string{
+		"reason": string(req.Reason),
+		"note":   req.Note,
+		"silent": fmt.Sprint(req.Silent),
+	}
+	return s.create(ctx, orgID, actorID, models.BulkOperationRevokeTickets, options, ticketItems(req.TicketIDs))
+}
+
+// ResendTickets queues a job emailing tickets of an organization to their attendees again: the
+// listed tickets, or every valid and checked-in ticket of an event that is not revoked
+func (s *BulkJobService) ResendTickets(ctx context.Context, orgID, actorID uuid.UUID, req *models.BulkResendTicketsRequest) (*models.BulkJob, error) {
+	ticketIDs := req.TicketIDs
+	if req.EventID != 0 {
+		db := s.db.WithContext(ctx)
+		var event models.Event
+		if err := db.Select("id").Where("id = ? AND organization_id = ?", req.EventID, orgID).First(&event).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("Event not found")
+			}
+			return nil, err
+		}
+		err := db.Model(&models.Ticket{}).
+			Where("event_id = ? AND organization_id = ? AND status IN ? AND revoked_at IS NULL", event.ID, orgID,
+				[]models.TicketStatus{models.TicketStatusValid, models.TicketStatusCheckedIn}).
+			Order("created_at").
+			Pluck("id", &ticketIDs).Error
+		if err != nil {
+			return nil, err
+		}
+		if len(ticketIDs) == 0 {
+			return nil, errors.New("The event has no tickets to resend")
+		}
+	}
+
+	options := map[string]string{}
+	if req.EventID != 0 {
+		options["event_id"] = fmt.Sprint(req.EventID)
+	}
+	return s.create(ctx, orgID, actorID, models.BulkOperationResendTickets, options, ticketItems(ticketIDs))
+}
+
+// IssueComps queues a job issuing a comp ticket from an allocation to each recipient. The
+// allocation must have a ticket left for every recipient when the job is queued.
+func (s *BulkJobService) IssueComps(ctx context.Context, orgID, actorID uuid.UUID, req *models.BulkIssueCompsRequest) (*models.BulkJob, error) {
+	var allocation models.TicketAllocation
+	if err := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", req.AllocationID, orgID).First(&allocation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Allocation not found")
+		}
+		return nil, err
+	}
+	if allocation.Type != models.AllocationTypeComp {
+		return nil, errors.New("Comp tickets can only be issued from comp allocations")
+	}
+	if allocation.Remaining() < len(req.Recipients) {
+		return nil, fmt.Errorf("Only %d comp tickets remain in this allocation", allocation.Remaining())
+	}
+
+	items := make([]models.BulkJobItem, len(req.Recipients))
+	for i, recipient := range req.Recipients {
+		items[i] = models.BulkJobItem{
+			Reference: strings.ToLower(strings.TrimSpace(recipient.Email)),
+			Name:      strings.TrimSpace(recipient.Name),
+		}
+	}
+	options := map[string]string{"allocation_id": allocation.ID.String()}
+	return s.create(ctx, orgID, actorID, models.BulkOperationIssueComps, options, items)
+}
+
+// List returns the requested page of an organization's bulk jobs, newest first, without their items
+func (s *BulkJobService) List(ctx context.Context, orgID uuid.UUID, query *models.BulkJobQuery) ([]models.BulkJob, *models.PageMeta, error) {
+	search := s.db.WithContext(ctx).Model(&models.BulkJob{}).Where("organization_id = ?", orgID)
+	if query.Operation != "" {
+		search = search.Where("operation = ?", query.Operation)
+	}
+	if query.Status != "" {
+		search = search.Where("status = ?", query.Status)
+	}
+
+	jobs := []models.BulkJob{}
+	meta, err := database.Paginate(search.Order("created_at DESC").Order("id ASC"), query.PageQuery, &jobs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return jobs, meta, nil
+}
+
+// Get returns a bulk job of an organization with its progress and every item's outcome
+func (s *BulkJobService) Get(ctx context.Context, orgID, jobID uuid.UUID) (*models.BulkJob, error) {
+	var job models.BulkJob
+	err := s.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC") }).
+		Where("id = ? AND organization_id = ?", jobID, orgID).
+		First(&job).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBulkJobNotFound
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+// Process applies a queued bulk job to its pending items in order, recording each outcome and
+// the job's progress as it goes. A retried job resumes with the items it had not reached. The
+// last retry of a job that keeps failing marks it failed.
+func (s *BulkJobService) Process(ctx context.Context, jobID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	var job models.BulkJob
+	if err := db.First(&job, "id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Job removed after it was queued
+		}
+		return err
+	}
+	if job.Status == models.BulkJobCompleted || job.Status == models.BulkJobFailed {
+		return nil
+	}
+
+	if job.StartedAt == nil {
+		now := time.Now()
+		job.StartedAt = &now
+	}
+	if err := db.Model(&job).Updates(map[string]interface{}{"status": models.BulkJobRunning, "started_at": job.StartedAt}).Error; err != nil {
+		return fmt.Errorf("failed to start bulk job: %w", err)
+	}
+
+	err := s.processItems(ctx, &job)
+	if err != nil {
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried < maxRetry {
+			return err
+		}
+		log.Printf("Bulk job failed: ID=%s, Error=%v", job.ID, err)
+		return s.finish(db, &job, models.BulkJobFailed, err.Error())
+	}
+
+	log.Printf("Bulk job completed: ID=%s, Operation=%s, Succeeded=%d, Failed=%d", job.ID, job.Operation, job.Succeeded, job.Failed)
+	return s.finish(db, &job, models.BulkJobCompleted, "")
+}
+
+// processItems applies a job to its pending items a chunk at a time, updating its progress after
+// each chunk
+func (s *BulkJobService) processItems(ctx context.Context, job *models.BulkJob) error {
+	db := s.db.WithContext(ctx)
+	events := make(map[uint]*models.Event)
+
+	for {
+		var items []models.BulkJobItem
+		err := db.Where("job_id = ? AND status = ?", job.ID, models.BulkItemPending).
+			Order("position ASC").Limit(bulkJobChunkSize).Find(&items).Error
+		if err != nil {
+			return fmt.Errorf("failed to load bulk job items: %w", err)
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		for i := range items {
+			item := &items[i]
+			result, err := s.apply(ctx, job, item, events)
+			now := time.Now()
+			item.ProcessedAt = &now
+			if err != nil {
+				item.Status = models.BulkItemFailed
+				item.Error = err.Error()
+				if len(item.Error) > 500 {
+					item.Error = item.Error[:500]
+				}
+			} else {
+				item.Status = models.BulkItemSucceeded
+				item.Result = result
+			}
+			if err := db.Model(item).Select("status", "result", "error", "processed_at").Updates(item).Error; err != nil {
+				return fmt.Errorf("failed to record bulk job item: %w", err)
+			}
+		}
+
+		if err := s.recordProgress(db, job); err != nil {
+			return err
+		}
+	}
+}
+
+// apply applies a job's operation to one of its items and returns what the item produced
+func (s *BulkJobService) apply(ctx context.Context, job *models.BulkJob, item *models.BulkJobItem, events map[uint]*models.Event) (string, error) {
+	switch job.Operation {
+	case models.BulkOperationRevokeTickets:
+		ticketID, err := uuid.Parse(item.Reference)
+		if err != nil {
+			return "", ErrTicketNotFound
+		}
+		revocation, err := s.revocationService.Revoke(ctx, job.OrganizationID, ticketID, job.CreatedBy, &models.TicketRevokeRequest{
+			Reason: models.TicketRevocationReason(job.Options["reason"]),
+			Note:   job.Options["note"],
+			Silent: job.Options["silent"] == "true",
+		})
+		if err != nil {
+			return "", err
+		}
+		return revocation.ID.String(), nil
+
+	case models.BulkOperationResendTickets:
+		ticketID, err := uuid.Parse(item.Reference)
+		if err != nil {
+			return "", ErrTicketNotFound
+		}
+		return "", s.resendTicket(ctx, job.OrganizationID, ticketID, events)
+
+	case models.BulkOperationIssueComps:
+		allocationID, err := uuid.Parse(job.Options["allocation_id"])
+		if err != nil {
+			return "", errors.New("Allocation not found")
+		}
+		attendees, err := s.allocationService.IssueComps(ctx, job.OrganizationID, allocationID, &models.IssueCompsRequest{
+			Recipients: []models.CompRecipient{{Name: item.Name, Email: item.Reference}},
+		})
+		if err != nil {
+			return "", err
+		}
+		return attendees[0].TicketID.String(), nil
+
+	default:
+		return "", fmt.Errorf("Unsupported bulk operation: %s", job.Operation)
+	}
+}
+
+// resendTicket queues the ticket email of a ticket of an organization again. Cancelled and revoked
+// tickets are refused.
+func (s *BulkJobService) resendTicket(ctx context.Context, orgID, ticketID uuid.UUID, events map[uint]*models.Event) error {
+	db := s.db.WithContext(ctx)
+
+	var ticket models.Ticket
+	if err := db.Where("id = ? AND organization_id = ?", ticketID, orgID).First(&ticket).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTicketNotFound
+		}
+		return err
+	}
+	if ticket.Status == models.TicketStatusCancelled {
+		return errors.New("Ticket has been cancelled")
+	}
+	if ticket.RevokedAt != nil {
+		return ErrTicketRevoked
+	}
+
+	event, ok := events[ticket.EventID]
+	if !ok {
+		event = &models.Event{}
+		if err := db.First(event, ticket.EventID).Error; err != nil {
+			return err
+		}
+		events[ticket.EventID] = event
+	}
+
+	return s.emailQueueService.QueueTicketEmail(&ticket, event, ticketTypeLabel(db, &ticket))
+}
+
+// recordProgress counts a job's processed items into its progress counters
+func (s *BulkJobService) recordProgress(db *gorm.DB, job *models.BulkJob) error {
+	var counts struct {
+		Succeeded int
+		Failed    int
+	}
+	err := db.Model(&models.BulkJobItem{}).
+		Select("COUNT(*) FILTER (WHERE status = ?) AS succeeded, COUNT(*) FILTER (WHERE status = ?) AS failed",
+			models.BulkItemSucceeded, models.BulkItemFailed).
+		Where("job_id = ?", job.ID).
+		Scan(&counts).Error
+	if err != nil {
+		return fmt.Errorf("failed to count bulk job items: %w", err)
+	}
+
+	job.Succeeded = counts.Succeeded
+	job.Failed = counts.Failed
+	job.Processed = counts.Succeeded + counts.Failed
+	if err := db.Model(job).Select("processed", "succeeded", "failed").Updates(job).Error; err != nil {
+		return fmt.Errorf("failed to record bulk job progress: %w", err)
+	}
+	return nil
+}
+
+// finish records the final state of a job
+func (s *BulkJobService) finish(db *gorm.DB, job *models.BulkJob, status models.BulkJobStatus, reason string) error {
+	now := time.Now()
+	if err := db.Model(job).Updates(map[string]interface{}{
+		"status":       status,
+		"error":        reason,
+		"completed_at": now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to finish bulk job: %w", err)
+	}
+	return nil
+}
+
+// create records a bulk job with its items and queues it
+func (s *BulkJobService) create(ctx context.Context, orgID, actorID uuid.UUID, operation models.BulkOperation, options map[string]string, items []models.BulkJobItem) (*models.BulkJob, error) {
+	if len(items) > maxBulkJobItems {
+		return nil, utils.NewValidationError(fmt.Sprintf("At most %d items can be processed in one job", maxBulkJobItems), nil)
+	}
+
+	job := models.BulkJob{
+		OrganizationID: orgID,
+		Operation:      operation,
+		Options:        options,
+		Status:         models.BulkJobPending,
+		Total:          len(items),
+		CreatedBy:      actorID,
+	}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&job).Error; err != nil {
+			return fmt.Errorf("failed to create bulk job: %w", err)
+		}
+		for i := range items {
+			items[i].JobID = job.ID
+			items[i].Position = i
+			items[i].Status = models.BulkItemPending
+		}
+		if err := tx.CreateInBatches(items, 500).Error; err != nil {
+			return fmt.Errorf("failed to create bulk job items: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "bulk_job.create", "bulk_job", job.ID.String(), &orgID, map[string]interface{}{
+			"operation": operation,
+			"items":     len(items),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(BulkJobPayload{JobID: job.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bulk job: %w", err)
+	}
+	task := asynq.NewTask(TaskBulkJob, payload)
+	if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.MaxRetry(3)); err != nil {
+		return nil, fmt.Errorf("failed to enqueue bulk job: %w", err)
+	}
+
+	log.Printf("Bulk job queued: ID=%s, Operation=%s, Items=%d", job.ID, operation, len(items))
+	return &job, nil
+}
+
+// ticketItems turns ticket IDs into bulk job items, dropping repeats
+func ticketItems(ticketIDs []uuid.UUID) []models.BulkJobItem {
+	seen := make(map[uuid.UUID]bool, len(ticketIDs))
+	items := make([]models.BulkJobItem, 0, len(ticketIDs))
+	for _, id := range ticketIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		items = append(items, models.BulkJobItem{Reference: id.String()})
+	}
+	return items
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrBundleNotFound is returned for a bundle that does not exist or belongs to another organization
+var ErrBundleNotFound = errors.New("Bundle not found")
+
+// BundleService sells bundles of an organization's events at a combined price. A purchase places
+// a pending card order per event, priced at the event's share of the bundle price, so each order
+// is paid, cancelled and refunded like any other.
+type BundleService struct {
+	db           *gorm.DB
+	orderService *OrderService
+}
+
+// NewBundleService creates a new bundle service
+func NewBundleService(orderService *OrderService) *BundleService {
+	return &BundleService{
+		db:           database.DB,
+		orderService: orderService,
+	}
+}
+
+// CreateBundle creates a bundle of events of the organization
+func (s *BundleService) CreateBundle(ctx context.Context, orgID, userID uuid.UUID, req *models.EventBundleRequest) (*models.EventBundleResponse, error) {
+	db := s.db.WithContext(ctx)
+	if err := checkBundleEvents(db, orgID, req.EventIDs); err != nil {
+		return nil, err
+	}
+
+	bundle := &models.EventBundle{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		EventIDs:       req.EventIDs,
+		Price:          math.Round(req.Price*100) / 100,
+		Currency:       models.DefaultCurrency,
+		Active:         req.Active == nil || *req.Active,
+		CreatedBy:      userID,
+	}
+	if err := db.Create(bundle).Error; err != nil {
+		return nil, err
+	}
+	return s.bundleResponse(ctx, db, bundle)
+}
+
+// UpdateBundle replaces the name, description, events and price of a bundle of the organization.
+// Orders placed already keep the prices they were placed at.
+func (s *BundleService) UpdateBundle(ctx context.Context, orgID, bundleID uuid.UUID, req *models.EventBundleRequest) (*models.EventBundleResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var bundle models.EventBundle
+	if err := db.Where("id = ? AND organization_id = ?", bundleID, orgID).First(&bundle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBundleNotFound
+		}
+		return nil, err
+	}
+	if err := checkBundleEvents(db, orgID, req.EventIDs); err != nil {
+		return nil, err
+	}
+
+	bundle.Name = strings.TrimSpace(req.Name)
+	bundle.Description = req.Description
+	bundle.EventIDs = req.EventIDs
+	bundle.Price = math.Round(req.Price*100) / 100
+	if req.Active != nil {
+		bundle.Active = *req.Active
+	}
+	if err := db.Model(&bundle).Select("name", "description", "event_ids", "price", "active").Updates(&bundle).Error; err != nil {
+		return nil, err
+	}
+	return s.bundleResponse(ctx, db, &bundle)
+}
+
+// ListBundles returns the bundles of an organization, newest first
+func (s *BundleService) ListBundles(ctx context.Context, orgID uuid.UUID) ([]models.EventBundleResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var bundles []models.EventBundle
+	if err := db.Where("organization_id = ?", orgID).Order("created_at DESC").Find(&bundles).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.EventBundleResponse, len(bundles))
+	for i := range bundles {
+		resp, err := s.bundleResponse(ctx, db, &bundles[i])
+		if err != nil {
+			return nil, err
+		}
+		responses[i] = *resp
+	}
+	return responses, nil
+}
+
+// GetBundle returns an active bundle with its events and their shares at current prices
+func (s *BundleService) GetBundle(ctx context.Context, bundleID uuid.UUID) (*models.EventBundleResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var bundle models.EventBundle
+	if err := db.Where("id = ? AND active = ?", bundleID, true).First(&bundle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBundleNotFound
+		}
+		return nil, err
+	}
+	return s.bundleResponse(ctx, db, &bundle)
+}
+
+// Purchase buys a bundle for the user: one pending card order per event of the bundle, each
+// priced at the event's share of the bundle price and holding its tickets. The tickets of every
+// event are taken off sale or, when any event is short, none. Orders left unpaid are cancelled
+// after ORDER_PENDING_TTL like any other.
+func (s *BundleService) Purchase(ctx context.Context, userID, bundleID uuid.UUID, req *models.BundlePurchaseRequest) (*models.BundlePurchaseResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load buyer: %w", err)
+	}
+
+	var bundle models.EventBundle
+	purchase := &models.BundlePurchase{UserID: userID, Quantity: req.Quantity}
+	var orders []*models.Order
+	var events []*models.Event
+	var previousAvailable []int
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND active = ?", bundleID, true).First(&bundle).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrBundleNotFound
+			}
+			return err
+		}
+
+		// Lock the events in ID order, so concurrent purchases of overlapping bundles cannot deadlock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND organization_id = ?", bundle.EventIDs, bundle.OrganizationID).
+			Order("id").Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) != len(bundle.EventIDs) {
+			return utils.NewBusinessLogicError("An event of this bundle is no longer available")
+		}
+		for _, event := range events {
+			if !event.OnSale() {
+				return utils.NewBusinessLogicError(fmt.Sprintf("Tickets for %s are not on sale", event.Title))
+			}
+			if event.Available < req.Quantity {
+				return utils.NewBusinessLogicError(fmt.Sprintf("Only %d tickets are available for %s", event.Available, event.Title))
+			}
+		}
+		// Bundles have no access code, so events in presale are sold on their own until it ends
+		if err := s.orderService.presaleService.RejectPresale(tx, events, time.Now()); err != nil {
+			return utils.NewBusinessLogicError(err.Error())
+		}
+
+		shares, err := s.shares(ctx, &bundle, events)
+		if err != nil {
+			return err
+		}
+
+		purchase.BundleID = bundle.ID
+		purchase.OrganizationID = bundle.OrganizationID
+		purchase.TotalAmount = fromMinorUnits(toMinorUnits(bundle.Price) * int64(req.Quantity))
+		purchase.Currency = bundle.Currency
+		if err := tx.Create(purchase).Error; err != nil {
+			return err
+		}
+
+		for i, event := range events {
+			order := &models.Order{
+				EventID:          event.ID,
+				OrganizationID:   &bundle.OrganizationID,
+				UserID:           &user.ID,
+				BuyerEmail:       user.Email,
+				BuyerName:        strings.TrimSpace(user.FirstName + " " + user.LastName),
+				Quantity:         req.Quantity,
+				TotalAmount:      fromMinorUnits(toMinorUnits(shares[i]) * int64(req.Quantity)),
+				Currency:         bundle.Currency,
+				Status:           models.OrderStatusPending,
+				PaymentMethod:    models.PaymentMethodCard,
+				BundlePurchaseID: &purchase.ID,
+			}
+			if err := tx.Create(order).Error; err != nil {
+				return err
+			}
+
+			for j := 0; j < req.Quantity; j++ {
+				ticket := &models.Ticket{
+					OrderID:        order.ID,
+					EventID:        event.ID,
+					OrganizationID: &bundle.OrganizationID,
+					AttendeeName:   order.BuyerName,
+					AttendeeEmail:  order.BuyerEmail,
+				}
+				if err := tx.Create(ticket).Error; err != nil {
+					return err
+				}
+				order.Tickets = append(order.Tickets, ticket)
+			}
+
+			previousAvailable = append(previousAvailable, event.Available)
+			if err := s.orderService.inventoryService.Reserve(ctx, tx, event, req.Quantity); err != nil {
+				return err
+			}
+			orders = append(orders, order)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.BundlePurchaseResponse{BundlePurchase: *purchase, Orders: make([]models.OrderDetailResponse, len(orders))}
+	for i, order := range orders {
+		log.Printf("Bundle order placed: Order=%s, Event=%d, Bundle=%s, Purchase=%s", order.ID, order.EventID, bundle.ID, purchase.ID)
+		s.orderService.afterOrderPlaced(order, events[i], previousAvailable[i])
+		resp.Orders[i] = *s.orderService.orderDetail(order, events[i])
+	}
+	return resp, nil
+}
+
+// bundleResponse adds a bundle's events, their shares at current prices and its availability
+func (s *BundleService) bundleResponse(ctx context.Context, db *gorm.DB, bundle *models.EventBundle) (*models.EventBundleResponse, error) {
+	var events []*models.Event
+	if err := db.Where("id IN ? AND organization_id = ?", bundle.EventIDs, bundle.OrganizationID).
+		Order("start_date ASC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	shares, err := s.shares(ctx, bundle, events)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.EventBundleResponse{
+		EventBundle: *bundle,
+		Events:      make([]models.BundleEventShare, len(events)),
+		OnSale:      bundle.Active && len(events) == len(bundle.EventIDs),
+	}
+	for i, event := range events {
+		resp.Events[i] = models.BundleEventShare{EventID: event.ID, Title: event.Title, StartDate: event.StartDate, Share: shares[i]}
+		if i == 0 || event.Available < resp.Available {
+			resp.Available = max(event.Available, 0)
+		}
+		resp.OnSale = resp.OnSale && event.OnSale()
+	}
+	return resp, nil
+}
+
+// shares splits the bundle price over its events in proportion to their current ticket prices,
+// or equally when none of them has a price. The shares add up to the bundle price.
+func (s *BundleService) shares(ctx context.Context, bundle *models.EventBundle, events []*models.Event) ([]float64, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	weights := make([]int64, len(events))
+	var total int64
+	for i, event := range events {
+		price, _, err := s.orderService.pricingService.CurrentPrice(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		weights[i] = toMinorUnits(price)
+		total += weights[i]
+	}
+	if total == 0 {
+		return splitAmount(bundle.Price, len(events)), nil
+	}
+
+	price := toMinorUnits(bundle.Price)
+	shares := make([]float64, len(events))
+	var allotted int64
+	for i := range events {
+		cents := price * weights[i] / total
+		if i == len(events)-1 {
+			cents = price - allotted
+		}
+		allotted += cents
+		shares[i] = fromMinorUnits(cents)
+	}
+	return shares, nil
+}
+
+// checkBundleEvents rejects events that do not belong to the organization or use reserved seating,
+// which bundles cannot pick seats for
+func checkBundleEvents(db *gorm.DB, orgID uuid.UUID, eventIDs []uint) error {
+	var events []models.Event
+	if err := db.Select("id", "venue_id").Where("id IN ? AND organization_id = ?", eventIDs, orgID).Find(&events).Error; err != nil {
+		return err
+	}
+	if len(events) != len(eventIDs) {
+		return utils.NewValidationError("Invalid bundle", map[string]interface{}{
+			"event_ids": "must all be events of this organization",
+		})
+	}
+	for _, event := range events {
+		if event.VenueID != nil {
+			return utils.NewValidationError("Invalid bundle", map[string]interface{}{
+				"event_ids": fmt.Sprintf("event %d has reserved seating and cannot be bundled", event.ID),
+			})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+// CalendarContentType is the content type of iCalendar files
+const CalendarContentType = "text/calendar"
+
+// ErrCalendarEventNotFound is returned when exporting an event that does not exist
+var ErrCalendarEventNotFound = errors.New("Event not found")
+
+// CalendarService exports events as iCalendar files, so attendees can add them to a calendar app
+type CalendarService struct {
+	db        *gorm.DB
+	publicURL string
+}
+
+// NewCalendarService creates a new calendar service
+func NewCalendarService(cfg *config.Config) *CalendarService {
+	return &CalendarService{
+		db:        database.DB,
+		publicURL: strings.TrimRight(cfg.Security.PublicURL, "/"),
+	}
+}
+
+// EventCalendar returns an iCalendar file with a published event. It is built from the event on
+// every request, so re-importing it picks up changes to the event's time or location.
+func (s *CalendarService) EventCalendar(ctx context.Context, eventID uint) ([]byte, error) {
+	var event models.Event
+	if err := s.db.WithContext(ctx).Where("status <> ?", models.EventStatusDraft).First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCalendarEventNotFound
+		}
+		return nil, err
+	}
+
+	return eventICS(&event, fmt.Sprintf("event-%d@timro-tickets", event.ID), event.Description,
+		fmt.Sprintf("%s/api/v1/events/%d", s.publicURL, event.ID)), nil
+}
+
+// CalendarFilename returns the file name an event's iCalendar file is attached and downloaded as
+func CalendarFilename(event *models.Event) string {
+	return fmt.Sprintf("event-%d.ics", event.ID)
+}
+
+// calendarLinks returns the links adding an event to a calendar: the iCalendar file served by
+// the API and a Google Calendar template
+func calendarLinks(publicURL string, event *models.Event) *models.CalendarLinks {
+	return &models.CalendarLinks{
+		ICS:    fmt.Sprintf("%s/api/v1/events/%d/calendar.ics", publicURL, event.ID),
+		Google: googleCalendarURL(event),
+	}
+}
+
+// googleCalendarURL returns a link opening Google Calendar with the event filled in
+func googleCalendarURL(event *models.Event) string {
+	query := url.Values{}
+	query.Set("action", "TEMPLATE")
+	query.Set("text", event.Title)
+	query.Set("dates", icsTime(event.StartDate)+"/"+icsTime(event.EndDate))
+	if event.Description != "" {
+		query.Set("details", event.Description)
+	}
+	if event.Location != "" {
+		query.Set("location", event.Location)
+	}
+	return "https://calendar.google.com/calendar/render?" + query.Encode()
+}
+
+// ticketICS returns an iCalendar file with a ticket's event, linking to the ticket's page. The UID
+// is the ticket's, so importing it again updates the entry rather than duplicating it.
+func ticketICS(ticket *models.Ticket, event *models.Event, manageURL string) []byte {
+	return eventICS(event, fmt.Sprintf("ticket-%s@timro-tickets", ticket.ID),
+		fmt.Sprintf("Ticket %s for %s", ticket.ID, ticket.AttendeeName), manageURL)
+}
+
+// eventICS returns an iCalendar file with a single event
+func eventICS(event *models.Event, uid, description, link string) []byte {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Timro Tickets//Ticket Portal//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:" + uid,
+		"DTSTAMP:" + icsTime(time.Now()),
+		"DTSTART:" + icsTime(event.StartDate),
+		"DTEND:" + icsTime(event.EndDate),
+		"SUMMARY:" + icsText(event.Title),
+		"LOCATION:" + icsText(event.Location),
+		"DESCRIPTION:" + icsText(description),
+		"URL:" + link,
+	}
+	if event.Status == models.EventStatusCancelled {
+		lines = append(lines, "STATUS:CANCELLED")
+	}
+	lines = append(lines, "END:VEVENT", "END:VCALENDAR")
+
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(icsFold(line))
+		b.WriteString("\r\n")
+	}
+	return []byte(b.String())
+}
+
+// icsTime formats a time as an iCalendar UTC date-time
+func icsTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsText escapes text for an iCalendar property value
+func icsText(text string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(text)
+}
+
+// icsFold splits a content line into lines of at most 75 octets, as iCalendar requires
+func icsFold(line string) string {
+	const limit = 75
+	var b strings.Builder
+	width := 0
+	for _, r := range line {
+		size := len(string(r))
+		if width+size > limit {
+			b.WriteString("\r\n ")
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	return b.String()
+}
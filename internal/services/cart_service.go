@@ -0,0 +1,455 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskCartExpiry is the periodic sweep releasing the tickets of lapsed carts
+const TaskCartExpiry = "cart:expiry"
+
+// ErrCartNotFound is returned when a cart does not exist, belongs to another user or has expired
+var ErrCartNotFound = errors.New("Cart not found or expired")
+
+const (
+	// cartExpiryKey is the sorted set of cart IDs scored by expiry time. Removing a cart from it
+	// claims the cart, so its tickets are released or ordered exactly once.
+	cartExpiryKey = "carts:expiring"
+	// cartGrace keeps a cart readable past its expiry until the sweep has released it
+	cartGrace = time.Hour
+	// cartSweepBatch is the number of lapsed carts released per sweep iteration
+	cartSweepBatch = 100
+)
+
+// CartService holds tickets for buyers for CHECKOUT_CART_TTL. Held tickets are taken off the
+// event's availability like any reservation; the cart itself lives in Redis, and lapsed carts
+// are put back on sale by a sweep run on CHECKOUT_CART_EXPIRY_CRON.
+type CartService struct {
+	db               *gorm.DB
+	redisClient      redislib.UniversalClient
+	orderService     *OrderService
+	inventoryService *InventoryService
+	ttl              time.Duration
+}
+
+// NewCartService creates a new cart service
+func NewCartService(cfg *config.Config, orderService *OrderService) *CartService {
+	return &CartService{
+		db:               database.DB,
+		redisClient:      redis.Client,
+		orderService:     orderService,
+		inventoryService: NewInventoryService(),
+		ttl:              cfg.Checkout.CartTTL,
+	}
+}
+
+// CreateCart prices ticket selections and holds them for the user until the cart expires. The
+// tickets of every selection are held or, when any is short, none. Events in presale are only
+// held for buyers admitted to the presale.
+func (s *CartService) CreateCart(ctx context.Context, userID uuid.UUID, req *models.CartRequest) (*models.CartResponse, error) {
+	quote, err := s.orderService.QuoteOrder(ctx, &models.OrderQuoteRequest{Items: req.Items, PromoCode: req.PromoCode})
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load buyer: %w", err)
+	}
+	if _, err := s.orderService.presaleService.Admit(s.db.WithContext(ctx), itemEventIDs(req.Items), user.Email, req.AccessCode, time.Now()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cart := models.Cart{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Items:      req.Items,
+		PromoCode:  req.PromoCode,
+		AccessCode: req.AccessCode,
+		ExpiresAt:  now.Add(s.ttl),
+		CreatedAt:  now,
+	}
+
+	changes := make([]*InventoryChange, 0, len(cart.Items))
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range cart.Items {
+			var event models.Event
+			if err := tx.First(&event, item.EventID).Error; err != nil {
+				return err
+			}
+			if event.VenueID != nil {
+				return fmt.Errorf("%s has reserved seating, select seats in a checkout instead", event.Title)
+			}
+			previousAvailable := event.Available
+			if err := s.inventoryService.Reserve(ctx, tx, &event, item.Quantity); err != nil {
+				return err
+			}
+			changes = append(changes, &InventoryChange{Event: &event, PreviousAvailable: previousAvailable})
+		}
+
+		// Saved last, so a failure rolls the reservations back
+		return s.save(ctx, &cart)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		InventoryChanged(change)
+	}
+	log.Printf("Cart created: Cart=%s, User=%s, Expires=%s", cart.ID, userID, cart.ExpiresAt.Format(time.RFC3339))
+	return &models.CartResponse{Cart: cart, Quote: quote}, nil
+}
+
+// GetCart returns a cart of the user that has not expired
+func (s *CartService) GetCart(ctx context.Context, userID uuid.UUID, cartID uuid.UUID) (*models.CartResponse, error) {
+	cart, err := s.load(ctx, userID, cartID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.CartResponse{Cart: *cart}, nil
+}
+
+// ReleaseCart empties a cart of the user and puts its tickets back on sale
+func (s *CartService) ReleaseCart(ctx context.Context, userID uuid.UUID, cartID uuid.UUID) error {
+	cart, err := s.load(ctx, userID, cartID)
+	if err != nil {
+		return err
+	}
+	claimed, err := s.claim(ctx, cart.ID)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrCartNotFound
+	}
+
+	if err := s.release(ctx, cart); err != nil {
+		return err
+	}
+	log.Printf("Cart released: Cart=%s, User=%s", cart.ID, userID)
+	return nil
+}
+
+// Checkout converts a cart of the user into pending card orders, one per event, priced at
+// current prices. The cart's promo code is applied again, using it once per discounted order.
+// The held tickets move to the orders, so nothing can sell out in between. Events still in
+// presale need the buyer admitted again, using the cart's access code once per order. Orders left
+// unpaid are cancelled after ORDER_PENDING_TTL like any other.
+func (s *CartService) Checkout(ctx context.Context, userID uuid.UUID, cartID uuid.UUID) (*models.CartCheckoutResponse, error) {
+	cart, err := s.load(ctx, userID, cartID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load buyer: %w", err)
+	}
+
+	claimed, err := s.claim(ctx, cart.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, ErrCartNotFound
+	}
+
+	orders := make([]*models.Order, 0, len(cart.Items))
+	events := make([]*models.Event, 0, len(cart.Items))
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		lines := make([]models.OrderQuoteLine, len(cart.Items))
+		for i, item := range cart.Items {
+			var event models.Event
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, item.EventID).Error; err != nil {
+				return err
+			}
+			if !event.OnSale() {
+				return fmt.Errorf("Tickets for %s are not on sale", event.Title)
+			}
+			unitPrice, _, err := s.orderService.pricingService.CurrentPrice(ctx, &event)
+			if err != nil {
+				return err
+			}
+			lines[i] = models.OrderQuoteLine{
+				EventID:   event.ID,
+				Title:     event.Title,
+				Quantity:  item.Quantity,
+				UnitPrice: unitPrice,
+				Amount:    math.Round(unitPrice*float64(item.Quantity)*100) / 100,
+			}
+			events = append(events, &event)
+		}
+		if cart.PromoCode != "" {
+			if _, err := s.orderService.promoCodeService.Apply(tx, cart.PromoCode, user.Email, lines, time.Now()); err != nil {
+				return err
+			}
+		}
+		admissions, err := s.orderService.presaleService.Admit(tx, itemEventIDs(cart.Items), user.Email, cart.AccessCode, time.Now())
+		if err != nil {
+			return err
+		}
+
+		for i := range lines {
+			order, err := s.placeOrder(tx, &user, events[i], &lines[i], admissions[events[i].ID])
+			if err != nil {
+				return err
+			}
+			orders = append(orders, order)
+		}
+		return nil
+	})
+	if err != nil {
+		// Hand the cart back, so it can be checked out again or lapses with the sweep
+		if restoreErr := s.redisClient.ZAdd(ctx, cartExpiryKey, redislib.Z{
+			Score:  float64(cart.ExpiresAt.Unix()),
+			Member: cart.ID.String(),
+		}).Err(); restoreErr != nil {
+			log.Printf("Failed to restore cart after checkout error: Cart=%s, Error=%v", cart.ID, restoreErr)
+		}
+		return nil, err
+	}
+
+	if err := s.redisClient.Del(ctx, cartKey(cart.ID)).Err(); err != nil {
+		log.Printf("Failed to delete checked out cart: Cart=%s, Error=%v", cart.ID, err)
+	}
+
+	resp := &models.CartCheckoutResponse{CartID: cart.ID, Orders: make([]models.OrderDetailResponse, len(orders))}
+	for i, order := range orders {
+		log.Printf("Cart order placed: Order=%s, Event=%d, Cart=%s", order.ID, order.EventID, cart.ID)
+		// The tickets were taken off sale with the cart, so availability is unchanged
+		s.orderService.afterOrderPlaced(order, events[i], events[i].Available)
+		resp.Orders[i] = *s.orderService.orderDetail(order, events[i])
+	}
+	return resp, nil
+}
+
+// ExpireCarts puts the tickets of lapsed carts back on sale
+func (s *CartService) ExpireCarts(ctx context.Context) error {
+	expired := 0
+	for ctx.Err() == nil {
+		ids, err := s.redisClient.ZRangeByScore(ctx, cartExpiryKey, &redislib.ZRangeBy{
+			Min:   "-inf",
+			Max:   strconv.FormatInt(time.Now().Unix(), 10),
+			Count: cartSweepBatch,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("failed to load lapsed carts: %w", err)
+		}
+
+		for _, id := range ids {
+			released, err := s.expire(ctx, id)
+			if err != nil {
+				// One cart's failure should not hold back the others
+				log.Printf("Failed to expire cart %s: %v", id, err)
+				continue
+			}
+			if released {
+				expired++
+			}
+		}
+		if len(ids) < cartSweepBatch {
+			break
+		}
+	}
+	if expired > 0 {
+		log.Printf("Expired %d carts", expired)
+	}
+	return ctx.Err()
+}
+
+// expire claims and releases a lapsed cart. It reports false when another sweep, a checkout or
+// the buyer got to the cart first.
+func (s *CartService) expire(ctx context.Context, id string) (bool, error) {
+	cartID, err := uuid.Parse(id)
+	if err != nil {
+		s.redisClient.ZRem(ctx, cartExpiryKey, id)
+		return false, fmt.Errorf("invalid cart ID: %w", err)
+	}
+
+	data, err := s.redisClient.Get(ctx, cartKey(cartID)).Bytes()
+	if err != nil && !errors.Is(err, redislib.Nil) {
+		return false, err
+	}
+
+	claimed, err := s.claim(ctx, cartID)
+	if err != nil || !claimed {
+		return false, err
+	}
+	if data == nil {
+		log.Printf("Lapsed cart %s is gone, its tickets cannot be released", cartID)
+		return false, nil
+	}
+
+	var cart models.Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return false, fmt.Errorf("failed to unmarshal cart: %w", err)
+	}
+	return true, s.release(ctx, &cart)
+}
+
+// release puts the tickets of a claimed cart back on sale and deletes it
+func (s *CartService) release(ctx context.Context, cart *models.Cart) error {
+	changes := make([]*InventoryChange, 0, len(cart.Items))
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, item := range cart.Items {
+			var event models.Event
+			if err := tx.First(&event, item.EventID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+				return err
+			}
+			previousAvailable := event.Available
+			if err := s.inventoryService.Release(ctx, tx, &event, item.Quantity); err != nil {
+				return err
+			}
+			changes = append(changes, &InventoryChange{Event: &event, PreviousAvailable: previousAvailable})
+		}
+		return nil
+	})
+	if err != nil {
+		// Hand the cart back to the sweep, so its tickets are not lost
+		s.redisClient.ZAdd(ctx, cartExpiryKey, redislib.Z{Score: float64(cart.ExpiresAt.Unix()), Member: cart.ID.String()})
+		return err
+	}
+
+	if err := s.redisClient.Del(ctx, cartKey(cart.ID)).Err(); err != nil {
+		log.Printf("Failed to delete released cart: Cart=%s, Error=%v", cart.ID, err)
+	}
+	for _, change := range changes {
+		InventoryChanged(change)
+	}
+	return nil
+}
+
+// placeOrder creates a pending card order for tickets held by a cart, in the caller's
+// transaction, and redeems the promo code that discounted its line and the presale access of its
+// event, if any
+func (s *CartService) placeOrder(tx *gorm.DB, user *models.User, event *models.Event, line *models.OrderQuoteLine, admission *presaleAdmission) (*models.Order, error) {
+	var orgID *uuid.UUID
+	if event.OrganizerID != nil {
+		var err error
+		if orgID, err = organizerOrganization(tx, *event.OrganizerID); err != nil {
+			return nil, err
+		}
+	}
+
+	order := &models.Order{
+		EventID:        event.ID,
+		OrganizationID: orgID,
+		UserID:         &user.ID,
+		BuyerEmail:     user.Email,
+		BuyerName:      strings.TrimSpace(user.FirstName + " " + user.LastName),
+		Quantity:       line.Quantity,
+		TotalAmount:    math.Round((line.Amount-line.DiscountAmount)*100) / 100,
+		Currency:       models.DefaultCurrency,
+		Status:         models.OrderStatusPending,
+		PaymentMethod:  models.PaymentMethodCard,
+	}
+	if err := tx.Create(order).Error; err != nil {
+		return nil, err
+	}
+	if line.PromoCodeID != nil {
+		if err := s.orderService.promoCodeService.Redeem(tx, *line.PromoCodeID, order, line.DiscountAmount); err != nil {
+			return nil, err
+		}
+	}
+	if admission != nil {
+		if err := s.orderService.presaleService.Redeem(tx, order, admission); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 0; i < line.Quantity; i++ {
+		ticket := &models.Ticket{
+			OrderID:        order.ID,
+			EventID:        event.ID,
+			OrganizationID: orgID,
+			AttendeeName:   order.BuyerName,
+			AttendeeEmail:  order.BuyerEmail,
+		}
+		if err := tx.Create(ticket).Error; err != nil {
+			return nil, err
+		}
+		order.Tickets = append(order.Tickets, ticket)
+	}
+	return order, nil
+}
+
+// save stores a new cart and schedules its expiry
+func (s *CartService) save(ctx context.Context, cart *models.Cart) error {
+	data, err := json.Marshal(cart)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cart: %w", err)
+	}
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.Set(ctx, cartKey(cart.ID), data, s.ttl+cartGrace)
+	pipe.ZAdd(ctx, cartExpiryKey, redislib.Z{Score: float64(cart.ExpiresAt.Unix()), Member: cart.ID.String()})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save cart: %w", err)
+	}
+	return nil
+}
+
+// load returns a cart of the user that has not expired
+func (s *CartService) load(ctx context.Context, userID uuid.UUID, cartID uuid.UUID) (*models.Cart, error) {
+	data, err := s.redisClient.Get(ctx, cartKey(cartID)).Bytes()
+	if err != nil {
+		if errors.Is(err, redislib.Nil) {
+			return nil, ErrCartNotFound
+		}
+		return nil, err
+	}
+
+	var cart models.Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cart: %w", err)
+	}
+	if cart.UserID != userID || !cart.ExpiresAt.After(time.Now()) {
+		return nil, ErrCartNotFound
+	}
+	return &cart, nil
+}
+
+// claim takes a cart off the expiry schedule. Only the caller that claimed a cart may release or
+// order its tickets.
+func (s *CartService) claim(ctx context.Context, cartID uuid.UUID) (bool, error) {
+	removed, err := s.redisClient.ZRem(ctx, cartExpiryKey, cartID.String()).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim cart: %w", err)
+	}
+	return removed > 0, nil
+}
+
+// itemEventIDs returns the events of ticket selections
+func itemEventIDs(items []models.OrderQuoteItem) []uint {
+	ids := make([]uint, len(items))
+	for i, item := range items {
+		ids[i] = item.EventID
+	}
+	return ids
+}
+
+func cartKey(cartID uuid.UUID) string {
+	return "cart:" + cartID.String()
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskChatNotify is the asynq task type for Slack/Discord notifications
+const TaskChatNotify = "chat:notify"
+
+// CreateChatWebhook adds a Slack or Discord incoming webhook to an organization
+func (s *IntegrationService) CreateChatWebhook(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.ChatWebhookRequest) (*models.ChatWebhookResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	webhook := models.ChatWebhook{
+		OrganizationID: orgID,
+		CreatedBy:      &userID,
+	}
+	if err := applyChatWebhookRequest(ctx, &webhook, req); err != nil {
+		return nil, err
+	}
+
+	if err := db.Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+
+	resp := webhook.ToResponse()
+	return &resp, nil
+}
+
+// ListChatWebhooks returns the chat webhooks of an organization
+func (s *IntegrationService) ListChatWebhooks(ctx context.Context, orgID uuid.UUID) ([]models.ChatWebhookResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var webhooks []models.ChatWebhook
+	if err := db.Where("organization_id = ?", orgID).Order("created_at ASC").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.ChatWebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		responses[i] = webhook.ToResponse()
+	}
+	return responses, nil
+}
+
+// UpdateChatWebhook replaces the settings of an organization's chat webhook
+func (s *IntegrationService) UpdateChatWebhook(ctx context.Context, orgID uuid.UUID, webhookID uuid.UUID, req *models.ChatWebhookRequest) (*models.ChatWebhookResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	webhook, err := s.getChatWebhook(ctx, orgID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyChatWebhookRequest(ctx, webhook, req); err != nil {
+		return nil, err
+	}
+
+	if err := db.Save(webhook).Error; err != nil {
+		return nil, err
+	}
+
+	resp := webhook.ToResponse()
+	return &resp, nil
+}
+
+// DeleteChatWebhook removes a chat webhook from an organization
+func (s *IntegrationService) DeleteChatWebhook(ctx context.Context, orgID uuid.UUID, webhookID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	result := db.Where("id = ? AND organization_id = ?", webhookID, orgID).Delete(&models.ChatWebhook{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("Chat webhook not found")
+	}
+
+	return nil
+}
+
+// SendTestChatMessage queues a test message to an organization's chat webhook
+func (s *IntegrationService) SendTestChatMessage(ctx context.Context, orgID uuid.UUID, webhookID uuid.UUID) error {
+	webhook, err := s.getChatWebhook(ctx, orgID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	return s.enqueueChatMessage(webhook, ":wave: Sale notifications are connected to this channel.")
+}
+
+// NotifyTicketsSold posts a sale notification for a paid order
+func (s *IntegrationService) NotifyTicketsSold(order *models.Order, event *models.Event) {
+	if order.OrganizationID == nil {
+		return
+	}
+
+	buyer := order.BuyerName
+	if buyer == "" {
+		buyer = order.BuyerEmail
+	}
+	text := fmt.Sprintf(":tada: %s bought %d ticket(s) for *%s* (%.2f %s). %d of %d remaining.",
+		buyer, order.Quantity, event.Title, order.TotalAmount, order.Currency, event.Available, event.Capacity)
+
+	s.NotifyChat(*order.OrganizationID, models.ChatNotificationTicketsSold, text)
+}
+
+// NotifyEventSoldOut posts a notification that an event has no tickets left
+func (s *IntegrationService) NotifyEventSoldOut(orgID uuid.UUID, event *models.Event) {
+	text := fmt.Sprintf(":rotating_light: *%s* is sold out! All %d tickets have been sold.", event.Title, event.Capacity)
+	s.NotifyChat(orgID, models.ChatNotificationEventSoldOut, text)
+}
+
+// NotifyOrderRefunded posts a notification that an order has been refunded
+func (s *IntegrationService) NotifyOrderRefunded(order *models.Order, event *models.Event, amount float64) {
+	if order.OrganizationID == nil {
+		return
+	}
+
+	text := fmt.Sprintf(":leftwards_arrow_with_hook: Refund of %.2f %s issued to %s for *%s* (order %s).",
+		amount, order.Currency, order.BuyerEmail, event.Title, order.ID)
+	s.NotifyChat(*order.OrganizationID, models.ChatNotificationOrderRefunded, text)
+}
+
+// NotifyChat queues a message to every chat webhook of the organization subscribed to the notification.
+// Failures are logged rather than returned so notifications never block a sale.
+func (s *IntegrationService) NotifyChat(orgID uuid.UUID, notification models.ChatNotification, text string) {
+	var webhooks []models.ChatWebhook
+	if err := s.db.Where("organization_id = ? AND enabled = ?", orgID, true).Find(&webhooks).Error; err != nil {
+		log.Printf("Failed to load chat webhooks: Organization=%s, Error=%v", orgID, err)
+		return
+	}
+
+	for i := range webhooks {
+		if !webhooks[i].Receives(notification) {
+			continue
+		}
+		if err := s.enqueueChatMessage(&webhooks[i], text); err != nil {
+			log.Printf("Failed to enqueue chat notification: Webhook=%s, Error=%v", webhooks[i].ID, err)
+		}
+	}
+}
+
+// enqueueChatMessage queues a single message for delivery by the integration worker
+func (s *IntegrationService) enqueueChatMessage(webhook *models.ChatWebhook, text string) error {
+	payload, err := json.Marshal(models.ChatMessage{
+		WebhookID:  webhook.ID,
+		Provider:   webhook.Provider,
+		WebhookURL: webhook.WebhookURL,
+		Text:       text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat message: %w", err)
+	}
+
+	task := asynq.NewTask(TaskChatNotify, payload)
+	if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(IntegrationQueue)), asynq.MaxRetry(3)); err != nil {
+		return fmt.Errorf("failed to enqueue chat message: %w", err)
+	}
+	return nil
+}
+
+// getChatWebhook loads a chat webhook belonging to an organization
+func (s *IntegrationService) getChatWebhook(ctx context.Context, orgID uuid.UUID, webhookID uuid.UUID) (*models.ChatWebhook, error) {
+	var webhook models.ChatWebhook
+	if err := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", webhookID, orgID).First(&webhook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Chat webhook not found")
+		}
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// applyChatWebhookRequest copies request settings onto a webhook, checking the URL belongs to the
+// provider and resolves to public addresses
+func applyChatWebhookRequest(ctx context.Context, webhook *models.ChatWebhook, req *models.ChatWebhookRequest) error {
+	provider := models.ChatProvider(req.Provider)
+	if err := validateChatWebhookURL(provider, req.WebhookURL); err != nil {
+		return err
+	}
+	if err := validatePublicURL(ctx, req.WebhookURL); err != nil {
+		return errors.New("Webhook URL must resolve to a public address")
+	}
+
+	notifications := make([]models.ChatNotification, len(req.Notifications))
+	for i, n := range req.Notifications {
+		notifications[i] = models.ChatNotification(n)
+	}
+
+	webhook.Provider = provider
+	webhook.Name = req.Name
+	webhook.WebhookURL = req.WebhookURL
+	webhook.Notifications = notifications
+	webhook.Enabled = req.Enabled == nil || *req.Enabled
+	return nil
+}
+
+// validateChatWebhookURL only accepts HTTPS incoming webhook URLs issued by the provider
+func validateChatWebhookURL(provider models.ChatProvider, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" {
+		return errors.New("Webhook URL must be an https URL")
+	}
+
+	switch provider {
+	case models.ChatProviderSlack:
+		if u.Host != "hooks.slack.com" {
+			return errors.New("Slack webhook URL must be on hooks.slack.com")
+		}
+	case models.ChatProviderDiscord:
+		if (u.Host != "discord.com" && u.Host != "discordapp.com") || !strings.HasPrefix(u.Path, "/api/webhooks/") {
+			return errors.New("Discord webhook URL must be a discord.com/api/webhooks URL")
+		}
+	default:
+		return fmt.Errorf("Unsupported chat provider: %s", provider)
+	}
+	return nil
+}
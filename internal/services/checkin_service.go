@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrEventAccessDenied is returned when staff work the door of an event they are not staffing
+	ErrEventAccessDenied = errors.New("You do not have access to this event")
+	// ErrTicketAlreadyCheckedIn is returned when checking in a ticket that is already checked in
+	ErrTicketAlreadyCheckedIn = errors.New("Ticket is already checked in")
+	// ErrTicketCancelled is returned when checking in a cancelled ticket
+	ErrTicketCancelled = errors.New("Ticket was cancelled")
+	// ErrTicketRevoked is returned when checking in a ticket the organization revoked
+	ErrTicketRevoked = errors.New("Ticket has been revoked")
+)
+
+// CheckInService checks attendees in and out of events by hand and reports attendance. Door
+// scanners check tickets in through the ticket service; both record CheckIn rows.
+type CheckInService struct {
+	db                 *gorm.DB
+	statsService       *CheckInStatsService
+	integrationService *IntegrationService
+}
+
+// NewCheckInService creates a new check-in service
+func NewCheckInService(cfg *config.Config, statsService *CheckInStatsService) *CheckInService {
+	return &CheckInService{
+		db:                 database.DB,
+		statsService:       statsService,
+		integrationService: NewIntegrationService(cfg),
+	}
+}
+
+// CheckIn admits the holder of a ticket to an event, for staff checking attendees in without a
+// scanner and for paired scanners. Admissions and rejections of tickets of the event are added to
+// the scan audit trail.
+func (s *CheckInService) CheckIn(ctx context.Context, eventID uint, userID uuid.UUID, roles []string, device models.ScanDevice, req *models.CheckInRequest) (*models.CheckIn, error) {
+	now := time.Now()
+	gate := strings.TrimSpace(req.Gate)
+	attempt := models.ScanAttempt{
+		EventID:         eventID,
+		TicketID:        &req.TicketID,
+		Gate:            gate,
+		DeviceID:        device.DeviceID,
+		ScannerDeviceID: device.ScannerDeviceID,
+		ScannedBy:       &userID,
+		ScannedAt:       now,
+	}
+
+	var checkIn *models.CheckIn
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		event, ticket, err := s.lockTicket(tx, eventID, userID, roles, req.TicketID)
+		if event != nil {
+			attempt.OrganizationID = event.OrganizationID
+		}
+		if err != nil {
+			return err
+		}
+		if ticket.RevokedAt != nil {
+			return ErrTicketRevoked
+		}
+		switch ticket.Status {
+		case models.TicketStatusCheckedIn:
+			return ErrTicketAlreadyCheckedIn
+		case models.TicketStatusCancelled:
+			return ErrTicketCancelled
+		}
+
+		if err := tx.Model(ticket).Updates(map[string]interface{}{
+			"status":        models.TicketStatusCheckedIn,
+			"checked_in_at": now,
+			"check_in_gate": gate,
+		}).Error; err != nil {
+			return err
+		}
+
+		checkIn = &models.CheckIn{
+			TicketID:       ticket.ID,
+			EventID:        ticket.EventID,
+			OrganizationID: ticket.OrganizationID,
+			Gate:           gate,
+			CheckedInBy:    &userID,
+			CheckedInAt:    now,
+		}
+		return tx.Create(checkIn).Error
+	})
+	s.recordCheckInAttempt(ctx, &attempt, err)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkIn.OrganizationID != nil {
+		s.statsService.Record(ctx, *checkIn.OrganizationID, eventID, checkIn.Gate, 1)
+		s.integrationService.DispatchCheckIns(*checkIn.OrganizationID, []uuid.UUID{checkIn.TicketID})
+	}
+	log.Printf("Attendee checked in: Ticket=%s, Event=%d, Staff=%s", checkIn.TicketID, eventID, userID)
+	return checkIn, nil
+}
+
+// UndoCheckIn returns a checked-in ticket to valid, e.g. after it was scanned by mistake, and marks
+// its check-in as undone. Live entry counters are not decremented; they count entries scanned.
+func (s *CheckInService) UndoCheckIn(ctx context.Context, eventID uint, userID uuid.UUID, roles []string, ticketID uuid.UUID) error {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_, ticket, err := s.lockTicket(tx, eventID, userID, roles, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.Status != models.TicketStatusCheckedIn {
+			return errors.New("Ticket is not checked in")
+		}
+
+		if err := tx.Model(ticket).Updates(map[string]interface{}{
+			"status":        models.TicketStatusValid,
+			"checked_in_at": nil,
+			"check_in_gate": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		// Tickets checked in before check-ins were recorded have no row to mark
+		return tx.Model(&models.CheckIn{}).
+			Where("ticket_id = ? AND undone_at IS NULL", ticket.ID).
+			Updates(map[string]interface{}{"undone_at": time.Now(), "undone_by": userID}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Check-in undone: Ticket=%s, Event=%d, Staff=%s", ticketID, eventID, userID)
+	return nil
+}
+
+// Stats counts an event's attendance from its tickets and check-ins
+func (s *CheckInService) Stats(ctx context.Context, eventID uint, userID uuid.UUID, roles []string) (*models.AttendanceStats, error) {
+	db := s.db.WithContext(ctx)
+	event, err := s.authorize(db, eventID, userID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.AttendanceStats{
+		EventID:   event.ID,
+		Capacity:  event.Capacity,
+		Gates:     make(map[string]int64),
+		UpdatedAt: time.Now(),
+	}
+
+	var statuses []struct {
+		Status models.TicketStatus
+		Count  int64
+	}
+	if err := db.Model(&models.Ticket{}).Select("status, COUNT(*) AS count").
+		Where("event_id = ?", eventID).Group("status").Scan(&statuses).Error; err != nil {
+		return nil, fmt.Errorf("failed to count tickets: %w", err)
+	}
+	for _, row := range statuses {
+		switch row.Status {
+		case models.TicketStatusCheckedIn:
+			stats.CheckedIn = row.Count
+			stats.Issued += row.Count
+		case models.TicketStatusValid:
+			stats.NotArrived = row.Count
+			stats.Issued += row.Count
+		}
+	}
+
+	var gates []struct {
+		Gate  string
+		Count int64
+	}
+	if err := db.Model(&models.CheckIn{}).Select("gate, COUNT(*) AS count").
+		Where("event_id = ? AND undone_at IS NULL", eventID).Group("gate").Scan(&gates).Error; err != nil {
+		return nil, fmt.Errorf("failed to count check-ins per gate: %w", err)
+	}
+	for _, row := range gates {
+		gate := row.Gate
+		if gate == "" {
+			gate = unassignedGate
+		}
+		stats.Gates[gate] += row.Count
+	}
+
+	var summary struct {
+		Undone int64
+		Last   *time.Time
+	}
+	if err := db.Model(&models.CheckIn{}).
+		Select("COUNT(undone_at) AS undone, MAX(CASE WHEN undone_at IS NULL THEN checked_in_at END) AS last").
+		Where("event_id = ?", eventID).Scan(&summary).Error; err != nil {
+		return nil, fmt.Errorf("failed to summarize check-ins: %w", err)
+	}
+	stats.Undone = summary.Undone
+	stats.LastCheckInAt = summary.Last
+
+	return stats, nil
+}
+
+// lockTicket loads and locks a ticket of an event the user staffs. The event is returned once the
+// user is authorized for it, even when the ticket is not found.
+func (s *CheckInService) lockTicket(tx *gorm.DB, eventID uint, userID uuid.UUID, roles []string, ticketID uuid.UUID) (*models.Event, *models.Ticket, error) {
+	event, err := s.authorize(tx, eventID, userID, roles)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var ticket models.Ticket
+	err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND event_id = ?", ticketID, eventID).First(&ticket).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return event, nil, ErrTicketNotFound
+		}
+		return event, nil, err
+	}
+	return event, &ticket, nil
+}
+
+// recordCheckInAttempt adds a check-in to the scan audit trail by its outcome. Attempts at events
+// the user does not staff, and failures unrelated to the ticket, are left out.
+func (s *CheckInService) recordCheckInAttempt(ctx context.Context, attempt *models.ScanAttempt, err error) {
+	switch {
+	case err == nil:
+		attempt.Result = models.TicketScanAdmitted
+	case errors.Is(err, ErrTicketAlreadyCheckedIn):
+		attempt.Result = models.TicketScanAlreadyCheckedIn
+	case errors.Is(err, ErrTicketCancelled):
+		attempt.Result = models.TicketScanCancelled
+	case errors.Is(err, ErrTicketRevoked):
+		attempt.Result = models.TicketScanRevoked
+	case errors.Is(err, ErrTicketNotFound):
+		attempt.Result = models.TicketScanNotFound
+	default:
+		return
+	}
+	recordScanAttempts(s.db.WithContext(ctx), []models.ScanAttempt{*attempt})
+}
+
+// authorize loads an event the user may work the door of: admins any, organizers their own, and
+// members the events of the organizers of their organizations
+func (s *CheckInService) authorize(db *gorm.DB, eventID uint, userID uuid.UUID, roles []string) (*models.Event, error) {
+	var event models.Event
+	if err := db.First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+
+	for _, role := range roles {
+		if role == "admin" {
+			return &event, nil
+		}
+	}
+	if event.OrganizerID == nil {
+		return nil, ErrEventAccessDenied
+	}
+	if *event.OrganizerID == userID {
+		return &event, nil
+	}
+
+	var count int64
+	err := db.Model(&models.OrganizationMember{}).
+		Joins("JOIN organizations ON organizations.id = organization_members.organization_id").
+		Where("organization_members.user_id = ? AND organization_members.active AND organizations.organizer_id = ?", userID, *event.OrganizerID).
+		Count(&count).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to check event access: %w", err)
+	}
+	if count == 0 {
+		return nil, ErrEventAccessDenied
+	}
+	return &event, nil
+}
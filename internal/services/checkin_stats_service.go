@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+)
+
+const (
+	// checkInWindow is how many recent minutes live statistics break down
+	checkInWindow = 30 * time.Minute
+	// checkInRetention keeps per-gate totals for the length of a long event day
+	checkInRetention = 48 * time.Hour
+	// checkInRefreshInterval resends statistics to watchers while doors are quiet, so the minute
+	// window keeps moving and idle connections stay open through proxies
+	checkInRefreshInterval = 30 * time.Second
+
+	// unassignedGate counts scans that did not say which gate they came from
+	unassignedGate = "unassigned"
+)
+
+// ErrLiveStatsUnavailable is returned when live statistics cannot be served without Redis
+var ErrLiveStatsUnavailable = errors.New("Live check-in statistics are unavailable")
+
+// CheckInStatsService keeps per-gate and per-minute check-in counters in Redis and publishes a
+// fresh summary on every change, so venue staff on any API instance can watch doors live.
+// Counters are scoped by organization as well as event.
+type CheckInStatsService struct {
+	redisClient redislib.UniversalClient
+}
+
+// NewCheckInStatsService creates a new check-in statistics service
+func NewCheckInStatsService() *CheckInStatsService {
+	return &CheckInStatsService{redisClient: redis.Client}
+}
+
+// Record counts tickets admitted at a gate and publishes the updated statistics. Failures are
+// logged; they never fail the check-in.
+func (s *CheckInStatsService) Record(ctx context.Context, orgID uuid.UUID, eventID uint, gate string, admitted int) {
+	if s.redisClient == nil || admitted == 0 {
+		return
+	}
+	gate = strings.TrimSpace(gate)
+	if gate == "" {
+		gate = unassignedGate
+	}
+
+	gatesKey := checkInGatesKey(orgID, eventID)
+	minuteKey := checkInMinuteKey(orgID, eventID, time.Now().UTC().Truncate(time.Minute))
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.HIncrBy(ctx, gatesKey, gate, int64(admitted))
+	pipe.Expire(ctx, gatesKey, checkInRetention)
+	pipe.HIncrBy(ctx, minuteKey, gate, int64(admitted))
+	pipe.ExpireNX(ctx, minuteKey, checkInWindow+time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to record check-ins for event %d: %v", eventID, err)
+		return
+	}
+
+	stats, err := s.Snapshot(ctx, orgID, eventID)
+	if err != nil {
+		log.Printf("Failed to read check-in statistics for event %d: %v", eventID, err)
+		return
+	}
+	payload, err := json.Marshal(stats)
+	if err != nil {
+		log.Printf("Failed to encode check-in statistics for event %d: %v", eventID, err)
+		return
+	}
+	if err := s.redisClient.Publish(ctx, checkInChannel(orgID, eventID), payload).Err(); err != nil {
+		log.Printf("Failed to publish check-in statistics for event %d: %v", eventID, err)
+	}
+}
+
+// Snapshot returns the current check-in statistics for an event
+func (s *CheckInStatsService) Snapshot(ctx context.Context, orgID uuid.UUID, eventID uint) (*models.CheckInStats, error) {
+	if s.redisClient == nil {
+		return nil, ErrLiveStatsUnavailable
+	}
+
+	now := time.Now().UTC()
+	stats := &models.CheckInStats{
+		EventID:   eventID,
+		Gates:     make(map[string]int64),
+		Minutes:   make([]models.CheckInMinute, int(checkInWindow/time.Minute)),
+		UpdatedAt: now,
+	}
+
+	pipe := s.redisClient.Pipeline()
+	gates := pipe.HGetAll(ctx, checkInGatesKey(orgID, eventID))
+	minutes := make([]*redislib.MapStringStringCmd, len(stats.Minutes))
+	first := now.Truncate(time.Minute).Add(-checkInWindow + time.Minute)
+	for i := range stats.Minutes {
+		stats.Minutes[i].Minute = first.Add(time.Duration(i) * time.Minute)
+		minutes[i] = pipe.HGetAll(ctx, checkInMinuteKey(orgID, eventID, stats.Minutes[i].Minute))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to read check-in counters: %w", err)
+	}
+
+	for gate, count := range parseCounts(gates.Val()) {
+		stats.Gates[gate] = count
+		stats.Total += count
+	}
+	for i, cmd := range minutes {
+		counts := parseCounts(cmd.Val())
+		for _, count := range counts {
+			stats.Minutes[i].Entries += count
+		}
+		if len(counts) > 0 {
+			stats.Minutes[i].Gates = counts
+		}
+	}
+	return stats, nil
+}
+
+// Watch sends the event's check-in statistics to send right away, again whenever they change and
+// periodically while doors are quiet. It blocks until ctx is done or send fails.
+func (s *CheckInStatsService) Watch(ctx context.Context, orgID uuid.UUID, eventID uint, send func(*models.CheckInStats) error) error {
+	if s.redisClient == nil {
+		return ErrLiveStatsUnavailable
+	}
+
+	// Subscribe before taking the first snapshot so no update falls in between
+	pubsub := s.redisClient.Subscribe(ctx, checkInChannel(orgID, eventID))
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return fmt.Errorf("failed to subscribe to check-ins: %w", err)
+	}
+	updates := pubsub.Channel()
+
+	refresh := func() error {
+		stats, err := s.Snapshot(ctx, orgID, eventID)
+		if err != nil {
+			return err
+		}
+		return send(stats)
+	}
+	if err := refresh(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(checkInRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			var stats models.CheckInStats
+			if err := json.Unmarshal([]byte(msg.Payload), &stats); err != nil {
+				log.Printf("Ignoring malformed check-in statistics: %v", err)
+				continue
+			}
+			if err := send(&stats); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := refresh(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// parseCounts converts a Redis hash of counters, skipping fields that are not numbers
+func parseCounts(values map[string]string) map[string]int64 {
+	counts := make(map[string]int64, len(values))
+	for field, value := range values {
+		if count, err := strconv.ParseInt(value, 10, 64); err == nil {
+			counts[field] = count
+		}
+	}
+	return counts
+}
+
+func checkInGatesKey(orgID uuid.UUID, eventID uint) string {
+	return fmt.Sprintf("checkins:%s:%d:gates", orgID, eventID)
+}
+
+func checkInMinuteKey(orgID uuid.UUID, eventID uint, minute time.Time) string {
+	return fmt.Sprintf("checkins:%s:%d:minute:%d", orgID, eventID, minute.Unix())
+}
+
+func checkInChannel(orgID uuid.UUID, eventID uint) string {
+	return redis.ChannelName(fmt.Sprintf("checkins:%s:%d:updates", orgID, eventID))
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskCheckoutReminder reminds a buyer of a checkout they did not complete
+const TaskCheckoutReminder = "checkout:reminder"
+
+// ErrCheckoutSessionNotFound is returned when a resume token matches no session that can be resumed
+var ErrCheckoutSessionNotFound = errors.New("Checkout session not found or expired")
+
+// CheckoutReminderPayload is the payload of a checkout reminder job
+type CheckoutReminderPayload struct {
+	SessionID uuid.UUID `json:"session_id"`
+}
+
+// CheckoutService persists buyers' checkouts in progress so they can be resumed, and sends a
+// single reminder for checkouts left incomplete while their tickets are still available
+type CheckoutService struct {
+	db                *gorm.DB
+	client            *asynq.Client
+	orderService      *OrderService
+	seatMapService    *SeatMapService
+	emailQueueService *EmailQueueService
+	cfg               config.CheckoutConfig
+}
+
+// NewCheckoutService creates a new checkout service
+func NewCheckoutService(cfg *config.Config, orderService *OrderService) *CheckoutService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	return &CheckoutService{
+		db:                database.DB,
+		client:            asynq.NewClient(redisOpts),
+		orderService:      orderService,
+		seatMapService:    NewSeatMapService(cfg),
+		emailQueueService: NewEmailQueueService(cfg),
+		cfg:               cfg.Checkout,
+	}
+}
+
+// StartSession saves a new checkout and schedules its reminder. The selections must be priceable
+// right now, and the buyer admitted to the presale of events in one; the returned resume token is
+// the only way to access the session later.
+func (s *CheckoutService) StartSession(ctx context.Context, userID *uuid.UUID, req *models.CheckoutSessionRequest) (*models.CheckoutSessionResponse, error) {
+	quote, err := s.orderService.QuoteOrder(ctx, quoteRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	presaleID, err := s.admit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newResumeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := models.CheckoutSession{
+		TokenHash:      utils.HashToken(token),
+		UserID:         userID,
+		Email:          strings.ToLower(req.Email),
+		Name:           req.Name,
+		Items:          req.Items,
+		PromoCode:      req.PromoCode,
+		AccessCode:     strings.TrimSpace(req.AccessCode),
+		PresaleID:      presaleID,
+		Currency:       quote.Currency,
+		MarketingOptIn: req.MarketingOptIn,
+		Status:         models.CheckoutSessionOpen,
+		ExpiresAt:      time.Now().Add(s.cfg.SessionTTL),
+	}
+	if err := s.db.WithContext(ctx).Create(&session).Error; err != nil {
+		return nil, fmt.Errorf("failed to save checkout session: %w", err)
+	}
+
+	// A missing reminder must not block the checkout itself
+	if err := s.scheduleReminder(&session); err != nil {
+		log.Printf("Failed to schedule checkout reminder: Session=%s, Error=%v", session.ID, err)
+	}
+
+	resp := session.ToResponse()
+	resp.ResumeToken = token
+	resp.Quote = quote
+	return &resp, nil
+}
+
+// ResumeSession returns a checkout with its selections priced at current prices. Selections that
+// can no longer be priced, e.g. because they sold out, are reported in quote_error.
+func (s *CheckoutService) ResumeSession(ctx context.Context, token string) (*models.CheckoutSessionResponse, error) {
+	session, err := s.find(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := session.ToResponse()
+	if session.Status != models.CheckoutSessionOpen {
+		return &resp, nil
+	}
+	if resp.Quote, err = s.orderService.QuoteOrder(ctx, quoteRequest(sessionRequest(session))); err != nil {
+		resp.QuoteError = err.Error()
+	}
+	return &resp, nil
+}
+
+// UpdateSession replaces the selections and contact details of an open checkout
+func (s *CheckoutService) UpdateSession(ctx context.Context, token string, req *models.CheckoutSessionRequest) (*models.CheckoutSessionResponse, error) {
+	session, err := s.find(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.CheckoutSessionOpen {
+		return nil, errors.New("Checkout has already been completed")
+	}
+
+	quote, err := s.orderService.QuoteOrder(ctx, quoteRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	presaleID, err := s.admit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Email = strings.ToLower(req.Email)
+	session.Name = req.Name
+	session.Items = req.Items
+	session.PromoCode = req.PromoCode
+	session.AccessCode = strings.TrimSpace(req.AccessCode)
+	session.PresaleID = presaleID
+	session.Currency = quote.Currency
+	session.MarketingOptIn = req.MarketingOptIn
+	if err := s.db.WithContext(ctx).Save(session).Error; err != nil {
+		return nil, fmt.Errorf("failed to save checkout session: %w", err)
+	}
+
+	resp := session.ToResponse()
+	resp.Quote = quote
+	return &resp, nil
+}
+
+// HoldSeats holds seats of an event with a seat map for an open checkout, at most as many as the
+// tickets selected for the event, replacing the seats held for it before
+func (s *CheckoutService) HoldSeats(ctx context.Context, token string, req *models.SeatHoldRequest) (*models.SeatHoldResponse, error) {
+	session, err := s.find(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != models.CheckoutSessionOpen {
+		return nil, errors.New("Checkout has already been completed")
+	}
+
+	quantity := 0
+	for _, item := range session.Items {
+		if item.EventID == req.EventID {
+			quantity += item.Quantity
+		}
+	}
+	if quantity == 0 {
+		return nil, errors.New("Event is not selected in this checkout")
+	}
+	if len(req.SeatIDs) > quantity {
+		return nil, fmt.Errorf("Only %d seats can be selected for the tickets in this checkout", quantity)
+	}
+
+	return s.seatMapService.HoldSeats(ctx, session.ID, req)
+}
+
+// SendReminder emails the buyer of a checkout left incomplete, once. Nothing is sent when the
+// buyer did not opt in, has since ordered the selected events, the session expired or the
+// selections can no longer be bought. The reminder carries a fresh resume token, so earlier
+// resume links stop working.
+func (s *CheckoutService) SendReminder(ctx context.Context, sessionID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	var session models.CheckoutSession
+	if err := db.First(&session, "id = ?", sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if session.Status != models.CheckoutSessionOpen || session.ReminderSentAt != nil ||
+		!session.MarketingOptIn || !session.ExpiresAt.After(time.Now()) {
+		return nil
+	}
+	if err := s.refreshStatus(ctx, &session); err != nil || session.Status != models.CheckoutSessionOpen {
+		return err
+	}
+
+	quote, err := s.orderService.QuoteOrder(ctx, quoteRequest(sessionRequest(&session)))
+	if err != nil {
+		log.Printf("Checkout reminder skipped: Session=%s, Reason=%v", session.ID, err)
+		return nil
+	}
+
+	token, err := newResumeToken()
+	if err != nil {
+		return err
+	}
+
+	// Claim the reminder so a retried or duplicate job never sends a second one
+	now := time.Now()
+	result := db.Model(&models.CheckoutSession{}).
+		Where("id = ? AND status = ? AND reminder_sent_at IS NULL", session.ID, models.CheckoutSessionOpen).
+		Updates(map[string]interface{}{"reminder_sent_at": now, "token_hash": utils.HashToken(token)})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	titles := make([]string, len(quote.Lines))
+	for i, line := range quote.Lines {
+		titles[i] = fmt.Sprintf("%d x %s", line.Quantity, line.Title)
+	}
+	message := fmt.Sprintf("You started ordering tickets but didn't finish: %s, for %s %s in total. "+
+		"Tickets are still available, but we can't hold them for you. Pick up where you left off below.",
+		strings.Join(titles, ", "), formatAmount(quote.Total), quote.Currency)
+	if err := s.emailQueueService.QueueCheckoutReminderEmail(&session, message, s.resumeLink(token)); err != nil {
+		// Release the claim so the retried job can send it
+		if err := db.Model(&models.CheckoutSession{}).Where("id = ?", session.ID).Update("reminder_sent_at", nil).Error; err != nil {
+			log.Printf("Failed to release checkout reminder: Session=%s, Error=%v", session.ID, err)
+		}
+		return fmt.Errorf("failed to queue checkout reminder: %w", err)
+	}
+
+	log.Printf("Checkout reminder sent: Session=%s", session.ID)
+	return nil
+}
+
+// find loads the unexpired session of a resume token and marks it completed if the buyer has
+// since ordered
+func (s *CheckoutService) find(ctx context.Context, token string) (*models.CheckoutSession, error) {
+	var session models.CheckoutSession
+	err := s.db.WithContext(ctx).
+		Where("token_hash = ? AND expires_at > ?", utils.HashToken(token), time.Now()).
+		First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCheckoutSessionNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.refreshStatus(ctx, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// refreshStatus completes an open session once the buyer has ordered any of its events after
+// starting it, whichever way the order was placed
+func (s *CheckoutService) refreshStatus(ctx context.Context, session *models.CheckoutSession) error {
+	if session.Status != models.CheckoutSessionOpen {
+		return nil
+	}
+
+	eventIDs := make([]uint, len(session.Items))
+	for i, item := range session.Items {
+		eventIDs[i] = item.EventID
+	}
+
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.Order{}).
+		Where("LOWER(buyer_email) = ? AND event_id IN ? AND created_at >= ? AND status <> ?",
+			session.Email, eventIDs, session.CreatedAt, models.OrderStatusCancelled).
+		Count(&count).Error
+	if err != nil || count == 0 {
+		return err
+	}
+
+	now := time.Now()
+	session.Status = models.CheckoutSessionCompleted
+	session.CompletedAt = &now
+	return s.db.WithContext(ctx).Model(session).
+		Updates(map[string]interface{}{"status": session.Status, "completed_at": now}).Error
+}
+
+// admit checks the buyer of a checkout may order its events now and returns the presale the
+// buyer was admitted to, if any of the events is in presale
+func (s *CheckoutService) admit(ctx context.Context, req *models.CheckoutSessionRequest) (*uuid.UUID, error) {
+	eventIDs := itemEventIDs(req.Items)
+	admissions, err := s.orderService.presaleService.Admit(s.db.WithContext(ctx), eventIDs, req.Email, req.AccessCode, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, eventID := range eventIDs {
+		if admission, ok := admissions[eventID]; ok {
+			return &admission.PresaleID, nil
+		}
+	}
+	return nil, nil
+}
+
+// scheduleReminder queues the reminder of a session for when it is due
+func (s *CheckoutService) scheduleReminder(session *models.CheckoutSession) error {
+	payload, err := json.Marshal(CheckoutReminderPayload{SessionID: session.ID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkout reminder job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskCheckoutReminder, payload)
+	_, err = s.client.Enqueue(task,
+		asynq.Queue(redis.QueueName(TicketingQueue)),
+		asynq.ProcessAt(session.CreatedAt.Add(s.cfg.ReminderDelay)),
+		asynq.MaxRetry(3),
+		asynq.TaskID("checkout-reminder-"+session.ID.String()),
+	)
+	return err
+}
+
+// resumeLink returns the frontend link resuming a checkout
+func (s *CheckoutService) resumeLink(token string) string {
+	link, err := url.Parse(s.cfg.ResumeURL)
+	if err != nil {
+		return s.cfg.ResumeURL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// newResumeToken generates a random resume token
+func newResumeToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate resume token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// quoteRequest converts checkout selections to a quote request
+func quoteRequest(req *models.CheckoutSessionRequest) *models.OrderQuoteRequest {
+	return &models.OrderQuoteRequest{Items: req.Items, PromoCode: req.PromoCode, Currency: req.Currency}
+}
+
+// sessionRequest returns the selections saved with a session
+func sessionRequest(session *models.CheckoutSession) *models.CheckoutSessionRequest {
+	return &models.CheckoutSessionRequest{Items: session.Items, PromoCode: session.PromoCode, Currency: session.Currency}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ConfirmationEmailService manages the content blocks organizers append to the ticket confirmation
+// emails of their events, such as directions, door times or prohibited items
+type ConfirmationEmailService struct {
+	db                *gorm.DB
+	emailQueueService *EmailQueueService
+}
+
+func NewConfirmationEmailService(cfg *config.Config) *ConfirmationEmailService {
+	return &ConfirmationEmailService{
+		db:                database.DB,
+		emailQueueService: NewEmailQueueService(cfg),
+	}
+}
+
+// Get returns the content blocks of an event the user may manage, as UpdateEvent checks
+func (s *ConfirmationEmailService) Get(ctx context.Context, eventID uint, userID uuid.UUID, roles []string) (*models.ConfirmationEmailResponse, error) {
+	event, err := s.managedEvent(s.db.WithContext(ctx), eventID, userID, roles)
+	if err != nil {
+		return nil, err
+	}
+	return confirmationEmailResponse(event.EmailBlocks), nil
+}
+
+// Update replaces the content blocks of an event the user may manage with sanitized copies of the
+// requested ones. Tickets sent afterwards carry the new blocks; emails already sent are unchanged.
+func (s *ConfirmationEmailService) Update(ctx context.Context, eventID uint, userID uuid.UUID, roles []string, req *models.ConfirmationEmailRequest) (*models.ConfirmationEmailResponse, error) {
+	db := s.db.WithContext(ctx)
+	event, err := s.managedEvent(db, eventID, userID, roles)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := sanitizeContentBlocks(req.Blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	event.EmailBlocks = blocks
+	if err := db.Model(event).Select("email_blocks").Updates(event).Error; err != nil {
+		return nil, err
+	}
+	return confirmationEmailResponse(event.EmailBlocks), nil
+}
+
+// Preview renders the ticket confirmation email of an event the user may manage for a sample
+// attendee, with sanitized copies of the requested blocks, without saving them
+func (s *ConfirmationEmailService) Preview(ctx context.Context, eventID uint, userID uuid.UUID, roles []string, req *models.ConfirmationEmailRequest) (*models.ConfirmationEmailPreview, error) {
+	event, err := s.managedEvent(s.db.WithContext(ctx), eventID, userID, roles)
+	if err != nil {
+		return nil, err
+	}
+	blocks, err := sanitizeContentBlocks(req.Blocks)
+	if err != nil {
+		return nil, err
+	}
+	return s.emailQueueService.PreviewTicketEmail(event, blocks)
+}
+
+func (s *ConfirmationEmailService) managedEvent(db *gorm.DB, eventID uint, userID uuid.UUID, roles []string) (*models.Event, error) {
+	var event models.Event
+	if err := db.First(&event, eventID).Error; err != nil {
+		return nil, err
+	}
+	if err := authorizeEventOwner(db, &event, userID, roles); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// sanitizeContentBlocks returns the blocks with their titles trimmed and their content reduced to
+// safe formatting. Blocks left without any content are rejected.
+func sanitizeContentBlocks(blocks []models.EmailContentBlock) ([]models.EmailContentBlock, error) {
+	sanitized := make([]models.EmailContentBlock, len(blocks))
+	fields := make(map[string]interface{})
+	for i, block := range blocks {
+		sanitized[i] = models.EmailContentBlock{
+			Title:   strings.TrimSpace(block.Title),
+			Content: utils.SanitizeHTML(block.Content),
+		}
+		if sanitized[i].Content == "" {
+			fields[fmt.Sprintf("blocks[%d].content", i)] = "has no content left after removing unsupported HTML"
+		}
+	}
+	if len(fields) > 0 {
+		return nil, utils.NewValidationError("Invalid content blocks", fields)
+	}
+	return sanitized, nil
+}
+
+func confirmationEmailResponse(blocks []models.EmailContentBlock) *models.ConfirmationEmailResponse {
+	if blocks == nil {
+		blocks = []models.EmailContentBlock{}
+	}
+	return &models.ConfirmationEmailResponse{Blocks: blocks}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// contactsSQL aggregates an organization's tickets and orders into one row per contact. Addresses
+// are compared lowercased and trimmed, and merged addresses count towards the contact they were
+// merged into. Cancelled tickets and orders are left out.
+const contactsSQL = `
+WITH activity AS (
+	SELECT LOWER(TRIM(attendee_email)) AS email, attendee_name AS name, event_id,
+		CASE WHEN checked_in_at IS NOT NULL THEN event_id END AS attended_event_id,
+		NULL::uuid AS order_id, 1 AS tickets, 0::double precision AS spend, marketing_opt_in,
+		created_at, COALESCE(checked_in_at, created_at) AS active_at
+	FROM tickets WHERE organization_id = @org AND status <> @cancelled_ticket
+	UNION ALL
+	SELECT LOWER(TRIM(buyer_email)), buyer_name, NULL, NULL, id, 0,
+		CASE WHEN status IN @paid THEN total_amount - refunded_amount ELSE 0 END, FALSE,
+		created_at, COALESCE(paid_at, created_at)
+	FROM orders WHERE organization_id = @org AND status <> @cancelled_order
+)
+SELECT COALESCE(contact_merges.into_email, activity.email) AS email,
+	COALESCE((ARRAY_AGG(activity.name ORDER BY activity.created_at DESC) FILTER (WHERE activity.name <> ''))[1], '') AS name,
+	COALESCE(STRING_AGG(DISTINCT activity.email, ',') FILTER (WHERE contact_merges.id IS NOT NULL), '') AS merged_emails,
+	SUM(activity.spend) AS lifetime_spend,
+	COUNT(DISTINCT activity.order_id) AS orders,
+	SUM(activity.tickets) AS tickets,
+	COUNT(DISTINCT activity.event_id) AS events,
+	COUNT(DISTINCT activity.attended_event_id) AS events_attended,
+	BOOL_OR(activity.marketing_opt_in) AS marketing_opt_in,
+	MIN(activity.created_at) AS first_seen_at,
+	MAX(activity.active_at) AS last_activity_at
+FROM activity
+LEFT JOIN contact_merges ON contact_merges.organization_id = @org AND contact_merges.email = activity.email
+GROUP BY 1`
+
+// contactSorts maps the sorts of ContactQuery to their ORDER BY, most recent or largest first
+var contactSorts = map[string]string{
+	"last_activity":   "last_activity_at DESC",
+	"lifetime_spend":  "lifetime_spend DESC",
+	"events_attended": "events_attended DESC",
+}
+
+var (
+	// ErrContactNotFound is returned when merging an address with no tickets or orders at the organization
+	ErrContactNotFound = errors.New("Contact not found")
+	// ErrContactMergeNotFound is returned for a merge the organization does not have
+	ErrContactMergeNotFound = errors.New("Contact merge not found")
+)
+
+// ContactService presents the attendees and buyers of all of an organization's events as unique
+// contacts, for a CRM view. Contacts are aggregated from the current tickets and orders on every
+// request; only merges of duplicate addresses are stored.
+type ContactService struct {
+	db *gorm.DB
+}
+
+func NewContactService() *ContactService {
+	return &ContactService{db: database.DB}
+}
+
+// contactRow is a contact as aggregated by contactsSQL
+type contactRow struct {
+	Email          string
+	Name           string
+	MergedEmails   string
+	LifetimeSpend  float64
+	Orders         int64
+	Tickets        int64
+	Events         int64
+	EventsAttended int64
+	MarketingOptIn bool
+	FirstSeenAt    time.Time
+	LastActivityAt time.Time
+}
+
+// List returns the requested page of the organization's contacts
+func (s *ContactService) List(ctx context.Context, orgID uuid.UUID, query *models.ContactQuery) ([]models.Contact, *models.PageMeta, error) {
+	var rows []contactRow
+	meta, err := database.Paginate(s.contacts(s.db.WithContext(ctx), orgID, query), query.PageQuery, &rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	contacts := make([]models.Contact, len(rows))
+	for i := range rows {
+		contacts[i] = rows[i].toContact()
+	}
+	return contacts, meta, nil
+}
+
+// Export renders all of the organization's contacts matching the query as CSV
+func (s *ContactService) Export(ctx context.Context, orgID uuid.UUID, query *models.ContactQuery) ([]byte, error) {
+	db := s.db.WithContext(ctx)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"email", "name", "merged_emails", "lifetime_spend", "orders", "tickets",
+		"events", "events_attended", "marketing_opt_in", "first_seen_at", "last_activity_at"}); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.contacts(db, orgID, query).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row contactRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return nil, err
+		}
+		w.Write([]string{
+			row.Email, row.Name, strings.ReplaceAll(row.MergedEmails, ",", ";"), formatAmount(row.LifetimeSpend),
+			strconv.FormatInt(row.Orders, 10), strconv.FormatInt(row.Tickets, 10),
+			strconv.FormatInt(row.Events, 10), strconv.FormatInt(row.EventsAttended, 10),
+			strconv.FormatBool(row.MarketingOptIn), formatTimestamp(&row.FirstSeenAt), formatTimestamp(&row.LastActivityAt),
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ListMerges returns the organization's merged addresses by the contact they were merged into
+func (s *ContactService) ListMerges(ctx context.Context, orgID uuid.UUID) ([]models.ContactMerge, error) {
+	var merges []models.ContactMerge
+	if err := s.db.WithContext(ctx).Where("organization_id = ?", orgID).
+		Order("into_email ASC").Order("email ASC").Find(&merges).Error; err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+// Merge folds an address into another contact of the organization. Addresses already merged into
+// the merged address move along with it; merging into an address that was itself merged lands on
+// the contact that address is part of.
+func (s *ContactService) Merge(ctx context.Context, orgID, userID uuid.UUID, req *models.ContactMergeRequest) (*models.ContactMerge, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	into := strings.ToLower(strings.TrimSpace(req.IntoEmail))
+
+	merge := &models.ContactMerge{OrganizationID: orgID, Email: email, MergedBy: userID}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target models.ContactMerge
+		err := tx.Where("organization_id = ? AND email = ?", orgID, into).First(&target).Error
+		switch {
+		case err == nil:
+			into = target.IntoEmail
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		}
+		if into == email {
+			return utils.NewValidationError("Invalid contact merge", map[string]interface{}{
+				"into_email": "must be a different contact",
+			})
+		}
+
+		var existing int64
+		if err := tx.Model(&models.ContactMerge{}).Where("organization_id = ? AND email = ?", orgID, email).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return utils.NewConflictError(fmt.Sprintf("%s is already merged into another contact; unmerge it first", email))
+		}
+		for _, address := range []string{email, into} {
+			if err := checkContactExists(tx, orgID, address); err != nil {
+				return err
+			}
+		}
+
+		merge.IntoEmail = into
+		if err := tx.Create(merge).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ContactMerge{}).
+			Where("organization_id = ? AND into_email = ?", orgID, email).
+			Update("into_email", into).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+// Unmerge makes a merged address its own contact again
+func (s *ContactService) Unmerge(ctx context.Context, orgID, mergeID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", mergeID, orgID).Delete(&models.ContactMerge{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrContactMergeNotFound
+	}
+	return nil
+}
+
+// contacts selects the organization's contacts matching the query, in the requested order. Ties
+// are broken by address so pages neither skip nor repeat contacts.
+func (s *ContactService) contacts(db *gorm.DB, orgID uuid.UUID, query *models.ContactQuery) *gorm.DB {
+	aggregated := db.Raw(contactsSQL, map[string]interface{}{
+		"org":              orgID,
+		"cancelled_ticket": models.TicketStatusCancelled,
+		"cancelled_order":  models.OrderStatusCancelled,
+		"paid":             []models.OrderStatus{models.OrderStatusPaid, models.OrderStatusRefunded},
+	})
+
+	contacts := db.Table("(?) AS contacts", aggregated)
+	if query.Q != "" {
+		pattern := containsPattern(query.Q)
+		contacts = contacts.Where("email ILIKE ? OR name ILIKE ? OR merged_emails ILIKE ?", pattern, pattern, pattern)
+	}
+
+	order, ok := contactSorts[query.Sort]
+	if !ok {
+		order = contactSorts["last_activity"]
+	}
+	return contacts.Order(order).Order("email ASC")
+}
+
+// checkContactExists fails with ErrContactNotFound unless the address holds a ticket or placed an
+// order at the organization
+func checkContactExists(db *gorm.DB, orgID uuid.UUID, email string) error {
+	var found bool
+	err := db.Raw(`SELECT EXISTS (SELECT 1 FROM tickets WHERE organization_id = ? AND LOWER(TRIM(attendee_email)) = ?)
+		OR EXISTS (SELECT 1 FROM orders WHERE organization_id = ? AND LOWER(TRIM(buyer_email)) = ?)`,
+		orgID, email, orgID, email).Scan(&found).Error
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", ErrContactNotFound, email)
+	}
+	return nil
+}
+
+func (r *contactRow) toContact() models.Contact {
+	contact := models.Contact{
+		Email:          r.Email,
+		Name:           r.Name,
+		LifetimeSpend:  r.LifetimeSpend,
+		Orders:         r.Orders,
+		Tickets:        r.Tickets,
+		Events:         r.Events,
+		EventsAttended: r.EventsAttended,
+		MarketingOptIn: r.MarketingOptIn,
+		FirstSeenAt:    r.FirstSeenAt,
+		LastActivityAt: r.LastActivityAt,
+	}
+	if r.MergedEmails != "" {
+		contact.MergedEmails = strings.Split(r.MergedEmails, ",")
+	}
+	return contact
+}
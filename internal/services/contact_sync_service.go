@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// ContactSyncPayload is the payload of a queued marketing contact sync task
+type ContactSyncPayload struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	SegmentID      *uuid.UUID `json:"segment_id,omitempty"` // Syncs only this attendee segment when set
+}
+
+// GetMarketingIntegration returns the contact sync configuration of an organization
+func (s *IntegrationService) GetMarketingIntegration(ctx context.Context, orgID uuid.UUID) (*models.MarketingIntegrationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var integration models.MarketingIntegration
+	if err := db.Where("organization_id = ?", orgID).First(&integration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Marketing integration not configured")
+		}
+		return nil, err
+	}
+
+	resp := integration.ToResponse()
+	return &resp, nil
+}
+
+// ConfigureMarketingIntegration creates or replaces the contact sync configuration of an organization
+func (s *IntegrationService) ConfigureMarketingIntegration(ctx context.Context, orgID uuid.UUID, req *models.MarketingIntegrationRequest) (*models.MarketingIntegrationResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var integration models.MarketingIntegration
+	if err := db.Where("organization_id = ?", orgID).First(&integration).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	integration.OrganizationID = orgID
+	integration.Provider = models.MarketingProvider(req.Provider)
+	integration.APIKey = req.APIKey
+	integration.AudienceID = req.AudienceID
+	integration.FieldMapping = req.FieldMapping
+	if len(integration.FieldMapping) == 0 {
+		integration.FieldMapping = models.DefaultMarketingFieldMapping(integration.Provider)
+	}
+	integration.TagByEvent = req.TagByEvent == nil || *req.TagByEvent
+	integration.Enabled = req.Enabled == nil || *req.Enabled
+
+	// Validate the provider settings, such as the Mailchimp data center, before persisting them
+	if _, err := NewContactSyncProvider(&integration); err != nil {
+		return nil, err
+	}
+
+	if err := db.Save(&integration).Error; err != nil {
+		return nil, err
+	}
+
+	resp := integration.ToResponse()
+	return &resp, nil
+}
+
+// DeleteMarketingIntegration removes the contact sync configuration of an organization
+func (s *IntegrationService) DeleteMarketingIntegration(ctx context.Context, orgID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	result := db.Where("organization_id = ?", orgID).Delete(&models.MarketingIntegration{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("Marketing integration not configured")
+	}
+
+	return nil
+}
+
+// QueueContactSync queues a background sync of an organization's opted-in attendees.
+// Repeated calls within a short window collapse into a single job.
+func (s *IntegrationService) QueueContactSync(orgID uuid.UUID) error {
+	payload, err := json.Marshal(ContactSyncPayload{OrganizationID: orgID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal contact sync job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskContactSync, payload)
+	_, err = s.client.Enqueue(task,
+		asynq.Queue(redis.QueueName(IntegrationQueue)),
+		asynq.MaxRetry(3),
+		asynq.Unique(5*time.Minute),
+	)
+	if err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		return fmt.Errorf("failed to enqueue contact sync: %w", err)
+	}
+
+	return nil
+}
+
+// QueueSegmentSync queues a background sync of the opted-in attendees of an attendee segment.
+// Repeated calls for the same segment within a short window collapse into a single job.
+func (s *IntegrationService) QueueSegmentSync(orgID, segmentID uuid.UUID) error {
+	payload, err := json.Marshal(ContactSyncPayload{OrganizationID: orgID, SegmentID: &segmentID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal segment sync job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskContactSync, payload)
+	_, err = s.client.Enqueue(task,
+		asynq.Queue(redis.QueueName(IntegrationQueue)),
+		asynq.MaxRetry(3),
+		asynq.Unique(5*time.Minute),
+	)
+	if err != nil && !errors.Is(err, asynq.ErrDuplicateTask) {
+		return fmt.Errorf("failed to enqueue segment sync: %w", err)
+	}
+
+	return nil
+}
+
+// SyncSegmentContacts uploads every opted-in attendee an attendee segment currently selects to
+// the organization's marketing audience, tagged with the segment. It leaves the incremental sync
+// of SyncContacts untouched.
+func (s *IntegrationService) SyncSegmentContacts(ctx context.Context, orgID, segmentID uuid.UUID) error {
+	var integration models.MarketingIntegration
+	if err := s.db.Where("organization_id = ?", orgID).First(&integration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Integration removed after the job was queued
+		}
+		return err
+	}
+
+	if !integration.Enabled {
+		return nil
+	}
+
+	var segment models.AttendeeSegment
+	if err := s.db.Where("id = ? AND organization_id = ?", segmentID, orgID).First(&segment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Segment deleted after the job was queued
+		}
+		return err
+	}
+
+	provider, err := NewContactSyncProvider(&integration)
+	if err != nil {
+		return err
+	}
+
+	var tickets []models.Ticket
+	if err := segmentTickets(s.db, orgID, &segment.Criteria).Preload("Event").
+		Where("tickets.marketing_opt_in = ?", true).
+		Order("tickets.created_at ASC").Find(&tickets).Error; err != nil {
+		return err
+	}
+
+	contacts := buildMarketingContacts(&integration, tickets)
+	tag := segmentTag(&segment)
+	for _, contact := range contacts {
+		contact.Tags = append(contact.Tags, tag)
+		if err := provider.UpsertContact(ctx, contact); err != nil {
+			return fmt.Errorf("failed to sync contact %s: %w", contact.Email, err)
+		}
+	}
+
+	log.Printf("Segment contacts synced: Organization=%s, Segment=%s, Provider=%s, Contacts=%d",
+		orgID, segment.ID, integration.Provider, len(contacts))
+	return nil
+}
+
+// SyncContacts uploads opted-in attendees created since the last successful sync
+// to the organization's marketing audience
+func (s *IntegrationService) SyncContacts(ctx context.Context, orgID uuid.UUID) error {
+	var integration models.MarketingIntegration
+	if err := s.db.Where("organization_id = ?", orgID).First(&integration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil // Integration removed after the job was queued
+		}
+		return err
+	}
+
+	if !integration.Enabled {
+		return nil
+	}
+
+	provider, err := NewContactSyncProvider(&integration)
+	if err != nil {
+		return err
+	}
+
+	syncStartedAt := time.Now()
+
+	query := s.db.Preload("Event").
+		Where("organization_id = ? AND marketing_opt_in = ?", orgID, true)
+	if integration.LastSyncedAt != nil {
+		query = query.Where("updated_at > ?", *integration.LastSyncedAt)
+	}
+
+	var tickets []models.Ticket
+	if err := query.Order("created_at ASC").Find(&tickets).Error; err != nil {
+		return err
+	}
+
+	contacts := buildMarketingContacts(&integration, tickets)
+
+	var syncErr error
+	for _, contact := range contacts {
+		if err := provider.UpsertContact(ctx, contact); err != nil {
+			syncErr = fmt.Errorf("failed to sync contact %s: %w", contact.Email, err)
+			break
+		}
+	}
+
+	updates := map[string]interface{}{"last_error": ""}
+	if syncErr != nil {
+		updates["last_error"] = syncErr.Error()
+	} else {
+		updates["last_synced_at"] = syncStartedAt
+	}
+	if err := s.db.Model(&integration).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record contact sync state: Organization=%s, Error=%v", orgID, err)
+	}
+
+	if syncErr != nil {
+		return syncErr
+	}
+
+	log.Printf("Marketing contacts synced: Organization=%s, Provider=%s, Contacts=%d",
+		orgID, integration.Provider, len(contacts))
+	return nil
+}
+
+// buildMarketingContacts maps tickets onto provider contacts, merging tickets that share an email
+func buildMarketingContacts(integration *models.MarketingIntegration, tickets []models.Ticket) []*models.MarketingContact {
+	byEmail := make(map[string]*models.MarketingContact)
+	var contacts []*models.MarketingContact
+
+	for _, ticket := range tickets {
+		email := strings.ToLower(ticket.AttendeeEmail)
+		contact, exists := byEmail[email]
+		if !exists {
+			contact = &models.MarketingContact{Email: email, Fields: map[string]string{}}
+			byEmail[email] = contact
+			contacts = append(contacts, contact)
+		}
+
+		for source, target := range integration.FieldMapping {
+			if value := contactFieldValue(source, &ticket); value != "" {
+				contact.Fields[target] = value
+			}
+		}
+
+		if integration.TagByEvent && ticket.Event != nil {
+			tag := eventTag(ticket.Event)
+			if !slices.Contains(contact.Tags, tag) {
+				contact.Tags = append(contact.Tags, tag)
+			}
+		}
+	}
+
+	return contacts
+}
+
+// contactFieldValue resolves a source contact field from a ticket
+func contactFieldValue(field string, ticket *models.Ticket) string {
+	firstName, lastName, _ := strings.Cut(strings.TrimSpace(ticket.AttendeeName), " ")
+
+	switch field {
+	case models.ContactFieldFirstName:
+		return firstName
+	case models.ContactFieldLastName:
+		return strings.TrimSpace(lastName)
+	case models.ContactFieldFullName:
+		return strings.TrimSpace(ticket.AttendeeName)
+	case models.ContactFieldEventTitle:
+		if ticket.Event != nil {
+			return ticket.Event.Title
+		}
+	case models.ContactFieldEventDate:
+		if ticket.Event != nil {
+			return ticket.Event.StartDate.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// eventTag returns the audience tag applied to attendees of an event
+func eventTag(event *models.Event) string {
+	return fmt.Sprintf("event-%d", event.ID)
+}
+
+// segmentTag returns the audience tag applied to attendees of a segment, e.g. "segment-checked-in-vips"
+func segmentTag(segment *models.AttendeeSegment) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(segment.Name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	return "segment-" + strings.TrimSuffix(b.String(), "-")
+}
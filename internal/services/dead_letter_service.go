@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/hibiken/asynq"
+)
+
+// EmailQueues are the priority queues email jobs are sent through
+var EmailQueues = []string{
+	"queue:email:urgent",
+	"queue:email:high",
+	"queue:email:normal",
+	"queue:email:low",
+}
+
+// DeadLetterService inspects and requeues email jobs that exhausted their retries. Asynq keeps
+// these as archived tasks in their queue.
+type DeadLetterService struct {
+	inspector *asynq.Inspector
+}
+
+// NewDeadLetterService creates a new dead-letter service
+func NewDeadLetterService(cfg *config.Config) *DeadLetterService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	return &DeadLetterService{
+		inspector: asynq.NewInspector(redisOpts),
+	}
+}
+
+// ListDeadLetterEmails returns up to limit dead-letter email jobs per queue
+func (s *DeadLetterService) ListDeadLetterEmails(limit int) ([]*asynq.TaskInfo, error) {
+	var tasks []*asynq.TaskInfo
+	for _, queue := range EmailQueues {
+		archived, err := s.inspector.ListArchivedTasks(redis.QueueName(queue), asynq.PageSize(limit))
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list dead-letter emails in %s: %w", queue, err)
+		}
+		tasks = append(tasks, archived...)
+	}
+	return tasks, nil
+}
+
+// RequeueDeadLetterEmail moves one dead-letter email job back to its queue
+func (s *DeadLetterService) RequeueDeadLetterEmail(queue, taskID string) error {
+	if err := s.inspector.RunTask(queue, taskID); err != nil {
+		return fmt.Errorf("failed to requeue email %s: %w", taskID, err)
+	}
+	return nil
+}
+
+// RequeueAllDeadLetterEmails moves every dead-letter email job back to its queue and returns how
+// many were requeued
+func (s *DeadLetterService) RequeueAllDeadLetterEmails() (int, error) {
+	total := 0
+	for _, queue := range EmailQueues {
+		n, err := s.inspector.RunAllArchivedTasks(redis.QueueName(queue))
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			continue
+		}
+		if err != nil {
+			return total, fmt.Errorf("failed to requeue dead-letter emails in %s: %w", queue, err)
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// Close closes the inspector connection
+func (s *DeadLetterService) Close() error {
+	return s.inspector.Close()
+}
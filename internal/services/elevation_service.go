@@ -0,0 +1,313 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Audit actions of admin elevations
+const (
+	AuditElevationRequested = "elevation.requested"
+	AuditElevationApproved  = "elevation.approved"
+	AuditElevationRejected  = "elevation.rejected"
+	AuditElevationRevoked   = "elevation.revoked"
+	AuditElevationUsed      = "elevation.used"
+)
+
+var (
+	// ErrElevationNotFound is returned for an elevation that does not exist
+	ErrElevationNotFound = errors.New("Elevation not found")
+	// ErrElevationRequired is returned when a destructive action is attempted without an active elevation
+	ErrElevationRequired = errors.New("This action requires elevated access; request an elevation and use its access token")
+)
+
+// ElevationService grants admins time-boxed break-glass access for destructive actions. Requests
+// are granted at once or, when ELEVATION_REQUIRE_APPROVAL is set, once a second admin approves
+// them. Every request, review, revocation and use is written to the audit log.
+type ElevationService struct {
+	db         *gorm.DB
+	jwtService *utils.JWTService
+	cfg        config.SecurityConfig
+}
+
+// NewElevationService creates a new elevation service
+func NewElevationService(cfg *config.Config) *ElevationService {
+	return &ElevationService{
+		db:         database.DB,
+		jwtService: utils.NewJWTService(&cfg.JWT),
+		cfg:        cfg.Security,
+	}
+}
+
+// RequestElevation records an admin's request for elevated access. Without required approval the
+// elevation is active at once and returned with its elevated access token.
+func (s *ElevationService) RequestElevation(ctx context.Context, adminID uuid.UUID, req *models.ElevationRequest) (*models.AdminElevation, error) {
+	duration := time.Duration(req.DurationMinutes) * time.Minute
+	if duration > s.cfg.ElevationMaxTTL {
+		return nil, fmt.Errorf("Elevations can last at most %s", s.cfg.ElevationMaxTTL)
+	}
+
+	elevation := models.AdminElevation{
+		UserID:   adminID,
+		Reason:   req.Reason,
+		Duration: req.DurationMinutes,
+		Status:   models.ElevationStatusPendingApproval,
+	}
+	now := time.Now()
+	if !s.cfg.ElevationRequireApproval {
+		grantElevation(&elevation, now)
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var open int64
+		err := tx.Model(&models.AdminElevation{}).
+			Where("user_id = ? AND (status = ? AND created_at > ? OR status = ? AND expires_at > ?)",
+				adminID, models.ElevationStatusPendingApproval, now.Add(-s.cfg.ElevationRequestTTL),
+				models.ElevationStatusActive, now).
+			Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return errors.New("You already have a pending or active elevation")
+		}
+
+		if err := tx.Create(&elevation).Error; err != nil {
+			return fmt.Errorf("failed to create elevation: %w", err)
+		}
+		return writeAuditLog(tx, &adminID, AuditElevationRequested, "admin_elevation", elevation.ID.String(), nil, map[string]interface{}{
+			"reason":            elevation.Reason,
+			"duration_minutes":  elevation.Duration,
+			"requires_approval": s.cfg.ElevationRequireApproval,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if elevation.Status == models.ElevationStatusActive {
+		log.Printf("Admin elevation granted: Elevation=%s, Admin=%s, Expires=%s", elevation.ID, adminID, elevation.ExpiresAt.Format(time.RFC3339))
+		if err := s.issueToken(ctx, &elevation); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Printf("Admin elevation awaiting approval: Elevation=%s, Admin=%s", elevation.ID, adminID)
+	}
+	return &elevation, nil
+}
+
+// ListElevations returns the most recent elevations, optionally of one admin and/or status
+func (s *ElevationService) ListElevations(ctx context.Context, userID *uuid.UUID, status string) ([]models.AdminElevation, error) {
+	if err := s.expire(ctx); err != nil {
+		return nil, err
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.AdminElevation{})
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var elevations []models.AdminElevation
+	if err := query.Order("created_at DESC").Limit(200).Find(&elevations).Error; err != nil {
+		return nil, err
+	}
+	return elevations, nil
+}
+
+// ApproveElevation grants a pending elevation for its requested duration from now. The approver
+// must be a different admin from the requester.
+func (s *ElevationService) ApproveElevation(ctx context.Context, elevationID, adminID uuid.UUID, note string) (*models.AdminElevation, error) {
+	return s.review(ctx, elevationID, adminID, note, true)
+}
+
+// RejectElevation rejects a pending elevation
+func (s *ElevationService) RejectElevation(ctx context.Context, elevationID, adminID uuid.UUID, note string) (*models.AdminElevation, error) {
+	return s.review(ctx, elevationID, adminID, note, false)
+}
+
+// IssueToken returns an elevated access token for an active elevation of the admin, e.g. once a
+// second admin approved it
+func (s *ElevationService) IssueToken(ctx context.Context, elevationID, adminID uuid.UUID) (*models.AdminElevation, error) {
+	elevation, err := s.find(ctx, elevationID)
+	if err != nil {
+		return nil, err
+	}
+	if elevation.UserID != adminID {
+		return nil, ErrElevationNotFound
+	}
+	if !elevation.IsActive(time.Now()) {
+		return nil, errors.New("Elevation is not active")
+	}
+
+	if err := s.issueToken(ctx, elevation); err != nil {
+		return nil, err
+	}
+	return elevation, nil
+}
+
+// RevokeElevation ends an active or pending elevation early. Its elevated tokens stop working at
+// once.
+func (s *ElevationService) RevokeElevation(ctx context.Context, elevationID, adminID uuid.UUID) (*models.AdminElevation, error) {
+	elevation, err := s.find(ctx, elevationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(elevation).
+			Where("status IN ?", []models.ElevationStatus{models.ElevationStatusPendingApproval, models.ElevationStatusActive}).
+			Updates(map[string]interface{}{"status": models.ElevationStatusRevoked, "revoked_by": adminID, "revoked_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("Elevation is already %s", elevation.Status)
+		}
+		return writeAuditLog(tx, &adminID, AuditElevationRevoked, "admin_elevation", elevation.ID.String(), nil, map[string]interface{}{
+			"user_id": elevation.UserID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	elevation.Status = models.ElevationStatusRevoked
+	elevation.RevokedBy = &adminID
+	elevation.RevokedAt = &now
+	log.Printf("Admin elevation revoked: Elevation=%s, By=%s", elevation.ID, adminID)
+	return elevation, nil
+}
+
+// AuthorizeElevated checks that an elevated token's elevation is still active for the admin and
+// records the destructive action it is used for
+func (s *ElevationService) AuthorizeElevated(ctx context.Context, elevationID, adminID uuid.UUID, action string) error {
+	var elevation models.AdminElevation
+	err := s.db.WithContext(ctx).First(&elevation, "id = ? AND user_id = ?", elevationID, adminID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrElevationRequired
+		}
+		return err
+	}
+	if !elevation.IsActive(time.Now()) {
+		return ErrElevationRequired
+	}
+
+	return writeAuditLog(s.db.WithContext(ctx), &adminID, AuditElevationUsed, "admin_elevation", elevation.ID.String(), nil, map[string]interface{}{
+		"action": action,
+	})
+}
+
+// review records a second admin's decision on a pending elevation
+func (s *ElevationService) review(ctx context.Context, elevationID, adminID uuid.UUID, note string, approve bool) (*models.AdminElevation, error) {
+	elevation, err := s.find(ctx, elevationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if elevation.Status != models.ElevationStatusPendingApproval {
+		return nil, fmt.Errorf("Elevation is already %s", elevation.Status)
+	}
+	if elevation.UserID == adminID {
+		return nil, errors.New("Elevations must be reviewed by a different admin than the requester")
+	}
+	now := time.Now()
+	if approve && !now.Before(elevation.CreatedAt.Add(s.cfg.ElevationRequestTTL)) {
+		return nil, errors.New("Elevation request has expired; the admin must request it again")
+	}
+
+	elevation.ReviewedBy = &adminID
+	elevation.ReviewedAt = &now
+	elevation.ReviewNote = note
+	action := AuditElevationApproved
+	if approve {
+		grantElevation(elevation, now)
+	} else {
+		action = AuditElevationRejected
+		elevation.Status = models.ElevationStatusRejected
+	}
+
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AdminElevation{}).
+			Where("id = ? AND status = ?", elevation.ID, models.ElevationStatusPendingApproval).
+			Updates(map[string]interface{}{
+				"status":      elevation.Status,
+				"reviewed_by": adminID,
+				"reviewed_at": now,
+				"review_note": note,
+				"granted_at":  elevation.GrantedAt,
+				"expires_at":  elevation.ExpiresAt,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Elevation was reviewed concurrently")
+		}
+		return writeAuditLog(tx, &adminID, action, "admin_elevation", elevation.ID.String(), nil, map[string]interface{}{
+			"user_id": elevation.UserID,
+			"note":    note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return elevation, nil
+}
+
+// issueToken sets the elevated access token of an active elevation
+func (s *ElevationService) issueToken(ctx context.Context, elevation *models.AdminElevation) error {
+	var user models.User
+	if err := s.db.WithContext(ctx).Preload("Roles").First(&user, "id = ?", elevation.UserID).Error; err != nil {
+		return fmt.Errorf("failed to load admin: %w", err)
+	}
+
+	token, err := s.jwtService.GenerateElevatedToken(&user, elevation.ID, *elevation.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	elevation.AccessToken = token
+	return nil
+}
+
+// expire marks active elevations past their end as expired
+func (s *ElevationService) expire(ctx context.Context) error {
+	return s.db.WithContext(ctx).Model(&models.AdminElevation{}).
+		Where("status = ? AND expires_at <= ?", models.ElevationStatusActive, time.Now()).
+		Update("status", models.ElevationStatusExpired).Error
+}
+
+// find loads an elevation
+func (s *ElevationService) find(ctx context.Context, elevationID uuid.UUID) (*models.AdminElevation, error) {
+	var elevation models.AdminElevation
+	if err := s.db.WithContext(ctx).First(&elevation, "id = ?", elevationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrElevationNotFound
+		}
+		return nil, err
+	}
+	return &elevation, nil
+}
+
+// grantElevation activates an elevation for its duration from now
+func grantElevation(elevation *models.AdminElevation, now time.Time) {
+	expiresAt := now.Add(time.Duration(elevation.Duration) * time.Minute)
+	elevation.Status = models.ElevationStatusActive
+	elevation.GrantedAt = &now
+	elevation.ExpiresAt = &expiresAt
+}
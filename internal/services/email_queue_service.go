@@ -4,39 +4,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"strconv"
+	"net/url"
+	"strings"
+	"time"
 
+	"event-ticketing-backend/internal/database"
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
 	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 )
 
 // EmailQueueService handles email job queuing using Asynq
 type EmailQueueService struct {
-	client *asynq.Client
+	client   *asynq.Client
+	direct   *EmailService // Sends emails right away while the queue is unavailable
+	portal   config.PortalConfig
+	qrSecret string
 }
 
 // NewEmailQueueService creates a new email queue service
 func NewEmailQueueService(cfg *config.Config) *EmailQueueService {
-	// Convert DB string to int for Asynq
-	db := 0
-	if cfg.Redis.DB != "" {
-		if dbInt, err := strconv.Atoi(cfg.Redis.DB); err == nil {
-			db = dbInt
-		}
-	}
-
-	redisOpts := asynq.RedisClientOpt{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       db,
-	}
+	redisOpts := redis.QueueConnOpt(cfg)
 
 	client := asynq.NewClient(redisOpts)
 
 	return &EmailQueueService{
-		client: client,
+		client:   client,
+		direct:   NewEmailService(cfg),
+		portal:   cfg.Portal,
+		qrSecret: cfg.Scan.QRSecret,
 	}
 }
 
@@ -83,6 +83,349 @@ func (s *EmailQueueService) QueueWelcomeEmail(to, firstName string) error {
 	return s.queueEmailJob(emailJob)
 }
 
+// QueueNotificationEmail queues a general notification email
+func (s *EmailQueueService) QueueNotificationEmail(to, name, subject, message string) error {
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeNotification,
+		To:           to,
+		Subject:      subject,
+		TemplateFile: "notification.html",
+		TemplateData: map[string]interface{}{
+			"Title":   subject,
+			"Name":    name,
+			"Message": message,
+		},
+		Priority:   models.PriorityNormal,
+		MaxRetries: 3,
+	}
+	emailJob.SetDefaults()
+
+	return s.queueEmailJob(emailJob)
+}
+
+// QueueTicketEmail queues a ticket confirmation email to an attendee with the ticket attached as a
+// PDF and the event as an iCalendar file. It links to the ticket's self-service page, where the
+// attendee can view it without an account. The delivery is tracked in the notification log.
+func (s *EmailQueueService) QueueTicketEmail(ticket *models.Ticket, event *models.Event, ticketType string) error {
+	var attachments []models.EmailAttachment
+	if pdf, err := renderTicketPDF(ticket, event, ticketType, s.TicketQRCode(ticket.ID)); err != nil {
+		log.Printf("Failed to render ticket PDF, sending the email without it: Ticket=%s, Error=%v", ticket.ID, err)
+	} else {
+		attachments = append(attachments, models.EmailAttachment{
+			Filename:    TicketPDFFilename(ticket),
+			ContentType: TicketPDFContentType,
+			Content:     pdf,
+		})
+	}
+	attachments = append(attachments, models.EmailAttachment{
+		Filename:    CalendarFilename(event),
+		ContentType: CalendarContentType,
+		Content:     ticketICS(ticket, event, s.TicketManageURL(ticket.ID)),
+	})
+
+	emailJob := s.ticketEmailJob(ticket, event, ticketType, event.EmailBlocks)
+	emailJob.Attachments = attachments
+	emailJob.SetDefaults()
+
+	orderID, ticketID := ticket.OrderID, ticket.ID
+	return s.queueLoggedEmail(emailJob, &models.NotificationLog{
+		OrganizationID: ticket.OrganizationID,
+		OrderID:        &orderID,
+		TicketID:       &ticketID,
+	})
+}
+
+// PreviewTicketEmail renders the ticket confirmation email of an event for a sample attendee, with
+// the given content blocks in place of the event's own
+func (s *EmailQueueService) PreviewTicketEmail(event *models.Event, blocks []models.EmailContentBlock) (*models.ConfirmationEmailPreview, error) {
+	ticket := &models.Ticket{
+		ID:            uuid.New(),
+		EventID:       event.ID,
+		AttendeeName:  "Alex Example",
+		AttendeeEmail: "attendee@example.com",
+	}
+	emailJob := s.ticketEmailJob(ticket, event, "General Admission", blocks)
+	body, err := s.direct.RenderJob(emailJob)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ConfirmationEmailPreview{Subject: emailJob.Subject, HTML: body, Blocks: blocks}, nil
+}
+
+// ticketEmailJob returns the ticket confirmation email of a ticket, with the event's content blocks
+// after the ticket details
+func (s *EmailQueueService) ticketEmailJob(ticket *models.Ticket, event *models.Event, ticketType string, blocks []models.EmailContentBlock) *models.EmailJob {
+	// Keyed by field name, so the template reads them alike before and after queuing as JSON
+	contentBlocks := make([]map[string]interface{}, len(blocks))
+	for i, block := range blocks {
+		contentBlocks[i] = map[string]interface{}{"Title": block.Title, "Content": block.Content}
+	}
+
+	return &models.EmailJob{
+		Type:         models.EmailTypeTicketConfirmation,
+		To:           ticket.AttendeeEmail,
+		Subject:      fmt.Sprintf("Your ticket for %s", event.Title),
+		TemplateFile: "ticket_confirmation.html",
+		TemplateData: map[string]interface{}{
+			"Name":          ticket.AttendeeName,
+			"EventName":     event.Title,
+			"EventDate":     event.StartDate.Format("Monday, January 2, 2006"),
+			"EventTime":     event.StartDate.Format("3:04 PM"),
+			"EventVenue":    event.Location,
+			"TicketID":      ticket.ID.String(),
+			"TicketType":    ticketType,
+			"QRCode":        s.TicketQRCode(ticket.ID),
+			"DownloadURL":   s.TicketManageURL(ticket.ID),
+			"ContentBlocks": contentBlocks,
+		},
+		Priority:   models.PriorityHigh,
+		MaxRetries: 3,
+		TicketID:   ticket.ID.String(),
+	}
+}
+
+// QueuePaymentReminderEmail queues a reminder about an order's payment to its buyer, such as a
+// failed installment. The delivery, opens and clicks are tracked in the notification log under
+// the order.
+func (s *EmailQueueService) QueuePaymentReminderEmail(order *models.Order, subject, message string) error {
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypePaymentReminder,
+		To:           order.BuyerEmail,
+		Subject:      subject,
+		TemplateFile: "notification.html",
+		TemplateData: map[string]interface{}{
+			"Title":   subject,
+			"Name":    order.BuyerName,
+			"Message": message,
+		},
+		Priority:   models.PriorityNormal,
+		MaxRetries: 3,
+	}
+	emailJob.SetDefaults()
+
+	orderID := order.ID
+	return s.queueLoggedEmail(emailJob, &models.NotificationLog{
+		UserID:         order.UserID,
+		OrganizationID: order.OrganizationID,
+		OrderID:        &orderID,
+	})
+}
+
+// QueueCheckoutReminderEmail queues a reminder of a checkout left incomplete, with a button
+// resuming it. The delivery, opens and clicks are tracked in the notification log.
+func (s *EmailQueueService) QueueCheckoutReminderEmail(session *models.CheckoutSession, message, resumeURL string) error {
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeReminder,
+		To:           session.Email,
+		Subject:      "Your tickets are waiting",
+		TemplateFile: "notification.html",
+		TemplateData: map[string]interface{}{
+			"Title":      "Your tickets are waiting",
+			"Name":       session.Name,
+			"Message":    message,
+			"ActionURL":  resumeURL,
+			"ActionText": "Complete your order",
+		},
+		Priority:   models.PriorityNormal,
+		MaxRetries: 3,
+	}
+	emailJob.SetDefaults()
+
+	return s.queueLoggedEmail(emailJob, &models.NotificationLog{UserID: session.UserID})
+}
+
+// queueLoggedEmail adds an email to the notification log and queues it, tracking its delivery and,
+// for types that may be tracked, its opens and clicks
+func (s *EmailQueueService) queueLoggedEmail(emailJob *models.EmailJob, entry *models.NotificationLog) error {
+	entry.Channel = models.NotificationChannelEmail
+	entry.Type = string(emailJob.Type)
+	entry.Recipient = emailJob.To
+	entry.Tracked = s.direct.tracks(emailJob.Type)
+
+	notificationID := logNotification(database.DB, entry)
+	if notificationID != nil {
+		emailJob.NotificationID = notificationID.String()
+		emailJob.Tracked = entry.Tracked
+	}
+
+	if err := s.queueEmailJob(emailJob); err != nil {
+		if notificationID != nil {
+			recordNotificationAttempt(database.DB, *notificationID, "", err)
+		}
+		return err
+	}
+	return nil
+}
+
+// TicketManageURL returns the link to a ticket's self-service page
+func (s *EmailQueueService) TicketManageURL(ticketID uuid.UUID) string {
+	token := utils.SignTicketToken(s.portal.Secret, ticketID)
+	link, err := url.Parse(s.portal.URL)
+	if err != nil {
+		return s.portal.URL + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// TicketQRCode returns the signed payload of a ticket's QR code, which door scanners validate
+func (s *EmailQueueService) TicketQRCode(ticketID uuid.UUID) string {
+	return utils.SignTicketQR(s.qrSecret, ticketID)
+}
+
+// QueueReceiptEmail queues a payment receipt for a paid order, itemizing tickets and insurance
+func (s *EmailQueueService) QueueReceiptEmail(order *models.Order, event *models.Event) error {
+	paidAt := time.Now()
+	if order.PaidAt != nil {
+		paidAt = *order.PaidAt
+	}
+	insurance := ""
+	if order.InsuranceAmount > 0 {
+		insurance = fmt.Sprintf("%.2f", order.InsuranceAmount)
+	}
+
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypePaymentConfirmation,
+		To:           order.BuyerEmail,
+		Subject:      fmt.Sprintf("Your receipt for %s", event.Title),
+		TemplateFile: "payment_confirmation.html",
+		TemplateData: map[string]interface{}{
+			"RecipientName":   order.BuyerName,
+			"EventName":       event.Title,
+			"Quantity":        order.Quantity,
+			"TicketsAmount":   fmt.Sprintf("%.2f", order.TotalAmount-order.InsuranceAmount),
+			"InsuranceAmount": insurance,
+			"Amount":          fmt.Sprintf("%.2f", order.TotalAmount),
+			"Currency":        order.Currency,
+			"PaymentMethod":   order.PaymentMethod,
+			"OrderID":         order.ID.String(),
+			"PaymentDate":     paidAt.Format("January 2, 2006"),
+		},
+		Priority:   models.PriorityNormal,
+		MaxRetries: 3,
+	}
+	emailJob.SetDefaults()
+
+	return s.queueEmailJob(emailJob)
+}
+
+// QueueTicketRefundEmail queues an email telling an attendee their ticket was refunded and no
+// longer admits them
+func (s *EmailQueueService) QueueTicketRefundEmail(ticket *models.Ticket, event *models.Event) error {
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeTicketRefund,
+		To:           ticket.AttendeeEmail,
+		Subject:      fmt.Sprintf("Your ticket for %s has been refunded", event.Title),
+		TemplateFile: "ticket_refund.html",
+		TemplateData: map[string]interface{}{
+			"RecipientName": ticket.AttendeeName,
+			"EventName":     event.Title,
+			"EventDate":     event.StartDate.Format("Monday, January 2, 2006"),
+			"TicketID":      ticket.ID.String(),
+		},
+		Priority:   models.PriorityNormal,
+		MaxRetries: 3,
+		TicketID:   ticket.ID.String(),
+	}
+	emailJob.SetDefaults()
+
+	return s.queueEmailJob(emailJob)
+}
+
+// QueueTicketRevokedEmail queues an email telling an attendee the organizer revoked their ticket
+// and it no longer admits them
+func (s *EmailQueueService) QueueTicketRevokedEmail(ticket *models.Ticket, event *models.Event, reason string) error {
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeTicketRevoked,
+		To:           ticket.AttendeeEmail,
+		Subject:      fmt.Sprintf("Your ticket for %s has been revoked", event.Title),
+		TemplateFile: "ticket_revoked.html",
+		TemplateData: map[string]interface{}{
+			"RecipientName": ticket.AttendeeName,
+			"EventName":     event.Title,
+			"EventDate":     event.StartDate.Format("Monday, January 2, 2006"),
+			"TicketID":      ticket.ID.String(),
+			"Reason":        reason,
+		},
+		Priority:   models.PriorityHigh,
+		MaxRetries: 3,
+		TicketID:   ticket.ID.String(),
+	}
+	emailJob.SetDefaults()
+
+	return s.queueEmailJob(emailJob)
+}
+
+// QueueRefundProcessedEmail queues an email telling the buyer how much of their order was
+// refunded, and which part the organizer returns outside the payment provider
+func (s *EmailQueueService) QueueRefundProcessedEmail(order *models.Order, event *models.Event, refund *models.Refund) error {
+	manual := ""
+	if refund.ManualAmount > 0 {
+		manual = fmt.Sprintf("%.2f", refund.ManualAmount)
+	}
+
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeRefundProcessed,
+		To:           order.BuyerEmail,
+		Subject:      fmt.Sprintf("Your refund for %s", event.Title),
+		TemplateFile: "refund_processed.html",
+		TemplateData: map[string]interface{}{
+			"RecipientName":  order.BuyerName,
+			"EventName":      event.Title,
+			"OrderID":        order.ID.String(),
+			"RefundAmount":   fmt.Sprintf("%.2f", refund.Amount+refund.PremiumAmount),
+			"ManualAmount":   manual,
+			"Currency":       refund.Currency,
+			"RefundMethod":   order.PaymentMethod,
+			"RefundReason":   refund.Reason,
+			"ProcessingTime": "5-10 business days",
+		},
+		Priority:   models.PriorityNormal,
+		MaxRetries: 3,
+	}
+	emailJob.SetDefaults()
+
+	return s.queueEmailJob(emailJob)
+}
+
+// QueueOrganizationInvitationEmail queues an invitation to join an organization. The links carry
+// the invitation token, so the invitee can accept it without an account.
+func (s *EmailQueueService) QueueOrganizationInvitationEmail(invitation *models.OrganizationInvitation, org *models.Organization, inviterName, acceptURL, declineURL string) error {
+	name := strings.TrimSpace(invitation.FirstName + " " + invitation.LastName)
+	if name == "" {
+		name = invitation.Email
+	}
+	perms := "Check in attendees and look up their tickets"
+	if invitation.RoleName == "manager" {
+		perms = "Manage the organization's events, orders and staff"
+	}
+
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeOrganizationInvitation,
+		To:           invitation.Email,
+		Subject:      fmt.Sprintf("You're invited to join %s", org.Name),
+		TemplateFile: "organization_invitation.html",
+		TemplateData: map[string]interface{}{
+			"Name":                    name,
+			"OrganizationName":        org.Name,
+			"OrganizationDescription": org.Description,
+			"InviterName":             inviterName,
+			"RoleName":                invitation.RoleName,
+			"RoleSpecificPerms":       perms,
+			"AcceptURL":               acceptURL,
+			"DeclineURL":              declineURL,
+			"ExpirationDate":          invitation.ExpiresAt.Format("January 2, 2006 at 3:04 PM MST"),
+		},
+		Priority:   models.PriorityHigh,
+		MaxRetries: 3,
+	}
+	emailJob.SetDefaults()
+
+	return s.queueEmailJob(emailJob)
+}
+
 // QueueRegistrationOTP queues a registration OTP email
 func (s *EmailQueueService) QueueRegistrationOTP(to, otp string) error {
 	return s.QueueOTPEmail(to, otp, "registration")
@@ -95,6 +438,11 @@ func (s *EmailQueueService) QueuePasswordResetOTP(to, otp string) error {
 
 // queueEmailJob queues an email job with the appropriate priority
 func (s *EmailQueueService) queueEmailJob(emailJob *models.EmailJob) error {
+	// Oversized attachments would fail every attempt, so they are refused before queueing
+	if err := s.direct.checkAttachments(emailJob.Attachments); err != nil {
+		return err
+	}
+
 	// Serialize the email job
 	payload, err := json.Marshal(emailJob)
 	if err != nil {
@@ -107,7 +455,7 @@ func (s *EmailQueueService) queueEmailJob(emailJob *models.EmailJob) error {
 	// Set task options based on priority
 	opts := []asynq.Option{
 		asynq.MaxRetry(emailJob.MaxRetries),
-		asynq.Queue(emailJob.GetPriorityQueue()),
+		asynq.Queue(redis.QueueName(emailJob.GetPriorityQueue())),
 	}
 
 	// Add process after time if specified
@@ -118,6 +466,14 @@ func (s *EmailQueueService) queueEmailJob(emailJob *models.EmailJob) error {
 	// Enqueue the task
 	info, err := s.client.Enqueue(task, opts...)
 	if err != nil {
+		// Without Redis the email is sent from here rather than lost, though without the queue's
+		// retries. Scheduled emails cannot be held until their time, so they still fail.
+		if emailJob.ProcessAfter.IsZero() {
+			log.Printf("Email queue unavailable, sending directly: ID=%s, Type=%s, To=%s, Error=%v",
+				emailJob.ID, emailJob.Type, emailJob.To, err)
+			go s.sendDirect(emailJob)
+			return nil
+		}
 		return fmt.Errorf("failed to enqueue email task: %w", err)
 	}
 
@@ -127,6 +483,22 @@ func (s *EmailQueueService) queueEmailJob(emailJob *models.EmailJob) error {
 	return nil
 }
 
+// sendDirect sends an email job that could not be queued and records its delivery like the
+// email worker does
+func (s *EmailQueueService) sendDirect(emailJob *models.EmailJob) {
+	err := s.direct.SendJob(emailJob)
+	if emailJob.NotificationID != "" {
+		if notificationID, parseErr := uuid.Parse(emailJob.NotificationID); parseErr == nil {
+			recordNotificationAttempt(database.DB, notificationID, "", err)
+		}
+	}
+	if err != nil {
+		log.Printf("Failed to send email directly: ID=%s, Error=%v", emailJob.ID, err)
+		return
+	}
+	log.Printf("Email sent directly: ID=%s, To=%s", emailJob.ID, emailJob.To)
+}
+
 // Close closes the client connection
 func (s *EmailQueueService) Close() error {
 	return s.client.Close()
@@ -2,22 +2,54 @@ package services
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"log"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net"
 	"net/smtp"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
 )
 
 // EmailService handles email sending functionality
 type EmailService struct {
 	smtpConfig   *config.SMTPConfig
 	templatesDir string
+	breaker      *utils.CircuitBreaker
+	timeout      time.Duration
+	attempts     int
+	retryDelay   time.Duration
+	attachments  *s3Bucket // Bucket of stored attachments; nil when not configured
+	limits       config.EmailAttachmentConfig
+	tracking     config.EmailTrackingConfig
+	publicURL    string // Base URL of the open and click links of tracked emails
 }
 
+var (
+	// ErrEmailAttachmentTooLarge is returned for attachments over the configured size limits
+	ErrEmailAttachmentTooLarge = errors.New("Email attachment is too large")
+	// ErrEmailAttachmentUnavailable is returned when a stored attachment cannot be read
+	ErrEmailAttachmentUnavailable = errors.New("Email attachment is unavailable")
+)
+
 // NewEmailService creates a new email service instance
 func NewEmailService(cfg *config.Config) *EmailService {
 	// Get the current working directory to build absolute path
@@ -29,10 +61,34 @@ func NewEmailService(cfg *config.Config) *EmailService {
 
 	templatesDir := filepath.Join(wd, "internal", "templates", "email")
 
-	return &EmailService{
+	service := &EmailService{
 		smtpConfig:   &cfg.SMTP,
 		templatesDir: templatesDir,
+		breaker: utils.GetCircuitBreaker("smtp", utils.CircuitBreakerSettings{
+			FailureThreshold: cfg.Resilience.BreakerFailures,
+			OpenTimeout:      cfg.Resilience.BreakerOpenTimeout,
+			MaxConcurrent:    cfg.Resilience.SMTPMaxConcurrent,
+			IsFailure:        isSMTPServerFailure,
+		}),
+		timeout:    cfg.Resilience.SMTPTimeout,
+		attempts:   cfg.Resilience.RetryAttempts,
+		retryDelay: cfg.Resilience.RetryBaseDelay,
+		limits:     cfg.EmailAttachment,
+		tracking:   cfg.EmailTracking,
+		publicURL:  strings.TrimRight(cfg.Security.PublicURL, "/"),
+	}
+	if cfg.EmailAttachment.S3Bucket == "" {
+		return service
+	}
+
+	bucket, err := newS3Bucket(cfg.EmailAttachment.S3Endpoint, cfg.EmailAttachment.S3Bucket, cfg.EmailAttachment.S3Region,
+		cfg.EmailAttachment.AccessKeyID, cfg.EmailAttachment.SecretAccessKey)
+	if err != nil {
+		log.Printf("Warning: stored email attachments disabled: %v", err)
+		return service
 	}
+	service.attachments = bucket
+	return service
 }
 
 // EmailData represents the data structure for email templates
@@ -50,8 +106,35 @@ type EmailData struct {
 	Data map[string]interface{}
 }
 
-// SendEmail sends an email using the provided template and data
-func (s *EmailService) SendEmail(to, subject, templateName string, data EmailData) error {
+// emailTemplateFuncs are the functions email templates can call
+var emailTemplateFuncs = template.FuncMap{
+	// safeHTML inserts trusted HTML unescaped, such as newsletter content composed by admins or
+	// event content blocks sanitized when organizers saved them
+	"safeHTML": func(s string) template.HTML { return template.HTML(s) },
+}
+
+// SendEmail sends an email using the provided template and data, with any attachments
+func (s *EmailService) SendEmail(to, subject, templateName string, data EmailData, attachments ...models.EmailAttachment) error {
+	return s.send(to, subject, templateName, data, nil, uuid.Nil, attachments)
+}
+
+// send renders a template and sends it with extra headers and attachments. Emails with the ID of
+// a notification log entry are sent with open and click tracking.
+func (s *EmailService) send(to, subject, templateName string, data EmailData, headers map[string]string, trackedID uuid.UUID, attachments []models.EmailAttachment) error {
+	body, err := s.render(to, subject, templateName, data)
+	if err != nil {
+		return err
+	}
+	if trackedID != uuid.Nil && s.tracking.Enabled {
+		body = s.trackEmail(body, trackedID)
+	}
+
+	// Send email via SMTP
+	return s.sendSMTP(to, subject, body, headers, attachments)
+}
+
+// render fills in the data every email has and executes a template with it
+func (s *EmailService) render(to, subject, templateName string, data EmailData) (string, error) {
 	// Set common data
 	data.To = to
 	data.Subject = subject
@@ -67,11 +150,83 @@ func (s *EmailService) SendEmail(to, subject, templateName string, data EmailDat
 	// Parse and execute template
 	body, err := s.parseTemplate(templateName, data)
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return "", fmt.Errorf("failed to parse template: %w", err)
 	}
+	return body, nil
+}
 
-	// Send email via SMTP
-	return s.sendSMTP(to, subject, body)
+// SendJob sends an email job right away, as the email worker does with jobs taken off the queue
+func (s *EmailService) SendJob(emailJob *models.EmailJob) error {
+	data := emailJobData(emailJob)
+	trackedID := uuid.Nil
+	if emailJob.Tracked {
+		// Emails queued as tracked carry the notification log entry their opens and clicks are recorded on
+		if notificationID, err := uuid.Parse(emailJob.NotificationID); err == nil {
+			trackedID = notificationID
+		}
+	}
+	return s.send(emailJob.To, emailJob.Subject, emailJob.TemplateFile, data, emailJob.Headers, trackedID, emailJob.Attachments)
+}
+
+// RenderJob renders the body of an email job as SendJob would send it, without tracking, for
+// previews
+func (s *EmailService) RenderJob(emailJob *models.EmailJob) (string, error) {
+	return s.render(emailJob.To, emailJob.Subject, emailJob.TemplateFile, emailJobData(emailJob))
+}
+
+// emailJobData returns the template data of an email job
+func emailJobData(emailJob *models.EmailJob) EmailData {
+	return EmailData{
+		Title:         emailJobTitle(emailJob),
+		Message:       emailJobMessage(emailJob),
+		RecipientName: emailJobRecipientName(emailJob),
+		OTP:           emailJobOTP(emailJob),
+		Data:          emailJob.TemplateData,
+	}
+}
+
+// emailJobRecipientName extracts the recipient name from email job data
+func emailJobRecipientName(emailJob *models.EmailJob) string {
+	if name, ok := emailJob.TemplateData["RecipientName"].(string); ok {
+		return name
+	}
+	if name, ok := emailJob.TemplateData["FirstName"].(string); ok {
+		return name
+	}
+	return ""
+}
+
+// emailJobTitle extracts the title from email job data
+func emailJobTitle(emailJob *models.EmailJob) string {
+	if title, ok := emailJob.TemplateData["Title"].(string); ok {
+		return title
+	}
+	// Default to subject if no title specified
+	return emailJob.Subject
+}
+
+// emailJobMessage extracts the message from email job data
+func emailJobMessage(emailJob *models.EmailJob) string {
+	if message, ok := emailJob.TemplateData["Message"].(string); ok {
+		return message
+	}
+	// Provide default message based on email type
+	switch emailJob.Type {
+	case models.EmailTypeOTP:
+		return "Please use the verification code below to proceed."
+	case models.EmailTypeWelcome:
+		return "Welcome! We're excited to have you join our community."
+	default:
+		return "Thank you for using our service."
+	}
+}
+
+// emailJobOTP extracts the OTP from email job data
+func emailJobOTP(emailJob *models.EmailJob) string {
+	if otp, ok := emailJob.TemplateData["OTP"].(string); ok {
+		return otp
+	}
+	return ""
 }
 
 // SendOTPEmail sends an OTP email for verification purposes
@@ -122,13 +277,6 @@ func (s *EmailService) SendWelcomeEmail(to, firstName string) error {
 	return s.SendEmail(to, subject, templateName, data)
 }
 
-// SendWelcomeEmailWithCredentials sends welcome email with login credentials
-func (s *EmailService) SendWelcomeEmailWithCredentials(user interface{}, password, orgName string) error {
-	// This method signature matches the existing call in organization service
-	// You can implement this based on your user model structure
-	return fmt.Errorf("not implemented yet - will be added when needed")
-}
-
 // parseTemplate parses and executes the email template
 func (s *EmailService) parseTemplate(templateName string, data EmailData) (string, error) {
 	templatePath := filepath.Join(s.templatesDir, templateName)
@@ -138,7 +286,7 @@ func (s *EmailService) parseTemplate(templateName string, data EmailData) (strin
 		return "", fmt.Errorf("template file does not exist: %s (templates dir: %s)", templatePath, s.templatesDir)
 	}
 
-	tmpl, err := template.ParseFiles(templatePath)
+	tmpl, err := template.New(templateName).Funcs(emailTemplateFuncs).ParseFiles(templatePath)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse template file %s: %w", templatePath, err)
 	}
@@ -152,24 +300,33 @@ func (s *EmailService) parseTemplate(templateName string, data EmailData) (strin
 }
 
 // sendSMTP sends email via SMTP
-func (s *EmailService) sendSMTP(to, subject, body string) error {
+func (s *EmailService) sendSMTP(to, subject, body string, headers map[string]string, attachments []models.EmailAttachment) error {
 	// Check if SMTP is properly configured
 	if s.smtpConfig.Host == "" || s.smtpConfig.Username == "" || s.smtpConfig.Password == "" {
 		return fmt.Errorf("SMTP configuration incomplete: Host=%s, Username=%s, Password=%s",
 			s.smtpConfig.Host, s.smtpConfig.Username, "***")
 	}
 
-	// Create SMTP authentication
-	auth := smtp.PlainAuth("", s.smtpConfig.Username, s.smtpConfig.Password, s.smtpConfig.Host)
-
-	// Compose email message
-	msg := s.composeMessage(to, subject, body)
+	if err := s.checkAttachments(attachments); err != nil {
+		return err
+	}
 
-	// Send email
+	// Send email, retrying transient failures while the SMTP circuit is closed
 	addr := fmt.Sprintf("%s:%d", s.smtpConfig.Host, s.smtpConfig.Port)
 	fmt.Printf("Attempting to send email via SMTP: %s to %s\n", addr, to)
 
-	err := smtp.SendMail(addr, auth, s.smtpConfig.FromEmail, []string{to}, []byte(msg))
+	err := utils.Retry(context.Background(), s.attempts, s.retryDelay, isSMTPServerFailure, func() error {
+		return s.breaker.Execute(func() error {
+			// Each attempt composes the message anew, streaming stored attachments from the bucket
+			return s.deliver(addr, to, func(w io.Writer) error {
+				return s.writeMessage(w, to, subject, body, headers, attachments)
+			})
+		})
+	})
+	if errors.Is(err, ErrEmailAttachmentTooLarge) || errors.Is(err, ErrEmailAttachmentUnavailable) {
+		fmt.Printf("SMTP Error: %v\n", err)
+		return err
+	}
 	if err != nil {
 		fmt.Printf("SMTP Error: %v\n", err)
 		return fmt.Errorf("failed to send email via SMTP %s: %w", addr, err)
@@ -179,15 +336,210 @@ func (s *EmailService) sendSMTP(to, subject, body string) error {
 	return nil
 }
 
-// composeMessage creates the email message with headers
-func (s *EmailService) composeMessage(to, subject, body string) string {
-	msg := fmt.Sprintf("From: %s\r\n", s.smtpConfig.FromEmail)
-	msg += fmt.Sprintf("To: %s\r\n", to)
-	msg += fmt.Sprintf("Subject: %s\r\n", subject)
-	msg += "MIME-Version: 1.0\r\n"
-	msg += "Content-Type: text/html; charset=UTF-8\r\n"
-	msg += "\r\n"
-	msg += body
+// checkAttachments rejects attachments over the size limits before anything is sent. Stored
+// attachments are checked against their declared size here and their actual size while streaming.
+func (s *EmailService) checkAttachments(attachments []models.EmailAttachment) error {
+	var total int64
+	for _, attachment := range attachments {
+		if attachment.StorageKey != "" && len(attachment.Content) == 0 && s.attachments == nil {
+			return fmt.Errorf("%w: %s: no attachment bucket is configured", ErrEmailAttachmentUnavailable, attachment.StorageKey)
+		}
+		size := attachment.ContentSize()
+		if s.limits.MaxSize > 0 && size > s.limits.MaxSize {
+			return fmt.Errorf("%w: %s is %d bytes, the limit is %d", ErrEmailAttachmentTooLarge, attachment.Filename, size, s.limits.MaxSize)
+		}
+		total += size
+	}
+	if s.limits.MaxTotalSize > 0 && total > s.limits.MaxTotalSize {
+		return fmt.Errorf("%w: attachments total %d bytes, the limit is %d", ErrEmailAttachmentTooLarge, total, s.limits.MaxTotalSize)
+	}
+	return nil
+}
+
+// deliver runs one SMTP conversation under a deadline, so a stalled server cannot hold a worker
+// indefinitely. Like smtp.SendMail it upgrades to TLS and authenticates when the server offers it.
+// The message is written straight into the connection by write.
+func (s *EmailService) deliver(addr, to string, write func(io.Writer) error) error {
+	conn, err := net.DialTimeout("tcp", addr, s.timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.smtpConfig.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.smtpConfig.Host}); err != nil {
+			return err
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); ok {
+		auth := smtp.PlainAuth("", s.smtpConfig.Username, s.smtpConfig.Password, s.smtpConfig.Host)
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.smtpConfig.FromEmail); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if err := write(w); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// isSMTPServerFailure reports whether err means the SMTP server is unreachable or temporarily
+// failing. Permanent rejections (5xx replies such as unknown mailbox) are answers, not failures.
+func isSMTPServerFailure(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// writeMessage writes the email message with headers, followed by any extra headers in name
+// order. Messages with attachments are sent as multipart/mixed with the HTML body as the first
+// part. Stored attachments are streamed from the bucket, so a message is never held in memory whole.
+func (s *EmailService) writeMessage(w io.Writer, to, subject, body string, headers map[string]string, attachments []models.EmailAttachment) error {
+	header := fmt.Sprintf("From: %s\r\n", s.smtpConfig.FromEmail)
+	header += fmt.Sprintf("To: %s\r\n", to)
+	header += fmt.Sprintf("Subject: %s\r\n", subject)
+	for _, name := range slices.Sorted(maps.Keys(headers)) {
+		// Line breaks would let a value add headers of its own
+		value := strings.NewReplacer("\r", "", "\n", "").Replace(headers[name])
+		header += fmt.Sprintf("%s: %s\r\n", textproto.CanonicalMIMEHeaderKey(name), value)
+	}
+	header += "MIME-Version: 1.0\r\n"
+	if len(attachments) == 0 {
+		header += "Content-Type: text/html; charset=UTF-8\r\n"
+		header += "\r\n"
+		_, err := io.WriteString(w, header+body)
+		return err
+	}
+
+	writer := multipart.NewWriter(w)
+	header += fmt.Sprintf("Content-Type: multipart/mixed; boundary=%q\r\n", writer.Boundary())
+	header += "\r\n"
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+
+	part, err := writer.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/html; charset=UTF-8"}})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(part, body); err != nil {
+		return err
+	}
+
+	var total int64
+	for _, attachment := range attachments {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.Filename})},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return err
+		}
+		written, err := s.writeAttachment(part, attachment)
+		if err != nil {
+			return err
+		}
+		total += written
+		if s.limits.MaxTotalSize > 0 && total > s.limits.MaxTotalSize {
+			return fmt.Errorf("%w: attachments total more than %d bytes", ErrEmailAttachmentTooLarge, s.limits.MaxTotalSize)
+		}
+	}
+	return writer.Close()
+}
+
+// writeAttachment writes an attachment base64-encoded in lines of 76 characters and returns its
+// size. Stored attachments are read from the bucket and may not exceed the size limit, whatever
+// size the job declared.
+func (s *EmailService) writeAttachment(w io.Writer, attachment models.EmailAttachment) (int64, error) {
+	var content io.Reader = bytes.NewReader(attachment.Content)
+	if attachment.StorageKey != "" && len(attachment.Content) == 0 {
+		object, _, err := s.attachments.Get(context.Background(), attachment.StorageKey)
+		if err != nil {
+			// Not wrapped, so failures of the bucket never count against the SMTP circuit
+			return 0, fmt.Errorf("%w: %s: %v", ErrEmailAttachmentUnavailable, attachment.StorageKey, err)
+		}
+		defer object.Close()
+		content = object
+		if s.limits.MaxSize > 0 {
+			content = io.LimitReader(object, s.limits.MaxSize+1)
+		}
+	}
+
+	lines := &lineWrapper{w: w, width: 76}
+	encoder := base64.NewEncoder(base64.StdEncoding, lines)
+	written, err := io.Copy(encoder, content)
+	if err != nil {
+		if attachment.StorageKey != "" && len(attachment.Content) == 0 && !errors.Is(err, lines.err) {
+			return 0, fmt.Errorf("%w: %s: %v", ErrEmailAttachmentUnavailable, attachment.StorageKey, err)
+		}
+		return 0, err
+	}
+	if s.limits.MaxSize > 0 && written > s.limits.MaxSize {
+		return 0, fmt.Errorf("%w: %s is more than %d bytes", ErrEmailAttachmentTooLarge, attachment.Filename, s.limits.MaxSize)
+	}
+	if err := encoder.Close(); err != nil {
+		return 0, err
+	}
+	if _, err := io.WriteString(w, "\r\n"); err != nil {
+		return 0, err
+	}
+	return written, nil
+}
+
+// lineWrapper breaks what is written to it into lines of at most width bytes ended by CRLF, as
+// base64 bodies of MIME parts must be
+type lineWrapper struct {
+	w      io.Writer
+	width  int
+	column int
+	err    error // First error of the underlying writer
+}
 
-	return msg
+func (l *lineWrapper) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if l.column == l.width {
+			if _, err := io.WriteString(l.w, "\r\n"); err != nil {
+				l.err = err
+				return written, err
+			}
+			l.column = 0
+		}
+		n := min(len(p), l.width-l.column)
+		if _, err := l.w.Write(p[:n]); err != nil {
+			l.err = err
+			return written, err
+		}
+		l.column += n
+		written += n
+		p = p[n:]
+	}
+	return written, nil
 }
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template/parse"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// AuditEmailTestSent is the audit log action of test emails sent by admins
+const AuditEmailTestSent = "email.test_sent"
+
+// ErrEmailTemplateNotFound is returned for a template name that matches no email template
+var ErrEmailTemplateNotFound = errors.New("Email template not found")
+
+// emailOwnFields are the EmailData fields SendEmail and SendJob always fill in
+var emailOwnFields = []string{"To", "Subject", "Title", "Message", "AppName", "SupportEmail", "CurrentYear", "Data"}
+
+// SendTestEmail renders a template with the supplied data and sends it, so a template can be
+// checked before emails go out with it. Every variable the template requires must be in the data,
+// or filled with placeholders when the request asks for sample data.
+func (s *EmailService) SendTestEmail(ctx context.Context, actorID uuid.UUID, req *models.TestEmailRequest) (*models.TestEmailResponse, error) {
+	variables, err := s.TemplateVariables(req.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{}, len(req.Data))
+	for key, value := range req.Data {
+		data[key] = value
+	}
+
+	var sampled []string
+	problems := make(map[string]interface{})
+	for i, variable := range variables {
+		key := strings.TrimPrefix(variable.Name, "Data.")
+		switch {
+		case !isEmailField(variable.Name):
+			// Templates execute against EmailData, so reading any other field fails every send
+			problems[variable.Name] = fmt.Sprintf("is not a field emails provide, read it as .Data.%s", variable.Name)
+			continue
+		case slices.Contains(emailOwnFields, variable.Name):
+			variables[i].Present = true
+		default:
+			variables[i].Present = templateDataPresent(data, key)
+		}
+
+		if variables[i].Present || !variable.Required {
+			continue
+		}
+		if !req.UseSampleData {
+			problems[variable.Name] = "is required but missing from data"
+			continue
+		}
+		data[key] = "[" + key + "]"
+		sampled = append(sampled, variable.Name)
+	}
+	if len(problems) > 0 {
+		return nil, utils.NewValidationError("Template variables are missing or invalid", problems)
+	}
+
+	subject := req.Subject
+	if subject == "" {
+		subject = "Test: " + req.Template
+	}
+	if err := s.SendJob(&models.EmailJob{
+		To:           req.To,
+		Subject:      subject,
+		TemplateFile: req.Template,
+		TemplateData: data,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := writeAuditLog(database.DB.WithContext(ctx), &actorID, AuditEmailTestSent, "email_template", req.Template, nil, map[string]interface{}{
+		"to":      req.To,
+		"sampled": sampled,
+	}); err != nil {
+		log.Printf("Failed to audit test email: Template=%s, Error=%v", req.Template, err)
+	}
+
+	return &models.TestEmailResponse{
+		Template:  req.Template,
+		To:        req.To,
+		Subject:   subject,
+		Variables: variables,
+		Sampled:   sampled,
+	}, nil
+}
+
+// TemplateVariables returns the variables an email template reads, in the order it first reads
+// them. Variables the template only tests with if or with, or reads inside such a test, are
+// optional.
+func (s *EmailService) TemplateVariables(templateName string) ([]models.EmailTemplateVariable, error) {
+	if templateName != filepath.Base(templateName) || filepath.Ext(templateName) != ".html" {
+		return nil, ErrEmailTemplateNotFound
+	}
+
+	tmpl, err := template.New(templateName).Funcs(emailTemplateFuncs).ParseFiles(filepath.Join(s.templatesDir, templateName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrEmailTemplateNotFound
+		}
+		return nil, fmt.Errorf("failed to parse template file %s: %w", templateName, err)
+	}
+
+	collector := &templateVariableCollector{required: make(map[string]bool)}
+	if tmpl.Tree != nil {
+		collector.walk(tmpl.Tree.Root, false, nil)
+	}
+
+	variables := make([]models.EmailTemplateVariable, 0, len(collector.names))
+	for _, name := range collector.names {
+		variables = append(variables, models.EmailTemplateVariable{Name: name, Required: collector.required[name]})
+	}
+	return variables, nil
+}
+
+// isEmailField reports whether a variable is one templates can read from EmailData
+func isEmailField(name string) bool {
+	return strings.HasPrefix(name, "Data.") || slices.Contains(emailOwnFields, name) || name == "RecipientName" || name == "OTP"
+}
+
+// templateDataPresent reports whether template data provides a variable, the way SendJob reads it
+func templateDataPresent(data map[string]interface{}, key string) bool {
+	job := &models.EmailJob{TemplateData: data}
+	switch key {
+	case "RecipientName":
+		return emailJobRecipientName(job) != ""
+	case "OTP":
+		return emailJobOTP(job) != ""
+	}
+	_, ok := data[key]
+	return ok
+}
+
+// templateVariableCollector records the fields a template reads from its data
+type templateVariableCollector struct {
+	names    []string
+	required map[string]bool
+}
+
+// walk records the fields read under node. Fields read in a condition, or guarded by one, are
+// optional.
+func (c *templateVariableCollector) walk(node parse.Node, condition bool, guarded map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(child, false, guarded)
+		}
+	case *parse.ActionNode:
+		c.walk(n.Pipe, condition, guarded)
+	case *parse.TemplateNode:
+		c.walk(n.Pipe, condition, guarded)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				c.walk(arg, condition, guarded)
+			}
+		}
+	case *parse.FieldNode:
+		c.record(fieldName(n.Ident), condition || guarded[fieldName(n.Ident)])
+	case *parse.IfNode:
+		c.walkBranch(&n.BranchNode, guarded, true)
+	case *parse.WithNode:
+		// The body of with reads fields of the tested value rather than of the email
+		c.walkBranch(&n.BranchNode, guarded, false)
+	case *parse.RangeNode:
+		c.walk(n.Pipe, false, guarded)
+		c.walk(n.ElseList, false, guarded)
+	}
+}
+
+// walkBranch records the fields of an if or with, whose tested fields guard its body
+func (c *templateVariableCollector) walkBranch(branch *parse.BranchNode, guarded map[string]bool, walkBody bool) {
+	c.walk(branch.Pipe, true, guarded)
+	if walkBody {
+		inner := make(map[string]bool, len(guarded))
+		for name := range guarded {
+			inner[name] = true
+		}
+		for _, cmd := range branch.Pipe.Cmds {
+			for _, arg := range cmd.Args {
+				if field, ok := arg.(*parse.FieldNode); ok {
+					inner[fieldName(field.Ident)] = true
+				}
+			}
+		}
+		c.walk(branch.List, false, inner)
+	}
+	c.walk(branch.ElseList, false, guarded)
+}
+
+// record adds a field, which becomes required as soon as one read of it is
+func (c *templateVariableCollector) record(name string, optional bool) {
+	if _, seen := c.required[name]; !seen {
+		c.names = append(c.names, name)
+	}
+	c.required[name] = c.required[name] || !optional
+}
+
+// fieldName names a field by the part the email data provides, e.g. Data.Event for .Data.Event.Title
+func fieldName(ident []string) string {
+	if len(ident) > 2 && ident[0] == "Data" {
+		ident = ident[:2]
+	} else if len(ident) > 1 && ident[0] != "Data" {
+		ident = ident[:1]
+	}
+	return strings.Join(ident, ".")
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// trackedLinkPattern matches the http(s) links of email HTML, the links whose clicks are tracked
+var trackedLinkPattern = regexp.MustCompile(`(?i)\bhref\s*=\s*(?:"(https?://[^"]*)"|'(https?://[^']*)')`)
+
+// tracks reports whether emails of a type are sent with open and click tracking
+func (s *EmailService) tracks(emailType models.EmailJobType) bool {
+	return s.tracking.Enabled && emailType.Trackable()
+}
+
+// trackEmail points the links of a tracked email to the click tracker, which redirects only to
+// links signed for the email, and adds the pixel recording its opens
+func (s *EmailService) trackEmail(body string, notificationID uuid.UUID) string {
+	trackingURL := s.publicURL + "/api/v1/emails/t/" + utils.SignEmailTrackingToken(s.tracking.Secret, notificationID)
+
+	body = trackedLinkPattern.ReplaceAllStringFunc(body, func(match string) string {
+		link := trackedLinkURL(trackedLinkPattern.FindStringSubmatch(match))
+		query := url.Values{}
+		query.Set("url", link)
+		query.Set("sig", utils.SignEmailLink(s.tracking.Secret, notificationID, link))
+		return `href="` + html.EscapeString(trackingURL+"/click?"+query.Encode()) + `"`
+	})
+
+	pixel := `<img src="` + html.EscapeString(trackingURL+"/open.gif") + `" width="1" height="1" alt="" style="display: block; border: 0;">`
+	if end := strings.LastIndex(strings.ToLower(body), "</body>"); end >= 0 {
+		return body[:end] + pixel + body[end:]
+	}
+	return body + pixel
+}
+
+// trackedLinkURL returns the URL of a link matched by trackedLinkPattern
+func trackedLinkURL(match []string) string {
+	link := match[1]
+	if link == "" {
+		link = match[2]
+	}
+	return html.UnescapeString(link)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskEncryptionRotate is the asynq task type for re-encrypting personal data under the active key
+const TaskEncryptionRotate = "encryption:rotate"
+
+// encryptionRotateBatchSize is the number of users re-encrypted per query
+const encryptionRotateBatchSize = 500
+
+// encryptedUserColumns are the user columns stored with the encrypted serializer
+var encryptedUserColumns = []string{"phone", "date_of_birth", "whats_app_phone"}
+
+// EncryptionService re-encrypts personal data after an encryption key rotation
+type EncryptionService struct {
+	db     *gorm.DB
+	client *asynq.Client
+}
+
+// NewEncryptionService creates a new encryption service
+func NewEncryptionService(cfg *config.Config) *EncryptionService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	return &EncryptionService{
+		db:     database.DB,
+		client: asynq.NewClient(redisOpts),
+	}
+}
+
+// QueueKeyRotation queues re-encryption of all personal data under the active key
+func (s *EncryptionService) QueueKeyRotation(actorID uuid.UUID) error {
+	if database.Encryptor == nil {
+		return errors.New("Field encryption is not configured")
+	}
+
+	// The task ID keeps a single rotation queued or running at a time
+	task := asynq.NewTask(TaskEncryptionRotate, nil)
+	if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.MaxRetry(3), asynq.TaskID(TaskEncryptionRotate)); err != nil {
+		if errors.Is(err, asynq.ErrTaskIDConflict) {
+			return errors.New("A key rotation is already in progress")
+		}
+		return err
+	}
+
+	recordAuditLog(s.db, &actorID, "encryption.rotate_requested", "encryption_key", database.Encryptor.ActiveKeyID(), nil, nil)
+	return nil
+}
+
+// RotateKeys re-encrypts user columns that are stored in plain text or under a retired key.
+// Rows are read and written as raw column values so the serializer does not interfere, and the
+// job is idempotent: an interrupted run is resumed by running it again.
+func (s *EncryptionService) RotateKeys(ctx context.Context) (int, error) {
+	encryptor := database.Encryptor
+	if encryptor == nil {
+		return 0, errors.New("field encryption is not configured")
+	}
+
+	type encryptedRow struct {
+		ID            uuid.UUID
+		Phone         string
+		DateOfBirth   string
+		WhatsAppPhone string
+	}
+
+	rotated := 0
+	lastID := uuid.Nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return rotated, err
+		}
+
+		var rows []encryptedRow
+		if err := s.db.Table("users").
+			Select("id, COALESCE(phone, '') AS phone, COALESCE(date_of_birth, '') AS date_of_birth, COALESCE(whats_app_phone, '') AS whats_app_phone").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(encryptionRotateBatchSize).
+			Scan(&rows).Error; err != nil {
+			return rotated, fmt.Errorf("failed to load users: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		for _, row := range rows {
+			updates := map[string]interface{}{}
+			for column, stored := range map[string]string{"phone": row.Phone, "date_of_birth": row.DateOfBirth, "whats_app_phone": row.WhatsAppPhone} {
+				if !encryptor.NeedsRotation(stored) {
+					continue
+				}
+				plaintext, err := encryptor.Decrypt(stored)
+				if err != nil {
+					return rotated, fmt.Errorf("failed to decrypt %s of user %s: %w", column, row.ID, err)
+				}
+				ciphertext, err := encryptor.Encrypt(plaintext)
+				if err != nil {
+					return rotated, err
+				}
+				updates[column] = ciphertext
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			if err := s.db.Table("users").Where("id = ?", row.ID).UpdateColumns(updates).Error; err != nil {
+				return rotated, fmt.Errorf("failed to re-encrypt user %s: %w", row.ID, err)
+			}
+			rotated++
+		}
+
+		lastID = rows[len(rows)-1].ID
+	}
+
+	log.Printf("Encryption key rotation complete: Key=%s, UsersReencrypted=%d", encryptor.ActiveKeyID(), rotated)
+	recordAuditLog(s.db, nil, "encryption.rotated", "encryption_key", encryptor.ActiveKeyID(), nil, map[string]interface{}{
+		"users_reencrypted": rotated,
+		"columns":           encryptedUserColumns,
+	})
+	return rotated, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEventVersionNotFound is returned when rolling back to a version the event does not have
+var ErrEventVersionNotFound = errors.New("Event version not found")
+
+// GetEventHistory returns the recorded versions of an event, newest first, each with the fields
+// that changed from the version before it
+func (s *EventService) GetEventHistory(ctx context.Context, id uint, userID uuid.UUID, roles []string) ([]models.EventVersion, error) {
+	db := database.DB.WithContext(ctx)
+
+	var event models.Event
+	if err := db.Select("id", "organizer_id", "organization_id").First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	if err := authorizeEventOwner(db, &event, userID, roles); err != nil {
+		return nil, err
+	}
+
+	versions := []models.EventVersion{}
+	if err := db.Where("event_id = ?", id).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// RollbackEvent restores the details an event had at an earlier version and records the result
+// as a new version. The status is kept, so a rollback neither unpublishes nor cancels an event,
+// and capacity changes keep the tickets already sold.
+func (s *EventService) RollbackEvent(ctx context.Context, id uint, version int, userID uuid.UUID, roles []string) (*models.Event, error) {
+	var event models.Event
+	var previousAvailable int
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, id).Error; err != nil {
+			return err
+		}
+		if err := authorizeEventOwner(tx, &event, userID, roles); err != nil {
+			return err
+		}
+		if event.Status == models.EventStatusCancelled || event.Status == models.EventStatusCompleted {
+			return errors.New("Cancelled and completed events cannot be rolled back")
+		}
+
+		var target models.EventVersion
+		if err := tx.Where("event_id = ? AND version = ?", id, version).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrEventVersionNotFound
+			}
+			return err
+		}
+
+		before := models.SnapshotOf(&event)
+		snapshot := target.Snapshot
+		event.Title = snapshot.Title
+		event.Description = snapshot.Description
+		event.Location = snapshot.Location
+		event.StartDate = snapshot.StartDate
+		event.EndDate = snapshot.EndDate
+		event.Price = snapshot.Price
+		event.CoverURL = snapshot.CoverURL
+		event.Performer = snapshot.Performer
+		event.Category = snapshot.Category
+		event.RefundPolicy = snapshot.RefundPolicy
+
+		previousAvailable = event.Available
+		if snapshot.Capacity > 0 && snapshot.Capacity != event.Capacity {
+			if err := s.inventoryService.Resize(ctx, tx, &event, snapshot.Capacity); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("available", "capacity").Save(&event).Error; err != nil {
+			return err
+		}
+
+		return recordEventVersion(tx, &before, &event, &userID, models.EventVersionRolledBack, &version)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable})
+	EventChanged(&event)
+	return &event, nil
+}
+
+// recordEventVersion stores the event's current details as its next version, with the changes
+// from the latest version. Callers pass the details before the change, or nil for a new event,
+// and hold a lock on the event row so versions are numbered in order. Changes that leave every
+// detail as it was are not recorded. An event without history first gets its prior state
+// recorded as a baseline, so its first change can be diffed and rolled back.
+func recordEventVersion(tx *gorm.DB, before *models.EventSnapshot, event *models.Event, userID *uuid.UUID, action models.EventVersionAction, restored *int) error {
+	var latest models.EventVersion
+	if err := tx.Where("event_id = ?", event.ID).Order("version DESC").Limit(1).Find(&latest).Error; err != nil {
+		return fmt.Errorf("failed to load event history: %w", err)
+	}
+
+	if latest.Version == 0 && before != nil {
+		latest = models.EventVersion{
+			EventID:  event.ID,
+			Version:  1,
+			Action:   models.EventVersionBaseline,
+			Snapshot: *before,
+			Changes:  []models.EventFieldChange{},
+		}
+		if err := tx.Create(&latest).Error; err != nil {
+			return fmt.Errorf("failed to record event baseline: %w", err)
+		}
+	}
+
+	snapshot := models.SnapshotOf(event)
+	changes := []models.EventFieldChange{}
+	if latest.Version > 0 {
+		var err error
+		if changes, err = diffEventSnapshots(&latest.Snapshot, &snapshot); err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+	}
+
+	version := &models.EventVersion{
+		EventID:         event.ID,
+		Version:         latest.Version + 1,
+		Action:          action,
+		ChangedBy:       userID,
+		RestoredVersion: restored,
+		Snapshot:        snapshot,
+		Changes:         changes,
+	}
+	if err := tx.Create(version).Error; err != nil {
+		return fmt.Errorf("failed to record event version: %w", err)
+	}
+	return nil
+}
+
+// diffEventSnapshots lists the fields that differ between two snapshots, in field order. Values
+// are compared as JSON, the form they are stored and shown in.
+func diffEventSnapshots(from, to *models.EventSnapshot) ([]models.EventFieldChange, error) {
+	changes := []models.EventFieldChange{}
+
+	fromValue, toValue := reflect.ValueOf(*from), reflect.ValueOf(*to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		oldJSON, err := json.Marshal(fromValue.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		newJSON, err := json.Marshal(toValue.Field(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		if string(oldJSON) == string(newJSON) {
+			continue
+		}
+
+		field, _, _ := strings.Cut(fromValue.Type().Field(i).Tag.Get("json"), ",")
+		changes = append(changes, models.EventFieldChange{
+			Field: field,
+			From:  fromValue.Field(i).Interface(),
+			To:    toValue.Field(i).Interface(),
+		})
+	}
+	return changes, nil
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"sync"
+
+	"event-ticketing-backend/internal/models"
+)
+
+// EventHook is called after an event is published, updated or deleted
+type EventHook func(event *models.Event)
+
+var (
+	eventHooksMu sync.RWMutex
+	eventHooks   []EventHook
+)
+
+// RegisterEventHook adds a hook that runs on every event change
+func RegisterEventHook(hook EventHook) {
+	eventHooksMu.Lock()
+	defer eventHooksMu.Unlock()
+	eventHooks = append(eventHooks, hook)
+}
+
+// EventChanged runs the registered event hooks. Callers invoke it once the change is committed.
+func EventChanged(event *models.Event) {
+	eventHooksMu.RLock()
+	hooks := make([]EventHook, len(eventHooks))
+	copy(hooks, eventHooks)
+	eventHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(event)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrEventTransition is returned when an event cannot move from its status to the requested one
+var ErrEventTransition = errors.New("Event status cannot change")
+
+// PauseEvent halts the ticket sales of a published event, which stays listed until it is
+// published again
+func (s *EventService) PauseEvent(ctx context.Context, id uint, userID uuid.UUID, roles []string) (*models.Event, error) {
+	return s.transition(ctx, id, userID, roles, models.EventStatusPaused)
+}
+
+// CancelEvent cancels a draft, published or paused event. Tickets already sold are left to be
+// refunded.
+func (s *EventService) CancelEvent(ctx context.Context, id uint, userID uuid.UUID, roles []string) (*models.Event, error) {
+	return s.transition(ctx, id, userID, roles, models.EventStatusCancelled)
+}
+
+// CompleteEvent closes a published or paused event once it has started
+func (s *EventService) CompleteEvent(ctx context.Context, id uint, userID uuid.UUID, roles []string) (*models.Event, error) {
+	return s.transition(ctx, id, userID, roles, models.EventStatusCompleted)
+}
+
+// transition moves an event the user may manage, as UpdateEvent checks, to another status and
+// records the change in its history. Publishing goes through PublishDraft instead, which
+// validates the event first.
+func (s *EventService) transition(ctx context.Context, id uint, userID uuid.UUID, roles []string, status models.EventStatus) (*models.Event, error) {
+	var event models.Event
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, id).Error; err != nil {
+			return err
+		}
+		if err := authorizeEventOwner(tx, &event, userID, roles); err != nil {
+			return err
+		}
+		if !event.Status.CanTransitionTo(status) {
+			return fmt.Errorf("%w from %s to %s", ErrEventTransition, event.Status, status)
+		}
+		if status == models.EventStatusCompleted && event.StartDate.After(time.Now()) {
+			return fmt.Errorf("%w to %s before the event starts", ErrEventTransition, status)
+		}
+
+		before := models.SnapshotOf(&event)
+		event.Status = status
+		if err := tx.Model(&event).Update("status", status).Error; err != nil {
+			return err
+		}
+		return recordEventVersion(tx, &before, &event, &userID, models.EventVersionStatus, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	EventChanged(&event)
+	return &event, nil
+}
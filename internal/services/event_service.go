@@ -1,88 +1,483 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"event-ticketing-backend/internal/database"
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/validators"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-type EventService struct{}
+type EventService struct {
+	inventoryService *InventoryService
+}
 
 func NewEventService() *EventService {
-	return &EventService{}
+	return &EventService{
+		inventoryService: NewInventoryService(),
+	}
 }
 
-func (s *EventService) CreateEvent(req *models.EventCreateRequest) (*models.Event, error) {
+// CreateEvent creates an event run by the requested organization, which the organizer must
+// manage, or else by the first organization they manage
+func (s *EventService) CreateEvent(ctx context.Context, organizerID uuid.UUID, roles []string, req *models.EventCreateRequest) (*models.Event, error) {
+	orgID, err := eventOrganization(database.DB.WithContext(ctx), organizerID, roles, req.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+
 	event := &models.Event{
-		Title:       req.Title,
-		Description: req.Description,
-		Location:    req.Location,
-		StartDate:   req.StartDate,
-		EndDate:     req.EndDate,
-		Price:       req.Price,
-		Capacity:    req.Capacity,
+		Title:          req.Title,
+		Description:    req.Description,
+		Location:       req.Location,
+		StartDate:      req.StartDate,
+		EndDate:        req.EndDate,
+		Price:          req.Price,
+		Capacity:       req.Capacity,
+		CoverURL:       req.CoverURL,
+		Performer:      req.Performer,
+		Category:       strings.ToLower(req.Category),
+		OrganizerID:    &organizerID,
+		OrganizationID: orgID,
+	}
+	if req.RefundPolicy != nil {
+		event.RefundPolicy = *req.RefundPolicy
 	}
 
-	if err := database.DB.Create(event).Error; err != nil {
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return recordEventVersion(tx, nil, event, &organizerID, models.EventVersionCreated, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	EventChanged(event)
 	return event, nil
 }
 
-func (s *EventService) GetAllEvents() ([]models.Event, error) {
+// GetAllEvents returns the requested page of published events matching the filter, soonest first
+// unless the filter sorts them otherwise
+func (s *EventService) GetAllEvents(ctx context.Context, filter *models.EventFilterQuery) ([]models.Event, *models.PageMeta, error) {
+	query := database.DB.WithContext(ctx).Where("status <> ?", models.EventStatusDraft)
+	if filter.Search != "" {
+		pattern := containsPattern(filter.Search)
+		query = query.Where("title ILIKE ? OR performer ILIKE ? OR location ILIKE ?", pattern, pattern, pattern)
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", strings.ToLower(filter.Category))
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Location != "" {
+		query = query.Where("location ILIKE ?", containsPattern(filter.Location))
+	}
+	if filter.OrganizationID != "" {
+		query = query.Where("organization_id = ?", filter.OrganizationID)
+	}
+	if filter.StartsFrom != "" {
+		from, _ := time.Parse(time.DateOnly, filter.StartsFrom)
+		query = query.Where("start_date >= ?", from)
+	}
+	if filter.StartsTo != "" {
+		to, _ := time.Parse(time.DateOnly, filter.StartsTo)
+		query = query.Where("start_date < ?", to.AddDate(0, 0, 1))
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+
+	// Ties are broken by ID so pages neither skip nor repeat events
+	order := "start_date ASC"
+	if column, descending := strings.CutPrefix(filter.Sort, "-"); descending {
+		order = column + " DESC"
+	} else if column != "" {
+		order = column + " ASC"
+	}
+	query = query.Order(order).Order("id ASC")
+
+	events := []models.Event{}
+	meta, err := database.Paginate(query, filter.PageQuery, &events)
+	if err != nil {
+		return nil, nil, err
+	}
+	return events, meta, nil
+}
+
+// containsPattern returns an ILIKE pattern matching text anywhere, with its wildcards escaped
+func containsPattern(text string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(text) + "%"
+}
+
+func (s *EventService) GetEventByID(ctx context.Context, id uint) (*models.Event, error) {
+	var event models.Event
+	if err := database.DB.WithContext(ctx).Where("status <> ?", models.EventStatusDraft).First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ListOrganizationEvents returns the events an organization runs, including unpublished ones,
+// soonest first, optionally limited to one status
+func (s *EventService) ListOrganizationEvents(ctx context.Context, orgID uuid.UUID, status string) ([]models.Event, error) {
+	query := database.DB.WithContext(ctx).Where("organization_id = ?", orgID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
 	var events []models.Event
-	if err := database.DB.Find(&events).Error; err != nil {
+	if err := query.Order("start_date ASC").Find(&events).Error; err != nil {
 		return nil, err
 	}
 	return events, nil
 }
 
-func (s *EventService) GetEventByID(id uint) (*models.Event, error) {
+// UpdateEvent applies the fields set in the request and records the result in the event's history.
+// Only admins, the organizer who created the event and organizers of its organization may update it.
+func (s *EventService) UpdateEvent(ctx context.Context, id uint, userID uuid.UUID, roles []string, req *models.EventUpdateRequest) (*models.Event, error) {
 	var event models.Event
-	if err := database.DB.First(&event, id).Error; err != nil {
+	var previousAvailable int
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, id).Error; err != nil {
+			return err
+		}
+		if err := authorizeEventOwner(tx, &event, userID, roles); err != nil {
+			return err
+		}
+		before := models.SnapshotOf(&event)
+
+		if req.Title != "" {
+			event.Title = req.Title
+		}
+		if req.Description != "" {
+			event.Description = req.Description
+		}
+		if req.Location != "" {
+			event.Location = req.Location
+		}
+		if !req.StartDate.IsZero() {
+			event.StartDate = req.StartDate
+		}
+		if !req.EndDate.IsZero() {
+			event.EndDate = req.EndDate
+		}
+		if req.Price > 0 {
+			event.Price = req.Price
+		}
+		if req.CoverURL != "" {
+			event.CoverURL = req.CoverURL
+		}
+		if req.Performer != "" {
+			event.Performer = req.Performer
+		}
+		if req.Category != "" {
+			event.Category = strings.ToLower(req.Category)
+		}
+		if req.RefundPolicy != nil {
+			event.RefundPolicy = *req.RefundPolicy
+		}
+
+		previousAvailable = event.Available
+		if req.Capacity > 0 && req.Capacity != event.Capacity {
+			if event.VenueID != nil {
+				return ErrSeatedCapacity
+			}
+			// Keep tickets already sold when the capacity changes
+			if err := s.inventoryService.Resize(ctx, tx, &event, req.Capacity); err != nil {
+				return err
+			}
+		}
+
+		// The counts are left to the inventory service so sales made meanwhile are not overwritten
+		if err := tx.Omit("available", "capacity").Save(&event).Error; err != nil {
+			return err
+		}
+		return recordEventVersion(tx, &before, &event, &userID, models.EventVersionUpdated, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
+
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable})
+	EventChanged(&event)
+
 	return &event, nil
 }
 
-func (s *EventService) UpdateEvent(id uint, req *models.EventUpdateRequest) (*models.Event, error) {
+// DeleteEvent deletes an event the user may manage, as UpdateEvent checks
+func (s *EventService) DeleteEvent(ctx context.Context, id uint, userID uuid.UUID, roles []string) error {
+	db := database.DB.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, id).Error; err != nil {
+		return err
+	}
+	if err := authorizeEventOwner(db, &event, userID, roles); err != nil {
+		return err
+	}
+	if err := db.Delete(&event).Error; err != nil {
+		return err
+	}
+
+	EventChanged(&event)
+	return nil
+}
+
+// CreateDraft creates an empty, unpublished event for the organizer UI to autosave into, run by the
+// first organization the organizer manages
+func (s *EventService) CreateDraft(ctx context.Context, organizerID uuid.UUID) (*models.Event, error) {
+	orgID, err := eventOrganization(database.DB.WithContext(ctx), organizerID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.Event{
+		Title:          "Untitled event",
+		Status:         models.EventStatusDraft,
+		OrganizerID:    &organizerID,
+		OrganizationID: orgID,
+	}
+	err = database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return err
+		}
+		return recordEventVersion(tx, nil, event, &organizerID, models.EventVersionCreated, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// GetDraft returns an event, including unpublished ones, with its autosaved changes
+func (s *EventService) GetDraft(ctx context.Context, id uint, userID uuid.UUID, roles []string) (*models.EventDraftResponse, error) {
+	db := database.DB.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	if err := authorizeEventOwner(db, &event, userID, roles); err != nil {
+		return nil, err
+	}
+	return eventDraftResponse(&event), nil
+}
+
+// SaveDraft merges a partial payload into an event's draft without validating it. Fields set to
+// null are removed from the draft; fields events don't have are ignored.
+func (s *EventService) SaveDraft(ctx context.Context, id uint, userID uuid.UUID, roles []string, changes map[string]interface{}) (*models.EventDraftResponse, error) {
+	db := database.DB.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	if err := authorizeEventOwner(db, &event, userID, roles); err != nil {
+		return nil, err
+	}
+
+	if event.Draft == nil {
+		event.Draft = make(map[string]interface{})
+	}
+	for _, field := range models.EventDraftFields {
+		value, ok := changes[field]
+		if !ok {
+			continue
+		}
+		if value == nil {
+			delete(event.Draft, field)
+		} else {
+			event.Draft[field] = value
+		}
+	}
+	now := time.Now()
+	event.DraftSavedAt = &now
+
+	if err := db.Model(&event).Select("draft", "draft_saved_at").Updates(&event).Error; err != nil {
+		return nil, fmt.Errorf("failed to save draft: %w", err)
+	}
+	return eventDraftResponse(&event), nil
+}
+
+// PublishDraft validates an event, with its draft applied over its current details, as a complete
+// event and applies it. Drafts and paused events are published and go on sale; published events
+// take the drafted changes over. Only users who may update the event may publish it.
+func (s *EventService) PublishDraft(ctx context.Context, id uint, userID uuid.UUID, roles []string) (*models.Event, error) {
+	db := database.DB.WithContext(ctx)
+
 	var event models.Event
-	if err := database.DB.First(&event, id).Error; err != nil {
+	if err := db.First(&event, id).Error; err != nil {
 		return nil, err
 	}
+	if err := authorizeEventOwner(db, &event, userID, roles); err != nil {
+		return nil, err
+	}
+	if event.Status != models.EventStatusPublished && !event.Status.CanTransitionTo(models.EventStatusPublished) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrEventTransition, event.Status, models.EventStatusPublished)
+	}
 
-	if req.Title != "" {
-		event.Title = req.Title
+	// Drafts started empty by CreateDraft are built from the autosaved changes alone; other events
+	// start from their current details
+	fields := make(map[string]interface{})
+	if event.Status != models.EventStatusDraft || !event.StartDate.IsZero() {
+		current, err := json.Marshal(models.EventCreateRequest{
+			Title:        event.Title,
+			Description:  event.Description,
+			Location:     event.Location,
+			StartDate:    event.StartDate,
+			EndDate:      event.EndDate,
+			Price:        event.Price,
+			Capacity:     event.Capacity,
+			CoverURL:     event.CoverURL,
+			Performer:    event.Performer,
+			Category:     event.Category,
+			RefundPolicy: &event.RefundPolicy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(current, &fields); err != nil {
+			return nil, err
+		}
 	}
-	if req.Description != "" {
-		event.Description = req.Description
+	for field, value := range event.Draft {
+		fields[field] = value
 	}
-	if req.Location != "" {
-		event.Location = req.Location
+
+	payload, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var req models.EventCreateRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("Draft field %s has an invalid value", typeErr.Field)
+		}
+		return nil, fmt.Errorf("Draft is invalid: %w", err)
 	}
-	if !req.StartDate.IsZero() {
-		event.StartDate = req.StartDate
+	if err := validators.ValidateStruct(&req); err != nil {
+		return nil, err
 	}
-	if !req.EndDate.IsZero() {
-		event.EndDate = req.EndDate
+	if !req.EndDate.After(req.StartDate) {
+		return nil, errors.New("End date must be after start date")
 	}
-	if req.Price > 0 {
-		event.Price = req.Price
+	if event.Status == models.EventStatusDraft && !req.StartDate.After(time.Now()) {
+		return nil, errors.New("Start date must be in the future")
 	}
-	if req.Capacity > 0 {
-		event.Capacity = req.Capacity
+
+	before := models.SnapshotOf(&event)
+	previousAvailable := event.Available
+	event.Title = req.Title
+	event.Description = req.Description
+	event.Location = req.Location
+	event.StartDate = req.StartDate
+	event.EndDate = req.EndDate
+	event.Price = req.Price
+	event.CoverURL = req.CoverURL
+	event.Performer = req.Performer
+	event.Category = strings.ToLower(req.Category)
+	if req.RefundPolicy != nil {
+		event.RefundPolicy = *req.RefundPolicy
 	}
-	if req.Status != "" {
-		event.Status = req.Status
+	event.Draft = nil
+	event.DraftSavedAt = nil
+
+	if event.VenueID != nil && req.Capacity != event.Capacity {
+		return nil, ErrSeatedCapacity
 	}
 
-	if err := database.DB.Save(&event).Error; err != nil {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		event.Status = models.EventStatusPublished
+		if req.Capacity != event.Capacity {
+			// Keep tickets already sold, allocated or comped when the capacity changes
+			if err := s.inventoryService.Resize(ctx, tx, &event, req.Capacity); err != nil {
+				return err
+			}
+		}
+		if err := tx.Omit("available", "capacity").Save(&event).Error; err != nil {
+			return err
+		}
+		return recordEventVersion(tx, &before, &event, &userID, models.EventVersionPublished, nil)
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable})
+	EventChanged(&event)
 	return &event, nil
 }
 
-func (s *EventService) DeleteEvent(id uint) error {
-	return database.DB.Delete(&models.Event{}, id).Error
+func eventDraftResponse(event *models.Event) *models.EventDraftResponse {
+	draft := event.Draft
+	if draft == nil {
+		draft = map[string]interface{}{}
+	}
+	return &models.EventDraftResponse{Event: event, Draft: draft, SavedAt: event.DraftSavedAt}
+}
+
+// eventOrganization returns the organization a new event of the organizer is run by. A requested
+// organization must be one they manage, unless they are an admin; without one, the first
+// organization they manage is used, or none when they manage none.
+func eventOrganization(db *gorm.DB, organizerID uuid.UUID, roles []string, requested *uuid.UUID) (*uuid.UUID, error) {
+	if requested != nil {
+		if slices.Contains(roles, "admin") {
+			return requested, nil
+		}
+		manages, err := managesOrganization(db, *requested, organizerID)
+		if err != nil {
+			return nil, err
+		}
+		if !manages {
+			return nil, ErrEventAccessDenied
+		}
+		return requested, nil
+	}
+
+	var orgs []models.Organization
+	if err := db.Where(managedOrganizationsCondition(db, organizerID)).Order("created_at").Limit(1).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	if len(orgs) == 0 {
+		return nil, nil
+	}
+	return &orgs[0].ID, nil
+}
+
+// authorizeEventOwner fails with ErrEventAccessDenied unless the user may manage the event: admins
+// any event, organizers the events they created and those of organizations they manage
+func authorizeEventOwner(db *gorm.DB, event *models.Event, userID uuid.UUID, roles []string) error {
+	if slices.Contains(roles, "admin") {
+		return nil
+	}
+	if event.OrganizerID != nil && *event.OrganizerID == userID {
+		return nil
+	}
+	if event.OrganizationID != nil {
+		manages, err := managesOrganization(db, *event.OrganizationID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to check event access: %w", err)
+		}
+		if manages {
+			return nil
+		}
+	}
+	return ErrEventAccessDenied
 }
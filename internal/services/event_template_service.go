@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrEventTemplateNotFound is returned when a template does not exist for the organization
+var ErrEventTemplateNotFound = errors.New("Event template not found")
+
+// EventTemplateService lets organizations save events they run repeatedly as templates and create
+// new events from them
+type EventTemplateService struct {
+	db *gorm.DB
+}
+
+// NewEventTemplateService creates a new event template service
+func NewEventTemplateService() *EventTemplateService {
+	return &EventTemplateService{
+		db: database.DB,
+	}
+}
+
+// Save stores an event, with the organization's price tiers and hold/comp blocks for it, as a template
+func (s *EventTemplateService) Save(ctx context.Context, orgID, userID uuid.UUID, req *models.SaveEventTemplateRequest) (*models.EventTemplate, error) {
+	db := s.db.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, req.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+
+	var rules []models.PricingRule
+	if err := db.Where("organization_id = ? AND event_id = ?", orgID, event.ID).Order("created_at ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	tiers := make([]models.TemplatePricingRule, len(rules))
+	for i, rule := range rules {
+		tiers[i] = models.TemplatePricingRule{Name: rule.Name, Trigger: rule.Trigger, SoldThreshold: rule.SoldThreshold, Price: rule.Price}
+		if rule.StartsAt != nil {
+			tiers[i].StartsBeforeHours = int(event.StartDate.Sub(*rule.StartsAt).Hours())
+		}
+	}
+
+	var allocations []models.TicketAllocation
+	err := db.Where("organization_id = ? AND event_id = ? AND released_at IS NULL", orgID, event.ID).
+		Order("created_at ASC").Find(&allocations).Error
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]models.FranchiseAllocation, len(allocations))
+	for i, allocation := range allocations {
+		blocks[i] = models.FranchiseAllocation{Type: allocation.Type, Label: allocation.Label, Quantity: allocation.Quantity}
+	}
+
+	template := models.EventTemplate{
+		OrganizationID:  orgID,
+		Name:            req.Name,
+		SourceEventID:   &event.ID,
+		CreatedBy:       &userID,
+		Title:           event.Title,
+		Description:     event.Description,
+		Location:        event.Location,
+		DurationMinutes: int(event.EndDate.Sub(event.StartDate).Minutes()),
+		Price:           event.Price,
+		Capacity:        event.Capacity,
+		WaitlistOpen:    event.WaitlistOpen,
+		CoverURL:        event.CoverURL,
+		Performer:       event.Performer,
+		Category:        event.Category,
+		RefundPolicy:    event.RefundPolicy,
+		PricingRules:    tiers,
+		Allocations:     blocks,
+	}
+	if err := db.Create(&template).Error; err != nil {
+		return nil, fmt.Errorf("failed to save event template: %w", err)
+	}
+	return &template, nil
+}
+
+// List returns an organization's templates, newest first
+func (s *EventTemplateService) List(ctx context.Context, orgID uuid.UUID) ([]models.EventTemplate, error) {
+	var templates []models.EventTemplate
+	if err := s.db.WithContext(ctx).Where("organization_id = ?", orgID).Order("created_at DESC").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// Delete removes a template; events created from it are not affected
+func (s *EventTemplateService) Delete(ctx context.Context, orgID, templateID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", templateID, orgID).Delete(&models.EventTemplate{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEventTemplateNotFound
+	}
+	return nil
+}
+
+// Instantiate creates an event from a template starting at the requested date, recreating the
+// template's price tiers and hold/comp blocks for it
+func (s *EventTemplateService) Instantiate(ctx context.Context, orgID, templateID, userID uuid.UUID, req *models.InstantiateEventTemplateRequest) (*models.Event, error) {
+	if !req.StartDate.After(time.Now()) {
+		return nil, errors.New("Start date must be in the future")
+	}
+
+	var event models.Event
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var template models.EventTemplate
+		if err := tx.Where("id = ? AND organization_id = ?", templateID, orgID).First(&template).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrEventTemplateNotFound
+			}
+			return err
+		}
+
+		reserved := 0
+		for _, block := range template.Allocations {
+			reserved += block.Quantity
+		}
+		if reserved > template.Capacity {
+			return fmt.Errorf("Allocations of %d tickets exceed the capacity of %d", reserved, template.Capacity)
+		}
+
+		startDate := req.StartDate.UTC()
+		event = models.Event{
+			Title:          template.Title,
+			Description:    template.Description,
+			Location:       template.Location,
+			StartDate:      startDate,
+			EndDate:        startDate.Add(time.Duration(template.DurationMinutes) * time.Minute),
+			Price:          template.Price,
+			Capacity:       template.Capacity,
+			WaitlistOpen:   template.WaitlistOpen,
+			CoverURL:       template.CoverURL,
+			Performer:      template.Performer,
+			Category:       template.Category,
+			OrganizerID:    &userID,
+			OrganizationID: &orgID,
+			RefundPolicy:   template.RefundPolicy,
+		}
+		if req.Title != "" {
+			event.Title = req.Title
+		}
+		if req.Location != "" {
+			event.Location = req.Location
+		}
+		if req.Performer != "" {
+			event.Performer = req.Performer
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+
+		if len(template.PricingRules) > 0 {
+			rules := make([]models.PricingRule, len(template.PricingRules))
+			for i, tier := range template.PricingRules {
+				rules[i] = models.PricingRule{
+					OrganizationID: orgID,
+					EventID:        event.ID,
+					Name:           tier.Name,
+					Trigger:        tier.Trigger,
+					SoldThreshold:  tier.SoldThreshold,
+					Price:          tier.Price,
+					CreatedBy:      &userID,
+				}
+				if tier.Trigger == models.PricingTriggerDate {
+					startsAt := startDate.Add(-time.Duration(tier.StartsBeforeHours) * time.Hour)
+					rules[i].StartsAt = &startsAt
+				}
+			}
+			if err := tx.Create(&rules).Error; err != nil {
+				return fmt.Errorf("failed to create pricing rules: %w", err)
+			}
+		}
+
+		if reserved > 0 {
+			allocations := make([]models.TicketAllocation, len(template.Allocations))
+			for i, block := range template.Allocations {
+				allocations[i] = models.TicketAllocation{
+					OrganizationID: orgID,
+					EventID:        event.ID,
+					Type:           block.Type,
+					Label:          block.Label,
+					Quantity:       block.Quantity,
+					CreatedBy:      &userID,
+				}
+			}
+			if err := tx.Create(&allocations).Error; err != nil {
+				return fmt.Errorf("failed to create allocations: %w", err)
+			}
+
+			event.Available -= reserved
+			if err := tx.Model(&event).Update("available", event.Available).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	EventChanged(&event)
+	return &event, nil
+}
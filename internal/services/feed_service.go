@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// feedVersionKey is the Redis counter bumped whenever an event changes. Rendered feeds are cached
+// under keys carrying the version, so a bump makes every instance rebuild its feeds.
+const feedVersionKey = "feed:events:version"
+
+// FeedService syndicates published events as Atom feeds for aggregators and newsletters. Rendered
+// feeds are cached in Redis per tenant and filter, and rebuilt after any event is published,
+// updated or deleted.
+type FeedService struct {
+	db          *gorm.DB
+	redisClient redislib.UniversalClient
+	publicURL   string
+	appName     string
+	cacheTTL    time.Duration
+	maxEntries  int
+}
+
+// NewFeedService creates a new feed service
+func NewFeedService(cfg *config.Config) *FeedService {
+	return &FeedService{
+		db:          database.DB,
+		redisClient: redis.Client,
+		publicURL:   strings.TrimRight(cfg.Security.PublicURL, "/"),
+		appName:     cfg.App.Name,
+		cacheTTL:    cfg.Feed.CacheTTL,
+		maxEntries:  cfg.Feed.MaxEntries,
+	}
+}
+
+// EventsFeed returns the Atom feed of upcoming published events, newest first. A cache that is
+// unavailable is logged and the feed is built from the database.
+func (s *FeedService) EventsFeed(ctx context.Context, filter *models.EventFeedFilter) ([]byte, error) {
+	appName, supportEmail, slug := s.appName, "", ""
+	if tenant, ok := TenantFromContext(ctx); ok {
+		appName, supportEmail, slug = tenant.AppName, tenant.SupportEmail, tenant.Slug
+	}
+	category := strings.ToLower(filter.Category)
+
+	key := ""
+	if version, ok := s.version(ctx); ok {
+		key = fmt.Sprintf("feed:events:%d:%s:%s:%s", version, slug, category, filter.Organizer)
+		data, err := s.redisClient.Get(ctx, key).Bytes()
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, redislib.Nil) {
+			log.Printf("Event feed cache unavailable: %v", err)
+		}
+	}
+
+	query := s.db.WithContext(ctx).Where("status IN ? AND end_date > ?", []models.EventStatus{models.EventStatusPublished, models.EventStatusPaused}, time.Now())
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if filter.Organizer != "" {
+		query = query.Where("organizer_id = ?", filter.Organizer)
+	}
+	var events []models.Event
+	if err := query.Order("created_at DESC").Limit(s.maxEntries).Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to list feed events: %w", err)
+	}
+
+	selfURL := s.publicURL + "/api/v1/public/events.atom"
+	params := url.Values{}
+	if category != "" {
+		params.Set("category", category)
+	}
+	if filter.Organizer != "" {
+		params.Set("organizer", filter.Organizer)
+	}
+	if len(params) > 0 {
+		selfURL += "?" + params.Encode()
+	}
+
+	feed := models.AtomFeed{
+		Xmlns:   models.AtomNamespace,
+		ID:      selfURL,
+		Title:   appName + " events",
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Links:   []models.AtomLink{{Rel: "self", Type: "application/atom+xml", Href: selfURL}},
+		Author:  models.AtomPerson{Name: appName, Email: supportEmail},
+		Entries: make([]models.AtomEntry, len(events)),
+	}
+	if category != "" {
+		feed.Subtitle = "Upcoming " + category + " events"
+	}
+
+	var updated time.Time
+	for i, event := range events {
+		eventURL := fmt.Sprintf("%s/api/v1/events/%d", s.publicURL, event.ID)
+		entry := models.AtomEntry{
+			ID:        eventURL,
+			Title:     event.Title,
+			Published: event.CreatedAt.UTC().Format(time.RFC3339),
+			Updated:   event.UpdatedAt.UTC().Format(time.RFC3339),
+			Links:     []models.AtomLink{{Rel: "alternate", Type: "application/json", Href: eventURL}},
+			Summary:   models.AtomText{Type: "text", Body: feedSummary(&event)},
+		}
+		if event.CoverURL != "" {
+			// Served through the image proxy rather than hotlinking the organizer's site
+			entry.Links = append(entry.Links, models.AtomLink{
+				Rel:  "enclosure",
+				Href: fmt.Sprintf("%s/api/v1/images/events/%d/cover", s.publicURL, event.ID),
+			})
+		}
+		if event.Category != "" {
+			entry.Categories = []models.AtomCategory{{Term: event.Category}}
+		}
+		feed.Entries[i] = entry
+
+		if event.UpdatedAt.After(updated) {
+			updated = event.UpdatedAt
+		}
+	}
+	if !updated.IsZero() {
+		feed.Updated = updated.UTC().Format(time.RFC3339)
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to render event feed: %w", err)
+	}
+	data := append([]byte(xml.Header), body...)
+
+	if key != "" {
+		if err := s.redisClient.Set(ctx, key, data, s.cacheTTL).Err(); err != nil {
+			log.Printf("Failed to cache event feed: %v", err)
+		}
+	}
+	return data, nil
+}
+
+// Invalidate makes all instances rebuild their feeds. It is registered as an event hook, so feeds
+// reflect an event as soon as it is published, updated or deleted.
+func (s *FeedService) Invalidate(event *models.Event) {
+	if s.redisClient == nil {
+		return
+	}
+	if err := s.redisClient.Incr(context.Background(), feedVersionKey).Err(); err != nil {
+		log.Printf("Failed to invalidate event feeds after event %d changed: %v", event.ID, err)
+	}
+}
+
+// version returns the current feed version, or false when feeds cannot be cached
+func (s *FeedService) version(ctx context.Context) (int64, bool) {
+	if s.redisClient == nil {
+		return 0, false
+	}
+	version, err := s.redisClient.Get(ctx, feedVersionKey).Int64()
+	if err != nil && !errors.Is(err, redislib.Nil) {
+		log.Printf("Event feed cache unavailable: %v", err)
+		return 0, false
+	}
+	return version, true
+}
+
+// feedSummary describes when and where an event takes place, followed by its description
+func feedSummary(event *models.Event) string {
+	summary := "Starts " + event.StartDate.UTC().Format("Mon, 02 Jan 2006 15:04 MST")
+	if event.Location != "" {
+		summary += " at " + event.Location
+	}
+	if event.Performer != "" {
+		summary += ", featuring " + event.Performer
+	}
+	summary += fmt.Sprintf(". Tickets from %s %s.", formatAmount(event.Price), models.DefaultCurrency)
+	if event.Description != "" {
+		summary += "\n\n" + event.Description
+	}
+	return summary
+}
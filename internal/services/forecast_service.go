@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// TaskForecastRefresh is the asynq task type for recomputing forecasts of upcoming events
+const TaskForecastRefresh = "forecast:refresh"
+
+const (
+	// maxSimilarEvents caps how many past events a forecast compares against, most recent first
+	maxSimilarEvents = 20
+	// maxForecastDays caps how far before the start sales curves are followed
+	maxForecastDays = 365
+	// similarityFactor bounds how much smaller or larger a past event's capacity and price may be
+	similarityFactor = 2.0
+)
+
+// ErrForecastEventNotFound is returned when forecasting an event that does not exist
+var ErrForecastEventNotFound = errors.New("Event not found")
+
+// salesDay is the number of tickets an event sold on one day, counted in days before its start.
+// Tickets sold after the start count as day 0.
+type salesDay struct {
+	EventID    uint
+	DaysBefore int
+	Sold       int
+	CheckedIn  int
+}
+
+// salesDaysSQL aggregates non-cancelled tickets of events per day before the event start
+const salesDaysSQL = `
+SELECT tickets.event_id,
+	GREATEST(FLOOR(EXTRACT(EPOCH FROM events.start_date - tickets.created_at) / 86400), 0)::int AS days_before,
+	COUNT(*) AS sold,
+	COUNT(*) FILTER (WHERE tickets.status = @checked_in) AS checked_in
+FROM tickets JOIN events ON events.id = tickets.event_id
+WHERE tickets.event_id IN @events AND tickets.status <> @cancelled
+GROUP BY tickets.event_id, days_before`
+
+// ForecastService estimates sell-out time and attendance of upcoming events from the sales curves
+// of similar past events. A periodic task recomputes forecasts of upcoming events; results are
+// cached in Redis and computed on demand when missing.
+type ForecastService struct {
+	db          *gorm.DB
+	redisClient redislib.UniversalClient
+	cacheTTL    time.Duration
+}
+
+// NewForecastService creates a new forecast service
+func NewForecastService(cfg *config.Config) *ForecastService {
+	return &ForecastService{
+		db:          database.DB,
+		redisClient: redis.Client,
+		cacheTTL:    cfg.Forecast.CacheTTL,
+	}
+}
+
+// Forecast returns the cached forecast of an event, computing it when there is none
+func (s *ForecastService) Forecast(ctx context.Context, eventID uint) (*models.EventForecast, error) {
+	if s.redisClient != nil {
+		data, err := s.redisClient.Get(ctx, forecastKey(eventID)).Bytes()
+		if err == nil {
+			var forecast models.EventForecast
+			if err := json.Unmarshal(data, &forecast); err == nil {
+				return &forecast, nil
+			}
+		} else if !errors.Is(err, redislib.Nil) {
+			log.Printf("Forecast cache unavailable: %v", err)
+		}
+	}
+
+	var event models.Event
+	if err := s.db.WithContext(ctx).First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrForecastEventNotFound
+		}
+		return nil, err
+	}
+	return s.refresh(ctx, &event)
+}
+
+// RefreshUpcoming recomputes and caches the forecasts of all active events that have not started
+func (s *ForecastService) RefreshUpcoming(ctx context.Context) error {
+	var events []models.Event
+	if err := s.db.WithContext(ctx).Where("status IN ? AND start_date > ?", []models.EventStatus{models.EventStatusPublished, models.EventStatusPaused}, time.Now()).Find(&events).Error; err != nil {
+		return fmt.Errorf("failed to load upcoming events: %w", err)
+	}
+
+	for i := range events {
+		if _, err := s.refresh(ctx, &events[i]); err != nil {
+			// One event's failure should not hold back the others
+			log.Printf("Failed to forecast event %d: %v", events[i].ID, err)
+		}
+	}
+	log.Printf("Refreshed forecasts of %d upcoming events", len(events))
+	return ctx.Err()
+}
+
+// refresh computes an event's forecast and caches it
+func (s *ForecastService) refresh(ctx context.Context, event *models.Event) (*models.EventForecast, error) {
+	forecast, err := s.compute(ctx, event, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	if s.redisClient != nil {
+		if data, err := json.Marshal(forecast); err == nil {
+			if err := s.redisClient.Set(ctx, forecastKey(event.ID), data, s.cacheTTL).Err(); err != nil {
+				log.Printf("Failed to cache forecast of event %d: %v", event.ID, err)
+			}
+		}
+	}
+	return forecast, nil
+}
+
+// compute projects the event's sales to its start. Each similar past event contributes how much
+// its sales grew from the same number of days before its start; the median growth is applied to
+// the tickets sold so far. Without comparable history the event's own average daily pace is
+// extrapolated instead.
+func (s *ForecastService) compute(ctx context.Context, event *models.Event, now time.Time) (*models.EventForecast, error) {
+	db := s.db.WithContext(ctx)
+
+	var similar []models.Event
+	err := db.Select("id", "capacity").
+		Where("id <> ? AND start_date < ? AND status <> ?", event.ID, now, models.EventStatusCancelled).
+		Where("capacity BETWEEN ? AND ?", float64(event.Capacity)/similarityFactor, float64(event.Capacity)*similarityFactor).
+		Where("price BETWEEN ? AND ?", event.Price/similarityFactor, event.Price*similarityFactor).
+		Order("start_date DESC").Limit(maxSimilarEvents).
+		Find(&similar).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar events: %w", err)
+	}
+
+	eventIDs := []uint{event.ID}
+	for _, e := range similar {
+		eventIDs = append(eventIDs, e.ID)
+	}
+	var days []salesDay
+	err = db.Raw(salesDaysSQL, map[string]interface{}{
+		"events":     eventIDs,
+		"checked_in": models.TicketStatusCheckedIn,
+		"cancelled":  models.TicketStatusCancelled,
+	}).Scan(&days).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sales history: %w", err)
+	}
+
+	// Cumulative sales per event indexed by days before start: curves[id][d] is what the event
+	// had sold d days before it started
+	curves := make(map[uint][]int, len(eventIDs))
+	checkedIn := make(map[uint]int, len(eventIDs))
+	for _, day := range days {
+		curve := curves[day.EventID]
+		if curve == nil {
+			curve = make([]int, maxForecastDays+1)
+			curves[day.EventID] = curve
+		}
+		curve[min(day.DaysBefore, maxForecastDays)] += day.Sold
+		checkedIn[day.EventID] += day.CheckedIn
+	}
+	for _, curve := range curves {
+		for d := maxForecastDays - 1; d >= 0; d-- {
+			curve[d] += curve[d+1]
+		}
+	}
+
+	forecast := &models.EventForecast{
+		EventID:    event.ID,
+		Capacity:   event.Capacity,
+		Basis:      models.ForecastBasisCurrentPace,
+		ShowUpRate: 1,
+		ComputedAt: now,
+	}
+	if curve := curves[event.ID]; curve != nil {
+		forecast.Sold = curve[0]
+	}
+	forecast.SoldOut = forecast.Sold >= event.Capacity
+
+	daysLeft := int(math.Floor(event.StartDate.Sub(now).Hours() / 24))
+	switch {
+	case forecast.SoldOut || daysLeft < 0:
+		// Nothing more to project
+		forecast.ProjectedSold = min(forecast.Sold, event.Capacity)
+	default:
+		daysLeft = min(daysLeft, maxForecastDays)
+		growth := medianGrowth(curves, similar, daysLeft)
+		if growth != nil {
+			forecast.Basis = models.ForecastBasisSimilarEvents
+			forecast.SimilarEvents = growth.samples
+		} else {
+			growth = paceGrowth(event, forecast.Sold, now, daysLeft)
+		}
+		forecast.ProjectedSold, forecast.SellOutAt = project(event, forecast.Sold, growth.byDay, now)
+	}
+
+	// Show-up rate of the similar events that recorded check-ins
+	var sold, attended int
+	for _, e := range similar {
+		if curve := curves[e.ID]; curve != nil && checkedIn[e.ID] > 0 {
+			sold += curve[0]
+			attended += checkedIn[e.ID]
+		}
+	}
+	if sold > 0 {
+		forecast.ShowUpRate = math.Round(float64(attended)/float64(sold)*100) / 100
+	}
+	forecast.ExpectedAttendance = int(math.Round(float64(forecast.ProjectedSold) * forecast.ShowUpRate))
+	return forecast, nil
+}
+
+// salesGrowth is the factor by which sales so far are expected to have grown d days before the
+// start, for each d from today (factor 1) down to the start
+type salesGrowth struct {
+	byDay   []float64
+	samples int // Similar events the growth is the median of
+}
+
+// medianGrowth derives expected sales growth from similar events that had sold tickets daysLeft
+// days before their start, or returns nil when there are none
+func medianGrowth(curves map[uint][]int, similar []models.Event, daysLeft int) *salesGrowth {
+	growth := &salesGrowth{byDay: make([]float64, daysLeft+1)}
+	var ratios [][]float64
+	for _, e := range similar {
+		curve := curves[e.ID]
+		if curve == nil || curve[daysLeft] == 0 {
+			continue
+		}
+		r := make([]float64, daysLeft+1)
+		for d := 0; d <= daysLeft; d++ {
+			r[d] = float64(curve[d]) / float64(curve[daysLeft])
+		}
+		ratios = append(ratios, r)
+		growth.samples++
+	}
+	if len(ratios) == 0 {
+		return nil
+	}
+
+	values := make([]float64, len(ratios))
+	for d := 0; d <= daysLeft; d++ {
+		for i, r := range ratios {
+			values[i] = r[d]
+		}
+		growth.byDay[d] = median(values)
+	}
+	return growth
+}
+
+// paceGrowth extrapolates the event's average daily sales since it went on sale
+func paceGrowth(event *models.Event, sold int, now time.Time, daysLeft int) *salesGrowth {
+	growth := &salesGrowth{byDay: make([]float64, daysLeft+1)}
+	onSaleDays := max(now.Sub(event.CreatedAt).Hours()/24, 1)
+	for d := 0; d <= daysLeft; d++ {
+		growth.byDay[d] = 1
+		if sold > 0 {
+			growth.byDay[d] += float64(daysLeft-d) / onSaleDays
+		}
+	}
+	return growth
+}
+
+// project applies growth to sold, returning the tickets expected to be sold by the start and the
+// first day capacity is expected to be reached
+func project(event *models.Event, sold int, growth []float64, now time.Time) (int, *time.Time) {
+	daysLeft := len(growth) - 1
+	for d := daysLeft; d >= 0; d-- {
+		if float64(sold)*growth[d] >= float64(event.Capacity) {
+			sellOut := event.StartDate.Add(-time.Duration(d) * 24 * time.Hour)
+			if sellOut.Before(now) {
+				sellOut = now
+			}
+			return event.Capacity, &sellOut
+		}
+	}
+	return int(math.Round(float64(sold) * growth[0])), nil
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func forecastKey(eventID uint) string {
+	return "forecast:event:" + strconv.FormatUint(uint64(eventID), 10)
+}
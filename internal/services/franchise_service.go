@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrFranchiseEventNotFound is returned when a pushed event does not exist for the organization
+var ErrFranchiseEventNotFound = errors.New("Franchise event not found")
+
+// FranchiseService lets a parent organization, or an admin, push a template event to child
+// organizations. Each child receives a draft copy with the template's branding, refund policy and
+// hold/comp blocks, localizes it and publishes it as its own event.
+type FranchiseService struct {
+	db *gorm.DB
+}
+
+// NewFranchiseService creates a new franchise service
+func NewFranchiseService() *FranchiseService {
+	return &FranchiseService{
+		db: database.DB,
+	}
+}
+
+// Push copies a template event to child organizations as drafts. Organizers may push only to
+// their organization's children; admins may push to any organization. Children that already hold
+// an unpublished draft of the template are skipped.
+func (s *FranchiseService) Push(ctx context.Context, parentOrgID, userID uuid.UUID, isAdmin bool, req *models.PushFranchiseEventRequest) ([]models.FranchiseEvent, error) {
+	db := s.db.WithContext(ctx)
+
+	var template models.Event
+	if err := db.First(&template, req.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Template event not found")
+		}
+		return nil, err
+	}
+	if template.Status == models.EventStatusCancelled {
+		return nil, errors.New("Cancelled events cannot be used as a template")
+	}
+
+	var children []models.Organization
+	query := db.Where("id IN ?", req.OrganizationIDs)
+	if !isAdmin {
+		query = query.Where("parent_id = ?", parentOrgID)
+	}
+	if err := query.Find(&children).Error; err != nil {
+		return nil, err
+	}
+	if len(children) != len(uniqueUUIDs(req.OrganizationIDs)) {
+		return nil, errors.New("Events can only be pushed to existing child organizations")
+	}
+
+	// The parent's holds and comps for the template travel with it
+	var allocations []models.TicketAllocation
+	err := db.Where("organization_id = ? AND event_id = ? AND released_at IS NULL", parentOrgID, template.ID).
+		Order("created_at ASC").Find(&allocations).Error
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]models.FranchiseAllocation, len(allocations))
+	for i, allocation := range allocations {
+		blocks[i] = models.FranchiseAllocation{Type: allocation.Type, Label: allocation.Label, Quantity: allocation.Quantity}
+	}
+
+	// The drafts belong to other organizations than the one in the request context
+	var existing []uuid.UUID
+	err = database.SkipTenancy(db).Model(&models.FranchiseEvent{}).
+		Where("template_event_id = ? AND status = ? AND organization_id IN ?", template.ID, models.FranchiseEventDraft, req.OrganizationIDs).
+		Pluck("organization_id", &existing).Error
+	if err != nil {
+		return nil, err
+	}
+	skip := make(map[uuid.UUID]bool, len(existing))
+	for _, id := range existing {
+		skip[id] = true
+	}
+
+	drafts := make([]models.FranchiseEvent, 0, len(children))
+	for _, child := range children {
+		if skip[child.ID] {
+			continue
+		}
+		drafts = append(drafts, models.FranchiseEvent{
+			OrganizationID:  child.ID,
+			TemplateEventID: template.ID,
+			PushedBy:        &userID,
+			Title:           template.Title,
+			Description:     template.Description,
+			Location:        template.Location,
+			StartDate:       template.StartDate,
+			EndDate:         template.EndDate,
+			Price:           template.Price,
+			Capacity:        template.Capacity,
+			CoverURL:        template.CoverURL,
+			Performer:       template.Performer,
+			Category:        template.Category,
+			RefundPolicy:    template.RefundPolicy,
+			Allocations:     blocks,
+			Status:          models.FranchiseEventDraft,
+		})
+	}
+	if len(drafts) == 0 {
+		return drafts, nil
+	}
+	if err := database.SkipTenancy(db).Create(&drafts).Error; err != nil {
+		return nil, fmt.Errorf("failed to push franchise events: %w", err)
+	}
+	return drafts, nil
+}
+
+// List returns the events pushed to an organization, optionally filtered by status
+func (s *FranchiseService) List(ctx context.Context, orgID uuid.UUID, status string) ([]models.FranchiseEvent, error) {
+	query := s.db.WithContext(ctx).Where("organization_id = ?", orgID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var events []models.FranchiseEvent
+	if err := query.Order("created_at DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Localize adapts a draft's title, description, venue and dates to the child organization
+func (s *FranchiseService) Localize(ctx context.Context, orgID, franchiseEventID uuid.UUID, req *models.LocalizeFranchiseEventRequest) (*models.FranchiseEvent, error) {
+	db := s.db.WithContext(ctx)
+
+	franchiseEvent, err := s.find(db, orgID, franchiseEventID)
+	if err != nil {
+		return nil, err
+	}
+	if franchiseEvent.Status != models.FranchiseEventDraft {
+		return nil, errors.New("Only draft events can be localized")
+	}
+
+	if req.Title != "" {
+		franchiseEvent.Title = req.Title
+	}
+	if req.Description != "" {
+		franchiseEvent.Description = req.Description
+	}
+	if req.Location != "" {
+		franchiseEvent.Location = req.Location
+	}
+	if req.StartDate != nil {
+		franchiseEvent.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		franchiseEvent.EndDate = *req.EndDate
+	}
+	if !franchiseEvent.EndDate.After(franchiseEvent.StartDate) {
+		return nil, errors.New("End date must be after start date")
+	}
+
+	if err := db.Save(franchiseEvent).Error; err != nil {
+		return nil, err
+	}
+	return franchiseEvent, nil
+}
+
+// Publish turns a draft into a live event of the child organization, recreating the template's
+// hold and comp blocks as the child's allocations
+func (s *FranchiseService) Publish(ctx context.Context, orgID, franchiseEventID, userID uuid.UUID) (*models.FranchiseEvent, error) {
+	var franchiseEvent *models.FranchiseEvent
+	var event models.Event
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		franchiseEvent, err = s.find(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID, franchiseEventID)
+		if err != nil {
+			return err
+		}
+		if franchiseEvent.Status != models.FranchiseEventDraft {
+			return errors.New("Event has already been published")
+		}
+		if !franchiseEvent.StartDate.After(time.Now()) {
+			return errors.New("Start date must be in the future; localize the dates before publishing")
+		}
+		if !franchiseEvent.EndDate.After(franchiseEvent.StartDate) {
+			return errors.New("End date must be after start date")
+		}
+
+		reserved := 0
+		for _, block := range franchiseEvent.Allocations {
+			reserved += block.Quantity
+		}
+		if reserved > franchiseEvent.Capacity {
+			return fmt.Errorf("Allocations of %d tickets exceed the capacity of %d", reserved, franchiseEvent.Capacity)
+		}
+
+		event = models.Event{
+			Title:          franchiseEvent.Title,
+			Description:    franchiseEvent.Description,
+			Location:       franchiseEvent.Location,
+			StartDate:      franchiseEvent.StartDate,
+			EndDate:        franchiseEvent.EndDate,
+			Price:          franchiseEvent.Price,
+			Capacity:       franchiseEvent.Capacity,
+			CoverURL:       franchiseEvent.CoverURL,
+			Performer:      franchiseEvent.Performer,
+			Category:       franchiseEvent.Category,
+			OrganizerID:    &userID,
+			OrganizationID: &orgID,
+			RefundPolicy:   franchiseEvent.RefundPolicy,
+			Status:         models.EventStatusPublished,
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return fmt.Errorf("failed to create event: %w", err)
+		}
+
+		if reserved > 0 {
+			allocations := make([]models.TicketAllocation, len(franchiseEvent.Allocations))
+			for i, block := range franchiseEvent.Allocations {
+				allocations[i] = models.TicketAllocation{
+					OrganizationID: orgID,
+					EventID:        event.ID,
+					Type:           block.Type,
+					Label:          block.Label,
+					Quantity:       block.Quantity,
+					CreatedBy:      &userID,
+				}
+			}
+			if err := tx.Create(&allocations).Error; err != nil {
+				return fmt.Errorf("failed to create allocations: %w", err)
+			}
+
+			event.Available -= reserved
+			if err := tx.Model(&event).Update("available", event.Available).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		franchiseEvent.Status = models.FranchiseEventPublished
+		franchiseEvent.EventID = &event.ID
+		franchiseEvent.PublishedAt = &now
+		return tx.Save(franchiseEvent).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	EventChanged(&event)
+	return franchiseEvent, nil
+}
+
+// find loads an event pushed to the organization
+func (s *FranchiseService) find(db *gorm.DB, orgID, franchiseEventID uuid.UUID) (*models.FranchiseEvent, error) {
+	var franchiseEvent models.FranchiseEvent
+	if err := db.Where("id = ? AND organization_id = ?", franchiseEventID, orgID).First(&franchiseEvent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFranchiseEventNotFound
+		}
+		return nil, err
+	}
+	return &franchiseEvent, nil
+}
+
+func uniqueUUIDs(ids []uuid.UUID) map[uuid.UUID]struct{} {
+	unique := make(map[uuid.UUID]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	return unique
+}
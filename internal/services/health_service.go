@@ -20,6 +20,8 @@ type HealthStatus struct {
 	Database    Status       `json:"database"`
 	Redis       Status       `json:"redis"`
 	Environment string       `json:"environment"`
+
+	DegradedFeatures []DegradedFeature `json:"degraded_features,omitempty"` // Features running in a reduced mode while a dependency is down
 }
 
 // SimpleHealthStatus represents a simplified health status with component statuses and messages
@@ -27,6 +29,8 @@ type SimpleHealthStatus struct {
 	Status   string            `json:"status"`
 	Uptime   string            `json:"uptime"`
 	Services map[string]string `json:"services"`
+
+	DegradedFeatures []DegradedFeature `json:"degraded_features,omitempty"`
 }
 
 // ServerStatus represents the server health status
@@ -43,6 +47,24 @@ type Status struct {
 	Message string `json:"message"`
 }
 
+// DegradedFeature describes how a feature behaves while a dependency it needs is down
+type DegradedFeature struct {
+	Feature string `json:"feature"`
+	Impact  string `json:"impact"`
+}
+
+// redisDegradedFeatures are the features that change behavior while Redis is unreachable
+var redisDegradedFeatures = []DegradedFeature{
+	{Feature: "otp", Impact: "One-time codes cannot be sent or verified; OTP and password reset endpoints respond with 503"},
+	{Feature: "email_queue", Impact: "Emails are sent directly by the API without retries; scheduled emails cannot be sent"},
+	{Feature: "background_jobs", Impact: "Jobs such as checkout reminders, webhook deliveries and exports cannot be queued"},
+	{Feature: "carts", Impact: "Carts cannot hold tickets; buyers can still order through checkout"},
+	{Feature: "idempotency", Impact: "Idempotency-Key headers are ignored, so retried writes are not deduplicated"},
+	{Feature: "live_check_in_stats", Impact: "Live check-in statistics are unavailable"},
+	{Feature: "resend_limits", Impact: "Ticket email resend limits are not enforced"},
+	{Feature: "access_token_revocation", Impact: "Signed-out and revoked access tokens stay valid until they expire"},
+}
+
 // NewHealthService creates a new health service
 func NewHealthService() *HealthService {
 	return &HealthService{
@@ -62,7 +84,7 @@ func (s *HealthService) CheckHealth() *HealthStatus {
 		status = "degraded"
 	}
 
-	return &HealthStatus{
+	health := &HealthStatus{
 		Status:      status,
 		Uptime:      time.Since(s.startTime).String(),
 		Server:      serverStatus,
@@ -70,6 +92,10 @@ func (s *HealthService) CheckHealth() *HealthStatus {
 		Redis:       redisStatus,
 		Environment: "production", // This should be dynamically determined from config
 	}
+	if redisStatus.Status == "unhealthy" {
+		health.DegradedFeatures = redisDegradedFeatures
+	}
+	return health
 }
 
 // CheckSimpleHealth provides a simplified health check for all components
@@ -92,15 +118,18 @@ func (s *HealthService) CheckSimpleHealth() *SimpleHealthStatus {
 		overallStatus = "degraded"
 	}
 
+	var degraded []DegradedFeature
 	if redisStatus.Status == "unhealthy" {
 		services["redis"] = redisStatus.Message
 		overallStatus = "degraded"
+		degraded = redisDegradedFeatures
 	}
 
 	return &SimpleHealthStatus{
-		Status:   overallStatus,
-		Uptime:   time.Since(s.startTime).String(),
-		Services: services,
+		Status:           overallStatus,
+		Uptime:           time.Since(s.startTime).String(),
+		Services:         services,
+		DegradedFeatures: degraded,
 	}
 }
 
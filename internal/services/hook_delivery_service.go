@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+const (
+	// hookMaxRetry is how many times a failed hook delivery is retried, with growing delays
+	hookMaxRetry = 5
+)
+
+var (
+	// ErrHookNotFound is returned for a hook subscription the organization does not have
+	ErrHookNotFound = errors.New("Hook subscription not found")
+	// ErrHookTargetNotPublic is returned when subscribing a target that is not a public http or
+	// https URL
+	ErrHookTargetNotPublic = errors.New("Hook target must be an http or https URL on a public address")
+)
+
+// RotateHookSecret replaces the signing secret of a hook subscription, or gives one to a hook
+// subscribed before deliveries were signed. Deliveries are signed with the new secret from now on.
+func (s *IntegrationService) RotateHookSecret(ctx context.Context, orgID, hookID uuid.UUID) (*models.HookSubscriptionResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var subscription models.HookSubscription
+	if err := db.Where("id = ? AND organization_id = ?", hookID, orgID).First(&subscription).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHookNotFound
+		}
+		return nil, err
+	}
+
+	secret, err := newHookSecret()
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = secret
+	if err := db.Model(&subscription).Select("secret").Updates(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	resp := subscription.ToResponse()
+	resp.Secret = secret
+	return &resp, nil
+}
+
+// ListHookDeliveries returns the requested page of an organization's hook deliveries, newest first
+func (s *IntegrationService) ListHookDeliveries(ctx context.Context, orgID uuid.UUID, query *models.HookDeliveryQuery) ([]models.HookDeliveryLog, *models.PageMeta, error) {
+	search := s.db.WithContext(ctx).Model(&models.HookDeliveryLog{}).Where("organization_id = ?", orgID)
+	if query.HookID != "" {
+		search = search.Where("subscription_id = ?", query.HookID)
+	}
+	if query.Event != "" {
+		search = search.Where("event = ?", query.Event)
+	}
+	if query.Status != "" {
+		search = search.Where("status = ?", query.Status)
+	}
+
+	deliveries := []models.HookDeliveryLog{}
+	meta, err := database.Paginate(search.Order("created_at DESC").Order("id ASC"), query.PageQuery, &deliveries)
+	if err != nil {
+		return nil, nil, err
+	}
+	return deliveries, meta, nil
+}
+
+// DispatchCheckIns queues ticket.checked_in hooks for tickets just checked in at the organization
+func (s *IntegrationService) DispatchCheckIns(orgID uuid.UUID, ticketIDs []uuid.UUID) {
+	if len(ticketIDs) == 0 {
+		return
+	}
+
+	// Most organizations have no check-in hooks; skip loading the tickets for them
+	var subscribed int64
+	if err := s.db.Model(&models.HookSubscription{}).
+		Where("organization_id = ? AND event = ?", orgID, models.HookEventTicketCheckedIn).
+		Count(&subscribed).Error; err != nil || subscribed == 0 {
+		return
+	}
+
+	var tickets []models.Ticket
+	if err := s.db.Where("id IN ? AND organization_id = ?", ticketIDs, orgID).Find(&tickets).Error; err != nil {
+		log.Printf("Failed to load checked-in tickets for hooks: Organization=%s, Error=%v", orgID, err)
+		return
+	}
+	for _, ticket := range tickets {
+		data := models.HookCheckIn{
+			AttendeeResponse: ticket.ToAttendeeResponse(),
+			CheckedInAt:      ticket.CheckedInAt,
+			CheckInGate:      ticket.CheckInGate,
+		}
+		if err := s.DispatchHook(orgID, models.HookEventTicketCheckedIn, data); err != nil {
+			log.Printf("Failed to dispatch check-in hook: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+	}
+}
+
+// DispatchEventChanged queues event.updated hooks for an event of an organization. It is
+// registered as an event hook, so it runs whenever an event is created, published, updated,
+// moved through its lifecycle or deleted.
+func (s *IntegrationService) DispatchEventChanged(event *models.Event) {
+	if event.OrganizationID == nil {
+		return
+	}
+	if err := s.DispatchHook(*event.OrganizationID, models.HookEventEventUpdated, event); err != nil {
+		log.Printf("Failed to dispatch event hook: Event=%d, Error=%v", event.ID, err)
+	}
+}
+
+// DeliverHook POSTs a queued hook delivery to its target, signed with the subscription's secret,
+// and records the attempt in the delivery log. Errors are retried by the queue until the last
+// attempt, which marks the delivery failed.
+func (s *IntegrationService) DeliverHook(ctx context.Context, client *http.Client, delivery *models.HookDelivery) error {
+	db := s.db.WithContext(ctx)
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+
+	var subscription models.HookSubscription
+	if err := db.First(&subscription, "id = ?", delivery.SubscriptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.recordHookAttempt(db, delivery.ID, retried, &hookAttempt{err: "Hook subscription was removed"}, models.HookDeliveryFailed)
+			return nil
+		}
+		return fmt.Errorf("failed to load hook subscription: %w", err)
+	}
+
+	attempt := s.postHook(ctx, client, &subscription, delivery)
+	switch {
+	case attempt.status == http.StatusGone:
+		// Integration platforms answer 410 Gone when the subscriber has been removed on their side
+		log.Printf("Hook target gone, removing subscription: ID=%s", delivery.SubscriptionID)
+		s.recordHookAttempt(db, delivery.ID, retried, attempt, models.HookDeliveryFailed)
+		if err := db.Delete(&models.HookSubscription{}, "id = ?", delivery.SubscriptionID).Error; err != nil {
+			return fmt.Errorf("failed to remove gone hook subscription: %w", err)
+		}
+		return nil
+	case attempt.err == "":
+		s.recordHookAttempt(db, delivery.ID, retried, attempt, models.HookDeliveryDelivered)
+		log.Printf("Hook delivered: Subscription=%s, Event=%s", delivery.SubscriptionID, delivery.Event)
+		return nil
+	}
+
+	status := models.HookDeliveryPending
+	if retried >= maxRetry {
+		status = models.HookDeliveryFailed
+	}
+	s.recordHookAttempt(db, delivery.ID, retried, attempt, status)
+	return errors.New(attempt.err)
+}
+
+// hookAttempt is the outcome of one POST of a hook delivery
+type hookAttempt struct {
+	status   int
+	err      string // Empty when the target answered 2xx
+	duration time.Duration
+}
+
+// postHook sends a delivery to its target. The body is sent exactly as logged, so receivers can
+// verify its signature.
+func (s *IntegrationService) postHook(ctx context.Context, client *http.Client, subscription *models.HookSubscription, delivery *models.HookDelivery) *hookAttempt {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.TargetURL, bytes.NewReader(delivery.Data))
+	if err != nil {
+		return &hookAttempt{err: fmt.Sprintf("Invalid target: %v", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hook-Event", string(delivery.Event))
+	req.Header.Set("X-Hook-Delivery", delivery.ID.String())
+	if subscription.Secret != "" {
+		req.Header.Set("X-Hook-Signature", utils.SignHookPayload(subscription.Secret, time.Now(), delivery.Data))
+	}
+
+	started := time.Now()
+	resp, err := client.Do(req)
+	attempt := &hookAttempt{duration: time.Since(started)}
+	if err != nil {
+		attempt.err = fmt.Sprintf("Request failed: %v", err)
+		return attempt
+	}
+	defer resp.Body.Close()
+
+	// Only the status is kept: response bodies are not stored, so the delivery log cannot be used
+	// to read what a target returned
+	attempt.status = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.err = fmt.Sprintf("Target responded with status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// recordHookAttempt updates a delivery log entry with an attempt. Deliveries queued before the
+// log existed have no entry and are not recorded.
+func (s *IntegrationService) recordHookAttempt(db *gorm.DB, deliveryID uuid.UUID, retried int, attempt *hookAttempt, status models.HookDeliveryStatus) {
+	if deliveryID == uuid.Nil {
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":          status,
+		"attempts":        retried + 1,
+		"response_status": attempt.status,
+		"error":           attempt.err,
+		"duration_ms":     attempt.duration.Milliseconds(),
+		"last_attempt_at": now,
+	}
+	if status == models.HookDeliveryDelivered {
+		updates["delivered_at"] = now
+	}
+	if err := db.Model(&models.HookDeliveryLog{}).Where("id = ?", deliveryID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record hook delivery attempt: Delivery=%s, Error=%v", deliveryID, err)
+	}
+}
+
+// newHookSecret generates a random hook signing secret
+func newHookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate hook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
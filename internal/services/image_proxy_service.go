@@ -0,0 +1,261 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	// Register the source formats image.Decode understands
+	_ "image/gif"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Image widths in pixels. Images are only ever scaled down.
+const (
+	DefaultLogoWidth  = 200
+	DefaultCoverWidth = 600
+	MinImageWidth     = 16
+	MaxImageWidth     = 1200
+)
+
+// maxImagePixels caps the decoded size of a source image, so a small file that expands to a huge
+// bitmap cannot exhaust memory
+const maxImagePixels = 25_000_000
+
+var (
+	// ErrImageNotFound is returned when the organization or event has no image
+	ErrImageNotFound = errors.New("Image not found")
+	// ErrImageUnavailable is returned when the source image cannot be downloaded or decoded
+	ErrImageUnavailable = errors.New("Image could not be loaded")
+)
+
+// ProxiedImage is a resized copy of an organizer-supplied image
+type ProxiedImage struct {
+	Data        []byte
+	ContentType string
+	ETag        string
+	MaxAge      time.Duration // How long clients may cache the image
+}
+
+// ImageProxyService serves organizer logos and event covers from this API, resized and re-encoded
+// as PNG or JPEG, so emails and wallet passes don't hotlink organizer sites that may move, block
+// or slow down. Results are cached in Redis.
+type ImageProxyService struct {
+	db          *gorm.DB
+	httpClient  *http.Client
+	redisClient redislib.UniversalClient
+	maxBytes    int64
+	cacheTTL    time.Duration
+}
+
+// NewImageProxyService creates a new image proxy service
+func NewImageProxyService(cfg *config.Config) *ImageProxyService {
+	return &ImageProxyService{
+		db:          database.DB,
+		httpClient:  NewPublicHTTPClient(cfg.ImageProxy.FetchTimeout),
+		redisClient: redis.Client,
+		maxBytes:    cfg.ImageProxy.MaxBytes,
+		cacheTTL:    cfg.ImageProxy.CacheTTL,
+	}
+}
+
+// OrganizationLogo returns an organization's logo scaled to at most width pixels wide
+func (s *ImageProxyService) OrganizationLogo(ctx context.Context, orgID uuid.UUID, width int) (*ProxiedImage, error) {
+	var org models.Organization
+	if err := s.db.WithContext(ctx).Select("id", "logo_url").First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	if org.LogoURL == "" {
+		return nil, ErrImageNotFound
+	}
+	return s.proxy(ctx, org.LogoURL, width)
+}
+
+// EventCover returns an event's cover image scaled to at most width pixels wide
+func (s *ImageProxyService) EventCover(ctx context.Context, eventID uint, width int) (*ProxiedImage, error) {
+	var event models.Event
+	if err := s.db.WithContext(ctx).Select("id", "cover_url").First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImageNotFound
+		}
+		return nil, err
+	}
+	if event.CoverURL == "" {
+		return nil, ErrImageNotFound
+	}
+	return s.proxy(ctx, event.CoverURL, width)
+}
+
+// proxy returns the source image resized to width, from the cache when available
+func (s *ImageProxyService) proxy(ctx context.Context, sourceURL string, width int) (*ProxiedImage, error) {
+	sum := sha256.Sum256([]byte(sourceURL))
+	cacheKey := fmt.Sprintf("image:%s:%d", hex.EncodeToString(sum[:]), width)
+
+	if s.redisClient != nil {
+		if data, err := s.redisClient.Get(ctx, cacheKey).Bytes(); err == nil {
+			return s.result(data), nil
+		} else if !errors.Is(err, redislib.Nil) {
+			log.Printf("Image cache unavailable: %v", err)
+		}
+	}
+
+	source, err := s.fetch(ctx, sourceURL)
+	if err != nil {
+		log.Printf("Failed to fetch image %s: %v", sourceURL, err)
+		return nil, ErrImageUnavailable
+	}
+	data, err := resizeImage(source, width)
+	if err != nil {
+		log.Printf("Failed to resize image %s: %v", sourceURL, err)
+		return nil, ErrImageUnavailable
+	}
+
+	if s.redisClient != nil {
+		if err := s.redisClient.Set(ctx, cacheKey, data, s.cacheTTL).Err(); err != nil {
+			log.Printf("Failed to cache image: %v", err)
+		}
+	}
+	return s.result(data), nil
+}
+
+// fetch downloads a source image, refusing bodies over the size limit
+func (s *ImageProxyService) fetch(ctx context.Context, sourceURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", req.URL.Scheme)
+	}
+	req.Header.Set("Accept", "image/png, image/jpeg, image/gif")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("source returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > s.maxBytes {
+		return nil, fmt.Errorf("source image exceeds %d bytes", s.maxBytes)
+	}
+	return body, nil
+}
+
+func (s *ImageProxyService) result(data []byte) *ProxiedImage {
+	return newProxiedImage(data, s.cacheTTL)
+}
+
+// newProxiedImage wraps encoded image data with its content type and an ETag of its content
+func newProxiedImage(data []byte, maxAge time.Duration) *ProxiedImage {
+	sum := sha256.Sum256(data)
+	return &ProxiedImage{
+		Data:        data,
+		ContentType: http.DetectContentType(data),
+		ETag:        `"` + hex.EncodeToString(sum[:8]) + `"`,
+		MaxAge:      maxAge,
+	}
+}
+
+// resizeImage decodes a PNG, JPEG or GIF image, scales it down to width and re-encodes it
+func resizeImage(source []byte, width int) ([]byte, error) {
+	src, err := decodeImage(source)
+	if err != nil {
+		return nil, err
+	}
+	return encodeImage(scaleDown(src, width))
+}
+
+// decodeImage decodes a PNG, JPEG or GIF image, refusing images over the pixel limit
+func decodeImage(source []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(source))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width == 0 || cfg.Height == 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("source image is %dx%d pixels", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(source))
+	return src, err
+}
+
+// encodeImage encodes an image as PNG when it has transparency and as JPEG otherwise, the two
+// formats every email client displays
+func encodeImage(img *image.RGBA) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	if img.Opaque() {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleDown resizes src to width keeping its aspect ratio, averaging the source pixels each
+// destination pixel covers. Images already narrow enough are only converted to RGBA.
+func scaleDown(src image.Image, width int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	width = min(width, srcW)
+	height := max(srcH*width/srcW, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := max(bounds.Min.Y+(y+1)*srcH/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := max(bounds.Min.X+(x+1)*srcW/width, x0+1)
+
+			// Sum premultiplied 16-bit channels so transparent pixels don't darken the edges
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(b / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskInstallmentCharge is the asynq task type for charging a due installment
+	TaskInstallmentCharge = "installment:charge"
+	// TaskInstallmentDeadline is the asynq task type that cancels unpaid plans when the event starts
+	TaskInstallmentDeadline = "installment:deadline"
+)
+
+// InstallmentChargePayload is the payload of a queued installment charge task
+type InstallmentChargePayload struct {
+	InstallmentID uuid.UUID `json:"installment_id"`
+}
+
+// InstallmentDeadlinePayload is the payload of the task that enforces full payment at event start
+type InstallmentDeadlinePayload struct {
+	OrderID uuid.UUID `json:"order_id"`
+}
+
+// InstallmentService splits orders into scheduled installments and charges them through the payment provider
+type InstallmentService struct {
+	db                *gorm.DB
+	client            *asynq.Client
+	provider          PaymentProvider
+	inventoryService  *InventoryService
+	emailQueueService *EmailQueueService
+	retryDelay        time.Duration
+	maxAttempts       int
+}
+
+// NewInstallmentService creates a new installment service
+func NewInstallmentService(cfg *config.Config) *InstallmentService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	provider, err := NewPaymentProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: Installment charging disabled: %v", err)
+	}
+
+	return &InstallmentService{
+		db:                database.DB,
+		client:            asynq.NewClient(redisOpts),
+		provider:          provider,
+		inventoryService:  NewInventoryService(),
+		emailQueueService: NewEmailQueueService(cfg),
+		retryDelay:        time.Duration(cfg.Payment.InstallmentRetryDays) * 24 * time.Hour,
+		maxAttempts:       cfg.Payment.InstallmentMaxRetries + 1,
+	}
+}
+
+// CreatePlan splits a pending order into installments charged to a saved payment method.
+// The first installment is due immediately; the last falls before the event starts.
+func (s *InstallmentService) CreatePlan(ctx context.Context, orgID uuid.UUID, orderID uuid.UUID, req *models.InstallmentPlanRequest) (*models.InstallmentPlanResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	if s.provider == nil {
+		return nil, errors.New("Installment payments are not available")
+	}
+
+	var order models.Order
+	if err := db.Preload("Event").Where("id = ? AND organization_id = ?", orderID, orgID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	if order.Status != models.OrderStatusPending {
+		return nil, fmt.Errorf("Only pending orders can be paid in installments, order is %s", order.Status)
+	}
+	if order.TotalAmount <= 0 {
+		return nil, errors.New("Free orders cannot be paid in installments")
+	}
+
+	now := time.Now()
+	dueDates := make([]time.Time, req.Installments)
+	for i := range dueDates {
+		if req.Interval == "weekly" {
+			dueDates[i] = now.AddDate(0, 0, 7*i)
+		} else {
+			dueDates[i] = now.AddDate(0, i, 0)
+		}
+	}
+	if order.Event != nil && !dueDates[len(dueDates)-1].Before(order.Event.StartDate) {
+		return nil, errors.New("The last installment would fall after the event starts, choose fewer installments or a shorter interval")
+	}
+
+	amounts := splitAmount(order.TotalAmount, req.Installments)
+	installments := make([]models.Installment, req.Installments)
+	for i := range installments {
+		installments[i] = models.Installment{
+			OrderID:        order.ID,
+			OrganizationID: order.OrganizationID,
+			Sequence:       i + 1,
+			Amount:         amounts[i],
+			DueDate:        dueDates[i],
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&order).Updates(map[string]interface{}{
+			"payment_method": models.PaymentMethodInstallments,
+			"customer_ref":   req.CustomerRef,
+			"payment_ref":    req.PaymentMethodRef,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&installments).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range installments {
+		if err := s.scheduleCharge(&installments[i], installments[i].DueDate); err != nil {
+			log.Printf("Failed to schedule installment: Installment=%s, Error=%v", installments[i].ID, err)
+		}
+	}
+	if order.Event != nil {
+		if err := s.scheduleDeadline(order.ID, order.Event.StartDate); err != nil {
+			log.Printf("Failed to schedule installment deadline: Order=%s, Error=%v", order.ID, err)
+		}
+	}
+
+	return s.GetPlan(ctx, orgID, order.ID)
+}
+
+// GetPlan returns the installment plan of an order
+func (s *InstallmentService) GetPlan(ctx context.Context, orgID uuid.UUID, orderID uuid.UUID) (*models.InstallmentPlanResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var order models.Order
+	if err := db.Where("id = ? AND organization_id = ?", orderID, orgID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	var installments []models.Installment
+	if err := db.Where("order_id = ?", order.ID).Order("sequence ASC").Find(&installments).Error; err != nil {
+		return nil, err
+	}
+
+	plan := &models.InstallmentPlanResponse{
+		OrderID:      order.ID,
+		OrderStatus:  order.Status,
+		TotalAmount:  order.TotalAmount,
+		Currency:     order.Currency,
+		Installments: installments,
+	}
+	for _, installment := range installments {
+		if installment.Status == models.InstallmentStatusPaid {
+			plan.PaidAmount += installment.Amount
+		}
+	}
+	plan.PaidAmount = math.Round(plan.PaidAmount*100) / 100
+
+	return plan, nil
+}
+
+// ChargeInstallment attempts to charge a due installment. Declined charges are retried on a
+// dunning schedule with an email to the buyer; once retries are exhausted the order is cancelled.
+// Transient provider errors are returned so the task is retried.
+func (s *InstallmentService) ChargeInstallment(ctx context.Context, installmentID uuid.UUID) error {
+	var installment models.Installment
+	if err := s.db.First(&installment, "id = ?", installmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// Stale task: already paid, cancelled, or rescheduled to a later attempt
+	if installment.Status != models.InstallmentStatusScheduled {
+		return nil
+	}
+	if installment.NextAttemptAt != nil && installment.NextAttemptAt.After(time.Now().Add(time.Minute)) {
+		return nil
+	}
+
+	var order models.Order
+	if err := s.db.Preload("Event").First(&order, "id = ?", installment.OrderID).Error; err != nil {
+		return err
+	}
+	if order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusRefunded {
+		return s.db.Model(&installment).Update("status", models.InstallmentStatusCancelled).Error
+	}
+
+	if s.provider == nil {
+		return errors.New("payment provider is not configured")
+	}
+
+	attempt := installment.Attempts + 1
+	result, chargeErr := s.provider.Charge(ctx, &ChargeRequest{
+		Amount:           installment.Amount,
+		Currency:         order.Currency,
+		CustomerRef:      order.CustomerRef,
+		PaymentMethodRef: order.PaymentRef,
+		Description:      fmt.Sprintf("Installment %d for order %s", installment.Sequence, order.ID),
+		IdempotencyKey:   fmt.Sprintf("installment-%s-%d", installment.ID, attempt),
+		Metadata: map[string]string{
+			"order_id":       order.ID.String(),
+			"installment_id": installment.ID.String(),
+		},
+	})
+	if chargeErr != nil && !errors.Is(chargeErr, ErrPaymentDeclined) {
+		return chargeErr
+	}
+
+	payment := models.Payment{
+		OrderID:        order.ID,
+		OrganizationID: order.OrganizationID,
+		InstallmentID:  &installment.ID,
+		Provider:       s.provider.Name(),
+		Amount:         installment.Amount,
+		Currency:       order.Currency,
+		Status:         models.PaymentStatusSucceeded,
+	}
+	if chargeErr != nil {
+		payment.Status = models.PaymentStatusFailed
+		payment.FailureReason = chargeErr.Error()
+	} else {
+		payment.ProviderRef = result.ProviderRef
+	}
+	if err := s.db.Create(&payment).Error; err != nil {
+		log.Printf("Failed to record installment payment: Installment=%s, Error=%v", installment.ID, err)
+	}
+
+	if chargeErr != nil {
+		return s.handleDeclined(ctx, &installment, &order, attempt, chargeErr)
+	}
+	return s.handlePaid(&installment, &order, attempt)
+}
+
+// EnforceDeadline cancels an installment order that is not fully paid when its event starts
+func (s *InstallmentService) EnforceDeadline(ctx context.Context, orderID uuid.UUID) error {
+	var order models.Order
+	if err := s.db.WithContext(ctx).Preload("Event").First(&order, "id = ?", orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if order.Status == models.OrderStatusPaid || order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusRefunded {
+		return nil
+	}
+
+	return s.cancelOrder(ctx, &order, "the order was not fully paid before the event started")
+}
+
+// handlePaid marks an installment paid and completes the order once every installment is paid
+func (s *InstallmentService) handlePaid(installment *models.Installment, order *models.Order, attempt int) error {
+	now := time.Now()
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(installment).Updates(map[string]interface{}{
+			"status":          models.InstallmentStatusPaid,
+			"attempts":        attempt,
+			"paid_at":         now,
+			"last_error":      "",
+			"next_attempt_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		var outstanding int64
+		if err := tx.Model(&models.Installment{}).
+			Where("order_id = ? AND status <> ?", order.ID, models.InstallmentStatusPaid).
+			Count(&outstanding).Error; err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{"status": models.OrderStatusPartiallyPaid}
+		if outstanding == 0 {
+			updates["status"] = models.OrderStatusPaid
+			updates["paid_at"] = now
+		}
+		return tx.Model(order).Updates(updates).Error
+	})
+}
+
+// handleDeclined schedules the next dunning attempt, or defaults the plan when attempts are exhausted
+func (s *InstallmentService) handleDeclined(ctx context.Context, installment *models.Installment, order *models.Order, attempt int, chargeErr error) error {
+	if attempt >= s.maxAttempts {
+		if err := s.db.Model(installment).Updates(map[string]interface{}{
+			"status":          models.InstallmentStatusFailed,
+			"attempts":        attempt,
+			"last_error":      chargeErr.Error(),
+			"next_attempt_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return s.cancelOrder(ctx, order, fmt.Sprintf("installment %d could not be charged after %d attempts", installment.Sequence, attempt))
+	}
+
+	nextAttempt := time.Now().Add(s.retryDelay)
+	if err := s.db.Model(installment).Updates(map[string]interface{}{
+		"attempts":        attempt,
+		"last_error":      chargeErr.Error(),
+		"next_attempt_at": nextAttempt,
+	}).Error; err != nil {
+		return err
+	}
+
+	if err := s.scheduleCharge(installment, nextAttempt); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("We couldn't charge installment %d of %.2f %s for your order %s. "+
+		"We'll try again on %s. Please make sure your payment method has sufficient funds, "+
+		"as tickets are only valid once the order is fully paid before the event starts.",
+		installment.Sequence, installment.Amount, order.Currency, order.ID, nextAttempt.Format("January 2, 2006"))
+	if err := s.emailQueueService.QueuePaymentReminderEmail(order, "Installment payment failed", message); err != nil {
+		log.Printf("Failed to queue dunning email: Order=%s, Error=%v", order.ID, err)
+	}
+
+	log.Printf("Installment declined: Installment=%s, Attempt=%d, NextAttempt=%s", installment.ID, attempt, nextAttempt)
+	return nil
+}
+
+// cancelOrder voids an unpaid installment order, its tickets and remaining installments,
+// and returns the tickets to general sale
+func (s *InstallmentService) cancelOrder(ctx context.Context, order *models.Order, reason string) error {
+	var event models.Event
+	var previousAvailable int
+	var cancelled bool
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(order).
+			Where("status IN ?", []models.OrderStatus{models.OrderStatusPending, models.OrderStatusPartiallyPaid}).
+			Update("status", models.OrderStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil // Cancelled or completed concurrently
+		}
+		cancelled = true
+
+		if err := tx.Model(&models.Ticket{}).Where("order_id = ?", order.ID).
+			Update("status", models.TicketStatusCancelled).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Installment{}).
+			Where("order_id = ? AND status = ?", order.ID, models.InstallmentStatusScheduled).
+			Update("status", models.InstallmentStatusCancelled).Error; err != nil {
+			return err
+		}
+
+		if err := tx.First(&event, order.EventID).Error; err != nil {
+			return err
+		}
+		previousAvailable = event.Available
+		return s.inventoryService.Release(ctx, tx, &event, order.Quantity)
+	})
+	if err != nil || !cancelled {
+		return err
+	}
+
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable, OrganizationID: order.OrganizationID})
+
+	message := fmt.Sprintf("Your order %s for %s has been cancelled because %s. Your tickets are no longer valid.",
+		order.ID, event.Title, reason)
+	if err := s.emailQueueService.QueueNotificationEmail(order.BuyerEmail, order.BuyerName, "Your order has been cancelled", message); err != nil {
+		log.Printf("Failed to queue cancellation email: Order=%s, Error=%v", order.ID, err)
+	}
+
+	log.Printf("Installment order cancelled: Order=%s, Reason=%s", order.ID, reason)
+	return nil
+}
+
+// scheduleCharge queues an installment charge at the given time
+func (s *InstallmentService) scheduleCharge(installment *models.Installment, at time.Time) error {
+	payload, err := json.Marshal(InstallmentChargePayload{InstallmentID: installment.ID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal installment charge job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskInstallmentCharge, payload)
+	_, err = s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.ProcessAt(at), asynq.MaxRetry(5))
+	return err
+}
+
+// scheduleDeadline queues the full-payment check of an order at event start
+func (s *InstallmentService) scheduleDeadline(orderID uuid.UUID, at time.Time) error {
+	payload, err := json.Marshal(InstallmentDeadlinePayload{OrderID: orderID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal installment deadline job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskInstallmentDeadline, payload)
+	_, err = s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.ProcessAt(at), asynq.MaxRetry(5))
+	return err
+}
+
+// splitAmount divides a total into n installments rounded to cents, putting the remainder on the last one
+func splitAmount(total float64, n int) []float64 {
+	totalCents := toMinorUnits(total)
+	base := totalCents / int64(n)
+
+	amounts := make([]float64, n)
+	for i := range amounts {
+		cents := base
+		if i == n-1 {
+			cents = totalCents - base*int64(n-1)
+		}
+		amounts[i] = float64(cents) / 100
+	}
+	return amounts
+}
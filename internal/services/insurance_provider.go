@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/pkg/config"
+)
+
+// ErrInsuranceUnavailable is returned when insurance is requested but no provider is configured
+var ErrInsuranceUnavailable = errors.New("Ticket insurance is not available")
+
+// InsuranceQuoteRequest describes the tickets a buyer wants insured
+type InsuranceQuoteRequest struct {
+	EventID       uint
+	EventTitle    string
+	EventStart    time.Time
+	Quantity      int
+	TicketsAmount float64 // Value of the tickets being insured
+	Currency      string
+}
+
+// InsuranceQuote is a provider's price for insuring tickets, valid until it expires
+type InsuranceQuote struct {
+	QuoteRef  string
+	Premium   float64
+	ExpiresAt time.Time
+	TermsURL  string
+}
+
+// InsuranceBindRequest turns an accepted quote into a policy for the buyer
+type InsuranceBindRequest struct {
+	QuoteRef       string
+	OrderRef       string
+	HolderName     string
+	HolderEmail    string
+	IdempotencyKey string
+}
+
+// InsuranceCancelRequest cancels a bound policy, e.g. after its tickets were refunded
+type InsuranceCancelRequest struct {
+	PolicyRef      string
+	Reason         string
+	IdempotencyKey string
+}
+
+// InsuranceCancellation is the outcome of cancelling a policy
+type InsuranceCancellation struct {
+	PremiumRefund float64 // Part of the premium the provider returns, to be refunded to the buyer
+}
+
+// InsuranceProvider sells ticket insurance as a checkout add-on. Quotes are free and unbinding;
+// a policy exists only once a quote is bound.
+type InsuranceProvider interface {
+	Name() string
+	Quote(ctx context.Context, req *InsuranceQuoteRequest) (*InsuranceQuote, error)
+	Bind(ctx context.Context, req *InsuranceBindRequest) (string, error)
+	Cancel(ctx context.Context, req *InsuranceCancelRequest) (*InsuranceCancellation, error)
+}
+
+// NewInsuranceProvider returns the insurance provider selected in the configuration, or nil when
+// the add-on is disabled
+func NewInsuranceProvider(cfg *config.Config) (InsuranceProvider, error) {
+	switch cfg.Insurance.Provider {
+	case "":
+		return nil, nil
+	case "http":
+		if _, err := url.ParseRequestURI(cfg.Insurance.APIURL); err != nil {
+			return nil, fmt.Errorf("invalid INSURANCE_API_URL: %w", err)
+		}
+		return &httpInsuranceProvider{
+			baseURL:    strings.TrimRight(cfg.Insurance.APIURL, "/"),
+			apiKey:     cfg.Insurance.APIKey,
+			httpClient: &http.Client{Timeout: cfg.Insurance.Timeout},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported insurance provider: %s", cfg.Insurance.Provider)
+	}
+}
+
+// httpInsuranceProvider talks to an insurer's JSON API:
+//
+//	POST /quotes                  -> {"quote_id", "premium", "expires_at", "terms_url"}
+//	POST /policies                -> {"policy_id"}
+//	POST /policies/{id}/cancel    -> {"premium_refund"}
+//
+// Amounts are decimal numbers in the request currency.
+type httpInsuranceProvider struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func (p *httpInsuranceProvider) Name() string {
+	return "http"
+}
+
+// Quote prices insurance for the tickets
+func (p *httpInsuranceProvider) Quote(ctx context.Context, req *InsuranceQuoteRequest) (*InsuranceQuote, error) {
+	var resp struct {
+		QuoteID   string    `json:"quote_id"`
+		Premium   float64   `json:"premium"`
+		ExpiresAt time.Time `json:"expires_at"`
+		TermsURL  string    `json:"terms_url"`
+	}
+	err := p.do(ctx, "/quotes", map[string]interface{}{
+		"event_id":       req.EventID,
+		"event_title":    req.EventTitle,
+		"event_start":    req.EventStart,
+		"quantity":       req.Quantity,
+		"tickets_amount": req.TicketsAmount,
+		"currency":       req.Currency,
+	}, "", &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.QuoteID == "" || resp.Premium < 0 {
+		return nil, errors.New("insurance provider returned an invalid quote")
+	}
+	return &InsuranceQuote{QuoteRef: resp.QuoteID, Premium: resp.Premium, ExpiresAt: resp.ExpiresAt, TermsURL: resp.TermsURL}, nil
+}
+
+// Bind issues a policy from a quote and returns its reference
+func (p *httpInsuranceProvider) Bind(ctx context.Context, req *InsuranceBindRequest) (string, error) {
+	var resp struct {
+		PolicyID string `json:"policy_id"`
+	}
+	err := p.do(ctx, "/policies", map[string]interface{}{
+		"quote_id":     req.QuoteRef,
+		"reference":    req.OrderRef,
+		"holder_name":  req.HolderName,
+		"holder_email": req.HolderEmail,
+	}, req.IdempotencyKey, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.PolicyID == "" {
+		return "", errors.New("insurance provider returned no policy ID")
+	}
+	return resp.PolicyID, nil
+}
+
+// Cancel cancels a policy
+func (p *httpInsuranceProvider) Cancel(ctx context.Context, req *InsuranceCancelRequest) (*InsuranceCancellation, error) {
+	var resp struct {
+		PremiumRefund float64 `json:"premium_refund"`
+	}
+	err := p.do(ctx, "/policies/"+url.PathEscape(req.PolicyRef)+"/cancel", map[string]interface{}{
+		"reason": req.Reason,
+	}, req.IdempotencyKey, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &InsuranceCancellation{PremiumRefund: resp.PremiumRefund}, nil
+}
+
+// do posts a JSON request to the provider and decodes the JSON response into out
+func (p *httpInsuranceProvider) do(ctx context.Context, path string, payload interface{}, idempotencyKey string, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build insurance request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("insurance request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read insurance response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return fmt.Errorf("insurance provider responded with status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode insurance response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskHookDeliver is the asynq task type for REST hook deliveries
+	TaskHookDeliver = "hook:deliver"
+	// TaskContactSync is the asynq task type for marketing contact syncs
+	TaskContactSync = "marketing:sync"
+	// IntegrationQueue is the asynq queue integration jobs are placed on
+	IntegrationQueue = "queue:integrations"
+
+	defaultPollingLimit = 50
+)
+
+// IntegrationService provides polling triggers, REST hook subscriptions and
+// marketing contact sync for third-party platforms (Zapier, Make, Mailchimp, Brevo)
+type IntegrationService struct {
+	db     *gorm.DB
+	client *asynq.Client
+}
+
+// NewIntegrationService creates a new integration service
+func NewIntegrationService(cfg *config.Config) *IntegrationService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	return &IntegrationService{
+		db:     database.DB,
+		client: asynq.NewClient(redisOpts),
+	}
+}
+
+// ListNewOrders returns orders of an organization created after the given cursor, oldest first
+func (s *IntegrationService) ListNewOrders(ctx context.Context, orgID uuid.UUID, query *models.PollingQuery) (*models.PollingPage, error) {
+	db := s.db.WithContext(ctx)
+
+	var orders []models.Order
+	limit, err := s.pollingScope(db.Where("organization_id = ?", orgID), query, &orders)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(orders) > limit
+	if hasMore {
+		orders = orders[:limit]
+	}
+
+	items := make([]models.OrderResponse, len(orders))
+	for i, order := range orders {
+		items[i] = order.ToResponse()
+	}
+
+	page := &models.PollingPage{Items: items, HasMore: hasMore, NextCursor: query.Since}
+	if len(orders) > 0 {
+		last := orders[len(orders)-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// ListNewAttendees returns ticket holders of an organization created after the given cursor, oldest first
+func (s *IntegrationService) ListNewAttendees(ctx context.Context, orgID uuid.UUID, query *models.PollingQuery) (*models.PollingPage, error) {
+	db := s.db.WithContext(ctx)
+
+	var tickets []models.Ticket
+	limit, err := s.pollingScope(db.Where("organization_id = ?", orgID), query, &tickets)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(tickets) > limit
+	if hasMore {
+		tickets = tickets[:limit]
+	}
+
+	items := make([]models.AttendeeResponse, len(tickets))
+	for i, ticket := range tickets {
+		items[i] = ticket.ToAttendeeResponse()
+	}
+
+	page := &models.PollingPage{Items: items, HasMore: hasMore, NextCursor: query.Since}
+	if len(tickets) > 0 {
+		last := tickets[len(tickets)-1]
+		page.NextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return page, nil
+}
+
+// pollingScope applies cursor, ordering and limit to a polling query and loads one extra row
+// so callers can tell whether more results are available
+func (s *IntegrationService) pollingScope(tx *gorm.DB, query *models.PollingQuery, dest interface{}) (int, error) {
+	cursor, err := utils.DecodeCursor(query.Since)
+	if err != nil {
+		return 0, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultPollingLimit
+	}
+
+	if cursor != nil {
+		tx = tx.Where("(created_at > ?) OR (created_at = ? AND id > ?)", cursor.CreatedAt, cursor.CreatedAt, cursor.ID)
+	}
+
+	if err := tx.Order("created_at ASC, id ASC").Limit(limit + 1).Find(dest).Error; err != nil {
+		return 0, err
+	}
+	return limit, nil
+}
+
+// Subscribe registers a REST hook for an organization
+func (s *IntegrationService) Subscribe(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.HookSubscribeRequest) (*models.HookSubscriptionResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	// Targets are organizer-supplied, so they must not point at internal services
+	if err := validatePublicURL(ctx, req.TargetURL); err != nil {
+		return nil, ErrHookTargetNotPublic
+	}
+
+	secret, err := newHookSecret()
+	if err != nil {
+		return nil, err
+	}
+	subscription := models.HookSubscription{
+		OrganizationID: orgID,
+		Event:          models.HookEvent(req.Event),
+		TargetURL:      req.TargetURL,
+		Secret:         secret,
+		CreatedBy:      &userID,
+	}
+
+	if err := db.Create(&subscription).Error; err != nil {
+		return nil, err
+	}
+
+	resp := subscription.ToResponse()
+	resp.Secret = secret
+	return &resp, nil
+}
+
+// ListSubscriptions returns all REST hooks registered for an organization
+func (s *IntegrationService) ListSubscriptions(ctx context.Context, orgID uuid.UUID) ([]models.HookSubscriptionResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var subscriptions []models.HookSubscription
+	if err := db.Where("organization_id = ?", orgID).Order("created_at ASC").Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.HookSubscriptionResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		responses[i] = subscription.ToResponse()
+	}
+	return responses, nil
+}
+
+// Unsubscribe removes a REST hook from an organization
+func (s *IntegrationService) Unsubscribe(ctx context.Context, orgID uuid.UUID, hookID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	result := db.Where("id = ? AND organization_id = ?", hookID, orgID).Delete(&models.HookSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrHookNotFound
+	}
+
+	return nil
+}
+
+// DispatchHook queues a delivery to every REST hook subscribed to the event for the organization.
+// Each delivery is recorded in the delivery log before it is queued.
+func (s *IntegrationService) DispatchHook(orgID uuid.UUID, event models.HookEvent, data interface{}) error {
+	var subscriptions []models.HookSubscription
+	if err := s.db.Where("organization_id = ? AND event = ?", orgID, event).Find(&subscriptions).Error; err != nil {
+		return err
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hook body: %w", err)
+	}
+
+	for _, subscription := range subscriptions {
+		entry := &models.HookDeliveryLog{
+			OrganizationID: orgID,
+			SubscriptionID: subscription.ID,
+			Event:          event,
+			TargetURL:      subscription.TargetURL,
+			Payload:        string(body),
+			Status:         models.HookDeliveryPending,
+		}
+		if err := s.db.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to log hook delivery: %w", err)
+		}
+
+		payload, err := json.Marshal(models.HookDelivery{
+			ID:             entry.ID,
+			SubscriptionID: subscription.ID,
+			TargetURL:      subscription.TargetURL,
+			Event:          event,
+			Data:           json.RawMessage(body),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal hook delivery: %w", err)
+		}
+
+		task := asynq.NewTask(TaskHookDeliver, payload)
+		if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(IntegrationQueue)), asynq.MaxRetry(hookMaxRetry)); err != nil {
+			log.Printf("Failed to enqueue hook delivery: Subscription=%s, Error=%v", subscription.ID, err)
+			s.db.Model(entry).Updates(map[string]interface{}{
+				"status": models.HookDeliveryFailed,
+				"error":  "Delivery could not be queued",
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInternalSubjectNotFound is returned for an order or attendee the organization does not have
+	ErrInternalSubjectNotFound = errors.New("Order or attendee not found")
+	// ErrInternalNoteNotFound is returned for a note the organization does not have
+	ErrInternalNoteNotFound = errors.New("Note not found")
+)
+
+// InternalNoteService keeps the internal notes and tags organization members put on orders and
+// attendees. They live apart from orders and tickets, so no buyer- or attendee-facing response
+// can carry them.
+type InternalNoteService struct {
+	db *gorm.DB
+}
+
+func NewInternalNoteService() *InternalNoteService {
+	return &InternalNoteService{db: database.DB}
+}
+
+// Annotations returns the tags and notes of an order or attendee of the organization
+func (s *InternalNoteService) Annotations(ctx context.Context, orgID uuid.UUID, subjectType models.InternalSubjectType, subjectID uuid.UUID) (*models.InternalAnnotationsResponse, error) {
+	db := s.db.WithContext(ctx)
+	if err := s.checkSubject(db, orgID, subjectType, subjectID); err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.load(db, orgID, []uuid.UUID{subjectID})
+	if err != nil {
+		return nil, err
+	}
+	return annotationsOf(annotations, subjectType, subjectID), nil
+}
+
+// AddNote adds a note to an order or attendee of the organization
+func (s *InternalNoteService) AddNote(ctx context.Context, orgID, authorID uuid.UUID, subjectType models.InternalSubjectType, subjectID uuid.UUID, req *models.InternalNoteRequest) (*models.InternalNote, error) {
+	db := s.db.WithContext(ctx)
+	if err := s.checkSubject(db, orgID, subjectType, subjectID); err != nil {
+		return nil, err
+	}
+
+	note := &models.InternalNote{
+		OrganizationID: orgID,
+		SubjectType:    subjectType,
+		SubjectID:      subjectID,
+		Body:           strings.TrimSpace(req.Body),
+		AuthorID:       authorID,
+	}
+	if note.Body == "" {
+		return nil, errors.New("Note must not be blank")
+	}
+	if err := db.Create(note).Error; err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// DeleteNote deletes a note of the organization
+func (s *InternalNoteService) DeleteNote(ctx context.Context, orgID, noteID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", noteID, orgID).Delete(&models.InternalNote{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInternalNoteNotFound
+	}
+	return nil
+}
+
+// SetTags replaces the tags of an order or attendee of the organization. Tags are trimmed,
+// lowercased and deduplicated; an empty list removes them all.
+func (s *InternalNoteService) SetTags(ctx context.Context, orgID, userID uuid.UUID, subjectType models.InternalSubjectType, subjectID uuid.UUID, req *models.InternalTagsRequest) (*models.InternalAnnotationsResponse, error) {
+	names := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		name := strings.ToLower(strings.TrimSpace(tag))
+		if name == "" {
+			return nil, errors.New("Tags must not be blank")
+		}
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var annotations map[uuid.UUID]*models.InternalAnnotationsResponse
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := s.checkSubject(tx, orgID, subjectType, subjectID); err != nil {
+			return err
+		}
+
+		if err := tx.Where("subject_type = ? AND subject_id = ?", subjectType, subjectID).Delete(&models.InternalTag{}).Error; err != nil {
+			return err
+		}
+		if len(names) > 0 {
+			tags := make([]models.InternalTag, len(names))
+			for i, name := range names {
+				tags[i] = models.InternalTag{
+					OrganizationID: orgID,
+					SubjectType:    subjectType,
+					SubjectID:      subjectID,
+					Name:           name,
+					CreatedBy:      userID,
+				}
+			}
+			if err := tx.Create(&tags).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		annotations, err = s.load(tx, orgID, []uuid.UUID{subjectID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return annotationsOf(annotations, subjectType, subjectID), nil
+}
+
+// Search returns the requested page of the organization's orders and attendees with notes or tags
+// matching the query, most recently annotated first, each with all of its tags and notes
+func (s *InternalNoteService) Search(ctx context.Context, orgID uuid.UUID, query *models.InternalAnnotationQuery) ([]models.InternalAnnotationsResponse, *models.PageMeta, error) {
+	db := s.db.WithContext(ctx)
+
+	subjects := db.Raw(`
+		SELECT subject_type, subject_id, MAX(created_at) AS annotated_at FROM (
+			SELECT subject_type, subject_id, created_at FROM internal_notes WHERE organization_id = @org
+			UNION ALL
+			SELECT subject_type, subject_id, created_at FROM internal_tags WHERE organization_id = @org
+		) AS annotations
+		GROUP BY subject_type, subject_id`, map[string]interface{}{"org": orgID})
+
+	search := db.Table("(?) AS subjects", subjects).Select("subject_type", "subject_id")
+	if query.SubjectType != "" {
+		search = search.Where("subject_type = ?", query.SubjectType)
+	}
+	if query.Tag != "" {
+		search = search.Where(`EXISTS (SELECT 1 FROM internal_tags WHERE internal_tags.subject_type = subjects.subject_type
+			AND internal_tags.subject_id = subjects.subject_id AND internal_tags.name = ?)`, strings.ToLower(strings.TrimSpace(query.Tag)))
+	}
+	if query.Q != "" {
+		search = search.Where(`EXISTS (SELECT 1 FROM internal_notes WHERE internal_notes.subject_type = subjects.subject_type
+			AND internal_notes.subject_id = subjects.subject_id AND internal_notes.body ILIKE ?)`, containsPattern(query.Q))
+	}
+	search = search.Order("annotated_at DESC").Order("subject_id ASC")
+
+	var matches []struct {
+		SubjectType models.InternalSubjectType
+		SubjectID   uuid.UUID
+	}
+	meta, err := database.Paginate(search, query.PageQuery, &matches)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]uuid.UUID, len(matches))
+	for i, match := range matches {
+		ids[i] = match.SubjectID
+	}
+	annotations, err := s.load(db, orgID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]models.InternalAnnotationsResponse, len(matches))
+	for i, match := range matches {
+		results[i] = *annotationsOf(annotations, match.SubjectType, match.SubjectID)
+	}
+	return results, meta, nil
+}
+
+// checkSubject fails with ErrInternalSubjectNotFound unless the order or ticket belongs to the organization
+func (s *InternalNoteService) checkSubject(db *gorm.DB, orgID uuid.UUID, subjectType models.InternalSubjectType, subjectID uuid.UUID) error {
+	var model interface{}
+	switch subjectType {
+	case models.InternalSubjectOrder:
+		model = &models.Order{}
+	case models.InternalSubjectTicket:
+		model = &models.Ticket{}
+	default:
+		return ErrInternalSubjectNotFound
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ? AND organization_id = ?", subjectID, orgID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrInternalSubjectNotFound
+	}
+	return nil
+}
+
+// load returns the tags and notes of the organization's subjects, by subject ID
+func (s *InternalNoteService) load(db *gorm.DB, orgID uuid.UUID, subjectIDs []uuid.UUID) (map[uuid.UUID]*models.InternalAnnotationsResponse, error) {
+	annotations := make(map[uuid.UUID]*models.InternalAnnotationsResponse, len(subjectIDs))
+	if len(subjectIDs) == 0 {
+		return annotations, nil
+	}
+	entry := func(subjectType models.InternalSubjectType, subjectID uuid.UUID) *models.InternalAnnotationsResponse {
+		if annotations[subjectID] == nil {
+			annotations[subjectID] = &models.InternalAnnotationsResponse{
+				SubjectType: subjectType,
+				SubjectID:   subjectID,
+				Tags:        []string{},
+				Notes:       []models.InternalNote{},
+			}
+		}
+		return annotations[subjectID]
+	}
+
+	var tags []models.InternalTag
+	if err := db.Where("organization_id = ? AND subject_id IN ?", orgID, subjectIDs).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		e := entry(tag.SubjectType, tag.SubjectID)
+		e.Tags = append(e.Tags, tag.Name)
+	}
+
+	var notes []models.InternalNote
+	if err := db.Where("organization_id = ? AND subject_id IN ?", orgID, subjectIDs).Order("created_at DESC").Find(&notes).Error; err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		e := entry(note.SubjectType, note.SubjectID)
+		e.Notes = append(e.Notes, note)
+	}
+	return annotations, nil
+}
+
+// annotationsOf returns the loaded annotations of a subject, empty when it has none
+func annotationsOf(annotations map[uuid.UUID]*models.InternalAnnotationsResponse, subjectType models.InternalSubjectType, subjectID uuid.UUID) *models.InternalAnnotationsResponse {
+	if found := annotations[subjectID]; found != nil {
+		return found
+	}
+	return &models.InternalAnnotationsResponse{
+		SubjectType: subjectType,
+		SubjectID:   subjectID,
+		Tags:        []string{},
+		Notes:       []models.InternalNote{},
+	}
+}
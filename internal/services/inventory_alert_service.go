@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// InventoryAlertService evaluates organizer-defined sold-percentage thresholds whenever event inventory changes
+type InventoryAlertService struct {
+	db                 *gorm.DB
+	integrationService *IntegrationService
+	emailQueueService  *EmailQueueService
+}
+
+// NewInventoryAlertService creates a new inventory alert service
+func NewInventoryAlertService(cfg *config.Config) *InventoryAlertService {
+	return &InventoryAlertService{
+		db:                 database.DB,
+		integrationService: NewIntegrationService(cfg),
+		emailQueueService:  NewEmailQueueService(cfg),
+	}
+}
+
+// CreateAlert adds an inventory alert on an event for an organization
+func (s *InventoryAlertService) CreateAlert(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.InventoryAlertRequest) (*models.InventoryAlertResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, req.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+
+	channels := req.Channels
+	if len(channels) == 0 {
+		channels = []string{models.AlertChannelChat, models.AlertChannelEmail}
+	}
+
+	alert := models.InventoryAlert{
+		OrganizationID:   orgID,
+		EventID:          event.ID,
+		ThresholdPercent: req.ThresholdPercent,
+		Channels:         channels,
+		AutoOpenWaitlist: req.AutoOpenWaitlist,
+		CreatedBy:        &userID,
+	}
+
+	if err := db.Create(&alert).Error; err != nil {
+		return nil, err
+	}
+
+	resp := alert.ToResponse()
+	return &resp, nil
+}
+
+// ListAlerts returns the inventory alerts of an organization
+func (s *InventoryAlertService) ListAlerts(ctx context.Context, orgID uuid.UUID) ([]models.InventoryAlertResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var alerts []models.InventoryAlert
+	if err := db.Where("organization_id = ?", orgID).Order("event_id ASC, threshold_percent ASC").Find(&alerts).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.InventoryAlertResponse, len(alerts))
+	for i, alert := range alerts {
+		responses[i] = alert.ToResponse()
+	}
+	return responses, nil
+}
+
+// DeleteAlert removes an inventory alert from an organization
+func (s *InventoryAlertService) DeleteAlert(ctx context.Context, orgID uuid.UUID, alertID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	result := db.Where("id = ? AND organization_id = ?", alertID, orgID).Delete(&models.InventoryAlert{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("Inventory alert not found")
+	}
+
+	return nil
+}
+
+// EvaluateInventory is an inventory hook that fires every alert whose threshold was crossed by the change.
+// Each alert fires once per crossing, so it re-arms if tickets are released and sold again.
+func (s *InventoryAlertService) EvaluateInventory(change *InventoryChange) {
+	event := change.Event
+	before, after := change.SoldPercent()
+
+	var alerts []models.InventoryAlert
+	if err := s.db.Where("event_id = ?", event.ID).Find(&alerts).Error; err != nil {
+		log.Printf("Failed to load inventory alerts: Event=%d, Error=%v", event.ID, err)
+	}
+
+	soldOutAlerted := false
+	for i := range alerts {
+		alert := &alerts[i]
+		threshold := float64(alert.ThresholdPercent)
+		if before >= threshold || after < threshold {
+			continue
+		}
+
+		s.triggerAlert(alert, event, after)
+		if alert.ThresholdPercent >= 100 && slices.Contains(alert.Channels, models.AlertChannelChat) {
+			soldOutAlerted = true
+		}
+	}
+
+	// Sold-out chat notifications are sent even without a configured alert
+	if !soldOutAlerted && change.OrganizationID != nil && change.PreviousAvailable > 0 && event.Available <= 0 {
+		s.integrationService.NotifyEventSoldOut(*change.OrganizationID, event)
+	}
+}
+
+// triggerAlert delivers an alert through its channels and opens the waitlist when requested
+func (s *InventoryAlertService) triggerAlert(alert *models.InventoryAlert, event *models.Event, soldPercent float64) {
+	subject, message := inventoryAlertMessage(alert, event, soldPercent)
+
+	for _, channel := range alert.Channels {
+		switch channel {
+		case models.AlertChannelChat:
+			notification := models.ChatNotificationLowInventory
+			if alert.ThresholdPercent >= 100 {
+				notification = models.ChatNotificationEventSoldOut
+			}
+			s.integrationService.NotifyChat(alert.OrganizationID, notification, ":warning: "+message)
+		case models.AlertChannelEmail:
+			if err := s.emailOrganizer(alert.OrganizationID, subject, message); err != nil {
+				log.Printf("Failed to email inventory alert: Alert=%s, Error=%v", alert.ID, err)
+			}
+		}
+	}
+
+	if alert.AutoOpenWaitlist && !event.WaitlistOpen {
+		if err := s.db.Model(&models.Event{}).Where("id = ?", event.ID).Update("waitlist_open", true).Error; err != nil {
+			log.Printf("Failed to open waitlist: Event=%d, Error=%v", event.ID, err)
+		} else {
+			event.WaitlistOpen = true
+			log.Printf("Waitlist opened by inventory alert: Event=%d, Alert=%s", event.ID, alert.ID)
+		}
+	}
+
+	if err := s.db.Model(alert).Update("last_triggered_at", time.Now()).Error; err != nil {
+		log.Printf("Failed to record inventory alert: Alert=%s, Error=%v", alert.ID, err)
+	}
+}
+
+// emailOrganizer queues an email to the organizer of an organization
+func (s *InventoryAlertService) emailOrganizer(orgID uuid.UUID, subject, message string) error {
+	var org models.Organization
+	if err := s.db.Preload("Organizer").First(&org, "id = ?", orgID).Error; err != nil {
+		return err
+	}
+
+	if org.Organizer == nil || org.Organizer.Email == "" {
+		return errors.New("Organization has no organizer email")
+	}
+
+	return s.emailQueueService.QueueNotificationEmail(org.Organizer.Email, org.Organizer.FirstName, subject, message)
+}
+
+// inventoryAlertMessage builds the subject and body of an alert
+func inventoryAlertMessage(alert *models.InventoryAlert, event *models.Event, soldPercent float64) (string, string) {
+	if event.Available <= 0 {
+		return fmt.Sprintf("%s is sold out", event.Title),
+			fmt.Sprintf("%s has sold all %d tickets.", event.Title, event.Capacity)
+	}
+
+	subject := fmt.Sprintf("%s is %d%% sold", event.Title, alert.ThresholdPercent)
+	message := fmt.Sprintf("%s has sold %.0f%% of its capacity. %d of %d tickets remain.",
+		event.Title, soldPercent, event.Available, event.Capacity)
+	if alert.AutoOpenWaitlist {
+		message += " The waitlist has been opened."
+	}
+	return subject, message
+}
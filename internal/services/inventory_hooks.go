@@ -0,0 +1,58 @@
+package services
+
+import (
+	"sync"
+
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// InventoryChange describes a change to the available ticket count of an event
+type InventoryChange struct {
+	Event             *models.Event // Event after the change
+	PreviousAvailable int
+	OrganizationID    *uuid.UUID // Organization selling the tickets, when known
+}
+
+// SoldPercent returns the share of capacity sold before and after the change
+func (c *InventoryChange) SoldPercent() (before, after float64) {
+	if c.Event.Capacity <= 0 {
+		return 0, 0
+	}
+	capacity := float64(c.Event.Capacity)
+	before = float64(c.Event.Capacity-c.PreviousAvailable) * 100 / capacity
+	after = float64(c.Event.Capacity-c.Event.Available) * 100 / capacity
+	return before, after
+}
+
+// InventoryHook is called after the available ticket count of an event changes
+type InventoryHook func(change *InventoryChange)
+
+var (
+	inventoryHooksMu sync.RWMutex
+	inventoryHooks   []InventoryHook
+)
+
+// RegisterInventoryHook adds a hook that runs on every inventory change
+func RegisterInventoryHook(hook InventoryHook) {
+	inventoryHooksMu.Lock()
+	defer inventoryHooksMu.Unlock()
+	inventoryHooks = append(inventoryHooks, hook)
+}
+
+// InventoryChanged runs the registered inventory hooks. Callers invoke it once the change is committed.
+func InventoryChanged(change *InventoryChange) {
+	if change.Event == nil || change.PreviousAvailable == change.Event.Available {
+		return
+	}
+
+	inventoryHooksMu.RLock()
+	hooks := make([]InventoryHook, len(inventoryHooks))
+	copy(hooks, inventoryHooks)
+	inventoryHooksMu.RUnlock()
+
+	for _, hook := range hooks {
+		hook(change)
+	}
+}
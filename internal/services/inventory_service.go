@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// inventoryColumns are read back from every inventory update so callers see the committed counts
+var inventoryColumns = []clause.Column{{Name: "available"}, {Name: "capacity"}}
+
+// InventoryService changes the available ticket count of events. Every change is a single
+// conditional UPDATE, so concurrent purchases, holds and capacity edits can neither oversell
+// an event nor overwrite each other's counts.
+type InventoryService struct {
+	db *gorm.DB
+}
+
+// NewInventoryService creates a new inventory service
+func NewInventoryService() *InventoryService {
+	return &InventoryService{
+		db: database.DB,
+	}
+}
+
+// Reserve takes quantity tickets off the event's availability and refreshes event with the
+// stored counts. Nothing changes when fewer tickets remain. tx is the caller's transaction,
+// or nil to update outside one under ctx.
+func (s *InventoryService) Reserve(ctx context.Context, tx *gorm.DB, event *models.Event, quantity int) error {
+	db := s.conn(ctx, tx)
+
+	result := db.Model(event).Clauses(clause.Returning{Columns: inventoryColumns}).
+		Where("available >= ?", quantity).
+		Update("available", gorm.Expr("available - ?", quantity))
+	if result.Error != nil {
+		return fmt.Errorf("failed to reserve tickets: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		return nil
+	}
+
+	if err := db.Select("available", "capacity").First(event, event.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("Event not found")
+		}
+		return err
+	}
+	return fmt.Errorf("Only %d tickets are available", event.Available)
+}
+
+// Release puts quantity tickets back on sale, never raising availability above capacity,
+// and refreshes event with the stored counts
+func (s *InventoryService) Release(ctx context.Context, tx *gorm.DB, event *models.Event, quantity int) error {
+	err := s.conn(ctx, tx).Model(event).Clauses(clause.Returning{Columns: inventoryColumns}).
+		Update("available", gorm.Expr("LEAST(available + ?, capacity)", quantity)).Error
+	if err != nil {
+		return fmt.Errorf("failed to release tickets: %w", err)
+	}
+	return nil
+}
+
+// Resize changes the event's capacity, keeping the tickets already sold or held, and
+// refreshes event with the stored counts
+func (s *InventoryService) Resize(ctx context.Context, tx *gorm.DB, event *models.Event, capacity int) error {
+	err := s.conn(ctx, tx).Model(event).Clauses(clause.Returning{Columns: inventoryColumns}).
+		Updates(map[string]interface{}{
+			"available": gorm.Expr("GREATEST(available + ? - capacity, 0)", capacity),
+			"capacity":  capacity,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to resize event: %w", err)
+	}
+	return nil
+}
+
+func (s *InventoryService) conn(ctx context.Context, tx *gorm.DB) *gorm.DB {
+	if tx != nil {
+		return tx
+	}
+	return s.db.WithContext(ctx)
+}
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Audit log actions for organization invitations
+const (
+	AuditInvitationCreated  = "organization_invitation.created"
+	AuditInvitationAccepted = "organization_invitation.accepted"
+	AuditInvitationDeclined = "organization_invitation.declined"
+	AuditInvitationRevoked  = "organization_invitation.revoked"
+)
+
+var (
+	// ErrInvitationNotFound is returned when a token matches no invitation that can still be accepted
+	ErrInvitationNotFound = errors.New("Invitation not found or expired")
+	// ErrInvitationEmailTaken is returned when the invited email already belongs to an account
+	ErrInvitationEmailTaken = errors.New("User with this email already exists")
+)
+
+// InvitationService invites people to join an organization by email. Invitees set their own
+// password when accepting, so no credentials are ever emailed.
+type InvitationService struct {
+	db                *gorm.DB
+	emailQueueService *EmailQueueService
+	cfg               config.InvitationConfig
+}
+
+// NewInvitationService creates a new invitation service
+func NewInvitationService(cfg *config.Config) *InvitationService {
+	return &InvitationService{
+		db:                database.DB,
+		emailQueueService: NewEmailQueueService(cfg),
+		cfg:               cfg.Invitation,
+	}
+}
+
+// Invite invites an email address to join an organization with a staff or manager role and emails
+// the invitation link. Pending invitations of the same address to the organization are revoked,
+// so only the latest link works.
+func (s *InvitationService) Invite(ctx context.Context, orgID, inviterID uuid.UUID, req *models.CreateInvitationRequest) (*models.OrganizationInvitation, error) {
+	db := s.db.WithContext(ctx)
+	email := strings.ToLower(req.Email)
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Organization not found")
+		}
+		return nil, err
+	}
+
+	var inviter models.User
+	if err := db.First(&inviter, "id = ?", inviterID).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.checkEmailAvailable(db, email); err != nil {
+		return nil, err
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", req.RoleName).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role '%s' not found", req.RoleName)
+		}
+		return nil, err
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := models.OrganizationInvitation{
+		OrganizationID: orgID,
+		Email:          email,
+		FirstName:      req.FirstName,
+		LastName:       req.LastName,
+		RoleName:       role.Name,
+		TokenHash:      utils.HashToken(token),
+		Status:         models.InvitationStatusPending,
+		InvitedBy:      inviterID,
+		ExpiresAt:      time.Now().Add(s.cfg.TTL),
+	}
+
+	err = database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		if err := tx.Model(&models.OrganizationInvitation{}).
+			Where("organization_id = ? AND email = ? AND status = ?", orgID, email, models.InvitationStatusPending).
+			Update("status", models.InvitationStatusRevoked).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&invitation).Error; err != nil {
+			return err
+		}
+		if err := writeAuditLog(tx, &inviterID, AuditInvitationCreated, "organization_invitation", invitation.ID.String(), &orgID, map[string]interface{}{
+			"email":      invitation.Email,
+			"role_name":  invitation.RoleName,
+			"expires_at": invitation.ExpiresAt,
+		}); err != nil {
+			return err
+		}
+
+		database.AfterCommit(ctx, func() {
+			inviterName := strings.TrimSpace(inviter.FirstName + " " + inviter.LastName)
+			if inviterName == "" {
+				inviterName = org.Name
+			}
+			if err := s.emailQueueService.QueueOrganizationInvitationEmail(&invitation, &org, inviterName,
+				s.invitationLink(token, ""), s.invitationLink(token, "decline")); err != nil {
+				log.Printf("Failed to queue organization invitation email: Invitation=%s, Error=%v", invitation.ID, err)
+			}
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &invitation, nil
+}
+
+// ListInvitations returns an organization's invitations, newest first, optionally limited to one status
+func (s *InvitationService) ListInvitations(ctx context.Context, orgID uuid.UUID, status string) ([]models.OrganizationInvitation, error) {
+	query := s.db.WithContext(ctx).Where("organization_id = ?", orgID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var invitations []models.OrganizationInvitation
+	if err := query.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		return nil, err
+	}
+	return invitations, nil
+}
+
+// RevokeInvitation withdraws a pending invitation so its link no longer works
+func (s *InvitationService) RevokeInvitation(ctx context.Context, orgID, invitationID, actorID uuid.UUID) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&invitation, "id = ? AND organization_id = ?", invitationID, orgID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvitationNotFound
+			}
+			return err
+		}
+		if invitation.Status != models.InvitationStatusPending {
+			return fmt.Errorf("Invitation is already %s", invitation.Status)
+		}
+
+		invitation.Status = models.InvitationStatusRevoked
+		if err := tx.Model(&invitation).Update("status", invitation.Status).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &actorID, AuditInvitationRevoked, "organization_invitation", invitation.ID.String(), &orgID, map[string]interface{}{
+			"email": invitation.Email,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// PreviewInvitation returns what an open invitation offers, for the page the emailed link opens
+func (s *InvitationService) PreviewInvitation(ctx context.Context, token string) (*models.InvitationPreview, error) {
+	var invitation models.OrganizationInvitation
+	err := s.db.WithContext(ctx).Preload("Organization").
+		Where("token_hash = ? AND status = ? AND expires_at > ?", utils.HashToken(token), models.InvitationStatusPending, time.Now()).
+		First(&invitation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	preview := &models.InvitationPreview{
+		Email:     invitation.Email,
+		FirstName: invitation.FirstName,
+		LastName:  invitation.LastName,
+		RoleName:  invitation.RoleName,
+		ExpiresAt: invitation.ExpiresAt,
+	}
+	if invitation.Organization != nil {
+		preview.OrganizationName = invitation.Organization.Name
+	}
+	return preview, nil
+}
+
+// AcceptInvitation creates the invitee's account with the password they chose, verified since the
+// invitation proves they own the email, and adds it to the organization with the invited role
+func (s *InvitationService) AcceptInvitation(ctx context.Context, token string, req *models.AcceptInvitationRequest) (*models.UserResponse, error) {
+	var user models.User
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		invitation, err := s.lockOpenInvitation(tx, token)
+		if err != nil {
+			return err
+		}
+		if err := s.checkEmailAvailable(tx, invitation.Email); err != nil {
+			return err
+		}
+
+		var role models.Role
+		if err := tx.Where("name = ?", invitation.RoleName).First(&role).Error; err != nil {
+			return fmt.Errorf("role '%s' not found: %w", invitation.RoleName, err)
+		}
+
+		user = models.User{
+			Email:           invitation.Email,
+			FirstName:       invitation.FirstName,
+			LastName:        invitation.LastName,
+			OrganizationID:  &invitation.OrganizationID,
+			CreatedBy:       &invitation.InvitedBy,
+			IsEmailVerified: true,
+		}
+		if req.FirstName != "" {
+			user.FirstName = req.FirstName
+		}
+		if req.LastName != "" {
+			user.LastName = req.LastName
+		}
+		if err := user.HashPassword(req.Password); err != nil {
+			return err
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Association("Roles").Append(&role); err != nil {
+			return err
+		}
+		if err := addOrganizationMember(tx, invitation.OrganizationID, user.ID, role.Name, &invitation.InvitedBy); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(invitation).Updates(map[string]interface{}{
+			"status":           models.InvitationStatusAccepted,
+			"accepted_user_id": user.ID,
+			"accepted_at":      now,
+		}).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &user.ID, AuditInvitationAccepted, "organization_invitation", invitation.ID.String(), &invitation.OrganizationID, map[string]interface{}{
+			"email":     invitation.Email,
+			"role_name": invitation.RoleName,
+			"user_id":   user.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Preload("Roles").Preload("Organization").First(&user, user.ID).Error; err != nil {
+		return nil, err
+	}
+	resp := user.ToResponse()
+	return &resp, nil
+}
+
+// DeclineInvitation lets the invitee turn down an invitation; its link stops working
+func (s *InvitationService) DeclineInvitation(ctx context.Context, token string) error {
+	return database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		invitation, err := s.lockOpenInvitation(tx, token)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(invitation).Update("status", models.InvitationStatusDeclined).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, nil, AuditInvitationDeclined, "organization_invitation", invitation.ID.String(), &invitation.OrganizationID, map[string]interface{}{
+			"email": invitation.Email,
+		})
+	})
+}
+
+// lockOpenInvitation loads and locks the pending, unexpired invitation of a token
+func (s *InvitationService) lockOpenInvitation(tx *gorm.DB, token string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&invitation, "token_hash = ?", utils.HashToken(token)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+	if !invitation.IsOpen() {
+		return nil, ErrInvitationNotFound
+	}
+	return &invitation, nil
+}
+
+// checkEmailAvailable fails when an account already uses the email
+func (s *InvitationService) checkEmailAvailable(db *gorm.DB, email string) error {
+	var count int64
+	if err := db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrInvitationEmailTaken
+	}
+	return nil
+}
+
+// invitationLink returns the frontend link of an invitation, optionally with the action the
+// invitee chose in the email
+func (s *InvitationService) invitationLink(token, action string) string {
+	link, err := url.Parse(s.cfg.AcceptURL)
+	if err != nil {
+		link = &url.URL{Path: s.cfg.AcceptURL}
+	}
+	query := link.Query()
+	query.Set("token", token)
+	if action != "" {
+		query.Set("action", action)
+	}
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// newInvitationToken generates a random invitation token
+func newInvitationToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
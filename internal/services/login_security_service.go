@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginSecurityService tracks the devices users sign in from and alerts them about
+// logins from a new device or country
+type LoginSecurityService struct {
+	db                *gorm.DB
+	emailQueueService *EmailQueueService
+	denylist          *AccessTokenDenylistService
+	cfg               config.SecurityConfig
+}
+
+// NewLoginSecurityService creates a new login security service
+func NewLoginSecurityService(cfg *config.Config, emailQueueService *EmailQueueService) *LoginSecurityService {
+	return &LoginSecurityService{
+		db:                database.DB,
+		emailQueueService: emailQueueService,
+		denylist:          NewAccessTokenDenylistService(cfg),
+		cfg:               cfg.Security,
+	}
+}
+
+// RecordLogin records the device of a successful login and sends a security alert when the
+// device or country has not been seen for the user before. A user's first login never alerts.
+// Failures are logged, never returned, so they can't block sign-in.
+func (s *LoginSecurityService) RecordLogin(ctx context.Context, user *models.User, client *models.LoginContext) {
+	db := s.db.WithContext(ctx)
+
+	fingerprint := deviceFingerprint(client)
+	country := strings.ToUpper(strings.TrimSpace(client.Country))
+	if len(country) != 2 || country == "XX" {
+		country = "" // Unknown or anonymized
+	}
+	now := time.Now()
+
+	var known []models.UserDevice
+	if err := db.Where("user_id = ?", user.ID).Find(&known).Error; err != nil {
+		log.Printf("Failed to load user devices: User=%s, Error=%v", user.ID, err)
+		return
+	}
+
+	var device *models.UserDevice
+	knownCountry := false
+	for i := range known {
+		if known[i].Fingerprint == fingerprint {
+			device = &known[i]
+		}
+		if country != "" && known[i].Country == country {
+			knownCountry = true
+		}
+	}
+
+	newDevice := device == nil
+	if newDevice {
+		device = &models.UserDevice{
+			UserID:      user.ID,
+			Fingerprint: fingerprint,
+			FirstSeenAt: now,
+		}
+	}
+	device.UserAgent = client.UserAgent
+	device.LastIP = client.IP
+	device.LastSeenAt = now
+	if country != "" {
+		device.Country = country
+	}
+
+	if err := db.Save(device).Error; err != nil {
+		log.Printf("Failed to record user device: User=%s, Error=%v", user.ID, err)
+		return
+	}
+
+	if len(known) == 0 {
+		return
+	}
+
+	newCountry := country != "" && !knownCountry
+	if newDevice || newCountry {
+		if err := s.sendLoginAlert(user, client, country, newDevice, newCountry); err != nil {
+			log.Printf("Failed to send login alert: User=%s, Error=%v", user.ID, err)
+		}
+	}
+}
+
+// ReportUnrecognizedLogin handles the "this wasn't me" link: it revokes all of the user's
+// sessions and access tokens and requires a password reset before the next login
+func (s *LoginSecurityService) ReportUnrecognizedLogin(ctx context.Context, rawToken string) error {
+	db := s.db.WithContext(ctx)
+
+	var token models.Token
+	if err := db.Where("token_hash = ? AND type = ? AND revoked = ? AND expires_at > ?",
+		utils.HashToken(rawToken), models.SecurityAlertToken, false, time.Now()).
+		First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("Invalid or expired link")
+		}
+		return err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Revokes every refresh token and outstanding alert link of the user, including this one
+		if err := tx.Model(&models.Token{}).
+			Where("user_id = ? AND type IN ? AND revoked = ?", token.UserID, []models.TokenType{models.RefreshToken, models.SecurityAlertToken}, false).
+			Update("revoked", true).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", token.UserID).
+			Update("password_reset_required", true).Error; err != nil {
+			return err
+		}
+		// Forget the reported device so a later login from it alerts again
+		return tx.Where("user_id = ? AND fingerprint = ?", token.UserID, token.Device).
+			Delete(&models.UserDevice{}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	// The account is locked until the reset, so tokens of the unrecognized login stop working now
+	if err := s.denylist.RevokeUserTokens(ctx, token.UserID); err != nil {
+		log.Printf("Failed to revoke access tokens: User=%s, Error=%v", token.UserID, err)
+	}
+
+	recordAuditLog(db, &token.UserID, "user.login_reported", "user", token.UserID.String(), nil, map[string]interface{}{
+		"ip": token.IP,
+	})
+	log.Printf("Login reported as unrecognized, sessions revoked: User=%s, IP=%s", token.UserID, token.IP)
+	return nil
+}
+
+// ListDevices returns the devices a user has signed in from, most recent first
+func (s *LoginSecurityService) ListDevices(ctx context.Context, userID uuid.UUID) ([]models.UserDevice, error) {
+	db := s.db.WithContext(ctx)
+
+	var devices []models.UserDevice
+	if err := db.Where("user_id = ?", userID).Order("last_seen_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// sendLoginAlert emails the user about the login with a one-click link to revoke their sessions
+func (s *LoginSecurityService) sendLoginAlert(user *models.User, client *models.LoginContext, country string, newDevice, newCountry bool) error {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate alert token: %w", err)
+	}
+	rawToken := hex.EncodeToString(raw)
+
+	if err := s.db.Create(&models.Token{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(rawToken),
+		Type:      models.SecurityAlertToken,
+		ExpiresAt: time.Now().Add(s.cfg.AlertLinkTTL),
+		Device:    deviceFingerprint(client),
+		IP:        client.IP,
+	}).Error; err != nil {
+		return err
+	}
+
+	reason := "a new device"
+	switch {
+	case newDevice && newCountry:
+		reason = fmt.Sprintf("a new device in a new country (%s)", country)
+	case newCountry:
+		reason = fmt.Sprintf("a new country (%s)", country)
+	}
+
+	link := fmt.Sprintf("%s/api/v1/auth/not-me?token=%s", strings.TrimRight(s.cfg.PublicURL, "/"), url.QueryEscape(rawToken))
+	message := fmt.Sprintf("Your account was just signed in to from %s.\n\nTime: %s\nIP address: %s\nDevice: %s\n\n"+
+		"If this was you, you can ignore this email. If it wasn't, open the link below to sign out of all sessions "+
+		"and require a password reset:\n%s",
+		reason, time.Now().UTC().Format("January 2, 2006 15:04 MST"), client.IP, describeUserAgent(client.UserAgent), link)
+
+	return s.emailQueueService.QueueNotificationEmail(user.Email, user.FirstName, "New sign-in to your account", message)
+}
+
+// deviceFingerprint identifies a client by its device ID header when provided, falling back to its user agent
+func deviceFingerprint(client *models.LoginContext) string {
+	source := "ua:" + strings.TrimSpace(client.UserAgent)
+	if client.DeviceID != "" {
+		source = "id:" + strings.TrimSpace(client.DeviceID)
+	}
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:])
+}
+
+// describeUserAgent shortens a user agent for display in alert emails
+func describeUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown"
+	}
+	if len(userAgent) > 120 {
+		return userAgent[:120] + "..."
+	}
+	return userAgent
+}
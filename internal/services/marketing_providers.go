@@ -0,0 +1,185 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+)
+
+// ContactSyncProvider uploads contacts to an email marketing audience
+type ContactSyncProvider interface {
+	UpsertContact(ctx context.Context, contact *models.MarketingContact) error
+}
+
+// mailchimpDataCenterPattern matches the data centers Mailchimp API keys end with, e.g. "us21".
+// The data center becomes part of the API host, so anything else could point requests elsewhere.
+var mailchimpDataCenterPattern = regexp.MustCompile(`^[a-z]+[0-9]+$`)
+
+// NewContactSyncProvider returns the provider client for a marketing integration
+func NewContactSyncProvider(integration *models.MarketingIntegration) (ContactSyncProvider, error) {
+	// API keys and audiences are organizer-supplied, so requests only go to public addresses
+	httpClient := NewPublicHTTPClient(15 * time.Second)
+
+	switch integration.Provider {
+	case models.MarketingProviderMailchimp:
+		dataCenter, err := mailchimpDataCenter(integration.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		return &mailchimpProvider{
+			httpClient: httpClient,
+			baseURL:    fmt.Sprintf("https://%s.api.mailchimp.com/3.0", dataCenter),
+			apiKey:     integration.APIKey,
+			listID:     integration.AudienceID,
+		}, nil
+	case models.MarketingProviderBrevo:
+		listID, err := strconv.ParseInt(integration.AudienceID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("brevo list ID must be numeric: %w", err)
+		}
+		return &brevoProvider{
+			httpClient: httpClient,
+			baseURL:    "https://api.brevo.com/v3",
+			apiKey:     integration.APIKey,
+			listID:     listID,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported marketing provider: %s", integration.Provider)
+	}
+}
+
+// mailchimpDataCenter returns the data center Mailchimp API keys carry as a suffix, e.g.
+// "abc123-us21"
+func mailchimpDataCenter(apiKey string) (string, error) {
+	parts := strings.Split(apiKey, "-")
+	if len(parts) < 2 {
+		return "", errors.New("mailchimp API key is missing the data center suffix")
+	}
+	dataCenter := parts[len(parts)-1]
+	if !mailchimpDataCenterPattern.MatchString(dataCenter) {
+		return "", errors.New("mailchimp API key has an invalid data center suffix")
+	}
+	return dataCenter, nil
+}
+
+// mailchimpProvider syncs contacts to a Mailchimp audience (list)
+type mailchimpProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	listID     string
+}
+
+// UpsertContact adds or updates a list member and applies event tags
+func (p *mailchimpProvider) UpsertContact(ctx context.Context, contact *models.MarketingContact) error {
+	hash := md5.Sum([]byte(strings.ToLower(contact.Email)))
+	memberURL := fmt.Sprintf("%s/lists/%s/members/%s", p.baseURL, url.PathEscape(p.listID), hex.EncodeToString(hash[:]))
+
+	member := map[string]interface{}{
+		"email_address": contact.Email,
+		"status_if_new": "subscribed",
+		"merge_fields":  contact.Fields,
+	}
+	if err := p.do(ctx, http.MethodPut, memberURL, member); err != nil {
+		return err
+	}
+
+	if len(contact.Tags) == 0 {
+		return nil
+	}
+
+	tags := make([]map[string]string, len(contact.Tags))
+	for i, tag := range contact.Tags {
+		tags[i] = map[string]string{"name": tag, "status": "active"}
+	}
+	return p.do(ctx, http.MethodPost, memberURL+"/tags", map[string]interface{}{"tags": tags})
+}
+
+func (p *mailchimpProvider) do(ctx context.Context, method, url string, body interface{}) error {
+	req, err := newJSONRequest(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth("anystring", p.apiKey)
+	return doProviderRequest(p.httpClient, req, "mailchimp")
+}
+
+// brevoProvider syncs contacts to a Brevo contact list
+type brevoProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	listID     int64
+}
+
+// UpsertContact creates or updates a contact. Brevo has no native tags, so event
+// tags are stored in the EVENT_TAGS attribute.
+func (p *brevoProvider) UpsertContact(ctx context.Context, contact *models.MarketingContact) error {
+	attributes := make(map[string]string, len(contact.Fields)+1)
+	for key, value := range contact.Fields {
+		attributes[key] = value
+	}
+	if len(contact.Tags) > 0 {
+		attributes["EVENT_TAGS"] = strings.Join(contact.Tags, ",")
+	}
+
+	body := map[string]interface{}{
+		"email":         contact.Email,
+		"attributes":    attributes,
+		"listIds":       []int64{p.listID},
+		"updateEnabled": true,
+	}
+
+	req, err := newJSONRequest(ctx, http.MethodPost, p.baseURL+"/contacts", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("api-key", p.apiKey)
+	return doProviderRequest(p.httpClient, req, "brevo")
+}
+
+// newJSONRequest builds an HTTP request with a JSON body
+func newJSONRequest(ctx context.Context, method, url string, body interface{}) (*http.Request, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// doProviderRequest executes a request against a third-party API and turns non-2xx responses into
+// errors. Errors end up in the integration's last error shown to organizers, so they only carry
+// the status code; connection details are logged instead, and response bodies are never read.
+func doProviderRequest(client *http.Client, req *http.Request, provider string) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Printf("Marketing provider request failed: Provider=%s, Error=%v", provider, err)
+		return fmt.Errorf("%s request failed", provider)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d", provider, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,745 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskNewsletterSend sends a scheduled newsletter to its segment
+const TaskNewsletterSend = "newsletter:send"
+
+// Audit log actions for newsletters and newsletter subscriptions
+const (
+	AuditNewsletterCreated      = "newsletter.created"
+	AuditNewsletterUpdated      = "newsletter.updated"
+	AuditNewsletterScheduled    = "newsletter.scheduled"
+	AuditNewsletterCancelled    = "newsletter.cancelled"
+	AuditNewsletterDeleted      = "newsletter.deleted"
+	AuditNewsletterSubscribed   = "newsletter.subscribed"
+	AuditNewsletterUnsubscribed = "newsletter.unsubscribed"
+)
+
+var (
+	// ErrNewsletterNotFound is returned when a newsletter ID matches no newsletter
+	ErrNewsletterNotFound = errors.New("Newsletter not found")
+	// ErrNewsletterNotEditable is returned for changes to a newsletter that is sending or sent
+	ErrNewsletterNotEditable = errors.New("Newsletter has already been sent")
+	// ErrNewsletterLinkNotFound is returned for tracked links that match no link of a sent newsletter
+	ErrNewsletterLinkNotFound = errors.New("Newsletter link not found")
+)
+
+// newsletterBatchSize is how many recipients are resolved and queued at a time
+const newsletterBatchSize = 500
+
+// newsletterDefaultColor is the header color of newsletters sent under a brand without a primary color
+const newsletterDefaultColor = "#2196F3"
+
+// NewsletterSendPayload is the payload of a newsletter send job. Jobs of a newsletter that was
+// rescheduled or cancelled since no longer match its scheduled time and do nothing.
+type NewsletterSendPayload struct {
+	NewsletterID uuid.UUID `json:"newsletter_id"`
+	ScheduledAt  time.Time `json:"scheduled_at"`
+}
+
+// NewsletterService lets admins compose newsletters and send them to a segment of users now or at
+// a scheduled time. Each recipient's copy carries signed links tracking opens and clicks and
+// unsubscribing; users who unsubscribed are never sent newsletters.
+type NewsletterService struct {
+	db                *gorm.DB
+	client            *asynq.Client
+	emailQueueService *EmailQueueService
+	cfg               config.NewsletterConfig
+	tracking          bool // Opens and clicks of newsletters sent from now on are tracked
+	publicURL         string
+	defaultBrand      models.Tenant
+}
+
+// NewNewsletterService creates a new newsletter service
+func NewNewsletterService(cfg *config.Config) *NewsletterService {
+	return &NewsletterService{
+		db:                database.DB,
+		client:            asynq.NewClient(redis.QueueConnOpt(cfg)),
+		emailQueueService: NewEmailQueueService(cfg),
+		cfg:               cfg.Newsletter,
+		tracking:          cfg.EmailTracking.Enabled,
+		publicURL:         strings.TrimRight(cfg.Security.PublicURL, "/"),
+		defaultBrand: models.Tenant{
+			AppName:      cfg.App.Name,
+			SupportEmail: cfg.Tenant.SupportEmail,
+		},
+	}
+}
+
+// CreateNewsletter saves a newsletter as a draft
+func (s *NewsletterService) CreateNewsletter(ctx context.Context, actorID uuid.UUID, req *models.NewsletterRequest) (*models.Newsletter, error) {
+	if err := s.validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	newsletter := models.Newsletter{CreatedBy: actorID, Status: models.NewsletterStatusDraft}
+	applyNewsletterRequest(&newsletter, req)
+
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Create(&newsletter).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &actorID, AuditNewsletterCreated, "newsletter", newsletter.ID.String(), nil, map[string]interface{}{
+			"subject": newsletter.Subject,
+			"segment": newsletter.Segment,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &newsletter, nil
+}
+
+// ListNewsletters returns a page of newsletters, newest first
+func (s *NewsletterService) ListNewsletters(ctx context.Context, query *models.NewsletterListQuery) ([]models.Newsletter, *models.PageMeta, error) {
+	db := s.db.WithContext(ctx).Model(&models.Newsletter{})
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+
+	var newsletters []models.Newsletter
+	meta, err := database.Paginate(db.Order("created_at DESC, id"), query.PageQuery, &newsletters)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newsletters, meta, nil
+}
+
+// GetNewsletter returns a newsletter with the number of users its segment selects while it is
+// unsent, and its opens, clicks and unsubscribes once sending started
+func (s *NewsletterService) GetNewsletter(ctx context.Context, newsletterID uuid.UUID) (*models.NewsletterResponse, error) {
+	newsletter, err := s.find(ctx, newsletterID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.NewsletterResponse{Newsletter: *newsletter}
+	switch newsletter.Status {
+	case models.NewsletterStatusDraft, models.NewsletterStatusScheduled:
+		var size int64
+		if err := s.audience(s.db.WithContext(ctx), newsletter).Count(&size).Error; err != nil {
+			return nil, err
+		}
+		response.AudienceSize = &size
+	default:
+		stats, err := s.stats(ctx, newsletter)
+		if err != nil {
+			return nil, err
+		}
+		response.Stats = stats
+	}
+	return response, nil
+}
+
+// UpdateNewsletter replaces the content and segment of a newsletter that has not started sending.
+// Scheduled newsletters stay scheduled and go out with the new content.
+func (s *NewsletterService) UpdateNewsletter(ctx context.Context, actorID, newsletterID uuid.UUID, req *models.NewsletterRequest) (*models.Newsletter, error) {
+	if err := s.validate(ctx, req); err != nil {
+		return nil, err
+	}
+
+	var newsletter models.Newsletter
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := s.lockEditable(tx, newsletterID, &newsletter); err != nil {
+			return err
+		}
+
+		applyNewsletterRequest(&newsletter, req)
+		if err := tx.Select("subject", "preview_text", "content", "tenant_id", "segment", "category", "inactive_days").
+			Updates(&newsletter).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &actorID, AuditNewsletterUpdated, "newsletter", newsletter.ID.String(), nil, map[string]interface{}{
+			"subject": newsletter.Subject,
+			"segment": newsletter.Segment,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &newsletter, nil
+}
+
+// ScheduleNewsletter schedules a newsletter that has not started sending, replacing any earlier
+// schedule. Without a time it is sent right away.
+func (s *NewsletterService) ScheduleNewsletter(ctx context.Context, actorID, newsletterID uuid.UUID, req *models.ScheduleNewsletterRequest) (*models.Newsletter, error) {
+	// Scheduled times are kept to the second so send jobs match them after the database round trip
+	now := time.Now().Truncate(time.Second)
+	sendAt := now
+	if req.SendAt != nil {
+		if req.SendAt.Before(now) {
+			return nil, utils.NewValidationError("Invalid schedule", map[string]interface{}{
+				"send_at": "must be in the future",
+			})
+		}
+		sendAt = req.SendAt.Truncate(time.Second)
+	}
+
+	var newsletter models.Newsletter
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := s.lockEditable(tx, newsletterID, &newsletter); err != nil {
+			return err
+		}
+
+		newsletter.Status = models.NewsletterStatusScheduled
+		newsletter.ScheduledAt = &sendAt
+		if err := tx.Select("status", "scheduled_at").Updates(&newsletter).Error; err != nil {
+			return err
+		}
+		if err := writeAuditLog(tx, &actorID, AuditNewsletterScheduled, "newsletter", newsletter.ID.String(), nil, map[string]interface{}{
+			"scheduled_at": sendAt,
+		}); err != nil {
+			return err
+		}
+
+		// Enqueued before committing so a newsletter is never left scheduled without a job. Jobs
+		// of a rolled back schedule find the newsletter unchanged and do nothing.
+		return s.enqueueSend(newsletter.ID, sendAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &newsletter, nil
+}
+
+// CancelNewsletter takes a scheduled newsletter back to draft
+func (s *NewsletterService) CancelNewsletter(ctx context.Context, actorID, newsletterID uuid.UUID) (*models.Newsletter, error) {
+	var newsletter models.Newsletter
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := s.lockEditable(tx, newsletterID, &newsletter); err != nil {
+			return err
+		}
+		if newsletter.Status != models.NewsletterStatusScheduled {
+			return utils.NewBusinessLogicError("Newsletter is not scheduled")
+		}
+
+		newsletter.Status = models.NewsletterStatusDraft
+		newsletter.ScheduledAt = nil
+		if err := tx.Select("status", "scheduled_at").Updates(&newsletter).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &actorID, AuditNewsletterCancelled, "newsletter", newsletter.ID.String(), nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &newsletter, nil
+}
+
+// DeleteNewsletter deletes a draft
+func (s *NewsletterService) DeleteNewsletter(ctx context.Context, actorID, newsletterID uuid.UUID) error {
+	return database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		var newsletter models.Newsletter
+		if err := s.lockEditable(tx, newsletterID, &newsletter); err != nil {
+			return err
+		}
+		if newsletter.Status != models.NewsletterStatusDraft {
+			return utils.NewBusinessLogicError("Cancel the scheduled newsletter before deleting it")
+		}
+
+		if err := tx.Delete(&newsletter).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &actorID, AuditNewsletterDeleted, "newsletter", newsletter.ID.String(), nil, map[string]interface{}{
+			"subject": newsletter.Subject,
+		})
+	})
+}
+
+// SendNewsletter sends a newsletter whose scheduled time has come: every user its segment selects
+// is recorded as a recipient and queued their own copy. A send that stopped partway resumes where
+// it left off, without sending anyone a second copy.
+func (s *NewsletterService) SendNewsletter(ctx context.Context, payload NewsletterSendPayload) error {
+	db := s.db.WithContext(ctx)
+
+	var newsletter models.Newsletter
+	if err := db.First(&newsletter, "id = ?", payload.NewsletterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if newsletter.ScheduledAt == nil || !newsletter.ScheduledAt.Equal(payload.ScheduledAt) {
+		return nil
+	}
+	switch newsletter.Status {
+	case models.NewsletterStatusScheduled:
+		// Whether the newsletter is tracked is settled when sending starts, so a resumed send matches
+		newsletter.Tracked = s.tracking
+		result := db.Model(&newsletter).Where("status = ?", models.NewsletterStatusScheduled).
+			Updates(map[string]interface{}{"status": models.NewsletterStatusSending, "tracked": newsletter.Tracked})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+	case models.NewsletterStatusSending:
+		log.Printf("Resuming newsletter send: Newsletter=%s", newsletter.ID)
+	default:
+		return nil
+	}
+
+	brand := s.brand(ctx, &newsletter)
+	var users []models.User
+	err := s.audience(db, &newsletter).Select("id", "email", "first_name").
+		FindInBatches(&users, newsletterBatchSize, func(batch *gorm.DB, _ int) error {
+			return s.queueBatch(ctx, &newsletter, brand, users)
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to queue newsletter: %w", err)
+	}
+
+	var recipients int64
+	if err := db.Model(&models.NewsletterRecipient{}).
+		Where("newsletter_id = ? AND queued_at IS NOT NULL", newsletter.ID).Count(&recipients).Error; err != nil {
+		return err
+	}
+	now := time.Now()
+	if err := db.Model(&newsletter).Updates(map[string]interface{}{
+		"status":          models.NewsletterStatusSent,
+		"sent_at":         now,
+		"recipient_count": recipients,
+	}).Error; err != nil {
+		return err
+	}
+
+	log.Printf("Newsletter sent: Newsletter=%s, Recipients=%d", newsletter.ID, recipients)
+	return nil
+}
+
+// queueBatch records a batch of users as recipients and queues each one not queued yet their copy
+func (s *NewsletterService) queueBatch(ctx context.Context, newsletter *models.Newsletter, brand *models.Tenant, users []models.User) error {
+	db := s.db.WithContext(ctx)
+
+	recipients := make([]models.NewsletterRecipient, 0, len(users))
+	names := make(map[uuid.UUID]string, len(users))
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		recipients = append(recipients, models.NewsletterRecipient{NewsletterID: newsletter.ID, UserID: user.ID, Email: user.Email})
+		names[user.ID] = user.FirstName
+		userIDs = append(userIDs, user.ID)
+	}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&recipients).Error; err != nil {
+		return err
+	}
+
+	var pending []models.NewsletterRecipient
+	if err := db.Where("newsletter_id = ? AND user_id IN ? AND queued_at IS NULL", newsletter.ID, userIDs).
+		Find(&pending).Error; err != nil {
+		return err
+	}
+	for _, recipient := range pending {
+		if err := s.queueCopy(newsletter, brand, &recipient, names[recipient.UserID]); err != nil {
+			return err
+		}
+		if err := db.Model(&recipient).Update("queued_at", time.Now()).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueCopy queues a recipient's copy of a newsletter, with its links tracked through the
+// recipient's token
+func (s *NewsletterService) queueCopy(newsletter *models.Newsletter, brand *models.Tenant, recipient *models.NewsletterRecipient, name string) error {
+	token := utils.SignNewsletterToken(s.cfg.Secret, recipient.ID)
+	trackingURL := s.publicURL + "/api/v1/newsletters/t/" + token
+	unsubscribeURL := trackingURL + "/unsubscribe"
+
+	color := brand.Colors.Primary
+	if color == "" {
+		color = newsletterDefaultColor
+	}
+	content, openURL := newsletter.Content, ""
+	if newsletter.Tracked {
+		content, openURL = trackNewsletterLinks(newsletter.Content, trackingURL+"/click/"), trackingURL+"/open.gif"
+	}
+
+	emailJob := &models.EmailJob{
+		Type:         models.EmailTypeNewsletter,
+		To:           recipient.Email,
+		Subject:      newsletter.Subject,
+		TemplateFile: "newsletter.html",
+		TemplateData: map[string]interface{}{
+			"RecipientName":  name,
+			"PreviewText":    newsletter.PreviewText,
+			"Content":        content,
+			"BrandName":      brand.AppName,
+			"BrandColor":     color,
+			"SupportEmail":   brand.SupportEmail,
+			"OpenURL":        openURL,
+			"UnsubscribeURL": unsubscribeURL,
+		},
+		// One-click unsubscribing from the mail client, as large mailbox providers require of bulk senders
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+		Priority:   models.PriorityLow,
+		MaxRetries: 3,
+		UserID:     recipient.UserID.String(),
+		Metadata:   map[string]interface{}{"newsletter_id": newsletter.ID.String()},
+	}
+	emailJob.SetDefaults()
+
+	return s.emailQueueService.queueEmailJob(emailJob)
+}
+
+// RecordOpen records the first open of a recipient's copy. Nothing is recorded while email
+// tracking is disabled.
+func (s *NewsletterService) RecordOpen(ctx context.Context, token string) error {
+	recipientID, err := utils.ParseNewsletterToken(s.cfg.Secret, token)
+	if err != nil || !s.tracking {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(&models.NewsletterRecipient{}).
+		Where("id = ? AND opened_at IS NULL", recipientID).
+		Update("opened_at", time.Now()).Error
+}
+
+// RecordClick records a click on a tracked link of a recipient's copy, which also counts as an
+// open, and returns the URL the link points to. Only links of the newsletter's content can be
+// followed, so tracked links cannot redirect anywhere else. Links still redirect while email
+// tracking is disabled, without recording the click.
+func (s *NewsletterService) RecordClick(ctx context.Context, token string, link int) (string, error) {
+	recipientID, err := utils.ParseNewsletterToken(s.cfg.Secret, token)
+	if err != nil {
+		return "", ErrNewsletterLinkNotFound
+	}
+
+	db := s.db.WithContext(ctx)
+	var recipient models.NewsletterRecipient
+	if err := db.First(&recipient, "id = ?", recipientID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNewsletterLinkNotFound
+		}
+		return "", err
+	}
+	var newsletter models.Newsletter
+	if err := db.Select("id", "content").First(&newsletter, "id = ?", recipient.NewsletterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrNewsletterLinkNotFound
+		}
+		return "", err
+	}
+
+	links := newsletterLinks(newsletter.Content)
+	if link < 0 || link >= len(links) {
+		return "", ErrNewsletterLinkNotFound
+	}
+	if !s.tracking {
+		return links[link], nil
+	}
+
+	now := time.Now()
+	err = database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Create(&models.NewsletterClick{
+			NewsletterID: newsletter.ID,
+			RecipientID:  recipient.ID,
+			Link:         link,
+			URL:          links[link],
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&recipient).Where("clicked_at IS NULL").Update("clicked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&recipient).Where("opened_at IS NULL").Update("opened_at", now).Error
+	})
+	if err != nil {
+		// Losing a click must not keep the reader from the page
+		log.Printf("Failed to record newsletter click: Recipient=%s, Error=%v", recipient.ID, err)
+	}
+	return links[link], nil
+}
+
+// Unsubscribe unsubscribes the recipient of a newsletter copy from all newsletters
+func (s *NewsletterService) Unsubscribe(ctx context.Context, token string) error {
+	recipientID, err := utils.ParseNewsletterToken(s.cfg.Secret, token)
+	if err != nil {
+		return err
+	}
+
+	return database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		var recipient models.NewsletterRecipient
+		if err := tx.First(&recipient, "id = ?", recipientID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return utils.ErrInvalidNewsletterToken
+			}
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&recipient).Where("unsubscribed_at IS NULL").Update("unsubscribed_at", now).Error; err != nil {
+			return err
+		}
+		return s.setUnsubscribed(tx, recipient.UserID, &now, map[string]interface{}{
+			"newsletter_id": recipient.NewsletterID,
+		})
+	})
+}
+
+// GetPreference returns whether a user is subscribed to newsletters
+func (s *NewsletterService) GetPreference(ctx context.Context, userID uuid.UUID) (*models.NewsletterPreferenceResponse, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).Select("id", "newsletter_unsubscribed_at").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("User not found")
+		}
+		return nil, err
+	}
+	return &models.NewsletterPreferenceResponse{
+		Subscribed:     user.NewsletterUnsubscribedAt == nil,
+		UnsubscribedAt: user.NewsletterUnsubscribedAt,
+	}, nil
+}
+
+// SetPreference subscribes a user to newsletters or unsubscribes them. Newsletters already
+// queued for the user are still delivered.
+func (s *NewsletterService) SetPreference(ctx context.Context, userID uuid.UUID, subscribed bool) (*models.NewsletterPreferenceResponse, error) {
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if subscribed {
+			return s.setUnsubscribed(tx, userID, nil, nil)
+		}
+		now := time.Now()
+		return s.setUnsubscribed(tx, userID, &now, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPreference(ctx, userID)
+}
+
+// setUnsubscribed records a user unsubscribing at a time, or subscribing again when nil. Only
+// changes are recorded, so the time of the first unsubscribe is kept.
+func (s *NewsletterService) setUnsubscribed(tx *gorm.DB, userID uuid.UUID, at *time.Time, details map[string]interface{}) error {
+	query := tx.Model(&models.User{}).Where("id = ?", userID)
+	action := AuditNewsletterUnsubscribed
+	if at == nil {
+		query = query.Where("newsletter_unsubscribed_at IS NOT NULL")
+		action = AuditNewsletterSubscribed
+	} else {
+		query = query.Where("newsletter_unsubscribed_at IS NULL")
+	}
+
+	result := query.Update("newsletter_unsubscribed_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return writeAuditLog(tx, &userID, action, "user", userID.String(), nil, details)
+}
+
+// validate checks the parts of a newsletter request that depend on its segment or on other records
+func (s *NewsletterService) validate(ctx context.Context, req *models.NewsletterRequest) error {
+	fields := make(map[string]interface{})
+	if req.Segment == models.NewsletterSegmentCategoryAttendees && strings.TrimSpace(req.Category) == "" {
+		fields["category"] = "is required for the category_attendees segment"
+	}
+	if req.TenantID != nil {
+		var count int64
+		if err := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("id = ?", *req.TenantID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			fields["tenant_id"] = "does not match a tenant"
+		}
+	}
+	if len(fields) > 0 {
+		return utils.NewValidationError("Invalid newsletter", fields)
+	}
+	return nil
+}
+
+// applyNewsletterRequest copies a request onto a newsletter, dropping the settings its segment
+// does not use
+func applyNewsletterRequest(newsletter *models.Newsletter, req *models.NewsletterRequest) {
+	newsletter.Subject = req.Subject
+	newsletter.PreviewText = req.PreviewText
+	newsletter.Content = req.Content
+	newsletter.TenantID = req.TenantID
+	newsletter.Segment = req.Segment
+	newsletter.Category = ""
+	newsletter.InactiveDays = 0
+	switch req.Segment {
+	case models.NewsletterSegmentCategoryAttendees:
+		newsletter.Category = strings.TrimSpace(req.Category)
+	case models.NewsletterSegmentInactiveUsers:
+		newsletter.InactiveDays = req.InactiveDays
+	}
+}
+
+// find loads a newsletter
+func (s *NewsletterService) find(ctx context.Context, newsletterID uuid.UUID) (*models.Newsletter, error) {
+	var newsletter models.Newsletter
+	if err := s.db.WithContext(ctx).First(&newsletter, "id = ?", newsletterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNewsletterNotFound
+		}
+		return nil, err
+	}
+	return &newsletter, nil
+}
+
+// lockEditable locks a newsletter that has not started sending for update
+func (s *NewsletterService) lockEditable(tx *gorm.DB, newsletterID uuid.UUID, newsletter *models.Newsletter) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(newsletter, "id = ?", newsletterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrNewsletterNotFound
+		}
+		return err
+	}
+	if newsletter.Status != models.NewsletterStatusDraft && newsletter.Status != models.NewsletterStatusScheduled {
+		return ErrNewsletterNotEditable
+	}
+	return nil
+}
+
+// enqueueSend enqueues the job sending a newsletter at its scheduled time
+func (s *NewsletterService) enqueueSend(newsletterID uuid.UUID, sendAt time.Time) error {
+	payload, err := json.Marshal(NewsletterSendPayload{NewsletterID: newsletterID, ScheduledAt: sendAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal newsletter send job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskNewsletterSend, payload)
+	_, err = s.client.Enqueue(task,
+		asynq.Queue(redis.QueueName(TicketingQueue)),
+		asynq.ProcessAt(sendAt),
+		asynq.MaxRetry(3),
+		asynq.TaskID(fmt.Sprintf("newsletter-send-%s-%d", newsletterID, sendAt.Unix())),
+	)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to enqueue newsletter send job: %w", err)
+	}
+	return nil
+}
+
+// audience selects the users a newsletter's segment is sent to
+func (s *NewsletterService) audience(db *gorm.DB, newsletter *models.Newsletter) *gorm.DB {
+	query := db.Model(&models.User{}).
+		Where("users.deleted_at IS NULL AND users.is_email_verified = ? AND users.newsletter_unsubscribed_at IS NULL", true)
+
+	switch newsletter.Segment {
+	case models.NewsletterSegmentCategoryAttendees:
+		query = query.Where(`EXISTS (SELECT 1 FROM orders JOIN events ON events.id = orders.event_id
+			WHERE orders.user_id = users.id AND orders.status IN ? AND LOWER(events.category) = LOWER(?))`,
+			[]models.OrderStatus{models.OrderStatusPaid, models.OrderStatusPartiallyPaid}, newsletter.Category)
+	case models.NewsletterSegmentInactiveUsers:
+		inactiveAfter := s.cfg.InactiveAfter
+		if newsletter.InactiveDays > 0 {
+			inactiveAfter = time.Duration(newsletter.InactiveDays) * 24 * time.Hour
+		}
+		cutoff := time.Now().Add(-inactiveAfter)
+		query = query.Where("users.created_at < ?", cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM user_devices WHERE user_devices.user_id = users.id AND user_devices.last_seen_at >= ?)", cutoff)
+	}
+	return query
+}
+
+// brand returns the tenant a newsletter is sent under, or the default brand
+func (s *NewsletterService) brand(ctx context.Context, newsletter *models.Newsletter) *models.Tenant {
+	if newsletter.TenantID != nil {
+		var tenant models.Tenant
+		err := s.db.WithContext(ctx).Where("id = ? AND active = ?", *newsletter.TenantID, true).First(&tenant).Error
+		if err == nil {
+			return &tenant
+		}
+		log.Printf("Newsletter tenant unavailable, sending under the default brand: Newsletter=%s, Error=%v", newsletter.ID, err)
+	}
+	brand := s.defaultBrand
+	return &brand
+}
+
+// stats counts the opens, clicks and unsubscribes of a newsletter's recipients
+func (s *NewsletterService) stats(ctx context.Context, newsletter *models.Newsletter) (*models.NewsletterStats, error) {
+	db := s.db.WithContext(ctx)
+
+	var counts struct {
+		Recipients   int64
+		Opened       int64
+		Clicked      int64
+		Unsubscribed int64
+	}
+	if err := db.Model(&models.NewsletterRecipient{}).
+		Select("COUNT(*) AS recipients, COUNT(opened_at) AS opened, COUNT(clicked_at) AS clicked, COUNT(unsubscribed_at) AS unsubscribed").
+		Where("newsletter_id = ? AND queued_at IS NOT NULL", newsletter.ID).
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &models.NewsletterStats{
+		Recipients:   counts.Recipients,
+		Opened:       counts.Opened,
+		Clicked:      counts.Clicked,
+		Unsubscribed: counts.Unsubscribed,
+		Links:        []models.NewsletterLinkStats{},
+	}
+	if counts.Recipients > 0 {
+		stats.OpenRate = float64(counts.Opened) / float64(counts.Recipients)
+		stats.ClickRate = float64(counts.Clicked) / float64(counts.Recipients)
+	}
+	if err := db.Model(&models.NewsletterClick{}).
+		Select("link, url, COUNT(*) AS clicks, COUNT(DISTINCT recipient_id) AS unique_clicks").
+		Where("newsletter_id = ?", newsletter.ID).
+		Group("link, url").Order("link").
+		Scan(&stats.Links).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// newsletterLinks returns the URLs of the tracked links of newsletter content, in order
+func newsletterLinks(content string) []string {
+	matches := trackedLinkPattern.FindAllStringSubmatch(content, -1)
+	links := make([]string, 0, len(matches))
+	for _, match := range matches {
+		links = append(links, trackedLinkURL(match))
+	}
+	return links
+}
+
+// trackNewsletterLinks points each tracked link of newsletter content to the click tracker, which
+// identifies it by its position
+func trackNewsletterLinks(content, clickURL string) string {
+	link := 0
+	return trackedLinkPattern.ReplaceAllStringFunc(content, func(string) string {
+		tracked := fmt.Sprintf(`href="%s%d"`, clickURL, link)
+		link++
+		return tracked
+	})
+}
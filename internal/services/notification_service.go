@@ -0,0 +1,569 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+const (
+	// TaskNotificationSMS is the asynq task type for texting a ticket link
+	TaskNotificationSMS = "notification:sms"
+	// TaskNotificationWhatsApp is the asynq task type for sending a ticket or reminder on WhatsApp
+	TaskNotificationWhatsApp = "notification:whatsapp"
+	// TaskWhatsAppReminders queues WhatsApp reminders of events starting soon
+	TaskWhatsAppReminders = "notification:whatsapp-reminders"
+)
+
+// NotificationSMSPayload is the payload of a ticket SMS job
+type NotificationSMSPayload struct {
+	NotificationID uuid.UUID `json:"notification_id"`
+}
+
+// NotificationWhatsAppPayload is the payload of a WhatsApp message job
+type NotificationWhatsAppPayload struct {
+	NotificationID uuid.UUID `json:"notification_id"`
+}
+
+// NotificationService texts ticket links, sends tickets and event reminders on WhatsApp to users
+// who opted in, and tracks the delivery of logged emails and messages in the notification log,
+// along with the opens and clicks of tracked emails
+type NotificationService struct {
+	db                *gorm.DB
+	client            *asynq.Client
+	provider          SMSProvider
+	whatsApp          WhatsAppProvider
+	whatsAppCfg       config.WhatsAppConfig
+	tracking          config.EmailTrackingConfig
+	emailQueueService *EmailQueueService
+}
+
+// ErrEmailLinkNotFound is returned for a tracked email link that was not signed for its email
+var ErrEmailLinkNotFound = errors.New("Email link not found")
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(cfg *config.Config) *NotificationService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	provider, err := NewSMSProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: SMS delivery disabled: %v", err)
+	}
+	whatsApp, err := NewWhatsAppProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: WhatsApp delivery disabled: %v", err)
+	}
+
+	return &NotificationService{
+		db:                database.DB,
+		client:            asynq.NewClient(redisOpts),
+		provider:          provider,
+		whatsApp:          whatsApp,
+		whatsAppCfg:       cfg.WhatsApp,
+		tracking:          cfg.EmailTracking,
+		emailQueueService: NewEmailQueueService(cfg),
+	}
+}
+
+// SMSAvailable reports whether ticket links can be texted
+func (s *NotificationService) SMSAvailable() bool {
+	return s.provider != nil
+}
+
+// QueueTicketSMS texts a link to each ticket of an order to a phone number. Each text is logged
+// as queued and sent by a background job.
+func (s *NotificationService) QueueTicketSMS(order *models.Order, phone string) error {
+	if s.provider == nil {
+		return ErrSMSUnavailable
+	}
+
+	for _, ticket := range order.Tickets {
+		ticketID := ticket.ID
+		entry := &models.NotificationLog{
+			Channel:        models.NotificationChannelSMS,
+			Type:           string(models.EmailTypeTicketConfirmation),
+			Recipient:      phone,
+			OrganizationID: order.OrganizationID,
+			OrderID:        &order.ID,
+			TicketID:       &ticketID,
+		}
+		if err := s.db.Create(entry).Error; err != nil {
+			return fmt.Errorf("failed to log ticket SMS: %w", err)
+		}
+
+		payload, err := json.Marshal(NotificationSMSPayload{NotificationID: entry.ID})
+		if err != nil {
+			return fmt.Errorf("failed to marshal ticket SMS job: %w", err)
+		}
+		task := asynq.NewTask(TaskNotificationSMS, payload)
+		if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.MaxRetry(3)); err != nil {
+			s.recordAttempt(context.Background(), entry.ID, "", err)
+			return fmt.Errorf("failed to enqueue ticket SMS: %w", err)
+		}
+	}
+	return nil
+}
+
+// SendTicketSMS texts the ticket link of a logged SMS notification and records the outcome.
+// Texts already sent are not sent again.
+func (s *NotificationService) SendTicketSMS(ctx context.Context, notificationID uuid.UUID) error {
+	if s.provider == nil {
+		return ErrSMSUnavailable
+	}
+
+	db := s.db.WithContext(ctx)
+	var entry models.NotificationLog
+	if err := db.First(&entry, "id = ?", notificationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("notification %s not found: %w", notificationID, asynq.SkipRetry)
+		}
+		return err
+	}
+	if entry.Status == models.NotificationStatusSent || entry.TicketID == nil {
+		return nil
+	}
+
+	var ticket models.Ticket
+	if err := db.Preload("Event").First(&ticket, "id = ?", *entry.TicketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("ticket %s not found: %w", *entry.TicketID, asynq.SkipRetry)
+		}
+		return err
+	}
+	if ticket.Status == models.TicketStatusCancelled || ticket.Event == nil {
+		s.recordAttempt(ctx, entry.ID, "", errors.New("ticket was cancelled before the text was sent"))
+		return nil
+	}
+
+	ref, err := s.provider.Send(ctx, &SMSMessage{
+		To: entry.Recipient,
+		Body: fmt.Sprintf("Your ticket for %s on %s: %s",
+			ticket.Event.Title, ticket.Event.StartDate.Format("Jan 2, 3:04 PM"), s.emailQueueService.TicketManageURL(ticket.ID)),
+		IdempotencyKey: "ticket-sms-" + entry.ID.String(),
+	})
+	s.recordAttempt(ctx, entry.ID, ref, err)
+	if err != nil {
+		return fmt.Errorf("failed to send ticket SMS: %w", err)
+	}
+
+	log.Printf("Ticket SMS sent: Notification=%s, Ticket=%s", entry.ID, ticket.ID)
+	return nil
+}
+
+// WhatsAppAvailable reports whether tickets and reminders can be sent on WhatsApp
+func (s *NotificationService) WhatsAppAvailable() bool {
+	return s.whatsApp != nil
+}
+
+// GetWhatsAppOptIn returns whether a user opted in to WhatsApp messages, and on which number
+func (s *NotificationService) GetWhatsAppOptIn(ctx context.Context, userID uuid.UUID) (*models.WhatsAppOptInResponse, error) {
+	var user models.User
+	if err := s.db.WithContext(ctx).First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("User not found")
+		}
+		return nil, err
+	}
+	return whatsAppOptIn(&user), nil
+}
+
+// OptInWhatsApp records a user's consent to receive their tickets and event reminders on WhatsApp
+// at a phone number. Opting in again replaces the number.
+func (s *NotificationService) OptInWhatsApp(ctx context.Context, userID uuid.UUID, phone string) (*models.WhatsAppOptInResponse, error) {
+	if s.whatsApp == nil {
+		return nil, ErrWhatsAppUnavailable
+	}
+
+	// Updating from the struct keeps the number going through the encrypted serializer
+	now := time.Now()
+	user := models.User{ID: userID, WhatsAppPhone: phone, WhatsAppOptInAt: &now}
+	result := s.db.WithContext(ctx).Model(&user).Select("whats_app_phone", "whats_app_opt_in_at").Updates(&user)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to record WhatsApp opt-in: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("User not found")
+	}
+
+	recordAuditLog(s.db.WithContext(ctx), &userID, "whatsapp.opt_in", "user", userID.String(), nil, nil)
+	return &models.WhatsAppOptInResponse{OptedIn: true, Phone: phone, OptedInAt: &now}, nil
+}
+
+// OptOutWhatsApp withdraws a user's WhatsApp consent. Messages already queued are not sent.
+func (s *NotificationService) OptOutWhatsApp(ctx context.Context, userID uuid.UUID) error {
+	user := models.User{ID: userID}
+	err := s.db.WithContext(ctx).Model(&user).Select("whats_app_phone", "whats_app_opt_in_at").Updates(&user).Error
+	if err != nil {
+		return fmt.Errorf("failed to record WhatsApp opt-out: %w", err)
+	}
+
+	recordAuditLog(s.db.WithContext(ctx), &userID, "whatsapp.opt_out", "user", userID.String(), nil, nil)
+	return nil
+}
+
+// QueueTicketWhatsApp sends each ticket of an order on WhatsApp when the buyer's account opted in.
+// Each message is logged as queued and sent by a background job.
+func (s *NotificationService) QueueTicketWhatsApp(order *models.Order) error {
+	if s.whatsApp == nil || order.UserID == nil {
+		return nil
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", *order.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if user.WhatsAppOptInAt == nil {
+		return nil
+	}
+
+	for _, ticket := range order.Tickets {
+		ticketID := ticket.ID
+		err := s.queueWhatsApp(&models.NotificationLog{
+			Channel:        models.NotificationChannelWhatsApp,
+			Type:           string(models.EmailTypeTicketConfirmation),
+			Recipient:      user.WhatsAppPhone,
+			UserID:         &user.ID,
+			OrganizationID: order.OrganizationID,
+			OrderID:        &order.ID,
+			TicketID:       &ticketID,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// QueueEventReminders queues a WhatsApp reminder for each paid order of an event starting within
+// the reminder lead time whose buyer opted in. Orders already reminded are skipped, so every sweep
+// can cover the whole window.
+func (s *NotificationService) QueueEventReminders(ctx context.Context) error {
+	if s.whatsApp == nil {
+		return nil
+	}
+
+	now := time.Now()
+	var orders []struct {
+		ID             uuid.UUID
+		OrganizationID *uuid.UUID
+		UserID         uuid.UUID
+	}
+	err := s.db.WithContext(ctx).Table("orders").
+		Select("orders.id, orders.organization_id, orders.user_id").
+		Joins("JOIN events ON events.id = orders.event_id").
+		Joins("JOIN users ON users.id = orders.user_id").
+		Where("events.start_date > ? AND events.start_date <= ? AND events.status IN ?", now, now.Add(s.whatsAppCfg.ReminderLead), []models.EventStatus{models.EventStatusPublished, models.EventStatusPaused}).
+		Where("orders.status IN ?", []models.OrderStatus{models.OrderStatusPaid, models.OrderStatusPartiallyPaid}).
+		Where("users.whats_app_opt_in_at IS NOT NULL AND users.deleted_at IS NULL").
+		Where("NOT EXISTS (SELECT 1 FROM notification_logs WHERE notification_logs.order_id = orders.id AND notification_logs.channel = ? AND notification_logs.type = ?)",
+			models.NotificationChannelWhatsApp, models.EmailTypeEventReminder).
+		Scan(&orders).Error
+	if err != nil {
+		return fmt.Errorf("failed to find orders to remind: %w", err)
+	}
+
+	queued := 0
+	for _, order := range orders {
+		var user models.User
+		if err := s.db.WithContext(ctx).First(&user, "id = ?", order.UserID).Error; err != nil {
+			return err
+		}
+		orderID := order.ID
+		err := s.queueWhatsApp(&models.NotificationLog{
+			Channel:        models.NotificationChannelWhatsApp,
+			Type:           string(models.EmailTypeEventReminder),
+			Recipient:      user.WhatsAppPhone,
+			UserID:         &user.ID,
+			OrganizationID: order.OrganizationID,
+			OrderID:        &orderID,
+		})
+		if err != nil {
+			return err
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		log.Printf("WhatsApp event reminders queued: %d", queued)
+	}
+	return nil
+}
+
+// SendWhatsApp sends a logged WhatsApp ticket or reminder from its approved template and records
+// the outcome. Messages already sent are not sent again, and nothing is sent to users who opted
+// out since the message was queued.
+func (s *NotificationService) SendWhatsApp(ctx context.Context, notificationID uuid.UUID) error {
+	if s.whatsApp == nil {
+		return ErrWhatsAppUnavailable
+	}
+
+	db := s.db.WithContext(ctx)
+	var entry models.NotificationLog
+	if err := db.First(&entry, "id = ?", notificationID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("notification %s not found: %w", notificationID, asynq.SkipRetry)
+		}
+		return err
+	}
+	if entry.Status == models.NotificationStatusSent || entry.UserID == nil {
+		return nil
+	}
+
+	var user models.User
+	if err := db.First(&user, "id = ?", *entry.UserID).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if user.WhatsAppOptInAt == nil {
+		s.recordAttempt(ctx, entry.ID, "", errors.New("recipient opted out of WhatsApp messages"))
+		return nil
+	}
+
+	msg, err := s.whatsAppMessage(ctx, &entry)
+	if err != nil {
+		return err
+	}
+	if msg == nil {
+		return nil
+	}
+
+	ref, err := s.whatsApp.SendTemplate(ctx, msg)
+	s.recordAttempt(ctx, entry.ID, ref, err)
+	if err != nil {
+		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+	}
+
+	log.Printf("WhatsApp message sent: Notification=%s, Type=%s", entry.ID, entry.Type)
+	return nil
+}
+
+// whatsAppMessage fills the approved template of a logged WhatsApp notification. A nil message
+// means there is nothing left to send, which is recorded as a failed attempt.
+func (s *NotificationService) whatsAppMessage(ctx context.Context, entry *models.NotificationLog) (*WhatsAppTemplateMessage, error) {
+	db := s.db.WithContext(ctx)
+	msg := &WhatsAppTemplateMessage{To: entry.Recipient, Language: s.whatsAppCfg.TemplateLanguage}
+
+	switch {
+	case entry.Type == string(models.EmailTypeTicketConfirmation) && entry.TicketID != nil:
+		var ticket models.Ticket
+		if err := db.Preload("Event").First(&ticket, "id = ?", *entry.TicketID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("ticket %s not found: %w", *entry.TicketID, asynq.SkipRetry)
+			}
+			return nil, err
+		}
+		if ticket.Status == models.TicketStatusCancelled || ticket.Event == nil {
+			s.recordAttempt(ctx, entry.ID, "", errors.New("ticket was cancelled before the message was sent"))
+			return nil, nil
+		}
+		msg.Template = s.whatsAppCfg.TicketTemplate
+		msg.Params = []string{ticket.Event.Title, ticket.Event.StartDate.Format("Jan 2, 3:04 PM"), s.emailQueueService.TicketManageURL(ticket.ID)}
+
+	case entry.Type == string(models.EmailTypeEventReminder) && entry.OrderID != nil:
+		var order models.Order
+		if err := db.Preload("Event").First(&order, "id = ?", *entry.OrderID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("order %s not found: %w", *entry.OrderID, asynq.SkipRetry)
+			}
+			return nil, err
+		}
+		if order.Event == nil || order.Event.Status == models.EventStatusCancelled ||
+			(order.Status != models.OrderStatusPaid && order.Status != models.OrderStatusPartiallyPaid) {
+			s.recordAttempt(ctx, entry.ID, "", errors.New("order or event was cancelled before the reminder was sent"))
+			return nil, nil
+		}
+		// Template placeholders cannot be left empty
+		location := order.Event.Location
+		if location == "" {
+			location = "TBA"
+		}
+		msg.Template = s.whatsAppCfg.ReminderTemplate
+		msg.Params = []string{order.Event.Title, order.Event.StartDate.Format("Jan 2, 3:04 PM"), location}
+
+	default:
+		return nil, fmt.Errorf("unsupported WhatsApp notification %s: %w", entry.Type, asynq.SkipRetry)
+	}
+	return msg, nil
+}
+
+// RecordEmailDelivery records the outcome of an attempt to send a logged email
+func (s *NotificationService) RecordEmailDelivery(ctx context.Context, notificationID uuid.UUID, sendErr error) {
+	s.recordAttempt(ctx, notificationID, "", sendErr)
+}
+
+// RecordEmailOpen counts an open of a tracked email. Nothing is recorded while email tracking is
+// disabled.
+func (s *NotificationService) RecordEmailOpen(ctx context.Context, token string) error {
+	notificationID, err := utils.ParseEmailTrackingToken(s.tracking.Secret, token)
+	if err != nil || !s.tracking.Enabled {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(&models.NotificationLog{}).
+		Where("id = ? AND tracked = ?", notificationID, true).
+		Updates(map[string]interface{}{
+			"opens":     gorm.Expr("opens + 1"),
+			"opened_at": gorm.Expr("COALESCE(opened_at, ?)", time.Now()),
+		}).Error
+}
+
+// RecordEmailClick counts a click on a tracked link of an email, which also counts as an open, and
+// returns the URL the link points to. Only links signed for the email are followed, so tracked
+// links cannot redirect anywhere else. Links still redirect while email tracking is disabled,
+// without recording the click.
+func (s *NotificationService) RecordEmailClick(ctx context.Context, token, link, signature string) (string, error) {
+	notificationID, err := utils.ParseEmailTrackingToken(s.tracking.Secret, token)
+	if err != nil || !utils.VerifyEmailLink(s.tracking.Secret, notificationID, link, signature) {
+		return "", ErrEmailLinkNotFound
+	}
+	if !s.tracking.Enabled {
+		return link, nil
+	}
+
+	now := time.Now()
+	err = s.db.WithContext(ctx).Model(&models.NotificationLog{}).
+		Where("id = ? AND tracked = ?", notificationID, true).
+		Updates(map[string]interface{}{
+			"clicks":     gorm.Expr("clicks + 1"),
+			"clicked_at": gorm.Expr("COALESCE(clicked_at, ?)", now),
+			"opened_at":  gorm.Expr("COALESCE(opened_at, ?)", now),
+		}).Error
+	if err != nil {
+		// Losing a click must not keep the reader from the page
+		log.Printf("Failed to record email click: Notification=%s, Error=%v", notificationID, err)
+	}
+	return link, nil
+}
+
+// OrganizationStats counts the delivery, opens and clicks of the notifications logged for an
+// organization's orders, per channel and type
+func (s *NotificationService) OrganizationStats(ctx context.Context, orgID uuid.UUID, filter *models.NotificationStatsFilter) (*models.NotificationStats, error) {
+	from := time.Time{}
+	to := time.Now()
+	if filter.From != "" {
+		from, _ = time.Parse("2006-01-02", filter.From)
+	}
+	if filter.To != "" {
+		day, _ := time.Parse("2006-01-02", filter.To)
+		to = day.Add(24 * time.Hour)
+	}
+	if !to.After(from) {
+		return nil, errors.New("From date must not be after to date")
+	}
+
+	stats := &models.NotificationStats{
+		OrganizationID: orgID,
+		From:           filter.From,
+		To:             filter.To,
+		Types:          []models.NotificationTypeStats{},
+	}
+	err := s.db.WithContext(ctx).Model(&models.NotificationLog{}).
+		Select(`channel, type, COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE status = ?) AS sent,
+			COUNT(*) FILTER (WHERE status = ?) AS failed,
+			COUNT(*) FILTER (WHERE tracked) AS tracked,
+			COUNT(*) FILTER (WHERE tracked AND opened_at IS NOT NULL) AS opened,
+			COUNT(*) FILTER (WHERE tracked AND clicked_at IS NOT NULL) AS clicked`,
+			models.NotificationStatusSent, models.NotificationStatusFailed).
+		Where("organization_id = ? AND created_at >= ? AND created_at < ?", orgID, from, to).
+		Group("channel, type").Order("channel, type").
+		Scan(&stats.Types).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	for i := range stats.Types {
+		row := &stats.Types[i]
+		if row.Tracked > 0 {
+			row.OpenRate = float64(row.Opened) / float64(row.Tracked)
+			row.ClickRate = float64(row.Clicked) / float64(row.Tracked)
+		}
+	}
+	return stats, nil
+}
+
+// ListOrderNotifications returns the logged ticket emails and texts of an order, oldest first
+func (s *NotificationService) ListOrderNotifications(ctx context.Context, orderID uuid.UUID) ([]models.NotificationLog, error) {
+	entries := []models.NotificationLog{}
+	if err := s.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// queueWhatsApp logs a queued WhatsApp message and enqueues the job sending it
+func (s *NotificationService) queueWhatsApp(entry *models.NotificationLog) error {
+	if err := s.db.Create(entry).Error; err != nil {
+		return fmt.Errorf("failed to log WhatsApp message: %w", err)
+	}
+
+	payload, err := json.Marshal(NotificationWhatsAppPayload{NotificationID: entry.ID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal WhatsApp job: %w", err)
+	}
+	task := asynq.NewTask(TaskNotificationWhatsApp, payload)
+	if _, err := s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.MaxRetry(3)); err != nil {
+		s.recordAttempt(context.Background(), entry.ID, "", err)
+		return fmt.Errorf("failed to enqueue WhatsApp message: %w", err)
+	}
+	return nil
+}
+
+// whatsAppOptIn describes a user's WhatsApp opt-in
+func whatsAppOptIn(user *models.User) *models.WhatsAppOptInResponse {
+	if user.WhatsAppOptInAt == nil {
+		return &models.WhatsAppOptInResponse{}
+	}
+	return &models.WhatsAppOptInResponse{OptedIn: true, Phone: user.WhatsAppPhone, OptedInAt: user.WhatsAppOptInAt}
+}
+
+func (s *NotificationService) recordAttempt(ctx context.Context, notificationID uuid.UUID, providerRef string, sendErr error) {
+	recordNotificationAttempt(s.db.WithContext(ctx), notificationID, providerRef, sendErr)
+}
+
+// logNotification adds a queued notification to the log. Delivery goes ahead untracked when the
+// log cannot be written, so failures are logged and nil is returned.
+func logNotification(db *gorm.DB, entry *models.NotificationLog) *uuid.UUID {
+	if err := db.Create(entry).Error; err != nil {
+		log.Printf("Failed to log notification: Channel=%s, Error=%v", entry.Channel, err)
+		return nil
+	}
+	return &entry.ID
+}
+
+// recordNotificationAttempt counts a delivery attempt and stores its outcome. A later successful
+// attempt turns a failed notification into a sent one. Failures to record are logged, never returned.
+func recordNotificationAttempt(db *gorm.DB, notificationID uuid.UUID, providerRef string, sendErr error) {
+	updates := map[string]interface{}{"attempts": gorm.Expr("attempts + 1")}
+	if sendErr != nil {
+		updates["status"] = models.NotificationStatusFailed
+		updates["last_error"] = sendErr.Error()
+	} else {
+		updates["status"] = models.NotificationStatusSent
+		updates["last_error"] = ""
+		updates["sent_at"] = time.Now()
+		if providerRef != "" {
+			updates["provider_ref"] = providerRef
+		}
+	}
+
+	err := db.Model(&models.NotificationLog{}).
+		Where("id = ? AND status <> ?", notificationID, models.NotificationStatusSent).
+		Updates(updates).Error
+	if err != nil {
+		log.Printf("Failed to record notification delivery: Notification=%s, Error=%v", notificationID, err)
+	}
+}
@@ -0,0 +1,366 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// oauthProfile is the account a provider says signed in
+type oauthProfile struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	FirstName     string
+	LastName      string
+}
+
+// oauthProvider runs the authorization code flow of a social login provider
+type oauthProvider interface {
+	// AuthURL returns the provider page the user signs in on
+	AuthURL(redirectURI, state, nonce string) string
+	// Exchange redeems the code the provider redirected back with for the signed-in account.
+	// The callback's form carries what some providers only send to the browser.
+	Exchange(ctx context.Context, redirectURI, code, nonce string, form url.Values) (*oauthProfile, error)
+}
+
+// newOAuthProviders returns the social login providers whose credentials are configured
+func newOAuthProviders(cfg *config.OAuthConfig) map[models.OAuthProvider]oauthProvider {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	providers := make(map[models.OAuthProvider]oauthProvider)
+
+	if cfg.GoogleClientID != "" && cfg.GoogleClientSecret != "" {
+		providers[models.OAuthProviderGoogle] = &googleOAuthProvider{
+			httpClient:   httpClient,
+			clientID:     cfg.GoogleClientID,
+			clientSecret: cfg.GoogleClientSecret,
+		}
+	}
+	if cfg.FacebookAppID != "" && cfg.FacebookAppSecret != "" {
+		providers[models.OAuthProviderFacebook] = &facebookOAuthProvider{
+			httpClient: httpClient,
+			appID:      cfg.FacebookAppID,
+			appSecret:  cfg.FacebookAppSecret,
+		}
+	}
+	if cfg.AppleClientID != "" && cfg.AppleTeamID != "" && cfg.AppleKeyID != "" && cfg.AppleKeyFile != "" {
+		key, err := loadAppleOAuthKey(cfg.AppleKeyFile)
+		if err != nil {
+			log.Printf("Sign in with Apple disabled: %v", err)
+		} else {
+			providers[models.OAuthProviderApple] = &appleOAuthProvider{
+				httpClient: httpClient,
+				clientID:   cfg.AppleClientID,
+				teamID:     cfg.AppleTeamID,
+				keyID:      cfg.AppleKeyID,
+				key:        key,
+			}
+		}
+	}
+	return providers
+}
+
+// googleOAuthProvider signs users in with their Google account through OpenID Connect
+type googleOAuthProvider struct {
+	httpClient   *http.Client
+	clientID     string
+	clientSecret string
+}
+
+func (p *googleOAuthProvider) AuthURL(redirectURI, state, nonce string) string {
+	return "https://accounts.google.com/o/oauth2/v2/auth?" + url.Values{
+		"client_id":     {p.clientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {"openid email profile"},
+		"state":         {state},
+		"nonce":         {nonce},
+		"prompt":        {"select_account"},
+	}.Encode()
+}
+
+func (p *googleOAuthProvider) Exchange(ctx context.Context, redirectURI, code, nonce string, form url.Values) (*oauthProfile, error) {
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	err := postOAuthForm(ctx, p.httpClient, "https://oauth2.googleapis.com/token", url.Values{
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {redirectURI},
+	}, &token, "google")
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := idTokenClaims(token.IDToken, []string{"https://accounts.google.com", "accounts.google.com"}, p.clientID, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("google: %w", err)
+	}
+	return &oauthProfile{
+		Subject:       claimString(claims, "sub"),
+		Email:         claimString(claims, "email"),
+		EmailVerified: claimBool(claims, "email_verified"),
+		FirstName:     claimString(claims, "given_name"),
+		LastName:      claimString(claims, "family_name"),
+	}, nil
+}
+
+// facebookOAuthProvider signs users in with their Facebook account through the Graph API
+type facebookOAuthProvider struct {
+	httpClient *http.Client
+	appID      string
+	appSecret  string
+}
+
+// facebookGraphURL is the Graph API version social login is built against
+const facebookGraphURL = "https://graph.facebook.com/v19.0"
+
+func (p *facebookOAuthProvider) AuthURL(redirectURI, state, nonce string) string {
+	return "https://www.facebook.com/v19.0/dialog/oauth?" + url.Values{
+		"client_id":     {p.appID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {"email,public_profile"},
+		"state":         {state},
+	}.Encode()
+}
+
+func (p *facebookOAuthProvider) Exchange(ctx context.Context, redirectURI, code, nonce string, form url.Values) (*oauthProfile, error) {
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	err := postOAuthForm(ctx, p.httpClient, facebookGraphURL+"/oauth/access_token", url.Values{
+		"client_id":     {p.appID},
+		"client_secret": {p.appSecret},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+	}, &token, "facebook")
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, facebookGraphURL+"/me?"+url.Values{
+		"fields":       {"id,email,first_name,last_name"},
+		"access_token": {token.AccessToken},
+	}.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	var me struct {
+		ID        string `json:"id"`
+		Email     string `json:"email"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	}
+	if err := doOAuthRequest(p.httpClient, req, &me, "facebook"); err != nil {
+		return nil, err
+	}
+
+	// Facebook only shares email addresses its users have confirmed
+	return &oauthProfile{
+		Subject:       me.ID,
+		Email:         me.Email,
+		EmailVerified: me.Email != "",
+		FirstName:     me.FirstName,
+		LastName:      me.LastName,
+	}, nil
+}
+
+// appleOAuthProvider signs users in with their Apple ID. Apple posts the callback as a form and
+// sends the user's name only there, and only the first time they sign in.
+type appleOAuthProvider struct {
+	httpClient *http.Client
+	clientID   string
+	teamID     string
+	keyID      string
+	key        *ecdsa.PrivateKey
+}
+
+// appleIssuer is the issuer of Apple ID tokens and the audience of client secrets
+const appleIssuer = "https://appleid.apple.com"
+
+func (p *appleOAuthProvider) AuthURL(redirectURI, state, nonce string) string {
+	return appleIssuer + "/auth/authorize?" + url.Values{
+		"client_id":     {p.clientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"response_mode": {"form_post"},
+		"scope":         {"name email"},
+		"state":         {state},
+		"nonce":         {nonce},
+	}.Encode()
+}
+
+func (p *appleOAuthProvider) Exchange(ctx context.Context, redirectURI, code, nonce string, form url.Values) (*oauthProfile, error) {
+	secret, err := p.clientSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	err = postOAuthForm(ctx, p.httpClient, appleIssuer+"/auth/token", url.Values{
+		"client_id":     {p.clientID},
+		"client_secret": {secret},
+		"code":          {code},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {redirectURI},
+	}, &token, "apple")
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := idTokenClaims(token.IDToken, []string{appleIssuer}, p.clientID, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("apple: %w", err)
+	}
+	profile := &oauthProfile{
+		Subject:       claimString(claims, "sub"),
+		Email:         claimString(claims, "email"),
+		EmailVerified: claimBool(claims, "email_verified"),
+	}
+
+	var user struct {
+		Name struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		} `json:"name"`
+	}
+	if raw := form.Get("user"); raw != "" && json.Unmarshal([]byte(raw), &user) == nil {
+		profile.FirstName = user.Name.FirstName
+		profile.LastName = user.Name.LastName
+	}
+	return profile, nil
+}
+
+// clientSecret signs the short-lived JWT Apple takes in place of a client secret
+func (p *appleOAuthProvider) clientSecret() (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.RegisteredClaims{
+		Issuer:    p.teamID,
+		Subject:   p.clientID,
+		Audience:  jwt.ClaimStrings{appleIssuer},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	})
+	token.Header["kid"] = p.keyID
+	secret, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign Apple client secret: %w", err)
+	}
+	return secret, nil
+}
+
+// loadAppleOAuthKey reads the Sign in with Apple private key
+func loadAppleOAuthKey(path string) (*ecdsa.PrivateKey, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Apple key: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Apple key: %w", err)
+	}
+	return key, nil
+}
+
+// idTokenClaims returns the claims of an OpenID Connect ID token after checking it was issued to
+// this client for this sign-in. The token comes straight from the provider's token endpoint over
+// TLS, which OpenID Connect accepts in place of checking its signature.
+func idTokenClaims(idToken string, issuers []string, clientID, nonce string) (jwt.MapClaims, error) {
+	if idToken == "" {
+		return nil, errors.New("no ID token returned")
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(idToken, claims); err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+
+	issuer, _ := claims.GetIssuer()
+	if !slices.Contains(issuers, issuer) {
+		return nil, fmt.Errorf("ID token issued by %q", issuer)
+	}
+	audience, _ := claims.GetAudience()
+	if !slices.Contains(audience, clientID) {
+		return nil, errors.New("ID token issued to another client")
+	}
+	expiresAt, _ := claims.GetExpirationTime()
+	if expiresAt == nil || expiresAt.Before(time.Now()) {
+		return nil, errors.New("ID token expired")
+	}
+	if claimString(claims, "nonce") != nonce {
+		return nil, errors.New("ID token nonce mismatch")
+	}
+	if claimString(claims, "sub") == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+	return claims, nil
+}
+
+// claimString returns a string claim, or "" when it is missing
+func claimString(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// claimBool returns a boolean claim; Apple sends booleans as strings
+func claimBool(claims jwt.MapClaims, name string) bool {
+	switch value := claims[name].(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
+
+// postOAuthForm posts a form to a provider's token endpoint and decodes its JSON response
+func postOAuthForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, dst interface{}, provider string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return doOAuthRequest(client, req, dst, provider)
+}
+
+// doOAuthRequest sends a request to a provider and decodes its JSON response
+func doOAuthRequest(client *http.Client, req *http.Request, dst interface{}, provider string) error {
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%s response unreadable: %w", provider, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(body[:min(len(body), 1024)])))
+	}
+	if err := json.Unmarshal(body, dst); err != nil {
+		return fmt.Errorf("%s response invalid: %w", provider, err)
+	}
+	return nil
+}
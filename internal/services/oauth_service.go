@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// oauthStateKeyPrefix prefixes the Redis keys of started sign-ins, by state
+const oauthStateKeyPrefix = "oauth:state:"
+
+var (
+	ErrOAuthProviderUnavailable = errors.New("Sign-in provider not available")
+	ErrOAuthStateInvalid        = errors.New("Sign-in expired or was already completed, please try again")
+)
+
+// oauthState is what a started sign-in remembers until the provider redirects back
+type oauthState struct {
+	Provider models.OAuthProvider `json:"provider"`
+	Nonce    string               `json:"nonce"`
+	Binding  string               `json:"binding"` // Also kept in a cookie of the browser that started the sign-in
+}
+
+// OAuthService signs users in with their Google, Facebook or Apple account. The provider account is
+// linked to the local user with the same verified email, or to a new verified user, and the user
+// gets the same token pair as a password login.
+type OAuthService struct {
+	db          *gorm.DB
+	redisClient redislib.UniversalClient
+	authService *AuthService
+	providers   map[models.OAuthProvider]oauthProvider
+	config      *config.OAuthConfig
+}
+
+// NewOAuthService creates a new OAuth service
+func NewOAuthService(cfg *config.Config) *OAuthService {
+	return &OAuthService{
+		db:          database.DB,
+		redisClient: redis.Client,
+		authService: NewAuthService(cfg),
+		providers:   newOAuthProviders(&cfg.OAuth),
+		config:      &cfg.OAuth,
+	}
+}
+
+// Start begins a sign-in with a provider and returns the provider page to send the user to, and
+// the binding to store in the browser. The callback only completes the sign-in in the browser
+// holding the binding, so a callback URL sent to someone else cannot sign them in to the
+// sender's account.
+func (s *OAuthService) Start(ctx context.Context, provider models.OAuthProvider) (authURL, binding string, err error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrOAuthProviderUnavailable
+	}
+
+	state, err := newOAuthToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := newOAuthToken()
+	if err != nil {
+		return "", "", err
+	}
+	binding, err = newOAuthToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	payload, err := json.Marshal(oauthState{Provider: provider, Nonce: nonce, Binding: binding})
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.redisClient.Set(ctx, oauthStateKeyPrefix+state, payload, s.config.StateTTL).Err(); err != nil {
+		return "", "", fmt.Errorf("failed to save sign-in state: %w", err)
+	}
+
+	return p.AuthURL(s.redirectURI(provider), state, nonce), binding, nil
+}
+
+// Callback completes a sign-in the provider redirected back from with a code, and returns the
+// user's token pair. Each started sign-in can be completed once, in the browser that started it.
+func (s *OAuthService) Callback(ctx context.Context, provider models.OAuthProvider, code, state, binding string, form url.Values, client *models.LoginContext) (*models.TokenResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrOAuthProviderUnavailable
+	}
+	if code == "" || state == "" {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	payload, err := s.redisClient.GetDel(ctx, oauthStateKeyPrefix+state).Bytes()
+	if errors.Is(err, redislib.Nil) {
+		return nil, ErrOAuthStateInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load sign-in state: %w", err)
+	}
+	var started oauthState
+	if err := json.Unmarshal(payload, &started); err != nil || started.Provider != provider {
+		return nil, ErrOAuthStateInvalid
+	}
+	if binding == "" || subtle.ConstantTimeCompare([]byte(started.Binding), []byte(binding)) != 1 {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	profile, err := p.Exchange(ctx, s.redirectURI(provider), code, started.Nonce, form)
+	if err != nil {
+		return nil, fmt.Errorf("Sign-in with %s failed: %w", provider, err)
+	}
+
+	user, err := s.linkUser(ctx, provider, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// A login reported as unrecognized locks the account until the password is reset
+	if user.PasswordResetRequired {
+		return nil, errors.New("Password reset required, please reset your password to sign in")
+	}
+
+	return s.authService.startSession(ctx, user, client)
+}
+
+// linkUser returns the local user a provider account signs in as: the user it was linked to
+// before, else the user with its verified email, else a new user. The user comes back with its
+// roles and permissions loaded.
+func (s *OAuthService) linkUser(ctx context.Context, provider models.OAuthProvider, profile *oauthProfile) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(profile.Email))
+	now := time.Now()
+
+	var userID uuid.UUID
+	var claimed bool
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		var identity models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", provider, profile.Subject).First(&identity).Error
+		switch {
+		case err == nil:
+			userID = identity.UserID
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user, wasClaimed, err := s.findOrCreateUser(tx, provider, email, profile)
+			if err != nil {
+				return err
+			}
+			claimed = wasClaimed
+			userID = user.ID
+			identity = models.UserIdentity{UserID: user.ID, Provider: provider, Subject: profile.Subject}
+		default:
+			return err
+		}
+
+		if email != "" {
+			identity.Email = email
+		}
+		identity.LastLoginAt = &now
+		return tx.Save(&identity).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		s.authService.revokeAccessTokens(ctx, userID)
+	}
+
+	var user models.User
+	if err := s.db.WithContext(ctx).Preload("Roles.Permissions").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// findOrCreateUser links a provider account seen for the first time to the user with its email,
+// or creates a user for it. Only emails the provider verified are trusted, as linking on an
+// unverified one would let anyone sign in to the account owning it.
+//
+// An unverified user with the email is claimed: whoever registered it never proved they own the
+// email, so its password is replaced and its refresh tokens revoked, and claimed is true so the
+// caller revokes its access tokens once the link is saved. The owner can set a password by
+// resetting it.
+func (s *OAuthService) findOrCreateUser(tx *gorm.DB, provider models.OAuthProvider, email string, profile *oauthProfile) (user *models.User, claimed bool, err error) {
+	if email == "" || !profile.EmailVerified {
+		return nil, false, fmt.Errorf("Your %s account has no verified email address", provider)
+	}
+
+	var existing models.User
+	err = tx.Where("email = ?", email).First(&existing).Error
+	switch {
+	case err == nil:
+		if !existing.IsEmailVerified {
+			password, err := newOAuthToken()
+			if err != nil {
+				return nil, false, err
+			}
+			if err := existing.HashPassword(password); err != nil {
+				return nil, false, err
+			}
+			if err := tx.Model(&existing).Updates(map[string]interface{}{
+				"is_email_verified": true,
+				"password_hash":     existing.PasswordHash,
+			}).Error; err != nil {
+				return nil, false, err
+			}
+			if err := revokeRefreshTokens(tx, existing.ID); err != nil {
+				return nil, false, err
+			}
+			claimed = true
+		}
+		recordAuditLog(tx, &existing.ID, "user.oauth_linked", "user", existing.ID.String(), nil, map[string]interface{}{
+			"provider": provider,
+			"claimed":  claimed,
+		})
+		return &existing, claimed, nil
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, false, err
+	}
+
+	userRole, err := defaultUserRole(tx)
+	if err != nil {
+		return nil, false, err
+	}
+	user = &models.User{
+		Email:           email,
+		FirstName:       profile.FirstName,
+		LastName:        profile.LastName,
+		IsEmailVerified: true,
+		Roles:           []*models.Role{userRole},
+	}
+
+	// The user signs in through the provider; a password can be set later by resetting it
+	password, err := newOAuthToken()
+	if err != nil {
+		return nil, false, err
+	}
+	if err := user.HashPassword(password); err != nil {
+		return nil, false, err
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return nil, false, err
+	}
+
+	recordAuditLog(tx, &user.ID, "user.oauth_registered", "user", user.ID.String(), nil, map[string]interface{}{
+		"provider": provider,
+	})
+	return user, false, nil
+}
+
+// redirectURI returns the callback URL registered with a provider
+func (s *OAuthService) redirectURI(provider models.OAuthProvider) string {
+	return strings.TrimRight(s.config.CallbackBaseURL, "/") + "/api/v1/auth/oauth/" + string(provider) + "/callback"
+}
+
+// SecureCookies reports whether the sign-in cookies can be marked Secure, as the callback is
+// served over https
+func (s *OAuthService) SecureCookies() bool {
+	return strings.HasPrefix(s.config.CallbackBaseURL, "https://")
+}
+
+// FrontendURL returns the page users are sent back to after signing in, or "" to respond with JSON
+func (s *OAuthService) FrontendURL() string {
+	return s.config.FrontendURL
+}
+
+// newOAuthToken generates a random sign-in state or nonce
+func newOAuthToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate sign-in token: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
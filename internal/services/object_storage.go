@@ -0,0 +1,162 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errObjectNotFound is returned when a bucket has no object under a key
+var errObjectNotFound = errors.New("object not found")
+
+// s3Bucket reads and writes objects of an S3-compatible bucket with path-style requests signed
+// with AWS Signature Version 4
+type s3Bucket struct {
+	httpClient      *http.Client
+	endpoint        *url.URL
+	bucket          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+func newS3Bucket(endpointURL, bucket, region, accessKeyID, secretAccessKey string) (*s3Bucket, error) {
+	endpoint, err := url.Parse(endpointURL)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", endpointURL)
+	}
+	return &s3Bucket{
+		httpClient:      &http.Client{Timeout: 5 * time.Minute},
+		endpoint:        endpoint,
+		bucket:          bucket,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+	}, nil
+}
+
+// location returns the s3:// URI of a key
+func (u *s3Bucket) location(key string) string {
+	return "s3://" + u.bucket + "/" + key
+}
+
+// Get opens the object under key for streaming; the caller closes it. The size is -1 when S3
+// does not report it.
+func (u *s3Bucket) Get(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	path := "/" + u.bucket + "/" + s3EscapePath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.endpoint.Scheme+"://"+u.endpoint.Host+path, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	u.sign(req, path, nil, time.Now().UTC())
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, resp.ContentLength, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, 0, errObjectNotFound
+	}
+	defer resp.Body.Close()
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, 0, fmt.Errorf("S3 returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
+
+// Put stores body under key, replacing any existing object
+func (u *s3Bucket) Put(ctx context.Context, key string, body []byte, contentType, contentEncoding string) error {
+	path := "/" + u.bucket + "/" + s3EscapePath(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.endpoint.Scheme+"://"+u.endpoint.Host+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", contentType)
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
+	u.sign(req, path, body, time.Now().UTC())
+
+	resp, err := u.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// sign adds the Signature Version 4 headers for a request without query parameters. Requests
+// without a body sign an empty payload.
+func (u *s3Bucket) sign(req *http.Request, path string, body []byte, now time.Time) {
+	payloadSum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payloadSum[:])
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		"", // No query string
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + u.region + "/s3/aws4_request"
+	requestSum := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestSum[:])
+
+	key := hmacSHA256([]byte("AWS4"+u.secretAccessKey), day)
+	key = hmacSHA256(key, u.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		u.accessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath percent-encodes every byte of an object key except unreserved characters and
+// the slashes between segments, as Signature Version 4 requires
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for _, c := range []byte(key) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,558 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Audit log actions for manual order adjustments
+const (
+	AuditAdjustmentRequested = "order_adjustment.requested"
+	AuditAdjustmentApproved  = "order_adjustment.approved"
+	AuditAdjustmentRejected  = "order_adjustment.rejected"
+	AuditAdjustmentApplied   = "order_adjustment.applied"
+	AuditAdjustmentFailed    = "order_adjustment.failed"
+)
+
+// refundSource identifies what money is returned for, keeping provider calls idempotent per source
+type refundSource struct {
+	key      string            // Prefix of the idempotency keys, e.g. "adjustment-<id>"
+	reason   string            // Reason passed to the provider
+	metadata map[string]string // Provider metadata, e.g. {"adjustment_id": "<id>"}
+}
+
+// adjustmentSource returns the refund source of an adjustment
+func adjustmentSource(adjustment *models.OrderAdjustment) refundSource {
+	return refundSource{
+		key:      "adjustment-" + adjustment.ID.String(),
+		reason:   adjustment.Reason,
+		metadata: map[string]string{"adjustment_id": adjustment.ID.String()},
+	}
+}
+
+// adjustmentPlan is the money movement an adjustment makes against the current order state
+type adjustmentPlan struct {
+	amount      float64  // Amount reported on the adjustment
+	refund      float64  // Portion returned to the buyer
+	newTotal    *float64 // Corrected order total, if it changes
+	closesOrder bool     // Returns everything the buyer paid for tickets, leaving nothing to insure
+}
+
+// RequestOrderAdjustment records a manual adjustment of an order. Adjustments below the approval
+// threshold are applied immediately; larger ones wait for a second admin's approval.
+func (s *OrderService) RequestOrderAdjustment(ctx context.Context, orderID uuid.UUID, adminID uuid.UUID, req *models.OrderAdjustmentRequest) (*models.OrderAdjustment, error) {
+	var order models.Order
+	if err := s.db.Preload("Event").Preload("Insurance").First(&order, "id = ?", orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	adjustment := models.OrderAdjustment{
+		OrderID:        order.ID,
+		OrganizationID: order.OrganizationID,
+		Type:           models.AdjustmentType(req.Type),
+		Amount:         req.Amount,
+		NewTotal:       req.NewTotal,
+		Currency:       order.Currency,
+		Reason:         req.Reason,
+		Status:         models.AdjustmentStatusPendingApproval,
+		RequestedBy:    adminID,
+	}
+
+	plan, err := s.planAdjustment(&order, &adjustment)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.Amount = plan.amount
+
+	requiresApproval := adjustment.Amount >= s.approvalThreshold
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&adjustment).Error; err != nil {
+			return err
+		}
+		return writeAuditLog(tx, &adminID, AuditAdjustmentRequested, "order", order.ID.String(), order.OrganizationID, map[string]interface{}{
+			"adjustment_id":     adjustment.ID,
+			"type":              adjustment.Type,
+			"amount":            adjustment.Amount,
+			"new_total":         adjustment.NewTotal,
+			"currency":          adjustment.Currency,
+			"reason":            adjustment.Reason,
+			"requires_approval": requiresApproval,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if requiresApproval {
+		log.Printf("Order adjustment awaiting approval: Adjustment=%s, Order=%s, Amount=%.2f", adjustment.ID, order.ID, adjustment.Amount)
+		return &adjustment, nil
+	}
+
+	if err := s.applyAdjustment(ctx, &adjustment, adminID); err != nil {
+		return &adjustment, err
+	}
+	return &adjustment, nil
+}
+
+// ApproveOrderAdjustment approves a pending adjustment and applies it. The approver must be a
+// different admin from the one who requested it.
+func (s *OrderService) ApproveOrderAdjustment(ctx context.Context, adjustmentID uuid.UUID, adminID uuid.UUID, note string) (*models.OrderAdjustment, error) {
+	adjustment, err := s.reviewAdjustment(ctx, adjustmentID, adminID, note, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyAdjustment(ctx, adjustment, adminID); err != nil {
+		return adjustment, err
+	}
+	return adjustment, nil
+}
+
+// RejectOrderAdjustment rejects a pending adjustment without changing the order
+func (s *OrderService) RejectOrderAdjustment(ctx context.Context, adjustmentID uuid.UUID, adminID uuid.UUID, note string) (*models.OrderAdjustment, error) {
+	return s.reviewAdjustment(ctx, adjustmentID, adminID, note, false)
+}
+
+// ListOrderAdjustments returns adjustments, optionally limited to one order and/or status
+func (s *OrderService) ListOrderAdjustments(ctx context.Context, orderID *uuid.UUID, status string) ([]models.OrderAdjustment, error) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Model(&models.OrderAdjustment{})
+	if orderID != nil {
+		query = query.Where("order_id = ?", *orderID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var adjustments []models.OrderAdjustment
+	if err := query.Order("created_at DESC").Limit(200).Find(&adjustments).Error; err != nil {
+		return nil, err
+	}
+	return adjustments, nil
+}
+
+// reviewAdjustment records a second admin's decision on a pending adjustment
+func (s *OrderService) reviewAdjustment(ctx context.Context, adjustmentID uuid.UUID, adminID uuid.UUID, note string, approve bool) (*models.OrderAdjustment, error) {
+	db := s.db.WithContext(ctx)
+
+	var adjustment models.OrderAdjustment
+	if err := db.First(&adjustment, "id = ?", adjustmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Adjustment not found")
+		}
+		return nil, err
+	}
+
+	if adjustment.Status != models.AdjustmentStatusPendingApproval {
+		return nil, fmt.Errorf("Adjustment is already %s", adjustment.Status)
+	}
+	if adjustment.RequestedBy == adminID {
+		return nil, errors.New("Adjustments must be reviewed by a different admin than the requester")
+	}
+
+	now := time.Now()
+	action := AuditAdjustmentApproved
+	updates := map[string]interface{}{"reviewed_by": adminID, "reviewed_at": now, "review_note": note}
+	if !approve {
+		action = AuditAdjustmentRejected
+		updates["status"] = models.AdjustmentStatusRejected
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&adjustment).
+			Where("status = ? AND reviewed_by IS NULL", models.AdjustmentStatusPendingApproval).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Adjustment was reviewed concurrently")
+		}
+		return writeAuditLog(tx, &adminID, action, "order", adjustment.OrderID.String(), adjustment.OrganizationID, map[string]interface{}{
+			"adjustment_id": adjustment.ID,
+			"note":          note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment.ReviewedBy = &adminID
+	adjustment.ReviewedAt = &now
+	adjustment.ReviewNote = note
+	if !approve {
+		adjustment.Status = models.AdjustmentStatusRejected
+	}
+	return &adjustment, nil
+}
+
+// applyAdjustment refunds the buyer through the provider where needed and updates the order.
+// Failures mark the adjustment failed and are recorded in the audit log.
+func (s *OrderService) applyAdjustment(ctx context.Context, adjustment *models.OrderAdjustment, actorID uuid.UUID) error {
+	var order models.Order
+	if err := s.db.Preload("Event").Preload("Insurance").First(&order, "id = ?", adjustment.OrderID).Error; err != nil {
+		return s.failAdjustment(adjustment, actorID, err)
+	}
+
+	// The order may have changed while the adjustment waited for approval
+	plan, err := s.planAdjustment(&order, adjustment)
+	if err != nil {
+		return s.failAdjustment(adjustment, actorID, err)
+	}
+
+	// Nothing is left to insure once the tickets are refunded. Cancel the policy before any money
+	// moves, so a refused cancellation leaves the order untouched; whatever premium the insurer
+	// returns is refunded with the tickets.
+	if plan.closesOrder && hasBoundInsurance(&order) {
+		premiumRefund, err := s.cancelInsurance(ctx, &order, adjustmentSource(adjustment))
+		if err != nil {
+			return s.failAdjustment(adjustment, actorID, err)
+		}
+		plan.amount = math.Round((plan.amount+premiumRefund)*100) / 100
+		plan.refund = math.Round((plan.refund+premiumRefund)*100) / 100
+	}
+
+	var refundRefs []string
+	manualRefund := plan.refund
+	if plan.refund > 0 {
+		refundRefs, manualRefund, err = s.refundPayments(ctx, &order, adjustmentSource(adjustment), plan.refund)
+		if err != nil {
+			return s.failAdjustment(adjustment, actorID, err)
+		}
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", order.ID).Error; err != nil {
+			return err
+		}
+		previousStatus, previousMethod := order.Status, order.PaymentMethod
+
+		order.RefundedAmount = math.Round((order.RefundedAmount+plan.refund)*100) / 100
+		updates := map[string]interface{}{"refunded_amount": order.RefundedAmount}
+
+		switch adjustment.Type {
+		case models.AdjustmentPartialRefund:
+			if plan.closesOrder || order.RefundedAmount >= order.TotalAmount {
+				updates["status"] = models.OrderStatusRefunded
+			}
+		case models.AdjustmentPriceCorrection:
+			updates["total_amount"] = *plan.newTotal
+		case models.AdjustmentCompConversion:
+			updates["total_amount"] = 0
+			updates["payment_method"] = models.PaymentMethodComp
+			updates["status"] = models.OrderStatusPaid
+			if order.PaidAt == nil {
+				updates["paid_at"] = now
+			}
+		}
+		if err := tx.Model(&order).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(adjustment).Updates(map[string]interface{}{
+			"status":      models.AdjustmentStatusApplied,
+			"amount":      plan.amount,
+			"refund_refs": refundRefs,
+			"applied_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, &actorID, AuditAdjustmentApplied, "order", order.ID.String(), order.OrganizationID, map[string]interface{}{
+			"adjustment_id":    adjustment.ID,
+			"type":             adjustment.Type,
+			"amount":           plan.amount,
+			"provider_refund":  math.Round((plan.refund-manualRefund)*100) / 100,
+			"manual_refund":    manualRefund,
+			"refund_refs":      refundRefs,
+			"refunded_amount":  order.RefundedAmount,
+			"new_total":        plan.newTotal,
+			"requested_by":     adjustment.RequestedBy,
+			"approved_by":      adjustment.ReviewedBy,
+			"previous_status":  previousStatus,
+			"previous_payment": previousMethod,
+		})
+	})
+	if err != nil {
+		return s.failAdjustment(adjustment, actorID, err)
+	}
+
+	adjustment.Status = models.AdjustmentStatusApplied
+	adjustment.Amount = plan.amount
+	adjustment.RefundRefs = refundRefs
+	adjustment.AppliedAt = &now
+
+	if plan.refund > 0 {
+		if order.Event != nil {
+			s.integrationService.NotifyOrderRefunded(&order, order.Event, plan.refund)
+		}
+
+		message := fmt.Sprintf("A refund of %.2f %s has been issued for your order %s.", plan.refund, order.Currency, order.ID)
+		if manualRefund > 0 {
+			message += " The organizer will contact you about the part of the refund not returned to your card."
+		}
+		if err := s.emailQueueService.QueueNotificationEmail(order.BuyerEmail, order.BuyerName, "Your refund", message); err != nil {
+			log.Printf("Failed to queue refund email: Order=%s, Error=%v", order.ID, err)
+		}
+	}
+
+	log.Printf("Order adjustment applied: Adjustment=%s, Order=%s, Type=%s, Amount=%.2f", adjustment.ID, order.ID, adjustment.Type, plan.amount)
+	return nil
+}
+
+// planAdjustment validates an adjustment against the order and works out the money it moves
+func (s *OrderService) planAdjustment(order *models.Order, adjustment *models.OrderAdjustment) (*adjustmentPlan, error) {
+	if order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusRefunded {
+		return nil, fmt.Errorf("Cannot adjust a %s order", order.Status)
+	}
+
+	refundable, err := s.refundableAmount(order)
+	if err != nil {
+		return nil, err
+	}
+
+	switch adjustment.Type {
+	case models.AdjustmentPartialRefund:
+		if adjustment.Amount <= 0 {
+			return nil, errors.New("Amount is required for a partial refund")
+		}
+		if toMinorUnits(adjustment.Amount) > toMinorUnits(refundable) {
+			return nil, fmt.Errorf("Refund exceeds the refundable amount of %.2f %s", refundable, order.Currency)
+		}
+		if err := checkRefundPolicy(order, adjustment.Amount, refundable, time.Now()); err != nil {
+			return nil, err
+		}
+		return &adjustmentPlan{
+			amount:      adjustment.Amount,
+			refund:      adjustment.Amount,
+			closesOrder: toMinorUnits(order.RefundedAmount+adjustment.Amount) >= toMinorUnits(order.TotalAmount-boundPremium(order)),
+		}, nil
+
+	case models.AdjustmentPriceCorrection:
+		if adjustment.NewTotal == nil {
+			return nil, errors.New("New total is required for a price correction")
+		}
+		if order.PaymentMethod == models.PaymentMethodInstallments {
+			return nil, errors.New("Installment orders cannot be repriced, refund the difference instead")
+		}
+		newTotal := math.Round(*adjustment.NewTotal*100) / 100
+		difference := math.Round((order.TotalAmount-newTotal)*100) / 100
+		if difference == 0 {
+			return nil, errors.New("New total is the same as the current total")
+		}
+		if difference < 0 {
+			// Raising the price only makes sense before payment is collected
+			if order.Status != models.OrderStatusPending {
+				return nil, errors.New("The price of a paid order can only be lowered")
+			}
+			return &adjustmentPlan{amount: -difference, newTotal: &newTotal}, nil
+		}
+		plan := &adjustmentPlan{amount: difference, newTotal: &newTotal}
+		if order.Status != models.OrderStatusPending {
+			if toMinorUnits(difference) > toMinorUnits(refundable) {
+				return nil, fmt.Errorf("Correction exceeds the refundable amount of %.2f %s", refundable, order.Currency)
+			}
+			plan.refund = difference
+		}
+		return plan, nil
+
+	case models.AdjustmentCompConversion:
+		if order.PaymentMethod == models.PaymentMethodComp {
+			return nil, errors.New("Order is already complimentary")
+		}
+		return &adjustmentPlan{
+			amount:      math.Round((order.TotalAmount-order.RefundedAmount-boundPremium(order))*100) / 100,
+			refund:      refundable,
+			closesOrder: true,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("Unsupported adjustment type: %s", adjustment.Type)
+}
+
+// checkRefundPolicy enforces the event's refund policy on a refund of amount out of the refundable
+// total. Price corrections and comp conversions fix the order itself and are not subject to it.
+func checkRefundPolicy(order *models.Order, amount, refundable float64, now time.Time) error {
+	if order.Event == nil {
+		return nil
+	}
+	policy := order.Event.RefundPolicy
+
+	deadline := policy.Deadline(order.Event.StartDate)
+	if deadline == nil {
+		return errors.New("Tickets for this event are not refundable")
+	}
+	if now.After(*deadline) {
+		return fmt.Errorf("Refunds for this event closed on %s", deadline.UTC().Format("2006-01-02 15:04 MST"))
+	}
+
+	if policy.FeePercent > 0 {
+		allowed := math.Floor(refundable*(100-policy.FeePercent)) / 100
+		if toMinorUnits(amount) > toMinorUnits(allowed) {
+			return fmt.Errorf("Refund exceeds the %.2f %s allowed after the event's %g%% refund fee", allowed, order.Currency, policy.FeePercent)
+		}
+	}
+	return nil
+}
+
+// refundableAmount returns how much of an order has been collected and not yet refunded
+func (s *OrderService) refundableAmount(order *models.Order) (float64, error) {
+	var collected float64
+	switch order.Status {
+	case models.OrderStatusPaid:
+		collected = order.TotalAmount
+	case models.OrderStatusPartiallyPaid:
+		if err := s.db.Model(&models.Payment{}).
+			Where("order_id = ? AND status = ?", order.ID, models.PaymentStatusSucceeded).
+			Select("COALESCE(SUM(amount), 0)").Scan(&collected).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return math.Max(0, math.Round((collected-order.RefundedAmount-boundPremium(order))*100)/100), nil
+}
+
+// boundPremium returns the insurance premium of an order whose policy is in force. It is not
+// refundable with the tickets; the insurer decides what to return when the policy is cancelled.
+func boundPremium(order *models.Order) float64 {
+	if !hasBoundInsurance(order) {
+		return 0
+	}
+	return order.InsuranceAmount
+}
+
+func hasBoundInsurance(order *models.Order) bool {
+	return order.Insurance != nil && order.Insurance.Status == models.InsuranceStatusBound
+}
+
+// cancelInsurance cancels an order's bound policy and returns the premium the insurer gives back
+func (s *OrderService) cancelInsurance(ctx context.Context, order *models.Order, source refundSource) (float64, error) {
+	if s.insurance == nil {
+		return 0, errors.New("Insurance provider is not configured, cannot cancel the order's policy")
+	}
+	insurance := order.Insurance
+
+	cancellation, err := s.insurance.Cancel(ctx, &InsuranceCancelRequest{
+		PolicyRef:      insurance.PolicyRef,
+		Reason:         source.reason,
+		IdempotencyKey: "insurance-cancel-" + source.key,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cancellation of insurance policy %s failed: %w", insurance.PolicyRef, err)
+	}
+	premiumRefund := math.Round(math.Min(math.Max(cancellation.PremiumRefund, 0), order.InsuranceAmount)*100) / 100
+
+	now := time.Now()
+	insurance.Status = models.InsuranceStatusCancelled
+	insurance.CancelledAt = &now
+	if err := s.db.Model(insurance).Updates(map[string]interface{}{
+		"status":       models.InsuranceStatusCancelled,
+		"cancelled_at": now,
+	}).Error; err != nil {
+		log.Printf("Failed to record insurance cancellation: Order=%s, Policy=%s, Error=%v", order.ID, insurance.PolicyRef, err)
+	}
+	return premiumRefund, nil
+}
+
+// refundPayments returns amount to the buyer across the order's provider charges, newest first.
+// Any part not covered by provider charges (cash, invoice) is returned as manual, to be settled offline.
+func (s *OrderService) refundPayments(ctx context.Context, order *models.Order, source refundSource, amount float64) ([]string, float64, error) {
+	var charges []models.Payment
+	if err := s.db.Where("order_id = ? AND status = ?", order.ID, models.PaymentStatusSucceeded).
+		Order("created_at DESC").Find(&charges).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var refs []string
+	remaining := toMinorUnits(amount)
+
+	for _, charge := range charges {
+		if remaining <= 0 {
+			break
+		}
+
+		var refunded float64
+		if err := s.db.Model(&models.Payment{}).
+			Where("refund_of_id = ? AND status = ?", charge.ID, models.PaymentStatusRefunded).
+			Select("COALESCE(SUM(amount), 0)").Scan(&refunded).Error; err != nil {
+			return refs, 0, err
+		}
+
+		available := toMinorUnits(charge.Amount) - toMinorUnits(refunded)
+		if available <= 0 {
+			continue
+		}
+		if s.provider == nil {
+			return refs, 0, errors.New("Payment provider is not configured, cannot refund card payments")
+		}
+
+		portion := min(available, remaining)
+		metadata := map[string]string{"order_id": order.ID.String()}
+		for k, v := range source.metadata {
+			metadata[k] = v
+		}
+		result, err := s.provider.Refund(ctx, &RefundRequest{
+			PaymentRef:     charge.ProviderRef,
+			Amount:         float64(portion) / 100,
+			Reason:         source.reason,
+			IdempotencyKey: fmt.Sprintf("%s-%s", source.key, charge.ID),
+			Metadata:       metadata,
+		})
+		if err != nil {
+			return refs, 0, fmt.Errorf("refund of payment %s failed: %w", charge.ID, err)
+		}
+
+		chargeID := charge.ID
+		if err := s.db.Create(&models.Payment{
+			OrderID:        order.ID,
+			OrganizationID: order.OrganizationID,
+			RefundOfID:     &chargeID,
+			Provider:       charge.Provider,
+			ProviderRef:    result.ProviderRef,
+			Amount:         float64(portion) / 100,
+			Currency:       charge.Currency,
+			Status:         models.PaymentStatusRefunded,
+		}).Error; err != nil {
+			log.Printf("Failed to record refund payment: Order=%s, Refund=%s, Error=%v", order.ID, result.ProviderRef, err)
+		}
+
+		refs = append(refs, result.ProviderRef)
+		remaining -= portion
+	}
+
+	return refs, float64(remaining) / 100, nil
+}
+
+// failAdjustment marks an adjustment failed and records the failure in the audit log
+func (s *OrderService) failAdjustment(adjustment *models.OrderAdjustment, actorID uuid.UUID, cause error) error {
+	adjustment.Status = models.AdjustmentStatusFailed
+	adjustment.Error = cause.Error()
+	if err := s.db.Model(adjustment).Updates(map[string]interface{}{
+		"status": models.AdjustmentStatusFailed,
+		"error":  cause.Error(),
+	}).Error; err != nil {
+		log.Printf("Failed to update adjustment: Adjustment=%s, Error=%v", adjustment.ID, err)
+	}
+
+	recordAuditLog(s.db, &actorID, AuditAdjustmentFailed, "order", adjustment.OrderID.String(), adjustment.OrganizationID, map[string]interface{}{
+		"adjustment_id": adjustment.ID,
+		"error":         cause.Error(),
+	})
+
+	return cause
+}
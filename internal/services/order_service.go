@@ -0,0 +1,732 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskOrderExpiry is the asynq task type for cancelling pending orders left unpaid
+const TaskOrderExpiry = "order:expiry"
+
+// AuditTicketsResent is the audit action of an admin resending an order's ticket emails
+const AuditTicketsResent = "order.tickets_resent"
+
+// OrderService places and manages ticket orders
+type OrderService struct {
+	db                  *gorm.DB
+	pricingService      *PricingService
+	promoCodeService    *PromoCodeService
+	presaleService      *PresaleService
+	inventoryService    *InventoryService
+	seatMapService      *SeatMapService
+	emailQueueService   *EmailQueueService
+	notificationService *NotificationService
+	integrationService  *IntegrationService
+	provider            PaymentProvider
+	insurance           InsuranceProvider
+	approvalThreshold   float64
+	checkout            config.CheckoutConfig
+	orders              config.OrderConfig
+	redisClient         redislib.UniversalClient
+	publicURL           string
+}
+
+// NewOrderService creates a new order service
+func NewOrderService(cfg *config.Config) *OrderService {
+	provider, err := NewPaymentProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: Provider refunds disabled: %v", err)
+	}
+	insurance, err := NewInsuranceProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: Ticket insurance disabled: %v", err)
+	}
+
+	return &OrderService{
+		db:                  database.DB,
+		pricingService:      NewPricingService(),
+		promoCodeService:    NewPromoCodeService(),
+		presaleService:      NewPresaleService(),
+		inventoryService:    NewInventoryService(),
+		seatMapService:      NewSeatMapService(cfg),
+		emailQueueService:   NewEmailQueueService(cfg),
+		notificationService: NewNotificationService(cfg),
+		integrationService:  NewIntegrationService(cfg),
+		provider:            provider,
+		insurance:           insurance,
+		approvalThreshold:   float64(cfg.Payment.AdjustmentApprovalMin),
+		checkout:            cfg.Checkout,
+		orders:              cfg.Order,
+		redisClient:         redis.RateLimit,
+		publicURL:           strings.TrimRight(cfg.Security.PublicURL, "/"),
+	}
+}
+
+// CreateStaffOrder places an order on behalf of a named attendee. Cash orders are paid on
+// the spot; invoice orders stay pending until marked paid. Tickets are emailed to the attendee,
+// and texted too when the buyer gave a phone number. Insurance, when requested, is quoted before
+// the order is placed and bound right after. At events with a seat map each ticket is issued for
+// one of the selected seats.
+func (s *OrderService) CreateStaffOrder(ctx context.Context, orgID uuid.UUID, staffID uuid.UUID, req *models.StaffOrderRequest) (*models.OrderDetailResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	if req.SMSPhone != "" && !s.notificationService.SMSAvailable() {
+		return nil, ErrSMSUnavailable
+	}
+
+	// Quote outside the transaction so the event row is not locked while the insurer responds
+	var quote *insuranceOffer
+	if req.Insurance {
+		var err error
+		if quote, err = s.quoteInsurance(ctx, req.EventID, req.Quantity); err != nil {
+			return nil, err
+		}
+	}
+
+	var event models.Event
+	var order models.Order
+	var previousAvailable int
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, req.EventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Event not found")
+			}
+			return err
+		}
+
+		if !event.OnSale() {
+			return errors.New("Tickets for this event are not on sale")
+		}
+		if event.Available < req.Quantity {
+			return fmt.Errorf("Only %d tickets are available", event.Available)
+		}
+		if event.VenueID != nil && len(req.SeatIDs) != req.Quantity {
+			return errors.New("Select one seat per ticket")
+		}
+		if event.VenueID == nil && len(req.SeatIDs) > 0 {
+			return errors.New("Event has no seat map")
+		}
+		if err := uniqueSeats(req.SeatIDs); err != nil {
+			return err
+		}
+
+		unitPrice, _, err := s.pricingService.CurrentPrice(ctx, &event)
+		if err != nil {
+			return err
+		}
+
+		ticketsAmount := math.Round(unitPrice*float64(req.Quantity)*100) / 100
+		order = models.Order{
+			EventID:        event.ID,
+			OrganizationID: &orgID,
+			BuyerEmail:     req.AttendeeEmail,
+			BuyerName:      req.AttendeeName,
+			Quantity:       req.Quantity,
+			TotalAmount:    ticketsAmount,
+			Currency:       models.DefaultCurrency,
+			Status:         models.OrderStatusPending,
+			PaymentMethod:  req.PaymentMethod,
+			CreatedBy:      &staffID,
+			SMSPhone:       req.SMSPhone,
+		}
+		if quote != nil {
+			if toMinorUnits(quote.CoveredAmount) != toMinorUnits(ticketsAmount) {
+				return errors.New("Ticket price changed while insurance was quoted, please try again")
+			}
+			order.InsuranceAmount = quote.Premium
+			order.TotalAmount = math.Round((ticketsAmount+quote.Premium)*100) / 100
+		}
+		if req.PaymentMethod == models.PaymentMethodCash {
+			now := time.Now()
+			order.Status = models.OrderStatusPaid
+			order.PaidAt = &now
+		}
+
+		if err := tx.Create(&order).Error; err != nil {
+			return err
+		}
+
+		if quote != nil {
+			order.Insurance = &models.OrderInsurance{
+				OrderID:        order.ID,
+				OrganizationID: &orgID,
+				Provider:       s.insurance.Name(),
+				QuoteRef:       quote.QuoteRef,
+				Premium:        quote.Premium,
+				CoveredAmount:  quote.CoveredAmount,
+				Currency:       order.Currency,
+				Status:         models.InsuranceStatusPending,
+			}
+			if err := tx.Create(order.Insurance).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := 0; i < req.Quantity; i++ {
+			ticket := &models.Ticket{
+				OrderID:        order.ID,
+				EventID:        event.ID,
+				OrganizationID: &orgID,
+				AttendeeName:   req.AttendeeName,
+				AttendeeEmail:  req.AttendeeEmail,
+				MarketingOptIn: req.MarketingOptIn,
+			}
+			if len(req.SeatIDs) > 0 {
+				ticket.SeatID = &req.SeatIDs[i]
+			}
+			if err := tx.Create(ticket).Error; err != nil {
+				return err
+			}
+			order.Tickets = append(order.Tickets, ticket)
+		}
+		if err := s.seatMapService.SellSeats(tx, event.ID, nil, order.Tickets); err != nil {
+			return err
+		}
+
+		previousAvailable = event.Available
+		return s.inventoryService.Reserve(ctx, tx, &event, req.Quantity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Staff order placed: Order=%s, Event=%d, Staff=%s, Method=%s",
+		order.ID, event.ID, staffID, order.PaymentMethod)
+
+	s.bindInsurance(ctx, &order)
+	s.afterOrderPlaced(&order, &event, previousAvailable)
+
+	return s.orderDetail(&order, &event), nil
+}
+
+// QuoteOrder prices ticket selections at their current prices and returns the full breakdown
+// buyers will be charged at checkout. Nothing is created or reserved. A promo code discounts the
+// tickets it covers; service fees and taxes are computed on the ticket amount after discounts.
+func (s *OrderService) QuoteOrder(ctx context.Context, req *models.OrderQuoteRequest) (*models.OrderQuote, error) {
+	currency := models.DefaultCurrency
+	if req.Currency != "" && !strings.EqualFold(req.Currency, currency) {
+		return nil, fmt.Errorf("Orders can only be priced in %s", currency)
+	}
+	quote := &models.OrderQuote{
+		Currency:  currency,
+		Lines:     make([]models.OrderQuoteLine, 0, len(req.Items)),
+		Discounts: []models.OrderQuoteDiscount{},
+	}
+	selected := make(map[uint]bool, len(req.Items))
+	tickets := 0
+	for _, item := range req.Items {
+		if selected[item.EventID] {
+			return nil, errors.New("Each event can only be selected once")
+		}
+		selected[item.EventID] = true
+
+		var event models.Event
+		if err := s.db.WithContext(ctx).First(&event, item.EventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("Event %d not found", item.EventID)
+			}
+			return nil, err
+		}
+		if !event.OnSale() {
+			return nil, fmt.Errorf("Tickets for %s are not on sale", event.Title)
+		}
+		if event.Available < item.Quantity {
+			return nil, fmt.Errorf("Only %d tickets are available for %s", event.Available, event.Title)
+		}
+
+		unitPrice, rule, err := s.pricingService.CurrentPrice(ctx, &event)
+		if err != nil {
+			return nil, err
+		}
+		line := models.OrderQuoteLine{
+			EventID:   event.ID,
+			Title:     event.Title,
+			Quantity:  item.Quantity,
+			UnitPrice: unitPrice,
+			Amount:    math.Round(unitPrice*float64(item.Quantity)*100) / 100,
+		}
+		if rule != nil {
+			line.PricingRuleID = &rule.ID
+		}
+		quote.Lines = append(quote.Lines, line)
+		quote.Subtotal = math.Round((quote.Subtotal+line.Amount)*100) / 100
+		tickets += item.Quantity
+	}
+
+	if req.PromoCode != "" {
+		discounts, err := s.promoCodeService.Apply(s.db.WithContext(ctx), req.PromoCode, "", quote.Lines, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		quote.Discounts = discounts
+		for _, discount := range discounts {
+			quote.DiscountAmount = math.Round((quote.DiscountAmount+discount.Amount)*100) / 100
+		}
+	}
+
+	discounted := math.Round((quote.Subtotal-quote.DiscountAmount)*100) / 100
+	if discounted > 0 {
+		quote.FeeAmount = math.Round((discounted*s.checkout.ServiceFeePercent/100+s.checkout.ServiceFeePerTicket*float64(tickets))*100) / 100
+		quote.TaxAmount = math.Round(discounted*s.checkout.TaxPercent) / 100
+	}
+	quote.Total = math.Round((discounted+quote.FeeAmount+quote.TaxAmount)*100) / 100
+	return quote, nil
+}
+
+// insuranceOffer is a provider quote for the tickets of an order about to be placed
+type insuranceOffer struct {
+	InsuranceQuote
+	CoveredAmount float64
+}
+
+// QuoteInsurance prices ticket insurance for an order before it is placed, so staff can offer it
+// to the attendee
+func (s *OrderService) QuoteInsurance(ctx context.Context, req *models.InsuranceQuoteRequest) (*models.InsuranceQuoteResponse, error) {
+	quote, err := s.quoteInsurance(ctx, req.EventID, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InsuranceQuoteResponse{
+		Provider:      s.insurance.Name(),
+		Premium:       quote.Premium,
+		CoveredAmount: quote.CoveredAmount,
+		Currency:      models.DefaultCurrency,
+		ExpiresAt:     quote.ExpiresAt,
+		TermsURL:      quote.TermsURL,
+	}, nil
+}
+
+// quoteInsurance asks the insurance provider to price cover for quantity tickets at the current price
+func (s *OrderService) quoteInsurance(ctx context.Context, eventID uint, quantity int) (*insuranceOffer, error) {
+	if s.insurance == nil {
+		return nil, ErrInsuranceUnavailable
+	}
+
+	var event models.Event
+	if err := s.db.WithContext(ctx).First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+	unitPrice, _, err := s.pricingService.CurrentPrice(ctx, &event)
+	if err != nil {
+		return nil, err
+	}
+	ticketsAmount := math.Round(unitPrice*float64(quantity)*100) / 100
+	if ticketsAmount <= 0 {
+		return nil, errors.New("Free tickets cannot be insured")
+	}
+
+	quote, err := s.insurance.Quote(ctx, &InsuranceQuoteRequest{
+		EventID:       event.ID,
+		EventTitle:    event.Title,
+		EventStart:    event.StartDate,
+		Quantity:      quantity,
+		TicketsAmount: ticketsAmount,
+		Currency:      models.DefaultCurrency,
+	})
+	if err != nil {
+		log.Printf("Insurance quote failed: Event=%d, Error=%v", event.ID, err)
+		return nil, fmt.Errorf("Ticket insurance could not be quoted: %w", err)
+	}
+	quote.Premium = math.Round(quote.Premium*100) / 100
+	return &insuranceOffer{InsuranceQuote: *quote, CoveredAmount: ticketsAmount}, nil
+}
+
+// bindInsurance issues the policy for an order's insurance. A refused bind does not fail the
+// order; it is recorded so the premium becomes refundable.
+func (s *OrderService) bindInsurance(ctx context.Context, order *models.Order) {
+	insurance := order.Insurance
+	if insurance == nil || insurance.Status != models.InsuranceStatusPending || s.insurance == nil {
+		return
+	}
+
+	updates := map[string]interface{}{}
+	policyRef, err := s.insurance.Bind(ctx, &InsuranceBindRequest{
+		QuoteRef:       insurance.QuoteRef,
+		OrderRef:       order.ID.String(),
+		HolderName:     order.BuyerName,
+		HolderEmail:    order.BuyerEmail,
+		IdempotencyKey: "insurance-bind-" + insurance.ID.String(),
+	})
+	if err != nil {
+		log.Printf("Insurance bind failed: Order=%s, Error=%v", order.ID, err)
+		insurance.Status = models.InsuranceStatusFailed
+		insurance.Error = err.Error()
+		updates["error"] = insurance.Error
+	} else {
+		now := time.Now()
+		insurance.Status = models.InsuranceStatusBound
+		insurance.PolicyRef = policyRef
+		insurance.BoundAt = &now
+		updates["policy_ref"] = policyRef
+		updates["bound_at"] = now
+	}
+	updates["status"] = insurance.Status
+
+	if err := s.db.WithContext(ctx).Model(insurance).Updates(updates).Error; err != nil {
+		log.Printf("Failed to record insurance bind: Order=%s, Policy=%s, Error=%v", order.ID, policyRef, err)
+	}
+}
+
+// MarkOrderPaid records payment of a pending invoice order
+func (s *OrderService) MarkOrderPaid(ctx context.Context, orgID uuid.UUID, orderID uuid.UUID) (*models.OrderResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var order models.Order
+	if err := db.Where("id = ? AND organization_id = ?", orderID, orgID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	if order.Status != models.OrderStatusPending {
+		return nil, fmt.Errorf("Order is already %s", order.Status)
+	}
+
+	now := time.Now()
+	result := db.Model(&order).
+		Where("status = ?", models.OrderStatusPending).
+		Updates(map[string]interface{}{"status": models.OrderStatusPaid, "paid_at": now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("Order was updated concurrently")
+	}
+
+	order.Status = models.OrderStatusPaid
+	order.PaidAt = &now
+
+	var event models.Event
+	if err := db.First(&event, order.EventID).Error; err != nil {
+		log.Printf("Failed to load event for receipt: Order=%s, Error=%v", order.ID, err)
+	} else if err := s.emailQueueService.QueueReceiptEmail(&order, &event); err != nil {
+		log.Printf("Failed to queue receipt email: Order=%s, Error=%v", order.ID, err)
+	}
+
+	resp := order.ToResponse()
+	return &resp, nil
+}
+
+// CancelOrder cancels a pending invoice order and puts its tickets back on sale
+func (s *OrderService) CancelOrder(ctx context.Context, orgID uuid.UUID, orderID uuid.UUID) (*models.OrderResponse, error) {
+	var order models.Order
+	if err := s.db.WithContext(ctx).Where("id = ? AND organization_id = ?", orderID, orgID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	if order.Status != models.OrderStatusPending {
+		return nil, fmt.Errorf("Order is already %s", order.Status)
+	}
+	if order.PaymentMethod == models.PaymentMethodInstallments {
+		return nil, errors.New("Installment orders are cancelled through their payment plan")
+	}
+
+	cancelled, err := s.cancelPendingOrder(ctx, &order, "it was cancelled by the organizer")
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, errors.New("Order was updated concurrently")
+	}
+
+	resp := order.ToResponse()
+	return &resp, nil
+}
+
+// ExpirePendingOrders cancels orders left pending for longer than the configured TTL and puts
+// their tickets back on sale. Installment orders are left to their own payment deadline.
+func (s *OrderService) ExpirePendingOrders(ctx context.Context) error {
+	if s.orders.PendingTTL <= 0 {
+		return nil
+	}
+
+	var orders []models.Order
+	err := s.db.WithContext(ctx).
+		Where("status = ? AND payment_method <> ? AND created_at < ?",
+			models.OrderStatusPending, models.PaymentMethodInstallments, time.Now().Add(-s.orders.PendingTTL)).
+		Find(&orders).Error
+	if err != nil {
+		return fmt.Errorf("failed to load expired orders: %w", err)
+	}
+
+	expired := 0
+	for i := range orders {
+		cancelled, err := s.cancelPendingOrder(ctx, &orders[i], "it was not paid in time")
+		if err != nil {
+			// One order's failure should not hold back the others
+			log.Printf("Failed to expire order %s: %v", orders[i].ID, err)
+			continue
+		}
+		if cancelled {
+			expired++
+		}
+	}
+	if expired > 0 {
+		log.Printf("Expired %d unpaid orders", expired)
+	}
+	return ctx.Err()
+}
+
+// cancelPendingOrder cancels a pending order and its tickets, restocks the event and notifies
+// the buyer. It reports false when the order was paid or cancelled concurrently.
+func (s *OrderService) cancelPendingOrder(ctx context.Context, order *models.Order, reason string) (bool, error) {
+	var event models.Event
+	var previousAvailable int
+	var cancelled bool
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(order).
+			Where("status = ?", models.OrderStatusPending).
+			Update("status", models.OrderStatusCancelled)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		cancelled = true
+		order.Status = models.OrderStatusCancelled
+
+		if err := s.promoCodeService.Release(tx, order.ID); err != nil {
+			return err
+		}
+		if err := s.presaleService.Release(tx, order); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Ticket{}).Where("order_id = ?", order.ID).
+			Update("status", models.TicketStatusCancelled).Error; err != nil {
+			return err
+		}
+		if err := s.seatMapService.ReleaseOrderSeats(tx, order.ID); err != nil {
+			return err
+		}
+
+		if err := tx.First(&event, order.EventID).Error; err != nil {
+			return err
+		}
+		previousAvailable = event.Available
+		return s.inventoryService.Release(ctx, tx, &event, order.Quantity)
+	})
+	if err != nil || !cancelled {
+		return false, err
+	}
+
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable, OrganizationID: order.OrganizationID})
+
+	message := fmt.Sprintf("Your order %s for %s has been cancelled because %s. Your tickets are no longer valid.",
+		order.ID, event.Title, reason)
+	if err := s.emailQueueService.QueueNotificationEmail(order.BuyerEmail, order.BuyerName, "Your order has been cancelled", message); err != nil {
+		log.Printf("Failed to queue cancellation email: Order=%s, Error=%v", order.ID, err)
+	}
+
+	log.Printf("Order cancelled: Order=%s, Reason=%s", order.ID, reason)
+	return true, nil
+}
+
+// ResendTickets queues the ticket emails of a buyer's order again, for buyers who lost them.
+// The order must have been placed by the user or for their email address.
+func (s *OrderService) ResendTickets(ctx context.Context, orderID uuid.UUID, userID uuid.UUID) (*models.TicketResendResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.Select("id", "email").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	err := db.Where("id = ? AND (user_id = ? OR LOWER(buyer_email) = LOWER(?))", orderID, userID, user.Email).
+		First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	return s.resendTickets(ctx, &order)
+}
+
+// AdminResendTickets queues the ticket emails of any order again on behalf of support staff.
+// Resends count towards the same hourly limit as the buyer's own.
+func (s *OrderService) AdminResendTickets(ctx context.Context, orderID uuid.UUID, adminID uuid.UUID) (*models.TicketResendResponse, error) {
+	var order models.Order
+	if err := s.db.WithContext(ctx).First(&order, "id = ?", orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+
+	resp, err := s.resendTickets(ctx, &order)
+	if err != nil {
+		return nil, err
+	}
+
+	recordAuditLog(s.db.WithContext(ctx), &adminID, AuditTicketsResent, "order", order.ID.String(), order.OrganizationID, map[string]interface{}{
+		"tickets": resp.TicketsResent,
+	})
+	return resp, nil
+}
+
+// resendTickets queues a ticket email for each ticket of the order still valid or checked in,
+// at most ORDER_TICKET_RESEND_LIMIT times per order per hour
+func (s *OrderService) resendTickets(ctx context.Context, order *models.Order) (*models.TicketResendResponse, error) {
+	if order.Status == models.OrderStatusCancelled || order.Status == models.OrderStatusRefunded {
+		return nil, fmt.Errorf("Tickets of %s orders cannot be resent", order.Status)
+	}
+
+	var event models.Event
+	if err := s.db.WithContext(ctx).First(&event, order.EventID).Error; err != nil {
+		return nil, err
+	}
+	var tickets []*models.Ticket
+	if err := s.db.WithContext(ctx).
+		Where("order_id = ? AND status <> ?", order.ID, models.TicketStatusCancelled).
+		Order("created_at").Find(&tickets).Error; err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, errors.New("Order has no tickets to resend")
+	}
+
+	remaining := -1
+	if s.redisClient != nil {
+		count, err := s.incrResendWindow(ctx, order.ID)
+		if err != nil {
+			log.Printf("Ticket resend throttle unavailable: %v", err)
+		} else {
+			if count > int64(s.orders.TicketResendLimit) {
+				return nil, utils.NewRateLimitError("Tickets of this order were resent too many times, please try again later")
+			}
+			remaining = s.orders.TicketResendLimit - int(count)
+		}
+	}
+
+	for _, ticket := range tickets {
+		if err := s.emailQueueService.QueueTicketEmail(ticket, &event, "General Admission"); err != nil {
+			return nil, fmt.Errorf("failed to queue ticket email: %w", err)
+		}
+	}
+
+	log.Printf("Ticket emails resent: Order=%s, Tickets=%d", order.ID, len(tickets))
+	resp := &models.TicketResendResponse{OrderID: order.ID, TicketsResent: len(tickets)}
+	if remaining >= 0 {
+		resp.ResendsLeft = &remaining
+	}
+	return resp, nil
+}
+
+// ListOrderNotifications returns the delivery log of an order's ticket emails and texts
+func (s *OrderService) ListOrderNotifications(ctx context.Context, orgID uuid.UUID, orderID uuid.UUID) ([]models.NotificationLog, error) {
+	var order models.Order
+	if err := s.db.WithContext(ctx).Select("id").Where("id = ? AND organization_id = ?", orderID, orgID).First(&order).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+	return s.notificationService.ListOrderNotifications(ctx, orderID)
+}
+
+// incrResendWindow counts a resend of the order's tickets in an hourly window started by the first
+func (s *OrderService) incrResendWindow(ctx context.Context, orderID uuid.UUID) (int64, error) {
+	key := "order:resend:" + orderID.String()
+	pipe := s.redisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, time.Hour)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// afterOrderPlaced runs the side effects of a new order: ticket emails, texts and WhatsApp messages, inventory hooks,
+// organizer notifications and integration triggers. Failures are logged, never returned.
+func (s *OrderService) afterOrderPlaced(order *models.Order, event *models.Event, previousAvailable int) {
+	for _, ticket := range order.Tickets {
+		if err := s.emailQueueService.QueueTicketEmail(ticket, event, "General Admission"); err != nil {
+			log.Printf("Failed to queue ticket email: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+	}
+	if order.SMSPhone != "" {
+		if err := s.notificationService.QueueTicketSMS(order, order.SMSPhone); err != nil {
+			log.Printf("Failed to queue ticket SMS: Order=%s, Error=%v", order.ID, err)
+		}
+	}
+	if err := s.notificationService.QueueTicketWhatsApp(order); err != nil {
+		log.Printf("Failed to queue ticket WhatsApp messages: Order=%s, Error=%v", order.ID, err)
+	}
+
+	if order.Status == models.OrderStatusPaid {
+		if err := s.emailQueueService.QueueReceiptEmail(order, event); err != nil {
+			log.Printf("Failed to queue receipt email: Order=%s, Error=%v", order.ID, err)
+		}
+	}
+
+	InventoryChanged(&InventoryChange{Event: event, PreviousAvailable: previousAvailable, OrganizationID: order.OrganizationID})
+
+	if order.OrganizationID == nil {
+		return
+	}
+	orgID := *order.OrganizationID
+
+	s.integrationService.NotifyTicketsSold(order, event)
+
+	if err := s.integrationService.DispatchHook(orgID, models.HookEventOrderCreated, order.ToResponse()); err != nil {
+		log.Printf("Failed to dispatch order hook: Order=%s, Error=%v", order.ID, err)
+	}
+
+	optedIn := false
+	for _, ticket := range order.Tickets {
+		if err := s.integrationService.DispatchHook(orgID, models.HookEventAttendeeCreated, ticket.ToAttendeeResponse()); err != nil {
+			log.Printf("Failed to dispatch attendee hook: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+		optedIn = optedIn || ticket.MarketingOptIn
+	}
+
+	if optedIn {
+		if err := s.integrationService.QueueContactSync(orgID); err != nil {
+			log.Printf("Failed to queue contact sync: Organization=%s, Error=%v", orgID, err)
+		}
+	}
+}
+
+// orderDetail converts an order and its loaded tickets to an OrderDetailResponse, with links
+// adding its event to a calendar
+func (s *OrderService) orderDetail(order *models.Order, event *models.Event) *models.OrderDetailResponse {
+	detail := &models.OrderDetailResponse{
+		OrderResponse: order.ToResponse(),
+		Tickets:       make([]models.AttendeeResponse, len(order.Tickets)),
+		Insurance:     order.Insurance,
+		Calendar:      calendarLinks(s.publicURL, event),
+	}
+	for i, ticket := range order.Tickets {
+		detail.Tickets[i] = ticket.ToAttendeeResponse()
+	}
+	return detail
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// rollupSQL walks an organization's sub-organizations at any depth and sums their paid orders per
+// currency. Cancelled orders are left out. Refunded orders only count towards the amounts, where
+// their refunds cancel out their gross in the net amount, so they are not counted as sales. Raw
+// SQL bypasses the tenancy guard, which would otherwise hide the sub-organizations' orders from
+// the parent's request context.
+const rollupSQL = `
+WITH RECURSIVE tree AS (
+	SELECT id, name, parent_id, 0 AS depth FROM organizations
+	WHERE id = @org AND deleted_at IS NULL
+	UNION ALL
+	SELECT organizations.id, organizations.name, organizations.parent_id, tree.depth + 1
+	FROM organizations JOIN tree ON organizations.parent_id = tree.id
+	WHERE organizations.deleted_at IS NULL
+)
+SELECT tree.id AS organization_id, tree.name, tree.parent_id, tree.depth,
+	COALESCE(orders.currency, '') AS currency,
+	COUNT(orders.id) FILTER (WHERE orders.status = @paid) AS orders,
+	COALESCE(SUM(orders.quantity) FILTER (WHERE orders.status = @paid), 0) AS tickets,
+	COALESCE(SUM(orders.total_amount), 0) AS gross_amount,
+	COALESCE(SUM(orders.fee_amount), 0) AS fee_amount,
+	COALESCE(SUM(orders.refunded_amount), 0) AS refunded_amount
+FROM tree LEFT JOIN orders ON orders.organization_id = tree.id
+	AND orders.paid_at >= @from AND orders.paid_at < @to
+	AND orders.status IN (@paid, @refunded)
+GROUP BY tree.id, tree.name, tree.parent_id, tree.depth, orders.currency
+ORDER BY tree.depth, tree.name, currency`
+
+// GetChildOrganizations returns the direct sub-organizations of an organization
+func (s *OrganizationService) GetChildOrganizations(ctx context.Context, orgID uuid.UUID) ([]models.OrganizationResponse, error) {
+	var children []models.Organization
+	if err := s.db.WithContext(ctx).Where("parent_id = ?", orgID).Order("name ASC").Find(&children).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.OrganizationResponse, len(children))
+	for i, child := range children {
+		responses[i] = child.ToResponse()
+	}
+	return responses, nil
+}
+
+// Rollup sums the sales of an organization and all its sub-organizations, per organization and in total
+func (s *OrganizationService) Rollup(ctx context.Context, orgID uuid.UUID, filter *models.OrganizationRollupFilter) (*models.OrganizationRollup, error) {
+	from := time.Time{}
+	to := time.Now()
+	if filter.From != "" {
+		from, _ = time.Parse("2006-01-02", filter.From)
+	}
+	if filter.To != "" {
+		day, _ := time.Parse("2006-01-02", filter.To)
+		to = day.Add(24 * time.Hour)
+	}
+	if !to.After(from) {
+		return nil, errors.New("From date must not be after to date")
+	}
+
+	var rows []models.OrganizationRollupRow
+	err := s.db.WithContext(ctx).Raw(rollupSQL, map[string]interface{}{
+		"org":      orgID,
+		"from":     from,
+		"to":       to,
+		"paid":     models.OrderStatusPaid,
+		"refunded": models.OrderStatusRefunded,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to roll up organization sales: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("Organization not found")
+	}
+
+	rollup := &models.OrganizationRollup{
+		OrganizationID: orgID,
+		From:           filter.From,
+		To:             filter.To,
+		Organizations:  rows,
+		Totals:         []models.OrganizationRollupTotal{},
+	}
+	totals := make(map[string]int) // Currency to its index in rollup.Totals
+	for i := range rows {
+		row := &rows[i]
+		row.NetAmount = math.Round((row.GrossAmount-row.FeeAmount-row.RefundedAmount)*100) / 100
+		if row.Currency == "" {
+			continue
+		}
+
+		index, ok := totals[row.Currency]
+		if !ok {
+			index = len(rollup.Totals)
+			totals[row.Currency] = index
+			rollup.Totals = append(rollup.Totals, models.OrganizationRollupTotal{Currency: row.Currency})
+		}
+		total := &rollup.Totals[index]
+		total.Orders += row.Orders
+		total.Tickets += row.Tickets
+		total.GrossAmount = math.Round((total.GrossAmount+row.GrossAmount)*100) / 100
+		total.FeeAmount = math.Round((total.FeeAmount+row.FeeAmount)*100) / 100
+		total.RefundedAmount = math.Round((total.RefundedAmount+row.RefundedAmount)*100) / 100
+		total.NetAmount = math.Round((total.NetAmount+row.NetAmount)*100) / 100
+	}
+	return rollup, nil
+}
+
+// setParent moves an organization under a parent, or makes it top-level for an empty ID. The
+// parent may not be the organization itself or one of its sub-organizations.
+func (s *OrganizationService) setParent(db *gorm.DB, org *models.Organization, parentID string) error {
+	if parentID == "" {
+		org.ParentID = nil
+		return nil
+	}
+
+	id, err := uuid.Parse(parentID)
+	if err != nil {
+		return errors.New("Invalid parent organization ID")
+	}
+
+	// Walk up from the new parent; reaching the organization would close a cycle
+	for ancestorID := &id; ancestorID != nil; {
+		if *ancestorID == org.ID {
+			return errors.New("An organization cannot be placed under itself or its sub-organizations")
+		}
+
+		var ancestor models.Organization
+		if err := db.Select("id", "parent_id").First(&ancestor, "id = ?", *ancestorID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Parent organization not found")
+			}
+			return err
+		}
+		ancestorID = ancestor.ParentID
+	}
+
+	org.ParentID = &id
+	return nil
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrOrganizationRoleNotFound is returned for a custom role that does not exist in the organization
+	ErrOrganizationRoleNotFound = errors.New("Role not found")
+	// ErrPermissionNotAssignable is returned when a custom role is given a permission outside
+	// database.OrganizationRoleResources, or one that does not exist
+	ErrPermissionNotAssignable = errors.New("Permission cannot be assigned to organization roles")
+)
+
+// OrganizationRoleService manages the custom roles organizers compose for their members and
+// decides what members may do within an organization
+type OrganizationRoleService struct {
+	db *gorm.DB
+}
+
+// NewOrganizationRoleService creates a new organization role service
+func NewOrganizationRoleService() *OrganizationRoleService {
+	return &OrganizationRoleService{db: database.DB}
+}
+
+// ListAssignablePermissions returns the permissions custom organization roles can be composed from
+func (s *OrganizationRoleService) ListAssignablePermissions(ctx context.Context) ([]models.PermissionResponse, error) {
+	var permissions []models.Permission
+	err := s.db.WithContext(ctx).
+		Where("resource IN ?", database.OrganizationRoleResources).
+		Order("resource, action").
+		Find(&permissions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.PermissionResponse, len(permissions))
+	for i := range permissions {
+		responses[i] = permissions[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// ListRoles returns the custom roles of an organization with their permissions and members
+func (s *OrganizationRoleService) ListRoles(ctx context.Context, orgID uuid.UUID) ([]models.OrganizationRoleResponse, error) {
+	var roles []models.OrganizationRole
+	err := s.db.WithContext(ctx).
+		Preload("Permissions").
+		Preload("Members").
+		Where("organization_id = ?", orgID).
+		Order("name").
+		Find(&roles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.OrganizationRoleResponse, len(roles))
+	for i := range roles {
+		responses[i] = roles[i].ToResponse()
+	}
+	return responses, nil
+}
+
+// CreateRole creates a custom role in an organization
+func (s *OrganizationRoleService) CreateRole(ctx context.Context, orgID, actorID uuid.UUID, req *models.OrganizationRoleRequest) (*models.OrganizationRoleResponse, error) {
+	role := models.OrganizationRole{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Description:    req.Description,
+		CreatedBy:      &actorID,
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		permissions, err := assignablePermissions(tx, req.Permissions)
+		if err != nil {
+			return err
+		}
+		if err := ensureRoleNameFree(tx, orgID, role.Name, uuid.Nil); err != nil {
+			return err
+		}
+
+		role.Permissions = permissions
+		if err := tx.Create(&role).Error; err != nil {
+			return fmt.Errorf("failed to create role: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "organization_role.create", "organization_role", role.ID.String(), &orgID, map[string]interface{}{
+			"name":        role.Name,
+			"permissions": req.Permissions,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := role.ToResponse()
+	return &resp, nil
+}
+
+// UpdateRole renames a custom role and replaces its permissions. Members keep the role.
+func (s *OrganizationRoleService) UpdateRole(ctx context.Context, orgID, roleID, actorID uuid.UUID, req *models.OrganizationRoleRequest) (*models.OrganizationRoleResponse, error) {
+	var role models.OrganizationRole
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findOrganizationRole(tx, orgID, roleID, &role); err != nil {
+			return err
+		}
+		permissions, err := assignablePermissions(tx, req.Permissions)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSpace(req.Name)
+		if err := ensureRoleNameFree(tx, orgID, name, role.ID); err != nil {
+			return err
+		}
+
+		err = tx.Model(&role).Updates(map[string]interface{}{
+			"name":        name,
+			"description": req.Description,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to update role: %w", err)
+		}
+		if err := tx.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+			return fmt.Errorf("failed to update role permissions: %w", err)
+		}
+		if err := tx.Model(&role).Association("Members").Find(&role.Members); err != nil {
+			return err
+		}
+
+		recordAuditLog(tx, &actorID, "organization_role.update", "organization_role", role.ID.String(), &orgID, map[string]interface{}{
+			"name":        name,
+			"permissions": req.Permissions,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := role.ToResponse()
+	return &resp, nil
+}
+
+// DeleteRole deletes a custom role, taking its permissions away from every member holding it
+func (s *OrganizationRoleService) DeleteRole(ctx context.Context, orgID, roleID, actorID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role models.OrganizationRole
+		if err := findOrganizationRole(tx, orgID, roleID, &role); err != nil {
+			return err
+		}
+		if err := tx.Select("Permissions", "Members").Delete(&role).Error; err != nil {
+			return fmt.Errorf("failed to delete role: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "organization_role.delete", "organization_role", role.ID.String(), &orgID, map[string]interface{}{
+			"name": role.Name,
+		})
+		return nil
+	})
+}
+
+// AssignMember gives a member of the organization a custom role. Assigning a role the member
+// already holds is a no-op.
+func (s *OrganizationRoleService) AssignMember(ctx context.Context, orgID, roleID, actorID, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role models.OrganizationRole
+		if err := findOrganizationRole(tx, orgID, roleID, &role); err != nil {
+			return err
+		}
+
+		if _, err := findOrganizationMember(tx, orgID, userID); err != nil {
+			if errors.Is(err, ErrNotOrganizationMember) {
+				return errors.New("User is not a member of this organization")
+			}
+			return err
+		}
+
+		if err := tx.Model(&role).Association("Members").Append(&models.User{ID: userID}); err != nil {
+			return fmt.Errorf("failed to assign role: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "organization_role.assign", "organization_role", role.ID.String(), &orgID, map[string]interface{}{
+			"name":    role.Name,
+			"user_id": userID,
+		})
+		return nil
+	})
+}
+
+// UnassignMember takes a custom role away from a member
+func (s *OrganizationRoleService) UnassignMember(ctx context.Context, orgID, roleID, actorID, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var role models.OrganizationRole
+		if err := findOrganizationRole(tx, orgID, roleID, &role); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&role).Association("Members").Delete(&models.User{ID: userID}); err != nil {
+			return fmt.Errorf("failed to unassign role: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "organization_role.unassign", "organization_role", role.ID.String(), &orgID, map[string]interface{}{
+			"name":    role.Name,
+			"user_id": userID,
+		})
+		return nil
+	})
+}
+
+// MemberHasPermission reports whether a user may perform an action within an organization. Only
+// active members of the organization and auditors qualify: the permission comes from the role the
+// member holds in the organization or from a custom role of the organization assigned to them.
+// Organizers and admins are checked by the caller.
+func (s *OrganizationRoleService) MemberHasPermission(ctx context.Context, orgID, userID uuid.UUID, resource, action string) (bool, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.Preload("Roles.Permissions").First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if utils.HasRole(&user, database.AuditorRole) {
+		return utils.HasPermission(&user, resource, action), nil
+	}
+
+	// Members get the permissions of the role they hold in this organization, whatever their
+	// global roles
+	member, err := findOrganizationMember(db, orgID, userID)
+	if err != nil {
+		if errors.Is(err, ErrNotOrganizationMember) {
+			return false, nil
+		}
+		return false, err
+	}
+	if !member.Active {
+		return false, nil
+	}
+	var memberRole models.Role
+	if err := db.Preload("Permissions").Where("name = ?", member.RoleName).Limit(1).Find(&memberRole).Error; err != nil {
+		return false, err
+	}
+	if utils.HasPermission(&models.User{Roles: []*models.Role{&memberRole}}, resource, action) {
+		return true, nil
+	}
+
+	var granted int64
+	err = db.Table("organization_role_members").
+		Joins("JOIN organization_roles ON organization_roles.id = organization_role_members.organization_role_id").
+		Joins("JOIN organization_role_permissions ON organization_role_permissions.organization_role_id = organization_roles.id").
+		Joins("JOIN permissions ON permissions.id = organization_role_permissions.permission_id").
+		Where("organization_role_members.user_id = ? AND organization_roles.organization_id = ?", userID, orgID).
+		Where("permissions.resource = ? AND (permissions.action = ? OR permissions.action = '*')", resource, action).
+		Count(&granted).Error
+	if err != nil {
+		return false, err
+	}
+	return granted > 0, nil
+}
+
+// findOrganizationRole loads a custom role of the organization
+func findOrganizationRole(tx *gorm.DB, orgID, roleID uuid.UUID, role *models.OrganizationRole) error {
+	err := tx.Preload("Permissions").First(role, "id = ? AND organization_id = ?", roleID, orgID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrOrganizationRoleNotFound
+	}
+	return err
+}
+
+// ensureRoleNameFree rejects a role name another custom role of the organization already uses
+func ensureRoleNameFree(tx *gorm.DB, orgID uuid.UUID, name string, roleID uuid.UUID) error {
+	var count int64
+	err := tx.Model(&models.OrganizationRole{}).
+		Where("organization_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", orgID, name, roleID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("a role named %q already exists", name)
+	}
+	return nil
+}
+
+// assignablePermissions resolves permission names, rejecting any outside database.OrganizationRoleResources
+func assignablePermissions(tx *gorm.DB, names []string) ([]*models.Permission, error) {
+	var permissions []*models.Permission
+	if err := tx.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[string]bool, len(permissions))
+	for _, permission := range permissions {
+		if !slices.Contains(database.OrganizationRoleResources, permission.Resource) {
+			return nil, fmt.Errorf("%w: %s", ErrPermissionNotAssignable, permission.Name)
+		}
+		found[permission.Name] = true
+	}
+	for _, name := range names {
+		if !found[name] {
+			return nil, fmt.Errorf("%w: %s", ErrPermissionNotAssignable, name)
+		}
+	}
+	return permissions, nil
+}
@@ -1,17 +1,26 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 
 	"event-ticketing-backend/internal/database"
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// AuditOrganizationMemberAdded is the audit log action for adding an existing account to an organization
+const AuditOrganizationMemberAdded = "organization_member.added"
+
+// ErrNotOrganizationMember is returned when a user is not a member of the organization
+var ErrNotOrganizationMember = errors.New("User not found in this organization")
+
 // OrganizationService provides methods for managing organizations
 type OrganizationService struct {
 	db           *gorm.DB
@@ -27,19 +36,26 @@ func NewOrganizationService(emailService *EmailService) *OrganizationService {
 }
 
 // CreateOrganization creates a new organization with the given user as organizer
-func (s *OrganizationService) CreateOrganization(organizerID uuid.UUID, req *models.CreateOrganizationRequest) (*models.OrganizationResponse, error) {
+func (s *OrganizationService) CreateOrganization(ctx context.Context, organizerID uuid.UUID, req *models.CreateOrganizationRequest) (*models.OrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Verify the user exists
 	var organizer models.User
-	if err := s.db.First(&organizer, "id = ?", organizerID).Error; err != nil {
+	if err := db.First(&organizer, "id = ?", organizerID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("Organizer not found")
 		}
 		return nil, err
 	}
 
+	// Registration asks for an email and password only; organizers need a complete profile
+	if missing := organizer.MissingProfileFields(); len(missing) > 0 {
+		return nil, utils.NewProfileIncompleteError(missing)
+	}
+
 	// Check if user already has an organizer role
 	var organizerRole models.Role
-	if err := s.db.Where("name = ?", "organizer").First(&organizerRole).Error; err != nil {
+	if err := db.Where("name = ?", "organizer").First(&organizerRole).Error; err != nil {
 		return nil, fmt.Errorf("organizer role not found: %w", err)
 	}
 
@@ -52,30 +68,31 @@ func (s *OrganizationService) CreateOrganization(organizerID uuid.UUID, req *mod
 		OrganizerID: organizerID,
 	}
 
-	// Start a transaction
-	tx := s.db.Begin()
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
 
-	// Create organization
-	if err := tx.Create(&org).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		// Create organization
+		if err := tx.Create(&org).Error; err != nil {
+			return err
+		}
 
-	// Add organizer role to the user if they don't have it already
-	var hasOrganizerRole bool
-	if err := tx.Model(&organizer).Association("Roles").Find(&organizerRole); err == nil {
-		hasOrganizerRole = true
-	}
+		// The organizer is the organization's first member
+		if err := addOrganizationMember(tx, org.ID, organizerID, models.MemberRoleOrganizer, &organizerID); err != nil {
+			return err
+		}
 
-	if !hasOrganizerRole {
-		if err := tx.Model(&organizer).Association("Roles").Append(&organizerRole); err != nil {
-			tx.Rollback()
-			return nil, err
+		// Add organizer role to the user if they don't have it already
+		var hasOrganizerRole bool
+		if err := tx.Model(&organizer).Association("Roles").Find(&organizerRole); err == nil {
+			hasOrganizerRole = true
 		}
-	}
 
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
+		if !hasOrganizerRole {
+			return tx.Model(&organizer).Association("Roles").Append(&organizerRole)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -83,20 +100,20 @@ func (s *OrganizationService) CreateOrganization(organizerID uuid.UUID, req *mod
 	return &resp, nil
 }
 
-// CreateOrgUser creates a new user under an organization
-func (s *OrganizationService) CreateOrgUser(organizerID uuid.UUID, orgID uuid.UUID, req *models.CreateOrgUserRequest) (*models.UserResponse, error) {
+// CreateOrgUser creates a new user under an organization with a password chosen by the organizer,
+// who passes it on themselves; nothing is emailed. Invitations let users choose their own.
+func (s *OrganizationService) CreateOrgUser(ctx context.Context, organizerID uuid.UUID, orgID uuid.UUID, req *models.CreateOrgUserRequest) (*models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check if the organization exists and the organizer is authorized
 	var org models.Organization
-	if err := s.db.First(&org, "id = ? AND organizer_id = ?", orgID, organizerID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("Organization not found or you are not authorized to manage this organization")
-		}
+	if err := findManagedOrganization(db, orgID, organizerID, &org); err != nil {
 		return nil, err
 	}
 
 	// Check if user with the email already exists
 	var existingUser models.User
-	if err := s.db.Where("email = ?", strings.ToLower(req.Email)).First(&existingUser).Error; err == nil {
+	if err := db.Where("email = ?", strings.ToLower(req.Email)).First(&existingUser).Error; err == nil {
 		return nil, errors.New("User with this email already exists")
 	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, err
@@ -104,16 +121,13 @@ func (s *OrganizationService) CreateOrgUser(organizerID uuid.UUID, orgID uuid.UU
 
 	// Get the role
 	var role models.Role
-	if err := s.db.Where("name = ?", req.RoleName).First(&role).Error; err != nil {
+	if err := db.Where("name = ?", req.RoleName).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, fmt.Errorf("role '%s' not found", req.RoleName)
 		}
 		return nil, err
 	}
 
-	// Store original plain password to send in email
-	plainPassword := req.Password
-
 	// Create user
 	user := models.User{
 		Email:           strings.ToLower(req.Email),
@@ -129,47 +143,40 @@ func (s *OrganizationService) CreateOrgUser(organizerID uuid.UUID, orgID uuid.UU
 		return nil, err
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-
-	// Create user
-	if err := tx.Create(&user).Error; err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+	// Create the user and assign the role together
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
 
-	// Assign role
-	if err := tx.Model(&user).Association("Roles").Append(&role); err != nil {
-		tx.Rollback()
-		return nil, err
-	}
+		// Create user
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
+		// Assign role
+		if err := tx.Model(&user).Association("Roles").Append(&role); err != nil {
+			return err
+		}
+		return addOrganizationMember(tx, orgID, user.ID, role.Name, &organizerID)
+	})
+	if err != nil {
 		return nil, err
 	}
 
 	// Load relations for response
-	if err := s.db.Preload("Roles").Preload("Organization").First(&user, user.ID).Error; err != nil {
+	if err := db.Preload("Roles").Preload("Organization").First(&user, user.ID).Error; err != nil {
 		return nil, err
 	}
 
-	// Send welcome email with credentials if email service is available
-	if s.emailService != nil {
-		if err := s.emailService.SendWelcomeEmailWithCredentials(&user, plainPassword, org.Name); err != nil {
-			// Log error but don't fail the request
-			fmt.Printf("Failed to send welcome email: %v\n", err)
-		}
-	}
-
 	resp := user.ToResponse()
 	return &resp, nil
 }
 
 // GetOrganizationByID retrieves an organization by its ID
-func (s *OrganizationService) GetOrganizationByID(orgID uuid.UUID) (*models.OrganizationResponse, error) {
+func (s *OrganizationService) GetOrganizationByID(ctx context.Context, orgID uuid.UUID) (*models.OrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	var org models.Organization
-	if err := s.db.First(&org, "id = ?", orgID).Error; err != nil {
+	if err := db.First(&org, "id = ?", orgID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("Organization not found")
 		}
@@ -177,7 +184,7 @@ func (s *OrganizationService) GetOrganizationByID(orgID uuid.UUID) (*models.Orga
 	}
 
 	// Load organizer
-	if err := s.db.Model(&org).Association("Organizer").Find(&org.Organizer); err != nil {
+	if err := db.Model(&org).Association("Organizer").Find(&org.Organizer); err != nil {
 		return nil, err
 	}
 
@@ -186,30 +193,18 @@ func (s *OrganizationService) GetOrganizationByID(orgID uuid.UUID) (*models.Orga
 }
 
 // GetUserOrganizations gets all organizations for a user
-func (s *OrganizationService) GetUserOrganizations(userID uuid.UUID) ([]models.OrganizationResponse, error) {
-	var organizations []models.Organization
+func (s *OrganizationService) GetUserOrganizations(ctx context.Context, userID uuid.UUID) ([]models.OrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
 
-	// If user is an organizer, get organizations they created
-	if err := s.db.Where("organizer_id = ?", userID).Find(&organizations).Error; err != nil {
+	// Organizations the user created, plus every one they are a member of, in a single query
+	var organizations []models.Organization
+	if err := db.Where("organizer_id = ?", userID).
+		Or("id IN (?)", db.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userID)).
+		Order("created_at ASC").
+		Find(&organizations).Error; err != nil {
 		return nil, err
 	}
 
-	// If user is a member, get organizations they belong to
-	var user models.User
-	if err := s.db.Preload("Organization").First(&user, "id = ?", userID).Error; err == nil && user.Organization != nil {
-		// Check if this organization is already in the list
-		found := false
-		for _, org := range organizations {
-			if org.ID == user.Organization.ID {
-				found = true
-				break
-			}
-		}
-		if !found {
-			organizations = append(organizations, *user.Organization)
-		}
-	}
-
 	// Convert to response objects
 	responses := make([]models.OrganizationResponse, len(organizations))
 	for i, org := range organizations {
@@ -220,100 +215,183 @@ func (s *OrganizationService) GetUserOrganizations(userID uuid.UUID) ([]models.O
 }
 
 // GetOrganizationUsers gets all users in an organization
-func (s *OrganizationService) GetOrganizationUsers(orgID uuid.UUID) ([]models.UserResponse, error) {
-	var users []models.User
-	if err := s.db.Where("organization_id = ?", orgID).Preload("Roles").Find(&users).Error; err != nil {
-		return nil, err
-	}
-
-	responses := make([]models.UserResponse, len(users))
-	for i, user := range users {
-		resp := user.ToResponse()
-		responses[i] = resp
-	}
+func (s *OrganizationService) GetOrganizationUsers(ctx context.Context, orgID uuid.UUID) ([]models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
 
-	return responses, nil
-}
-
-// UpdateOrganizationUser updates a user's role within an organization
-func (s *OrganizationService) UpdateOrganizationUser(orgID uuid.UUID, userID uuid.UUID, req *models.UpdateOrgUserRequest) (*models.UserResponse, error) {
-	// Check if the user exists in the organization
-	var user models.User
-	if err := s.db.Where("id = ? AND organization_id = ?", userID, orgID).First(&user).Error; err != nil {
+	var org models.Organization
+	if err := db.First(&org, "id = ?", orgID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("User not found in this organization")
+			return []models.UserResponse{}, nil
 		}
 		return nil, err
 	}
 
-	// Update role if specified
+	return s.organizationUsers(db, &org)
+}
+
+// UpdateOrganizationUser updates a member's role and status within an organization. The role
+// applies to this organization only; the member's other memberships are left alone.
+func (s *OrganizationService) UpdateOrganizationUser(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.UpdateOrgUserRequest) (*models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	// Find the role if specified
+	var role *models.Role
 	if req.RoleType != "" {
-		// Find the role
-		var role models.Role
-		if err := s.db.Where("name = ?", req.RoleType).First(&role).Error; err != nil {
+		role = &models.Role{}
+		if err := db.Where("name = ?", req.RoleType).First(role).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return nil, fmt.Errorf("role '%s' not found", req.RoleType)
 			}
 			return nil, err
 		}
+	}
 
-		// Start transaction for role update
-		tx := s.db.Begin()
+	// Apply the role and status changes together
+	var member *models.OrganizationMember
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
 
-		// Remove existing roles
-		if err := tx.Model(&user).Association("Roles").Clear(); err != nil {
-			tx.Rollback()
-			return nil, err
+		var err error
+		if member, err = findOrganizationMember(tx, orgID, userID); err != nil {
+			return err
 		}
 
-		// Assign new role
-		if err := tx.Model(&user).Association("Roles").Append(&role); err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-
-		// Commit transaction
-		if err := tx.Commit().Error; err != nil {
-			return nil, err
+		if role != nil {
+			member.RoleName = role.Name
+			if err := grantUserRole(tx, userID, role); err != nil {
+				return err
+			}
 		}
-	}
 
-	// Update active status if provided
-	if req.Active != nil {
-		if err := s.db.Model(&user).Update("is_active", *req.Active).Error; err != nil {
-			return nil, err
+		// Update active status if provided
+		if req.Active != nil {
+			member.Active = *req.Active
 		}
+		return tx.Model(member).Select("role_name", "active").Updates(member).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Refresh user data
-	if err := s.db.Preload("Roles").Preload("Organization").First(&user, user.ID).Error; err != nil {
+	var user models.User
+	if err := db.Preload("Roles").Preload("Organization").First(&user, userID).Error; err != nil {
 		return nil, err
 	}
 
 	resp := user.ToResponse()
+	resp.OrganizationRole = member.RoleName
+	resp.MemberActive = &member.Active
 	return &resp, nil
 }
 
-// DeleteOrganizationUser removes a user from an organization
-func (s *OrganizationService) DeleteOrganizationUser(orgID uuid.UUID, userID uuid.UUID) error {
-	// Check if the user exists in the organization
-	result := s.db.Where("id = ? AND organization_id = ?", userID, orgID).Delete(&models.User{})
-	if result.Error != nil {
-		return result.Error
+// DeleteOrganizationUser removes a member from an organization, along with the custom roles they
+// held there. The account is kept, as it may belong to other organizations; when this was its
+// primary organization, another membership takes its place.
+func (s *OrganizationService) DeleteOrganizationUser(ctx context.Context, orgID uuid.UUID, userID uuid.UUID) error {
+	return database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		result := tx.Where("organization_id = ? AND user_id = ?", orgID, userID).Delete(&models.OrganizationMember{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotOrganizationMember
+		}
+
+		if err := tx.Exec(`DELETE FROM organization_role_members WHERE user_id = ? AND organization_role_id IN (
+			SELECT id FROM organization_roles WHERE organization_id = ?)`, userID, orgID).Error; err != nil {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Select("id", "organization_id").First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+		if user.OrganizationID == nil || *user.OrganizationID != orgID {
+			return nil
+		}
+		var next []models.OrganizationMember
+		if err := database.SkipTenancy(tx).Where("user_id = ?", userID).Order("created_at").Limit(1).Find(&next).Error; err != nil {
+			return err
+		}
+		var primary *uuid.UUID
+		if len(next) > 0 {
+			primary = &next[0].OrganizationID
+		}
+		return tx.Model(&user).Update("organization_id", primary).Error
+	})
+}
+
+// AddOrganizationMember adds an existing account to an organization with a role that applies to
+// that organization only. Adding a current member changes their role and reactivates them.
+func (s *OrganizationService) AddOrganizationMember(ctx context.Context, orgID, actorID uuid.UUID, req *models.AddOrganizationMemberRequest) (*models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var org models.Organization
+	if err := db.First(&org, "id = ?", orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Organization not found")
+		}
+		return nil, err
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New("User not found in this organization")
+	var user models.User
+	if err := db.Where("email = ?", strings.ToLower(req.Email)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("No account uses this email; invite them instead")
+		}
+		return nil, err
+	}
+
+	var role models.Role
+	if err := db.Where("name = ?", req.RoleName).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("role '%s' not found", req.RoleName)
+		}
+		return nil, err
 	}
 
-	return nil
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		if err := addOrganizationMember(tx, orgID, user.ID, role.Name, &actorID); err != nil {
+			return err
+		}
+		if err := grantUserRole(tx, user.ID, &role); err != nil {
+			return err
+		}
+		if user.OrganizationID == nil {
+			if err := tx.Model(&user).Update("organization_id", orgID).Error; err != nil {
+				return err
+			}
+		}
+		return writeAuditLog(tx, &actorID, AuditOrganizationMemberAdded, "user", user.ID.String(), &orgID, map[string]interface{}{
+			"role_name": role.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Preload("Roles").Preload("Organization").First(&user, user.ID).Error; err != nil {
+		return nil, err
+	}
+	resp := user.ToResponse()
+	resp.OrganizationRole = role.Name
+	active := true
+	resp.MemberActive = &active
+	return &resp, nil
 }
 
 // UpdateOrganization updates an organization's details
-func (s *OrganizationService) UpdateOrganization(orgID uuid.UUID, req *models.UpdateOrganizationRequest) (*models.OrganizationResponse, error) {
+func (s *OrganizationService) UpdateOrganization(ctx context.Context, orgID uuid.UUID, req *models.UpdateOrganizationRequest) (*models.OrganizationResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Find the organization
 	var org models.Organization
-	if err := s.db.First(&org, "id = ?", orgID).Error; err != nil {
+	if err := db.First(&org, "id = ?", orgID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("Organization not found")
 		}
@@ -333,14 +411,22 @@ func (s *OrganizationService) UpdateOrganization(orgID uuid.UUID, req *models.Up
 	if req.LogoURL != "" {
 		org.LogoURL = req.LogoURL
 	}
+	if req.ParentID != nil {
+		if err := s.setParent(db, &org, *req.ParentID); err != nil {
+			return nil, err
+		}
+	}
+	if req.InheritPermissions != nil {
+		org.InheritPermissions = *req.InheritPermissions
+	}
 
 	// Save changes
-	if err := s.db.Save(&org).Error; err != nil {
+	if err := db.Save(&org).Error; err != nil {
 		return nil, err
 	}
 
 	// Load organizer for response
-	if err := s.db.Model(&org).Association("Organizer").Find(&org.Organizer); err != nil {
+	if err := db.Model(&org).Association("Organizer").Find(&org.Organizer); err != nil {
 		return nil, err
 	}
 
@@ -349,22 +435,29 @@ func (s *OrganizationService) UpdateOrganization(orgID uuid.UUID, req *models.Up
 }
 
 // DeleteOrganization deletes an organization
-func (s *OrganizationService) DeleteOrganization(orgID uuid.UUID) error {
-	// Delete organization (this will use soft delete if configured)
-	result := s.db.Delete(&models.Organization{}, "id = ?", orgID)
-	if result.Error != nil {
-		return result.Error
-	}
+func (s *OrganizationService) DeleteOrganization(ctx context.Context, orgID uuid.UUID) error {
+	return database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+
+		// Delete organization (this will use soft delete if configured)
+		result := tx.Delete(&models.Organization{}, "id = ?", orgID)
+		if result.Error != nil {
+			return result.Error
+		}
 
-	if result.RowsAffected == 0 {
-		return errors.New("Organization not found")
-	}
+		if result.RowsAffected == 0 {
+			return errors.New("Organization not found")
+		}
 
-	return nil
+		// Sub-organizations become top-level organizations
+		return tx.Model(&models.Organization{}).Where("parent_id = ?", orgID).Update("parent_id", nil).Error
+	})
 }
 
 // UpdateOrgUserRole updates a user's role within an organization (deprecated, use UpdateOrganizationUser instead)
-func (s *OrganizationService) UpdateOrgUserRole(organizerID uuid.UUID, orgID uuid.UUID, req *models.UpdateUserRoleRequest) error {
+func (s *OrganizationService) UpdateOrgUserRole(ctx context.Context, organizerID uuid.UUID, orgID uuid.UUID, req *models.UpdateUserRoleRequest) error {
+	db := s.db.WithContext(ctx)
+
 	// Parse user ID
 	userID, err := uuid.Parse(req.UserID)
 	if err != nil {
@@ -373,75 +466,131 @@ func (s *OrganizationService) UpdateOrgUserRole(organizerID uuid.UUID, orgID uui
 
 	// Check if the organization exists and the organizer is authorized
 	var org models.Organization
-	if err := s.db.First(&org, "id = ? AND organizer_id = ?", orgID, organizerID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("Organization not found or you are not authorized to manage this organization")
-		}
-		return err
-	}
-
-	// Check if the user exists and belongs to the organization
-	var user models.User
-	if err := s.db.First(&user, "id = ? AND organization_id = ?", userID, orgID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("User not found in this organization")
-		}
+	if err := findManagedOrganization(db, orgID, organizerID, &org); err != nil {
 		return err
 	}
 
 	// Get the role
 	var role models.Role
-	if err := s.db.Where("name = ?", req.RoleName).First(&role).Error; err != nil {
+	if err := db.Where("name = ?", req.RoleName).First(&role).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return fmt.Errorf("role '%s' not found", req.RoleName)
 		}
 		return err
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
+	return database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
 
-	// Remove existing roles
-	if err := tx.Model(&user).Association("Roles").Clear(); err != nil {
-		tx.Rollback()
+		// Check if the user belongs to the organization
+		member, err := findOrganizationMember(tx, orgID, userID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(member).Update("role_name", role.Name).Error; err != nil {
+			return err
+		}
+		return grantUserRole(tx, userID, &role)
+	})
+}
+
+// grantUserRole gives a user a global role unless they already hold it. Global roles decide what
+// members may do outside organization routes; within an organization their membership role counts.
+func grantUserRole(tx *gorm.DB, userID uuid.UUID, role *models.Role) error {
+	var held int64
+	if err := tx.Table("user_roles").Where("user_id = ? AND role_id = ?", userID, role.ID).Count(&held).Error; err != nil {
 		return err
 	}
+	if held > 0 {
+		return nil
+	}
+	return tx.Model(&models.User{ID: userID}).Association("Roles").Append(role)
+}
 
-	// Assign new role
-	if err := tx.Model(&user).Association("Roles").Append(&role); err != nil {
-		tx.Rollback()
-		return err
+// addOrganizationMember makes a user a member of an organization with a role, or updates the role
+// and reactivates them when they already are one
+func addOrganizationMember(tx *gorm.DB, orgID, userID uuid.UUID, roleName string, createdBy *uuid.UUID) error {
+	member := models.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         userID,
+		RoleName:       roleName,
+		Active:         true,
+		CreatedBy:      createdBy,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"role_name": roleName, "active": true}),
+	}).Create(&member).Error
+}
+
+// findOrganizationMember loads a user's membership of an organization
+func findOrganizationMember(tx *gorm.DB, orgID, userID uuid.UUID) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	if err := tx.First(&member, "organization_id = ? AND user_id = ?", orgID, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotOrganizationMember
+		}
+		return nil, err
 	}
+	return &member, nil
+}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return err
+// findManagedOrganization loads an organization the user may manage
+func findManagedOrganization(db *gorm.DB, orgID, userID uuid.UUID, org *models.Organization) error {
+	err := db.Where("id = ?", orgID).Where(managedOrganizationsCondition(db, userID)).First(org).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("Organization not found or you are not authorized to manage this organization")
 	}
+	return err
+}
 
-	return nil
+// managesOrganization reports whether the user may manage an organization
+func managesOrganization(db *gorm.DB, orgID, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&models.Organization{}).Where("id = ?", orgID).Where(managedOrganizationsCondition(db, userID)).Count(&count).Error
+	return count > 0, err
+}
+
+// managedOrganizationsCondition matches the organizations a user may manage: those they created and
+// those they are an active organizer member of
+func managedOrganizationsCondition(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true}).Where("organizer_id = ? OR id IN (?)", userID,
+		db.Session(&gorm.Session{NewDB: true}).Model(&models.OrganizationMember{}).Select("organization_id").
+			Where("user_id = ? AND role_name = ? AND active", userID, models.MemberRoleOrganizer))
 }
 
 // GetOrganizationUsersForOrganizer gets all users in an organization for a specific organizer (deprecated)
-func (s *OrganizationService) GetOrganizationUsersForOrganizer(organizerID uuid.UUID, orgID uuid.UUID) ([]models.UserResponse, error) {
+func (s *OrganizationService) GetOrganizationUsersForOrganizer(ctx context.Context, organizerID uuid.UUID, orgID uuid.UUID) ([]models.UserResponse, error) {
+	db := s.db.WithContext(ctx)
+
 	// Check if the organization exists and the organizer is authorized
 	var org models.Organization
-	if err := s.db.First(&org, "id = ? AND organizer_id = ?", orgID, organizerID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("Organization not found or you are not authorized to manage this organization")
-		}
+	if err := findManagedOrganization(db, orgID, organizerID, &org); err != nil {
 		return nil, err
 	}
 
-	// Get all users in the organization
-	var users []models.User
-	if err := s.db.Preload("Roles").Where("organization_id = ?", orgID).Find(&users).Error; err != nil {
+	return s.organizationUsers(db, &org)
+}
+
+// organizationUsers returns the members of an organization with their global roles and their role
+// in the organization. Users and their roles are preloaded in batched queries, so the number of
+// queries does not grow with the number of members.
+func (s *OrganizationService) organizationUsers(db *gorm.DB, org *models.Organization) ([]models.UserResponse, error) {
+	var members []models.OrganizationMember
+	if err := db.Preload("User.Roles").Preload("User.Organization").
+		Where("organization_id = ?", org.ID).Order("created_at ASC").Find(&members).Error; err != nil {
 		return nil, err
 	}
 
-	// Convert to response objects
-	responses := make([]models.UserResponse, len(users))
-	for i, user := range users {
-		responses[i] = user.ToResponse()
+	responses := make([]models.UserResponse, 0, len(members))
+	for i := range members {
+		if members[i].User == nil {
+			continue
+		}
+		resp := members[i].User.ToResponse()
+		resp.OrganizationRole = members[i].RoleName
+		resp.MemberActive = &members[i].Active
+		responses = append(responses, resp)
 	}
 
 	return responses, nil
@@ -1,55 +1,120 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"gorm.io/gorm"
 )
 
 // OTPExpiryTime is defined in otp_service.go and used here
 
-// GenerateAndSendOTP is a unified function for generating and sending OTPs
-func (s *AuthService) GenerateAndSendOTP(req *models.OTPSendRequest) (*models.OTPResponse, error) {
+// otpSentMessage is returned for every accepted OTP request, whether or not a code was sent,
+// so responses don't reveal which identifiers have accounts or have hit their quota
+const otpSentMessage = "If the identifier is eligible, a code has been sent"
+
+// GenerateAndSendOTP is a unified function for generating and sending OTPs.
+// Requests over the per-IP quota are rejected; requests for identifiers over their quota or
+// without a matching account are silently dropped and receive the same response as a real send.
+func (s *AuthService) GenerateAndSendOTP(ctx context.Context, req *models.OTPSendRequest, clientIP string) (*models.OTPResponse, error) {
 	// Validate identifier
 	if req.Identifier == "" {
 		return nil, errors.New("Identifier is required")
 	}
 
+	// Reject unknown types before they count against any quota
+	switch req.OTPType {
+	case OTPTypeRegistration, OTPTypePasswordReset, OTPTypeTwoFactorAuth:
+	case OTPTypePhoneVerification:
+		// Handle SMS OTP sending if implemented
+		return nil, fmt.Errorf("SMS OTP not yet implemented")
+	default:
+		return nil, fmt.Errorf("unknown OTP type: %s", req.OTPType)
+	}
+
+	// Quotas and codes both live in Redis
+	if err := s.otpService.Ready(ctx); err != nil {
+		return nil, err
+	}
+
+	response := &models.OTPResponse{
+		Success:   true,
+		Message:   otpSentMessage,
+		ExpiresIn: int(OTPExpiryTime.Seconds()),
+	}
+
+	if !s.otpQuotaService.AllowIP(ctx, clientIP) {
+		return nil, utils.NewRateLimitError("Too many OTP requests")
+	}
+	if !s.otpQuotaService.AllowIdentifier(ctx, req.Identifier) {
+		return response, nil
+	}
+
+	eligible, err := s.otpEligible(ctx, req.Identifier, req.OTPType)
+	if err != nil {
+		return nil, err
+	}
+	if !eligible {
+		s.otpQuotaService.RecordSuppressed(ctx)
+		return response, nil
+	}
+
 	// Generate OTP
 	otp := s.otpService.GenerateOTP(6) // 6-digit OTP
 
 	// Save OTP to Redis
-	if err := s.otpService.SaveOTP(req.Identifier, req.OTPType, otp); err != nil {
+	if err := s.otpService.SaveOTP(ctx, req.Identifier, req.OTPType, otp); err != nil {
 		return nil, fmt.Errorf("failed to save OTP: %w", err)
 	}
 
 	// Handle sending OTP based on type
-	var err error
 	switch req.OTPType {
-	case "registration":
+	case OTPTypeRegistration:
 		err = s.sendVerificationOTPEmail(req.Identifier, otp)
-	case "password_reset":
+	case OTPTypePasswordReset:
 		err = s.sendPasswordResetOTPEmail(req.Identifier, otp)
-	case "phone_verification":
-		// Handle SMS OTP sending if implemented
-		err = fmt.Errorf("SMS OTP not yet implemented")
-	case "2fa":
+	case OTPTypeTwoFactorAuth:
 		err = s.sendTwoFactorOTPEmail(req.Identifier, otp)
-	default:
-		err = fmt.Errorf("unknown OTP type: %s", req.OTPType)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Return success response with expiry time
-	return &models.OTPResponse{
-		Success:   true,
-		Message:   fmt.Sprintf("OTP sent to %s", req.Identifier),
-		ExpiresIn: int(OTPExpiryTime.Seconds()),
-	}, nil
+	s.otpQuotaService.RecordIssued(ctx)
+
+	// Return the same response as suppressed requests
+	return response, nil
+}
+
+// GetOTPMetrics returns hourly OTP issuance counters
+func (s *AuthService) GetOTPMetrics(ctx context.Context, hours int) (*models.OTPMetricsResponse, error) {
+	return s.otpQuotaService.Metrics(ctx, hours)
+}
+
+// otpEligible reports whether the identifier has an account the OTP type applies to, so codes
+// are only emailed to addresses that signed up rather than to arbitrary inboxes
+func (s *AuthService) otpEligible(ctx context.Context, identifier string, otpType string) (bool, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.Where("email = ?", strings.ToLower(identifier)).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Registration codes only make sense until the email is verified
+	if otpType == OTPTypeRegistration {
+		return !user.IsEmailVerified, nil
+	}
+	return true, nil
 }
 
 // sendTwoFactorOTPEmail sends an email with 2FA OTP
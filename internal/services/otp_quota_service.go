@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	redislib "github.com/redis/go-redis/v9"
+)
+
+// OTP metric counters, kept per hour in Redis
+const (
+	otpMetricIssued      = "issued"
+	otpMetricSuppressed  = "suppressed"
+	otpMetricIPThrottled = "ip_throttled"
+
+	otpMetricsRetention = 7 * 24 * time.Hour
+)
+
+// OTPQuotaService enforces OTP send quotas per client IP and per identifier, and tracks
+// issuance volume to alert on abuse. Redis errors fail open so an outage never blocks sign-in.
+type OTPQuotaService struct {
+	redisClient       redislib.UniversalClient
+	cfg               config.OTPConfig
+	emailQueueService *EmailQueueService
+}
+
+// NewOTPQuotaService creates a new OTP quota service
+func NewOTPQuotaService(cfg *config.Config, emailQueueService *EmailQueueService) *OTPQuotaService {
+	return &OTPQuotaService{
+		redisClient:       redis.RateLimit,
+		cfg:               cfg.OTP,
+		emailQueueService: emailQueueService,
+	}
+}
+
+// AllowIP counts an OTP request from a client IP and reports whether it is within quota
+func (s *OTPQuotaService) AllowIP(ctx context.Context, ip string) bool {
+	count, err := s.incrWindow(ctx, "otp:quota:ip:"+ip, s.cfg.IPWindow)
+	if err != nil {
+		log.Printf("OTP IP quota check failed: %v", err)
+		return true
+	}
+
+	if count > int64(s.cfg.IPSendLimit) {
+		s.record(ctx, otpMetricIPThrottled)
+		if count == int64(s.cfg.IPSendLimit)+1 {
+			log.Printf("OTP IP quota exceeded: IP=%s, Limit=%d/%s", ip, s.cfg.IPSendLimit, s.cfg.IPWindow)
+		}
+		return false
+	}
+	return true
+}
+
+// AllowIdentifier reports whether another code may be sent to an identifier, enforcing both
+// the resend cooldown and the windowed quota. Rejections are counted as suppressed sends.
+func (s *OTPQuotaService) AllowIdentifier(ctx context.Context, identifier string) bool {
+	key := hashIdentifier(identifier)
+
+	fresh, err := s.redisClient.SetNX(ctx, "otp:cooldown:"+key, 1, s.cfg.ResendCooldown).Result()
+	if err != nil {
+		log.Printf("OTP cooldown check failed: %v", err)
+		return true
+	}
+	if !fresh {
+		s.record(ctx, otpMetricSuppressed)
+		return false
+	}
+
+	count, err := s.incrWindow(ctx, "otp:quota:identifier:"+key, s.cfg.IdentifierWindow)
+	if err != nil {
+		log.Printf("OTP identifier quota check failed: %v", err)
+		return true
+	}
+	if count > int64(s.cfg.IdentifierSendLimit) {
+		s.record(ctx, otpMetricSuppressed)
+		return false
+	}
+	return true
+}
+
+// RecordSuppressed counts a request answered without sending a code
+func (s *OTPQuotaService) RecordSuppressed(ctx context.Context) {
+	s.record(ctx, otpMetricSuppressed)
+}
+
+// RecordIssued counts a sent code and alerts once per hour when issuance crosses the threshold
+func (s *OTPQuotaService) RecordIssued(ctx context.Context) {
+	count := s.record(ctx, otpMetricIssued)
+	if s.cfg.AlertHourlyThreshold > 0 && count == int64(s.cfg.AlertHourlyThreshold) {
+		s.alert(count)
+	}
+}
+
+// Metrics returns hourly OTP counters for the most recent hours, newest first
+func (s *OTPQuotaService) Metrics(ctx context.Context, hours int) (*models.OTPMetricsResponse, error) {
+	if s.redisClient == nil {
+		return nil, ErrOTPUnavailable
+	}
+	if hours <= 0 || hours > int(otpMetricsRetention/time.Hour) {
+		hours = 24
+	}
+
+	now := time.Now().UTC().Truncate(time.Hour)
+	pipe := s.redisClient.Pipeline()
+	cmds := make([]*redislib.MapStringStringCmd, hours)
+	for i := range cmds {
+		cmds[i] = pipe.HGetAll(ctx, otpMetricsKey(now.Add(-time.Duration(i)*time.Hour)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redislib.Nil {
+		return nil, fmt.Errorf("failed to read OTP metrics: %w", err)
+	}
+
+	resp := &models.OTPMetricsResponse{
+		AlertHourlyThreshold: s.cfg.AlertHourlyThreshold,
+		Buckets:              make([]models.OTPMetricsBucket, hours),
+	}
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		bucket := models.OTPMetricsBucket{Hour: now.Add(-time.Duration(i) * time.Hour)}
+		fmt.Sscanf(values[otpMetricIssued], "%d", &bucket.Issued)
+		fmt.Sscanf(values[otpMetricSuppressed], "%d", &bucket.Suppressed)
+		fmt.Sscanf(values[otpMetricIPThrottled], "%d", &bucket.IPThrottled)
+		resp.Buckets[i] = bucket
+	}
+
+	return resp, nil
+}
+
+// incrWindow increments a fixed-window counter, starting the window on the first hit
+func (s *OTPQuotaService) incrWindow(ctx context.Context, key string, window time.Duration) (int64, error) {
+	pipe := s.redisClient.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// record increments an hourly metric counter and returns its new value
+func (s *OTPQuotaService) record(ctx context.Context, metric string) int64 {
+	key := otpMetricsKey(time.Now().UTC())
+
+	pipe := s.redisClient.TxPipeline()
+	incr := pipe.HIncrBy(ctx, key, metric, 1)
+	pipe.ExpireNX(ctx, key, otpMetricsRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to record OTP metric %s: %v", metric, err)
+		return 0
+	}
+	return incr.Val()
+}
+
+// alert reports abnormal OTP issuance volume
+func (s *OTPQuotaService) alert(count int64) {
+	log.Printf("ALERT: Abnormal OTP issuance volume: %d codes sent this hour (threshold %d)", count, s.cfg.AlertHourlyThreshold)
+
+	if s.cfg.AlertEmail == "" {
+		return
+	}
+
+	message := fmt.Sprintf("%d one-time passcodes have been sent in the current hour, reaching the alert threshold of %d. "+
+		"This may indicate the send-otp endpoint is being abused to spam inboxes. Review the OTP metrics and consider "+
+		"tightening OTP_IP_SEND_LIMIT or OTP_IDENTIFIER_SEND_LIMIT.", count, s.cfg.AlertHourlyThreshold)
+	if err := s.emailQueueService.QueueNotificationEmail(s.cfg.AlertEmail, "Security team", "Abnormal OTP volume", message); err != nil {
+		log.Printf("Failed to queue OTP volume alert: %v", err)
+	}
+}
+
+// otpMetricsKey returns the Redis hash holding the counters of the hour containing t
+func otpMetricsKey(t time.Time) string {
+	return "otp:metrics:" + t.UTC().Format("2006010215")
+}
+
+// hashIdentifier normalizes an identifier and hashes it so quota keys don't store emails in plain text
+func hashIdentifier(identifier string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(identifier))))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strconv"
@@ -16,15 +17,18 @@ const (
 	OTPExpiryTime = 10 * time.Minute // OTPs expire after 10 minutes
 )
 
+// ErrOTPUnavailable is returned when codes cannot be stored or checked because Redis is unreachable
+var ErrOTPUnavailable = errors.New("One-time codes are temporarily unavailable, please try again later")
+
 // OTPService handles OTP generation, storage and verification using Redis
 type OTPService struct {
-	redisClient *redislib.Client
+	redisClient redislib.UniversalClient
 }
 
 // NewOTPService creates a new OTP service
 func NewOTPService() *OTPService {
 	return &OTPService{
-		redisClient: redis.Client,
+		redisClient: redis.OTP,
 	}
 }
 
@@ -43,23 +47,39 @@ func (s *OTPService) GenerateOTP(digits int) string {
 	return strconv.Itoa(otp)
 }
 
+// Ready checks that codes can be stored, so callers can refuse OTP requests before doing any
+// other work. It returns ErrOTPUnavailable while Redis is unreachable.
+func (s *OTPService) Ready(ctx context.Context) error {
+	if s.redisClient == nil {
+		return ErrOTPUnavailable
+	}
+	if err := s.redisClient.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrOTPUnavailable, err)
+	}
+	return nil
+}
+
 // SaveOTP saves an OTP to Redis with an expiry time
-func (s *OTPService) SaveOTP(identifier string, otpType string, otp string) error {
-	ctx := context.Background()
+func (s *OTPService) SaveOTP(ctx context.Context, identifier string, otpType string, otp string) error {
+	if s.redisClient == nil {
+		return ErrOTPUnavailable
+	}
 	key := fmt.Sprintf("%s:%s", otpType, identifier)
 
 	// Store OTP in Redis with expiry
 	err := s.redisClient.Set(ctx, key, otp, OTPExpiryTime).Err()
 	if err != nil {
-		return fmt.Errorf("failed to save OTP: %w", err)
+		return fmt.Errorf("%w: failed to save OTP: %v", ErrOTPUnavailable, err)
 	}
 
 	return nil
 }
 
 // VerifyOTP checks if the provided OTP is valid
-func (s *OTPService) VerifyOTP(identifier string, otpType string, otp string) (bool, error) {
-	ctx := context.Background()
+func (s *OTPService) VerifyOTP(ctx context.Context, identifier string, otpType string, otp string) (bool, error) {
+	if s.redisClient == nil {
+		return false, ErrOTPUnavailable
+	}
 	key := fmt.Sprintf("%s:%s", otpType, identifier)
 
 	// Get OTP from Redis
@@ -69,7 +89,7 @@ func (s *OTPService) VerifyOTP(identifier string, otpType string, otp string) (b
 			// OTP doesn't exist or has expired
 			return false, nil
 		}
-		return false, fmt.Errorf("failed to verify OTP: %w", err)
+		return false, fmt.Errorf("%w: failed to verify OTP: %v", ErrOTPUnavailable, err)
 	}
 
 	// Check if OTP matches
@@ -83,14 +103,16 @@ func (s *OTPService) VerifyOTP(identifier string, otpType string, otp string) (b
 }
 
 // InvalidateOTP removes an OTP from Redis
-func (s *OTPService) InvalidateOTP(identifier string, otpType string) error {
-	ctx := context.Background()
+func (s *OTPService) InvalidateOTP(ctx context.Context, identifier string, otpType string) error {
+	if s.redisClient == nil {
+		return ErrOTPUnavailable
+	}
 	key := fmt.Sprintf("%s:%s", otpType, identifier)
 
 	// Delete OTP from Redis
 	err := s.redisClient.Del(ctx, key).Err()
 	if err != nil {
-		return fmt.Errorf("failed to invalidate OTP: %w", err)
+		return fmt.Errorf("%w: failed to invalidate OTP: %v", ErrOTPUnavailable, err)
 	}
 
 	return nil
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	redislib "github.com/redis/go-redis/v9"
+)
+
+// PasswordScreeningService checks candidate passwords against the HaveIBeenPwned Pwned Passwords
+// corpus using its k-anonymity range API: only the first five characters of the password's SHA-1
+// hash leave the server. Range responses are cached in Redis.
+type PasswordScreeningService struct {
+	enabled     bool
+	baseURL     string
+	cacheTTL    time.Duration
+	minHits     int
+	httpClient  *http.Client
+	redisClient redislib.UniversalClient
+}
+
+// NewPasswordScreeningService creates a new password screening service
+func NewPasswordScreeningService(cfg *config.Config) *PasswordScreeningService {
+	return &PasswordScreeningService{
+		enabled:     cfg.Security.PasswordScreening,
+		baseURL:     strings.TrimRight(cfg.Security.PwnedPasswordsURL, "/"),
+		cacheTTL:    cfg.Security.PwnedPasswordsTTL,
+		minHits:     max(cfg.Security.PwnedPasswordsMinHit, 1),
+		httpClient:  &http.Client{Timeout: 5 * time.Second},
+		redisClient: redis.Client,
+	}
+}
+
+// CheckPassword returns a PASSWORD_BREACHED error when the password appears in a known breach.
+// Lookup failures are logged and the password is allowed, so an outage of the API never blocks sign-up.
+func (s *PasswordScreeningService) CheckPassword(ctx context.Context, password string) error {
+	if !s.enabled {
+		return nil
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	body, err := s.rangeFor(ctx, prefix)
+	if err != nil {
+		log.Printf("Password breach check unavailable, allowing password: %v", err)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		candidate, countStr, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || candidate != suffix {
+			continue
+		}
+		// Padding entries have a count of 0
+		count, _ := strconv.Atoi(countStr)
+		if count >= s.minHits {
+			return utils.NewPasswordBreachedError()
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// rangeFor returns the hash suffixes for a prefix, from the cache when available
+func (s *PasswordScreeningService) rangeFor(ctx context.Context, prefix string) (string, error) {
+	cacheKey := "pwned:range:" + prefix
+	if s.redisClient != nil {
+		if cached, err := s.redisClient.Get(ctx, cacheKey).Result(); err == nil {
+			return cached, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Add-Padding", "true")
+	req.Header.Set("User-Agent", "event-ticketing-backend")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("pwned passwords request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pwned passwords responded with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read pwned passwords response: %w", err)
+	}
+	body := string(data)
+
+	if s.redisClient != nil {
+		if err := s.redisClient.Set(ctx, cacheKey, body, s.cacheTTL).Err(); err != nil {
+			log.Printf("Failed to cache pwned passwords range: %v", err)
+		}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,368 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+)
+
+// ErrPaymentDeclined is returned when the provider refuses a charge (card declined, insufficient funds, ...)
+var ErrPaymentDeclined = errors.New("payment declined")
+
+// ErrPaymentProviderUnavailable is returned when the provider could not be reached or failed on
+// its side (network errors, rate limiting, 5xx). These calls are retried and trip the circuit breaker.
+var ErrPaymentProviderUnavailable = errors.New("payment provider unavailable")
+
+// ChargeRequest describes an off-session charge against a saved payment method
+type ChargeRequest struct {
+	Amount           float64
+	Currency         string
+	CustomerRef      string // Provider customer ID
+	PaymentMethodRef string // Provider saved payment method ID
+	Description      string
+	IdempotencyKey   string
+	Metadata         map[string]string
+}
+
+// ChargeResult is the outcome of a successful charge
+type ChargeResult struct {
+	ProviderRef string
+}
+
+// RefundRequest describes a full or partial refund of a previous charge
+type RefundRequest struct {
+	PaymentRef     string // Provider reference of the charge being refunded
+	Amount         float64
+	Reason         string
+	IdempotencyKey string
+	Metadata       map[string]string
+}
+
+// RefundResult is the outcome of a successful refund
+type RefundResult struct {
+	ProviderRef string
+}
+
+// BalanceTransaction is a movement of funds in the provider account (charge, refund, fee, payout, ...)
+type BalanceTransaction struct {
+	ID          string
+	Type        string // Provider transaction type, e.g. charge, refund, payout
+	PaymentRef  string // Payment the transaction belongs to, matching Payment.ProviderRef
+	Amount      float64
+	Fee         float64
+	Net         float64
+	Currency    string
+	CreatedAt   time.Time
+	Description string
+}
+
+// PaymentProvider charges saved payment methods through an external payment processor
+type PaymentProvider interface {
+	Name() string
+	Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error)
+	Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error)
+	ListBalanceTransactions(ctx context.Context, from, to time.Time) ([]BalanceTransaction, error)
+}
+
+// NewPaymentProvider returns the payment provider selected in the configuration, guarded by a
+// circuit breaker and retries
+func NewPaymentProvider(cfg *config.Config) (PaymentProvider, error) {
+	var provider PaymentProvider
+	switch cfg.Payment.Provider {
+	case "stripe":
+		if cfg.Payment.StripeSecretKey == "" {
+			return nil, errors.New("STRIPE_SECRET_KEY is not configured")
+		}
+		provider = &stripeProvider{
+			secretKey:  cfg.Payment.StripeSecretKey,
+			baseURL:    strings.TrimRight(cfg.Payment.StripeAPIURL, "/"),
+			httpClient: &http.Client{Timeout: 30 * time.Second},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported payment provider: %s", cfg.Payment.Provider)
+	}
+
+	return &resilientProvider{
+		PaymentProvider: provider,
+		breaker: utils.GetCircuitBreaker("payment:"+provider.Name(), utils.CircuitBreakerSettings{
+			FailureThreshold: cfg.Resilience.BreakerFailures,
+			OpenTimeout:      cfg.Resilience.BreakerOpenTimeout,
+			MaxConcurrent:    cfg.Resilience.PaymentMaxConcurrent,
+			IsFailure:        isPaymentProviderFailure,
+		}),
+		attempts:  cfg.Resilience.RetryAttempts,
+		baseDelay: cfg.Resilience.RetryBaseDelay,
+	}, nil
+}
+
+// resilientProvider retries transient provider failures and stops calling a provider that keeps
+// failing. Retried charges and refunds reuse their idempotency key, so they are applied once.
+type resilientProvider struct {
+	PaymentProvider
+	breaker   *utils.CircuitBreaker
+	attempts  int
+	baseDelay time.Duration
+}
+
+// isPaymentProviderFailure reports whether err means the provider itself is failing, as opposed to
+// declines and invalid requests, which it answered normally
+func isPaymentProviderFailure(err error) bool {
+	return errors.Is(err, ErrPaymentProviderUnavailable) && !errors.Is(err, context.Canceled)
+}
+
+func (p *resilientProvider) call(ctx context.Context, fn func() error) error {
+	return utils.Retry(ctx, p.attempts, p.baseDelay, isPaymentProviderFailure, func() error {
+		return p.breaker.Execute(fn)
+	})
+}
+
+func (p *resilientProvider) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	var result *ChargeResult
+	err := p.call(ctx, func() error {
+		var err error
+		result, err = p.PaymentProvider.Charge(ctx, req)
+		return err
+	})
+	return result, err
+}
+
+func (p *resilientProvider) Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error) {
+	var result *RefundResult
+	err := p.call(ctx, func() error {
+		var err error
+		result, err = p.PaymentProvider.Refund(ctx, req)
+		return err
+	})
+	return result, err
+}
+
+func (p *resilientProvider) ListBalanceTransactions(ctx context.Context, from, to time.Time) ([]BalanceTransaction, error) {
+	var transactions []BalanceTransaction
+	err := p.call(ctx, func() error {
+		var err error
+		transactions, err = p.PaymentProvider.ListBalanceTransactions(ctx, from, to)
+		return err
+	})
+	return transactions, err
+}
+
+// stripeProvider charges through Stripe PaymentIntents
+type stripeProvider struct {
+	secretKey  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// stripeError is the error envelope returned by the Stripe API
+type stripeError struct {
+	Error struct {
+		Type        string `json:"type"`
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *stripeProvider) Name() string {
+	return "stripe"
+}
+
+// Charge creates and confirms an off-session PaymentIntent
+func (p *stripeProvider) Charge(ctx context.Context, req *ChargeRequest) (*ChargeResult, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(toMinorUnits(req.Amount), 10))
+	form.Set("currency", strings.ToLower(req.Currency))
+	form.Set("customer", req.CustomerRef)
+	form.Set("payment_method", req.PaymentMethodRef)
+	form.Set("off_session", "true")
+	form.Set("confirm", "true")
+	if req.Description != "" {
+		form.Set("description", req.Description)
+	}
+	for key, value := range req.Metadata {
+		form.Set(fmt.Sprintf("metadata[%s]", key), value)
+	}
+
+	var intent struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/v1/payment_intents", form, req.IdempotencyKey, &intent); err != nil {
+		return nil, err
+	}
+
+	if intent.Status != "succeeded" {
+		return nil, fmt.Errorf("%w: payment intent %s is %s", ErrPaymentDeclined, intent.ID, intent.Status)
+	}
+
+	return &ChargeResult{ProviderRef: intent.ID}, nil
+}
+
+// Refund refunds part or all of a PaymentIntent
+func (p *stripeProvider) Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error) {
+	form := url.Values{}
+	form.Set("payment_intent", req.PaymentRef)
+	form.Set("amount", strconv.FormatInt(toMinorUnits(req.Amount), 10))
+	if req.Reason != "" {
+		form.Set("metadata[reason]", req.Reason)
+	}
+	for key, value := range req.Metadata {
+		form.Set(fmt.Sprintf("metadata[%s]", key), value)
+	}
+
+	var refund struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := p.do(ctx, http.MethodPost, "/v1/refunds", form, req.IdempotencyKey, &refund); err != nil {
+		return nil, err
+	}
+
+	if refund.Status == "failed" || refund.Status == "canceled" {
+		return nil, fmt.Errorf("refund %s is %s", refund.ID, refund.Status)
+	}
+
+	return &RefundResult{ProviderRef: refund.ID}, nil
+}
+
+// ListBalanceTransactions pages through the balance transactions created in [from, to).
+// The source charge is expanded so transactions can be matched on their payment intent.
+func (p *stripeProvider) ListBalanceTransactions(ctx context.Context, from, to time.Time) ([]BalanceTransaction, error) {
+	var transactions []BalanceTransaction
+	startingAfter := ""
+
+	for {
+		query := url.Values{}
+		query.Set("limit", "100")
+		query.Set("created[gte]", strconv.FormatInt(from.Unix(), 10))
+		query.Set("created[lt]", strconv.FormatInt(to.Unix(), 10))
+		query.Add("expand[]", "data.source")
+		if startingAfter != "" {
+			query.Set("starting_after", startingAfter)
+		}
+
+		var page struct {
+			Data []struct {
+				ID          string `json:"id"`
+				Type        string `json:"type"`
+				Amount      int64  `json:"amount"`
+				Fee         int64  `json:"fee"`
+				Net         int64  `json:"net"`
+				Currency    string `json:"currency"`
+				Created     int64  `json:"created"`
+				Description string `json:"description"`
+				Source      struct {
+					ID            string `json:"id"`
+					PaymentIntent string `json:"payment_intent"`
+				} `json:"source"`
+			} `json:"data"`
+			HasMore bool `json:"has_more"`
+		}
+		if err := p.do(ctx, http.MethodGet, "/v1/balance_transactions", query, "", &page); err != nil {
+			return nil, err
+		}
+
+		for _, item := range page.Data {
+			paymentRef := item.Source.PaymentIntent
+			if paymentRef == "" {
+				paymentRef = item.Source.ID
+			}
+			transactions = append(transactions, BalanceTransaction{
+				ID:          item.ID,
+				Type:        item.Type,
+				PaymentRef:  paymentRef,
+				Amount:      fromMinorUnits(item.Amount),
+				Fee:         fromMinorUnits(item.Fee),
+				Net:         fromMinorUnits(item.Net),
+				Currency:    strings.ToUpper(item.Currency),
+				CreatedAt:   time.Unix(item.Created, 0).UTC(),
+				Description: item.Description,
+			})
+		}
+
+		if !page.HasMore || len(page.Data) == 0 {
+			break
+		}
+		startingAfter = page.Data[len(page.Data)-1].ID
+	}
+
+	return transactions, nil
+}
+
+// do sends a form-encoded request to the Stripe API and decodes the JSON response into out
+func (p *stripeProvider) do(ctx context.Context, method, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	var body io.Reader
+	endpoint := p.baseURL + path
+	if method == http.MethodGet {
+		if len(form) > 0 {
+			endpoint += "?" + form.Encode()
+		}
+	} else {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("failed to build stripe request: %w", err)
+	}
+	req.SetBasicAuth(p.secretKey, "")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: stripe request failed: %w", ErrPaymentProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read stripe response: %w", ErrPaymentProviderUnavailable, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr stripeError
+		_ = json.Unmarshal(data, &apiErr)
+		if apiErr.Error.Type == "card_error" {
+			reason := apiErr.Error.DeclineCode
+			if reason == "" {
+				reason = apiErr.Error.Code
+			}
+			return fmt.Errorf("%w: %s (%s)", ErrPaymentDeclined, apiErr.Error.Message, reason)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: stripe responded with status %d: %s", ErrPaymentProviderUnavailable, resp.StatusCode, apiErr.Error.Message)
+		}
+		return fmt.Errorf("stripe responded with status %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode stripe response: %w", err)
+	}
+	return nil
+}
+
+// toMinorUnits converts an amount to the provider's smallest currency unit (cents)
+func toMinorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// fromMinorUnits converts an amount in the provider's smallest currency unit back to a decimal amount
+func fromMinorUnits(amount int64) float64 {
+	return float64(amount) / 100
+}
@@ -0,0 +1,606 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPresaleNotFound is returned for a presale that does not exist in the organization
+	ErrPresaleNotFound = errors.New("Presale not found")
+	// ErrPresaleAccessCodeNotFound is returned for an access code that does not exist in the presale
+	ErrPresaleAccessCodeNotFound = errors.New("Access code not found")
+)
+
+// presaleAdmission is how a buyer was admitted to the presale of an event
+type presaleAdmission struct {
+	PresaleID    uuid.UUID
+	AccessCodeID *uuid.UUID // Empty for buyers on the allowlist
+}
+
+// PresaleService manages organizers' presale windows and admits buyers to them at checkout.
+// During a presale window an event only sells to buyers entering one of the presale's access
+// codes or whose email is on its allowlist.
+type PresaleService struct {
+	db *gorm.DB
+}
+
+// NewPresaleService creates a new presale service
+func NewPresaleService() *PresaleService {
+	return &PresaleService{db: database.DB}
+}
+
+// CreatePresale adds a presale window to an event of the organization
+func (s *PresaleService) CreatePresale(ctx context.Context, orgID, userID uuid.UUID, req *models.PresaleRequest) (*models.Presale, error) {
+	presale := models.Presale{OrganizationID: orgID, CreatedBy: &userID}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := assignPresale(tx, &presale, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&presale).Error; err != nil {
+			return fmt.Errorf("failed to create presale: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "presale.create", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"event_id":  presale.EventID,
+			"starts_at": presale.StartsAt,
+			"ends_at":   presale.EndsAt,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &presale, nil
+}
+
+// ListPresales returns the presales of an organization with their access codes, optionally only
+// those of an event
+func (s *PresaleService) ListPresales(ctx context.Context, orgID uuid.UUID, query *models.PresaleQuery) ([]models.Presale, error) {
+	db := s.db.WithContext(ctx)
+
+	q := db.Preload("AccessCodes", func(db *gorm.DB) *gorm.DB { return db.Order("code") }).
+		Where("organization_id = ?", orgID)
+	if query.EventID != 0 {
+		q = q.Where("event_id = ?", query.EventID)
+	}
+
+	presales := []models.Presale{}
+	if err := q.Order("starts_at").Find(&presales).Error; err != nil {
+		return nil, err
+	}
+	for i := range presales {
+		if err := countAllowlist(db, &presales[i]); err != nil {
+			return nil, err
+		}
+	}
+	return presales, nil
+}
+
+// GetPresale returns a presale of the organization with its access codes
+func (s *PresaleService) GetPresale(ctx context.Context, orgID, presaleID uuid.UUID) (*models.Presale, error) {
+	db := s.db.WithContext(ctx)
+
+	var presale models.Presale
+	if err := findPresale(db.Preload("AccessCodes", func(db *gorm.DB) *gorm.DB { return db.Order("code") }), orgID, presaleID, &presale); err != nil {
+		return nil, err
+	}
+	if err := countAllowlist(db, &presale); err != nil {
+		return nil, err
+	}
+	return &presale, nil
+}
+
+// UpdatePresale replaces the event, name and window of a presale. Its access codes, their uses
+// and its allowlist are kept.
+func (s *PresaleService) UpdatePresale(ctx context.Context, orgID, presaleID, userID uuid.UUID, req *models.PresaleRequest) (*models.Presale, error) {
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presale models.Presale
+		if err := findPresale(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID, presaleID, &presale); err != nil {
+			return err
+		}
+		if err := assignPresale(tx, &presale, req); err != nil {
+			return err
+		}
+		if err := tx.Save(&presale).Error; err != nil {
+			return fmt.Errorf("failed to update presale: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "presale.update", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"event_id":  presale.EventID,
+			"starts_at": presale.StartsAt,
+			"ends_at":   presale.EndsAt,
+			"active":    presale.Active,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetPresale(ctx, orgID, presaleID)
+}
+
+// DeletePresale removes a presale with its access codes and allowlist, putting its window on
+// general sale. Orders already placed keep their presale.
+func (s *PresaleService) DeletePresale(ctx context.Context, orgID, presaleID, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presale models.Presale
+		if err := findPresale(tx, orgID, presaleID, &presale); err != nil {
+			return err
+		}
+		if err := tx.Where("presale_id = ?", presale.ID).Delete(&models.PresaleAccessCode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("presale_id = ?", presale.ID).Delete(&models.PresaleAllowlistEntry{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&presale).Error; err != nil {
+			return fmt.Errorf("failed to delete presale: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "presale.delete", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"event_id": presale.EventID,
+		})
+		return nil
+	})
+}
+
+// CreateAccessCode adds an access code to a presale of the organization
+func (s *PresaleService) CreateAccessCode(ctx context.Context, orgID, presaleID, userID uuid.UUID, req *models.PresaleAccessCodeRequest) (*models.PresaleAccessCode, error) {
+	code := models.PresaleAccessCode{
+		PresaleID: presaleID,
+		Code:      normalizePromoCode(req.Code),
+		MaxUses:   req.MaxUses,
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presale models.Presale
+		if err := findPresale(tx, orgID, presaleID, &presale); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.PresaleAccessCode{}).
+			Where("presale_id = ? AND code = ?", presaleID, code.Code).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("an access code %q already exists", code.Code)
+		}
+
+		if err := tx.Create(&code).Error; err != nil {
+			return fmt.Errorf("failed to create access code: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "presale.access_code_create", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"code":     code.Code,
+			"max_uses": code.MaxUses,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// DeleteAccessCode removes an access code from a presale. Orders already placed with it keep it.
+func (s *PresaleService) DeleteAccessCode(ctx context.Context, orgID, presaleID, codeID, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presale models.Presale
+		if err := findPresale(tx, orgID, presaleID, &presale); err != nil {
+			return err
+		}
+
+		var code models.PresaleAccessCode
+		if err := tx.First(&code, "id = ? AND presale_id = ?", codeID, presaleID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPresaleAccessCodeNotFound
+			}
+			return err
+		}
+		if err := tx.Delete(&code).Error; err != nil {
+			return fmt.Errorf("failed to delete access code: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "presale.access_code_delete", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"code":       code.Code,
+			"used_count": code.UsedCount,
+		})
+		return nil
+	})
+}
+
+// ListAllowlist returns a page of a presale's allowlisted emails in alphabetical order
+func (s *PresaleService) ListAllowlist(ctx context.Context, orgID, presaleID uuid.UUID, query *models.PresaleAllowlistQuery) ([]models.PresaleAllowlistEntry, *models.PageMeta, error) {
+	db := s.db.WithContext(ctx)
+
+	var presale models.Presale
+	if err := findPresale(db, orgID, presaleID, &presale); err != nil {
+		return nil, nil, err
+	}
+
+	q := db.Model(&models.PresaleAllowlistEntry{}).Where("presale_id = ?", presaleID)
+	if query.Search != "" {
+		q = q.Where("email ILIKE ?", containsPattern(query.Search))
+	}
+
+	entries := []models.PresaleAllowlistEntry{}
+	meta, err := database.Paginate(q.Order("email"), query.PageQuery, &entries)
+	if err != nil {
+		return nil, nil, err
+	}
+	return entries, meta, nil
+}
+
+// AddToAllowlist allowlists emails for a presale. Emails already on it are skipped.
+func (s *PresaleService) AddToAllowlist(ctx context.Context, orgID, presaleID, userID uuid.UUID, req *models.PresaleAllowlistRequest) (*models.PresaleAllowlistResponse, error) {
+	emails := uniqueEmails(req.Emails)
+	entries := make([]models.PresaleAllowlistEntry, len(emails))
+	for i, email := range emails {
+		entries[i] = models.PresaleAllowlistEntry{PresaleID: presaleID, Email: email}
+	}
+
+	resp := &models.PresaleAllowlistResponse{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presale models.Presale
+		if err := findPresale(tx, orgID, presaleID, &presale); err != nil {
+			return err
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&entries, 500)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update allowlist: %w", result.Error)
+		}
+		resp.Changed = int(result.RowsAffected)
+
+		recordAuditLog(tx, &userID, "presale.allowlist_add", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"added": resp.Changed,
+		})
+		return countAllowlist(tx, &presale)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.allowlistResponse(ctx, presaleID, resp)
+}
+
+// RemoveFromAllowlist takes emails off a presale's allowlist. Emails not on it are skipped.
+func (s *PresaleService) RemoveFromAllowlist(ctx context.Context, orgID, presaleID, userID uuid.UUID, req *models.PresaleAllowlistRequest) (*models.PresaleAllowlistResponse, error) {
+	resp := &models.PresaleAllowlistResponse{}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presale models.Presale
+		if err := findPresale(tx, orgID, presaleID, &presale); err != nil {
+			return err
+		}
+
+		result := tx.Where("presale_id = ? AND email IN ?", presaleID, uniqueEmails(req.Emails)).
+			Delete(&models.PresaleAllowlistEntry{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to update allowlist: %w", result.Error)
+		}
+		resp.Changed = int(result.RowsAffected)
+
+		recordAuditLog(tx, &userID, "presale.allowlist_remove", "presale", presale.ID.String(), &orgID, map[string]interface{}{
+			"removed": resp.Changed,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.allowlistResponse(ctx, presaleID, resp)
+}
+
+// Analytics compares the checkouts and orders of an event of the organization during its presale
+// windows with those on general sale
+func (s *PresaleService) Analytics(ctx context.Context, orgID uuid.UUID, eventID uint) (*models.PresaleAnalytics, error) {
+	db := s.db.WithContext(ctx)
+
+	var event models.Event
+	if err := db.Select("id").Where("id = ? AND organization_id = ?", eventID, orgID).First(&event).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("Event %d not found", eventID)
+		}
+		return nil, err
+	}
+
+	analytics := &models.PresaleAnalytics{
+		EventID:     eventID,
+		AccessCodes: []models.PresaleCodeUsage{},
+		Presales:    []models.PresaleWindowStat{},
+	}
+
+	var sessions []struct {
+		Presale   bool
+		Started   int64
+		Completed int64
+	}
+	if err := db.Model(&models.CheckoutSession{}).
+		Select("presale_id IS NOT NULL AS presale, COUNT(*) AS started, COUNT(completed_at) AS completed").
+		Where("items::jsonb @> ?::jsonb", fmt.Sprintf(`[{"event_id":%d}]`, eventID)).
+		Group("presale").Scan(&sessions).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range sessions {
+		stats := &analytics.GeneralSale
+		if row.Presale {
+			stats = &analytics.Presale
+		}
+		stats.CheckoutsStarted = row.Started
+		stats.CheckoutsCompleted = row.Completed
+		if row.Started > 0 {
+			stats.ConversionRate = math.Round(float64(row.Completed)/float64(row.Started)*10000) / 10000
+		}
+	}
+
+	var orders []struct {
+		PresaleID  *uuid.UUID
+		Orders     int64
+		CodeOrders int64
+		Tickets    int64
+		Revenue    float64
+	}
+	if err := db.Model(&models.Order{}).
+		Select("presale_id, COUNT(*) AS orders, COUNT(presale_access_code_id) AS code_orders, "+
+			"COALESCE(SUM(quantity), 0) AS tickets, COALESCE(SUM(total_amount - refunded_amount), 0) AS revenue").
+		Where("event_id = ? AND status <> ?", eventID, models.OrderStatusCancelled).
+		Group("presale_id").Scan(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	var presales []models.Presale
+	if err := db.Preload("AccessCodes", func(db *gorm.DB) *gorm.DB { return db.Order("code") }).
+		Where("event_id = ? AND organization_id = ?", eventID, orgID).
+		Order("starts_at").Find(&presales).Error; err != nil {
+		return nil, err
+	}
+	windows := make(map[uuid.UUID]*models.PresaleWindowStat, len(presales))
+	for _, presale := range presales {
+		analytics.Presales = append(analytics.Presales, models.PresaleWindowStat{PresaleID: presale.ID, Name: presale.Name})
+		for _, code := range presale.AccessCodes {
+			analytics.AccessCodes = append(analytics.AccessCodes, models.PresaleCodeUsage{
+				PresaleID: presale.ID,
+				Code:      code.Code,
+				MaxUses:   code.MaxUses,
+				UsedCount: code.UsedCount,
+			})
+		}
+	}
+	for i := range analytics.Presales {
+		windows[analytics.Presales[i].PresaleID] = &analytics.Presales[i]
+	}
+
+	for _, row := range orders {
+		stats := &analytics.GeneralSale
+		if row.PresaleID != nil {
+			stats = &analytics.Presale
+			// Presales deleted since are only counted in the totals
+			if window, ok := windows[*row.PresaleID]; ok {
+				window.Orders = row.Orders
+				window.CodeOrders = row.CodeOrders
+				window.AllowlistOrders = row.Orders - row.CodeOrders
+				window.Tickets = row.Tickets
+				window.Revenue = math.Round(row.Revenue*100) / 100
+			}
+		}
+		stats.Orders += row.Orders
+		stats.Tickets += row.Tickets
+		stats.Revenue = math.Round((stats.Revenue+row.Revenue)*100) / 100
+	}
+	return analytics, nil
+}
+
+// Admit checks a buyer may order the selected events now and returns how they were admitted to
+// the events in presale. Buyers on a presale's allowlist need no code; others need an access code
+// of one of the event's open presales with uses left. Events not in presale are left out.
+func (s *PresaleService) Admit(db *gorm.DB, eventIDs []uint, buyerEmail, accessCode string, now time.Time) (map[uint]*presaleAdmission, error) {
+	presales, err := openPresales(db, eventIDs, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(presales) == 0 {
+		return nil, nil
+	}
+
+	byEvent := make(map[uint][]models.Presale)
+	for _, presale := range presales {
+		byEvent[presale.EventID] = append(byEvent[presale.EventID], presale)
+	}
+
+	email := strings.ToLower(strings.TrimSpace(buyerEmail))
+	code := normalizePromoCode(accessCode)
+	admissions := make(map[uint]*presaleAdmission, len(byEvent))
+	for eventID, open := range byEvent {
+		admission, err := admitToPresale(db, open, email, code)
+		if err != nil {
+			return nil, err
+		}
+		if admission == nil {
+			var event models.Event
+			if err := db.Select("id", "title").First(&event, eventID).Error; err != nil {
+				return nil, err
+			}
+			if code != "" {
+				return nil, fmt.Errorf("Access code is not valid for %s", event.Title)
+			}
+			return nil, fmt.Errorf("Tickets for %s are only sold to presale members until %s, enter your access code",
+				event.Title, open[len(open)-1].EndsAt.UTC().Format(time.RFC3339))
+		}
+		admissions[eventID] = admission
+	}
+	return admissions, nil
+}
+
+// Redeem records the presale of an order placed in the caller's transaction and uses its access
+// code once. The code is locked, so its cap holds across concurrent checkouts.
+func (s *PresaleService) Redeem(tx *gorm.DB, order *models.Order, admission *presaleAdmission) error {
+	if admission.AccessCodeID != nil {
+		var code models.PresaleAccessCode
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&code, "id = ?", *admission.AccessCodeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Access code is no longer valid")
+			}
+			return err
+		}
+		if code.MaxUses > 0 && code.UsedCount >= code.MaxUses {
+			return fmt.Errorf("Access code %s has been used up", code.Code)
+		}
+		if err := tx.Model(&code).Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+			return err
+		}
+	}
+
+	order.PresaleID = &admission.PresaleID
+	order.PresaleAccessCodeID = admission.AccessCodeID
+	return tx.Model(order).Updates(map[string]interface{}{
+		"presale_id":             order.PresaleID,
+		"presale_access_code_id": order.PresaleAccessCodeID,
+	}).Error
+}
+
+// Release gives back the access code use of an order cancelled in the caller's transaction
+func (s *PresaleService) Release(tx *gorm.DB, order *models.Order) error {
+	if order.PresaleAccessCodeID == nil {
+		return nil
+	}
+	return tx.Model(&models.PresaleAccessCode{}).
+		Where("id = ? AND used_count > 0", *order.PresaleAccessCodeID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// RejectPresale refuses events in presale, for sales that cannot admit buyers to a presale
+func (s *PresaleService) RejectPresale(db *gorm.DB, events []*models.Event, now time.Time) error {
+	eventIDs := make([]uint, len(events))
+	for i, event := range events {
+		eventIDs[i] = event.ID
+	}
+	presales, err := openPresales(db, eventIDs, now)
+	if err != nil || len(presales) == 0 {
+		return err
+	}
+	for _, event := range events {
+		if event.ID == presales[0].EventID {
+			return fmt.Errorf("Tickets for %s are only sold to presale members right now", event.Title)
+		}
+	}
+	return nil
+}
+
+// allowlistResponse completes an allowlist change with the allowlist's size
+func (s *PresaleService) allowlistResponse(ctx context.Context, presaleID uuid.UUID, resp *models.PresaleAllowlistResponse) (*models.PresaleAllowlistResponse, error) {
+	presale := models.Presale{ID: presaleID}
+	if err := countAllowlist(s.db.WithContext(ctx), &presale); err != nil {
+		return nil, err
+	}
+	resp.AllowlistCount = presale.AllowlistCount
+	return resp, nil
+}
+
+// admitToPresale admits a buyer to one of an event's open presales, by allowlisted email first so
+// allowlisted buyers don't use up codes. It returns nil when the buyer is not admitted.
+func admitToPresale(db *gorm.DB, open []models.Presale, email, code string) (*presaleAdmission, error) {
+	ids := make([]uuid.UUID, len(open))
+	for i, presale := range open {
+		ids[i] = presale.ID
+	}
+
+	if email != "" {
+		var entry models.PresaleAllowlistEntry
+		err := db.Where("presale_id IN ? AND email = ?", ids, email).First(&entry).Error
+		if err == nil {
+			return &presaleAdmission{PresaleID: entry.PresaleID}, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if code == "" {
+		return nil, nil
+	}
+	var accessCode models.PresaleAccessCode
+	err := db.Where("presale_id IN ? AND code = ?", ids, code).First(&accessCode).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if accessCode.MaxUses > 0 && accessCode.UsedCount >= accessCode.MaxUses {
+		return nil, fmt.Errorf("Access code %s has been used up", accessCode.Code)
+	}
+	return &presaleAdmission{PresaleID: accessCode.PresaleID, AccessCodeID: &accessCode.ID}, nil
+}
+
+// openPresales returns the presales of events open at a time, earliest first
+func openPresales(db *gorm.DB, eventIDs []uint, now time.Time) ([]models.Presale, error) {
+	var presales []models.Presale
+	err := db.Where("event_id IN ? AND active = ? AND starts_at <= ? AND ends_at > ?", eventIDs, true, now, now).
+		Order("starts_at").Find(&presales).Error
+	return presales, err
+}
+
+// assignPresale validates a presale request and copies it onto presale
+func assignPresale(tx *gorm.DB, presale *models.Presale, req *models.PresaleRequest) error {
+	if !req.EndsAt.After(req.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+
+	// Presales may only gate the organization's own events
+	var event models.Event
+	if err := tx.Select("id").Where("id = ? AND organization_id = ?", req.EventID, presale.OrganizationID).First(&event).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("Event %d does not belong to this organization", req.EventID)
+		}
+		return err
+	}
+
+	presale.EventID = req.EventID
+	presale.Name = strings.TrimSpace(req.Name)
+	presale.StartsAt = req.StartsAt
+	presale.EndsAt = req.EndsAt
+	presale.Active = req.Active == nil || *req.Active
+	return nil
+}
+
+// findPresale loads a presale of the organization
+func findPresale(db *gorm.DB, orgID, presaleID uuid.UUID, presale *models.Presale) error {
+	err := db.First(presale, "id = ? AND organization_id = ?", presaleID, orgID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPresaleNotFound
+	}
+	return err
+}
+
+// countAllowlist sets the size of a presale's allowlist
+func countAllowlist(db *gorm.DB, presale *models.Presale) error {
+	return db.Model(&models.PresaleAllowlistEntry{}).Where("presale_id = ?", presale.ID).Count(&presale.AllowlistCount).Error
+}
+
+// uniqueEmails lower-cases emails and drops repeated ones, keeping their order
+func uniqueEmails(emails []string) []string {
+	seen := make(map[string]bool, len(emails))
+	unique := make([]string, 0, len(emails))
+	for _, email := range emails {
+		email = strings.ToLower(strings.TrimSpace(email))
+		if !seen[email] {
+			seen[email] = true
+			unique = append(unique, email)
+		}
+	}
+	return unique
+}
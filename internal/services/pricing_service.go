@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PricingService manages dynamic pricing rules and resolves the price that applies at checkout
+type PricingService struct {
+	db *gorm.DB
+}
+
+// NewPricingService creates a new pricing service
+func NewPricingService() *PricingService {
+	return &PricingService{
+		db: database.DB,
+	}
+}
+
+// CreateRule adds a pricing rule to an event
+func (s *PricingService) CreateRule(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.PricingRuleRequest) (*models.PricingRuleResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	trigger := models.PricingTrigger(req.Trigger)
+	switch trigger {
+	case models.PricingTriggerTicketsSold:
+		if req.SoldThreshold < 1 {
+			return nil, errors.New("sold_threshold is required for tickets_sold rules")
+		}
+	case models.PricingTriggerDate:
+		if req.StartsAt == nil {
+			return nil, errors.New("starts_at is required for date rules")
+		}
+	}
+
+	var event models.Event
+	if err := db.First(&event, req.EventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+
+	if trigger == models.PricingTriggerTicketsSold && req.SoldThreshold >= event.Capacity {
+		return nil, errors.New("sold_threshold must be below the event capacity")
+	}
+
+	rule := models.PricingRule{
+		OrganizationID: orgID,
+		EventID:        event.ID,
+		Name:           req.Name,
+		Trigger:        trigger,
+		Price:          req.Price,
+		CreatedBy:      &userID,
+	}
+	if trigger == models.PricingTriggerTicketsSold {
+		rule.SoldThreshold = req.SoldThreshold
+	} else {
+		startsAt := req.StartsAt.UTC()
+		rule.StartsAt = &startsAt
+	}
+
+	if err := db.Create(&rule).Error; err != nil {
+		return nil, err
+	}
+
+	resp := rule.ToResponse()
+	return &resp, nil
+}
+
+// ListRules returns the pricing rules of an organization, optionally limited to one event
+func (s *PricingService) ListRules(ctx context.Context, orgID uuid.UUID, eventID uint) ([]models.PricingRuleResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	query := db.Where("organization_id = ?", orgID)
+	if eventID != 0 {
+		query = query.Where("event_id = ?", eventID)
+	}
+
+	var rules []models.PricingRule
+	if err := query.Order("event_id ASC, price ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.PricingRuleResponse, len(rules))
+	for i, rule := range rules {
+		responses[i] = rule.ToResponse()
+	}
+	return responses, nil
+}
+
+// DeleteRule removes a pricing rule from an organization
+func (s *PricingService) DeleteRule(ctx context.Context, orgID uuid.UUID, ruleID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+
+	result := db.Where("id = ? AND organization_id = ?", ruleID, orgID).Delete(&models.PricingRule{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return errors.New("Pricing rule not found")
+	}
+
+	return nil
+}
+
+// CurrentPrice returns the unit price that applies to an event at checkout and the rule that set it.
+// The rule is nil when the event's base price applies.
+func (s *PricingService) CurrentPrice(ctx context.Context, event *models.Event) (float64, *models.PricingRule, error) {
+	rules, err := s.eventRules(ctx, event.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	price, rule := resolvePrice(event, rules, time.Now())
+	return price, rule, nil
+}
+
+// Preview returns the current price of an event and the schedule of upcoming price changes
+func (s *PricingService) Preview(ctx context.Context, eventID uint) (*models.PricingPreview, error) {
+	db := s.db.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+
+	rules, err := s.eventRules(ctx, event.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	sold := event.Capacity - event.Available
+	price, active := resolvePrice(&event, rules, now)
+
+	preview := &models.PricingPreview{
+		EventID:      event.ID,
+		BasePrice:    event.Price,
+		CurrentPrice: price,
+		TicketsSold:  sold,
+		Upcoming:     []models.PriceChange{},
+	}
+	if active != nil {
+		preview.ActiveRuleID = &active.ID
+	}
+
+	for _, rule := range rules {
+		// Rules that are already active or would not raise the price never take effect
+		if rule.IsActive(sold, now) || rule.Price <= price {
+			continue
+		}
+
+		change := models.PriceChange{
+			RuleID:   rule.ID,
+			Name:     rule.Name,
+			Trigger:  rule.Trigger,
+			Price:    rule.Price,
+			StartsAt: rule.StartsAt,
+		}
+		if rule.Trigger == models.PricingTriggerTicketsSold {
+			remaining := rule.SoldThreshold - sold
+			change.TicketsRemaining = &remaining
+		}
+		preview.Upcoming = append(preview.Upcoming, change)
+	}
+
+	sort.SliceStable(preview.Upcoming, func(i, j int) bool {
+		return preview.Upcoming[i].Price < preview.Upcoming[j].Price
+	})
+
+	return preview, nil
+}
+
+// eventRules loads the pricing rules of an event
+func (s *PricingService) eventRules(ctx context.Context, eventID uint) ([]models.PricingRule, error) {
+	db := s.db.WithContext(ctx)
+
+	var rules []models.PricingRule
+	if err := db.Where("event_id = ?", eventID).Order("price ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// resolvePrice picks the highest price among the event's base price and its active rules
+func resolvePrice(event *models.Event, rules []models.PricingRule, now time.Time) (float64, *models.PricingRule) {
+	sold := event.Capacity - event.Available
+	price := event.Price
+	var active *models.PricingRule
+
+	for i := range rules {
+		if rules[i].IsActive(sold, now) && rules[i].Price > price {
+			price = rules[i].Price
+			active = &rules[i]
+		}
+	}
+
+	return price, active
+}
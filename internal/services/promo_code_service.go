@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrPromoCodeNotFound is returned for a promo code that does not exist in the organization
+	ErrPromoCodeNotFound = errors.New("Promo code not found")
+	// ErrPromoCodeInvalid is returned at checkout for codes that do not exist or do not cover any
+	// selected ticket. Both read the same, so buyers cannot probe other organizations' codes.
+	ErrPromoCodeInvalid = errors.New("Promo code is not valid")
+)
+
+// PromoCodeService manages organizers' promo codes and applies them to checkout prices
+type PromoCodeService struct {
+	db *gorm.DB
+}
+
+// NewPromoCodeService creates a new promo code service
+func NewPromoCodeService() *PromoCodeService {
+	return &PromoCodeService{db: database.DB}
+}
+
+// CreatePromoCode adds a promo code to an organization
+func (s *PromoCodeService) CreatePromoCode(ctx context.Context, orgID, userID uuid.UUID, req *models.PromoCodeRequest) (*models.PromoCodeResponse, error) {
+	promo := models.PromoCode{
+		OrganizationID: orgID,
+		CreatedBy:      &userID,
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.assign(tx, &promo, req); err != nil {
+			return err
+		}
+		if err := tx.Create(&promo).Error; err != nil {
+			return fmt.Errorf("failed to create promo code: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "promo_code.create", "promo_code", promo.ID.String(), &orgID, map[string]interface{}{
+			"code":          promo.Code,
+			"discount_type": promo.DiscountType,
+			"value":         promo.Value,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := promo.ToResponse()
+	return &resp, nil
+}
+
+// ListPromoCodes returns the promo codes of an organization, optionally only those covering an event
+func (s *PromoCodeService) ListPromoCodes(ctx context.Context, orgID uuid.UUID, eventID uint) ([]models.PromoCodeResponse, error) {
+	var promos []models.PromoCode
+	if err := s.db.WithContext(ctx).Where("organization_id = ?", orgID).Order("code").Find(&promos).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.PromoCodeResponse, 0, len(promos))
+	for i := range promos {
+		if eventID != 0 && !promos[i].AppliesTo(eventID) {
+			continue
+		}
+		responses = append(responses, promos[i].ToResponse())
+	}
+	return responses, nil
+}
+
+// GetPromoCode returns a promo code of an organization with its usage
+func (s *PromoCodeService) GetPromoCode(ctx context.Context, orgID, promoID uuid.UUID) (*models.PromoCodeResponse, error) {
+	var promo models.PromoCode
+	if err := findPromoCode(s.db.WithContext(ctx), orgID, promoID, &promo); err != nil {
+		return nil, err
+	}
+
+	resp := promo.ToResponse()
+	return &resp, nil
+}
+
+// UpdatePromoCode replaces the settings of a promo code. Its uses so far are kept and count
+// towards the new limits.
+func (s *PromoCodeService) UpdatePromoCode(ctx context.Context, orgID, promoID, userID uuid.UUID, req *models.PromoCodeRequest) (*models.PromoCodeResponse, error) {
+	var promo models.PromoCode
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := findPromoCode(tx.Clauses(clause.Locking{Strength: "UPDATE"}), orgID, promoID, &promo); err != nil {
+			return err
+		}
+		if err := s.assign(tx, &promo, req); err != nil {
+			return err
+		}
+		if err := tx.Save(&promo).Error; err != nil {
+			return fmt.Errorf("failed to update promo code: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "promo_code.update", "promo_code", promo.ID.String(), &orgID, map[string]interface{}{
+			"code":          promo.Code,
+			"discount_type": promo.DiscountType,
+			"value":         promo.Value,
+			"active":        promo.Active,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := promo.ToResponse()
+	return &resp, nil
+}
+
+// DeletePromoCode removes a promo code. Orders already placed with it keep their discount.
+func (s *PromoCodeService) DeletePromoCode(ctx context.Context, orgID, promoID, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var promo models.PromoCode
+		if err := findPromoCode(tx, orgID, promoID, &promo); err != nil {
+			return err
+		}
+		if err := tx.Delete(&promo).Error; err != nil {
+			return fmt.Errorf("failed to delete promo code: %w", err)
+		}
+
+		recordAuditLog(tx, &userID, "promo_code.delete", "promo_code", promo.ID.String(), &orgID, map[string]interface{}{
+			"code":       promo.Code,
+			"used_count": promo.UsedCount,
+		})
+		return nil
+	})
+}
+
+// Apply discounts the lines of a quote with a promo code and returns the discounts given. The
+// code is matched within the organizations of the selected events; lines it covers get their
+// DiscountAmount and PromoCodeID set. Per-buyer limits are only checked when buyerEmail is known,
+// and every limit is checked again when the orders are placed.
+func (s *PromoCodeService) Apply(db *gorm.DB, code string, buyerEmail string, lines []models.OrderQuoteLine, now time.Time) ([]models.OrderQuoteDiscount, error) {
+	var promos []models.PromoCode
+	if err := db.Where("code = ? AND active = ?", normalizePromoCode(code), true).Find(&promos).Error; err != nil {
+		return nil, err
+	}
+	if len(promos) == 0 {
+		return nil, ErrPromoCodeInvalid
+	}
+
+	organizations, err := lineOrganizations(db, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts := []models.OrderQuoteDiscount{}
+	for i := range promos {
+		promo := &promos[i]
+
+		var eligible []int
+		for j := range lines {
+			if lines[j].PromoCodeID == nil && lines[j].Amount > 0 &&
+				organizations[lines[j].EventID] == promo.OrganizationID && promo.AppliesTo(lines[j].EventID) {
+				eligible = append(eligible, j)
+			}
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		// Each discounted line becomes an order that uses the code once
+		if err := checkPromoCodeUsable(db, promo, buyerEmail, len(eligible), now); err != nil {
+			return nil, err
+		}
+
+		amount := discountLines(promo, lines, eligible)
+		discounts = append(discounts, models.OrderQuoteDiscount{
+			Code:        promo.Code,
+			Description: promoCodeDescription(promo),
+			Amount:      amount,
+		})
+	}
+	if len(discounts) == 0 {
+		return nil, ErrPromoCodeInvalid
+	}
+	return discounts, nil
+}
+
+// Redeem records the use of a promo code on an order placed in the caller's transaction. The code
+// is locked, so its limits hold across concurrent checkouts.
+func (s *PromoCodeService) Redeem(tx *gorm.DB, promoCodeID uuid.UUID, order *models.Order, amount float64) error {
+	var promo models.PromoCode
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&promo, "id = ?", promoCodeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPromoCodeInvalid
+		}
+		return err
+	}
+	if !promo.Active {
+		return ErrPromoCodeInvalid
+	}
+	if err := checkPromoCodeUsable(tx, &promo, order.BuyerEmail, 1, time.Now()); err != nil {
+		return err
+	}
+
+	if err := tx.Model(&promo).Update("used_count", gorm.Expr("used_count + 1")).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.PromoCodeRedemption{
+		PromoCodeID: promo.ID,
+		OrderID:     order.ID,
+		BuyerEmail:  strings.ToLower(order.BuyerEmail),
+		Amount:      amount,
+	}).Error
+}
+
+// Release gives back the promo code use of an order cancelled in the caller's transaction
+func (s *PromoCodeService) Release(tx *gorm.DB, orderID uuid.UUID) error {
+	var redemption models.PromoCodeRedemption
+	err := tx.Where("order_id = ?", orderID).First(&redemption).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := tx.Delete(&redemption).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.PromoCode{}).
+		Where("id = ? AND used_count > 0", redemption.PromoCodeID).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// assign validates a promo code request and copies it onto promo
+func (s *PromoCodeService) assign(tx *gorm.DB, promo *models.PromoCode, req *models.PromoCodeRequest) error {
+	discountType := models.DiscountType(req.DiscountType)
+	if discountType == models.DiscountTypePercentage && req.Value > 100 {
+		return errors.New("Percentage discounts cannot exceed 100")
+	}
+	if req.StartsAt != nil && req.EndsAt != nil && !req.EndsAt.After(*req.StartsAt) {
+		return errors.New("ends_at must be after starts_at")
+	}
+	if req.MaxUses > 0 && req.MaxUsesPerBuyer > req.MaxUses {
+		return errors.New("max_uses_per_buyer cannot exceed max_uses")
+	}
+
+	code := normalizePromoCode(req.Code)
+	var count int64
+	err := tx.Model(&models.PromoCode{}).
+		Where("organization_id = ? AND code = ? AND id <> ?", promo.OrganizationID, code, promo.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("a promo code %q already exists", code)
+	}
+
+	// Codes may only discount the organization's own events
+	eventIDs := uniqueEventIDs(req.EventIDs)
+	for _, eventID := range eventIDs {
+		var event models.Event
+		if err := tx.First(&event, eventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("Event %d not found", eventID)
+			}
+			return err
+		}
+		if event.OrganizerID == nil {
+			return fmt.Errorf("Event %d does not belong to this organization", eventID)
+		}
+		orgID, err := organizerOrganization(tx, *event.OrganizerID)
+		if err != nil {
+			return err
+		}
+		if orgID == nil || *orgID != promo.OrganizationID {
+			return fmt.Errorf("Event %d does not belong to this organization", eventID)
+		}
+	}
+
+	promo.Code = code
+	promo.Description = req.Description
+	promo.DiscountType = discountType
+	promo.Value = req.Value
+	promo.EventIDs = eventIDs
+	promo.MaxUses = req.MaxUses
+	promo.MaxUsesPerBuyer = req.MaxUsesPerBuyer
+	promo.StartsAt = req.StartsAt
+	promo.EndsAt = req.EndsAt
+	promo.Active = req.Active == nil || *req.Active
+	return nil
+}
+
+// findPromoCode loads a promo code of the organization
+func findPromoCode(db *gorm.DB, orgID, promoID uuid.UUID, promo *models.PromoCode) error {
+	err := db.First(promo, "id = ? AND organization_id = ?", promoID, orgID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPromoCodeNotFound
+	}
+	return err
+}
+
+// checkPromoCodeUsable rejects a code outside its validity window or without enough uses left
+// for uses more orders. The per-buyer limit is skipped when buyerEmail is empty.
+func checkPromoCodeUsable(db *gorm.DB, promo *models.PromoCode, buyerEmail string, uses int, now time.Time) error {
+	if promo.StartsAt != nil && now.Before(*promo.StartsAt) {
+		return fmt.Errorf("Promo code %s is not valid yet", promo.Code)
+	}
+	if promo.EndsAt != nil && !now.Before(*promo.EndsAt) {
+		return fmt.Errorf("Promo code %s has expired", promo.Code)
+	}
+	if promo.MaxUses > 0 && promo.UsedCount+uses > promo.MaxUses {
+		return fmt.Errorf("Promo code %s has been used up", promo.Code)
+	}
+
+	if buyerEmail == "" || promo.MaxUsesPerBuyer == 0 {
+		return nil
+	}
+	var used int64
+	err := db.Model(&models.PromoCodeRedemption{}).
+		Where("promo_code_id = ? AND buyer_email = ?", promo.ID, strings.ToLower(buyerEmail)).
+		Count(&used).Error
+	if err != nil {
+		return err
+	}
+	if int(used)+uses > promo.MaxUsesPerBuyer {
+		return fmt.Errorf("You have already used promo code %s", promo.Code)
+	}
+	return nil
+}
+
+// discountLines takes a promo code's discount off the eligible lines and returns the total.
+// Percentages apply to each line; fixed amounts are spread over the lines by their amounts, the
+// rounding remainder going to the last line.
+func discountLines(promo *models.PromoCode, lines []models.OrderQuoteLine, eligible []int) float64 {
+	total := 0.0
+	for _, i := range eligible {
+		total += lines[i].Amount
+	}
+	total = math.Round(total*100) / 100
+
+	discount := math.Min(promo.Value, total) // Fixed codes only
+
+	given := 0.0
+	for n, i := range eligible {
+		line := &lines[i]
+		var amount float64
+		switch {
+		case promo.DiscountType == models.DiscountTypePercentage:
+			amount = math.Round(line.Amount*promo.Value) / 100
+		case n == len(eligible)-1:
+			amount = math.Round((discount-given)*100) / 100
+		default:
+			amount = math.Round(discount*line.Amount/total*100) / 100
+		}
+		amount = math.Min(amount, line.Amount)
+
+		line.DiscountAmount = amount
+		line.PromoCodeID = &promo.ID
+		given = math.Round((given+amount)*100) / 100
+	}
+	return given
+}
+
+// promoCodeDescription describes a promo code's discount to buyers
+func promoCodeDescription(promo *models.PromoCode) string {
+	if promo.Description != "" {
+		return promo.Description
+	}
+	if promo.DiscountType == models.DiscountTypePercentage {
+		return fmt.Sprintf("%s%% off", strings.TrimSuffix(strings.TrimRight(formatAmount(promo.Value), "0"), "."))
+	}
+	return fmt.Sprintf("%s %s off", formatAmount(promo.Value), models.DefaultCurrency)
+}
+
+// lineOrganizations maps the events of quote lines to the organizations selling them. Events
+// without an organization are left out.
+func lineOrganizations(db *gorm.DB, lines []models.OrderQuoteLine) (map[uint]uuid.UUID, error) {
+	organizations := make(map[uint]uuid.UUID, len(lines))
+	organizers := make(map[uuid.UUID]*uuid.UUID)
+	for _, line := range lines {
+		var event models.Event
+		if err := db.Select("id", "organizer_id").First(&event, line.EventID).Error; err != nil {
+			return nil, err
+		}
+		if event.OrganizerID == nil {
+			continue
+		}
+
+		orgID, seen := organizers[*event.OrganizerID]
+		if !seen {
+			var err error
+			if orgID, err = organizerOrganization(db, *event.OrganizerID); err != nil {
+				return nil, err
+			}
+			organizers[*event.OrganizerID] = orgID
+		}
+		if orgID != nil {
+			organizations[line.EventID] = *orgID
+		}
+	}
+	return organizations, nil
+}
+
+// normalizePromoCode returns the stored form of a code buyers or organizers entered
+func normalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// uniqueEventIDs drops repeated event IDs, keeping their order
+func uniqueEventIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// NewPublicHTTPClient returns a client for organizer-supplied URLs, such as logos, hook targets and
+// chat webhooks. It refuses to connect to loopback, private or link-local addresses, redirects
+// included, so those URLs cannot probe internal services.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: rejectNonPublicAddress}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+	}
+}
+
+// rejectNonPublicAddress is the dialer control of NewPublicHTTPClient. It checks the address
+// actually dialed, after DNS resolution, so hosts cannot be re-pointed at internal addresses.
+func rejectNonPublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicIP(net.ParseIP(host)) {
+		return errNonPublicAddress
+	}
+	return nil
+}
+
+// isPublicIP reports whether an address is reachable on the public internet
+func isPublicIP(ip net.IP) bool {
+	return ip != nil && ip.IsGlobalUnicast() && !ip.IsPrivate()
+}
+
+// validatePublicURL checks that an organizer-supplied URL is http or https and that its host
+// resolves to public addresses only. Deliveries are checked again when they connect, as DNS can
+// change after the URL was accepted.
+func validatePublicURL(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("URL must be an http or https URL")
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		if !isPublicIP(ip) {
+			return errNonPublicAddress
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return errors.New("URL host could not be resolved")
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return errNonPublicAddress
+		}
+	}
+	return nil
+}
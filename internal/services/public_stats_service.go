@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// publicStatsKey is the Redis key shared by all instances for the cached platform statistics
+const publicStatsKey = "stats:public"
+
+// publicStatsSQL counts platform-wide totals in one round trip. Nothing is grouped, so no
+// per-organization figure can leak.
+const publicStatsSQL = `
+SELECT
+	(SELECT COUNT(*) FROM events WHERE deleted_at IS NULL AND status NOT IN @hidden_events) AS events_hosted,
+	(SELECT COUNT(*) FROM tickets WHERE status <> @cancelled_ticket) AS tickets_issued,
+	(SELECT COUNT(*) FROM organizations WHERE deleted_at IS NULL) AS organizers_onboarded`
+
+// PublicStatsService serves anonymous platform-wide counters for the marketing site. The counters
+// are cached in memory and in Redis, so a busy public page costs at most one count per cache TTL
+// across all instances.
+type PublicStatsService struct {
+	db          *gorm.DB
+	redisClient redislib.UniversalClient
+	cacheTTL    time.Duration
+
+	mu     sync.Mutex
+	cached *models.PublicStats
+}
+
+// NewPublicStatsService creates a new public statistics service
+func NewPublicStatsService(cfg *config.Config) *PublicStatsService {
+	return &PublicStatsService{
+		db:          database.DB,
+		redisClient: redis.Client,
+		cacheTTL:    cfg.Stats.CacheTTL,
+	}
+}
+
+// CacheTTL returns how long statistics are served before they are recounted
+func (s *PublicStatsService) CacheTTL() time.Duration {
+	return s.cacheTTL
+}
+
+// Stats returns the platform statistics, counting them when the cached ones have expired. If
+// counting fails, the last known statistics are served instead.
+func (s *PublicStatsService) Stats(ctx context.Context) (*models.PublicStats, error) {
+	// Holding the lock while counting lets concurrent requests wait for one count
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if s.fresh(s.cached, now) {
+		return s.cached, nil
+	}
+
+	if s.redisClient != nil {
+		data, err := s.redisClient.Get(ctx, publicStatsKey).Bytes()
+		if err == nil {
+			var stats models.PublicStats
+			if err := json.Unmarshal(data, &stats); err == nil && s.fresh(&stats, now) {
+				s.cached = &stats
+				return s.cached, nil
+			}
+		} else if !errors.Is(err, redislib.Nil) {
+			log.Printf("Public statistics cache unavailable: %v", err)
+		}
+	}
+
+	stats := models.PublicStats{UpdatedAt: now.UTC()}
+	err := s.db.WithContext(ctx).Raw(publicStatsSQL, map[string]interface{}{
+		"hidden_events":    []models.EventStatus{models.EventStatusCancelled, models.EventStatusDraft},
+		"cancelled_ticket": models.TicketStatusCancelled,
+	}).Scan(&stats).Error
+	if err != nil {
+		if s.cached != nil {
+			log.Printf("Failed to count public statistics, serving stale ones: %v", err)
+			return s.cached, nil
+		}
+		return nil, fmt.Errorf("failed to count public statistics: %w", err)
+	}
+	s.cached = &stats
+
+	if s.redisClient != nil {
+		if data, err := json.Marshal(stats); err == nil {
+			if err := s.redisClient.Set(ctx, publicStatsKey, data, s.cacheTTL).Err(); err != nil {
+				log.Printf("Failed to cache public statistics: %v", err)
+			}
+		}
+	}
+	return s.cached, nil
+}
+
+// fresh reports whether stats were counted within the cache TTL
+func (s *PublicStatsService) fresh(stats *models.PublicStats, now time.Time) bool {
+	return stats != nil && now.Sub(stats.UpdatedAt) < s.cacheTTL
+}
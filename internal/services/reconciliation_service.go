@@ -0,0 +1,326 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"gorm.io/gorm"
+)
+
+// TaskReconciliationRun is the asynq task type for reconciling provider transactions with internal payments
+const TaskReconciliationRun = "reconciliation:run"
+
+// reconciliationGrace widens the provider window so charges recorded just across a period
+// boundary still match their internal payment
+const reconciliationGrace = time.Hour
+
+// ReconciliationPayload is the payload of a reconciliation task. A zero period reconciles the previous UTC day.
+type ReconciliationPayload struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+// ReconciliationService matches the payment provider's balance transactions against internal payments
+type ReconciliationService struct {
+	db                *gorm.DB
+	client            *asynq.Client
+	provider          PaymentProvider
+	emailQueueService *EmailQueueService
+	alertTo           string
+}
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(cfg *config.Config) *ReconciliationService {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	provider, err := NewPaymentProvider(cfg)
+	if err != nil {
+		log.Printf("Warning: Payment reconciliation disabled: %v", err)
+	}
+
+	return &ReconciliationService{
+		db:                database.DB,
+		client:            asynq.NewClient(redisOpts),
+		provider:          provider,
+		emailQueueService: NewEmailQueueService(cfg),
+		alertTo:           cfg.Payment.ReconciliationAlertTo,
+	}
+}
+
+// QueueReconciliation queues an on-demand reconciliation of the given period
+func (s *ReconciliationService) QueueReconciliation(req *models.RunReconciliationRequest) error {
+	if s.provider == nil {
+		return errors.New("Payment reconciliation is not available")
+	}
+	if req.PeriodEnd.Sub(req.PeriodStart) > 31*24*time.Hour {
+		return errors.New("Reconciliation period cannot exceed 31 days")
+	}
+
+	payload, err := json.Marshal(ReconciliationPayload{PeriodStart: req.PeriodStart, PeriodEnd: req.PeriodEnd})
+	if err != nil {
+		return fmt.Errorf("failed to marshal reconciliation job: %w", err)
+	}
+
+	task := asynq.NewTask(TaskReconciliationRun, payload)
+	_, err = s.client.Enqueue(task, asynq.Queue(redis.QueueName(TicketingQueue)), asynq.MaxRetry(3))
+	return err
+}
+
+// ListReports returns the most recent reconciliation reports
+func (s *ReconciliationService) ListReports(ctx context.Context, limit int) ([]models.ReconciliationReport, error) {
+	db := s.db.WithContext(ctx)
+
+	if limit <= 0 || limit > 100 {
+		limit = 30
+	}
+
+	var reports []models.ReconciliationReport
+	if err := db.Order("period_start DESC, created_at DESC").Limit(limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetReport returns a reconciliation report with its discrepancies
+func (s *ReconciliationService) GetReport(ctx context.Context, reportID uuid.UUID) (*models.ReconciliationReport, error) {
+	db := s.db.WithContext(ctx)
+
+	var report models.ReconciliationReport
+	if err := db.Preload("Discrepancies").First(&report, "id = ?", reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Reconciliation report not found")
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Reconcile pulls the provider's balance transactions for a period and matches them against
+// succeeded internal payments, storing a discrepancy report and alerting on mismatches.
+// A zero period reconciles the previous UTC day.
+func (s *ReconciliationService) Reconcile(ctx context.Context, start, end time.Time) (*models.ReconciliationReport, error) {
+	if s.provider == nil {
+		return nil, errors.New("payment provider is not configured")
+	}
+	if start.IsZero() || end.IsZero() {
+		end = time.Now().UTC().Truncate(24 * time.Hour)
+		start = end.AddDate(0, 0, -1)
+	}
+
+	report := models.ReconciliationReport{
+		Provider:    s.provider.Name(),
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+	if err := s.db.Create(&report).Error; err != nil {
+		return nil, err
+	}
+
+	discrepancies, err := s.match(ctx, &report)
+	if err != nil {
+		s.failReport(&report, err)
+		return &report, err
+	}
+
+	now := time.Now()
+	report.DiscrepancyCount = len(discrepancies)
+	report.Status = models.ReconciliationStatusMatched
+	if len(discrepancies) > 0 {
+		report.Status = models.ReconciliationStatusDiscrepancies
+	}
+	report.CompletedAt = &now
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if len(discrepancies) > 0 {
+			for i := range discrepancies {
+				discrepancies[i].ReportID = report.ID
+			}
+			if err := tx.CreateInBatches(&discrepancies, 100).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(&report).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Discrepancies = discrepancies
+
+	log.Printf("Reconciliation completed: Report=%s, Period=%s..%s, Matched=%d, Discrepancies=%d",
+		report.ID, start.Format(time.RFC3339), end.Format(time.RFC3339), report.MatchedCount, report.DiscrepancyCount)
+
+	if len(discrepancies) > 0 {
+		s.alert(&report)
+	}
+
+	return &report, nil
+}
+
+// match compares provider charges with internal payments and fills the report totals
+func (s *ReconciliationService) match(ctx context.Context, report *models.ReconciliationReport) ([]models.ReconciliationDiscrepancy, error) {
+	transactions, err := s.provider.ListBalanceTransactions(ctx, report.PeriodStart.Add(-reconciliationGrace), report.PeriodEnd.Add(reconciliationGrace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list balance transactions: %w", err)
+	}
+
+	var payments []models.Payment
+	if err := s.db.Where("provider = ? AND status = ? AND created_at >= ? AND created_at < ?",
+		report.Provider, models.PaymentStatusSucceeded, report.PeriodStart, report.PeriodEnd).
+		Find(&payments).Error; err != nil {
+		return nil, err
+	}
+
+	charges := make(map[string]BalanceTransaction)
+	for _, txn := range transactions {
+		if txn.Type != "charge" && txn.Type != "payment" {
+			continue
+		}
+		charges[txn.PaymentRef] = txn
+	}
+
+	var discrepancies []models.ReconciliationDiscrepancy
+	matched := make(map[string]bool)
+
+	for _, payment := range payments {
+		report.InternalCount++
+		report.InternalTotal += payment.Amount
+
+		paymentID, orderID := payment.ID, payment.OrderID
+		txn, ok := charges[payment.ProviderRef]
+		if !ok {
+			discrepancies = append(discrepancies, models.ReconciliationDiscrepancy{
+				Type:           models.DiscrepancyMissingProvider,
+				PaymentID:      &paymentID,
+				OrderID:        &orderID,
+				ProviderRef:    payment.ProviderRef,
+				InternalAmount: payment.Amount,
+				Currency:       payment.Currency,
+				Detail:         "Payment recorded as succeeded has no matching provider charge",
+			})
+			continue
+		}
+		matched[payment.ProviderRef] = true
+
+		switch {
+		case !strings.EqualFold(txn.Currency, payment.Currency):
+			discrepancies = append(discrepancies, models.ReconciliationDiscrepancy{
+				Type:           models.DiscrepancyCurrencyMismatch,
+				PaymentID:      &paymentID,
+				OrderID:        &orderID,
+				ProviderRef:    payment.ProviderRef,
+				InternalAmount: payment.Amount,
+				ProviderAmount: txn.Amount,
+				Currency:       payment.Currency,
+				Detail:         fmt.Sprintf("Provider settled in %s, payment recorded in %s", txn.Currency, payment.Currency),
+			})
+		case toMinorUnits(txn.Amount) != toMinorUnits(payment.Amount):
+			discrepancies = append(discrepancies, models.ReconciliationDiscrepancy{
+				Type:           models.DiscrepancyAmountMismatch,
+				PaymentID:      &paymentID,
+				OrderID:        &orderID,
+				ProviderRef:    payment.ProviderRef,
+				InternalAmount: payment.Amount,
+				ProviderAmount: txn.Amount,
+				Currency:       payment.Currency,
+				Detail:         fmt.Sprintf("Provider charged %.2f, payment recorded %.2f", txn.Amount, payment.Amount),
+			})
+		default:
+			report.MatchedCount++
+		}
+	}
+
+	for ref, txn := range charges {
+		// Charges in the grace window only serve to match boundary payments
+		if txn.CreatedAt.Before(report.PeriodStart) || !txn.CreatedAt.Before(report.PeriodEnd) {
+			continue
+		}
+
+		report.ProviderCount++
+		report.ProviderTotal += txn.Amount
+		report.FeeTotal += txn.Fee
+
+		if matched[ref] {
+			continue
+		}
+
+		// The internal payment may have been recorded just outside the period
+		var count int64
+		if err := s.db.Model(&models.Payment{}).
+			Where("provider = ? AND provider_ref = ? AND status = ?", report.Provider, ref, models.PaymentStatusSucceeded).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			continue
+		}
+
+		discrepancies = append(discrepancies, models.ReconciliationDiscrepancy{
+			Type:           models.DiscrepancyMissingInternal,
+			ProviderRef:    ref,
+			ProviderAmount: txn.Amount,
+			Currency:       txn.Currency,
+			Detail:         fmt.Sprintf("Provider charge %s has no succeeded internal payment", txn.ID),
+		})
+	}
+
+	report.InternalTotal = math.Round(report.InternalTotal*100) / 100
+	report.ProviderTotal = math.Round(report.ProviderTotal*100) / 100
+	report.FeeTotal = math.Round(report.FeeTotal*100) / 100
+
+	return discrepancies, nil
+}
+
+// failReport marks a report as failed and alerts, since a missed run hides discrepancies
+func (s *ReconciliationService) failReport(report *models.ReconciliationReport, cause error) {
+	now := time.Now()
+	report.Status = models.ReconciliationStatusFailed
+	report.Error = cause.Error()
+	report.CompletedAt = &now
+	if err := s.db.Save(report).Error; err != nil {
+		log.Printf("Failed to save reconciliation report: Report=%s, Error=%v", report.ID, err)
+	}
+
+	log.Printf("Reconciliation failed: Report=%s, Error=%v", report.ID, cause)
+	s.alert(report)
+}
+
+// alert notifies the finance contact about a report that needs attention
+func (s *ReconciliationService) alert(report *models.ReconciliationReport) {
+	log.Printf("ALERT: Payment reconciliation needs attention: Report=%s, Status=%s, Discrepancies=%d",
+		report.ID, report.Status, report.DiscrepancyCount)
+
+	if s.alertTo == "" {
+		return
+	}
+
+	period := fmt.Sprintf("%s to %s", report.PeriodStart.Format("2006-01-02 15:04"), report.PeriodEnd.Format("2006-01-02 15:04 MST"))
+	var subject, message string
+	if report.Status == models.ReconciliationStatusFailed {
+		subject = "Payment reconciliation failed"
+		message = fmt.Sprintf("The %s reconciliation for %s could not be completed: %s. Report ID: %s.",
+			report.Provider, period, report.Error, report.ID)
+	} else {
+		subject = fmt.Sprintf("Payment reconciliation found %d discrepancies", report.DiscrepancyCount)
+		message = fmt.Sprintf("The %s reconciliation for %s matched %d of %d internal payments and found %d discrepancies "+
+			"(provider total %.2f, internal total %.2f). Review report %s for details.",
+			report.Provider, period, report.MatchedCount, report.InternalCount, report.DiscrepancyCount,
+			report.ProviderTotal, report.InternalTotal, report.ID)
+	}
+
+	if err := s.emailQueueService.QueueNotificationEmail(s.alertTo, "Finance team", subject, message); err != nil {
+		log.Printf("Failed to queue reconciliation alert: Report=%s, Error=%v", report.ID, err)
+	}
+}
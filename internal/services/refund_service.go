@@ -0,0 +1,635 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Audit log actions for refunds
+const (
+	AuditRefundRequested = "refund.requested"
+	AuditRefundApproved  = "refund.approved"
+	AuditRefundRejected  = "refund.rejected"
+	AuditRefundProcessed = "refund.processed"
+	AuditRefundFailed    = "refund.failed"
+)
+
+// ErrRefundNotFound is returned for a refund that does not exist in the organization
+var ErrRefundNotFound = errors.New("Refund not found")
+
+// openRefundStatuses are the states of refunds not yet settled. Their tickets and amounts are
+// reserved, so later requests cannot refund them again.
+var openRefundStatuses = []models.RefundStatus{models.RefundStatusRequested, models.RefundStatusApproved, models.RefundStatusFailed}
+
+// RefundService handles refund requests from buyers and staff, their approval by the organization
+// and their processing through the payment provider
+type RefundService struct {
+	db           *gorm.DB
+	orderService *OrderService
+}
+
+// NewRefundService creates a new refund service
+func NewRefundService(orderService *OrderService) *RefundService {
+	return &RefundService{
+		db:           database.DB,
+		orderService: orderService,
+	}
+}
+
+// RequestBuyerRefund records a buyer's request to refund tickets of an order placed by them or for
+// their email address. The event's refund policy applies, including its refund fee, which is kept
+// from the refunded amount.
+func (s *RefundService) RequestBuyerRefund(ctx context.Context, userID, orderID uuid.UUID, req *models.CreateRefundRequest) (*models.Refund, error) {
+	if req.Amount != nil {
+		return nil, errors.New("Refund amounts are set by the organizer, select the tickets to refund instead")
+	}
+
+	order, err := s.buyerOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.request(ctx, order, userID, req, true)
+}
+
+// RequestStaffRefund records a refund requested by staff of the organization. Staff may refund
+// outside the event's refund policy and return part of an order's money without cancelling tickets.
+func (s *RefundService) RequestStaffRefund(ctx context.Context, orgID, orderID, staffID uuid.UUID, req *models.CreateRefundRequest) (*models.Refund, error) {
+	var order models.Order
+	err := s.db.WithContext(ctx).Preload("Event").Preload("Insurance").
+		First(&order, "id = ? AND organization_id = ?", orderID, orgID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+	return s.request(ctx, &order, staffID, req, false)
+}
+
+// ListBuyerRefunds returns the refunds of an order placed by or for the user, newest first
+func (s *RefundService) ListBuyerRefunds(ctx context.Context, userID, orderID uuid.UUID) ([]models.Refund, error) {
+	order, err := s.buyerOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var refunds []models.Refund
+	err = s.db.WithContext(ctx).Preload("Items").
+		Where("order_id = ?", order.ID).
+		Order("created_at DESC").
+		Find(&refunds).Error
+	return refunds, err
+}
+
+// ListRefunds returns an organization's refunds, newest first, optionally limited to one order and/or status
+func (s *RefundService) ListRefunds(ctx context.Context, orgID uuid.UUID, orderID *uuid.UUID, status string) ([]models.Refund, error) {
+	query := s.db.WithContext(ctx).Preload("Items").Where("organization_id = ?", orgID)
+	if orderID != nil {
+		query = query.Where("order_id = ?", *orderID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var refunds []models.Refund
+	if err := query.Order("created_at DESC").Limit(200).Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// ApproveRefund approves a requested refund and processes it: the money is returned through the
+// payment provider and the refunded tickets are cancelled and put back on sale
+func (s *RefundService) ApproveRefund(ctx context.Context, orgID, refundID, reviewerID uuid.UUID, note string) (*models.Refund, error) {
+	refund, err := s.review(ctx, orgID, refundID, reviewerID, note, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.process(ctx, refund, reviewerID); err != nil {
+		return refund, err
+	}
+	return refund, nil
+}
+
+// RejectRefund rejects a requested refund, releasing its tickets and amount for later requests.
+// Buyers who requested the refund themselves are told by email.
+func (s *RefundService) RejectRefund(ctx context.Context, orgID, refundID, reviewerID uuid.UUID, note string) (*models.Refund, error) {
+	refund, err := s.review(ctx, orgID, refundID, reviewerID, note, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	if err := s.db.WithContext(ctx).Preload("Event").First(&order, "id = ?", refund.OrderID).Error; err != nil {
+		log.Printf("Failed to load order of rejected refund: Refund=%s, Error=%v", refund.ID, err)
+		return refund, nil
+	}
+	if order.UserID != nil && *order.UserID == refund.RequestedBy && order.Event != nil {
+		message := fmt.Sprintf("Your refund request for order %s (%s) was declined.", order.ID, order.Event.Title)
+		if note != "" {
+			message += " " + note
+		}
+		if err := s.orderService.emailQueueService.QueueNotificationEmail(order.BuyerEmail, order.BuyerName, "Your refund request", message); err != nil {
+			log.Printf("Failed to queue refund rejection email: Refund=%s, Error=%v", refund.ID, err)
+		}
+	}
+	return refund, nil
+}
+
+// ProcessRefund retries a refund whose processing failed, e.g. after a payment provider outage.
+// Money returned by earlier attempts is not returned again.
+func (s *RefundService) ProcessRefund(ctx context.Context, orgID, refundID, actorID uuid.UUID) (*models.Refund, error) {
+	db := s.db.WithContext(ctx)
+
+	var refund models.Refund
+	if err := db.Preload("Items").First(&refund, "id = ? AND organization_id = ?", refundID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefundNotFound
+		}
+		return nil, err
+	}
+	if refund.Status != models.RefundStatusFailed {
+		return nil, fmt.Errorf("Only failed refunds can be retried, this one is %s", refund.Status)
+	}
+
+	result := db.Model(&refund).
+		Where("status = ?", models.RefundStatusFailed).
+		Update("status", models.RefundStatusApproved)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("Refund is already being processed")
+	}
+	refund.Status = models.RefundStatusApproved
+
+	if err := s.process(ctx, &refund, actorID); err != nil {
+		return &refund, err
+	}
+	return &refund, nil
+}
+
+// buyerOrder loads an order placed by the user or for their email address
+func (s *RefundService) buyerOrder(ctx context.Context, userID, orderID uuid.UUID) (*models.Order, error) {
+	db := s.db.WithContext(ctx)
+
+	var user models.User
+	if err := db.Select("id", "email").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	err := db.Preload("Event").Preload("Insurance").
+		Where("id = ? AND (user_id = ? OR LOWER(buyer_email) = LOWER(?))", orderID, userID, user.Email).
+		First(&order).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Order not found")
+		}
+		return nil, err
+	}
+	return &order, nil
+}
+
+// request validates a refund request against the order and records it for approval
+func (s *RefundService) request(ctx context.Context, order *models.Order, requesterID uuid.UUID, req *models.CreateRefundRequest, enforcePolicy bool) (*models.Refund, error) {
+	if order.Status != models.OrderStatusPaid && order.Status != models.OrderStatusPartiallyPaid {
+		return nil, fmt.Errorf("Cannot refund a %s order", order.Status)
+	}
+
+	refund := models.Refund{
+		OrderID:        order.ID,
+		OrganizationID: order.OrganizationID,
+		EventID:        order.EventID,
+		Currency:       order.Currency,
+		Reason:         req.Reason,
+		Status:         models.RefundStatusRequested,
+		RequestedBy:    requesterID,
+	}
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the order so concurrent requests cannot reserve the same tickets or money
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(order, "id = ?", order.ID).Error; err != nil {
+			return err
+		}
+
+		available, err := s.availableAmount(tx, order)
+		if err != nil {
+			return err
+		}
+		items, amount, err := s.planRefund(tx, order, req, available)
+		if err != nil {
+			return err
+		}
+
+		if enforcePolicy {
+			// Buyers get the ticket price less the event's refund fee
+			if order.Event != nil && order.Event.RefundPolicy.FeePercent > 0 {
+				net := math.Floor(amount*(100-order.Event.RefundPolicy.FeePercent)) / 100
+				if err := checkRefundPolicy(order, net, amount, time.Now()); err != nil {
+					return err
+				}
+				amount = net
+				for i, share := range splitAmount(amount, len(items)) {
+					items[i].Amount = share
+				}
+			} else if err := checkRefundPolicy(order, amount, amount, time.Now()); err != nil {
+				return err
+			}
+		}
+		if amount <= 0 {
+			return errors.New("Nothing left to refund on this order")
+		}
+
+		refund.Amount = amount
+		refund.Items = items
+		if err := tx.Create(&refund).Error; err != nil {
+			return fmt.Errorf("failed to record refund: %w", err)
+		}
+
+		ticketIDs := make([]uuid.UUID, len(items))
+		for i, item := range items {
+			ticketIDs[i] = item.TicketID
+		}
+		return writeAuditLog(tx, &requesterID, AuditRefundRequested, "order", order.ID.String(), order.OrganizationID, map[string]interface{}{
+			"refund_id":  refund.ID,
+			"amount":     refund.Amount,
+			"currency":   refund.Currency,
+			"ticket_ids": ticketIDs,
+			"reason":     refund.Reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Refund requested: Refund=%s, Order=%s, Amount=%.2f, Tickets=%d", refund.ID, order.ID, refund.Amount, len(refund.Items))
+	return &refund, nil
+}
+
+// availableAmount returns how much of an order can still be refunded: what was collected and not
+// yet refunded, less the amounts of refunds still open
+func (s *RefundService) availableAmount(tx *gorm.DB, order *models.Order) (float64, error) {
+	refundable, err := s.orderService.refundableAmount(order)
+	if err != nil {
+		return 0, err
+	}
+
+	var open float64
+	if err := tx.Model(&models.Refund{}).
+		Where("order_id = ? AND status IN ?", order.ID, openRefundStatuses).
+		Select("COALESCE(SUM(amount), 0)").Scan(&open).Error; err != nil {
+		return 0, err
+	}
+	return math.Max(0, math.Round((refundable-open)*100)/100), nil
+}
+
+// planRefund works out the tickets a request cancels and the amount it returns. Tickets are worth
+// an equal share of the order's ticket total; refunding every remaining ticket returns all that is
+// left to refund.
+func (s *RefundService) planRefund(tx *gorm.DB, order *models.Order, req *models.CreateRefundRequest, available float64) ([]models.RefundItem, float64, error) {
+	if available <= 0 {
+		return nil, 0, errors.New("Nothing left to refund on this order")
+	}
+
+	// Money only: the tickets stay valid
+	if len(req.TicketIDs) == 0 && req.Amount != nil {
+		if toMinorUnits(*req.Amount) > toMinorUnits(available) {
+			return nil, 0, fmt.Errorf("Refund exceeds the refundable amount of %.2f %s", available, order.Currency)
+		}
+		return nil, *req.Amount, nil
+	}
+
+	// Tickets not cancelled and not part of an open refund
+	var remaining []models.Ticket
+	err := tx.Where("order_id = ? AND status <> ?", order.ID, models.TicketStatusCancelled).
+		Where("id NOT IN (?)", tx.Model(&models.RefundItem{}).
+			Select("refund_items.ticket_id").
+			Joins("JOIN refunds ON refunds.id = refund_items.refund_id").
+			Where("refunds.order_id = ? AND refunds.status IN ?", order.ID, openRefundStatuses)).
+		Order("created_at").
+		Find(&remaining).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var selected []models.Ticket
+	if len(req.TicketIDs) == 0 {
+		for _, ticket := range remaining {
+			if ticket.Status == models.TicketStatusValid {
+				selected = append(selected, ticket)
+			}
+		}
+		if len(selected) == 0 {
+			return nil, 0, errors.New("No tickets left to refund on this order")
+		}
+	} else {
+		byID := make(map[uuid.UUID]models.Ticket, len(remaining))
+		for _, ticket := range remaining {
+			byID[ticket.ID] = ticket
+		}
+		seen := make(map[uuid.UUID]bool, len(req.TicketIDs))
+		for _, id := range req.TicketIDs {
+			ticket, ok := byID[id]
+			if !ok || seen[id] {
+				return nil, 0, fmt.Errorf("Ticket %s cannot be refunded: it is not part of this order, already refunded or selected twice", id)
+			}
+			if ticket.Status != models.TicketStatusValid {
+				return nil, 0, fmt.Errorf("Ticket %s has already been used", id)
+			}
+			seen[id] = true
+			selected = append(selected, ticket)
+		}
+	}
+
+	var amount float64
+	if len(selected) == len(remaining) {
+		amount = available
+	} else {
+		unit := toMinorUnits(order.TotalAmount-order.InsuranceAmount) / int64(max(order.Quantity, 1))
+		amount = math.Min(fromMinorUnits(unit*int64(len(selected))), available)
+	}
+	if req.Amount != nil {
+		if toMinorUnits(*req.Amount) > toMinorUnits(amount) {
+			return nil, 0, fmt.Errorf("Refund exceeds the %.2f %s the selected tickets are worth", amount, order.Currency)
+		}
+		amount = *req.Amount
+	}
+
+	items := make([]models.RefundItem, len(selected))
+	for i, share := range splitAmount(amount, len(selected)) {
+		items[i] = models.RefundItem{TicketID: selected[i].ID, Amount: share}
+	}
+	return items, amount, nil
+}
+
+// review records the organization's decision on a requested refund
+func (s *RefundService) review(ctx context.Context, orgID, refundID, reviewerID uuid.UUID, note string, approve bool) (*models.Refund, error) {
+	db := s.db.WithContext(ctx)
+
+	var refund models.Refund
+	if err := db.Preload("Items").First(&refund, "id = ? AND organization_id = ?", refundID, orgID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefundNotFound
+		}
+		return nil, err
+	}
+	if refund.Status != models.RefundStatusRequested {
+		return nil, fmt.Errorf("Refund is already %s", refund.Status)
+	}
+
+	now := time.Now()
+	action, status := AuditRefundApproved, models.RefundStatusApproved
+	if !approve {
+		action, status = AuditRefundRejected, models.RefundStatusRejected
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&refund).
+			Where("status = ?", models.RefundStatusRequested).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"reviewed_by": reviewerID,
+				"reviewed_at": now,
+				"review_note": note,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Refund was reviewed concurrently")
+		}
+		return writeAuditLog(tx, &reviewerID, action, "order", refund.OrderID.String(), refund.OrganizationID, map[string]interface{}{
+			"refund_id": refund.ID,
+			"note":      note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refund.Status = status
+	refund.ReviewedBy = &reviewerID
+	refund.ReviewedAt = &now
+	refund.ReviewNote = note
+	return &refund, nil
+}
+
+// process returns an approved refund's money through the payment provider, then cancels its
+// tickets, puts them back on sale and updates the order. Failures mark the refund failed and are
+// recorded in the audit log.
+func (s *RefundService) process(ctx context.Context, refund *models.Refund, actorID uuid.UUID) error {
+	var order models.Order
+	if err := s.db.Preload("Event").Preload("Insurance").First(&order, "id = ?", refund.OrderID).Error; err != nil {
+		return s.fail(refund, actorID, err)
+	}
+	if order.Status != models.OrderStatusPaid && order.Status != models.OrderStatusPartiallyPaid {
+		return s.fail(refund, actorID, fmt.Errorf("Cannot refund a %s order", order.Status))
+	}
+
+	ticketIDs := make([]uuid.UUID, len(refund.Items))
+	for i, item := range refund.Items {
+		ticketIDs[i] = item.TicketID
+	}
+	var used int64
+	if err := s.db.Model(&models.Ticket{}).
+		Where("id IN ? AND status <> ?", ticketIDs, models.TicketStatusValid).
+		Count(&used).Error; err != nil {
+		return s.fail(refund, actorID, err)
+	}
+	if used > 0 {
+		return s.fail(refund, actorID, errors.New("Tickets of the refund were used or cancelled since it was requested"))
+	}
+
+	var kept int64
+	if err := s.db.Model(&models.Ticket{}).
+		Where("order_id = ? AND status <> ? AND id NOT IN ?", order.ID, models.TicketStatusCancelled, append(ticketIDs, uuid.Nil)).
+		Count(&kept).Error; err != nil {
+		return s.fail(refund, actorID, err)
+	}
+	closesOrder := (len(ticketIDs) > 0 && kept == 0) ||
+		toMinorUnits(order.RefundedAmount+refund.Amount) >= toMinorUnits(order.TotalAmount-boundPremium(&order))
+
+	refund.Attempts++
+	if err := s.db.Model(refund).Update("attempts", refund.Attempts).Error; err != nil {
+		return s.fail(refund, actorID, err)
+	}
+	source := refundSource{
+		key:      fmt.Sprintf("refund-%s-%d", refund.ID, refund.Attempts),
+		reason:   refund.Reason,
+		metadata: map[string]string{"refund_id": refund.ID.String()},
+	}
+
+	// Nothing is left to insure once every ticket is refunded. The policy is cancelled once;
+	// the premium the insurer returns is refunded with the tickets.
+	if closesOrder && hasBoundInsurance(&order) {
+		premium, err := s.orderService.cancelInsurance(ctx, &order, refundSource{key: "refund-" + refund.ID.String(), reason: refund.Reason})
+		if err != nil {
+			return s.fail(refund, actorID, err)
+		}
+		refund.PremiumAmount = premium
+		if err := s.db.Model(refund).Update("premium_amount", premium).Error; err != nil {
+			log.Printf("Failed to record refunded premium: Refund=%s, Premium=%.2f, Error=%v", refund.ID, premium, err)
+		}
+	}
+
+	// Money returned by earlier attempts
+	var returned float64
+	if len(refund.RefundRefs) > 0 {
+		if err := s.db.Model(&models.Payment{}).
+			Where("order_id = ? AND provider_ref IN ? AND status = ?", order.ID, []string(refund.RefundRefs), models.PaymentStatusRefunded).
+			Select("COALESCE(SUM(amount), 0)").Scan(&returned).Error; err != nil {
+			return s.fail(refund, actorID, err)
+		}
+	}
+
+	total := math.Round((refund.Amount+refund.PremiumAmount-returned)*100) / 100
+	manual := 0.0
+	if total > 0 {
+		refs, rest, err := s.orderService.refundPayments(ctx, &order, source, total)
+		refund.RefundRefs = append(refund.RefundRefs, refs...)
+		if err != nil {
+			return s.fail(refund, actorID, err)
+		}
+		manual = rest
+	}
+
+	now := time.Now()
+	var event models.Event
+	var previousAvailable int
+	var cancelled []models.Ticket
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", order.ID).Error; err != nil {
+			return err
+		}
+		previousStatus := order.Status
+
+		order.RefundedAmount = math.Round((order.RefundedAmount+refund.Amount+refund.PremiumAmount)*100) / 100
+		updates := map[string]interface{}{"refunded_amount": order.RefundedAmount}
+		if closesOrder {
+			updates["status"] = models.OrderStatusRefunded
+		}
+		if err := tx.Model(&order).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if len(ticketIDs) > 0 {
+			if err := tx.Where("id IN ? AND status = ?", ticketIDs, models.TicketStatusValid).Find(&cancelled).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Ticket{}).Where("id IN ?", ticketIDs).
+				Update("status", models.TicketStatusCancelled).Error; err != nil {
+				return err
+			}
+			if err := s.orderService.seatMapService.ReleaseTicketSeats(tx, ticketIDs); err != nil {
+				return err
+			}
+			if err := tx.First(&event, order.EventID).Error; err != nil {
+				return err
+			}
+			previousAvailable = event.Available
+			if err := s.orderService.inventoryService.Release(ctx, tx, &event, len(ticketIDs)); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(refund).Updates(map[string]interface{}{
+			"status":        models.RefundStatusProcessed,
+			"manual_amount": manual,
+			"refund_refs":   refund.RefundRefs,
+			"error":         "",
+			"processed_at":  now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return writeAuditLog(tx, &actorID, AuditRefundProcessed, "order", order.ID.String(), order.OrganizationID, map[string]interface{}{
+			"refund_id":       refund.ID,
+			"amount":          refund.Amount,
+			"premium_amount":  refund.PremiumAmount,
+			"provider_refund": math.Round((total-manual)*100) / 100,
+			"manual_refund":   manual,
+			"refund_refs":     refund.RefundRefs,
+			"ticket_ids":      ticketIDs,
+			"refunded_amount": order.RefundedAmount,
+			"previous_status": previousStatus,
+		})
+	})
+	if err != nil {
+		return s.fail(refund, actorID, err)
+	}
+
+	refund.Status = models.RefundStatusProcessed
+	refund.ManualAmount = manual
+	refund.Error = ""
+	refund.ProcessedAt = &now
+
+	if len(ticketIDs) > 0 {
+		InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable, OrganizationID: order.OrganizationID})
+	}
+	if order.Event != nil {
+		s.notifyProcessed(&order, refund, cancelled)
+	}
+
+	log.Printf("Refund processed: Refund=%s, Order=%s, Amount=%.2f, Tickets=%d", refund.ID, order.ID, refund.Amount+refund.PremiumAmount, len(ticketIDs))
+	return nil
+}
+
+// notifyProcessed tells the buyer about their refund, the organization's chat and hooks that it was
+// processed, and the holders of cancelled tickets that their tickets no longer admit them. Holders
+// who are the buyer learn it from the refund email.
+func (s *RefundService) notifyProcessed(order *models.Order, refund *models.Refund, cancelled []models.Ticket) {
+	s.orderService.integrationService.NotifyOrderRefunded(order, order.Event, refund.Amount+refund.PremiumAmount)
+	if order.OrganizationID != nil {
+		if err := s.orderService.integrationService.DispatchHook(*order.OrganizationID, models.HookEventRefundProcessed, refund); err != nil {
+			log.Printf("Failed to dispatch refund hook: Refund=%s, Error=%v", refund.ID, err)
+		}
+	}
+
+	emails := s.orderService.emailQueueService
+	if err := emails.QueueRefundProcessedEmail(order, order.Event, refund); err != nil {
+		log.Printf("Failed to queue refund email: Refund=%s, Error=%v", refund.ID, err)
+	}
+	for i := range cancelled {
+		if strings.EqualFold(cancelled[i].AttendeeEmail, order.BuyerEmail) {
+			continue
+		}
+		if err := emails.QueueTicketRefundEmail(&cancelled[i], order.Event); err != nil {
+			log.Printf("Failed to queue ticket refund email: Ticket=%s, Error=%v", cancelled[i].ID, err)
+		}
+	}
+}
+
+// fail marks a refund failed and records the failure in the audit log. Provider refunds made
+// before the failure are kept on the refund, so a retry does not return them again.
+func (s *RefundService) fail(refund *models.Refund, actorID uuid.UUID, cause error) error {
+	refund.Status = models.RefundStatusFailed
+	refund.Error = cause.Error()
+	if err := s.db.Model(refund).Updates(map[string]interface{}{
+		"status":      models.RefundStatusFailed,
+		"error":       cause.Error(),
+		"refund_refs": refund.RefundRefs,
+	}).Error; err != nil {
+		log.Printf("Failed to update refund: Refund=%s, Error=%v", refund.ID, err)
+	}
+
+	recordAuditLog(s.db, &actorID, AuditRefundFailed, "order", refund.OrderID.String(), refund.OrganizationID, map[string]interface{}{
+		"refund_id": refund.ID,
+		"error":     cause.Error(),
+	})
+
+	return cause
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// scanAnomaliesSQL finds the tickets of an event whose scans match a suspicious pattern, one row
+// per ticket and pattern, with a summary of all of the ticket's scans at the event. Raw SQL
+// bypasses the tenancy guard, hence the explicit organization condition.
+const scanAnomaliesSQL = `
+WITH attempts AS (
+	SELECT * FROM scan_attempts
+	WHERE organization_id = @org AND event_id = @event AND ticket_id IS NOT NULL
+), ordered AS (
+	SELECT ticket_id, gate, scanned_at,
+		LAG(gate) OVER (PARTITION BY ticket_id ORDER BY scanned_at) AS previous_gate,
+		LAG(scanned_at) OVER (PARTITION BY ticket_id ORDER BY scanned_at) AS previous_at
+	FROM attempts WHERE gate <> ''
+), flagged AS (
+	SELECT @multiple_gates::text AS kind, ticket_id, MIN(EXTRACT(EPOCH FROM scanned_at - previous_at))::double precision AS gap_seconds
+	FROM ordered
+	WHERE previous_gate <> gate AND scanned_at - previous_at <= @window * INTERVAL '1 second'
+	GROUP BY ticket_id
+	UNION ALL
+	SELECT @repeated_rejections::text, ticket_id, NULL FROM attempts
+	GROUP BY ticket_id HAVING COUNT(*) FILTER (WHERE result IN @rejections) >= @min_attempts
+	UNION ALL
+	SELECT @cancelled_ticket::text, ticket_id, NULL FROM attempts
+	GROUP BY ticket_id HAVING BOOL_OR(result IN @voided)
+)
+SELECT flagged.kind, flagged.ticket_id, flagged.gap_seconds,
+	COALESCE(tickets.attendee_name, '') AS attendee_name,
+	COUNT(*) AS scans,
+	COALESCE(STRING_AGG(DISTINCT NULLIF(attempts.gate, ''), ','), '') AS gates,
+	COALESCE(STRING_AGG(DISTINCT COALESCE(NULLIF(attempts.device_id, ''), attempts.scanner_device_id::text), ','), '') AS devices,
+	MIN(attempts.scanned_at) AS first_scan_at,
+	MAX(attempts.scanned_at) AS last_scan_at
+FROM flagged
+JOIN attempts ON attempts.ticket_id = flagged.ticket_id
+LEFT JOIN tickets ON tickets.id = flagged.ticket_id
+GROUP BY flagged.kind, flagged.ticket_id, flagged.gap_seconds, tickets.attendee_name
+ORDER BY last_scan_at DESC, flagged.ticket_id, flagged.kind`
+
+const (
+	// defaultScanAnomalyWindow is how close scans at different gates must be to be reported
+	defaultScanAnomalyWindow = 60
+	// defaultScanAnomalyMinAttempts is how often a used ticket must be rejected to be reported
+	defaultScanAnomalyMinAttempts = 3
+)
+
+// ScanAuditService keeps the audit trail of every scan at the door, admitted or rejected, and
+// looks through it for tickets being shared or forged
+type ScanAuditService struct {
+	db *gorm.DB
+}
+
+// NewScanAuditService creates a new scan audit service
+func NewScanAuditService() *ScanAuditService {
+	return &ScanAuditService{db: database.DB}
+}
+
+// scanAnomalyRow is a row of scanAnomaliesSQL
+type scanAnomalyRow struct {
+	Kind         models.ScanAnomalyKind
+	TicketID     uuid.UUID
+	GapSeconds   *float64
+	AttendeeName string
+	Scans        int64
+	Gates        string
+	Devices      string
+	FirstScanAt  time.Time
+	LastScanAt   time.Time
+}
+
+// List returns the requested page of an organization's scan attempts, newest first
+func (s *ScanAuditService) List(ctx context.Context, orgID uuid.UUID, query *models.ScanAttemptQuery) ([]models.ScanAttempt, *models.PageMeta, error) {
+	search := s.db.WithContext(ctx).Model(&models.ScanAttempt{}).Where("organization_id = ?", orgID)
+	if query.EventID != 0 {
+		search = search.Where("event_id = ?", query.EventID)
+	}
+	if query.TicketID != "" {
+		search = search.Where("ticket_id = ?", query.TicketID)
+	}
+	if query.Result != "" {
+		search = search.Where("result = ?", query.Result)
+	}
+	if query.Gate != "" {
+		search = search.Where("gate = ?", query.Gate)
+	}
+
+	attempts := []models.ScanAttempt{}
+	meta, err := database.Paginate(search.Order("scanned_at DESC").Order("id ASC"), query.PageQuery, &attempts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attempts, meta, nil
+}
+
+// Anomalies returns the tickets of an organization's event whose scans suggest sharing or
+// forgery, most recently scanned first: tickets scanned at two gates within the window, used
+// tickets rejected again and again, and cancelled or revoked tickets presented at the door
+func (s *ScanAuditService) Anomalies(ctx context.Context, orgID uuid.UUID, query *models.ScanAnomalyQuery) ([]models.ScanAnomaly, error) {
+	window := query.Window
+	if window == 0 {
+		window = defaultScanAnomalyWindow
+	}
+	minAttempts := query.MinAttempts
+	if minAttempts == 0 {
+		minAttempts = defaultScanAnomalyMinAttempts
+	}
+
+	var rows []scanAnomalyRow
+	err := s.db.WithContext(ctx).Raw(scanAnomaliesSQL, map[string]interface{}{
+		"org":                 orgID,
+		"event":               query.EventID,
+		"window":              window,
+		"min_attempts":        minAttempts,
+		"rejections":          []models.TicketScanResult{models.TicketScanAlreadyCheckedIn, models.TicketScanDuplicate},
+		"voided":              []models.TicketScanResult{models.TicketScanCancelled, models.TicketScanRevoked},
+		"multiple_gates":      models.ScanAnomalyMultipleGates,
+		"repeated_rejections": models.ScanAnomalyRepeatedRejections,
+		"cancelled_ticket":    models.ScanAnomalyCancelledTicket,
+	}).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]models.ScanAnomaly, len(rows))
+	for i, row := range rows {
+		anomalies[i] = models.ScanAnomaly{
+			Kind:         row.Kind,
+			TicketID:     row.TicketID,
+			AttendeeName: row.AttendeeName,
+			Scans:        row.Scans,
+			Gates:        splitList(row.Gates),
+			Devices:      splitList(row.Devices),
+			GapSeconds:   row.GapSeconds,
+			FirstScanAt:  row.FirstScanAt,
+			LastScanAt:   row.LastScanAt,
+		}
+	}
+	return anomalies, nil
+}
+
+// recordScanAttempts adds scans to the audit trail. Failures are logged rather than returned, so
+// the audit trail never turns attendees away at the door.
+func recordScanAttempts(db *gorm.DB, attempts []models.ScanAttempt) {
+	if len(attempts) == 0 {
+		return
+	}
+	if err := db.Create(&attempts).Error; err != nil {
+		log.Printf("Failed to record scan attempts: Count=%d, Error=%v", len(attempts), err)
+	}
+}
+
+// splitList splits a comma-separated aggregate, returning nil for an empty one
+func splitList(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScannerTokenPrefix marks the device tokens of paired scanners, telling them apart from user tokens
+const ScannerTokenPrefix = "scn_"
+
+// scannerPairingQRPrefix marks the payload of pairing QR codes
+const scannerPairingQRPrefix = "SCANPAIR1."
+
+// scannerCodeAlphabet leaves out characters easily mistaken for one another when typed
+const scannerCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// scannerCodeLength is the number of characters in a pairing code, about 40 bits
+const scannerCodeLength = 8
+
+var (
+	// ErrInvalidPairingCode is returned for a pairing code that is unknown, used or expired
+	ErrInvalidPairingCode = errors.New("Invalid or expired pairing code")
+	// ErrInvalidScannerToken is returned for a device token that is unknown, revoked or expired
+	ErrInvalidScannerToken = errors.New("Invalid or expired scanner token")
+)
+
+// ScannerService pairs door scanner devices to events. A device is never given a user token: it
+// exchanges a short-lived pairing code for a device token scoped to one event's check-ins.
+type ScannerService struct {
+	db  *gorm.DB
+	cfg config.ScanConfig
+}
+
+// NewScannerService creates a new scanner service
+func NewScannerService(cfg *config.Config) *ScannerService {
+	return &ScannerService{
+		db:  database.DB,
+		cfg: cfg.Scan,
+	}
+}
+
+// CreatePairing generates a pairing code for a scanner device to check attendees in to an event.
+// Only the event's organizer can pair scanners; the devices check attendees in on their behalf.
+func (s *ScannerService) CreatePairing(ctx context.Context, eventID uint, userID uuid.UUID, req *models.ScannerPairingRequest) (*models.ScannerPairingResponse, error) {
+	db := s.db.WithContext(ctx)
+	event, err := s.authorizeOrganizer(db, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status == models.EventStatusCancelled || event.Status == models.EventStatusCompleted || !event.EndDate.After(time.Now()) {
+		return nil, errors.New("Scanners can only be paired to upcoming events")
+	}
+
+	orgID, err := organizerOrganization(db, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := newPairingCode()
+	if err != nil {
+		return nil, err
+	}
+	device := &models.ScannerDevice{
+		EventID:          event.ID,
+		OrganizationID:   orgID,
+		Name:             strings.TrimSpace(req.Name),
+		PairedBy:         userID,
+		PairingCodeHash:  utils.HashToken(code),
+		PairingExpiresAt: time.Now().Add(s.cfg.PairingCodeTTL),
+	}
+	if err := db.Create(device).Error; err != nil {
+		return nil, fmt.Errorf("failed to create scanner pairing: %w", err)
+	}
+
+	return &models.ScannerPairingResponse{
+		DeviceID:  device.ID,
+		Code:      code[:scannerCodeLength/2] + "-" + code[scannerCodeLength/2:],
+		QRCode:    scannerPairingQRPrefix + code,
+		ExpiresAt: device.PairingExpiresAt,
+	}, nil
+}
+
+// Pair exchanges a pairing code, typed or scanned from its QR code, for a device token. Each code
+// can be exchanged once.
+func (s *ScannerService) Pair(ctx context.Context, req *models.ScannerPairRequest) (*models.ScannerTokenResponse, error) {
+	code := normalizePairingCode(req.Code)
+	if len(code) != scannerCodeLength {
+		return nil, ErrInvalidPairingCode
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate scanner token: %w", err)
+	}
+	token := ScannerTokenPrefix + hex.EncodeToString(raw)
+	tokenHash := utils.HashToken(token)
+
+	// Claiming the code in one statement keeps two devices from pairing with the same code
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+	expiresAt := now.Add(s.cfg.DeviceTokenTTL)
+	result := db.Model(&models.ScannerDevice{}).
+		Where("pairing_code_hash = ? AND paired_at IS NULL AND revoked_at IS NULL AND pairing_expires_at > ?", utils.HashToken(code), now).
+		Updates(map[string]interface{}{
+			"paired_at":        now,
+			"token_hash":       tokenHash,
+			"token_expires_at": expiresAt,
+			"app_version":      strings.TrimSpace(req.AppVersion),
+			"last_seen_at":     now,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to pair scanner: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidPairingCode
+	}
+
+	var device models.ScannerDevice
+	if err := db.Where("token_hash = ?", tokenHash).First(&device).Error; err != nil {
+		return nil, err
+	}
+
+	recordAuditLog(db, &device.PairedBy, "scanner.paired", "scanner_device", device.ID.String(), nil,
+		map[string]interface{}{"event_id": device.EventID, "name": device.Name})
+	log.Printf("Scanner paired: Device=%s, Event=%d", device.ID, device.EventID)
+
+	return &models.ScannerTokenResponse{
+		DeviceID:  device.ID,
+		EventID:   device.EventID,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// Authenticate returns the paired device a token belongs to, provided it is scoped to the event,
+// and records that the device was seen running the app version it reports
+func (s *ScannerService) Authenticate(ctx context.Context, token string, eventID uint, appVersion string) (*models.ScannerDevice, error) {
+	db := s.db.WithContext(ctx)
+	now := time.Now()
+
+	var device models.ScannerDevice
+	err := db.Where("token_hash = ? AND revoked_at IS NULL AND token_expires_at > ?", utils.HashToken(token), now).
+		First(&device).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidScannerToken
+		}
+		return nil, err
+	}
+	if device.EventID != eventID {
+		return nil, ErrEventAccessDenied
+	}
+
+	seen := map[string]interface{}{"last_seen_at": now}
+	if appVersion = strings.TrimSpace(appVersion); appVersion != "" && len(appVersion) <= 50 {
+		seen["app_version"] = appVersion
+	}
+	if err := db.Model(&device).UpdateColumns(seen).Error; err != nil {
+		log.Printf("Failed to record scanner activity: Device=%s, Error=%v", device.ID, err)
+	}
+	return &device, nil
+}
+
+// ListDevices returns the scanners paired or being paired to an event, newest first
+func (s *ScannerService) ListDevices(ctx context.Context, eventID uint, userID uuid.UUID) ([]models.ScannerDevice, error) {
+	db := s.db.WithContext(ctx)
+	if _, err := s.authorizeOrganizer(db, eventID, userID); err != nil {
+		return nil, err
+	}
+
+	devices := []models.ScannerDevice{}
+	if err := db.Where("event_id = ?", eventID).Order("created_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// RevokeDevice unpairs a scanner, e.g. a lost device. Its token stops working immediately, and an
+// unused pairing code can no longer be exchanged.
+func (s *ScannerService) RevokeDevice(ctx context.Context, eventID uint, deviceID uuid.UUID, userID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+	if _, err := s.authorizeOrganizer(db, eventID, userID); err != nil {
+		return err
+	}
+
+	result := db.Model(&models.ScannerDevice{}).
+		Where("id = ? AND event_id = ? AND revoked_at IS NULL", deviceID, eventID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Scanner not found")
+	}
+
+	recordAuditLog(db, &userID, "scanner.revoked", "scanner_device", deviceID.String(), nil,
+		map[string]interface{}{"event_id": eventID})
+	return nil
+}
+
+// ListOrganizationDevices returns the scanners of an organization, newest first. Revoked devices
+// are included unless activeOnly is set.
+func (s *ScannerService) ListOrganizationDevices(ctx context.Context, orgID uuid.UUID, activeOnly bool) ([]models.ScannerDevice, error) {
+	query := s.db.WithContext(ctx).Where("organization_id = ?", orgID)
+	if activeOnly {
+		query = query.Where("revoked_at IS NULL")
+	}
+
+	devices := []models.ScannerDevice{}
+	if err := query.Order("created_at DESC").Find(&devices).Error; err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// RenameDevice changes the label of an organization's scanner
+func (s *ScannerService) RenameDevice(ctx context.Context, orgID uuid.UUID, deviceID uuid.UUID, req *models.ScannerDeviceUpdateRequest) (*models.ScannerDevice, error) {
+	db := s.db.WithContext(ctx)
+	var device models.ScannerDevice
+	if err := db.Where("id = ? AND organization_id = ?", deviceID, orgID).First(&device).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Scanner not found")
+		}
+		return nil, err
+	}
+
+	device.Name = strings.TrimSpace(req.Name)
+	if err := db.Model(&device).Update("name", device.Name).Error; err != nil {
+		return nil, fmt.Errorf("failed to rename scanner: %w", err)
+	}
+	return &device, nil
+}
+
+// RevokeOrganizationDevice cuts an organization's scanner off from the check-in endpoints
+// immediately, e.g. a lost phone
+func (s *ScannerService) RevokeOrganizationDevice(ctx context.Context, orgID uuid.UUID, deviceID uuid.UUID, actorID uuid.UUID) error {
+	db := s.db.WithContext(ctx)
+	result := db.Model(&models.ScannerDevice{}).
+		Where("id = ? AND organization_id = ? AND revoked_at IS NULL", deviceID, orgID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("Scanner not found")
+	}
+
+	recordAuditLog(db, &actorID, "scanner.revoked", "scanner_device", deviceID.String(), &orgID, nil)
+	return nil
+}
+
+// authorizeOrganizer loads an event organized by the user
+func (s *ScannerService) authorizeOrganizer(db *gorm.DB, eventID uint, userID uuid.UUID) (*models.Event, error) {
+	var event models.Event
+	if err := db.First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+	if event.OrganizerID == nil || *event.OrganizerID != userID {
+		return nil, ErrEventAccessDenied
+	}
+	return &event, nil
+}
+
+// organizerOrganization returns the organization scanners paired by an organizer belong to: the
+// first one they joined, or else the first they organize
+func organizerOrganization(db *gorm.DB, userID uuid.UUID) (*uuid.UUID, error) {
+	var user models.User
+	if err := db.First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	if user.OrganizationID != nil {
+		return user.OrganizationID, nil
+	}
+
+	var orgs []models.Organization
+	if err := db.Where("organizer_id = ?", userID).Order("created_at").Limit(1).Find(&orgs).Error; err != nil {
+		return nil, err
+	}
+	if len(orgs) == 0 {
+		return nil, nil
+	}
+	return &orgs[0].ID, nil
+}
+
+// newPairingCode generates a random pairing code
+func newPairingCode() (string, error) {
+	raw := make([]byte, scannerCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate pairing code: %w", err)
+	}
+	code := make([]byte, scannerCodeLength)
+	for i, b := range raw {
+		code[i] = scannerCodeAlphabet[int(b)%len(scannerCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// normalizePairingCode reduces a typed code or scanned QR payload to the bare code
+func normalizePairingCode(input string) string {
+	code := strings.TrimPrefix(strings.TrimSpace(input), scannerPairingQRPrefix)
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	return strings.ToUpper(code)
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrSeatsUnavailable is returned when a selected seat is sold or held by another checkout
+	ErrSeatsUnavailable = errors.New("Some of the selected seats are no longer available")
+	// ErrSeatedCapacity is returned when the capacity of an event with a seat map is changed directly
+	ErrSeatedCapacity = errors.New("The capacity of an event with a seat map follows its seats")
+	// ErrVenueNotFound is returned when a venue does not exist in the organization
+	ErrVenueNotFound = errors.New("Venue not found")
+)
+
+// SeatMapService manages venues' seat maps and the seats of the events they are attached to.
+// Seats selected in a checkout are held for it for CHECKOUT_SEAT_HOLD_TTL; expired holds are
+// available again without any cleanup.
+type SeatMapService struct {
+	db               *gorm.DB
+	inventoryService *InventoryService
+	holdTTL          time.Duration
+}
+
+// NewSeatMapService creates a new seat map service
+func NewSeatMapService(cfg *config.Config) *SeatMapService {
+	return &SeatMapService{
+		db:               database.DB,
+		inventoryService: NewInventoryService(),
+		holdTTL:          cfg.Checkout.SeatHoldTTL,
+	}
+}
+
+// CreateVenue defines a venue of the organization with its sections, rows and seats
+func (s *SeatMapService) CreateVenue(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, req *models.VenueRequest) (*models.Venue, error) {
+	venue := models.Venue{
+		OrganizationID: orgID,
+		Name:           strings.TrimSpace(req.Name),
+		Address:        strings.TrimSpace(req.Address),
+		CreatedBy:      &userID,
+	}
+	for i, sectionReq := range req.Sections {
+		section := &models.Section{Name: strings.TrimSpace(sectionReq.Name), Position: i + 1}
+		for j, rowReq := range sectionReq.Rows {
+			if (rowReq.SeatCount > 0) == (len(rowReq.Seats) > 0) {
+				return nil, fmt.Errorf("Row %s of section %s needs either seat_count or seats", rowReq.Label, section.Name)
+			}
+			row := &models.Row{Label: strings.TrimSpace(rowReq.Label), Position: j + 1}
+			if rowReq.SeatCount > 0 {
+				for k := 1; k <= rowReq.SeatCount; k++ {
+					row.Seats = append(row.Seats, &models.Seat{Label: strconv.Itoa(k), Position: k})
+				}
+			}
+			for k, seatReq := range rowReq.Seats {
+				row.Seats = append(row.Seats, &models.Seat{
+					Label:      strings.TrimSpace(seatReq.Label),
+					Position:   k + 1,
+					Accessible: seatReq.Accessible,
+				})
+			}
+			section.Rows = append(section.Rows, row)
+		}
+		venue.Sections = append(venue.Sections, section)
+	}
+
+	if err := s.db.WithContext(ctx).Create(&venue).Error; err != nil {
+		return nil, fmt.Errorf("failed to create venue: %w", err)
+	}
+
+	recordAuditLog(s.db.WithContext(ctx), &userID, "venue.created", "venue", venue.ID.String(), &orgID, map[string]interface{}{
+		"name": venue.Name,
+	})
+	log.Printf("Venue created: Venue=%s, Organization=%s", venue.ID, orgID)
+	return &venue, nil
+}
+
+// ListVenues returns the organization's venues, without their seat maps
+func (s *SeatMapService) ListVenues(ctx context.Context, orgID uuid.UUID) ([]models.Venue, error) {
+	var venues []models.Venue
+	if err := s.db.WithContext(ctx).Where("organization_id = ?", orgID).Order("name").Find(&venues).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch venues: %w", err)
+	}
+	return venues, nil
+}
+
+// GetVenue returns a venue of the organization with its seat map
+func (s *SeatMapService) GetVenue(ctx context.Context, orgID uuid.UUID, venueID uuid.UUID) (*models.Venue, error) {
+	byPosition := func(db *gorm.DB) *gorm.DB { return db.Order("position") }
+
+	var venue models.Venue
+	err := s.db.WithContext(ctx).
+		Preload("Sections", byPosition).
+		Preload("Sections.Rows", byPosition).
+		Preload("Sections.Rows.Seats", byPosition).
+		Where("id = ? AND organization_id = ?", venueID, orgID).
+		First(&venue).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVenueNotFound
+		}
+		return nil, err
+	}
+	return &venue, nil
+}
+
+// AttachSeatMap puts the seats of a venue on sale for an event of the organizer, replacing any
+// seat map attached before. The event's capacity becomes the number of seats, so it can only be
+// done before any ticket is sold.
+func (s *SeatMapService) AttachSeatMap(ctx context.Context, eventID uint, userID uuid.UUID, req *models.AttachSeatMapRequest) (*models.SeatMapResponse, error) {
+	var event models.Event
+	var previousAvailable int
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Event not found")
+			}
+			return err
+		}
+		if event.OrganizerID == nil || *event.OrganizerID != userID {
+			return ErrEventAccessDenied
+		}
+
+		var venue models.Venue
+		err := tx.Joins("JOIN organizations ON organizations.id = venues.organization_id").
+			Where("venues.id = ?", req.VenueID).
+			Where("organizations.organizer_id = ? OR organizations.id IN (?)", userID,
+				tx.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ? AND active", userID)).
+			First(&venue).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrVenueNotFound
+			}
+			return err
+		}
+
+		var sold int64
+		if err := tx.Model(&models.Ticket{}).
+			Where("event_id = ? AND status <> ?", event.ID, models.TicketStatusCancelled).
+			Count(&sold).Error; err != nil {
+			return err
+		}
+		if sold > 0 {
+			return errors.New("A seat map can only be attached before tickets are sold")
+		}
+
+		var seatIDs []uuid.UUID
+		if err := tx.Model(&models.Seat{}).
+			Joins("JOIN rows ON rows.id = seats.row_id").
+			Joins("JOIN sections ON sections.id = rows.section_id").
+			Where("sections.venue_id = ?", venue.ID).
+			Pluck("seats.id", &seatIDs).Error; err != nil {
+			return err
+		}
+		if len(seatIDs) == 0 {
+			return errors.New("Venue has no seats")
+		}
+
+		if err := tx.Where("event_id = ?", event.ID).Delete(&models.EventSeat{}).Error; err != nil {
+			return err
+		}
+		eventSeats := make([]models.EventSeat, len(seatIDs))
+		for i, seatID := range seatIDs {
+			eventSeats[i] = models.EventSeat{EventID: event.ID, SeatID: seatID, Status: models.SeatStatusAvailable}
+		}
+		if err := tx.CreateInBatches(eventSeats, 500).Error; err != nil {
+			return fmt.Errorf("failed to create event seats: %w", err)
+		}
+
+		if err := tx.Model(&event).Update("venue_id", venue.ID).Error; err != nil {
+			return err
+		}
+		previousAvailable = event.Available
+		return s.inventoryService.Resize(ctx, tx, &event, len(seatIDs))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	InventoryChanged(&InventoryChange{Event: &event, PreviousAvailable: previousAvailable})
+	log.Printf("Seat map attached: Event=%d, Venue=%s, Seats=%d", event.ID, req.VenueID, event.Capacity)
+	return s.GetEventSeats(ctx, eventID)
+}
+
+// GetEventSeats returns the seat map of an event with the availability of each seat. Seats held
+// by a checkout whose hold expired are shown available.
+func (s *SeatMapService) GetEventSeats(ctx context.Context, eventID uint) (*models.SeatMapResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var event models.Event
+	if err := db.First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("Event not found")
+		}
+		return nil, err
+	}
+	if event.VenueID == nil {
+		return nil, errors.New("Event has no seat map")
+	}
+
+	var venue models.Venue
+	if err := db.First(&venue, "id = ?", *event.VenueID).Error; err != nil {
+		return nil, err
+	}
+
+	seats, err := s.eventSeats(db, event.ID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.SeatMapResponse{EventID: event.ID, VenueID: venue.ID, VenueName: venue.Name}
+	for _, seat := range seats {
+		if n := len(resp.Sections); n == 0 || resp.Sections[n-1].Name != seat.Section {
+			resp.Sections = append(resp.Sections, models.SeatMapSection{Name: seat.Section})
+		}
+		section := &resp.Sections[len(resp.Sections)-1]
+		if n := len(section.Rows); n == 0 || section.Rows[n-1].Label != seat.Row {
+			section.Rows = append(section.Rows, models.SeatMapRow{Label: seat.Row})
+		}
+		row := &section.Rows[len(section.Rows)-1]
+		if seat.Status == models.SeatStatusAvailable {
+			resp.Available++
+		}
+		seat.Section, seat.Row = "", ""
+		row.Seats = append(row.Seats, seat)
+	}
+	return resp, nil
+}
+
+// HoldSeats holds seats of an event for a checkout, replacing the seats it held for the event
+// before. Either all seats are held or, when any is taken, none and the previous hold stays.
+// An empty selection releases the checkout's seats.
+func (s *SeatMapService) HoldSeats(ctx context.Context, holdID uuid.UUID, req *models.SeatHoldRequest) (*models.SeatHoldResponse, error) {
+	seatIDs := req.SeatIDs
+	if err := uniqueSeats(seatIDs); err != nil {
+		return nil, err
+	}
+
+	resp := &models.SeatHoldResponse{EventID: req.EventID, Seats: []models.SeatMapSeat{}}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var event models.Event
+		if err := tx.Select("id", "venue_id").First(&event, req.EventID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("Event not found")
+			}
+			return err
+		}
+		if event.VenueID == nil {
+			return errors.New("Event has no seat map")
+		}
+
+		if err := tx.Model(&models.EventSeat{}).
+			Where("event_id = ? AND hold_id = ? AND status = ?", event.ID, holdID, models.SeatStatusHeld).
+			Updates(map[string]interface{}{"status": models.SeatStatusAvailable, "hold_id": nil, "held_until": nil}).Error; err != nil {
+			return err
+		}
+		if len(seatIDs) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		heldUntil := now.Add(s.holdTTL)
+		result := tx.Model(&models.EventSeat{}).
+			Where("event_id = ? AND seat_id IN ?", event.ID, seatIDs).
+			Where("status = ? OR (status = ? AND held_until < ?)", models.SeatStatusAvailable, models.SeatStatusHeld, now).
+			Updates(map[string]interface{}{"status": models.SeatStatusHeld, "hold_id": holdID, "held_until": heldUntil})
+		if result.Error != nil {
+			return fmt.Errorf("failed to hold seats: %w", result.Error)
+		}
+		if result.RowsAffected != int64(len(seatIDs)) {
+			return ErrSeatsUnavailable
+		}
+
+		seats, err := s.eventSeats(tx, event.ID, seatIDs)
+		if err != nil {
+			return err
+		}
+		resp.Seats = seats
+		resp.HeldUntil = &heldUntil
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SellSeats marks the seats of tickets being issued sold, in the caller's transaction. Seats
+// must be available, held by an expired hold or held by holdID.
+func (s *SeatMapService) SellSeats(tx *gorm.DB, eventID uint, holdID *uuid.UUID, tickets []*models.Ticket) error {
+	now := time.Now()
+	for _, ticket := range tickets {
+		if ticket.SeatID == nil {
+			continue
+		}
+		query := tx.Model(&models.EventSeat{}).Where("event_id = ? AND seat_id = ?", eventID, *ticket.SeatID)
+		if holdID != nil {
+			query = query.Where("status = ? OR (status = ? AND (held_until < ? OR hold_id = ?))",
+				models.SeatStatusAvailable, models.SeatStatusHeld, now, *holdID)
+		} else {
+			query = query.Where("status = ? OR (status = ? AND held_until < ?)",
+				models.SeatStatusAvailable, models.SeatStatusHeld, now)
+		}
+		result := query.Updates(map[string]interface{}{
+			"status":     models.SeatStatusSold,
+			"ticket_id":  ticket.ID,
+			"hold_id":    nil,
+			"held_until": nil,
+		})
+		if result.Error != nil {
+			return fmt.Errorf("failed to sell seat: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrSeatsUnavailable
+		}
+	}
+	return nil
+}
+
+// ReleaseOrderSeats puts the seats of an order's tickets back on sale, in the caller's transaction
+func (s *SeatMapService) ReleaseOrderSeats(tx *gorm.DB, orderID uuid.UUID) error {
+	err := tx.Model(&models.EventSeat{}).
+		Where("ticket_id IN (?)", tx.Model(&models.Ticket{}).Select("id").Where("order_id = ?", orderID)).
+		Updates(map[string]interface{}{"status": models.SeatStatusAvailable, "ticket_id": nil}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release seats: %w", err)
+	}
+	return nil
+}
+
+// ReleaseTicketSeats puts the seats of some tickets back on sale, in the caller's transaction
+func (s *SeatMapService) ReleaseTicketSeats(tx *gorm.DB, ticketIDs []uuid.UUID) error {
+	err := tx.Model(&models.EventSeat{}).
+		Where("ticket_id IN ?", ticketIDs).
+		Updates(map[string]interface{}{"status": models.SeatStatusAvailable, "ticket_id": nil}).Error
+	if err != nil {
+		return fmt.Errorf("failed to release seats: %w", err)
+	}
+	return nil
+}
+
+// eventSeats loads seats of an event in seat map order, all of them when seatIDs is nil
+func (s *SeatMapService) eventSeats(db *gorm.DB, eventID uint, seatIDs []uuid.UUID) ([]models.SeatMapSeat, error) {
+	var rows []struct {
+		SeatID      uuid.UUID
+		SectionName string
+		RowLabel    string
+		Label       string
+		Accessible  bool
+		Status      models.SeatStatus
+		HeldUntil   *time.Time
+	}
+	query := db.Model(&models.EventSeat{}).
+		Select("event_seats.seat_id, sections.name AS section_name, rows.label AS row_label, seats.label, seats.accessible, event_seats.status, event_seats.held_until").
+		Joins("JOIN seats ON seats.id = event_seats.seat_id").
+		Joins("JOIN rows ON rows.id = seats.row_id").
+		Joins("JOIN sections ON sections.id = rows.section_id").
+		Where("event_seats.event_id = ?", eventID).
+		Order("sections.position, rows.position, seats.position")
+	if seatIDs != nil {
+		query = query.Where("event_seats.seat_id IN ?", seatIDs)
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch seats: %w", err)
+	}
+
+	now := time.Now()
+	seats := make([]models.SeatMapSeat, len(rows))
+	for i, row := range rows {
+		status := row.Status
+		if status == models.SeatStatusHeld && row.HeldUntil != nil && row.HeldUntil.Before(now) {
+			status = models.SeatStatusAvailable
+		}
+		seats[i] = models.SeatMapSeat{
+			ID:         row.SeatID,
+			Section:    row.SectionName,
+			Row:        row.RowLabel,
+			Label:      row.Label,
+			Accessible: row.Accessible,
+			Status:     status,
+		}
+	}
+	return seats, nil
+}
+
+// uniqueSeats rejects a seat selected twice
+func uniqueSeats(seatIDs []uuid.UUID) error {
+	seen := make(map[uuid.UUID]bool, len(seatIDs))
+	for _, id := range seatIDs {
+		if seen[id] {
+			return errors.New("A seat was selected twice")
+		}
+		seen[id] = true
+	}
+	return nil
+}
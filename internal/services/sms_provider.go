@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"event-ticketing-backend/pkg/config"
+)
+
+// ErrSMSUnavailable is returned when SMS delivery is requested but no provider is configured
+var ErrSMSUnavailable = errors.New("SMS delivery is not available")
+
+// SMSMessage is a text message to one phone number
+type SMSMessage struct {
+	To             string // Phone number in international format
+	Body           string
+	IdempotencyKey string // Lets the provider drop a retried send it already accepted
+}
+
+// SMSProvider sends text messages
+type SMSProvider interface {
+	Name() string
+	Send(ctx context.Context, msg *SMSMessage) (string, error) // Returns the provider's message ID
+}
+
+// NewSMSProvider returns the SMS provider selected in the configuration, or nil when SMS
+// delivery is disabled
+func NewSMSProvider(cfg *config.Config) (SMSProvider, error) {
+	switch cfg.SMS.Provider {
+	case "":
+		return nil, nil
+	case "http":
+		if _, err := url.ParseRequestURI(cfg.SMS.APIURL); err != nil {
+			return nil, fmt.Errorf("invalid SMS_API_URL: %w", err)
+		}
+		return &httpSMSProvider{
+			baseURL:    strings.TrimRight(cfg.SMS.APIURL, "/"),
+			apiKey:     cfg.SMS.APIKey,
+			sender:     cfg.SMS.Sender,
+			httpClient: &http.Client{Timeout: cfg.SMS.Timeout},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported SMS provider: %s", cfg.SMS.Provider)
+	}
+}
+
+// httpSMSProvider talks to an SMS gateway's JSON API:
+//
+//	POST /messages    {"from", "to", "body"} -> {"message_id"}
+type httpSMSProvider struct {
+	baseURL    string
+	apiKey     string
+	sender     string
+	httpClient *http.Client
+}
+
+func (p *httpSMSProvider) Name() string {
+	return "http"
+}
+
+// Send sends a text message and returns the gateway's message ID
+func (p *httpSMSProvider) Send(ctx context.Context, msg *SMSMessage) (string, error) {
+	body, err := json.Marshal(map[string]string{
+		"from": p.sender,
+		"to":   msg.To,
+		"body": msg.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build SMS request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	if msg.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", msg.IdempotencyKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("SMS request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read SMS response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return "", fmt.Errorf("SMS provider responded with status %d: %s", resp.StatusCode, apiErr.Message)
+	}
+
+	var out struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("failed to decode SMS response: %w", err)
+	}
+	if out.MessageID == "" {
+		return "", errors.New("SMS provider returned no message ID")
+	}
+	return out.MessageID, nil
+}
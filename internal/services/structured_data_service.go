@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"gorm.io/gorm"
+)
+
+// ErrStructuredDataEventNotFound is returned when describing an event that does not exist
+var ErrStructuredDataEventNotFound = errors.New("Event not found")
+
+// StructuredDataService describes events as schema.org structured data for search engines. It is
+// built from the event on every request, so it always reflects the event's current details,
+// status and availability.
+type StructuredDataService struct {
+	db        *gorm.DB
+	publicURL string
+}
+
+// NewStructuredDataService creates a new structured data service
+func NewStructuredDataService(cfg *config.Config) *StructuredDataService {
+	return &StructuredDataService{
+		db:        database.DB,
+		publicURL: strings.TrimRight(cfg.Security.PublicURL, "/"),
+	}
+}
+
+// EventJSONLD returns an event as schema.org Event structured data
+func (s *StructuredDataService) EventJSONLD(ctx context.Context, eventID uint) (*models.EventJSONLD, error) {
+	var event models.Event
+	if err := s.db.WithContext(ctx).Where("status <> ?", models.EventStatusDraft).First(&event, eventID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStructuredDataEventNotFound
+		}
+		return nil, err
+	}
+
+	data := &models.EventJSONLD{
+		Context:             models.SchemaOrgContext,
+		Type:                "Event",
+		Name:                event.Title,
+		Description:         event.Description,
+		StartDate:           event.StartDate.Format(time.RFC3339),
+		EndDate:             event.EndDate.Format(time.RFC3339),
+		EventStatus:         models.SchemaEventScheduled,
+		EventAttendanceMode: models.SchemaOfflineAttendanceMode,
+		Offers: models.JSONLDOffer{
+			Type:          "Offer",
+			Price:         formatAmount(event.Price),
+			PriceCurrency: models.DefaultCurrency,
+			Availability:  models.SchemaInStock,
+			ValidFrom:     event.CreatedAt.Format(time.RFC3339),
+		},
+	}
+	if event.Status == models.EventStatusCancelled {
+		data.EventStatus = models.SchemaEventCancelled
+	}
+	if event.Available <= 0 {
+		data.Offers.Availability = models.SchemaSoldOut
+	}
+	if event.Location != "" {
+		data.Location = &models.JSONLDPlace{Type: "Place", Name: event.Location, Address: event.Location}
+	}
+	if event.CoverURL != "" {
+		// Served through the image proxy rather than hotlinking the organizer's site
+		data.Image = []string{fmt.Sprintf("%s/api/v1/images/events/%d/cover", s.publicURL, event.ID)}
+	}
+	if event.Performer != "" {
+		data.Performer = &models.JSONLDPerformer{Type: "PerformingGroup", Name: event.Performer}
+	}
+	return data, nil
+}
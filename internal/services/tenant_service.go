@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrTenantNotFound is returned when a tenant does not exist
+var ErrTenantNotFound = errors.New("Tenant not found")
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context carrying the tenant a request resolved to
+func WithTenant(ctx context.Context, tenant *models.Tenant) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant a request resolved to. Requests matching no tenant carry
+// the default brand built from the deployment's configuration.
+func TenantFromContext(ctx context.Context) (*models.Tenant, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(*models.Tenant)
+	return tenant, ok && tenant != nil
+}
+
+// Slugs and domains tenants can have, as accepted when creating them. Other values sent in headers
+// cannot match a tenant and are not looked up.
+var (
+	tenantSlugPattern   = regexp.MustCompile(`^[a-z0-9]{1,50}$`)
+	tenantDomainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+)
+
+// cachedTenant is a resolved tenant and when it expires
+type cachedTenant struct {
+	tenant    *models.Tenant
+	expiresAt time.Time
+}
+
+// TenantService manages white-label tenants and resolves the tenant of each request. Resolved
+// tenants are cached in memory for the configured TTL, so other instances pick up changes within
+// that time. Misses are not cached, so headers of arbitrary requests cannot grow the cache beyond
+// one entry per tenant slug and domain.
+type TenantService struct {
+	db            *gorm.DB
+	cacheTTL      time.Duration
+	defaultTenant *models.Tenant
+
+	mu    sync.Mutex
+	cache map[string]cachedTenant
+}
+
+// NewTenantService creates a new tenant service
+func NewTenantService(cfg *config.Config) *TenantService {
+	senderDomain := ""
+	if at := strings.LastIndex(cfg.SMTP.FromEmail, "@"); at >= 0 {
+		senderDomain = cfg.SMTP.FromEmail[at+1:]
+	}
+	return &TenantService{
+		db:       database.DB,
+		cacheTTL: cfg.Tenant.CacheTTL,
+		defaultTenant: &models.Tenant{
+			AppName:      cfg.App.Name,
+			SupportEmail: cfg.Tenant.SupportEmail,
+			SenderDomain: senderDomain,
+			Active:       true,
+		},
+		cache: make(map[string]cachedTenant),
+	}
+}
+
+// Resolve returns the active tenant for a request, matched by slug when given and otherwise by the
+// host it was sent to. Requests matching no tenant get the default brand. Lookup errors are logged
+// and also fall back to the default, so branding never takes a request down.
+func (s *TenantService) Resolve(ctx context.Context, host, slug string) *models.Tenant {
+	column, value := "domain", normalizeHost(host)
+	valid := len(value) <= 255 && tenantDomainPattern.MatchString(value)
+	if slug = strings.ToLower(strings.TrimSpace(slug)); slug != "" {
+		column, value = "slug", slug
+		valid = tenantSlugPattern.MatchString(value)
+	}
+	if !valid {
+		return s.defaultTenant
+	}
+
+	key := column + ":" + value
+	s.mu.Lock()
+	entry, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.tenant
+	}
+
+	var tenant models.Tenant
+	err := s.db.WithContext(ctx).Where(column+" = ? AND active = ?", value, true).First(&tenant).Error
+	switch {
+	case err == nil:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if ok {
+			// The tenant was deactivated or renamed since it was cached
+			s.mu.Lock()
+			delete(s.cache, key)
+			s.mu.Unlock()
+		}
+		return s.defaultTenant
+	default:
+		log.Printf("Failed to resolve tenant %s: %v", key, err)
+		return s.defaultTenant
+	}
+
+	s.mu.Lock()
+	s.cache[key] = cachedTenant{tenant: &tenant, expiresAt: time.Now().Add(s.cacheTTL)}
+	s.mu.Unlock()
+	return &tenant
+}
+
+// CreateTenant creates a tenant
+func (s *TenantService) CreateTenant(ctx context.Context, req *models.CreateTenantRequest) (*models.TenantResponse, error) {
+	tenant := models.Tenant{
+		Slug:         strings.ToLower(req.Slug),
+		Domain:       normalizeHost(req.Domain),
+		AppName:      req.AppName,
+		SupportEmail: req.SupportEmail,
+		SenderDomain: strings.ToLower(req.SenderDomain),
+		Colors:       req.Colors,
+		Active:       true,
+	}
+	if err := s.checkUnique(ctx, &tenant); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Create(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	s.invalidate()
+
+	resp := tenant.ToResponse()
+	return &resp, nil
+}
+
+// ListTenants returns all tenants
+func (s *TenantService) ListTenants(ctx context.Context) ([]models.TenantResponse, error) {
+	var tenants []models.Tenant
+	if err := s.db.WithContext(ctx).Order("slug ASC").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+
+	responses := make([]models.TenantResponse, len(tenants))
+	for i, tenant := range tenants {
+		responses[i] = tenant.ToResponse()
+	}
+	return responses, nil
+}
+
+// UpdateTenant updates a tenant's settings
+func (s *TenantService) UpdateTenant(ctx context.Context, tenantID uuid.UUID, req *models.UpdateTenantRequest) (*models.TenantResponse, error) {
+	db := s.db.WithContext(ctx)
+
+	var tenant models.Tenant
+	if err := db.First(&tenant, "id = ?", tenantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTenantNotFound
+		}
+		return nil, err
+	}
+
+	if req.Domain != "" {
+		tenant.Domain = normalizeHost(req.Domain)
+	}
+	if req.AppName != "" {
+		tenant.AppName = req.AppName
+	}
+	if req.SupportEmail != "" {
+		tenant.SupportEmail = req.SupportEmail
+	}
+	if req.SenderDomain != "" {
+		tenant.SenderDomain = strings.ToLower(req.SenderDomain)
+	}
+	if req.Colors != nil {
+		tenant.Colors = *req.Colors
+	}
+	if req.Active != nil {
+		tenant.Active = *req.Active
+	}
+	if err := s.checkUnique(ctx, &tenant); err != nil {
+		return nil, err
+	}
+
+	if err := db.Save(&tenant).Error; err != nil {
+		return nil, fmt.Errorf("failed to update tenant: %w", err)
+	}
+	s.invalidate()
+
+	resp := tenant.ToResponse()
+	return &resp, nil
+}
+
+// DeleteTenant deletes a tenant; its frontend falls back to the default brand
+func (s *TenantService) DeleteTenant(ctx context.Context, tenantID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&models.Tenant{}, "id = ?", tenantID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTenantNotFound
+	}
+	s.invalidate()
+	return nil
+}
+
+// checkUnique rejects a slug or domain already used by another tenant
+func (s *TenantService) checkUnique(ctx context.Context, tenant *models.Tenant) error {
+	query := s.db.WithContext(ctx).Model(&models.Tenant{}).Where("id <> ?", tenant.ID)
+	if tenant.Domain != "" {
+		query = query.Where("slug = ? OR domain = ?", tenant.Slug, tenant.Domain)
+	} else {
+		query = query.Where("slug = ?", tenant.Slug)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("Another tenant already uses this slug or domain")
+	}
+	return nil
+}
+
+// invalidate forgets this instance's resolved tenants after a change
+func (s *TenantService) invalidate() {
+	s.mu.Lock()
+	s.cache = make(map[string]cachedTenant)
+	s.mu.Unlock()
+}
+
+// normalizeHost lowercases a host name and strips its port
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"fmt"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/utils"
+
+	"gorm.io/gorm"
+)
+
+// TicketPDFContentType is the content type of rendered tickets
+const TicketPDFContentType = "application/pdf"
+
+// TicketPDFFilename returns the file name a ticket is attached and downloaded as
+func TicketPDFFilename(ticket *models.Ticket) string {
+	return fmt.Sprintf("ticket-%s.pdf", ticket.ID.String()[:8])
+}
+
+// renderTicketPDF renders a printable A4 ticket with the event details, the attendee, the order
+// number and the QR code door scanners check in
+func renderTicketPDF(ticket *models.Ticket, event *models.Event, ticketType, qrPayload string) ([]byte, error) {
+	code, err := utils.EncodeQR(qrPayload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ticket QR code: %w", err)
+	}
+
+	const margin = 48.0
+	pdf := utils.NewPDF(utils.PDFA4Width, utils.PDFA4Height)
+	pdf.AddPage()
+
+	// Header band with the event title
+	pdf.SetColor(17, 24, 39)
+	pdf.Rect(0, 0, utils.PDFA4Width, 150)
+	pdf.SetColor(255, 255, 255)
+	pdf.Text(margin, 52, 10, true, "ADMIT ONE")
+	title := utils.WrapPDFText(event.Title, 22, utils.PDFA4Width-2*margin)
+	if len(title) > 2 {
+		title = append(title[:1], title[1]+"...")
+	}
+	for i, line := range title {
+		pdf.Text(margin, 88+float64(i)*28, 22, true, line)
+	}
+
+	// Details on the left
+	attendee := ticket.AttendeeName
+	if attendee == "" {
+		attendee = ticket.AttendeeEmail
+	}
+	details := []struct{ label, value string }{
+		{"DATE", event.StartDate.Format("Monday, January 2, 2006")},
+		{"TIME", event.StartDate.Format("3:04 PM")},
+		{"VENUE", event.Location},
+		{"ATTENDEE", attendee},
+		{"TICKET TYPE", ticketType},
+		{"ORDER NUMBER", ticket.OrderID.String()},
+		{"TICKET ID", ticket.ID.String()},
+	}
+	const detailsWidth = 270.0
+	y := 200.0
+	for _, detail := range details {
+		if detail.value == "" {
+			continue
+		}
+		pdf.SetColor(107, 114, 128)
+		pdf.Text(margin, y, 9, true, detail.label)
+		pdf.SetColor(17, 24, 39)
+		lines := utils.WrapPDFText(detail.value, 13, detailsWidth)
+		if len(lines) > 3 {
+			lines = append(lines[:2], lines[2]+"...")
+		}
+		for i, line := range lines {
+			pdf.Text(margin, y+18+float64(i)*16, 13, false, line)
+		}
+		y += 18 + float64(len(lines))*16 + 18
+	}
+
+	// QR code on the right, with its quiet zone
+	const qrWidth = 200.0
+	qrX := utils.PDFA4Width - margin - qrWidth
+	moduleSize := qrWidth / float64(code.Size+8)
+	pdf.SetColor(0, 0, 0)
+	pdf.QRCode(qrX+4*moduleSize, 180+4*moduleSize, moduleSize, code)
+	pdf.SetColor(107, 114, 128)
+	pdf.Text(qrX+52, 180+qrWidth+16, 10, false, "Scan at the entrance")
+
+	// Footer
+	pdf.SetColor(229, 231, 235)
+	pdf.Rect(margin, utils.PDFA4Height-90, utils.PDFA4Width-2*margin, 1)
+	pdf.SetColor(107, 114, 128)
+	pdf.Text(margin, utils.PDFA4Height-70, 9, false, "This ticket admits one person. The QR code can be used once; do not share it.")
+	pdf.Text(margin, utils.PDFA4Height-56, 9, false, "Bring a valid ID matching the attendee name.")
+
+	return pdf.Bytes()
+}
+
+// ticketTypeLabel returns the ticket type printed on a ticket: complimentary tickets name their
+// allocation, others are general admission
+func ticketTypeLabel(db *gorm.DB, ticket *models.Ticket) string {
+	if ticket.AllocationID != nil {
+		var allocation models.TicketAllocation
+		err := db.Select("type", "label").First(&allocation, "id = ?", *ticket.AllocationID).Error
+		if err == nil && allocation.Type == models.AllocationTypeComp {
+			return "Complimentary - " + allocation.Label
+		}
+	}
+	return "General Admission"
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// AuditTicketRenamed is the audit action of an attendee correcting the name on their ticket
+const AuditTicketRenamed = "ticket.attendee_renamed"
+
+// ErrTicketNotFound is returned for ticket links that are invalid or whose ticket no longer exists
+var ErrTicketNotFound = errors.New("Ticket not found")
+
+// TicketPortalService serves the no-login ticket pages linked from ticket emails, so attendees
+// without an account, such as guests a buyer ordered for, can manage their own ticket
+type TicketPortalService struct {
+	db                *gorm.DB
+	redisClient       redislib.UniversalClient
+	emailQueueService *EmailQueueService
+	cfg               config.PortalConfig
+}
+
+// NewTicketPortalService creates a new ticket portal service
+func NewTicketPortalService(cfg *config.Config) *TicketPortalService {
+	return &TicketPortalService{
+		db:                database.DB,
+		redisClient:       redis.RateLimit,
+		emailQueueService: NewEmailQueueService(cfg),
+		cfg:               cfg.Portal,
+	}
+}
+
+// GetTicket returns the ticket a link grants access to
+func (s *TicketPortalService) GetTicket(ctx context.Context, token string) (*models.TicketPortalResponse, error) {
+	ticket, err := s.find(s.db.WithContext(ctx), token)
+	if err != nil {
+		return nil, err
+	}
+	return s.response(ticket, time.Now()), nil
+}
+
+// UpdateAttendeeName corrects the attendee name of a ticket. Names can be changed a limited
+// number of times, until shortly before the event, and only on tickets not yet used or cancelled.
+func (s *TicketPortalService) UpdateAttendeeName(ctx context.Context, token string, req *models.TicketAttendeeUpdateRequest) (*models.TicketPortalResponse, error) {
+	name := strings.TrimSpace(req.AttendeeName)
+	if name == "" {
+		return nil, errors.New("Attendee name is required")
+	}
+
+	var ticket *models.Ticket
+	var renamed bool
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ticket, err = s.find(tx, token); err != nil {
+			return err
+		}
+		if err := s.checkNameChange(ticket, time.Now()); err != nil {
+			return err
+		}
+		if ticket.AttendeeName == name {
+			return nil
+		}
+
+		// The change count is re-checked so concurrent requests cannot exceed the limit
+		previousName := ticket.AttendeeName
+		result := tx.Model(ticket).
+			Where("status = ? AND name_changes < ?", models.TicketStatusValid, s.cfg.MaxNameChanges).
+			Updates(map[string]interface{}{"attendee_name": name, "name_changes": gorm.Expr("name_changes + 1")})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("Attendee name can no longer be changed")
+		}
+		ticket.AttendeeName = name
+		ticket.NameChanges++
+		renamed = true
+
+		return writeAuditLog(tx, nil, AuditTicketRenamed, "ticket", ticket.ID.String(), ticket.OrganizationID, map[string]interface{}{
+			"from": previousName,
+			"to":   name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if renamed {
+		log.Printf("Ticket attendee renamed: Ticket=%s", ticket.ID)
+	}
+	return s.response(ticket, time.Now()), nil
+}
+
+// ResendEmail sends the ticket email again to the attendee's address. Resends of a ticket are
+// limited to one per cooldown; without Redis they are not limited.
+func (s *TicketPortalService) ResendEmail(ctx context.Context, token string) error {
+	ticket, err := s.find(s.db.WithContext(ctx), token)
+	if err != nil {
+		return err
+	}
+	if ticket.Status == models.TicketStatusCancelled {
+		return errors.New("Cancelled tickets cannot be resent")
+	}
+
+	if s.redisClient != nil && s.cfg.ResendCooldown > 0 {
+		claimed, err := s.redisClient.SetNX(ctx, ticketResendKey(ticket.ID), 1, s.cfg.ResendCooldown).Result()
+		if err != nil {
+			log.Printf("Ticket resend throttle unavailable: %v", err)
+		} else if !claimed {
+			return utils.NewRateLimitError("Ticket email was sent recently, please check your inbox")
+		}
+	}
+
+	if err := s.emailQueueService.QueueTicketEmail(ticket, ticket.Event, "General Admission"); err != nil {
+		return fmt.Errorf("failed to queue ticket email: %w", err)
+	}
+	return nil
+}
+
+// Calendar returns an iCalendar file with the ticket's event, for adding it to a calendar app
+func (s *TicketPortalService) Calendar(ctx context.Context, token string) ([]byte, error) {
+	ticket, err := s.find(s.db.WithContext(ctx), token)
+	if err != nil {
+		return nil, err
+	}
+
+	return ticketICS(ticket, ticket.Event, s.emailQueueService.TicketManageURL(ticket.ID)), nil
+}
+
+// find loads the ticket a link grants access to, with its event
+func (s *TicketPortalService) find(db *gorm.DB, token string) (*models.Ticket, error) {
+	ticketID, err := utils.ParseTicketToken(s.cfg.Secret, token)
+	if err != nil {
+		return nil, ErrTicketNotFound
+	}
+
+	var ticket models.Ticket
+	if err := db.Preload("Event").First(&ticket, "id = ?", ticketID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	if ticket.Event == nil {
+		return nil, ErrTicketNotFound
+	}
+	return &ticket, nil
+}
+
+// checkNameChange returns why the ticket's attendee name cannot be changed now, if it cannot
+func (s *TicketPortalService) checkNameChange(ticket *models.Ticket, now time.Time) error {
+	switch {
+	case ticket.Status != models.TicketStatusValid:
+		return fmt.Errorf("Attendee name cannot be changed on a %s ticket", strings.ReplaceAll(string(ticket.Status), "_", " "))
+	case ticket.NameChanges >= s.cfg.MaxNameChanges:
+		return errors.New("Attendee name has already been changed the maximum number of times")
+	case !now.Before(s.nameChangeDeadline(ticket)):
+		return errors.New("Attendee name can no longer be changed this close to the event")
+	}
+	return nil
+}
+
+func (s *TicketPortalService) nameChangeDeadline(ticket *models.Ticket) time.Time {
+	return ticket.Event.StartDate.Add(-s.cfg.NameChangeCutoff)
+}
+
+// response converts a ticket with its event to what the ticket page shows
+func (s *TicketPortalService) response(ticket *models.Ticket, now time.Time) *models.TicketPortalResponse {
+	return &models.TicketPortalResponse{
+		TicketID:           ticket.ID,
+		Status:             ticket.Status,
+		AttendeeName:       ticket.AttendeeName,
+		AttendeeEmail:      ticket.AttendeeEmail,
+		EventID:            ticket.EventID,
+		EventTitle:         ticket.Event.Title,
+		EventLocation:      ticket.Event.Location,
+		StartDate:          ticket.Event.StartDate,
+		EndDate:            ticket.Event.EndDate,
+		CheckedInAt:        ticket.CheckedInAt,
+		RevokedAt:          ticket.RevokedAt,
+		QRCode:             s.emailQueueService.TicketQRCode(ticket.ID),
+		NameChangeAllowed:  s.checkNameChange(ticket, now) == nil,
+		NameChangesLeft:    max(s.cfg.MaxNameChanges-ticket.NameChanges, 0),
+		NameChangeDeadline: s.nameChangeDeadline(ticket),
+	}
+}
+
+func ticketResendKey(ticketID uuid.UUID) string {
+	return "ticket:resend:" + ticketID.String()
+}
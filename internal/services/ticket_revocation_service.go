@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revokedTicketsKey is the Redis set of revoked ticket IDs scanners check before the database
+const revokedTicketsKey = "tickets:revoked"
+
+// revocationReasonLabels are the reasons for revoking a ticket as told to its holder
+var revocationReasonLabels = map[models.TicketRevocationReason]string{
+	models.TicketRevocationChargeback:      "The payment for this ticket was disputed",
+	models.TicketRevocationFraud:           "This ticket was flagged as fraudulent",
+	models.TicketRevocationPolicyViolation: "The event's ticket terms were not respected",
+	models.TicketRevocationOther:           "Revoked by the event organizer",
+}
+
+// TicketRevocationService revokes tickets an organization no longer honours, e.g. after a
+// chargeback, so their QR codes are refused at the door, and reinstates them
+type TicketRevocationService struct {
+	db                *gorm.DB
+	redisClient       redislib.UniversalClient
+	emailQueueService *EmailQueueService
+}
+
+// NewTicketRevocationService creates a new ticket revocation service
+func NewTicketRevocationService(cfg *config.Config) *TicketRevocationService {
+	return &TicketRevocationService{
+		db:                database.DB,
+		redisClient:       redis.Client,
+		emailQueueService: NewEmailQueueService(cfg),
+	}
+}
+
+// Revoke revokes a ticket of an organization. The ticket is added to the revocation set scanners
+// check, and its holder is emailed unless the request is silent.
+func (s *TicketRevocationService) Revoke(ctx context.Context, orgID, ticketID, actorID uuid.UUID, req *models.TicketRevokeRequest) (*models.TicketRevocation, error) {
+	var ticket *models.Ticket
+	var revocation *models.TicketRevocation
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		ticket, err = s.lockTicket(tx, orgID, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.RevokedAt != nil {
+			return utils.NewConflictError("Ticket is already revoked")
+		}
+		if ticket.Status == models.TicketStatusCancelled {
+			return utils.NewConflictError("Cancelled tickets cannot be revoked")
+		}
+
+		now := time.Now()
+		if err := tx.Model(ticket).Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke ticket: %w", err)
+		}
+		revocation = &models.TicketRevocation{
+			TicketID:       ticket.ID,
+			OrganizationID: orgID,
+			EventID:        ticket.EventID,
+			Reason:         req.Reason,
+			Note:           req.Note,
+			RevokedBy:      actorID,
+			RevokedAt:      now,
+		}
+		if err := tx.Create(revocation).Error; err != nil {
+			return fmt.Errorf("failed to record ticket revocation: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "ticket.revoke", "ticket", ticket.ID.String(), &orgID, map[string]interface{}{
+			"reason": req.Reason,
+			"note":   req.Note,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The database refuses the ticket even when Redis misses it, so a failure only costs a query
+	if s.redisClient != nil {
+		if err := s.redisClient.SAdd(ctx, revokedTicketsKey, ticket.ID.String()).Err(); err != nil {
+			log.Printf("Failed to add ticket to revocation set: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+	}
+
+	if !req.Silent && ticket.AttendeeEmail != "" && ticket.Event != nil {
+		if err := s.emailQueueService.QueueTicketRevokedEmail(ticket, ticket.Event, revocationReasonLabels[req.Reason]); err != nil {
+			log.Printf("Failed to queue ticket revoked email: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+	}
+
+	log.Printf("Ticket revoked: Ticket=%s, Reason=%s, By=%s", ticket.ID, req.Reason, actorID)
+	revocation.Ticket = ticket
+	return revocation, nil
+}
+
+// Reinstate lifts the revocation of a ticket of an organization so it admits its holder again,
+// and emails the holder unless the request is silent
+func (s *TicketRevocationService) Reinstate(ctx context.Context, orgID, ticketID, actorID uuid.UUID, req *models.TicketReinstateRequest) (*models.TicketRevocation, error) {
+	var ticket *models.Ticket
+	var revocation models.TicketRevocation
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		ticket, err = s.lockTicket(tx, orgID, ticketID)
+		if err != nil {
+			return err
+		}
+		if ticket.RevokedAt == nil {
+			return utils.NewConflictError("Ticket is not revoked")
+		}
+
+		now := time.Now()
+		if err := tx.Model(ticket).Update("revoked_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to reinstate ticket: %w", err)
+		}
+		err = tx.Where("ticket_id = ? AND reinstated_at IS NULL", ticket.ID).Order("revoked_at DESC").First(&revocation).Error
+		if err != nil {
+			return fmt.Errorf("failed to load ticket revocation: %w", err)
+		}
+		revocation.ReinstatedBy = &actorID
+		revocation.ReinstatedAt = &now
+		revocation.ReinstateNote = req.Note
+		if err := tx.Model(&revocation).Select("reinstated_by", "reinstated_at", "reinstate_note").Updates(&revocation).Error; err != nil {
+			return fmt.Errorf("failed to record ticket reinstatement: %w", err)
+		}
+
+		recordAuditLog(tx, &actorID, "ticket.reinstate", "ticket", ticket.ID.String(), &orgID, map[string]interface{}{
+			"note": req.Note,
+		})
+
+		// Scanners would keep refusing a ticket left in the revocation set, so give up when it
+		// cannot be removed
+		if s.redisClient != nil {
+			if err := s.redisClient.SRem(ctx, revokedTicketsKey, ticket.ID.String()).Err(); err != nil {
+				return fmt.Errorf("failed to remove ticket from revocation set: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.Silent && ticket.AttendeeEmail != "" && ticket.Event != nil {
+		message := fmt.Sprintf("Good news: your ticket %s for %s has been reinstated and is valid for entry again. You can use the QR code you already have.", ticket.ID, ticket.Event.Title)
+		if err := s.emailQueueService.QueueNotificationEmail(ticket.AttendeeEmail, ticket.AttendeeName, "Your ticket has been reinstated", message); err != nil {
+			log.Printf("Failed to queue ticket reinstated email: Ticket=%s, Error=%v", ticket.ID, err)
+		}
+	}
+
+	log.Printf("Ticket reinstated: Ticket=%s, By=%s", ticket.ID, actorID)
+	ticket.RevokedAt = nil
+	revocation.Ticket = ticket
+	return &revocation, nil
+}
+
+// List returns the requested page of an organization's ticket revocations with their tickets,
+// most recent first
+func (s *TicketRevocationService) List(ctx context.Context, orgID uuid.UUID, query *models.TicketRevocationQuery) ([]models.TicketRevocation, *models.PageMeta, error) {
+	search := s.db.WithContext(ctx).Model(&models.TicketRevocation{}).Where("organization_id = ?", orgID)
+	if query.EventID != 0 {
+		search = search.Where("event_id = ?", query.EventID)
+	}
+	if query.Reason != "" {
+		search = search.Where("reason = ?", query.Reason)
+	}
+	if query.Active != nil {
+		if *query.Active {
+			search = search.Where("reinstated_at IS NULL")
+		} else {
+			search = search.Where("reinstated_at IS NOT NULL")
+		}
+	}
+
+	revocations := []models.TicketRevocation{}
+	meta, err := database.Paginate(search.Preload("Ticket").Order("revoked_at DESC").Order("id ASC"), query.PageQuery, &revocations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return revocations, meta, nil
+}
+
+// lockTicket loads and locks a ticket of an organization with its event
+func (s *TicketRevocationService) lockTicket(tx *gorm.DB, orgID, ticketID uuid.UUID) (*models.Ticket, error) {
+	var ticket models.Ticket
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND organization_id = ?", ticketID, orgID).
+		First(&ticket).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+
+	var event models.Event
+	if err := tx.First(&event, ticket.EventID).Error; err != nil {
+		return nil, fmt.Errorf("failed to load event: %w", err)
+	}
+	ticket.Event = &event
+	return &ticket, nil
+}
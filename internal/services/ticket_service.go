@@ -0,0 +1,430 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// checkInSQL looks up the scanned tickets, checks in the valid ones for the event and records
+// their check-ins in a single round trip. The update re-checks the status, so two scanners racing
+// on the same ticket admit it once; the loser sees it as already checked in. Raw SQL bypasses the
+// tenancy guard, hence the explicit organization condition. Revoked tickets are never admitted,
+// whatever the revocation set in Redis says.
+const checkInSQL = `
+WITH scanned AS (
+	SELECT id, event_id, status, attendee_name, revoked_at IS NOT NULL AS revoked FROM tickets
+	WHERE id IN @ids AND organization_id = @org
+), admitted AS (
+	UPDATE tickets SET status = @checked_in, checked_in_at = @now, check_in_gate = @gate, updated_at = @now
+	WHERE id IN (SELECT id FROM scanned WHERE event_id = @event AND status = @valid AND NOT revoked)
+		AND status = @valid AND revoked_at IS NULL
+	RETURNING id, event_id, organization_id
+), recorded AS (
+	INSERT INTO check_ins (id, ticket_id, event_id, organization_id, gate, checked_in_by, checked_in_at, created_at)
+	SELECT uuid_generate_v4(), id, event_id, organization_id, @gate, @by, @now, @now FROM admitted
+)
+SELECT scanned.id, scanned.event_id, scanned.status, scanned.attendee_name, scanned.revoked, admitted.id IS NOT NULL AS admitted
+FROM scanned LEFT JOIN admitted ON admitted.id = scanned.id`
+
+// scannedTicket is a row of checkInSQL
+type scannedTicket struct {
+	ID           uuid.UUID
+	EventID      uint
+	Status       models.TicketStatus
+	AttendeeName string
+	Revoked      bool
+	Admitted     bool
+}
+
+// TicketService validates and checks in tickets at the door
+type TicketService struct {
+	db                 *gorm.DB
+	redisClient        redislib.UniversalClient
+	cfg                config.ScanConfig
+	statsService       *CheckInStatsService
+	integrationService *IntegrationService
+}
+
+// NewTicketService creates a new ticket service
+func NewTicketService(cfg *config.Config, statsService *CheckInStatsService) *TicketService {
+	return &TicketService{
+		db:                 database.DB,
+		redisClient:        redis.Client,
+		cfg:                cfg.Scan,
+		statsService:       statsService,
+		integrationService: NewIntegrationService(cfg),
+	}
+}
+
+// ValidateBatch checks in a burst of scanned codes for an event, such as a turnstile queue, and
+// returns a result per code in request order. A code scanned again within the duplicate window is
+// reported as a duplicate without touching the database. Every scan is added to the audit trail.
+func (s *TicketService) ValidateBatch(ctx context.Context, orgID uuid.UUID, userID uuid.UUID, device models.ScanDevice, req *models.TicketValidationBatchRequest) (*models.TicketValidationBatchResponse, error) {
+	if len(req.Codes) > s.cfg.MaxBatchSize {
+		return nil, utils.NewValidationError(fmt.Sprintf("At most %d codes can be validated at once", s.cfg.MaxBatchSize), nil)
+	}
+
+	results := make([]models.TicketValidationResult, len(req.Codes))
+	pending := make(map[uuid.UUID]int) // Ticket ID to the index of its first scan in the batch
+	var ids []uuid.UUID
+	for i, code := range req.Codes {
+		results[i].Code = code
+		id, err := s.parseCode(code)
+		if err != nil {
+			results[i].Result = models.TicketScanInvalidCode
+			continue
+		}
+		results[i].TicketID = &id
+		if _, seen := pending[id]; seen {
+			results[i].Result = models.TicketScanDuplicate
+			continue
+		}
+		pending[id] = i
+		ids = append(ids, id)
+	}
+
+	var admitted []uuid.UUID
+	ids = s.rejectRevoked(ctx, ids, results, pending)
+	ids = s.claimScans(ctx, ids, results, pending)
+	if len(ids) > 0 {
+		var rows []scannedTicket
+		err := database.Conn(ctx, s.db).Raw(checkInSQL, map[string]interface{}{
+			"ids":        ids,
+			"org":        orgID,
+			"event":      req.EventID,
+			"valid":      models.TicketStatusValid,
+			"checked_in": models.TicketStatusCheckedIn,
+			"gate":       strings.TrimSpace(req.Gate),
+			"by":         userID,
+			"now":        time.Now(),
+		}).Scan(&rows).Error
+		if err != nil {
+			// Let the scanner retry the codes instead of reporting them as duplicates
+			s.releaseScans(ids)
+			return nil, fmt.Errorf("failed to check in tickets: %w", err)
+		}
+
+		found := make(map[uuid.UUID]scannedTicket, len(rows))
+		for _, row := range rows {
+			found[row.ID] = row
+		}
+		for _, id := range ids {
+			result := &results[pending[id]]
+			row, ok := found[id]
+			if !ok {
+				result.Result = models.TicketScanNotFound
+				continue
+			}
+			result.AttendeeName = row.AttendeeName
+			result.Result = scanResult(row, req.EventID)
+			if result.Result == models.TicketScanAdmitted {
+				admitted = append(admitted, id)
+			}
+		}
+	}
+
+	response := &models.TicketValidationBatchResponse{Results: results}
+	scannedAt := time.Now()
+	attempts := make([]models.ScanAttempt, len(results))
+	for i, result := range results {
+		if result.Result == models.TicketScanAdmitted {
+			response.Admitted++
+		}
+		attempts[i] = scanAttempt(&orgID, req.EventID, req.Gate, userID, device, &results[i], scannedAt)
+	}
+	recordScanAttempts(database.Conn(ctx, s.db), attempts)
+	s.statsService.Record(ctx, orgID, req.EventID, req.Gate, response.Admitted)
+	s.integrationService.DispatchCheckIns(orgID, admitted)
+	return response, nil
+}
+
+// Validate checks in the ticket of one scanned QR code for an event. Only signed payloads are
+// accepted, and only from staff who may scan for the ticket's organization: its organizer, its
+// members and admins. Codes of tickets the scanner may not see are reported as not found. The scan
+// is added to the audit trail, under the ticket's organization when the scanner may see it.
+func (s *TicketService) Validate(ctx context.Context, userID uuid.UUID, roles []string, device models.ScanDevice, req *models.TicketValidationRequest) (*models.TicketValidationResult, error) {
+	result, orgID, err := s.validate(ctx, userID, roles, req)
+	if err != nil {
+		return nil, err
+	}
+
+	attempt := scanAttempt(orgID, req.EventID, req.Gate, userID, device, result, time.Now())
+	recordScanAttempts(database.Conn(ctx, s.db), []models.ScanAttempt{attempt})
+	return result, nil
+}
+
+// validate checks in the ticket of one scanned QR code and returns its organization, or nil when
+// the code is not a ticket the scanner may see
+func (s *TicketService) validate(ctx context.Context, userID uuid.UUID, roles []string, req *models.TicketValidationRequest) (*models.TicketValidationResult, *uuid.UUID, error) {
+	result := &models.TicketValidationResult{Code: req.Code}
+	id, err := utils.ParseTicketQR(s.cfg.QRSecret, req.Code)
+	if err != nil {
+		result.Result = models.TicketScanInvalidCode
+		return result, nil, nil
+	}
+	result.TicketID = &id
+
+	db := database.Conn(ctx, s.db)
+	var ticket models.Ticket
+	if err := db.Select("id", "organization_id").First(&ticket, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Result = models.TicketScanNotFound
+			return result, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to look up ticket: %w", err)
+	}
+	if ticket.OrganizationID == nil {
+		result.Result = models.TicketScanNotFound
+		return result, nil, nil
+	}
+	orgID := *ticket.OrganizationID
+	allowed, err := s.canScan(db, userID, roles, orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !allowed {
+		result.Result = models.TicketScanNotFound
+		return result, nil, nil
+	}
+
+	// Revoked tickets and rescans within the duplicate window are reported without touching the database
+	pending := map[uuid.UUID]int{id: 0}
+	results := []models.TicketValidationResult{*result}
+	if len(s.rejectRevoked(ctx, []uuid.UUID{id}, results, pending)) == 0 {
+		return &results[0], &orgID, nil
+	}
+	if len(s.claimScans(ctx, []uuid.UUID{id}, results, pending)) == 0 {
+		return &results[0], &orgID, nil
+	}
+
+	var rows []scannedTicket
+	err = db.Raw(checkInSQL, map[string]interface{}{
+		"ids":        []uuid.UUID{id},
+		"org":        orgID,
+		"event":      req.EventID,
+		"valid":      models.TicketStatusValid,
+		"checked_in": models.TicketStatusCheckedIn,
+		"gate":       strings.TrimSpace(req.Gate),
+		"by":         userID,
+		"now":        time.Now(),
+	}).Scan(&rows).Error
+	if err != nil {
+		s.releaseScans([]uuid.UUID{id})
+		return nil, nil, fmt.Errorf("failed to check in ticket: %w", err)
+	}
+	if len(rows) == 0 {
+		result.Result = models.TicketScanNotFound
+		return result, &orgID, nil
+	}
+
+	result.AttendeeName = rows[0].AttendeeName
+	result.Result = scanResult(rows[0], req.EventID)
+	if result.Result == models.TicketScanAdmitted {
+		s.statsService.Record(ctx, orgID, req.EventID, req.Gate, 1)
+		s.integrationService.DispatchCheckIns(orgID, []uuid.UUID{id})
+	}
+	return result, &orgID, nil
+}
+
+// TicketPDF renders a ticket of the user as a PDF for download. Users can download the tickets
+// they hold and those of orders they placed; cancelled tickets cannot be downloaded.
+func (s *TicketService) TicketPDF(ctx context.Context, ticketID, userID uuid.UUID) (*models.Ticket, []byte, error) {
+	db := s.db.WithContext(ctx)
+	ticket, err := findHeldTicket(db, ticketID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := renderTicketPDF(ticket, ticket.Event, ticketTypeLabel(db, ticket), utils.SignTicketQR(s.cfg.QRSecret, ticket.ID))
+	if err != nil {
+		return nil, nil, err
+	}
+	return ticket, pdf, nil
+}
+
+// findHeldTicket loads a ticket with its event for a user who holds it or placed its order.
+// Cancelled and revoked tickets are refused.
+func findHeldTicket(db *gorm.DB, ticketID, userID uuid.UUID) (*models.Ticket, error) {
+	var user models.User
+	if err := db.Select("id", "email").First(&user, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+
+	var ticket models.Ticket
+	err := db.Preload("Event").
+		Joins("JOIN orders ON orders.id = tickets.order_id").
+		Where("tickets.id = ?", ticketID).
+		Where("LOWER(tickets.attendee_email) = LOWER(?) OR orders.user_id = ? OR LOWER(orders.buyer_email) = LOWER(?)", user.Email, userID, user.Email).
+		First(&ticket).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTicketNotFound
+		}
+		return nil, err
+	}
+	if ticket.Status == models.TicketStatusCancelled {
+		return nil, errors.New("Ticket has been cancelled")
+	}
+	if ticket.RevokedAt != nil {
+		return nil, ErrTicketRevoked
+	}
+	if ticket.Event == nil {
+		return nil, ErrTicketNotFound
+	}
+	return &ticket, nil
+}
+
+// canScan reports whether a user may check in tickets of an organization
+func (s *TicketService) canScan(db *gorm.DB, userID uuid.UUID, roles []string, orgID uuid.UUID) (bool, error) {
+	for _, role := range roles {
+		if role == "admin" {
+			return true, nil
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ? AND active", orgID, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to load scanner: %w", err)
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := db.Model(&models.Organization{}).Where("id = ? AND organizer_id = ?", orgID, userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to load organization: %w", err)
+	}
+	return count > 0, nil
+}
+
+// parseCode returns the ticket a scanned code is for. Batches accept signed QR payloads and,
+// for scanners still reading printed ticket IDs, bare IDs.
+func (s *TicketService) parseCode(code string) (uuid.UUID, error) {
+	if id, err := utils.ParseTicketQR(s.cfg.QRSecret, code); err == nil {
+		return id, nil
+	}
+	return uuid.Parse(code)
+}
+
+// rejectRevoked records codes of tickets in the revocation set as revoked and returns the rest.
+// Redis errors fail open; checkInSQL refuses revoked tickets anyway.
+func (s *TicketService) rejectRevoked(ctx context.Context, ids []uuid.UUID, results []models.TicketValidationResult, pending map[uuid.UUID]int) []uuid.UUID {
+	if s.redisClient == nil || len(ids) == 0 {
+		return ids
+	}
+
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id.String()
+	}
+	revoked, err := s.redisClient.SMIsMember(ctx, revokedTicketsKey, members...).Result()
+	if err != nil {
+		log.Printf("Ticket revocation check failed: %v", err)
+		return ids
+	}
+
+	allowed := ids[:0]
+	for i, id := range ids {
+		if revoked[i] {
+			results[pending[id]].Result = models.TicketScanRevoked
+		} else {
+			allowed = append(allowed, id)
+		}
+	}
+	return allowed
+}
+
+// claimScans marks codes as scanned in Redis for the duplicate window, records codes already
+// claimed by a recent scan as duplicates and returns the rest. Redis errors fail open; the
+// database still admits each ticket only once.
+func (s *TicketService) claimScans(ctx context.Context, ids []uuid.UUID, results []models.TicketValidationResult, pending map[uuid.UUID]int) []uuid.UUID {
+	if s.redisClient == nil || len(ids) == 0 {
+		return ids
+	}
+
+	pipe := s.redisClient.Pipeline()
+	claims := make([]*redislib.BoolCmd, len(ids))
+	for i, id := range ids {
+		claims[i] = pipe.SetNX(ctx, scanKey(id), 1, s.cfg.DuplicateWindow)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Scan duplicate check failed: %v", err)
+		return ids
+	}
+
+	fresh := ids[:0]
+	for i, id := range ids {
+		if claims[i].Val() {
+			fresh = append(fresh, id)
+		} else {
+			results[pending[id]].Result = models.TicketScanDuplicate
+		}
+	}
+	return fresh
+}
+
+// releaseScans forgets claims for codes that were not checked, so a retry is not reported as a duplicate
+func (s *TicketService) releaseScans(ids []uuid.UUID) {
+	if s.redisClient == nil {
+		return
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = scanKey(id)
+	}
+	// The request context may be what failed, so use a fresh one
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		log.Printf("Failed to release scan claims: %v", err)
+	}
+}
+
+// scanResult maps a ticket looked up by checkInSQL to the result shown to the scanner
+func scanResult(row scannedTicket, eventID uint) models.TicketScanResult {
+	switch {
+	case row.Admitted:
+		return models.TicketScanAdmitted
+	case row.Revoked:
+		return models.TicketScanRevoked
+	case row.EventID != eventID:
+		return models.TicketScanWrongEvent
+	case row.Status == models.TicketStatusCancelled:
+		return models.TicketScanCancelled
+	default:
+		// Checked in earlier, or by a concurrent scan between the lookup and the update
+		return models.TicketScanAlreadyCheckedIn
+	}
+}
+
+// scanAttempt is the audit trail entry of a scanned code's result
+func scanAttempt(orgID *uuid.UUID, eventID uint, gate string, userID uuid.UUID, device models.ScanDevice, result *models.TicketValidationResult, scannedAt time.Time) models.ScanAttempt {
+	return models.ScanAttempt{
+		OrganizationID:  orgID,
+		EventID:         eventID,
+		TicketID:        result.TicketID,
+		Result:          result.Result,
+		Gate:            strings.TrimSpace(gate),
+		DeviceID:        device.DeviceID,
+		ScannerDeviceID: device.ScannerDeviceID,
+		ScannedBy:       &userID,
+		ScannedAt:       scannedAt,
+	}
+}
+
+func scanKey(ticketID uuid.UUID) string {
+	return "scan:ticket:" + ticketID.String()
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+
+	"github.com/google/uuid"
+	redislib "github.com/redis/go-redis/v9"
+)
+
+// Usage counters are kept per calendar month, a little past the month's end so the previous
+// month can still be looked up
+const usageRetention = 62 * 24 * time.Hour
+
+// UsageService meters per-user activity in monthly Redis counters so integrators can see how much
+// they have used. Counting is best effort: Redis errors are logged and never fail the metered action.
+type UsageService struct {
+	redisClient redislib.UniversalClient
+}
+
+// NewUsageService creates a new usage service
+func NewUsageService() *UsageService {
+	return &UsageService{redisClient: redis.Client}
+}
+
+// RecordEmailSent counts an email delivered to recipient
+func (s *UsageService) RecordEmailSent(ctx context.Context, recipient string) {
+	s.incr(ctx, usageKey("emails", hashIdentifier(recipient), time.Now()))
+}
+
+// RecordEventCreated counts an event created by a user
+func (s *UsageService) RecordEventCreated(ctx context.Context, userID uuid.UUID) {
+	s.incr(ctx, usageKey("events", userID.String(), time.Now()))
+}
+
+// MonthlyUsage returns the current month's counters for a user
+func (s *UsageService) MonthlyUsage(ctx context.Context, userID uuid.UUID, email string) (*models.MonthlyUsage, error) {
+	now := time.Now()
+	usage := &models.MonthlyUsage{Period: now.UTC().Format("2006-01")}
+	if s.redisClient == nil {
+		return usage, nil
+	}
+
+	pipe := s.redisClient.Pipeline()
+	emails := pipe.Get(ctx, usageKey("emails", hashIdentifier(email), now))
+	events := pipe.Get(ctx, usageKey("events", userID.String(), now))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redislib.Nil) {
+		return nil, fmt.Errorf("failed to read usage counters: %w", err)
+	}
+
+	// Missing keys mean nothing was counted this month and read as zero
+	usage.EmailsSent, _ = emails.Int64()
+	usage.EventsCreated, _ = events.Int64()
+	return usage, nil
+}
+
+func (s *UsageService) incr(ctx context.Context, key string) {
+	if s.redisClient == nil {
+		return
+	}
+	pipe := s.redisClient.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, usageRetention)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to record usage %s: %v", key, err)
+	}
+}
+
+func usageKey(metric, subject string, t time.Time) string {
+	return fmt.Sprintf("usage:%s:%s:%s", metric, subject, t.UTC().Format("2006-01"))
+}
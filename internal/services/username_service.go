@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// usernameHoldPeriod is how long a dropped username stays reserved for its former owner
+const usernameHoldPeriod = 30 * 24 * time.Hour
+
+// usernameChangeInterval is the minimum time between two username changes of a user
+const usernameChangeInterval = 24 * time.Hour
+
+// ErrUsernameNotFound is returned when no user has or recently had a username
+var ErrUsernameNotFound = errors.New("User not found")
+
+// reservedUsernames cannot be taken, as they could pass for the platform itself or clash with
+// frontend routes
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "help": true, "me": true, "root": true,
+	"security": true, "settings": true, "staff": true, "support": true, "system": true,
+}
+
+// UsernameService manages users' public handles, used in public profile URLs and @mentions
+type UsernameService struct {
+	db *gorm.DB
+}
+
+// NewUsernameService creates a new username service
+func NewUsernameService() *UsernameService {
+	return &UsernameService{
+		db: database.DB,
+	}
+}
+
+// Availability reports whether a username can be taken
+func (s *UsernameService) Availability(ctx context.Context, username string) (*models.UsernameAvailability, error) {
+	username = strings.ToLower(username)
+	reason, err := usernameUnavailable(s.db.WithContext(ctx), username, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UsernameAvailability{Username: username, Available: reason == "", Reason: reason}, nil
+}
+
+// ChangeUsername sets, changes or, for an empty username, removes a user's username and records
+// the change. Usernames can be changed once per day.
+func (s *UsernameService) ChangeUsername(ctx context.Context, userID uuid.UUID, req *models.ChangeUsernameRequest) (*models.UserProfileResponse, error) {
+	username := strings.ToLower(req.Username)
+
+	var user models.User
+	err := database.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Conn(ctx, s.db)
+		if err := tx.Preload("Organization").First(&user, "id = ?", userID).Error; err != nil {
+			return err
+		}
+
+		current := ""
+		if user.Username != nil {
+			current = *user.Username
+		}
+		if username == current {
+			return nil
+		}
+
+		var last models.UsernameChange
+		err := tx.Where("user_id = ?", userID).Order("created_at DESC").First(&last).Error
+		if err == nil && time.Since(last.CreatedAt) < usernameChangeInterval {
+			return fmt.Errorf("Usernames can be changed again after %s", last.CreatedAt.Add(usernameChangeInterval).UTC().Format(time.RFC3339))
+		} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if username != "" {
+			reason, err := usernameUnavailable(tx, username, userID)
+			if err != nil {
+				return err
+			}
+			if reason != "" {
+				return fmt.Errorf("Username is not available (%s)", reason)
+			}
+			user.Username = &username
+		} else {
+			user.Username = nil
+		}
+
+		if err := tx.Model(&user).Update("username", user.Username).Error; err != nil {
+			return fmt.Errorf("failed to change username: %w", err)
+		}
+		return tx.Create(&models.UsernameChange{UserID: userID, OldUsername: current, NewUsername: username}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := user.ToProfileResponse()
+	return &resp, nil
+}
+
+// History returns a user's username changes, newest first
+func (s *UsernameService) History(ctx context.Context, userID uuid.UUID) ([]models.UsernameChange, error) {
+	var changes []models.UsernameChange
+	if err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// PublicProfile returns the public profile of a username. A username its owner dropped within the
+// hold period resolves to the owner, flagged as renamed.
+func (s *UsernameService) PublicProfile(ctx context.Context, username string) (*models.PublicProfile, error) {
+	db := s.db.WithContext(ctx)
+	username = strings.ToLower(username)
+
+	var user models.User
+	renamed := false
+	err := db.Where("username = ?", username).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		var change models.UsernameChange
+		err = db.Where("old_username = ? AND created_at > ?", username, time.Now().Add(-usernameHoldPeriod)).
+			Order("created_at DESC").First(&change).Error
+		if err == nil {
+			renamed = true
+			err = db.Where("username IS NOT NULL").First(&user, "id = ?", change.UserID).Error
+		}
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUsernameNotFound
+		}
+		return nil, err
+	}
+
+	displayName := user.FirstName
+	if user.LastName != "" {
+		displayName = strings.TrimSpace(displayName + " " + user.LastName[:1] + ".")
+	}
+	if displayName == "" {
+		displayName = *user.Username
+	}
+	return &models.PublicProfile{
+		Username:    *user.Username,
+		DisplayName: displayName,
+		MemberSince: user.CreatedAt,
+		Renamed:     renamed,
+	}, nil
+}
+
+// usernameUnavailable returns why a lowercase username cannot be taken by a user, or "" if it can.
+// Usernames dropped within the hold period can only be taken back by their former owner.
+func usernameUnavailable(db *gorm.DB, username string, userID uuid.UUID) (string, error) {
+	if reservedUsernames[username] {
+		return "reserved", nil
+	}
+
+	var count int64
+	if err := db.Model(&models.User{}).Where("username = ? AND id <> ?", username, userID).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "taken", nil
+	}
+
+	err := db.Model(&models.UsernameChange{}).
+		Where("old_username = ? AND user_id <> ? AND created_at > ?", username, userID, time.Now().Add(-usernameHoldPeriod)).
+		Count(&count).Error
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "recently_used", nil
+	}
+	return "", nil
+}
@@ -0,0 +1,358 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WalletPassContentType is the content type of Apple Wallet passes
+const WalletPassContentType = "application/vnd.apple.pkpass"
+
+// googleWalletSaveURL is the prefix of links adding a signed pass to Google Wallet
+const googleWalletSaveURL = "https://pay.google.com/gp/v/save/"
+
+var (
+	// ErrAppleWalletNotConfigured is returned for Apple Wallet passes when no pass type certificate is set
+	ErrAppleWalletNotConfigured = errors.New("Apple Wallet passes are not configured")
+	// ErrGoogleWalletNotConfigured is returned for Google Wallet links when no issuer is set
+	ErrGoogleWalletNotConfigured = errors.New("Google Wallet passes are not configured")
+)
+
+// WalletService adds issued tickets to phone wallets: it builds signed Apple Wallet passes and
+// Google Wallet save links. Both carry the same signed QR code as the ticket email and PDF, so
+// door scanners admit them alike.
+type WalletService struct {
+	db       *gorm.DB
+	cfg      config.WalletConfig
+	appName  string
+	qrSecret string
+
+	appleCert *x509.Certificate
+	appleKey  crypto.Signer
+	appleWWDR *x509.Certificate
+
+	googleEmail   string
+	googleKey     *rsa.PrivateKey
+	googleOrigins []string
+}
+
+// NewWalletService creates a new wallet service. A wallet whose credentials fail to load is
+// disabled with a warning.
+func NewWalletService(cfg *config.Config) *WalletService {
+	s := &WalletService{
+		db:       database.DB,
+		cfg:      cfg.Wallet,
+		appName:  cfg.App.Name,
+		qrSecret: cfg.Scan.QRSecret,
+	}
+	if cfg.Wallet.ApplePassTypeID != "" {
+		if err := s.loadAppleCredentials(); err != nil {
+			log.Printf("Warning: Apple Wallet passes disabled: %v", err)
+		}
+	}
+	if cfg.Wallet.GoogleIssuerID != "" {
+		if err := s.loadGoogleCredentials(); err != nil {
+			log.Printf("Warning: Google Wallet passes disabled: %v", err)
+		}
+	}
+	for _, origin := range strings.Split(cfg.Wallet.GoogleOrigins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			s.googleOrigins = append(s.googleOrigins, origin)
+		}
+	}
+	return s
+}
+
+// ApplePass builds a signed .pkpass of a ticket of the user. Users can add the tickets they hold
+// and those of orders they placed; cancelled tickets cannot be added.
+func (s *WalletService) ApplePass(ctx context.Context, ticketID, userID uuid.UUID) (*models.Ticket, []byte, error) {
+	if s.appleKey == nil {
+		return nil, nil, ErrAppleWalletNotConfigured
+	}
+
+	db := s.db.WithContext(ctx)
+	ticket, err := findHeldTicket(db, ticketID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pass, err := json.Marshal(s.applePassJSON(ticket, ticketTypeLabel(db, ticket)))
+	if err != nil {
+		return nil, nil, err
+	}
+	files := map[string][]byte{"pass.json": pass}
+	for name, size := range map[string]int{"icon.png": 29, "icon@2x.png": 58, "icon@3x.png": 87} {
+		if files[name], err = walletIcon(size); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	manifest := make(map[string]string, len(files))
+	for name, content := range files {
+		sum := sha1.Sum(content)
+		manifest[name] = hex.EncodeToString(sum[:])
+	}
+	if files["manifest.json"], err = json.Marshal(manifest); err != nil {
+		return nil, nil, err
+	}
+	if files["signature"], err = utils.SignPKCS7Detached(files["manifest.json"], s.appleCert, s.appleKey, s.appleWWDR); err != nil {
+		return nil, nil, fmt.Errorf("failed to sign pass: %w", err)
+	}
+
+	var archive bytes.Buffer
+	w := zip.NewWriter(&archive)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := f.Write(content); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, nil, err
+	}
+	return ticket, archive.Bytes(), nil
+}
+
+// GoogleSaveLink returns a link adding a ticket of the user to Google Wallet. The link carries
+// the event's ticket class and the ticket as a signed JWT; Google creates the class the first
+// time a ticket of the event is saved.
+func (s *WalletService) GoogleSaveLink(ctx context.Context, ticketID, userID uuid.UUID) (*models.WalletPassLink, error) {
+	if s.googleKey == nil {
+		return nil, ErrGoogleWalletNotConfigured
+	}
+
+	db := s.db.WithContext(ctx)
+	ticket, err := findHeldTicket(db, ticketID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	event := ticket.Event
+	classID := fmt.Sprintf("%s.event_%d", s.cfg.GoogleIssuerID, event.ID)
+	class := map[string]interface{}{
+		"id":           classID,
+		"issuerName":   s.appName,
+		"reviewStatus": "UNDER_REVIEW",
+		"eventName":    googleLocalized(event.Title),
+		"dateTime": map[string]string{
+			"start": event.StartDate.Format(time.RFC3339),
+			"end":   event.EndDate.Format(time.RFC3339),
+		},
+	}
+	if event.Location != "" {
+		class["venue"] = map[string]interface{}{
+			"name":    googleLocalized(event.Location),
+			"address": googleLocalized(event.Location),
+		}
+	}
+	object := map[string]interface{}{
+		"id":               fmt.Sprintf("%s.ticket_%s", s.cfg.GoogleIssuerID, ticket.ID),
+		"classId":          classID,
+		"state":            "ACTIVE",
+		"ticketHolderName": ticketHolder(ticket),
+		"ticketNumber":     ticket.ID.String(),
+		"ticketType":       googleLocalized(ticketTypeLabel(db, ticket)),
+		"reservationInfo":  map[string]string{"confirmationCode": ticket.OrderID.String()},
+		"barcode": map[string]string{
+			"type":  "QR_CODE",
+			"value": utils.SignTicketQR(s.qrSecret, ticket.ID),
+		},
+	}
+
+	claims := jwt.MapClaims{
+		"iss": s.googleEmail,
+		"aud": "google",
+		"typ": "savetowallet",
+		"iat": time.Now().Unix(),
+		"payload": map[string]interface{}{
+			"eventTicketClasses": []interface{}{class},
+			"eventTicketObjects": []interface{}{object},
+		},
+	}
+	if len(s.googleOrigins) > 0 {
+		claims["origins"] = s.googleOrigins
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(s.googleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign Google Wallet pass: %w", err)
+	}
+
+	return &models.WalletPassLink{Provider: models.WalletProviderGoogle, SaveURL: googleWalletSaveURL + token}, nil
+}
+
+// WalletPassFilename returns the file name an Apple Wallet pass is downloaded as
+func WalletPassFilename(ticket *models.Ticket) string {
+	return fmt.Sprintf("ticket-%s.pkpass", ticket.ID.String()[:8])
+}
+
+// applePassJSON returns the pass.json of an event ticket
+func (s *WalletService) applePassJSON(ticket *models.Ticket, ticketType string) map[string]interface{} {
+	event := ticket.Event
+	qr := utils.SignTicketQR(s.qrSecret, ticket.ID)
+
+	field := func(key, label, value string) map[string]string {
+		return map[string]string{"key": key, "label": label, "value": value}
+	}
+	secondary := []interface{}{
+		map[string]string{
+			"key":       "date",
+			"label":     "DATE",
+			"value":     event.StartDate.Format(time.RFC3339),
+			"dateStyle": "PKDateStyleMedium",
+			"timeStyle": "PKDateStyleShort",
+		},
+	}
+	if event.Location != "" {
+		secondary = append(secondary, field("venue", "VENUE", event.Location))
+	}
+
+	return map[string]interface{}{
+		"formatVersion":      1,
+		"passTypeIdentifier": s.cfg.ApplePassTypeID,
+		"teamIdentifier":     s.cfg.AppleTeamID,
+		"organizationName":   s.cfg.AppleOrganizationName,
+		"serialNumber":       ticket.ID.String(),
+		"description":        "Ticket for " + event.Title,
+		"relevantDate":       event.StartDate.Format(time.RFC3339),
+		"expirationDate":     event.EndDate.Format(time.RFC3339),
+		"foregroundColor":    "rgb(255, 255, 255)",
+		"backgroundColor":    "rgb(17, 24, 39)",
+		"labelColor":         "rgb(156, 163, 175)",
+		"barcodes": []interface{}{map[string]string{
+			"format":          "PKBarcodeFormatQR",
+			"message":         qr,
+			"messageEncoding": "iso-8859-1",
+		}},
+		"eventTicket": map[string]interface{}{
+			"primaryFields":   []interface{}{field("event", "EVENT", event.Title)},
+			"secondaryFields": secondary,
+			"auxiliaryFields": []interface{}{
+				field("attendee", "ATTENDEE", ticketHolder(ticket)),
+				field("type", "TICKET TYPE", ticketType),
+			},
+			"backFields": []interface{}{
+				field("order", "ORDER NUMBER", ticket.OrderID.String()),
+				field("ticket", "TICKET ID", ticket.ID.String()),
+				field("terms", "TERMS", "This ticket admits one person. The QR code can be used once; do not share it."),
+			},
+		},
+	}
+}
+
+// loadAppleCredentials reads the pass type certificate, its key and the WWDR certificate
+func (s *WalletService) loadAppleCredentials() error {
+	if s.cfg.AppleTeamID == "" || s.cfg.AppleCertFile == "" || s.cfg.AppleKeyFile == "" || s.cfg.AppleWWDRCertFile == "" {
+		return errors.New("APPLE_WALLET_TEAM_ID, APPLE_WALLET_CERT_FILE, APPLE_WALLET_KEY_FILE and APPLE_WALLET_WWDR_CERT_FILE are required")
+	}
+
+	cert, err := utils.LoadPEMCertificate(s.cfg.AppleCertFile)
+	if err != nil {
+		return fmt.Errorf("failed to load pass certificate: %w", err)
+	}
+	key, err := utils.LoadPEMPrivateKey(s.cfg.AppleKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load pass certificate key: %w", err)
+	}
+	wwdr, err := utils.LoadPEMCertificate(s.cfg.AppleWWDRCertFile)
+	if err != nil {
+		return fmt.Errorf("failed to load WWDR certificate: %w", err)
+	}
+	s.appleCert, s.appleKey, s.appleWWDR = cert, key, wwdr
+	return nil
+}
+
+// loadGoogleCredentials reads the service account's email and RSA key from its JSON key file
+func (s *WalletService) loadGoogleCredentials() error {
+	if s.cfg.GoogleServiceAccountFile == "" {
+		return errors.New("GOOGLE_WALLET_SERVICE_ACCOUNT_FILE is required")
+	}
+
+	data, err := os.ReadFile(s.cfg.GoogleServiceAccountFile)
+	if err != nil {
+		return err
+	}
+	var account struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return fmt.Errorf("invalid service account key: %w", err)
+	}
+	key, err := utils.ParsePEMPrivateKey([]byte(account.PrivateKey))
+	if err != nil {
+		return fmt.Errorf("invalid service account key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok || account.ClientEmail == "" {
+		return errors.New("service account key must hold a client email and an RSA private key")
+	}
+	s.googleEmail, s.googleKey = account.ClientEmail, rsaKey
+	return nil
+}
+
+// ticketHolder returns the name shown on a ticket, its attendee's email when unnamed
+func ticketHolder(ticket *models.Ticket) string {
+	if ticket.AttendeeName != "" {
+		return ticket.AttendeeName
+	}
+	return ticket.AttendeeEmail
+}
+
+// googleLocalized returns a Google Wallet localized string in the default language
+func googleLocalized(value string) map[string]interface{} {
+	return map[string]interface{}{
+		"defaultValue": map[string]string{"language": "en-US", "value": value},
+	}
+}
+
+// walletIcon renders the square icon shown with a pass on the lock screen and in notifications
+func walletIcon(size int) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	background := color.RGBA{R: 17, G: 24, B: 39, A: 255}
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			img.Set(x, y, background)
+		}
+	}
+	// A white ticket stub across the middle
+	inset, height := size/5, size/3
+	for y := (size - height) / 2; y < (size+height)/2; y++ {
+		for x := inset; x < size-inset; x++ {
+			img.Set(x, y, color.White)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
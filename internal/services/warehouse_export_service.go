@@ -0,0 +1,256 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskWarehouseExport is the asynq task type for the nightly warehouse export
+const TaskWarehouseExport = "warehouse:export"
+
+// warehouseBatchSize is how many rows are read from the database at a time
+const warehouseBatchSize = 1000
+
+// ErrWarehouseNotConfigured is returned when exporting without a destination bucket
+var ErrWarehouseNotConfigured = errors.New("warehouse export is not configured")
+
+// WarehouseExportPayload is the payload of a warehouse export task. A zero date exports the previous UTC day.
+type WarehouseExportPayload struct {
+	Date time.Time `json:"date"`
+}
+
+// WarehouseExportService exports a day of orders, tickets and check-ins as gzipped CSV fact tables
+// to S3, partitioned by UTC day, for the data team's warehouse. Names are dropped and emails are
+// replaced by keyed hashes, so repeat buyers can still be counted but not identified.
+type WarehouseExportService struct {
+	db           *gorm.DB
+	cfg          config.WarehouseConfig
+	uploader     *s3Bucket
+	pseudonymKey []byte
+}
+
+// NewWarehouseExportService creates a new warehouse export service. Exports are disabled when no
+// bucket is configured.
+func NewWarehouseExportService(cfg *config.Config) *WarehouseExportService {
+	service := &WarehouseExportService{
+		db:           database.DB,
+		cfg:          cfg.Warehouse,
+		pseudonymKey: []byte(cfg.Warehouse.PseudonymKey),
+	}
+	if cfg.Warehouse.S3Bucket == "" {
+		return service
+	}
+	if cfg.Warehouse.PseudonymKey == "" {
+		log.Println("Warning: WAREHOUSE_PSEUDONYM_KEY is not set, warehouse export disabled")
+		return service
+	}
+
+	uploader, err := newS3Bucket(cfg.Warehouse.S3Endpoint, cfg.Warehouse.S3Bucket, cfg.Warehouse.S3Region,
+		cfg.Warehouse.AccessKeyID, cfg.Warehouse.SecretAccessKey)
+	if err != nil {
+		log.Printf("Warning: warehouse export disabled: %v", err)
+		return service
+	}
+	service.uploader = uploader
+	return service
+}
+
+// Enabled reports whether exports have somewhere to go
+func (s *WarehouseExportService) Enabled() bool {
+	return s.uploader != nil
+}
+
+// ExportDay exports every dataset's partition for the UTC day containing day. A failed dataset
+// does not stop the others; its partition is recorded as failed and the error is returned.
+func (s *WarehouseExportService) ExportDay(ctx context.Context, day time.Time) error {
+	if !s.Enabled() {
+		return ErrWarehouseNotConfigured
+	}
+
+	day = day.UTC().Truncate(24 * time.Hour)
+	var errs []error
+	for _, dataset := range []string{models.WarehouseDatasetOrders, models.WarehouseDatasetTickets, models.WarehouseDatasetCheckIns} {
+		if err := s.exportPartition(ctx, dataset, day); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", dataset, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("warehouse export of %s failed: %w", day.Format("2006-01-02"), err)
+	}
+	log.Printf("Warehouse export of %s completed", day.Format("2006-01-02"))
+	return nil
+}
+
+// ListPartitions returns exported partitions, newest first
+func (s *WarehouseExportService) ListPartitions(ctx context.Context, filter *models.WarehouseExportFilter) ([]models.WarehouseExport, error) {
+	query := s.db.WithContext(ctx).Order("partition_date DESC, dataset")
+	if filter.Dataset != "" {
+		query = query.Where("dataset = ?", filter.Dataset)
+	}
+	if filter.From != "" {
+		query = query.Where("partition_date >= ?", filter.From)
+	}
+	if filter.To != "" {
+		query = query.Where("partition_date <= ?", filter.To)
+	}
+
+	var partitions []models.WarehouseExport
+	if err := query.Find(&partitions).Error; err != nil {
+		return nil, err
+	}
+	return partitions, nil
+}
+
+// exportPartition writes one dataset's day to S3 and records the partition in the manifest
+func (s *WarehouseExportService) exportPartition(ctx context.Context, dataset string, day time.Time) error {
+	key := fmt.Sprintf("%s/%s/dt=%s/%s.csv.gz", strings.Trim(s.cfg.S3Prefix, "/"), dataset, day.Format("2006-01-02"), dataset)
+	partition := models.WarehouseExport{
+		Dataset:       dataset,
+		PartitionDate: day,
+		Location:      s.uploader.location(key),
+		Status:        models.ExportStatusFailed,
+	}
+
+	data, rows, err := s.buildPartition(ctx, dataset, day)
+	if err == nil {
+		err = s.uploader.Put(ctx, key, data, "text/csv", "gzip")
+	}
+	if err != nil {
+		partition.Error = err.Error()
+	} else {
+		now := time.Now()
+		partition.Status = models.ExportStatusCompleted
+		partition.RowCount = rows
+		partition.Bytes = int64(len(data))
+		partition.CompletedAt = &now
+	}
+
+	saveErr := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dataset"}, {Name: "partition_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"location", "status", "row_count", "bytes", "error", "completed_at", "updated_at"}),
+	}).Create(&partition).Error
+	return errors.Join(err, saveErr)
+}
+
+// buildPartition renders a dataset's rows for a day as gzipped CSV. Rows are read in batches
+// keyed on the primary key, so they come out in ID order.
+func (s *WarehouseExportService) buildPartition(ctx context.Context, dataset string, day time.Time) ([]byte, int, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	w := csv.NewWriter(gz)
+
+	db := s.db.WithContext(ctx)
+	next := day.Add(24 * time.Hour)
+	rows := 0
+	var err error
+
+	switch dataset {
+	case models.WarehouseDatasetOrders:
+		w.Write([]string{"order_id", "event_id", "organization_id", "buyer_key", "quantity", "total_amount", "fee_amount", "insurance_amount", "refunded_amount", "currency", "status", "payment_method", "staff_order", "created_at", "paid_at"})
+		var batch []models.Order
+		err = db.Where("created_at >= ? AND created_at < ?", day, next).
+			FindInBatches(&batch, warehouseBatchSize, func(tx *gorm.DB, _ int) error {
+				for _, o := range batch {
+					w.Write([]string{
+						o.ID.String(), strconv.FormatUint(uint64(o.EventID), 10), uuidString(o.OrganizationID), s.pseudonym(o.BuyerEmail),
+						strconv.Itoa(o.Quantity), formatAmount(o.TotalAmount), formatAmount(o.FeeAmount), formatAmount(o.InsuranceAmount), formatAmount(o.RefundedAmount),
+						o.Currency, string(o.Status), o.PaymentMethod, strconv.FormatBool(o.CreatedBy != nil),
+						formatTimestamp(&o.CreatedAt), formatTimestamp(o.PaidAt),
+					})
+				}
+				rows += len(batch)
+				return w.Error()
+			}).Error
+
+	case models.WarehouseDatasetTickets:
+		w.Write([]string{"ticket_id", "order_id", "event_id", "organization_id", "allocation_id", "attendee_key", "marketing_opt_in", "status", "created_at"})
+		var batch []models.Ticket
+		err = db.Where("created_at >= ? AND created_at < ?", day, next).
+			FindInBatches(&batch, warehouseBatchSize, func(tx *gorm.DB, _ int) error {
+				for _, t := range batch {
+					w.Write([]string{
+						t.ID.String(), t.OrderID.String(), strconv.FormatUint(uint64(t.EventID), 10), uuidString(t.OrganizationID),
+						uuidString(t.AllocationID), s.pseudonym(t.AttendeeEmail), strconv.FormatBool(t.MarketingOptIn),
+						string(t.Status), formatTimestamp(&t.CreatedAt),
+					})
+				}
+				rows += len(batch)
+				return w.Error()
+			}).Error
+
+	case models.WarehouseDatasetCheckIns:
+		w.Write([]string{"ticket_id", "event_id", "organization_id", "gate", "checked_in_at"})
+		var batch []models.Ticket
+		err = db.Where("checked_in_at >= ? AND checked_in_at < ?", day, next).
+			FindInBatches(&batch, warehouseBatchSize, func(tx *gorm.DB, _ int) error {
+				for _, t := range batch {
+					w.Write([]string{
+						t.ID.String(), strconv.FormatUint(uint64(t.EventID), 10), uuidString(t.OrganizationID),
+						t.CheckInGate, formatTimestamp(t.CheckedInAt),
+					})
+				}
+				rows += len(batch)
+				return w.Error()
+			}).Error
+
+	default:
+		return nil, 0, fmt.Errorf("unknown dataset %q", dataset)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, 0, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), rows, nil
+}
+
+// pseudonym replaces an email with a stable keyed hash, so the same person gets the same key in
+// every export without the key revealing the address
+func (s *WarehouseExportService) pseudonym(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.pseudonymKey)
+	mac.Write([]byte(email))
+	return hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+func uuidString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+func formatTimestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
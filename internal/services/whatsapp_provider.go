@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"event-ticketing-backend/pkg/config"
+)
+
+// ErrWhatsAppUnavailable is returned when WhatsApp delivery is requested but no account is configured
+var ErrWhatsAppUnavailable = errors.New("WhatsApp delivery is not available")
+
+// WhatsAppTemplateMessage is a message built from an approved template, sent to one phone number.
+// Business-initiated WhatsApp messages must use a template the account had approved.
+type WhatsAppTemplateMessage struct {
+	To       string   // Phone number in international format
+	Template string   // Name of the approved template
+	Language string   // Language code the template was approved in
+	Params   []string // Values of the template's body placeholders, in order
+}
+
+// WhatsAppProvider sends WhatsApp template messages
+type WhatsAppProvider interface {
+	Name() string
+	SendTemplate(ctx context.Context, msg *WhatsAppTemplateMessage) (string, error) // Returns the provider's message ID
+}
+
+// NewWhatsAppProvider returns the WhatsApp Cloud API provider, or nil when WhatsApp delivery is
+// disabled
+func NewWhatsAppProvider(cfg *config.Config) (WhatsAppProvider, error) {
+	if cfg.WhatsApp.PhoneNumberID == "" {
+		return nil, nil
+	}
+	if _, err := url.ParseRequestURI(cfg.WhatsApp.APIURL); err != nil {
+		return nil, fmt.Errorf("invalid WHATSAPP_API_URL: %w", err)
+	}
+	if cfg.WhatsApp.AccessToken == "" {
+		return nil, errors.New("WHATSAPP_ACCESS_TOKEN is required")
+	}
+	return &cloudWhatsAppProvider{
+		baseURL:       strings.TrimRight(cfg.WhatsApp.APIURL, "/"),
+		phoneNumberID: cfg.WhatsApp.PhoneNumberID,
+		accessToken:   cfg.WhatsApp.AccessToken,
+		httpClient:    &http.Client{Timeout: cfg.WhatsApp.Timeout},
+	}, nil
+}
+
+// cloudWhatsAppProvider talks to the WhatsApp Business Cloud API:
+//
+//	POST /{phone-number-id}/messages    {"messaging_product": "whatsapp", "type": "template", ...} -> {"messages": [{"id"}]}
+type cloudWhatsAppProvider struct {
+	baseURL       string
+	phoneNumberID string
+	accessToken   string
+	httpClient    *http.Client
+}
+
+func (p *cloudWhatsAppProvider) Name() string {
+	return "cloud"
+}
+
+// SendTemplate sends a template message and returns the Cloud API's message ID
+func (p *cloudWhatsAppProvider) SendTemplate(ctx context.Context, msg *WhatsAppTemplateMessage) (string, error) {
+	params := make([]map[string]string, 0, len(msg.Params))
+	for _, value := range msg.Params {
+		params = append(params, map[string]string{"type": "text", "text": value})
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                strings.TrimPrefix(msg.To, "+"),
+		"type":              "template",
+		"template": map[string]interface{}{
+			"name":     msg.Template,
+			"language": map[string]string{"code": msg.Language},
+			"components": []map[string]interface{}{
+				{"type": "body", "parameters": params},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := fmt.Sprintf("%s/%s/messages", p.baseURL, url.PathEscape(p.phoneNumberID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build WhatsApp request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("WhatsApp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read WhatsApp response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		return "", fmt.Errorf("WhatsApp API responded with status %d: %s", resp.StatusCode, apiErr.Error.Message)
+	}
+
+	var out struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("failed to decode WhatsApp response: %w", err)
+	}
+	if len(out.Messages) == 0 || out.Messages[0].ID == "" {
+		return "", errors.New("WhatsApp API returned no message ID")
+	}
+	return out.Messages[0].ID, nil
+}
@@ -1,9 +1,11 @@
 package validators
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"unicode"
 
@@ -58,10 +60,15 @@ func Initialize() {
 		_ = v.RegisterValidation("address", validateAddress)
 		_ = v.RegisterValidation("zip_code", validateZipCode)
 		_ = v.RegisterValidation("currency_amount", validateCurrencyAmount)
+		_ = v.RegisterValidation("range_end", validateRangeEnd)
 
 		// Register custom error messages
 		v.RegisterTagNameFunc(func(fld reflect.StructField) string {
 			name := strings.SplitN(fld.Tag.Get("json"), ",", 2)[0]
+			if name == "" {
+				// Query structures are named by their form tags
+				name = strings.SplitN(fld.Tag.Get("form"), ",", 2)[0]
+			}
 			if name == "-" {
 				return ""
 			}
@@ -70,6 +77,12 @@ func Initialize() {
 	}
 }
 
+// ValidateStruct checks a struct against its binding tags, for payloads that are not validated
+// while binding the request, such as published event drafts
+func ValidateStruct(obj interface{}) error {
+	return binding.Validator.ValidateStruct(obj)
+}
+
 // Custom validators
 func validateEmail(fl validator.FieldLevel) bool {
 	return emailRegex.MatchString(fl.Field().String())
@@ -136,6 +149,28 @@ func validateCurrencyAmount(fl validator.FieldLevel) bool {
 	return currencyAmountRegex.MatchString(fl.Field().String())
 }
 
+// validateRangeEnd checks that the field closing a range is not less than the field opening it,
+// named by the tag's parameter. Ranges left open at either end are valid; dates compare as
+// YYYY-MM-DD strings.
+func validateRangeEnd(fl validator.FieldLevel) bool {
+	start, kind, _, ok := fl.GetStructFieldOKAdvanced2(fl.Parent(), fl.Param())
+	if !ok {
+		return true
+	}
+
+	field := fl.Field()
+	switch kind {
+	case reflect.String:
+		return start.String() == "" || field.String() == "" || field.String() >= start.String()
+	case reflect.Float32, reflect.Float64:
+		return field.Float() >= start.Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int() >= start.Int()
+	default:
+		return true
+	}
+}
+
 // FormatErrors formats validation errors into a user-friendly format
 func FormatErrors(err error) ValidationErrors {
 	var validationErrors ValidationErrors
@@ -147,6 +182,12 @@ func FormatErrors(err error) ValidationErrors {
 				Message: getErrorMsg(e),
 			})
 		}
+	} else if numErr := (*strconv.NumError)(nil); errors.As(err, &numErr) {
+		// Numbers that fail to parse while binding query parameters or forms
+		validationErrors.Errors = append(validationErrors.Errors, ValidationError{
+			Field:   "request",
+			Message: fmt.Sprintf("%q is not a valid number", numErr.Num),
+		})
 	} else {
 		// If it's not a validation error, still provide some feedback
 		validationErrors.Errors = append(validationErrors.Errors, ValidationError{
@@ -172,8 +213,14 @@ func getErrorMsg(e validator.FieldError) string {
 	case "strong_password":
 		return fmt.Sprintf("%s must be at least 8 characters long and contain uppercase, lowercase, number, and special character", fieldName)
 	case "min":
+		if isNumber(e.Kind()) {
+			return fmt.Sprintf("%s must be at least %s", fieldName, e.Param())
+		}
 		return fmt.Sprintf("%s must be at least %s characters long", fieldName, e.Param())
 	case "max":
+		if isNumber(e.Kind()) {
+			return fmt.Sprintf("%s must not exceed %s", fieldName, e.Param())
+		}
 		return fmt.Sprintf("%s must not exceed %s characters", fieldName, e.Param())
 	case "url":
 		return fmt.Sprintf("%s must be a valid URL", fieldName)
@@ -218,14 +265,30 @@ func getErrorMsg(e validator.FieldError) string {
 	case "numeric":
 		return fmt.Sprintf("%s must contain only numbers", fieldName)
 	case "datetime":
+		if e.Param() == "2006-01-02" {
+			return fmt.Sprintf("%s must be a valid date in YYYY-MM-DD format", fieldName)
+		}
 		return fmt.Sprintf("%s must be a valid date and time", fieldName)
 	case "oneof":
 		return fmt.Sprintf("%s must be one of the following values: %s", fieldName, e.Param())
+	case "range_end":
+		return fmt.Sprintf("%s must not be less than %s", fieldName, getFieldDisplayName(e.Param()))
 	default:
 		return fmt.Sprintf("%s is invalid", fieldName)
 	}
 }
 
+// isNumber reports whether a field is numeric, whose min and max are bounds rather than lengths
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
 // getFieldDisplayName converts technical field names to user-friendly display names
 func getFieldDisplayName(fieldName string) string {
 	fieldDisplayNames := map[string]string{
@@ -255,9 +318,13 @@ func getFieldDisplayName(fieldName string) string {
 		"start_date":        "Start date",
 		"end_date":          "End date",
 		"start_time":        "Start time",
+		"starts_from":       "Start date from",
+		"starts_to":         "Start date to",
 		"end_time":          "End time",
 		"price":             "Price",
 		"ticket_price":      "Ticket price",
+		"min_price":         "Minimum price",
+		"max_price":         "Maximum price",
 		"quantity":          "Quantity",
 		"capacity":          "Capacity",
 		"location":          "Location",
@@ -284,6 +351,8 @@ func getFieldDisplayName(fieldName string) string {
 		"LastName":          "Last name",        // For struct field names
 		"Email":             "Email address",    // For struct field names
 		"Password":          "Password",         // For struct field names
+		"StartsFrom":        "Start date from",  // For struct field names
+		"MinPrice":          "Minimum price",    // For struct field names
 	}
 
 	if displayName, exists := fieldDisplayNames[fieldName]; exists {
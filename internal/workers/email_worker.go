@@ -3,55 +3,53 @@ package workers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"strconv"
 	"time"
 
 	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
 	"event-ticketing-backend/internal/services"
 	"event-ticketing-backend/pkg/config"
+	"event-ticketing-backend/pkg/utils"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 )
 
 // EmailWorker processes email jobs from the queue
 type EmailWorker struct {
-	server       *asynq.Server
-	mux          *asynq.ServeMux
-	emailService *services.EmailService
-	cfg          *config.Config
+	server              *asynq.Server
+	mux                 *asynq.ServeMux
+	emailService        *services.EmailService
+	usageService        *services.UsageService
+	notificationService *services.NotificationService
+	cfg                 *config.Config
 }
 
 // NewEmailWorker creates a new email worker
 func NewEmailWorker(cfg *config.Config, emailService *services.EmailService) *EmailWorker {
-	// Convert DB string to int for Asynq
-	db := 0
-	if cfg.Redis.DB != "" {
-		if dbInt, err := strconv.Atoi(cfg.Redis.DB); err == nil {
-			db = dbInt
-		}
-	}
-
-	redisOpts := asynq.RedisClientOpt{
-		Addr:     fmt.Sprintf("%s:%d", cfg.Redis.Host, cfg.Redis.Port),
-		Password: cfg.Redis.Password,
-		DB:       db,
-	}
+	redisOpts := redis.QueueConnOpt(cfg)
 
 	// Configure server with different priority queues
 	serverConfig := asynq.Config{
 		Concurrency: 10, // Number of concurrent workers
 		Queues: map[string]int{
-			"queue:email:urgent": 6, // Highest priority (OTP, password reset)
-			"queue:email:high":   3, // High priority (welcome, verification)
-			"queue:email:normal": 1, // Normal priority (notifications)
-			"queue:email:low":    1, // Low priority (marketing)
+			redis.QueueName("queue:email:urgent"): 6, // Highest priority (OTP, password reset)
+			redis.QueueName("queue:email:high"):   3, // High priority (welcome, verification)
+			redis.QueueName("queue:email:normal"): 1, // Normal priority (notifications)
+			redis.QueueName("queue:email:low"):    1, // Low priority (marketing)
 		},
 		// Configure retry delays
 		RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+			if delay, ok := backpressureDelay(err); ok {
+				return delay
+			}
 			return time.Duration(n) * time.Minute // 1min, 2min, 3min, etc.
 		},
+		// Calls rejected by a circuit breaker are deferred without using up the task's retries
+		IsFailure: isTaskFailure,
 		ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
 			log.Printf("Email task failed: %v, Error: %v", task.Type(), err)
 		}),
@@ -61,10 +59,12 @@ func NewEmailWorker(cfg *config.Config, emailService *services.EmailService) *Em
 	mux := asynq.NewServeMux()
 
 	worker := &EmailWorker{
-		server:       server,
-		mux:          mux,
-		emailService: emailService,
-		cfg:          cfg,
+		server:              server,
+		mux:                 mux,
+		emailService:        emailService,
+		usageService:        services.NewUsageService(),
+		notificationService: services.NewNotificationService(cfg),
+		cfg:                 cfg,
 	}
 
 	// Register task handlers
@@ -89,76 +89,34 @@ func (w *EmailWorker) handleEmailSend(ctx context.Context, task *asynq.Task) err
 
 	log.Printf("Processing email job: ID=%s, Type=%s, To=%s", emailJob.ID, emailJob.Type, emailJob.To)
 
-	// Prepare email data
-	emailData := services.EmailData{
-		To:            emailJob.To,
-		Subject:       emailJob.Subject,
-		Title:         w.getTitleFromJob(emailJob),
-		Message:       w.getMessageFromJob(emailJob),
-		RecipientName: w.getRecipientName(emailJob),
-		OTP:           w.getOTPFromJob(emailJob),
-		Data:          emailJob.TemplateData,
-	}
-
 	// Send the email
-	err := w.emailService.SendEmail(
-		emailJob.To,
-		emailJob.Subject,
-		emailJob.TemplateFile,
-		emailData,
-	)
+	err := w.emailService.SendJob(&emailJob)
+	w.recordDelivery(ctx, emailJob, err)
 
 	if err != nil {
 		log.Printf("Failed to send email: ID=%s, Error=%v", emailJob.ID, err)
+		if errors.Is(err, services.ErrEmailAttachmentTooLarge) {
+			// Retrying cannot shrink the attachment
+			return fmt.Errorf("failed to send email: %w: %w", err, asynq.SkipRetry)
+		}
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	log.Printf("Email sent successfully: ID=%s, To=%s", emailJob.ID, emailJob.To)
+	w.usageService.RecordEmailSent(ctx, emailJob.To)
 	return nil
 }
 
-// getRecipientName extracts recipient name from email job data
-func (w *EmailWorker) getRecipientName(emailJob models.EmailJob) string {
-	if name, ok := emailJob.TemplateData["RecipientName"].(string); ok {
-		return name
-	}
-	if name, ok := emailJob.TemplateData["FirstName"].(string); ok {
-		return name
-	}
-	return ""
-}
-
-// getTitleFromJob extracts title from email job data
-func (w *EmailWorker) getTitleFromJob(emailJob models.EmailJob) string {
-	if title, ok := emailJob.TemplateData["Title"].(string); ok {
-		return title
+// recordDelivery records the outcome of sending an email tracked in the notification log
+func (w *EmailWorker) recordDelivery(ctx context.Context, emailJob models.EmailJob, err error) {
+	if emailJob.NotificationID == "" {
+		return
 	}
-	// Default to subject if no title specified
-	return emailJob.Subject
-}
-
-// getMessageFromJob extracts message from email job data
-func (w *EmailWorker) getMessageFromJob(emailJob models.EmailJob) string {
-	if message, ok := emailJob.TemplateData["Message"].(string); ok {
-		return message
-	}
-	// Provide default message based on email type
-	switch emailJob.Type {
-	case models.EmailTypeOTP:
-		return "Please use the verification code below to proceed."
-	case models.EmailTypeWelcome:
-		return "Welcome! We're excited to have you join our community."
-	default:
-		return "Thank you for using our service."
-	}
-}
-
-// getOTPFromJob extracts OTP from email job data
-func (w *EmailWorker) getOTPFromJob(emailJob models.EmailJob) string {
-	if otp, ok := emailJob.TemplateData["OTP"].(string); ok {
-		return otp
+	notificationID, parseErr := uuid.Parse(emailJob.NotificationID)
+	if parseErr != nil {
+		return
 	}
-	return ""
+	w.notificationService.RecordEmailDelivery(ctx, notificationID, err)
 }
 
 // Start starts the email worker
@@ -180,3 +138,18 @@ func (w *EmailWorker) Stop() {
 	w.server.Shutdown()
 	log.Println("Email worker stopped")
 }
+
+// isTaskFailure reports whether a task error should count against the task's retries. Tasks
+// rejected by a circuit breaker never reached the dependency, so they are only deferred.
+func isTaskFailure(err error) bool {
+	return !utils.IsBackpressure(err)
+}
+
+// backpressureDelay returns how long to defer a task rejected by a circuit breaker
+func backpressureDelay(err error) (time.Duration, bool) {
+	var bp *utils.BackpressureError
+	if !errors.As(err, &bp) {
+		return 0, false
+	}
+	return bp.RetryAfter, true
+}
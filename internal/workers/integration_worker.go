@@ -0,0 +1,162 @@
+package workers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"event-ticketing-backend/internal/database"
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/hibiken/asynq"
+)
+
+// IntegrationWorker processes third-party integration jobs (REST hooks, contact sync, accounting exports, chat notifications)
+type IntegrationWorker struct {
+	server             *asynq.Server
+	mux                *asynq.ServeMux
+	httpClient         *http.Client
+	integrationService *services.IntegrationService
+}
+
+// NewIntegrationWorker creates a new integration worker
+func NewIntegrationWorker(cfg *config.Config) *IntegrationWorker {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	serverConfig := asynq.Config{
+		Concurrency: 5,
+		Queues: map[string]int{
+			redis.QueueName(services.IntegrationQueue): 1,
+		},
+		RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+			return time.Duration(n*n) * time.Minute // 1min, 4min, 9min, etc.
+		},
+		ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
+			log.Printf("Integration job failed: %v, Error: %v", task.Type(), err)
+		}),
+	}
+
+	worker := &IntegrationWorker{
+		server:             asynq.NewServer(redisOpts, serverConfig),
+		mux:                asynq.NewServeMux(),
+		httpClient:         services.NewPublicHTTPClient(10 * time.Second), // Hook targets and chat webhooks are organizer-supplied
+		integrationService: services.NewIntegrationService(cfg),
+	}
+
+	worker.mux.HandleFunc(services.TaskHookDeliver, worker.handleHookDeliver)
+	worker.mux.HandleFunc(services.TaskContactSync, worker.handleContactSync)
+	worker.mux.HandleFunc(services.TaskAccountingExport, worker.handleAccountingExport)
+	worker.mux.HandleFunc(services.TaskChatNotify, worker.handleChatNotify)
+
+	return worker
+}
+
+// handleHookDeliver POSTs a signed hook payload to its subscription target
+func (w *IntegrationWorker) handleHookDeliver(ctx context.Context, task *asynq.Task) error {
+	var delivery models.HookDelivery
+	if err := json.Unmarshal(task.Payload(), &delivery); err != nil {
+		return fmt.Errorf("failed to unmarshal hook delivery: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.integrationService.DeliverHook(ctx, w.httpClient, &delivery)
+}
+
+// handleContactSync syncs an organization's opted-in attendees, or those of one of its attendee
+// segments, to its marketing audience
+func (w *IntegrationWorker) handleContactSync(ctx context.Context, task *asynq.Task) error {
+	var payload services.ContactSyncPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal contact sync job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	if payload.SegmentID != nil {
+		return w.integrationService.SyncSegmentContacts(ctx, payload.OrganizationID, *payload.SegmentID)
+	}
+	return w.integrationService.SyncContacts(ctx, payload.OrganizationID)
+}
+
+// handleAccountingExport generates a queued accounting export file
+func (w *IntegrationWorker) handleAccountingExport(ctx context.Context, task *asynq.Task) error {
+	var payload services.AccountingExportPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal accounting export job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.integrationService.GenerateAccountingExport(ctx, payload.ExportID)
+}
+
+// handleChatNotify posts a notification message to a Slack or Discord incoming webhook
+func (w *IntegrationWorker) handleChatNotify(ctx context.Context, task *asynq.Task) error {
+	var message models.ChatMessage
+	if err := json.Unmarshal(task.Payload(), &message); err != nil {
+		return fmt.Errorf("failed to unmarshal chat message: %w: %w", err, asynq.SkipRetry)
+	}
+
+	// Slack expects "text", Discord expects "content"
+	var body map[string]string
+	switch message.Provider {
+	case models.ChatProviderDiscord:
+		body = map[string]string{"content": message.Text}
+	default:
+		body = map[string]string{"text": message.Text}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat message body: %w: %w", err, asynq.SkipRetry)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, message.WebhookURL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to build chat request: %w: %w", err, asynq.SkipRetry)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post chat message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Revoked webhooks (channel archived, webhook deleted) will never succeed again
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		log.Printf("Chat webhook revoked, disabling: ID=%s", message.WebhookID)
+		if err := database.DB.Model(&models.ChatWebhook{}).Where("id = ?", message.WebhookID).Update("enabled", false).Error; err != nil {
+			return fmt.Errorf("failed to disable revoked chat webhook: %w", err)
+		}
+		return nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("chat webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// Start starts the integration worker
+func (w *IntegrationWorker) Start() {
+	log.Println("Starting integration worker...")
+
+	go func() {
+		if err := w.server.Run(w.mux); err != nil {
+			log.Fatalf("Failed to start integration worker: %v", err)
+		}
+	}()
+
+	log.Println("Integration worker started successfully")
+}
+
+// Stop stops the integration worker gracefully
+func (w *IntegrationWorker) Stop() {
+	log.Println("Stopping integration worker...")
+	w.server.Shutdown()
+	log.Println("Integration worker stopped")
+}
@@ -0,0 +1,129 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"event-ticketing-backend/internal/redis"
+)
+
+const (
+	// leaderKey is the Redis key of the lease held by the replica that runs scheduled jobs
+	leaderKey = "workers:leader"
+	// leaderTTL is how long a lease outlives its holder. A crashed leader is replaced within this time.
+	leaderTTL = 15 * time.Second
+)
+
+// LeaderElector keeps at most one replica in charge of scheduled jobs. Replicas compete for a
+// Redis lease; the holder renews it every third of its TTL and steps down as soon as a renewal
+// fails, before the lease can pass to another replica.
+type LeaderElector struct {
+	onElected func()
+	onDeposed func()
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	mu     sync.Mutex
+	leader bool
+}
+
+// NewLeaderElector creates an elector that calls onElected when this replica becomes leader and
+// onDeposed when it stops being leader
+func NewLeaderElector(onElected, onDeposed func()) *LeaderElector {
+	return &LeaderElector{
+		onElected: onElected,
+		onDeposed: onDeposed,
+	}
+}
+
+// Start begins competing for leadership. Without Redis there is nothing to coordinate with, so
+// the replica leads on its own as a single instance would.
+func (e *LeaderElector) Start() {
+	if redis.Client == nil {
+		log.Println("Warning: Redis unavailable, running scheduled jobs without leader election")
+		e.setLeader(true)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.run(ctx)
+}
+
+// Stop gives up leadership and stops competing for it
+func (e *LeaderElector) Stop() {
+	if e.cancel == nil {
+		e.setLeader(false)
+		return
+	}
+	e.cancel()
+	<-e.done
+}
+
+// IsLeader reports whether this replica currently runs scheduled jobs
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leader
+}
+
+func (e *LeaderElector) run(ctx context.Context) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(leaderTTL / 3)
+	defer ticker.Stop()
+
+	var lease *redis.Lock
+	for {
+		if lease == nil {
+			acquired, err := redis.AcquireLock(ctx, leaderKey, leaderTTL)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Leader election failed: %v", err)
+			}
+			if acquired != nil {
+				lease = acquired
+				log.Println("Elected leader for scheduled jobs")
+				e.setLeader(true)
+			}
+		} else if err := lease.Refresh(ctx, leaderTTL); err != nil && ctx.Err() == nil {
+			log.Printf("Lost leadership for scheduled jobs: %v", err)
+			lease = nil
+			e.setLeader(false)
+		}
+
+		select {
+		case <-ctx.Done():
+			if lease != nil {
+				e.setLeader(false)
+				// Hand over right away instead of making the next leader wait for the TTL
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+				if err := lease.Release(releaseCtx); err != nil {
+					log.Printf("Failed to release leadership: %v", err)
+				}
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setLeader records a leadership change and notifies the callbacks
+func (e *LeaderElector) setLeader(leader bool) {
+	e.mu.Lock()
+	changed := e.leader != leader
+	e.leader = leader
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	if leader {
+		e.onElected()
+	} else {
+		e.onDeposed()
+	}
+}
@@ -0,0 +1,390 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"event-ticketing-backend/internal/redis"
+	"event-ticketing-backend/internal/services"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/hibiken/asynq"
+)
+
+// TicketingWorker processes scheduled inventory and order jobs
+type TicketingWorker struct {
+	server                *asynq.Server
+	mux                   *asynq.ServeMux
+	redisOpts             asynq.RedisConnOpt
+	schedulerMu           sync.Mutex
+	scheduler             *asynq.Scheduler // Set while this replica leads scheduled jobs
+	reconciliationCron    string
+	forecastCron          string
+	warehouseCron         string
+	orderExpiryCron       string
+	cartExpiryCron        string
+	whatsAppReminderCron  string
+	archiveCron           string
+	allocationService     *services.AllocationService
+	installmentService    *services.InstallmentService
+	reconciliationService *services.ReconciliationService
+	encryptionService     *services.EncryptionService
+	forecastService       *services.ForecastService
+	warehouseService      *services.WarehouseExportService
+	checkoutService       *services.CheckoutService
+	cartService           *services.CartService
+	orderService          *services.OrderService
+	notificationService   *services.NotificationService
+	newsletterService     *services.NewsletterService
+	bulkJobService        *services.BulkJobService
+	archiveService        *services.ArchiveService
+}
+
+// NewTicketingWorker creates a new ticketing worker
+func NewTicketingWorker(cfg *config.Config) *TicketingWorker {
+	redisOpts := redis.QueueConnOpt(cfg)
+
+	serverConfig := asynq.Config{
+		Concurrency: 5,
+		Queues: map[string]int{
+			redis.QueueName(services.TicketingQueue): 1,
+		},
+		RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+			if delay, ok := backpressureDelay(err); ok {
+				return delay
+			}
+			return time.Duration(n) * time.Minute // 1min, 2min, 3min, etc.
+		},
+		// Calls rejected by a circuit breaker are deferred without using up the task's retries
+		IsFailure: isTaskFailure,
+		ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
+			log.Printf("Ticketing task failed: %v, Error: %v", task.Type(), err)
+		}),
+	}
+
+	orderService := services.NewOrderService(cfg)
+	notificationService := services.NewNotificationService(cfg)
+	whatsAppReminderCron := ""
+	if notificationService.WhatsAppAvailable() {
+		whatsAppReminderCron = cfg.WhatsApp.ReminderCron
+	}
+	worker := &TicketingWorker{
+		server:                asynq.NewServer(redisOpts, serverConfig),
+		mux:                   asynq.NewServeMux(),
+		redisOpts:             redisOpts,
+		reconciliationCron:    cfg.Payment.ReconciliationCron,
+		forecastCron:          cfg.Forecast.RefreshCron,
+		warehouseCron:         cfg.Warehouse.ExportCron,
+		orderExpiryCron:       cfg.Order.ExpiryCron,
+		cartExpiryCron:        cfg.Checkout.CartExpiryCron,
+		whatsAppReminderCron:  whatsAppReminderCron,
+		archiveCron:           cfg.Archive.Cron,
+		allocationService:     services.NewAllocationService(cfg),
+		installmentService:    services.NewInstallmentService(cfg),
+		reconciliationService: services.NewReconciliationService(cfg),
+		encryptionService:     services.NewEncryptionService(cfg),
+		forecastService:       services.NewForecastService(cfg),
+		warehouseService:      services.NewWarehouseExportService(cfg),
+		checkoutService:       services.NewCheckoutService(cfg, orderService),
+		cartService:           services.NewCartService(cfg, orderService),
+		orderService:          orderService,
+		notificationService:   notificationService,
+		newsletterService:     services.NewNewsletterService(cfg),
+		bulkJobService:        services.NewBulkJobService(cfg),
+		archiveService:        services.NewArchiveService(cfg),
+	}
+
+	worker.mux.HandleFunc(services.TaskAllocationRelease, worker.handleAllocationRelease)
+	worker.mux.HandleFunc(services.TaskInstallmentCharge, worker.handleInstallmentCharge)
+	worker.mux.HandleFunc(services.TaskInstallmentDeadline, worker.handleInstallmentDeadline)
+	worker.mux.HandleFunc(services.TaskReconciliationRun, worker.handleReconciliationRun)
+	worker.mux.HandleFunc(services.TaskEncryptionRotate, worker.handleEncryptionRotate)
+	worker.mux.HandleFunc(services.TaskForecastRefresh, worker.handleForecastRefresh)
+	worker.mux.HandleFunc(services.TaskWarehouseExport, worker.handleWarehouseExport)
+	worker.mux.HandleFunc(services.TaskCheckoutReminder, worker.handleCheckoutReminder)
+	worker.mux.HandleFunc(services.TaskOrderExpiry, worker.handleOrderExpiry)
+	worker.mux.HandleFunc(services.TaskCartExpiry, worker.handleCartExpiry)
+	worker.mux.HandleFunc(services.TaskNotificationSMS, worker.handleNotificationSMS)
+	worker.mux.HandleFunc(services.TaskNotificationWhatsApp, worker.handleNotificationWhatsApp)
+	worker.mux.HandleFunc(services.TaskWhatsAppReminders, worker.handleWhatsAppReminders)
+	worker.mux.HandleFunc(services.TaskNewsletterSend, worker.handleNewsletterSend)
+	worker.mux.HandleFunc(services.TaskBulkJob, worker.handleBulkJob)
+	worker.mux.HandleFunc(services.TaskArchiveRun, worker.handleArchiveRun)
+
+	return worker
+}
+
+// handleAllocationRelease releases a hold whose scheduled release time has come
+func (w *TicketingWorker) handleAllocationRelease(ctx context.Context, task *asynq.Task) error {
+	var payload services.AllocationReleasePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal allocation release job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.allocationService.ReleaseScheduled(ctx, payload.AllocationID)
+}
+
+// handleInstallmentCharge charges a due installment of an order
+func (w *TicketingWorker) handleInstallmentCharge(ctx context.Context, task *asynq.Task) error {
+	var payload services.InstallmentChargePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal installment charge job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.installmentService.ChargeInstallment(ctx, payload.InstallmentID)
+}
+
+// handleInstallmentDeadline cancels an installment order that is not fully paid at event start
+func (w *TicketingWorker) handleInstallmentDeadline(ctx context.Context, task *asynq.Task) error {
+	var payload services.InstallmentDeadlinePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal installment deadline job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.installmentService.EnforceDeadline(ctx, payload.OrderID)
+}
+
+// handleReconciliationRun reconciles provider transactions with internal payments
+func (w *TicketingWorker) handleReconciliationRun(ctx context.Context, task *asynq.Task) error {
+	var payload services.ReconciliationPayload
+	if len(task.Payload()) > 0 {
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal reconciliation job: %w: %w", err, asynq.SkipRetry)
+		}
+	}
+
+	_, err := w.reconciliationService.Reconcile(ctx, payload.PeriodStart, payload.PeriodEnd)
+	return err
+}
+
+// handleEncryptionRotate re-encrypts personal data under the active encryption key
+func (w *TicketingWorker) handleEncryptionRotate(ctx context.Context, task *asynq.Task) error {
+	_, err := w.encryptionService.RotateKeys(ctx)
+	return err
+}
+
+// handleForecastRefresh recomputes the sales forecasts of upcoming events
+func (w *TicketingWorker) handleForecastRefresh(ctx context.Context, task *asynq.Task) error {
+	return w.forecastService.RefreshUpcoming(ctx)
+}
+
+// handleWarehouseExport exports a day of anonymized fact tables for the data warehouse
+func (w *TicketingWorker) handleWarehouseExport(ctx context.Context, task *asynq.Task) error {
+	var payload services.WarehouseExportPayload
+	if len(task.Payload()) > 0 {
+		if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+			return fmt.Errorf("failed to unmarshal warehouse export job: %w: %w", err, asynq.SkipRetry)
+		}
+	}
+	if payload.Date.IsZero() {
+		payload.Date = time.Now().UTC().AddDate(0, 0, -1)
+	}
+
+	return w.warehouseService.ExportDay(ctx, payload.Date)
+}
+
+// handleCheckoutReminder reminds a buyer of a checkout left incomplete
+func (w *TicketingWorker) handleCheckoutReminder(ctx context.Context, task *asynq.Task) error {
+	var payload services.CheckoutReminderPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal checkout reminder job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.checkoutService.SendReminder(ctx, payload.SessionID)
+}
+
+// handleOrderExpiry cancels orders left unpaid and puts their tickets back on sale
+func (w *TicketingWorker) handleOrderExpiry(ctx context.Context, task *asynq.Task) error {
+	return w.orderService.ExpirePendingOrders(ctx)
+}
+
+// handleArchiveRun moves the orders and tickets of events that ended years ago to the archive
+func (w *TicketingWorker) handleArchiveRun(ctx context.Context, task *asynq.Task) error {
+	_, err := w.archiveService.RunPolicy(ctx)
+	return err
+}
+
+// handleCartExpiry puts the tickets of lapsed carts back on sale
+func (w *TicketingWorker) handleCartExpiry(ctx context.Context, task *asynq.Task) error {
+	return w.cartService.ExpireCarts(ctx)
+}
+
+// handleNotificationSMS texts a ticket link to the phone number a buyer gave at checkout
+func (w *TicketingWorker) handleNotificationSMS(ctx context.Context, task *asynq.Task) error {
+	var payload services.NotificationSMSPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal ticket SMS job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.notificationService.SendTicketSMS(ctx, payload.NotificationID)
+}
+
+// handleNotificationWhatsApp sends a ticket or event reminder on WhatsApp to a user who opted in
+func (w *TicketingWorker) handleNotificationWhatsApp(ctx context.Context, task *asynq.Task) error {
+	var payload services.NotificationWhatsAppPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal WhatsApp job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.notificationService.SendWhatsApp(ctx, payload.NotificationID)
+}
+
+// handleWhatsAppReminders queues WhatsApp reminders of events starting soon
+func (w *TicketingWorker) handleWhatsAppReminders(ctx context.Context, task *asynq.Task) error {
+	return w.notificationService.QueueEventReminders(ctx)
+}
+
+// handleNewsletterSend sends a scheduled newsletter to the users of its segment
+func (w *TicketingWorker) handleNewsletterSend(ctx context.Context, task *asynq.Task) error {
+	var payload services.NewsletterSendPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal newsletter send job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.newsletterService.SendNewsletter(ctx, payload)
+}
+
+// handleBulkJob applies a queued bulk job to its items
+func (w *TicketingWorker) handleBulkJob(ctx context.Context, task *asynq.Task) error {
+	var payload services.BulkJobPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal bulk job: %w: %w", err, asynq.SkipRetry)
+	}
+
+	return w.bulkJobService.Process(ctx, payload.JobID)
+}
+
+// Start starts the ticketing worker
+func (w *TicketingWorker) Start() {
+	log.Println("Starting ticketing worker...")
+
+	go func() {
+		if err := w.server.Run(w.mux); err != nil {
+			log.Fatalf("Failed to start ticketing worker: %v", err)
+		}
+	}()
+
+	log.Println("Ticketing worker started successfully")
+}
+
+// StartScheduler starts enqueuing periodic jobs. The worker manager calls it only on the
+// replica elected leader, so each job is enqueued once per interval across the deployment.
+func (w *TicketingWorker) StartScheduler() {
+	w.schedulerMu.Lock()
+	defer w.schedulerMu.Unlock()
+
+	warehouseCron := ""
+	if w.warehouseService.Enabled() {
+		warehouseCron = w.warehouseCron
+	}
+	archiveCron := ""
+	if w.archiveService.Enabled() {
+		archiveCron = w.archiveCron
+	}
+	if w.scheduler != nil || (w.reconciliationCron == "" && w.forecastCron == "" && warehouseCron == "" && w.orderExpiryCron == "" && w.cartExpiryCron == "" && w.whatsAppReminderCron == "" && archiveCron == "") {
+		return
+	}
+
+	// A scheduler cannot be restarted after shutdown, so each term of leadership gets a new one
+	scheduler := asynq.NewScheduler(w.redisOpts, &asynq.SchedulerOpts{Location: time.UTC})
+
+	// Nightly reconciliation of the previous day's payments. Uniqueness guards against a
+	// second enqueue if leadership changes hands right at the scheduled time.
+	if w.reconciliationCron != "" {
+		task := asynq.NewTask(services.TaskReconciliationRun, nil)
+		if _, err := scheduler.Register(w.reconciliationCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(3), asynq.Unique(time.Hour)); err != nil {
+			log.Printf("Failed to schedule payment reconciliation: %v", err)
+			return
+		}
+	}
+
+	// Sales forecasts of upcoming events. A missed run is made up by the next one, so no retries.
+	if w.forecastCron != "" {
+		task := asynq.NewTask(services.TaskForecastRefresh, nil)
+		if _, err := scheduler.Register(w.forecastCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(0), asynq.Unique(10*time.Minute)); err != nil {
+			log.Printf("Failed to schedule forecast refresh: %v", err)
+			return
+		}
+	}
+
+	// Nightly export of the previous day's anonymized fact tables for the data warehouse
+	if warehouseCron != "" {
+		task := asynq.NewTask(services.TaskWarehouseExport, nil)
+		if _, err := scheduler.Register(warehouseCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(3), asynq.Unique(time.Hour)); err != nil {
+			log.Printf("Failed to schedule warehouse export: %v", err)
+			return
+		}
+	}
+
+	// Expiry of unpaid orders. A missed sweep is made up by the next one, so no retries.
+	if w.orderExpiryCron != "" {
+		task := asynq.NewTask(services.TaskOrderExpiry, nil)
+		if _, err := scheduler.Register(w.orderExpiryCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(0), asynq.Unique(10*time.Minute)); err != nil {
+			log.Printf("Failed to schedule order expiry: %v", err)
+			return
+		}
+	}
+
+	// Release of lapsed carts. Carts stay claimable until swept, so a missed sweep is made up by
+	// the next one.
+	if w.cartExpiryCron != "" {
+		task := asynq.NewTask(services.TaskCartExpiry, nil)
+		if _, err := scheduler.Register(w.cartExpiryCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(0), asynq.Unique(time.Minute)); err != nil {
+			log.Printf("Failed to schedule cart expiry: %v", err)
+			return
+		}
+	}
+
+	// WhatsApp reminders of events starting soon. Reminded orders are skipped, so a missed sweep is
+	// made up by the next one.
+	if w.whatsAppReminderCron != "" {
+		task := asynq.NewTask(services.TaskWhatsAppReminders, nil)
+		if _, err := scheduler.Register(w.whatsAppReminderCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(0), asynq.Unique(10*time.Minute)); err != nil {
+			log.Printf("Failed to schedule WhatsApp reminders: %v", err)
+			return
+		}
+	}
+
+	// Archival of past events. Each sweep archives a batch of the oldest events left, so a missed
+	// sweep is made up by the next one.
+	if archiveCron != "" {
+		task := asynq.NewTask(services.TaskArchiveRun, nil)
+		if _, err := scheduler.Register(archiveCron, task, asynq.Queue(redis.QueueName(services.TicketingQueue)), asynq.MaxRetry(0), asynq.Unique(time.Hour)); err != nil {
+			log.Printf("Failed to schedule event archival: %v", err)
+			return
+		}
+	}
+
+	if err := scheduler.Start(); err != nil {
+		log.Printf("Failed to start ticketing scheduler: %v", err)
+		return
+	}
+
+	w.scheduler = scheduler
+	log.Println("Ticketing scheduler started")
+}
+
+// StopScheduler stops enqueuing periodic jobs
+func (w *TicketingWorker) StopScheduler() {
+	w.schedulerMu.Lock()
+	defer w.schedulerMu.Unlock()
+
+	if w.scheduler == nil {
+		return
+	}
+	w.scheduler.Shutdown()
+	w.scheduler = nil
+	log.Println("Ticketing scheduler stopped")
+}
+
+// Stop stops the ticketing worker gracefully
+func (w *TicketingWorker) Stop() {
+	log.Println("Stopping ticketing worker...")
+	w.StopScheduler()
+	w.server.Shutdown()
+	log.Println("Ticketing worker stopped")
+}
@@ -1,23 +1,37 @@
 package workers
 
-// WorkerManager manages all background workers
+// WorkerManager manages all background workers. Every replica processes queued jobs, while
+// scheduled jobs run only on the replica elected leader so they are not enqueued twice.
 type WorkerManager struct {
-	EmailWorker *EmailWorker
+	EmailWorker       *EmailWorker
+	IntegrationWorker *IntegrationWorker
+	TicketingWorker   *TicketingWorker
+	elector           *LeaderElector
 }
 
 // NewWorkerManager creates a new worker manager and initializes all workers
-func NewWorkerManager(emailWorker *EmailWorker) *WorkerManager {
+func NewWorkerManager(emailWorker *EmailWorker, integrationWorker *IntegrationWorker, ticketingWorker *TicketingWorker) *WorkerManager {
 	return &WorkerManager{
-		EmailWorker: emailWorker,
+		EmailWorker:       emailWorker,
+		IntegrationWorker: integrationWorker,
+		TicketingWorker:   ticketingWorker,
+		elector:           NewLeaderElector(ticketingWorker.StartScheduler, ticketingWorker.StopScheduler),
 	}
 }
 
 // StartAll starts all background workers
 func (m *WorkerManager) StartAll() {
 	m.EmailWorker.Start()
+	m.IntegrationWorker.Start()
+	m.TicketingWorker.Start()
+	m.elector.Start()
 }
 
 // StopAll stops all background workers
 func (m *WorkerManager) StopAll() {
+	// Step down first so another replica takes over scheduled jobs without waiting for the lease to expire
+	m.elector.Stop()
 	m.EmailWorker.Stop()
+	m.IntegrationWorker.Stop()
+	m.TicketingWorker.Stop()
 }
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// errNoRefreshToken is returned when a refresh is needed but the client has no refresh token
+var errNoRefreshToken = errors.New("no refresh token available")
+
+// Login signs in with email and password and stores the issued tokens on the client
+func (c *Client) Login(ctx context.Context, email, password string) (*Tokens, error) {
+	var tokens Tokens
+	req := LoginRequest{Email: email, Password: password}
+	if err := c.do(ctx, http.MethodPost, "/auth/login", nil, req, &tokens, public()); err != nil {
+		return nil, err
+	}
+	c.SetTokens(tokens)
+	return &tokens, nil
+}
+
+// Register creates a user account. The account must verify its email before signing in.
+func (c *Client) Register(ctx context.Context, req RegisterRequest, opts ...RequestOption) (*User, error) {
+	var user User
+	if err := c.do(ctx, http.MethodPost, "/auth/register", nil, req, &user, append(opts, public())...); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Refresh exchanges the refresh token for a new token pair. The client also refreshes on its own
+// shortly before the access token expires and after a 401 response.
+func (c *Client) Refresh(ctx context.Context) (*Tokens, error) {
+	if err := c.refresh(ctx, c.Tokens().AccessToken); err != nil {
+		return nil, err
+	}
+	tokens := c.Tokens()
+	return &tokens, nil
+}
+
+// Logout revokes the current refresh token, or every refresh token of the user when all is set,
+// and clears the client's tokens
+func (c *Client) Logout(ctx context.Context, all bool) error {
+	query := url.Values{}
+	if all {
+		query.Set("all", "true")
+	}
+	if err := c.do(ctx, http.MethodPost, "/auth/logout", query, nil, nil); err != nil {
+		return err
+	}
+	c.SetTokens(Tokens{})
+	return nil
+}
+
+// Profile returns the signed-in user's profile
+func (c *Client) Profile(ctx context.Context) (*UserProfile, error) {
+	var profile UserProfile
+	if err := c.do(ctx, http.MethodGet, "/auth/profile", nil, nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UpdateProfile updates the signed-in user's profile
+func (c *Client) UpdateProfile(ctx context.Context, req UpdateProfileRequest) (*UserProfile, error) {
+	var profile UserProfile
+	if err := c.do(ctx, http.MethodPut, "/auth/profile", nil, req, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// PatchProfile fills in or changes only the set fields of the signed-in user's profile
+func (c *Client) PatchProfile(ctx context.Context, req PatchProfileRequest) (*UserProfile, error) {
+	var profile UserProfile
+	if err := c.do(ctx, http.MethodPatch, "/auth/profile", nil, req, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UsernameAvailable reports whether a username can be taken
+func (c *Client) UsernameAvailable(ctx context.Context, username string) (*UsernameAvailability, error) {
+	query := url.Values{}
+	query.Set("username", username)
+
+	var availability UsernameAvailability
+	if err := c.do(ctx, http.MethodGet, "/usernames/available", query, nil, &availability); err != nil {
+		return nil, err
+	}
+	return &availability, nil
+}
+
+// ChangeUsername takes, changes or, for an empty username, removes the signed-in user's username
+func (c *Client) ChangeUsername(ctx context.Context, username string) (*UserProfile, error) {
+	var profile UserProfile
+	if err := c.do(ctx, http.MethodPut, "/auth/username", nil, ChangeUsernameRequest{Username: username}, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// UsernameHistory returns the signed-in user's username changes, newest first
+func (c *Client) UsernameHistory(ctx context.Context) ([]UsernameChange, error) {
+	var changes []UsernameChange
+	if err := c.do(ctx, http.MethodGet, "/auth/username/history", nil, nil, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// PublicProfile returns the public profile behind a username
+func (c *Client) PublicProfile(ctx context.Context, username string) (*PublicProfile, error) {
+	var profile PublicProfile
+	if err := c.do(ctx, http.MethodGet, "/public/users/"+url.PathEscape(username), nil, nil, &profile); err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Limits returns the signed-in user's rate limit allowance and this month's usage
+func (c *Client) Limits(ctx context.Context) (*Limits, error) {
+	var limits Limits
+	if err := c.do(ctx, http.MethodGet, "/me/limits", nil, nil, &limits); err != nil {
+		return nil, err
+	}
+	return &limits, nil
+}
+
+// WhatsAppOptIn returns whether the signed-in user opted in to WhatsApp messages
+func (c *Client) WhatsAppOptIn(ctx context.Context) (*WhatsAppOptIn, error) {
+	var optIn WhatsAppOptIn
+	if err := c.do(ctx, http.MethodGet, "/me/whatsapp", nil, nil, &optIn); err != nil {
+		return nil, err
+	}
+	return &optIn, nil
+}
+
+// OptInWhatsApp opts the signed-in user in to receiving tickets and event reminders on WhatsApp
+func (c *Client) OptInWhatsApp(ctx context.Context, phone string) (*WhatsAppOptIn, error) {
+	var optIn WhatsAppOptIn
+	body := map[string]string{"phone": phone}
+	if err := c.do(ctx, http.MethodPut, "/me/whatsapp", nil, body, &optIn); err != nil {
+		return nil, err
+	}
+	return &optIn, nil
+}
+
+// OptOutWhatsApp withdraws the signed-in user's WhatsApp consent
+func (c *Client) OptOutWhatsApp(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/me/whatsapp", nil, nil, nil)
+}
+
+// accessToken returns the access token to send, refreshing it first when it is about to expire
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	tokens := c.Tokens()
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" || !expiresWithin(tokens.AccessToken, refreshLeeway) {
+		return tokens.AccessToken, nil
+	}
+	if err := c.refresh(ctx, tokens.AccessToken); err != nil {
+		return "", err
+	}
+	return c.Tokens().AccessToken, nil
+}
+
+// refresh replaces the stale access token with a new token pair. Callers that waited on another
+// goroutine's refresh reuse its result instead of rotating the tokens again.
+func (c *Client) refresh(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	current := c.Tokens()
+	if current.AccessToken != stale && current.AccessToken != "" {
+		return nil
+	}
+	if current.RefreshToken == "" {
+		return errNoRefreshToken
+	}
+
+	var tokens Tokens
+	req := refreshTokenRequest{RefreshToken: current.RefreshToken}
+	if err := c.do(ctx, http.MethodPost, "/auth/refresh", nil, req, &tokens, public()); err != nil {
+		return err
+	}
+	c.SetTokens(tokens)
+
+	if c.onTokenRefresh != nil {
+		c.onTokenRefresh(tokens)
+	}
+	return nil
+}
+
+// expiresWithin reports whether a JWT expires within d. Tokens that cannot be parsed are assumed
+// valid and left to the server to reject.
+func expiresWithin(token string, d time.Duration) bool {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return false
+	}
+	return time.Until(time.Unix(claims.Exp, 0)) < d
+}
@@ -0,0 +1,387 @@
+// Package client is a Go client for the Event Ticketing API. It wraps the REST endpoints with
+// typed methods, refreshes access tokens automatically, retries transient failures with
+// exponential backoff and sends an Idempotency-Key with every write so retries are safe.
+//
+//	c := client.New("https://tickets.example.com")
+//	if _, err := c.Login(ctx, "user@example.com", "secret"); err != nil {
+//		return err
+//	}
+//	events, err := c.ListEvents(ctx)
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	apiPrefix = "/api/v1"
+
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 3
+	defaultMinBackoff = 200 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+
+	// refreshLeeway refreshes access tokens shortly before they expire rather than after a 401
+	refreshLeeway = 30 * time.Second
+)
+
+// Client calls the Event Ticketing API. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	userAgent  string
+	deviceID   string
+	tenant     string
+
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu             sync.Mutex
+	tokens         Tokens
+	onTokenRefresh func(Tokens)
+
+	// refreshMu makes concurrent callers share one refresh instead of racing to rotate the token
+	refreshMu sync.Mutex
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTokens starts the client with previously issued tokens
+func WithTokens(tokens Tokens) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithTokenRefreshHandler registers a callback invoked with the new tokens after every refresh,
+// so callers can persist the rotated refresh token
+func WithTokenRefreshHandler(fn func(Tokens)) Option {
+	return func(c *Client) {
+		c.onTokenRefresh = fn
+	}
+}
+
+// WithRetries sets how often transient failures are retried and the backoff bounds between attempts
+func WithRetries(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithDeviceID sets the X-Device-ID header used by the API to recognize known devices at sign-in
+func WithDeviceID(deviceID string) Option {
+	return func(c *Client) {
+		c.deviceID = deviceID
+	}
+}
+
+// WithTenant sets the X-Tenant header selecting the white-label tenant requests are made for
+func WithTenant(slug string) Option {
+	return func(c *Client) {
+		c.tenant = slug
+	}
+}
+
+// New creates a client for the API served at baseURL, e.g. "https://tickets.example.com"
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "event-ticketing-go-client",
+		maxRetries: defaultMaxRetries,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Tokens returns the client's current tokens
+func (c *Client) Tokens() Tokens {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tokens
+}
+
+// SetTokens replaces the client's tokens
+func (c *Client) SetTokens(tokens Tokens) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tokens = tokens
+}
+
+// RequestOption configures a single request
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	idempotencyKey string
+	public         bool
+}
+
+// WithIdempotencyKey sets the Idempotency-Key of a write. Writes get a random key by default, which
+// protects retries within one call; pass a stable key to also deduplicate across process restarts.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// public marks an endpoint that must be called without credentials
+func public() RequestOption {
+	return func(o *requestOptions) {
+		o.public = true
+	}
+}
+
+// envelope is the standard response body of the API
+type envelope struct {
+	Success   bool            `json:"success"`
+	Message   string          `json:"message"`
+	Data      json.RawMessage `json:"data"`
+	Error     *errorInfo      `json:"error"`
+	RequestID string          `json:"request_id"`
+}
+
+type errorInfo struct {
+	Code    string                 `json:"code"`
+	Details string                 `json:"details"`
+	Fields  map[string]interface{} `json:"fields"`
+}
+
+// do sends a request to an /api/v1 path and decodes the response data into out. Transient
+// failures are retried; a 401 triggers one token refresh and retry.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}, opts ...RequestOption) error {
+	var o requestOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	// The same key is reused across retries so the server replays instead of repeating the write
+	if o.idempotencyKey == "" && (method == http.MethodPost || method == http.MethodPatch) {
+		o.idempotencyKey = newIdempotencyKey()
+	}
+
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, path, query, payload, o)
+		if err != nil {
+			// A failed token refresh is an API answer, not a transient network error
+			var apiErr *APIError
+			if errors.As(err, &apiErr) || ctx.Err() != nil || attempt >= c.maxRetries {
+				return err
+			}
+			if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && !o.public && !refreshed && c.Tokens().RefreshToken != "" {
+			drain(resp)
+			refreshed = true
+			if err := c.refresh(ctx, c.Tokens().AccessToken); err != nil {
+				return err
+			}
+			attempt--
+			continue
+		}
+
+		if isRetryableStatus(resp.StatusCode) && attempt < c.maxRetries {
+			delay := retryAfter(resp, c.backoff(attempt))
+			drain(resp)
+			if err := c.sleep(ctx, delay); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return decode(resp, out)
+	}
+}
+
+// send performs a single HTTP request
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, payload []byte, o requestOptions) (*http.Response, error) {
+	endpoint := c.baseURL + apiPrefix + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if o.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", o.idempotencyKey)
+	}
+	if c.deviceID != "" {
+		req.Header.Set("X-Device-ID", c.deviceID)
+	}
+	if c.tenant != "" {
+		req.Header.Set("X-Tenant", c.tenant)
+	}
+	if !o.public {
+		token, err := c.accessToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	return c.httpClient.Do(req)
+}
+
+// decode reads the response envelope, returning an *APIError for failed requests
+func decode(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var env envelope
+	if err := json.Unmarshal(raw, &env); err != nil {
+		if resp.StatusCode >= http.StatusBadRequest {
+			return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(raw))}
+		}
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest || !env.Success {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Message:    env.Message,
+			RequestID:  env.RequestID,
+		}
+		if env.Error != nil {
+			apiErr.Code = env.Error.Code
+			apiErr.Details = env.Error.Details
+			apiErr.Fields = env.Error.Fields
+		}
+		return apiErr
+	}
+
+	if out == nil || len(env.Data) == 0 || string(env.Data) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// isRetryableStatus reports whether a status indicates a transient failure
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before the given retry: exponential with full jitter, capped at maxBackoff
+func (c *Client) backoff(attempt int) time.Duration {
+	limit := float64(c.minBackoff) * math.Pow(2, float64(attempt))
+	if limit > float64(c.maxBackoff) {
+		limit = float64(c.maxBackoff)
+	}
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(mathrand.Int63n(int64(limit)) + 1)
+}
+
+// retryAfter honors the Retry-After header of a throttled response, falling back to the backoff delay
+func retryAfter(resp *http.Response, fallback time.Duration) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(resp.Header.Get("Retry-After")); err == nil {
+		if delay := time.Until(when); delay > 0 {
+			return delay
+		}
+	}
+	return fallback
+}
+
+// sleep waits for d or until the context is done
+func (c *Client) sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain discards a response body so the connection can be reused
+func drain(resp *http.Response) {
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	resp.Body.Close()
+}
+
+// newIdempotencyKey returns a random key for a write
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error codes returned by the API in the error.code field
+const (
+	CodeValidation   = "VALIDATION_ERROR"
+	CodeBadRequest   = "BAD_REQUEST"
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeForbidden    = "FORBIDDEN"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeRateLimit    = "RATE_LIMIT_EXCEEDED"
+	CodeInternal     = "INTERNAL_SERVER_ERROR"
+
+	// CodeInvalidID is returned for an ID in the path that is not a valid UUID; the error's
+	// Fields name the path parameter
+	CodeInvalidID = "INVALID_ID"
+
+	// CodeProfileIncomplete is returned for actions that need a complete profile; the error's
+	// Fields["missing"] lists the profile fields to fill in with PatchProfile
+	CodeProfileIncomplete = "PROFILE_INCOMPLETE"
+)
+
+// APIError is an error response returned by the API
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    string
+	Fields     map[string]interface{}
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Code != "" {
+		msg = fmt.Sprintf("%s (%s)", msg, e.Code)
+	}
+	if e.RequestID != "" {
+		return fmt.Sprintf("api error %d: %s [request %s]", e.StatusCode, msg, e.RequestID)
+	}
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, msg)
+}
+
+// IsCode reports whether err is an APIError with the given error code
+func IsCode(err error, code string) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Code == code
+}
+
+// IsNotFound reports whether err is an APIError for a missing resource
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,374 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ListEvents returns all events
+func (c *Client) ListEvents(ctx context.Context) ([]Event, error) {
+	var events []Event
+	if err := c.do(ctx, http.MethodGet, "/events", nil, nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// SearchEvents returns the published events matching the filter, soonest first
+func (c *Client) SearchEvents(ctx context.Context, filter EventFilter) ([]Event, error) {
+	query := url.Values{}
+	if filter.Query != "" {
+		query.Set("q", filter.Query)
+	}
+	if filter.Category != "" {
+		query.Set("category", filter.Category)
+	}
+	if filter.Status != "" {
+		query.Set("status", filter.Status)
+	}
+	if filter.StartsFrom != "" {
+		query.Set("starts_from", filter.StartsFrom)
+	}
+	if filter.StartsTo != "" {
+		query.Set("starts_to", filter.StartsTo)
+	}
+	if filter.MinPrice != nil {
+		query.Set("min_price", strconv.FormatFloat(*filter.MinPrice, 'f', -1, 64))
+	}
+	if filter.MaxPrice != nil {
+		query.Set("max_price", strconv.FormatFloat(*filter.MaxPrice, 'f', -1, 64))
+	}
+
+	var events []Event
+	if err := c.do(ctx, http.MethodGet, "/events", query, nil, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetEvent returns an event by ID
+func (c *Client) GetEvent(ctx context.Context, id uint) (*Event, error) {
+	var event Event
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/events/%d", id), nil, nil, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// CreateEvent creates an event as a draft, which goes on sale once published with PublishEvent.
+// Requires the organizer or admin role.
+func (c *Client) CreateEvent(ctx context.Context, req EventCreateRequest, opts ...RequestOption) (*Event, error) {
+	var event Event
+	if err := c.do(ctx, http.MethodPost, "/events", nil, req, &event, opts...); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// UpdateEvent updates an event. Requires the organizer or admin role.
+func (c *Client) UpdateEvent(ctx context.Context, id uint, req EventUpdateRequest) (*Event, error) {
+	var event Event
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/events/%d", id), nil, req, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// PublishEvent publishes a draft or paused event, applying its pending draft changes. Requires the
+// organizer or admin role.
+func (c *Client) PublishEvent(ctx context.Context, id uint) (*Event, error) {
+	return c.transitionEvent(ctx, id, "publish")
+}
+
+// PauseEvent halts the ticket sales of a published event until it is published again. Requires
+// the organizer or admin role.
+func (c *Client) PauseEvent(ctx context.Context, id uint) (*Event, error) {
+	return c.transitionEvent(ctx, id, "pause")
+}
+
+// CancelEvent cancels an event that is not completed. Requires the organizer or admin role.
+func (c *Client) CancelEvent(ctx context.Context, id uint) (*Event, error) {
+	return c.transitionEvent(ctx, id, "cancel")
+}
+
+// CompleteEvent closes a published or paused event that has started. Requires the organizer or
+// admin role.
+func (c *Client) CompleteEvent(ctx context.Context, id uint) (*Event, error) {
+	return c.transitionEvent(ctx, id, "complete")
+}
+
+func (c *Client) transitionEvent(ctx context.Context, id uint, action string) (*Event, error) {
+	var event Event
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/events/%d/%s", id, action), nil, nil, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// DeleteEvent deletes an event. Requires the organizer or admin role.
+func (c *Client) DeleteEvent(ctx context.Context, id uint) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/events/%d", id), nil, nil, nil)
+}
+
+// GetEventHistory returns the recorded versions of an event, newest first. Requires the organizer
+// or admin role.
+func (c *Client) GetEventHistory(ctx context.Context, id uint) ([]EventVersion, error) {
+	var versions []EventVersion
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/events/%d/history", id), nil, nil, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// CreateScannerPairing generates a short-lived code for a door scanner to pair to an event.
+// Only the event's organizer can pair scanners.
+func (c *Client) CreateScannerPairing(ctx context.Context, eventID uint, name string) (*ScannerPairing, error) {
+	var pairing ScannerPairing
+	body := map[string]string{"name": name}
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/events/%d/scanner-pairings", eventID), nil, body, &pairing); err != nil {
+		return nil, err
+	}
+	return &pairing, nil
+}
+
+// PairScanner exchanges a pairing code for a device token. Scanner apps then use the token with
+// SetTokens; it is accepted only by the check-in endpoints of the paired event.
+func (c *Client) PairScanner(ctx context.Context, code, appVersion string) (*ScannerToken, error) {
+	var token ScannerToken
+	body := map[string]string{"code": code, "app_version": appVersion}
+	if err := c.do(ctx, http.MethodPost, "/scanner/pair", nil, body, &token, public()); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ListScannerDevices returns the scanners paired to an event
+func (c *Client) ListScannerDevices(ctx context.Context, eventID uint) ([]ScannerDevice, error) {
+	var devices []ScannerDevice
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/events/%d/scanner-devices", eventID), nil, nil, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// RevokeScannerDevice unpairs a scanner; its token stops working immediately
+func (c *Client) RevokeScannerDevice(ctx context.Context, eventID uint, deviceID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/events/%d/scanner-devices/%s", eventID, deviceID), nil, nil, nil)
+}
+
+// CheckIn checks the holder of a ticket in to an event. Requires the ticket scan permission.
+func (c *Client) CheckIn(ctx context.Context, eventID uint, req CheckInRequest) (*CheckIn, error) {
+	var checkIn CheckIn
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/events/%d/checkins", eventID), nil, req, &checkIn); err != nil {
+		return nil, err
+	}
+	return &checkIn, nil
+}
+
+// UndoCheckIn returns a checked-in ticket to valid
+func (c *Client) UndoCheckIn(ctx context.Context, eventID uint, ticketID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/events/%d/checkins/%s", eventID, ticketID), nil, nil, nil)
+}
+
+// GetAttendance returns an event's attendance counted from its tickets
+func (c *Client) GetAttendance(ctx context.Context, eventID uint) (*Attendance, error) {
+	var attendance Attendance
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/events/%d/checkins/stats", eventID), nil, nil, &attendance); err != nil {
+		return nil, err
+	}
+	return &attendance, nil
+}
+
+// RollbackEvent restores an event's details from an earlier version. Requires the organizer or
+// admin role.
+func (c *Client) RollbackEvent(ctx context.Context, id uint, version int) (*Event, error) {
+	var event Event
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/events/%d/history/%d/rollback", id, version), nil, nil, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// GetEventForecast returns an event's projected sales, sell-out time and attendance. Requires the
+// organizer or admin role.
+func (c *Client) GetEventForecast(ctx context.Context, id uint) (*EventForecast, error) {
+	var forecast EventForecast
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/events/%d/forecast", id), nil, nil, &forecast); err != nil {
+		return nil, err
+	}
+	return &forecast, nil
+}
+
+// PublicStats returns the platform-wide totals shown on the marketing site. No sign-in is required.
+func (c *Client) PublicStats(ctx context.Context) (*PublicStats, error) {
+	var stats PublicStats
+	if err := c.do(ctx, http.MethodGet, "/public/stats", nil, nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// TenantConfig returns the branding of the tenant selected with WithTenant, or of the default brand
+func (c *Client) TenantConfig(ctx context.Context) (*TenantConfig, error) {
+	var tenant TenantConfig
+	if err := c.do(ctx, http.MethodGet, "/public/tenant", nil, nil, &tenant); err != nil {
+		return nil, err
+	}
+	return &tenant, nil
+}
+
+// QuoteOrder prices ticket selections with fees and taxes before checkout. Nothing is reserved.
+func (c *Client) QuoteOrder(ctx context.Context, req OrderQuoteRequest) (*OrderQuote, error) {
+	var quote OrderQuote
+	if err := c.do(ctx, http.MethodPost, "/orders/quote", nil, req, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// ResendTickets resends the ticket emails of an order placed by or for the signed-in user
+func (c *Client) ResendTickets(ctx context.Context, orderID uuid.UUID) (*TicketResend, error) {
+	var resend TicketResend
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/orders/%s/resend-tickets", orderID), nil, nil, &resend); err != nil {
+		return nil, err
+	}
+	return &resend, nil
+}
+
+// RequestRefund asks the organizer to refund tickets of an order placed by or for the signed-in user
+func (c *Client) RequestRefund(ctx context.Context, orderID uuid.UUID, req RefundRequest) (*Refund, error) {
+	var refund Refund
+	if err := c.do(ctx, http.MethodPost, fmt.Sprintf("/orders/%s/refunds", orderID), nil, req, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// ListOrderRefunds returns the refunds of an order placed by or for the signed-in user
+func (c *Client) ListOrderRefunds(ctx context.Context, orderID uuid.UUID) ([]Refund, error) {
+	var refunds []Refund
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/orders/%s/refunds", orderID), nil, nil, &refunds); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// StartCheckout saves a checkout in progress. Keep the returned resume token to resume it later.
+func (c *Client) StartCheckout(ctx context.Context, req CheckoutSessionRequest) (*CheckoutSession, error) {
+	var session CheckoutSession
+	if err := c.do(ctx, http.MethodPost, "/checkout/sessions", nil, req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ResumeCheckout returns a saved checkout priced at current prices
+func (c *Client) ResumeCheckout(ctx context.Context, token string) (*CheckoutSession, error) {
+	var session CheckoutSession
+	if err := c.do(ctx, http.MethodGet, "/checkout/sessions/"+url.PathEscape(token), nil, nil, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// UpdateCheckout replaces the selections and contact details of an open checkout
+func (c *Client) UpdateCheckout(ctx context.Context, token string, req CheckoutSessionRequest) (*CheckoutSession, error) {
+	var session CheckoutSession
+	if err := c.do(ctx, http.MethodPut, "/checkout/sessions/"+url.PathEscape(token), nil, req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// HoldCheckoutSeats holds seats of an event with a seat map for an open checkout, replacing the
+// seats held for the event before. An empty selection releases them.
+func (c *Client) HoldCheckoutSeats(ctx context.Context, token string, eventID uint, seatIDs []uuid.UUID) (*SeatHold, error) {
+	var hold SeatHold
+	body := map[string]interface{}{"event_id": eventID, "seat_ids": seatIDs}
+	if err := c.do(ctx, http.MethodPut, "/checkout/sessions/"+url.PathEscape(token)+"/seats", nil, body, &hold); err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// GetEventSeats returns the seat map of an event with reserved seating
+func (c *Client) GetEventSeats(ctx context.Context, eventID uint) (*SeatMap, error) {
+	var seatMap SeatMap
+	if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/events/%d/seats", eventID), nil, nil, &seatMap); err != nil {
+		return nil, err
+	}
+	return &seatMap, nil
+}
+
+// AttachSeatMap puts the seats of a venue on sale for an event, before any ticket is sold
+func (c *Client) AttachSeatMap(ctx context.Context, eventID uint, venueID uuid.UUID) (*SeatMap, error) {
+	var seatMap SeatMap
+	body := map[string]uuid.UUID{"venue_id": venueID}
+	if err := c.do(ctx, http.MethodPut, fmt.Sprintf("/events/%d/seat-map", eventID), nil, body, &seatMap); err != nil {
+		return nil, err
+	}
+	return &seatMap, nil
+}
+
+// CreateCart holds ticket selections for the signed-in buyer until the cart expires. promoCode
+// may be empty.
+func (c *Client) CreateCart(ctx context.Context, items []OrderQuoteItem, promoCode string) (*Cart, error) {
+	var cart Cart
+	body := map[string]interface{}{"items": items, "promo_code": promoCode}
+	if err := c.do(ctx, http.MethodPost, "/carts", nil, body, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetCart returns a cart of the signed-in buyer
+func (c *Client) GetCart(ctx context.Context, cartID uuid.UUID) (*Cart, error) {
+	var cart Cart
+	if err := c.do(ctx, http.MethodGet, "/carts/"+cartID.String(), nil, nil, &cart); err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// ReleaseCart empties a cart and puts its tickets back on sale
+func (c *Client) ReleaseCart(ctx context.Context, cartID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/carts/"+cartID.String(), nil, nil, nil)
+}
+
+// CheckoutCart converts a cart into pending orders, one per event
+func (c *Client) CheckoutCart(ctx context.Context, cartID uuid.UUID) (*CartCheckout, error) {
+	var checkout CartCheckout
+	if err := c.do(ctx, http.MethodPost, "/carts/"+cartID.String()+"/checkout", nil, nil, &checkout); err != nil {
+		return nil, err
+	}
+	return &checkout, nil
+}
+
+// GetManagedTicket returns the ticket a ticket email link's token grants access to. No sign-in is required.
+func (c *Client) GetManagedTicket(ctx context.Context, token string) (*ManagedTicket, error) {
+	var ticket ManagedTicket
+	if err := c.do(ctx, http.MethodGet, "/tickets/manage/"+url.PathEscape(token), nil, nil, &ticket); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// UpdateTicketAttendee corrects the attendee name of the ticket a token grants access to
+func (c *Client) UpdateTicketAttendee(ctx context.Context, token, attendeeName string) (*ManagedTicket, error) {
+	var ticket ManagedTicket
+	body := map[string]string{"attendee_name": attendeeName}
+	if err := c.do(ctx, http.MethodPut, "/tickets/manage/"+url.PathEscape(token)+"/attendee", nil, body, &ticket); err != nil {
+		return nil, err
+	}
+	return &ticket, nil
+}
+
+// ResendTicketEmail sends the ticket email of the ticket a token grants access to again
+func (c *Client) ResendTicketEmail(ctx context.Context, token string) error {
+	return c.do(ctx, http.MethodPost, "/tickets/manage/"+url.PathEscape(token)+"/resend", nil, nil, nil)
+}
@@ -0,0 +1,355 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ListOrganizations returns the organizations the signed-in user belongs to
+func (c *Client) ListOrganizations(ctx context.Context) ([]Organization, error) {
+	var orgs []Organization
+	if err := c.do(ctx, http.MethodGet, "/organizations", nil, nil, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// GetOrganization returns an organization by ID
+func (c *Client) GetOrganization(ctx context.Context, orgID uuid.UUID) (*Organization, error) {
+	var org Organization
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String(), nil, nil, &org); err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// ListChildOrganizations returns the direct sub-organizations of an organization
+func (c *Client) ListChildOrganizations(ctx context.Context, orgID uuid.UUID) ([]Organization, error) {
+	var orgs []Organization
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/children", nil, nil, &orgs); err != nil {
+		return nil, err
+	}
+	return orgs, nil
+}
+
+// GetOrganizationRollup returns the paid order totals of an organization and all its
+// sub-organizations. from and to are inclusive days formatted as YYYY-MM-DD and may be empty.
+func (c *Client) GetOrganizationRollup(ctx context.Context, orgID uuid.UUID, from, to string) (*OrganizationRollup, error) {
+	var rollup OrganizationRollup
+	query := url.Values{}
+	if from != "" {
+		query.Set("from", from)
+	}
+	if to != "" {
+		query.Set("to", to)
+	}
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/analytics/rollup", query, nil, &rollup); err != nil {
+		return nil, err
+	}
+	return &rollup, nil
+}
+
+// CreateStaffOrder records a box office sale or invoice order and issues its tickets
+func (c *Client) CreateStaffOrder(ctx context.Context, orgID uuid.UUID, req StaffOrderRequest, opts ...RequestOption) (*OrderDetail, error) {
+	var order OrderDetail
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/orders", nil, req, &order, opts...); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// QuoteInsurance prices ticket insurance for quantity tickets of an event
+func (c *Client) QuoteInsurance(ctx context.Context, orgID uuid.UUID, eventID uint, quantity int) (*InsuranceQuote, error) {
+	var quote InsuranceQuote
+	query := url.Values{}
+	query.Set("event_id", strconv.FormatUint(uint64(eventID), 10))
+	query.Set("quantity", strconv.Itoa(quantity))
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/orders/insurance-quote", query, nil, &quote); err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// MarkOrderPaid marks an invoice order as paid
+func (c *Client) MarkOrderPaid(ctx context.Context, orgID, orderID uuid.UUID, opts ...RequestOption) (*Order, error) {
+	var order Order
+	path := "/organizations/" + orgID.String() + "/orders/" + orderID.String() + "/mark-paid"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &order, opts...); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrderNotifications returns the ticket emails and texts sent for an order with their delivery status
+func (c *Client) ListOrderNotifications(ctx context.Context, orgID, orderID uuid.UUID) ([]Notification, error) {
+	var notifications []Notification
+	path := "/organizations/" + orgID.String() + "/orders/" + orderID.String() + "/notifications"
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &notifications); err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// ValidateTickets checks in a batch of scanned ticket codes for an event and returns a result per
+// code in the order given
+func (c *Client) ValidateTickets(ctx context.Context, orgID uuid.UUID, req TicketValidationRequest) (*TicketValidation, error) {
+	var validation TicketValidation
+	path := "/organizations/" + orgID.String() + "/tickets/validate/batch"
+	if err := c.do(ctx, http.MethodPost, path, nil, req, &validation); err != nil {
+		return nil, err
+	}
+	return &validation, nil
+}
+
+// ValidateTicket checks in the ticket of one scanned QR code for an event
+func (c *Client) ValidateTicket(ctx context.Context, req TicketScanRequest) (*TicketScan, error) {
+	var scan TicketScan
+	if err := c.do(ctx, http.MethodPost, "/tickets/validate", nil, req, &scan); err != nil {
+		return nil, err
+	}
+	return &scan, nil
+}
+
+// PollNewOrders returns orders created after cursor, oldest first. Pass an empty cursor to start
+// from the beginning and a limit of 0 for the server default.
+func (c *Client) PollNewOrders(ctx context.Context, orgID uuid.UUID, cursor string, limit int) (*Page[Order], error) {
+	var page Page[Order]
+	path := "/organizations/" + orgID.String() + "/integrations/orders"
+	if err := c.do(ctx, http.MethodGet, path, pollingQuery(cursor, limit), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// PollNewAttendees returns attendees created after cursor, oldest first
+func (c *Client) PollNewAttendees(ctx context.Context, orgID uuid.UUID, cursor string, limit int) (*Page[Attendee], error) {
+	var page Page[Attendee]
+	path := "/organizations/" + orgID.String() + "/integrations/attendees"
+	if err := c.do(ctx, http.MethodGet, path, pollingQuery(cursor, limit), nil, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+func pollingQuery(cursor string, limit int) url.Values {
+	query := url.Values{}
+	if cursor != "" {
+		query.Set("since", cursor)
+	}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+	return query
+}
+
+// ListScannerDevicesOfOrganization returns the scanners paired to an organization's events,
+// leaving out revoked ones when activeOnly is set
+func (c *Client) ListScannerDevicesOfOrganization(ctx context.Context, orgID uuid.UUID, activeOnly bool) ([]ScannerDevice, error) {
+	var query url.Values
+	if activeOnly {
+		query = url.Values{"active": {"true"}}
+	}
+	var devices []ScannerDevice
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/scanner-devices", query, nil, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// RenameScannerDevice changes the label of an organization's scanner
+func (c *Client) RenameScannerDevice(ctx context.Context, orgID, deviceID uuid.UUID, name string) (*ScannerDevice, error) {
+	var device ScannerDevice
+	body := map[string]string{"name": name}
+	if err := c.do(ctx, http.MethodPatch, "/organizations/"+orgID.String()+"/scanner-devices/"+deviceID.String(), nil, body, &device); err != nil {
+		return nil, err
+	}
+	return &device, nil
+}
+
+// RevokeOrganizationScannerDevice cuts an organization's scanner off from check-ins immediately
+func (c *Client) RevokeOrganizationScannerDevice(ctx context.Context, orgID, deviceID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/organizations/"+orgID.String()+"/scanner-devices/"+deviceID.String(), nil, nil, nil)
+}
+
+// CreateVenue defines a venue of an organization with its seat map
+func (c *Client) CreateVenue(ctx context.Context, orgID uuid.UUID, req VenueRequest) (*Venue, error) {
+	var venue Venue
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/venues", nil, req, &venue); err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+// ListVenues returns an organization's venues, without their seat maps
+func (c *Client) ListVenues(ctx context.Context, orgID uuid.UUID) ([]Venue, error) {
+	var venues []Venue
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/venues", nil, nil, &venues); err != nil {
+		return nil, err
+	}
+	return venues, nil
+}
+
+// GetVenue returns a venue of an organization with its seat map
+func (c *Client) GetVenue(ctx context.Context, orgID, venueID uuid.UUID) (*Venue, error) {
+	var venue Venue
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/venues/"+venueID.String(), nil, nil, &venue); err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+// ListAssignablePermissions returns the permissions custom organization roles can be composed from
+func (c *Client) ListAssignablePermissions(ctx context.Context, orgID uuid.UUID) ([]Permission, error) {
+	var permissions []Permission
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/permissions", nil, nil, &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// ListOrganizationRoles returns the custom roles of an organization
+func (c *Client) ListOrganizationRoles(ctx context.Context, orgID uuid.UUID) ([]OrganizationRole, error) {
+	var roles []OrganizationRole
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/roles", nil, nil, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// CreateOrganizationRole creates a custom role of an organization
+func (c *Client) CreateOrganizationRole(ctx context.Context, orgID uuid.UUID, req OrganizationRoleRequest) (*OrganizationRole, error) {
+	var role OrganizationRole
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/roles", nil, req, &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// UpdateOrganizationRole renames a custom role and replaces its permissions
+func (c *Client) UpdateOrganizationRole(ctx context.Context, orgID, roleID uuid.UUID, req OrganizationRoleRequest) (*OrganizationRole, error) {
+	var role OrganizationRole
+	if err := c.do(ctx, http.MethodPut, "/organizations/"+orgID.String()+"/roles/"+roleID.String(), nil, req, &role); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// DeleteOrganizationRole deletes a custom role, taking it away from its members
+func (c *Client) DeleteOrganizationRole(ctx context.Context, orgID, roleID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/organizations/"+orgID.String()+"/roles/"+roleID.String(), nil, nil, nil)
+}
+
+// AssignOrganizationRole gives a member of the organization a custom role
+func (c *Client) AssignOrganizationRole(ctx context.Context, orgID, roleID, userID uuid.UUID) error {
+	body := map[string]uuid.UUID{"user_id": userID}
+	return c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/roles/"+roleID.String()+"/members", nil, body, nil)
+}
+
+// UnassignOrganizationRole takes a custom role away from a member
+func (c *Client) UnassignOrganizationRole(ctx context.Context, orgID, roleID, userID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/organizations/"+orgID.String()+"/roles/"+roleID.String()+"/members/"+userID.String(), nil, nil, nil)
+}
+
+// ListPromoCodes returns an organization's promo codes, optionally only those covering an event (0 for all)
+func (c *Client) ListPromoCodes(ctx context.Context, orgID uuid.UUID, eventID uint) ([]PromoCode, error) {
+	query := url.Values{}
+	if eventID != 0 {
+		query.Set("event_id", strconv.FormatUint(uint64(eventID), 10))
+	}
+
+	var promos []PromoCode
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/promo-codes", query, nil, &promos); err != nil {
+		return nil, err
+	}
+	return promos, nil
+}
+
+// CreatePromoCode adds a promo code to an organization
+func (c *Client) CreatePromoCode(ctx context.Context, orgID uuid.UUID, req PromoCodeRequest) (*PromoCode, error) {
+	var promo PromoCode
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/promo-codes", nil, req, &promo); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// GetPromoCode returns a promo code with its usage
+func (c *Client) GetPromoCode(ctx context.Context, orgID, promoCodeID uuid.UUID) (*PromoCode, error) {
+	var promo PromoCode
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/promo-codes/"+promoCodeID.String(), nil, nil, &promo); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// UpdatePromoCode replaces the settings of a promo code
+func (c *Client) UpdatePromoCode(ctx context.Context, orgID, promoCodeID uuid.UUID, req PromoCodeRequest) (*PromoCode, error) {
+	var promo PromoCode
+	if err := c.do(ctx, http.MethodPut, "/organizations/"+orgID.String()+"/promo-codes/"+promoCodeID.String(), nil, req, &promo); err != nil {
+		return nil, err
+	}
+	return &promo, nil
+}
+
+// DeletePromoCode deletes a promo code
+func (c *Client) DeletePromoCode(ctx context.Context, orgID, promoCodeID uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/organizations/"+orgID.String()+"/promo-codes/"+promoCodeID.String(), nil, nil, nil)
+}
+
+// RequestStaffRefund records a refund of an organization's order for approval
+func (c *Client) RequestStaffRefund(ctx context.Context, orgID, orderID uuid.UUID, req RefundRequest) (*Refund, error) {
+	var refund Refund
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/orders/"+orderID.String()+"/refunds", nil, req, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// ListRefunds returns an organization's refunds, optionally of one order and/or in one status
+func (c *Client) ListRefunds(ctx context.Context, orgID uuid.UUID, orderID *uuid.UUID, status string) ([]Refund, error) {
+	query := url.Values{}
+	if orderID != nil {
+		query.Set("order_id", orderID.String())
+	}
+	if status != "" {
+		query.Set("status", status)
+	}
+
+	var refunds []Refund
+	if err := c.do(ctx, http.MethodGet, "/organizations/"+orgID.String()+"/refunds", query, nil, &refunds); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// ApproveRefund approves a requested refund and processes it
+func (c *Client) ApproveRefund(ctx context.Context, orgID, refundID uuid.UUID, note string) (*Refund, error) {
+	return c.reviewRefund(ctx, orgID, refundID, "approve", note)
+}
+
+// RejectRefund rejects a requested refund
+func (c *Client) RejectRefund(ctx context.Context, orgID, refundID uuid.UUID, note string) (*Refund, error) {
+	return c.reviewRefund(ctx, orgID, refundID, "reject", note)
+}
+
+// RetryRefund processes a failed refund again
+func (c *Client) RetryRefund(ctx context.Context, orgID, refundID uuid.UUID) (*Refund, error) {
+	var refund Refund
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/refunds/"+refundID.String()+"/process", nil, nil, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+func (c *Client) reviewRefund(ctx context.Context, orgID, refundID uuid.UUID, decision, note string) (*Refund, error) {
+	var refund Refund
+	body := map[string]string{"note": note}
+	if err := c.do(ctx, http.MethodPost, "/organizations/"+orgID.String()+"/refunds/"+refundID.String()+"/"+decision, nil, body, &refund); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
@@ -0,0 +1,844 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tokens is an access and refresh token pair
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LoginRequest is the request body for signing in
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RegisterRequest is the request body for creating an account. The name and phone can be
+// completed later with PatchProfile.
+type RegisterRequest struct {
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	Username  string `json:"username,omitempty"`
+}
+
+// UpdateProfileRequest is the request body for updating the signed-in user's profile
+type UpdateProfileRequest struct {
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	Phone       string `json:"phone,omitempty"`
+	DateOfBirth string `json:"date_of_birth,omitempty"` // YYYY-MM-DD
+}
+
+// PatchProfileRequest is the request body for completing the signed-in user's profile. Only the
+// fields set are updated.
+type PatchProfileRequest struct {
+	FirstName   *string `json:"first_name,omitempty"`
+	LastName    *string `json:"last_name,omitempty"`
+	Phone       *string `json:"phone,omitempty"`
+	DateOfBirth *string `json:"date_of_birth,omitempty"` // YYYY-MM-DD
+}
+
+// ChangeUsernameRequest is the request body for changing the signed-in user's username. An empty
+// username removes it.
+type ChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+// UsernameAvailability tells whether a username can be taken and, if not, why
+type UsernameAvailability struct {
+	Username  string `json:"username"`
+	Available bool   `json:"available"`
+	Reason    string `json:"reason,omitempty"` // "taken", "reserved" or "recently_used"
+}
+
+// UsernameChange is a past change of the signed-in user's username
+type UsernameChange struct {
+	ID          uuid.UUID `json:"id"`
+	OldUsername string    `json:"old_username,omitempty"`
+	NewUsername string    `json:"new_username,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// PublicProfile is what anyone can see of a user through their username
+type PublicProfile struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	MemberSince time.Time `json:"member_since"`
+	Renamed     bool      `json:"renamed"` // Requested through a previous username
+}
+
+// Permission is a permission granted by a role
+type Permission struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Resource    string    `json:"resource"`
+	Action      string    `json:"action"`
+}
+
+// Role is a role assigned to a user
+type Role struct {
+	ID          uuid.UUID    `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Permissions []Permission `json:"permissions,omitempty"`
+}
+
+// User is a user account
+type User struct {
+	ID              uuid.UUID         `json:"id"`
+	Email           string            `json:"email"`
+	Username        string            `json:"username,omitempty"`
+	FirstName       string            `json:"first_name"`
+	LastName        string            `json:"last_name"`
+	Phone           string            `json:"phone"`
+	IsEmailVerified bool              `json:"is_email_verified"`
+	ProfileComplete bool              `json:"profile_complete"`
+	MissingFields   []string          `json:"missing_profile_fields,omitempty"`
+	AvatarURLs      map[string]string `json:"avatar_urls,omitempty"` // Avatar path per size in pixels
+	OrganizationID  *uuid.UUID        `json:"organization_id,omitempty"`
+	Organization    *Organization     `json:"organization,omitempty"`
+	CreatedBy       *uuid.UUID        `json:"created_by,omitempty"`
+	Roles           []Role            `json:"roles"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// UserProfile is the signed-in user's profile
+type UserProfile struct {
+	ID              uuid.UUID         `json:"id"`
+	Email           string            `json:"email"`
+	Username        string            `json:"username,omitempty"`
+	FirstName       string            `json:"first_name"`
+	LastName        string            `json:"last_name"`
+	Phone           string            `json:"phone"`
+	DateOfBirth     string            `json:"date_of_birth,omitempty"`
+	IsEmailVerified bool              `json:"is_email_verified"`
+	ProfileComplete bool              `json:"profile_complete"`
+	MissingFields   []string          `json:"missing_profile_fields,omitempty"`
+	AvatarURLs      map[string]string `json:"avatar_urls,omitempty"` // Avatar path per size in pixels
+	OrganizationID  *uuid.UUID        `json:"organization_id,omitempty"`
+	Organization    *Organization     `json:"organization,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// RateLimit is the caller's standing against the API rate limit
+type RateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// Usage counts the caller's metered activity in one calendar month
+type Usage struct {
+	Period        string `json:"period"`
+	EmailsSent    int64  `json:"emails_sent"`
+	EventsCreated int64  `json:"events_created"`
+}
+
+// Limits is the caller's rate limit and usage
+type Limits struct {
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
+	Usage     Usage      `json:"usage"`
+}
+
+// Organization is an organization that runs events
+type Organization struct {
+	ID                 uuid.UUID  `json:"id"`
+	Name               string     `json:"name"`
+	Description        string     `json:"description"`
+	LogoURL            string     `json:"logo_url"`
+	WebsiteURL         string     `json:"website_url"`
+	OrganizerID        uuid.UUID  `json:"organizer_id"`
+	ParentID           *uuid.UUID `json:"parent_id,omitempty"` // Parent organization, if any
+	InheritPermissions bool       `json:"inherit_permissions"` // Whether ancestors' organizers may manage it
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// OrganizationSales is the paid order totals of an organization in one currency
+type OrganizationSales struct {
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+	Depth          int        `json:"depth"`
+	Currency       string     `json:"currency"` // Empty for an organization without sales
+	Orders         int        `json:"orders"`
+	Tickets        int        `json:"tickets"`
+	GrossAmount    float64    `json:"gross_amount"`
+	FeeAmount      float64    `json:"fee_amount"`
+	RefundedAmount float64    `json:"refunded_amount"`
+	NetAmount      float64    `json:"net_amount"`
+}
+
+// OrganizationRollup is the sales of an organization and its sub-organizations. Totals holds one
+// entry per currency, without organization fields.
+type OrganizationRollup struct {
+	OrganizationID uuid.UUID           `json:"organization_id"`
+	From           string              `json:"from,omitempty"`
+	To             string              `json:"to,omitempty"`
+	Organizations  []OrganizationSales `json:"organizations"`
+	Totals         []OrganizationSales `json:"totals"`
+}
+
+// TenantColors is a brand's color scheme as CSS hex colors
+type TenantColors struct {
+	Primary    string `json:"primary"`
+	Secondary  string `json:"secondary"`
+	Background string `json:"background"`
+	Text       string `json:"text"`
+}
+
+// TenantConfig is the branding of a white-label frontend
+type TenantConfig struct {
+	Slug         string       `json:"slug,omitempty"` // Empty for the default brand
+	AppName      string       `json:"app_name"`
+	SupportEmail string       `json:"support_email"`
+	Colors       TenantColors `json:"colors"`
+}
+
+// Event is a ticketed event
+type Event struct {
+	ID           uint         `json:"id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Location     string       `json:"location"`
+	StartDate    time.Time    `json:"start_date"`
+	EndDate      time.Time    `json:"end_date"`
+	Price        float64      `json:"price"`
+	Capacity     int          `json:"capacity"`
+	Available    int          `json:"available"`
+	Status       string       `json:"status"` // "draft", "published", "paused", "cancelled" or "completed"
+	WaitlistOpen bool         `json:"waitlist_open"`
+	CoverURL     string       `json:"cover_url"`
+	Performer    string       `json:"performer"`
+	Category     string       `json:"category"`
+	OrganizerID  *uuid.UUID   `json:"organizer_id,omitempty"`
+	VenueID      *uuid.UUID   `json:"venue_id,omitempty"` // Venue whose seat map is attached; seats are selected when buying
+	RefundPolicy RefundPolicy `json:"refund_policy"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// EventFilter narrows SearchEvents; empty fields are ignored. Days are formatted as YYYY-MM-DD
+// and both are inclusive.
+type EventFilter struct {
+	Query      string // Text in the title, performer or location
+	Category   string
+	Status     string // "published", "paused", "cancelled" or "completed"
+	StartsFrom string
+	StartsTo   string
+	MinPrice   *float64
+	MaxPrice   *float64
+}
+
+// RefundPolicy is an event's refund terms
+type RefundPolicy struct {
+	Type       string  `json:"type"`        // "flexible", "until_days_before" or "none"
+	DaysBefore int     `json:"days_before"` // For "until_days_before": refunds close this many days before the start
+	FeePercent float64 `json:"fee_percent"` // Share of a refund withheld as a fee
+}
+
+// EventCreateRequest is the request body for creating an event
+type EventCreateRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	StartDate   time.Time `json:"start_date"`
+	EndDate     time.Time `json:"end_date"`
+	Price       float64   `json:"price"`
+	Capacity    int       `json:"capacity"`
+	CoverURL    string    `json:"cover_url,omitempty"`
+	Performer   string    `json:"performer,omitempty"`
+	Category    string    `json:"category,omitempty"`
+	// Defaults to flexible refunds until the event starts
+	RefundPolicy *RefundPolicy `json:"refund_policy,omitempty"`
+}
+
+// EventUpdateRequest is the request body for updating an event. Zero fields are left unchanged.
+type EventUpdateRequest struct {
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+	Price       float64    `json:"price,omitempty"`
+	Capacity    int        `json:"capacity,omitempty"`
+	CoverURL    string     `json:"cover_url,omitempty"`
+	Performer   string     `json:"performer,omitempty"`
+	Category    string     `json:"category,omitempty"`
+	// Replaces the whole policy when set
+	RefundPolicy *RefundPolicy `json:"refund_policy,omitempty"`
+}
+
+// EventVersion is a recorded version of an event's details
+type EventVersion struct {
+	ID              uuid.UUID          `json:"id"`
+	EventID         uint               `json:"event_id"`
+	Version         int                `json:"version"`
+	Action          string             `json:"action"` // "created", "updated", "published", "status", "rolled_back" or "baseline"
+	ChangedBy       *uuid.UUID         `json:"changed_by,omitempty"`
+	RestoredVersion *int               `json:"restored_version,omitempty"`
+	Snapshot        EventSnapshot      `json:"snapshot"`
+	Changes         []EventFieldChange `json:"changes"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+// EventSnapshot holds an event's organizer-editable details at one version
+type EventSnapshot struct {
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Location     string       `json:"location"`
+	StartDate    time.Time    `json:"start_date"`
+	EndDate      time.Time    `json:"end_date"`
+	Price        float64      `json:"price"`
+	Capacity     int          `json:"capacity"`
+	Status       string       `json:"status"`
+	CoverURL     string       `json:"cover_url"`
+	Performer    string       `json:"performer"`
+	Category     string       `json:"category"`
+	RefundPolicy RefundPolicy `json:"refund_policy"`
+}
+
+// EventFieldChange is a field whose value differs from the previous version
+type EventFieldChange struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// EventForecast estimates how an event's sales will end up
+type EventForecast struct {
+	EventID            uint       `json:"event_id"`
+	Capacity           int        `json:"capacity"`
+	Sold               int        `json:"sold"`
+	ProjectedSold      int        `json:"projected_sold"`
+	SoldOut            bool       `json:"sold_out"`
+	SellOutAt          *time.Time `json:"sell_out_at,omitempty"` // Nil when the event is not expected to sell out
+	ExpectedAttendance int        `json:"expected_attendance"`
+	ShowUpRate         float64    `json:"show_up_rate"`
+	Basis              string     `json:"basis"` // "similar_events" or "current_pace"
+	SimilarEvents      int        `json:"similar_events"`
+	ComputedAt         time.Time  `json:"computed_at"`
+}
+
+// PublicStats are platform-wide totals across all organizations
+type PublicStats struct {
+	EventsHosted        int64     `json:"events_hosted"`
+	TicketsIssued       int64     `json:"tickets_issued"`
+	OrganizersOnboarded int64     `json:"organizers_onboarded"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// Order is a ticket order
+type Order struct {
+	ID              uuid.UUID  `json:"id"`
+	EventID         uint       `json:"event_id"`
+	OrganizationID  *uuid.UUID `json:"organization_id,omitempty"`
+	BuyerEmail      string     `json:"buyer_email"`
+	BuyerName       string     `json:"buyer_name"`
+	Quantity        int        `json:"quantity"`
+	TotalAmount     float64    `json:"total_amount"`
+	FeeAmount       float64    `json:"fee_amount"`
+	InsuranceAmount float64    `json:"insurance_amount"` // Ticket insurance premium included in the total
+	RefundedAmount  float64    `json:"refunded_amount"`
+	Currency        string     `json:"currency"`
+	Status          string     `json:"status"`
+	PaymentMethod   string     `json:"payment_method"`
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// OrderDetail is an order with its tickets
+type OrderDetail struct {
+	Order
+	Tickets   []Attendee      `json:"tickets"`
+	Insurance *OrderInsurance `json:"insurance,omitempty"`
+}
+
+// OrderInsurance is the ticket insurance bought with an order
+type OrderInsurance struct {
+	ID            uuid.UUID  `json:"id"`
+	Provider      string     `json:"provider"`
+	PolicyRef     string     `json:"policy_ref,omitempty"`
+	Premium       float64    `json:"premium"`
+	CoveredAmount float64    `json:"covered_amount"`
+	Currency      string     `json:"currency"`
+	Status        string     `json:"status"` // "pending", "bound", "failed" or "cancelled"
+	BoundAt       *time.Time `json:"bound_at,omitempty"`
+}
+
+// OrderQuoteItem is a ticket selection to price
+type OrderQuoteItem struct {
+	EventID  uint `json:"event_id"`
+	Quantity int  `json:"quantity"`
+}
+
+// OrderQuoteRequest is the request body for pricing ticket selections
+type OrderQuoteRequest struct {
+	Items     []OrderQuoteItem `json:"items"`
+	PromoCode string           `json:"promo_code,omitempty"`
+	Currency  string           `json:"currency,omitempty"`
+}
+
+// OrderQuoteLine is the price of one ticket selection
+type OrderQuoteLine struct {
+	EventID        uint       `json:"event_id"`
+	Title          string     `json:"title"`
+	Quantity       int        `json:"quantity"`
+	UnitPrice      float64    `json:"unit_price"`
+	Amount         float64    `json:"amount"`
+	PricingRuleID  *uuid.UUID `json:"pricing_rule_id,omitempty"`
+	DiscountAmount float64    `json:"discount_amount"`
+	PromoCodeID    *uuid.UUID `json:"promo_code_id,omitempty"`
+}
+
+// OrderQuoteDiscount is a discount applied to a quote
+type OrderQuoteDiscount struct {
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+}
+
+// OrderQuote is the price breakdown of ticket selections before checkout
+type OrderQuote struct {
+	Currency       string               `json:"currency"`
+	Lines          []OrderQuoteLine     `json:"lines"`
+	Subtotal       float64              `json:"subtotal"`
+	Discounts      []OrderQuoteDiscount `json:"discounts"`
+	DiscountAmount float64              `json:"discount_amount"`
+	FeeAmount      float64              `json:"fee_amount"`
+	TaxAmount      float64              `json:"tax_amount"`
+	Total          float64              `json:"total"`
+}
+
+// CheckoutSessionRequest is the request body for starting or updating a checkout
+type CheckoutSessionRequest struct {
+	Items          []OrderQuoteItem `json:"items"`
+	PromoCode      string           `json:"promo_code,omitempty"`
+	Currency       string           `json:"currency,omitempty"`
+	Email          string           `json:"email"`
+	Name           string           `json:"name,omitempty"`
+	MarketingOptIn bool             `json:"marketing_opt_in"` // Consent to a reminder if the checkout is left incomplete
+}
+
+// CheckoutSession is a buyer's checkout in progress
+type CheckoutSession struct {
+	ID             uuid.UUID        `json:"id"`
+	ResumeToken    string           `json:"resume_token,omitempty"` // Only returned when the session is started
+	Email          string           `json:"email"`
+	Name           string           `json:"name"`
+	Items          []OrderQuoteItem `json:"items"`
+	PromoCode      string           `json:"promo_code,omitempty"`
+	MarketingOptIn bool             `json:"marketing_opt_in"`
+	Status         string           `json:"status"` // "open" or "completed"
+	Quote          *OrderQuote      `json:"quote,omitempty"`
+	QuoteError     string           `json:"quote_error,omitempty"`
+	ExpiresAt      time.Time        `json:"expires_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+// Cart is a signed-in buyer's temporary hold on tickets
+type Cart struct {
+	ID        uuid.UUID        `json:"id"`
+	UserID    uuid.UUID        `json:"user_id"`
+	Items     []OrderQuoteItem `json:"items"`
+	PromoCode string           `json:"promo_code,omitempty"`
+	ExpiresAt time.Time        `json:"expires_at"`
+	CreatedAt time.Time        `json:"created_at"`
+	Quote     *OrderQuote      `json:"quote,omitempty"` // Only returned when the cart is created
+}
+
+// CartCheckout is the orders a cart was converted into, one per event
+type CartCheckout struct {
+	CartID uuid.UUID     `json:"cart_id"`
+	Orders []OrderDetail `json:"orders"`
+}
+
+// InsuranceQuote is the price of insuring tickets, offered to the attendee before ordering
+type InsuranceQuote struct {
+	Provider      string    `json:"provider"`
+	Premium       float64   `json:"premium"`
+	CoveredAmount float64   `json:"covered_amount"`
+	Currency      string    `json:"currency"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	TermsURL      string    `json:"terms_url,omitempty"`
+}
+
+// Attendee is a ticket and its holder
+type Attendee struct {
+	TicketID       uuid.UUID  `json:"ticket_id"`
+	OrderID        uuid.UUID  `json:"order_id"`
+	EventID        uint       `json:"event_id"`
+	OrganizationID *uuid.UUID `json:"organization_id,omitempty"`
+	SeatID         *uuid.UUID `json:"seat_id,omitempty"`
+	Name           string     `json:"name"`
+	Email          string     `json:"email"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// StaffOrderRequest is the request body for a box office sale or invoice order
+type StaffOrderRequest struct {
+	EventID        uint        `json:"event_id"`
+	Quantity       int         `json:"quantity"`
+	AttendeeName   string      `json:"attendee_name"`
+	AttendeeEmail  string      `json:"attendee_email"`
+	PaymentMethod  string      `json:"payment_method"` // "invoice" or "cash"
+	MarketingOptIn bool        `json:"marketing_opt_in"`
+	Insurance      bool        `json:"insurance,omitempty"` // Add ticket insurance to the order
+	SMSPhone       string      `json:"sms_phone,omitempty"` // Also text a link to each ticket to this number
+	SeatIDs        []uuid.UUID `json:"seat_ids,omitempty"`  // One seat per ticket, required at events with a seat map
+}
+
+// TicketValidationRequest is the request body for checking in scanned tickets
+type TicketValidationRequest struct {
+	EventID uint     `json:"event_id"`
+	Gate    string   `json:"gate,omitempty"` // Entrance the scanner is at
+	Codes   []string `json:"codes"`          // Payloads read from ticket QR codes, or ticket IDs
+}
+
+// TicketScanRequest is the request body for checking in one scanned ticket QR code
+type TicketScanRequest struct {
+	EventID uint   `json:"event_id"`
+	Gate    string `json:"gate,omitempty"` // Entrance the scanner is at
+	Code    string `json:"code"`           // Payload read from the ticket's QR code
+}
+
+// TicketScan is the result for one scanned code: "admitted", "already_checked_in", "duplicate",
+// "cancelled", "wrong_event", "not_found" or "invalid_code"
+type TicketScan struct {
+	Code         string     `json:"code"`
+	Result       string     `json:"result"`
+	TicketID     *uuid.UUID `json:"ticket_id,omitempty"`
+	AttendeeName string     `json:"attendee_name,omitempty"`
+}
+
+// TicketValidation is the result of a batch of scanned codes, in the order they were sent
+type TicketValidation struct {
+	Results  []TicketScan `json:"results"`
+	Admitted int          `json:"admitted"`
+}
+
+// ScannerPairing is a pairing code for a door scanner device
+type ScannerPairing struct {
+	DeviceID  uuid.UUID `json:"device_id"`
+	Code      string    `json:"code"`    // For typing into the device
+	QRCode    string    `json:"qr_code"` // Payload to show as a QR code for the device to scan
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ScannerToken is the device token of a paired scanner
+type ScannerToken struct {
+	DeviceID  uuid.UUID `json:"device_id"`
+	EventID   uint      `json:"event_id"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ScannerDevice is a door scanner paired to an event
+type ScannerDevice struct {
+	ID               uuid.UUID  `json:"id"`
+	EventID          uint       `json:"event_id"`
+	OrganizationID   *uuid.UUID `json:"organization_id,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	AppVersion       string     `json:"app_version,omitempty"`
+	PairingExpiresAt time.Time  `json:"pairing_expires_at"`
+	PairedAt         *time.Time `json:"paired_at,omitempty"`
+	TokenExpiresAt   *time.Time `json:"token_expires_at,omitempty"`
+	LastSeenAt       *time.Time `json:"last_seen_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CheckInRequest is the request body for checking an attendee in by ticket
+type CheckInRequest struct {
+	TicketID uuid.UUID `json:"ticket_id"`
+	Gate     string    `json:"gate,omitempty"` // Entrance the attendee came through
+}
+
+// CheckIn is an attendee's entry to an event
+type CheckIn struct {
+	ID          uuid.UUID  `json:"id"`
+	TicketID    uuid.UUID  `json:"ticket_id"`
+	EventID     uint       `json:"event_id"`
+	Gate        string     `json:"gate,omitempty"`
+	CheckedInBy *uuid.UUID `json:"checked_in_by,omitempty"`
+	CheckedInAt time.Time  `json:"checked_in_at"`
+	UndoneAt    *time.Time `json:"undone_at,omitempty"`
+}
+
+// Attendance is an event's attendance counted from its tickets
+type Attendance struct {
+	EventID       uint             `json:"event_id"`
+	Capacity      int              `json:"capacity"`
+	Issued        int64            `json:"issued"`
+	CheckedIn     int64            `json:"checked_in"`
+	NotArrived    int64            `json:"not_arrived"`
+	Undone        int64            `json:"undone"`
+	Gates         map[string]int64 `json:"gates"`
+	LastCheckInAt *time.Time       `json:"last_check_in_at,omitempty"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+}
+
+// Page is one page of a cursor-paginated feed. Pass NextCursor as the cursor of the next call.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ManagedTicket is a ticket as shown on its no-login ticket page
+type ManagedTicket struct {
+	TicketID           uuid.UUID  `json:"ticket_id"`
+	Status             string     `json:"status"` // "valid", "checked_in" or "cancelled"
+	AttendeeName       string     `json:"attendee_name"`
+	AttendeeEmail      string     `json:"attendee_email"`
+	EventID            uint       `json:"event_id"`
+	EventTitle         string     `json:"event_title"`
+	EventLocation      string     `json:"event_location"`
+	StartDate          time.Time  `json:"start_date"`
+	EndDate            time.Time  `json:"end_date"`
+	CheckedInAt        *time.Time `json:"checked_in_at,omitempty"`
+	QRCode             string     `json:"qr_code"` // Payload to show as a QR code at the door
+	NameChangeAllowed  bool       `json:"name_change_allowed"`
+	NameChangesLeft    int        `json:"name_changes_left"`
+	NameChangeDeadline time.Time  `json:"name_change_deadline"`
+}
+
+// Notification is the delivery of a ticket email, text or WhatsApp message
+type Notification struct {
+	ID          uuid.UUID  `json:"id"`
+	Channel     string     `json:"channel"` // "email", "sms" or "whatsapp"
+	Type        string     `json:"type"`
+	Recipient   string     `json:"recipient"`
+	OrderID     *uuid.UUID `json:"order_id,omitempty"`
+	TicketID    *uuid.UUID `json:"ticket_id,omitempty"`
+	Status      string     `json:"status"` // "queued", "sent" or "failed"
+	Attempts    int        `json:"attempts"`
+	ProviderRef string     `json:"provider_ref,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// WhatsAppOptIn is the signed-in user's consent to WhatsApp messages
+type WhatsAppOptIn struct {
+	OptedIn   bool       `json:"opted_in"`
+	Phone     string     `json:"phone,omitempty"`
+	OptedInAt *time.Time `json:"opted_in_at,omitempty"`
+}
+
+// TicketResend is the outcome of resending an order's ticket emails
+type TicketResend struct {
+	OrderID       uuid.UUID `json:"order_id"`
+	TicketsResent int       `json:"tickets_resent"`
+	ResendsLeft   *int      `json:"resends_left,omitempty"` // Resends still allowed this hour, when counted
+}
+
+// RefundRequest requests a refund of an order. Without tickets or an amount the whole order is
+// refunded. TicketIDs refunds and cancels those tickets; Amount alone, for staff only, returns part
+// of the money and keeps the tickets valid.
+type RefundRequest struct {
+	TicketIDs []uuid.UUID `json:"ticket_ids,omitempty"`
+	Amount    *float64    `json:"amount,omitempty"`
+	Reason    string      `json:"reason"`
+}
+
+// Refund is a refund of an order: requested, then approved and processed, or rejected.
+// Failed refunds can be retried.
+type Refund struct {
+	ID             uuid.UUID    `json:"id"`
+	OrderID        uuid.UUID    `json:"order_id"`
+	OrganizationID *uuid.UUID   `json:"organization_id,omitempty"`
+	EventID        uint         `json:"event_id"`
+	Amount         float64      `json:"amount"`
+	PremiumAmount  float64      `json:"premium_amount"` // Insurance premium returned when the refund closed the order
+	ManualAmount   float64      `json:"manual_amount"`  // Part to be settled offline, e.g. cash payments
+	Currency       string       `json:"currency"`
+	Reason         string       `json:"reason"`
+	Status         string       `json:"status"` // requested, approved, processed, rejected or failed
+	Items          []RefundItem `json:"items,omitempty"`
+	RequestedBy    uuid.UUID    `json:"requested_by"`
+	ReviewedBy     *uuid.UUID   `json:"reviewed_by,omitempty"`
+	ReviewNote     string       `json:"review_note,omitempty"`
+	ReviewedAt     *time.Time   `json:"reviewed_at,omitempty"`
+	RefundRefs     []string     `json:"refund_refs,omitempty"`
+	Attempts       int          `json:"attempts"`
+	Error          string       `json:"error,omitempty"`
+	ProcessedAt    *time.Time   `json:"processed_at,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// RefundItem is a ticket cancelled by a refund and its share of the refunded amount
+type RefundItem struct {
+	TicketID uuid.UUID `json:"ticket_id"`
+	Amount   float64   `json:"amount"`
+}
+
+// PromoCodeRequest is the request body for creating or updating a promo code
+type PromoCodeRequest struct {
+	Code            string     `json:"code"`
+	Description     string     `json:"description,omitempty"`
+	DiscountType    string     `json:"discount_type"` // percentage or fixed
+	Value           float64    `json:"value"`
+	EventIDs        []uint     `json:"event_ids,omitempty"` // Empty for every event of the organization
+	MaxUses         int        `json:"max_uses,omitempty"`  // 0 for unlimited
+	MaxUsesPerBuyer int        `json:"max_uses_per_buyer,omitempty"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	Active          *bool      `json:"active,omitempty"` // Defaults to true
+}
+
+// PromoCode is a discount code buyers enter at checkout
+type PromoCode struct {
+	ID              uuid.UUID  `json:"id"`
+	Code            string     `json:"code"`
+	Description     string     `json:"description"`
+	DiscountType    string     `json:"discount_type"`
+	Value           float64    `json:"value"`
+	EventIDs        []uint     `json:"event_ids"`
+	MaxUses         int        `json:"max_uses"`
+	MaxUsesPerBuyer int        `json:"max_uses_per_buyer"`
+	UsedCount       int        `json:"used_count"`
+	StartsAt        *time.Time `json:"starts_at,omitempty"`
+	EndsAt          *time.Time `json:"ends_at,omitempty"`
+	Active          bool       `json:"active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// VenueRequest is the request body for defining a venue and its seat map
+type VenueRequest struct {
+	Name     string           `json:"name"`
+	Address  string           `json:"address,omitempty"`
+	Sections []SectionRequest `json:"sections"`
+}
+
+// SectionRequest is a section of a venue being defined
+type SectionRequest struct {
+	Name string       `json:"name"`
+	Rows []RowRequest `json:"rows"`
+}
+
+// RowRequest is a row of a section being defined. Set either SeatCount, for seats labelled 1 to
+// SeatCount, or Seats.
+type RowRequest struct {
+	Label     string        `json:"label"`
+	SeatCount int           `json:"seat_count,omitempty"`
+	Seats     []SeatRequest `json:"seats,omitempty"`
+}
+
+// SeatRequest is a seat of a row being defined
+type SeatRequest struct {
+	Label      string `json:"label"`
+	Accessible bool   `json:"accessible"`
+}
+
+// OrganizationRoleRequest creates or updates a custom organization role. Permissions are
+// permission names such as "read:order".
+type OrganizationRoleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+// OrganizationRole is a custom role of an organization and the members holding it
+type OrganizationRole struct {
+	ID             uuid.UUID    `json:"id"`
+	OrganizationID uuid.UUID    `json:"organization_id"`
+	Name           string       `json:"name"`
+	Description    string       `json:"description"`
+	Permissions    []Permission `json:"permissions"`
+	MemberIDs      []uuid.UUID  `json:"member_ids"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// Venue is a place with reserved seating. Sections are only returned by GetVenue.
+type Venue struct {
+	ID             uuid.UUID  `json:"id"`
+	OrganizationID uuid.UUID  `json:"organization_id"`
+	Name           string     `json:"name"`
+	Address        string     `json:"address,omitempty"`
+	Sections       []Section  `json:"sections,omitempty"`
+	CreatedBy      *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Section is an area of a venue
+type Section struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	Position int       `json:"position"`
+	Rows     []Row     `json:"rows,omitempty"`
+}
+
+// Row is a row of seats in a section
+type Row struct {
+	ID       uuid.UUID `json:"id"`
+	Label    string    `json:"label"`
+	Position int       `json:"position"`
+	Seats    []Seat    `json:"seats,omitempty"`
+}
+
+// Seat is a single seat of a row
+type Seat struct {
+	ID         uuid.UUID `json:"id"`
+	Label      string    `json:"label"`
+	Position   int       `json:"position"`
+	Accessible bool      `json:"accessible"`
+}
+
+// SeatMap is the seat map of an event with the availability of each seat
+type SeatMap struct {
+	EventID   uint             `json:"event_id"`
+	VenueID   uuid.UUID        `json:"venue_id"`
+	VenueName string           `json:"venue_name"`
+	Available int              `json:"available"`
+	Sections  []SeatMapSection `json:"sections"`
+}
+
+// SeatMapSection is a section of an event's seat map
+type SeatMapSection struct {
+	Name string       `json:"name"`
+	Rows []SeatMapRow `json:"rows"`
+}
+
+// SeatMapRow is a row of an event's seat map
+type SeatMapRow struct {
+	Label string        `json:"label"`
+	Seats []SeatMapSeat `json:"seats"`
+}
+
+// SeatMapSeat is a seat of an event's seat map
+type SeatMapSeat struct {
+	ID         uuid.UUID `json:"id"`
+	Section    string    `json:"section,omitempty"` // Only set on seats held by a checkout
+	Row        string    `json:"row,omitempty"`
+	Label      string    `json:"label"`
+	Accessible bool      `json:"accessible"`
+	Status     string    `json:"status"` // "available", "held" or "sold"
+}
+
+// SeatHold is the seats a checkout holds for an event
+type SeatHold struct {
+	EventID   uint          `json:"event_id"`
+	Seats     []SeatMapSeat `json:"seats"`
+	HeldUntil *time.Time    `json:"held_until,omitempty"`
+}
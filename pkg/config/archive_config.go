@@ -0,0 +1,17 @@
+package config
+
+// ArchiveConfig defines when the orders and tickets of past events are moved out of the hot tables
+type ArchiveConfig struct {
+	AfterYears int    // Years after an event ends before it is archived; 0 disables the policy
+	Cron       string // Cron spec of the archival sweep (UTC); empty disables it
+	BatchSize  int    // Events archived per sweep, each in its own transaction
+}
+
+// Add archive config to main config
+func (c *Config) AddArchiveConfig() {
+	c.Archive = ArchiveConfig{
+		AfterYears: getEnvAsInt("ARCHIVE_AFTER_YEARS", 3),
+		Cron:       getEnv("ARCHIVE_CRON", "0 4 * * 0"),
+		BatchSize:  getEnvAsInt("ARCHIVE_BATCH_SIZE", 50),
+	}
+}
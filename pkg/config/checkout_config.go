@@ -0,0 +1,32 @@
+package config
+
+import "time"
+
+// CheckoutConfig defines the charges added to ticket prices at checkout, how incomplete
+// checkouts are followed up and how long carts hold tickets
+type CheckoutConfig struct {
+	ServiceFeePercent   float64       // Service fee as a percentage of the discounted ticket amount
+	ServiceFeePerTicket float64       // Flat service fee per ticket
+	TaxPercent          float64       // Sales tax as a percentage of the discounted ticket amount
+	SessionTTL          time.Duration // How long an incomplete checkout can be resumed
+	ReminderDelay       time.Duration // Time after starting a checkout at which an incomplete one is reminded of
+	ResumeURL           string        // Frontend page resuming a checkout; the resume token is appended as the token query parameter
+	SeatHoldTTL         time.Duration // How long seats selected in a checkout are held for it
+	CartTTL             time.Duration // How long a cart holds its tickets before they go back on sale
+	CartExpiryCron      string        // Cron spec of the sweep releasing lapsed carts (UTC); empty disables it
+}
+
+// Add checkout config to main config
+func (c *Config) AddCheckoutConfig() {
+	c.Checkout = CheckoutConfig{
+		ServiceFeePercent:   getEnvAsFloat("CHECKOUT_SERVICE_FEE_PERCENT", 0),
+		ServiceFeePerTicket: getEnvAsFloat("CHECKOUT_SERVICE_FEE_PER_TICKET", 0),
+		TaxPercent:          getEnvAsFloat("CHECKOUT_TAX_PERCENT", 0),
+		SessionTTL:          parseDuration(getEnv("CHECKOUT_SESSION_TTL", "72h")),
+		ReminderDelay:       parseDuration(getEnv("CHECKOUT_REMINDER_DELAY", "3h")),
+		ResumeURL:           getEnv("CHECKOUT_RESUME_URL", "http://localhost:3000/checkout/resume"),
+		SeatHoldTTL:         parseDuration(getEnv("CHECKOUT_SEAT_HOLD_TTL", "10m")),
+		CartTTL:             parseDuration(getEnv("CHECKOUT_CART_TTL", "10m")),
+		CartExpiryCron:      getEnv("CHECKOUT_CART_EXPIRY_CRON", "* * * * *"),
+	}
+}
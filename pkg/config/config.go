@@ -10,12 +10,36 @@ import (
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Server   ServerConfig
-	JWT      JWTConfig
-	SMTP     SMTPConfig
+	App             AppConfig
+	Database        DatabaseConfig
+	Redis           RedisConfig
+	Server          ServerConfig
+	JWT             JWTConfig
+	SMTP            SMTPConfig
+	EmailAttachment EmailAttachmentConfig
+	Payment         PaymentConfig
+	OTP             OTPConfig
+	Security        SecurityConfig
+	Resilience      ResilienceConfig
+	ImageProxy      ImageProxyConfig
+	Scan            ScanConfig
+	Forecast        ForecastConfig
+	Warehouse       WarehouseConfig
+	Stats           StatsConfig
+	Insurance       InsuranceConfig
+	Tenant          TenantConfig
+	Feed            FeedConfig
+	Checkout        CheckoutConfig
+	Order           OrderConfig
+	Portal          PortalConfig
+	SMS             SMSConfig
+	WhatsApp        WhatsAppConfig
+	Wallet          WalletConfig
+	Invitation      InvitationConfig
+	Newsletter      NewsletterConfig
+	EmailTracking   EmailTrackingConfig
+	OAuth           OAuthConfig
+	Archive         ArchiveConfig
 }
 
 type AppConfig struct {
@@ -33,19 +57,49 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+
+	SkipMigrations bool // Start without running migrations; they run as a separate job (e.g. "ticketctl migrate")
 }
 
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       string
+	Mode             string // Topology: standalone, sentinel or cluster
+	Host             string // Standalone server host
+	Port             int    // Standalone server port
+	Addrs            string // Comma-separated sentinel addresses in sentinel mode, seed nodes in cluster mode
+	MasterName       string // Name of the master monitored by the sentinels
+	Password         string
+	SentinelPassword string        // Password of the sentinels themselves, when it differs from the master's
+	DB               int           // Default logical database of every store
+	KeyPrefix        string        // Namespace of every key, queue and pub/sub channel, e.g. "ticketing:staging:", so environments can share a server
+	OperationTimeout time.Duration // Read and write timeout of a single command
+
+	// Each use of Redis gets its own logical database and key prefix, defaulting to DB and no prefix.
+	// Store prefixes are added after KeyPrefix.
+	Cache     RedisStoreConfig // Caches, carts, idempotency keys, live statistics and locks
+	OTP       RedisStoreConfig // One-time passwords
+	RateLimit RedisStoreConfig // Send quotas and resend cooldowns
+	Queue     RedisStoreConfig // Background job queues; asynq names its own keys, so only KeyPrefix applies, to the queue names
 }
 
+// RedisStoreConfig separates one use of Redis from the others. Cluster mode only has database 0,
+// so stores sharing a cluster are told apart by their key prefix.
+type RedisStoreConfig struct {
+	DB     int
+	Prefix string
+}
+
+// Redis topologies
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
 type ServerConfig struct {
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	ReadTimeout    time.Duration
+	WriteTimeout   time.Duration
+	IdleTimeout    time.Duration
+	RequestTimeout time.Duration // Deadline for the database and Redis work of one request
 }
 
 func Load() (*Config, error) {
@@ -79,12 +133,21 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", "postgres"),
 			DBName:   getEnv("DB_NAME", "event_ticketing"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+
+			SkipMigrations: getEnv("DB_SKIP_MIGRATIONS", "false") == "true",
 		},
 		Redis: RedisConfig{
-			Host:     getEnv("REDIS_HOST", "localhost"),
-			Port:     getEnvAsInt("REDIS_PORT", 6379),
-			Password: getEnv("REDIS_PASSWORD", ""),
-			DB:       getEnv("REDIS_DB", "0"),
+			Mode:             getEnv("REDIS_MODE", RedisStandalone),
+			Host:             getEnv("REDIS_HOST", "localhost"),
+			Port:             getEnvAsInt("REDIS_PORT", 6379),
+			Addrs:            getEnv("REDIS_ADDRS", ""),
+			MasterName:       getEnv("REDIS_SENTINEL_MASTER", "mymaster"),
+			Password:         getEnv("REDIS_PASSWORD", ""),
+			SentinelPassword: getEnv("REDIS_SENTINEL_PASSWORD", ""),
+			DB:               getEnvAsInt("REDIS_DB", 0),
+			KeyPrefix:        getEnv("REDIS_KEY_PREFIX", ""),
+
+			OperationTimeout: parseDuration(getEnv("REDIS_OPERATION_TIMEOUT", "3s")),
 		},
 		Server: ServerConfig{
 			ReadTimeout:  parseDuration(getEnv("SERVER_READ_TIMEOUT", "30s")),
@@ -93,9 +156,44 @@ func Load() (*Config, error) {
 		},
 	}
 
-	// Add JWT and SMTP configurations
+	config.Redis.Cache = redisStore("CACHE", config.Redis.DB)
+	config.Redis.OTP = redisStore("OTP", config.Redis.DB)
+	config.Redis.RateLimit = redisStore("RATE_LIMIT", config.Redis.DB)
+	config.Redis.Queue = redisStore("QUEUE", config.Redis.DB)
+
+	// Requests stop their queries before the server gives up on writing the response
+	config.Server.RequestTimeout = config.Server.WriteTimeout
+	if timeout := getEnv("SERVER_REQUEST_TIMEOUT", ""); timeout != "" {
+		config.Server.RequestTimeout = parseDuration(timeout)
+	}
+
+	// Add JWT, SMTP, email attachment, payment, OTP, security, resilience, image proxy, ticket scanning, forecast, warehouse export, public statistics, insurance, tenant, feed, checkout, order, ticket portal, SMS, WhatsApp, wallet, invitation, newsletter, email tracking and OAuth configurations
 	config.AddJWTConfig()
 	config.AddSMTPConfig()
+	config.AddEmailAttachmentConfig()
+	config.AddPaymentConfig()
+	config.AddOTPConfig()
+	config.AddSecurityConfig()
+	config.AddResilienceConfig()
+	config.AddImageProxyConfig()
+	config.AddScanConfig()
+	config.AddForecastConfig()
+	config.AddWarehouseConfig()
+	config.AddStatsConfig()
+	config.AddInsuranceConfig()
+	config.AddTenantConfig()
+	config.AddFeedConfig()
+	config.AddCheckoutConfig()
+	config.AddOrderConfig()
+	config.AddPortalConfig()
+	config.AddSMSConfig()
+	config.AddWhatsAppConfig()
+	config.AddWalletConfig()
+	config.AddInvitationConfig()
+	config.AddNewsletterConfig()
+	config.AddEmailTrackingConfig()
+	config.AddOAuthConfig()
+	config.AddArchiveConfig()
 
 	return config, nil
 }
@@ -122,6 +220,30 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return value
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value := 0.0
+	_, err := fmt.Sscanf(valueStr, "%g", &value)
+	if err != nil {
+		log.Printf("Warning: Environment variable %s is not a number, using default value %g", key, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// redisStore reads the database and key prefix of one use of Redis, e.g. REDIS_OTP_DB and
+// REDIS_OTP_PREFIX
+func redisStore(name string, defaultDB int) RedisStoreConfig {
+	return RedisStoreConfig{
+		DB:     getEnvAsInt("REDIS_"+name+"_DB", defaultDB),
+		Prefix: getEnv("REDIS_"+name+"_PREFIX", ""),
+	}
+}
+
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
 	if err != nil {
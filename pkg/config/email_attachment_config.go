@@ -0,0 +1,29 @@
+package config
+
+import "fmt"
+
+// EmailAttachmentConfig defines where email attachments referenced by object key are read from
+// and how large attachments may be
+type EmailAttachmentConfig struct {
+	S3Bucket        string // Bucket of stored attachments; emails can only carry inline attachments when empty
+	S3Region        string
+	S3Endpoint      string // S3 or S3-compatible endpoint, addressed path-style
+	AccessKeyID     string
+	SecretAccessKey string
+	MaxSize         int64 // Largest single attachment, in bytes
+	MaxTotalSize    int64 // Largest total of an email's attachments, in bytes; mail servers commonly reject messages over 25 MB
+}
+
+// Add email attachment config to main config
+func (c *Config) AddEmailAttachmentConfig() {
+	region := getEnv("EMAIL_ATTACHMENT_S3_REGION", "us-east-1")
+	c.EmailAttachment = EmailAttachmentConfig{
+		S3Bucket:        getEnv("EMAIL_ATTACHMENT_S3_BUCKET", ""),
+		S3Region:        region,
+		S3Endpoint:      getEnv("EMAIL_ATTACHMENT_S3_ENDPOINT", fmt.Sprintf("https://s3.%s.amazonaws.com", region)),
+		AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+		SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		MaxSize:         int64(getEnvAsInt("EMAIL_ATTACHMENT_MAX_BYTES", 10<<20)),
+		MaxTotalSize:    int64(getEnvAsInt("EMAIL_ATTACHMENT_MAX_TOTAL_BYTES", 18<<20)),
+	}
+}
@@ -0,0 +1,16 @@
+package config
+
+// EmailTrackingConfig defines the tracking of opens and clicks of non-critical emails, such as
+// reminders and newsletters. Security, account, ticket and payment emails are never tracked.
+type EmailTrackingConfig struct {
+	Enabled bool   // Privacy toggle; when off, no email carries a tracking pixel or tracked links
+	Secret  string // Key signing the open and click links of tracked emails
+}
+
+// Add email tracking config to main config
+func (c *Config) AddEmailTrackingConfig() {
+	c.EmailTracking = EmailTrackingConfig{
+		Enabled: getEnv("EMAIL_TRACKING_ENABLED", "true") == "true",
+		Secret:  getEnv("EMAIL_TRACKING_SECRET", c.JWT.Secret),
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// FeedConfig defines how the Atom feed of published events is built and cached
+type FeedConfig struct {
+	CacheTTL   time.Duration // How long a rendered feed is served when no event changes
+	MaxEntries int           // Most recently published events listed in a feed
+}
+
+// Add feed config to main config
+func (c *Config) AddFeedConfig() {
+	c.Feed = FeedConfig{
+		CacheTTL:   parseDuration(getEnv("FEED_CACHE_TTL", "1h")),
+		MaxEntries: getEnvAsInt("FEED_MAX_ENTRIES", 50),
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// ForecastConfig defines how event sales forecasts are refreshed and cached
+type ForecastConfig struct {
+	RefreshCron string        // Cron spec of the forecast refresh for upcoming events (UTC); empty disables it
+	CacheTTL    time.Duration // How long a computed forecast is served before it is recomputed
+}
+
+// Add forecast config to main config
+func (c *Config) AddForecastConfig() {
+	c.Forecast = ForecastConfig{
+		RefreshCron: getEnv("FORECAST_REFRESH_CRON", "*/30 * * * *"),
+		CacheTTL:    parseDuration(getEnv("FORECAST_CACHE_TTL", "2h")),
+	}
+}
@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// ImageProxyConfig defines how organizer logos and event covers are fetched and served for emails
+type ImageProxyConfig struct {
+	FetchTimeout time.Duration // Deadline for downloading a source image
+	MaxBytes     int64         // Largest source image that is downloaded
+	CacheTTL     time.Duration // How long resized images are cached and may be cached by clients
+}
+
+// Add image proxy config to main config
+func (c *Config) AddImageProxyConfig() {
+	c.ImageProxy = ImageProxyConfig{
+		FetchTimeout: parseDuration(getEnv("IMAGE_PROXY_FETCH_TIMEOUT", "10s")),
+		MaxBytes:     int64(getEnvAsInt("IMAGE_PROXY_MAX_BYTES", 5<<20)),
+		CacheTTL:     parseDuration(getEnv("IMAGE_PROXY_CACHE_TTL", "24h")),
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// InsuranceConfig defines the ticket insurance provider offered as a checkout add-on
+type InsuranceConfig struct {
+	Provider string        // Insurance provider name (http); empty disables the add-on
+	APIURL   string        // Base URL of the provider's API
+	APIKey   string        // Provider API key
+	Timeout  time.Duration // Timeout of a call to the provider
+}
+
+// Add insurance config to main config
+func (c *Config) AddInsuranceConfig() {
+	c.Insurance = InsuranceConfig{
+		Provider: getEnv("INSURANCE_PROVIDER", ""),
+		APIURL:   getEnv("INSURANCE_API_URL", ""),
+		APIKey:   getEnv("INSURANCE_API_KEY", ""),
+		Timeout:  parseDuration(getEnv("INSURANCE_TIMEOUT", "10s")),
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// InvitationConfig defines the links emailed to people invited to join an organization
+type InvitationConfig struct {
+	AcceptURL string        // Frontend page accepting an invitation; the invitation token is appended as the token query parameter
+	TTL       time.Duration // How long an invitation can be accepted
+}
+
+// Add invitation config to main config
+func (c *Config) AddInvitationConfig() {
+	c.Invitation = InvitationConfig{
+		AcceptURL: getEnv("INVITATION_ACCEPT_URL", "http://localhost:3000/invitations/accept"),
+		TTL:       parseDuration(getEnv("INVITATION_TTL", "168h")),
+	}
+}
@@ -6,11 +6,15 @@ import (
 
 // JWTConfig defines the configuration for JWT authentication
 type JWTConfig struct {
-	Secret          string        // Secret key for signing JWTs
-	AccessTokenTTL  time.Duration // Time-to-live for access tokens
-	RefreshTokenTTL time.Duration // Time-to-live for refresh tokens
-	Issuer          string        // JWT issuer claim
-	Audience        string        // JWT audience claim
+	Algorithm        string        // Signing algorithm: HS256 with Secret, or RS256 or EdDSA with the key in PrivateKeyFile
+	Secret           string        // Secret key for signing JWTs
+	PreviousSecrets  string        // Comma-separated secrets retired by rotation, still accepted until their tokens expire
+	PrivateKeyFile   string        // PEM private key signing JWTs with RS256 or EdDSA
+	PreviousKeysFile string        // PEM public keys retired by rotation, still accepted and published until their tokens expire
+	AccessTokenTTL   time.Duration // Time-to-live for access tokens
+	RefreshTokenTTL  time.Duration // Time-to-live for refresh tokens
+	Issuer           string        // JWT issuer claim
+	Audience         string        // JWT audience claim
 }
 
 // Add JWT config to Config struct
@@ -22,10 +26,14 @@ func init() {
 func (c *Config) AddJWTConfig() {
 	// Default values for JWT config
 	c.JWT = JWTConfig{
-		Secret:          getEnv("JWT_SECRET", "your-super-secret-key-change-in-production"),
-		AccessTokenTTL:  time.Duration(getEnvAsInt("JWT_ACCESS_TOKEN_TTL", 5)) * time.Minute,   // 24 hours (1 day)
-		RefreshTokenTTL: time.Duration(getEnvAsInt("JWT_REFRESH_TOKEN_TTL", 7*24)) * time.Hour, // 7 days
-		Issuer:          getEnv("JWT_ISSUER", "event-ticketing-api"),
-		Audience:        getEnv("JWT_AUDIENCE", "event-ticketing-clients"),
+		Algorithm:        getEnv("JWT_ALGORITHM", "HS256"),
+		Secret:           getEnv("JWT_SECRET", "your-super-secret-key-change-in-production"),
+		PreviousSecrets:  getEnv("JWT_PREVIOUS_SECRETS", ""),
+		PrivateKeyFile:   getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		PreviousKeysFile: getEnv("JWT_PREVIOUS_KEYS_FILE", ""),
+		AccessTokenTTL:   time.Duration(getEnvAsInt("JWT_ACCESS_TOKEN_TTL", 5)) * time.Minute,   // 24 hours (1 day)
+		RefreshTokenTTL:  time.Duration(getEnvAsInt("JWT_REFRESH_TOKEN_TTL", 7*24)) * time.Hour, // 7 days
+		Issuer:           getEnv("JWT_ISSUER", "event-ticketing-api"),
+		Audience:         getEnv("JWT_AUDIENCE", "event-ticketing-clients"),
 	}
 }
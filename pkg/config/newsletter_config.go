@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// NewsletterConfig defines the newsletters admins send to users and the links tracking them
+type NewsletterConfig struct {
+	Secret        string        // Key signing the open, click and unsubscribe links; changing it invalidates every sent link
+	InactiveAfter time.Duration // How long without signing in makes a user inactive, unless a newsletter sets its own period
+}
+
+// Add newsletter config to main config
+func (c *Config) AddNewsletterConfig() {
+	c.Newsletter = NewsletterConfig{
+		Secret:        getEnv("NEWSLETTER_SECRET", c.JWT.Secret),
+		InactiveAfter: parseDuration(getEnv("NEWSLETTER_INACTIVE_AFTER", "2160h")),
+	}
+}
@@ -0,0 +1,42 @@
+package config
+
+import "time"
+
+// OAuthConfig defines the providers users can sign in with instead of a password. Each provider
+// is disabled until its client credentials are set.
+type OAuthConfig struct {
+	CallbackBaseURL string        // Public URL of this API; providers redirect to {CallbackBaseURL}/api/v1/auth/oauth/{provider}/callback
+	FrontendURL     string        // Page receiving the tokens in the URL fragment after signing in; the callback responds with JSON when empty
+	StateTTL        time.Duration // How long a started sign-in can be completed
+
+	GoogleClientID     string
+	GoogleClientSecret string
+
+	FacebookAppID     string
+	FacebookAppSecret string
+
+	AppleClientID string // Services ID registered for Sign in with Apple
+	AppleTeamID   string // Team identifier of the Apple developer account
+	AppleKeyID    string // ID of the Sign in with Apple private key
+	AppleKeyFile  string // PEM (.p8) Sign in with Apple private key, signing the client secret
+}
+
+// Add OAuth config to main config
+func (c *Config) AddOAuthConfig() {
+	c.OAuth = OAuthConfig{
+		CallbackBaseURL: getEnv("OAUTH_CALLBACK_BASE_URL", "http://localhost:"+c.App.Port),
+		FrontendURL:     getEnv("OAUTH_FRONTEND_URL", ""),
+		StateTTL:        parseDuration(getEnv("OAUTH_STATE_TTL", "10m")),
+
+		GoogleClientID:     getEnv("GOOGLE_OAUTH_CLIENT_ID", ""),
+		GoogleClientSecret: getEnv("GOOGLE_OAUTH_CLIENT_SECRET", ""),
+
+		FacebookAppID:     getEnv("FACEBOOK_OAUTH_APP_ID", ""),
+		FacebookAppSecret: getEnv("FACEBOOK_OAUTH_APP_SECRET", ""),
+
+		AppleClientID: getEnv("APPLE_OAUTH_CLIENT_ID", ""),
+		AppleTeamID:   getEnv("APPLE_OAUTH_TEAM_ID", ""),
+		AppleKeyID:    getEnv("APPLE_OAUTH_KEY_ID", ""),
+		AppleKeyFile:  getEnv("APPLE_OAUTH_KEY_FILE", ""),
+	}
+}
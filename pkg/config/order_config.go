@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// OrderConfig defines how unpaid orders are expired and how often their tickets can be resent
+type OrderConfig struct {
+	PendingTTL        time.Duration // How long an order can stay pending before it is cancelled and its tickets restocked; 0 disables expiry
+	ExpiryCron        string        // Cron spec of the pending order expiry sweep (UTC); empty disables it
+	TicketResendLimit int           // Ticket email resends allowed per order per hour
+}
+
+// Add order config to main config
+func (c *Config) AddOrderConfig() {
+	c.Order = OrderConfig{
+		PendingTTL:        parseDuration(getEnv("ORDER_PENDING_TTL", "168h")),
+		ExpiryCron:        getEnv("ORDER_EXPIRY_CRON", "*/15 * * * *"),
+		TicketResendLimit: getEnvAsInt("ORDER_TICKET_RESEND_LIMIT", 3),
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// OTPConfig defines the send quotas and abuse alerting for one-time passwords
+type OTPConfig struct {
+	IPSendLimit          int           // OTP requests allowed per client IP per IPWindow
+	IPWindow             time.Duration // Window of the per-IP quota
+	IdentifierSendLimit  int           // Codes sent to one identifier per IdentifierWindow
+	IdentifierWindow     time.Duration // Window of the per-identifier quota
+	ResendCooldown       time.Duration // Minimum time between two codes to the same identifier
+	AlertHourlyThreshold int           // Codes issued in one hour that trigger an abuse alert
+	AlertEmail           string        // Address notified of abnormal OTP volume
+}
+
+// Add OTP config to main config
+func (c *Config) AddOTPConfig() {
+	c.OTP = OTPConfig{
+		IPSendLimit:          getEnvAsInt("OTP_IP_SEND_LIMIT", 10),
+		IPWindow:             parseDuration(getEnv("OTP_IP_WINDOW", "1h")),
+		IdentifierSendLimit:  getEnvAsInt("OTP_IDENTIFIER_SEND_LIMIT", 5),
+		IdentifierWindow:     parseDuration(getEnv("OTP_IDENTIFIER_WINDOW", "1h")),
+		ResendCooldown:       parseDuration(getEnv("OTP_RESEND_COOLDOWN", "60s")),
+		AlertHourlyThreshold: getEnvAsInt("OTP_ALERT_HOURLY_THRESHOLD", 500),
+		AlertEmail:           getEnv("OTP_ALERT_EMAIL", ""),
+	}
+}
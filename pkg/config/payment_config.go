@@ -0,0 +1,27 @@
+package config
+
+// PaymentConfig defines the configuration for the card payment provider
+type PaymentConfig struct {
+	Provider              string // Payment provider name (stripe)
+	StripeSecretKey       string // Stripe secret API key
+	StripeAPIURL          string // Stripe API base URL
+	InstallmentRetryDays  int    // Days between attempts to charge a failed installment
+	InstallmentMaxRetries int    // Failed attempts before an installment plan defaults
+	ReconciliationCron    string // Cron spec of the nightly reconciliation run (UTC)
+	ReconciliationAlertTo string // Address alerted when reconciliation finds discrepancies
+	AdjustmentApprovalMin int    // Manual order adjustments of at least this amount need a second admin's approval
+}
+
+// Add payment config to main config
+func (c *Config) AddPaymentConfig() {
+	c.Payment = PaymentConfig{
+		Provider:              getEnv("PAYMENT_PROVIDER", "stripe"),
+		StripeSecretKey:       getEnv("STRIPE_SECRET_KEY", ""),
+		StripeAPIURL:          getEnv("STRIPE_API_URL", "https://api.stripe.com"),
+		InstallmentRetryDays:  getEnvAsInt("INSTALLMENT_RETRY_DAYS", 3),
+		InstallmentMaxRetries: getEnvAsInt("INSTALLMENT_MAX_RETRIES", 3),
+		ReconciliationCron:    getEnv("RECONCILIATION_CRON", "0 3 * * *"),
+		ReconciliationAlertTo: getEnv("RECONCILIATION_ALERT_EMAIL", ""),
+		AdjustmentApprovalMin: getEnvAsInt("ADJUSTMENT_APPROVAL_THRESHOLD", 100),
+	}
+}
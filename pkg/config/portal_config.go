@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// PortalConfig defines the no-login ticket management pages linked from ticket emails
+type PortalConfig struct {
+	URL              string        // Frontend ticket page; the ticket token is appended as the token query parameter
+	Secret           string        // Key signing ticket tokens; changing it invalidates every emailed link
+	NameChangeCutoff time.Duration // How long before the event starts attendee names stop being editable
+	MaxNameChanges   int           // Attendee name changes allowed per ticket; 0 disables them
+	ResendCooldown   time.Duration // Minimum time between ticket emails resent from the portal
+}
+
+// Add ticket portal config to main config
+func (c *Config) AddPortalConfig() {
+	c.Portal = PortalConfig{
+		URL:              getEnv("TICKET_PORTAL_URL", "http://localhost:3000/tickets/manage"),
+		Secret:           getEnv("TICKET_PORTAL_SECRET", c.JWT.Secret),
+		NameChangeCutoff: parseDuration(getEnv("TICKET_NAME_CHANGE_CUTOFF", "48h")),
+		MaxNameChanges:   getEnvAsInt("TICKET_NAME_CHANGE_LIMIT", 1),
+		ResendCooldown:   parseDuration(getEnv("TICKET_RESEND_COOLDOWN", "10m")),
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import "time"
+
+// ResilienceConfig defines how calls to external providers (SMTP, payments) are protected
+type ResilienceConfig struct {
+	BreakerFailures      int           // Consecutive provider failures that open a circuit
+	BreakerOpenTimeout   time.Duration // How long an open circuit rejects calls before letting a trial call through
+	RetryAttempts        int           // Attempts per call, including the first, for transient provider failures
+	RetryBaseDelay       time.Duration // Delay before the first retry, doubled for each further attempt
+	SMTPMaxConcurrent    int           // SMTP sends allowed in flight at once per instance
+	SMTPTimeout          time.Duration // Deadline of a single SMTP conversation
+	PaymentMaxConcurrent int           // Payment provider calls allowed in flight at once per instance
+}
+
+// Add resilience config to main config
+func (c *Config) AddResilienceConfig() {
+	c.Resilience = ResilienceConfig{
+		BreakerFailures:      getEnvAsInt("CIRCUIT_BREAKER_FAILURES", 5),
+		BreakerOpenTimeout:   parseDuration(getEnv("CIRCUIT_BREAKER_OPEN_TIMEOUT", "30s")),
+		RetryAttempts:        getEnvAsInt("PROVIDER_RETRY_ATTEMPTS", 3),
+		RetryBaseDelay:       parseDuration(getEnv("PROVIDER_RETRY_BASE_DELAY", "200ms")),
+		SMTPMaxConcurrent:    getEnvAsInt("SMTP_MAX_CONCURRENT", 5),
+		SMTPTimeout:          parseDuration(getEnv("SMTP_TIMEOUT", "30s")),
+		PaymentMaxConcurrent: getEnvAsInt("PAYMENT_MAX_CONCURRENT", 10),
+	}
+}
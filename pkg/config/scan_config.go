@@ -0,0 +1,24 @@
+package config
+
+import "time"
+
+// ScanConfig defines how batches of scanned tickets are validated at the door and how scanner
+// devices are paired to events
+type ScanConfig struct {
+	MaxBatchSize    int           // Most codes accepted in one validation request
+	DuplicateWindow time.Duration // How long a scanned code is reported as a duplicate instead of re-checked
+	QRSecret        string        // Key signing the payload of ticket QR codes
+	PairingCodeTTL  time.Duration // How long a scanner pairing code can be exchanged for a device token
+	DeviceTokenTTL  time.Duration // How long a paired scanner's device token stays valid
+}
+
+// Add ticket scanning config to main config
+func (c *Config) AddScanConfig() {
+	c.Scan = ScanConfig{
+		MaxBatchSize:    getEnvAsInt("SCAN_MAX_BATCH_SIZE", 100),
+		DuplicateWindow: parseDuration(getEnv("SCAN_DUPLICATE_WINDOW", "30s")),
+		QRSecret:        getEnv("TICKET_QR_SECRET", c.JWT.Secret),
+		PairingCodeTTL:  parseDuration(getEnv("SCANNER_PAIRING_CODE_TTL", "5m")),
+		DeviceTokenTTL:  parseDuration(getEnv("SCANNER_DEVICE_TOKEN_TTL", "24h")),
+	}
+}
@@ -0,0 +1,49 @@
+package config
+
+import "time"
+
+// SecurityConfig defines account security settings
+type SecurityConfig struct {
+	PublicURL     string        // Public base URL of the API, used in links sent by email
+	CountryHeader string        // Request header carrying the client's ISO country code, set by the CDN or proxy
+	AlertLinkTTL  time.Duration // Validity of the "this wasn't me" link in new-device alerts
+
+	PasswordScreening    bool          // Reject passwords found in the HaveIBeenPwned breach corpus
+	PwnedPasswordsURL    string        // HaveIBeenPwned range API base URL
+	PwnedPasswordsTTL    time.Duration // How long range responses are cached
+	PwnedPasswordsMinHit int           // Breach occurrences at which a password is rejected
+
+	EncryptionKeys      string // Field encryption keyring as comma-separated "id:base64key" pairs of 32-byte AES keys
+	EncryptionActiveKey string // ID of the keyring entry new values are encrypted with
+
+	ElevationMaxTTL          time.Duration // Longest elevated access an admin can request for destructive actions
+	ElevationRequireApproval bool          // Elevation requests wait for a second admin's approval
+	ElevationRequestTTL      time.Duration // How long a request can wait for approval
+
+	AdminIPAllowlist string // Comma-separated IPs and CIDR ranges allowed to reach /admin routes; empty allows any
+	TrustedProxies   string // Comma-separated proxy IPs and CIDR ranges whose X-Forwarded-For is trusted; empty trusts any
+}
+
+// Add security config to main config
+func (c *Config) AddSecurityConfig() {
+	c.Security = SecurityConfig{
+		PublicURL:     getEnv("API_PUBLIC_URL", "http://localhost:8080"),
+		CountryHeader: getEnv("GEOIP_COUNTRY_HEADER", "CF-IPCountry"),
+		AlertLinkTTL:  parseDuration(getEnv("SECURITY_ALERT_LINK_TTL", "168h")),
+
+		PasswordScreening:    getEnv("PASSWORD_BREACH_CHECK_ENABLED", "true") == "true",
+		PwnedPasswordsURL:    getEnv("PWNED_PASSWORDS_API_URL", "https://api.pwnedpasswords.com"),
+		PwnedPasswordsTTL:    parseDuration(getEnv("PWNED_PASSWORDS_CACHE_TTL", "24h")),
+		PwnedPasswordsMinHit: getEnvAsInt("PWNED_PASSWORDS_MIN_COUNT", 1),
+
+		EncryptionKeys:      getEnv("ENCRYPTION_KEYS", ""),
+		EncryptionActiveKey: getEnv("ENCRYPTION_ACTIVE_KEY", ""),
+
+		ElevationMaxTTL:          parseDuration(getEnv("ELEVATION_MAX_TTL", "1h")),
+		ElevationRequireApproval: getEnv("ELEVATION_REQUIRE_APPROVAL", "false") == "true",
+		ElevationRequestTTL:      parseDuration(getEnv("ELEVATION_REQUEST_TTL", "30m")),
+
+		AdminIPAllowlist: getEnv("ADMIN_IP_ALLOWLIST", ""),
+		TrustedProxies:   getEnv("TRUSTED_PROXIES", ""),
+	}
+}
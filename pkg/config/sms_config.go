@@ -0,0 +1,23 @@
+package config
+
+import "time"
+
+// SMSConfig defines the SMS provider ticket links are texted through
+type SMSConfig struct {
+	Provider string        // SMS provider name (http); empty disables SMS delivery
+	APIURL   string        // Base URL of the provider's API
+	APIKey   string        // Provider API key
+	Sender   string        // Sender ID or number messages are sent from
+	Timeout  time.Duration // Timeout of a call to the provider
+}
+
+// Add SMS config to main config
+func (c *Config) AddSMSConfig() {
+	c.SMS = SMSConfig{
+		Provider: getEnv("SMS_PROVIDER", ""),
+		APIURL:   getEnv("SMS_API_URL", ""),
+		APIKey:   getEnv("SMS_API_KEY", ""),
+		Sender:   getEnv("SMS_SENDER", "TimroTix"),
+		Timeout:  parseDuration(getEnv("SMS_TIMEOUT", "10s")),
+	}
+}
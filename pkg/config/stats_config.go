@@ -0,0 +1,15 @@
+package config
+
+import "time"
+
+// StatsConfig defines how the public platform statistics are cached
+type StatsConfig struct {
+	CacheTTL time.Duration // How long platform-wide counters are served before they are recounted
+}
+
+// Add public statistics config to main config
+func (c *Config) AddStatsConfig() {
+	c.Stats = StatsConfig{
+		CacheTTL: parseDuration(getEnv("PUBLIC_STATS_CACHE_TTL", "15m")),
+	}
+}
@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// TenantConfig defines how white-label tenants are resolved
+type TenantConfig struct {
+	CacheTTL     time.Duration // How long a resolved tenant is reused before it is looked up again
+	SupportEmail string        // Support address of requests that match no tenant
+}
+
+// Add tenant config to main config
+func (c *Config) AddTenantConfig() {
+	c.Tenant = TenantConfig{
+		CacheTTL:     parseDuration(getEnv("TENANT_CACHE_TTL", "1m")),
+		SupportEmail: getEnv("SUPPORT_EMAIL", c.SMTP.FromEmail),
+	}
+}
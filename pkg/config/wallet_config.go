@@ -0,0 +1,32 @@
+package config
+
+// WalletConfig defines the Apple Wallet pass type and Google Wallet issuer tickets are added to
+// phone wallets with. Each wallet is disabled until its credentials are set.
+type WalletConfig struct {
+	ApplePassTypeID       string // Pass type identifier registered with Apple, e.g. pass.com.example.ticket
+	AppleTeamID           string // Team identifier of the Apple developer account
+	AppleOrganizationName string // Name shown on the lock screen and in the Wallet app
+	AppleCertFile         string // PEM certificate of the pass type ID
+	AppleKeyFile          string // PEM private key of the pass type ID certificate
+	AppleWWDRCertFile     string // PEM Apple Worldwide Developer Relations intermediate certificate
+
+	GoogleIssuerID           string // Issuer ID of the Google Wallet console
+	GoogleServiceAccountFile string // JSON key of the service account allowed to issue passes
+	GoogleOrigins            string // Comma-separated origins of the websites showing the save links
+}
+
+// Add wallet config to main config
+func (c *Config) AddWalletConfig() {
+	c.Wallet = WalletConfig{
+		ApplePassTypeID:       getEnv("APPLE_WALLET_PASS_TYPE_ID", ""),
+		AppleTeamID:           getEnv("APPLE_WALLET_TEAM_ID", ""),
+		AppleOrganizationName: getEnv("APPLE_WALLET_ORGANIZATION_NAME", c.App.Name),
+		AppleCertFile:         getEnv("APPLE_WALLET_CERT_FILE", ""),
+		AppleKeyFile:          getEnv("APPLE_WALLET_KEY_FILE", ""),
+		AppleWWDRCertFile:     getEnv("APPLE_WALLET_WWDR_CERT_FILE", ""),
+
+		GoogleIssuerID:           getEnv("GOOGLE_WALLET_ISSUER_ID", ""),
+		GoogleServiceAccountFile: getEnv("GOOGLE_WALLET_SERVICE_ACCOUNT_FILE", ""),
+		GoogleOrigins:            getEnv("GOOGLE_WALLET_ORIGINS", ""),
+	}
+}
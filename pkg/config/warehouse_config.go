@@ -0,0 +1,30 @@
+package config
+
+import "fmt"
+
+// WarehouseConfig defines the nightly export of anonymized fact tables for the data team
+type WarehouseConfig struct {
+	ExportCron      string // Cron spec of the nightly export (UTC)
+	S3Bucket        string // Destination bucket; exports are disabled when empty
+	S3Region        string
+	S3Endpoint      string // S3 or S3-compatible endpoint, addressed path-style
+	S3Prefix        string // Key prefix under which datasets are written
+	AccessKeyID     string
+	SecretAccessKey string
+	PseudonymKey    string // Secret keying the hashes that replace buyer and attendee emails
+}
+
+// Add warehouse export config to main config
+func (c *Config) AddWarehouseConfig() {
+	region := getEnv("WAREHOUSE_S3_REGION", "us-east-1")
+	c.Warehouse = WarehouseConfig{
+		ExportCron:      getEnv("WAREHOUSE_EXPORT_CRON", "30 2 * * *"),
+		S3Bucket:        getEnv("WAREHOUSE_S3_BUCKET", ""),
+		S3Region:        region,
+		S3Endpoint:      getEnv("WAREHOUSE_S3_ENDPOINT", fmt.Sprintf("https://s3.%s.amazonaws.com", region)),
+		S3Prefix:        getEnv("WAREHOUSE_S3_PREFIX", "warehouse"),
+		AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+		SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+		PseudonymKey:    getEnv("WAREHOUSE_PSEUDONYM_KEY", ""),
+	}
+}
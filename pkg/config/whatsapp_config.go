@@ -0,0 +1,32 @@
+package config
+
+import "time"
+
+// WhatsAppConfig defines the WhatsApp Business Cloud API account tickets and event reminders are
+// sent from. Only message templates approved for the account can be sent.
+type WhatsAppConfig struct {
+	APIURL           string        // Base URL of the Cloud API, including its version
+	PhoneNumberID    string        // ID of the business phone number messages are sent from; empty disables WhatsApp delivery
+	AccessToken      string        // System user access token
+	TicketTemplate   string        // Approved template delivering a ticket: event title, start time, ticket link
+	ReminderTemplate string        // Approved template reminding of an event: event title, start time, location
+	TemplateLanguage string        // Language code the templates were approved in
+	ReminderLead     time.Duration // How long before an event starts its reminders are sent
+	ReminderCron     string        // Cron spec of the event reminder sweep (UTC); empty disables reminders
+	Timeout          time.Duration // Timeout of a call to the Cloud API
+}
+
+// Add WhatsApp config to main config
+func (c *Config) AddWhatsAppConfig() {
+	c.WhatsApp = WhatsAppConfig{
+		APIURL:           getEnv("WHATSAPP_API_URL", "https://graph.facebook.com/v19.0"),
+		PhoneNumberID:    getEnv("WHATSAPP_PHONE_NUMBER_ID", ""),
+		AccessToken:      getEnv("WHATSAPP_ACCESS_TOKEN", ""),
+		TicketTemplate:   getEnv("WHATSAPP_TICKET_TEMPLATE", "ticket_delivery"),
+		ReminderTemplate: getEnv("WHATSAPP_REMINDER_TEMPLATE", "event_reminder"),
+		TemplateLanguage: getEnv("WHATSAPP_TEMPLATE_LANGUAGE", "en_US"),
+		ReminderLead:     parseDuration(getEnv("WHATSAPP_REMINDER_LEAD", "24h")),
+		ReminderCron:     getEnv("WHATSAPP_REMINDER_CRON", "*/15 * * * *"),
+		Timeout:          parseDuration(getEnv("WHATSAPP_TIMEOUT", "10s")),
+	}
+}
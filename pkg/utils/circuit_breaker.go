@@ -0,0 +1,222 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrCircuitOpen is returned while a dependency's circuit is open and calls are rejected
+	ErrCircuitOpen = errors.New("circuit breaker is open")
+	// ErrTooManyCalls is returned when a dependency already has its maximum of calls in flight
+	ErrTooManyCalls = errors.New("too many concurrent calls")
+)
+
+// Circuit states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half-open"
+)
+
+// CircuitBreakerSettings configures a circuit breaker
+type CircuitBreakerSettings struct {
+	FailureThreshold int              // Consecutive failures that open the circuit
+	OpenTimeout      time.Duration    // How long the circuit stays open before a trial call is let through
+	MaxConcurrent    int              // Calls allowed in flight at once, 0 for no limit
+	IsFailure        func(error) bool // Errors that count against the dependency; any error when nil
+}
+
+// BackpressureError is returned when a call is rejected without reaching the dependency
+type BackpressureError struct {
+	Dependency string
+	RetryAfter time.Duration // When the dependency may accept calls again
+	cause      error
+}
+
+func (e *BackpressureError) Error() string {
+	return fmt.Sprintf("%s unavailable: %v", e.Dependency, e.cause)
+}
+
+func (e *BackpressureError) Unwrap() error {
+	return e.cause
+}
+
+// IsBackpressure reports whether err is a call rejected by a circuit breaker. Such calls never
+// reached the dependency and should be deferred rather than counted as failures.
+func IsBackpressure(err error) bool {
+	var bp *BackpressureError
+	return errors.As(err, &bp)
+}
+
+// CircuitBreaker stops calling a failing dependency for a while so callers fail fast instead of
+// piling up behind it, and caps how many calls may wait on it at once
+type CircuitBreaker struct {
+	name     string
+	settings CircuitBreakerSettings
+	slots    chan struct{}
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	trial    bool // A half-open trial call is in flight
+}
+
+var (
+	breakersMu sync.Mutex
+	breakers   = map[string]*CircuitBreaker{}
+)
+
+// GetCircuitBreaker returns the process-wide breaker for a dependency, creating it with the given
+// settings on first use. Services built more than once share the same breaker.
+func GetCircuitBreaker(name string, settings CircuitBreakerSettings) *CircuitBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+
+	if breaker, ok := breakers[name]; ok {
+		return breaker
+	}
+	breaker := NewCircuitBreaker(name, settings)
+	breakers[name] = breaker
+	return breaker
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(name string, settings CircuitBreakerSettings) *CircuitBreaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = 5
+	}
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = 30 * time.Second
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = func(err error) bool { return err != nil }
+	}
+
+	breaker := &CircuitBreaker{name: name, settings: settings, state: CircuitClosed}
+	if settings.MaxConcurrent > 0 {
+		breaker.slots = make(chan struct{}, settings.MaxConcurrent)
+	}
+	return breaker
+}
+
+// State returns the current state of the circuit
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Execute runs fn unless the circuit is open or the dependency is at its concurrency limit, in
+// which case it returns a *BackpressureError without calling fn
+func (b *CircuitBreaker) Execute(fn func() error) error {
+	if err := b.before(); err != nil {
+		return err
+	}
+
+	if b.slots != nil {
+		select {
+		case b.slots <- struct{}{}:
+			defer func() { <-b.slots }()
+		default:
+			b.release()
+			return &BackpressureError{Dependency: b.name, RetryAfter: time.Second, cause: ErrTooManyCalls}
+		}
+	}
+
+	err := fn()
+	b.after(err)
+	return err
+}
+
+// before admits a call, moving an open circuit to half-open once its timeout has passed
+func (b *CircuitBreaker) before() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		remaining := b.settings.OpenTimeout - time.Since(b.openedAt)
+		if remaining > 0 {
+			return &BackpressureError{Dependency: b.name, RetryAfter: remaining, cause: ErrCircuitOpen}
+		}
+		b.setState(CircuitHalfOpen)
+		b.trial = true
+	case CircuitHalfOpen:
+		// Only one trial call at a time decides whether the dependency recovered
+		if b.trial {
+			return &BackpressureError{Dependency: b.name, RetryAfter: time.Second, cause: ErrCircuitOpen}
+		}
+		b.trial = true
+	}
+	return nil
+}
+
+// release gives back a half-open trial slot for a call that never ran
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.trial = false
+	}
+}
+
+// after records the outcome of a call
+func (b *CircuitBreaker) after(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+	if err == nil || !b.settings.IsFailure(err) {
+		b.failures = 0
+		if b.state != CircuitClosed {
+			b.setState(CircuitClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.openedAt = time.Now()
+		b.setState(CircuitOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(state string) {
+	log.Printf("Circuit breaker %s: %s -> %s", b.name, b.state, state)
+	b.state = state
+}
+
+// Retry calls fn up to attempts times while it fails with an error retryable accepts, waiting
+// baseDelay doubled per attempt with jitter in between. Calls rejected by a circuit breaker are
+// not retried, since the dependency is known to be unavailable.
+func Retry(ctx context.Context, attempts int, baseDelay time.Duration, retryable func(error) bool, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < max(attempts, 1); attempt++ {
+		if attempt > 0 {
+			delay := baseDelay << (attempt - 1)
+			if delay > 0 {
+				delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		err = fn()
+		if err == nil || IsBackpressure(err) || !retryable(err) {
+			return err
+		}
+	}
+	return err
+}
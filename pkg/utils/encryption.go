@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks values produced by FieldEncryptor. Stored values have the form
+// "enc:v1:<key id>:<base64(nonce|ciphertext)>", so the key used is known when decrypting.
+const encryptedPrefix = "enc:v1:"
+
+// FieldEncryptor encrypts individual column values with AES-256-GCM. It holds a keyring so
+// values written under a retired key can still be read while they are re-encrypted.
+type FieldEncryptor struct {
+	keys     map[string]cipher.AEAD
+	activeID string
+}
+
+// NewFieldEncryptor creates an encryptor from a keyring of comma-separated "id:base64key" pairs
+// and the ID of the key used for new values
+func NewFieldEncryptor(keyring, activeID string) (*FieldEncryptor, error) {
+	e := &FieldEncryptor{keys: make(map[string]cipher.AEAD), activeID: activeID}
+
+	for _, entry := range strings.Split(keyring, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid encryption key entry %q", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key %s: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("encryption key %s must be 32 bytes, got %d", id, len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		e.keys[id] = aead
+	}
+
+	if len(e.keys) == 0 {
+		return nil, errors.New("no encryption keys configured")
+	}
+	if _, ok := e.keys[activeID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not in the keyring", activeID)
+	}
+	return e, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are encrypted with
+func (e *FieldEncryptor) ActiveKeyID() string {
+	return e.activeID
+}
+
+// Encrypt encrypts a value with the active key. Empty values are stored as is.
+func (e *FieldEncryptor) Encrypt(plaintext string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+
+	aead := e.keys[e.activeID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(e.activeID))
+	return encryptedPrefix + e.activeID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt. Values without the encryption prefix were
+// written before encryption was enabled and are returned unchanged.
+func (e *FieldEncryptor) Decrypt(value string) (string, error) {
+	if !IsEncryptedValue(value) {
+		return value, nil
+	}
+
+	id, encoded, found := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !found {
+		return "", errors.New("malformed encrypted value")
+	}
+	aead, ok := e.keys[id]
+	if !ok {
+		return "", fmt.Errorf("encryption key %q is not in the keyring", id)
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value is not yet encrypted with the active key
+func (e *FieldEncryptor) NeedsRotation(value string) bool {
+	if value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, encryptedPrefix+e.activeID+":")
+}
+
+// IsEncryptedValue reports whether a stored value was produced by a FieldEncryptor
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
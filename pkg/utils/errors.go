@@ -15,6 +15,27 @@ type AppError struct {
 	Cause      error                  `json:"-"`
 }
 
+// ErrorCodes lists every code returned in the error.code field of error responses
+var ErrorCodes = []string{
+	"BAD_REQUEST",
+	"BUSINESS_LOGIC_ERROR",
+	"CONFLICT",
+	"DATABASE_ERROR",
+	"EXTERNAL_SERVICE_ERROR",
+	"FORBIDDEN",
+	"GENERIC_ERROR",
+	"INTERNAL_SERVER_ERROR",
+	"INVALID_ID",
+	"NOT_FOUND",
+	"PASSWORD_BREACHED",
+	"PROFILE_INCOMPLETE",
+	"RATE_LIMIT_EXCEEDED",
+	"SERVICE_UNAVAILABLE",
+	"TIMEOUT_ERROR",
+	"UNAUTHORIZED",
+	"VALIDATION_ERROR",
+}
+
 // Error implements the error interface
 func (e *AppError) Error() string {
 	if e.Details != "" {
@@ -134,6 +155,27 @@ func NewRateLimitError(message string) *AppError {
 	}
 }
 
+// NewPasswordBreachedError creates an error for a password found in a known data breach
+func NewPasswordBreachedError() *AppError {
+	return &AppError{
+		Code:       "PASSWORD_BREACHED",
+		Message:    "This password has appeared in a data breach and cannot be used",
+		Details:    "Choose a different password that you have not used elsewhere",
+		StatusCode: http.StatusBadRequest,
+	}
+}
+
+// NewProfileIncompleteError creates an error for an action that needs profile fields the user has not filled in
+func NewProfileIncompleteError(missing []string) *AppError {
+	return &AppError{
+		Code:       "PROFILE_INCOMPLETE",
+		Message:    "Complete your profile to continue",
+		Details:    "Fill in the missing profile fields with PATCH /auth/profile",
+		StatusCode: http.StatusForbidden,
+		Fields:     map[string]interface{}{"missing": missing},
+	}
+}
+
 // NewTimeoutError creates a timeout error
 func NewTimeoutError(operation string) *AppError {
 	return &AppError{
@@ -0,0 +1,21 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// SignHookPayload returns the X-Hook-Signature header of a hook delivery: the Unix time it was
+// signed at and the hex HMAC-SHA256 of "<time>.<body>" under the subscription's secret, as
+// "t=<time>,v1=<hmac>". Receivers recompute the HMAC and reject stale times to stop replays.
+func SignHookPayload(secret string, signedAt time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// sanitizedTags are the formatting elements SanitizeHTML keeps; void elements have no end tag
+var sanitizedTags = map[string]bool{
+	"a": false, "b": false, "blockquote": false, "br": true, "em": false, "h2": false, "h3": false,
+	"h4": false, "hr": true, "i": false, "li": false, "ol": false, "p": false, "strong": false,
+	"u": false, "ul": false,
+}
+
+// droppedTags are the elements SanitizeHTML removes together with their content
+var droppedTags = []string{"script", "style", "iframe", "object", "embed", "noscript", "template", "textarea", "select", "title", "head"}
+
+// linkSchemes are the URL schemes links may keep
+var linkSchemes = []string{"http", "https", "mailto", "tel"}
+
+// SanitizeHTML reduces untrusted HTML, such as content written by organizers, to basic formatting
+// that is safe to embed in emails. Other elements are removed while their text is kept, scripts
+// and styles are removed entirely, and every attribute but the href of http(s), mailto and tel
+// links is dropped. Unclosed elements are closed.
+func SanitizeHTML(input string) string {
+	tokenizer := html.NewTokenizer(strings.NewReader(input))
+	var out strings.Builder
+	var open []string
+	dropped := 0 // Depth inside elements removed with their content
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			for i := len(open) - 1; i >= 0; i-- {
+				out.WriteString("</" + open[i] + ">")
+			}
+			return strings.TrimSpace(out.String())
+
+		case html.TextToken:
+			if dropped == 0 {
+				out.WriteString(html.EscapeString(string(tokenizer.Text())))
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if slices.Contains(droppedTags, token.Data) {
+				if tokenType == html.StartTagToken {
+					dropped++
+				}
+				continue
+			}
+			void, allowed := sanitizedTags[token.Data]
+			if dropped > 0 || !allowed {
+				continue
+			}
+			out.WriteString("<" + token.Data)
+			if token.Data == "a" {
+				if href, ok := sanitizedLink(token.Attr); ok {
+					out.WriteString(` href="` + html.EscapeString(href) + `"`)
+				}
+			}
+			out.WriteString(">")
+			if !void && tokenType == html.StartTagToken {
+				open = append(open, token.Data)
+			}
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			if slices.Contains(droppedTags, token.Data) {
+				if dropped > 0 {
+					dropped--
+				}
+				continue
+			}
+			if dropped > 0 {
+				continue
+			}
+			// Close the element along with any left open inside it; stray end tags are ignored
+			i := len(open) - 1
+			for i >= 0 && open[i] != token.Data {
+				i--
+			}
+			for i >= 0 && len(open) > i {
+				out.WriteString("</" + open[len(open)-1] + ">")
+				open = open[:len(open)-1]
+			}
+		}
+	}
+}
+
+// sanitizedLink returns the href of a link when it uses one of the allowed schemes
+func sanitizedLink(attrs []html.Attribute) (string, bool) {
+	for _, attr := range attrs {
+		if attr.Key != "href" {
+			continue
+		}
+		link, err := url.Parse(strings.TrimSpace(attr.Val))
+		if err != nil || !slices.Contains(linkSchemes, strings.ToLower(link.Scheme)) {
+			return "", false
+		}
+		return link.String(), true
+	}
+	return "", false
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,24 +14,83 @@ import (
 	"github.com/google/uuid"
 )
 
+// ScopeElevated is the scope of access tokens issued for an admin's active break-glass elevation
+const ScopeElevated = "admin:elevated"
+
+// Token types, told apart by the token_type claim. Refresh tokens are only redeemed at the refresh
+// endpoint and are never accepted as bearer tokens.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrNotAccessToken is returned when a refresh token, or a token from before token types, is used
+// as an access token
+var ErrNotAccessToken = errors.New("token is not an access token")
+
 // Claims defines the claims in the JWT
 type Claims struct {
-	UserID uuid.UUID `json:"user_id"`
-	Email  string    `json:"email"`
-	Roles  []string  `json:"roles"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Email       string     `json:"email"`
+	Roles       []string   `json:"roles"`
+	TokenType   string     `json:"token_type,omitempty"`   // TokenTypeAccess or TokenTypeRefresh
+	Scope       string     `json:"scope,omitempty"`        // ScopeElevated on elevated access tokens
+	ElevationID *uuid.UUID `json:"elevation_id,omitempty"` // Elevation an elevated access token was issued for
 	jwt.RegisteredClaims
 }
 
+// IssueTime returns when a token was issued, to the millisecond for tokens whose ID is a UUIDv7
+// and to the second, from iat, for older ones
+func (c *Claims) IssueTime() time.Time {
+	if id, err := uuid.Parse(c.ID); err == nil && id.Version() == 7 {
+		sec, nsec := id.Time().UnixTime()
+		return time.Unix(sec, nsec)
+	}
+	if c.IssuedAt == nil {
+		return time.Time{}
+	}
+	return c.IssuedAt.Time
+}
+
+// newTokenID returns a unique token ID. It is a UUIDv7, so it also records the issue time more
+// precisely than iat, which is in whole seconds.
+func newTokenID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.New().String()
+	}
+	return id.String()
+}
+
 // JWTService provides methods for JWT operations
 type JWTService struct {
 	config *config.JWTConfig
+	keys   *jwtKeySet
+	keyErr error // Why the signing keys could not be loaded; every token operation fails with it
 }
 
 // NewJWTService creates a new JWT service
 func NewJWTService(config *config.JWTConfig) *JWTService {
+	keys, err := loadJWTKeySet(config)
 	return &JWTService{
 		config: config,
+		keys:   keys,
+		keyErr: err,
+	}
+}
+
+// Err reports why the configured signing keys could not be loaded, so the API can refuse to start
+func (j *JWTService) Err() error {
+	return j.keyErr
+}
+
+// JWKS returns the public keys tokens are verified with, for services validating tokens on their
+// own. It is empty while tokens are signed with a shared secret.
+func (j *JWTService) JWKS() (*models.JSONWebKeySet, error) {
+	if j.keyErr != nil {
+		return nil, j.keyErr
 	}
+	return j.keys.jwks(), nil
 }
 
 // GenerateTokens creates a new pair of access and refresh tokens
@@ -44,9 +104,10 @@ func (j *JWTService) GenerateTokens(user *models.User) (*models.TokenResponse, e
 	// Create access token
 	accessTokenExpiry := time.Now().Add(j.config.AccessTokenTTL)
 	accessTokenClaims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Roles:  roles,
+		UserID:    user.ID,
+		Email:     user.Email,
+		Roles:     roles,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(accessTokenExpiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -54,11 +115,11 @@ func (j *JWTService) GenerateTokens(user *models.User) (*models.TokenResponse, e
 			Issuer:    j.config.Issuer,
 			Subject:   user.ID.String(),
 			Audience:  []string{j.config.Audience},
-			ID:        uuid.New().String(),
+			ID:        newTokenID(),
 		},
 	}
 
-	accessToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims).SignedString([]byte(j.config.Secret))
+	accessToken, err := j.sign(accessTokenClaims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create access token: %w", err)
 	}
@@ -66,8 +127,9 @@ func (j *JWTService) GenerateTokens(user *models.User) (*models.TokenResponse, e
 	// Create refresh token
 	refreshTokenExpiry := time.Now().Add(j.config.RefreshTokenTTL)
 	refreshTokenClaims := &Claims{
-		UserID: user.ID,
-		Email:  user.Email,
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(refreshTokenExpiry),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -75,11 +137,11 @@ func (j *JWTService) GenerateTokens(user *models.User) (*models.TokenResponse, e
 			Issuer:    j.config.Issuer,
 			Subject:   user.ID.String(),
 			Audience:  []string{j.config.Audience},
-			ID:        uuid.New().String(),
+			ID:        newTokenID(),
 		},
 	}
 
-	refreshToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims).SignedString([]byte(j.config.Secret))
+	refreshToken, err := j.sign(refreshTokenClaims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create refresh token: %w", err)
 	}
@@ -91,16 +153,44 @@ func (j *JWTService) GenerateTokens(user *models.User) (*models.TokenResponse, e
 	}, nil
 }
 
+// GenerateElevatedToken creates an access token carrying the elevated scope for an admin's
+// elevation, valid until the elevation expires. Elevated tokens cannot be refreshed.
+func (j *JWTService) GenerateElevatedToken(user *models.User, elevationID uuid.UUID, expiresAt time.Time) (string, error) {
+	roles := make([]string, len(user.Roles))
+	for i, role := range user.Roles {
+		roles[i] = role.Name
+	}
+
+	claims := &Claims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Roles:       roles,
+		TokenType:   TokenTypeAccess,
+		Scope:       ScopeElevated,
+		ElevationID: &elevationID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    j.config.Issuer,
+			Subject:   user.ID.String(),
+			Audience:  []string{j.config.Audience},
+			ID:        newTokenID(),
+		},
+	}
+
+	token, err := j.sign(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to create elevated access token: %w", err)
+	}
+	return token, nil
+}
+
 // ValidateToken validates a JWT token
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(j.config.Secret), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.verificationKey,
+		jwt.WithValidMethods([]string{JWTAlgorithmHS256, JWTAlgorithmRS256, JWTAlgorithmEdDSA}))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -120,6 +210,64 @@ func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// ValidateAccessToken validates a JWT and checks it is an access token. Tokens issued before token
+// types are rejected too, as access tokens cannot be told apart from refresh tokens; clients
+// holding one refresh it.
+func (j *JWTService) ValidateAccessToken(tokenString string) (*Claims, error) {
+	claims, err := j.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != TokenTypeAccess {
+		return nil, ErrNotAccessToken
+	}
+	return claims, nil
+}
+
+// sign signs claims with the current key, naming it in the kid header so tokens stay verifiable
+// after the key is rotated
+func (j *JWTService) sign(claims *Claims) (string, error) {
+	if j.keyErr != nil {
+		return "", j.keyErr
+	}
+	token := jwt.NewWithClaims(j.keys.signing.method, claims)
+	token.Header["kid"] = j.keys.signing.id
+	return token.SignedString(j.keys.signing.sign)
+}
+
+// verificationKey returns the key a token was signed with: the current key or one retired by
+// rotation. Tokens without a kid predate rotation support and use the current secret.
+func (j *JWTService) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.keyErr != nil {
+		return nil, j.keyErr
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if j.keys.signing.method != jwt.SigningMethodHS256 || token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return j.keys.signing.verify, nil
+	}
+
+	key, ok := j.keys.byID[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %s", kid)
+	}
+	// A key only verifies tokens of its own algorithm, so a published public key can never be
+	// used as an HMAC secret
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verify, nil
+}
+
+// JWTKeyID returns the key ID of a signing secret, a short fingerprint that does not reveal it
+func JWTKeyID(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:8])
+}
+
 // HashToken creates a secure hash of a token for database storage
 func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
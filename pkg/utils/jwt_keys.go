@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"event-ticketing-backend/internal/models"
+	"event-ticketing-backend/pkg/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Algorithms access and refresh tokens can be signed with
+const (
+	JWTAlgorithmHS256 = "HS256" // Shared secret; tokens can only be verified by this API
+	JWTAlgorithmRS256 = "RS256" // RSA key pair; public keys are published in the JWKS
+	JWTAlgorithmEdDSA = "EdDSA" // Ed25519 key pair; public keys are published in the JWKS
+)
+
+// jwtKey is a key tokens are signed or verified with
+type jwtKey struct {
+	id     string
+	method jwt.SigningMethod
+	sign   interface{} // Secret or private key; nil for keys retired by rotation
+	verify interface{} // Secret or public key
+}
+
+// jwtKeySet holds the key new tokens are signed with and every key tokens are still verified with
+type jwtKeySet struct {
+	signing *jwtKey
+	byID    map[string]*jwtKey
+	public  []*jwtKey // Key pairs, published in the JWKS; the signing key comes first
+}
+
+// loadJWTKeySet loads the signing key of the configured algorithm and the keys retired by
+// rotation. With RS256 or EdDSA, JWT_SECRET only verifies tokens when it is listed in
+// JWT_PREVIOUS_SECRETS, so switching algorithms keeps sessions open until they expire.
+func loadJWTKeySet(cfg *config.JWTConfig) (*jwtKeySet, error) {
+	set := &jwtKeySet{byID: make(map[string]*jwtKey)}
+
+	switch cfg.Algorithm {
+	case "", JWTAlgorithmHS256:
+		set.add(secretJWTKey(cfg.Secret))
+	case JWTAlgorithmRS256, JWTAlgorithmEdDSA:
+		if cfg.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE is required to sign tokens with %s", cfg.Algorithm)
+		}
+		signer, err := LoadPEMPrivateKey(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load JWT signing key: %w", err)
+		}
+		key, err := publicJWTKey(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		if key.method.Alg() != cfg.Algorithm {
+			return nil, fmt.Errorf("JWT signing key is not a %s key", cfg.Algorithm)
+		}
+		key.sign = signer
+		set.add(key)
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.Algorithm)
+	}
+
+	for _, secret := range strings.Split(cfg.PreviousSecrets, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			set.add(secretJWTKey(secret))
+		}
+	}
+
+	if cfg.PreviousKeysFile != "" {
+		publicKeys, err := LoadPEMPublicKeys(cfg.PreviousKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load retired JWT keys: %w", err)
+		}
+		for _, publicKey := range publicKeys {
+			key, err := publicJWTKey(publicKey)
+			if err != nil {
+				return nil, err
+			}
+			set.add(key)
+		}
+	}
+
+	return set, nil
+}
+
+// add adds a key to the set; the first key added signs new tokens
+func (s *jwtKeySet) add(key *jwtKey) {
+	if _, ok := s.byID[key.id]; ok {
+		return
+	}
+	if s.signing == nil {
+		s.signing = key
+	}
+	s.byID[key.id] = key
+	if _, ok := key.method.(*jwt.SigningMethodHMAC); !ok {
+		s.public = append(s.public, key)
+	}
+}
+
+// jwks returns the public keys of the set as a JWKS document. Secrets are never published.
+func (s *jwtKeySet) jwks() *models.JSONWebKeySet {
+	set := &models.JSONWebKeySet{Keys: make([]models.JSONWebKey, 0, len(s.public))}
+	for _, key := range s.public {
+		jwk := models.JSONWebKey{Kid: key.id, Use: "sig", Alg: key.method.Alg()}
+		switch publicKey := key.verify.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// secretJWTKey returns the HS256 key of a secret
+func secretJWTKey(secret string) *jwtKey {
+	return &jwtKey{
+		id:     JWTKeyID(secret),
+		method: jwt.SigningMethodHS256,
+		sign:   []byte(secret),
+		verify: []byte(secret),
+	}
+}
+
+// publicJWTKey returns the verification key of an RSA or Ed25519 public key
+func publicJWTKey(publicKey crypto.PublicKey) (*jwtKey, error) {
+	var method jwt.SigningMethod
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT key type: %T", publicKey)
+	}
+
+	id, err := JWTPublicKeyID(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &jwtKey{id: id, method: method, verify: publicKey}, nil
+}
+
+// JWTPublicKeyID returns the key ID of a public signing key, a short fingerprint of its
+// PKIX encoding
+func JWTPublicKeyID(publicKey crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode public key: %w", err)
+	}
+	hash := sha256.Sum256(der)
+	return hex.EncodeToString(hash[:8]), nil
+}
+
+// LoadPEMPublicKeys reads every public key of a PEM file. Certificates and private keys count for
+// their public key.
+func LoadPEMPublicKeys(path string) ([]crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKeys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "PUBLIC KEY":
+			publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			publicKeys = append(publicKeys, publicKey)
+		case "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			publicKeys = append(publicKeys, certificate.PublicKey)
+		case "RSA PRIVATE KEY", "EC PRIVATE KEY", "PRIVATE KEY":
+			signer, err := ParsePEMPrivateKey(pem.EncodeToMemory(block))
+			if err != nil {
+				return nil, err
+			}
+			publicKeys = append(publicKeys, signer.Public())
+		}
+	}
+
+	if len(publicKeys) == 0 {
+		return nil, errors.New("no public key found")
+	}
+	return publicKeys, nil
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidNewsletterToken is returned for a newsletter link that was not signed with the current key
+var ErrInvalidNewsletterToken = errors.New("Invalid newsletter link")
+
+// SignNewsletterToken returns the URL-safe token of a newsletter recipient, carried by the open,
+// click and unsubscribe links of their copy. It is the recipient ID with an HMAC of it.
+func SignNewsletterToken(secret string, recipientID uuid.UUID) string {
+	return base64.RawURLEncoding.EncodeToString(recipientID[:]) + "." +
+		base64.RawURLEncoding.EncodeToString(newsletterTokenMAC(secret, recipientID))
+}
+
+// ParseNewsletterToken returns the newsletter recipient a token produced by SignNewsletterToken is for
+func ParseNewsletterToken(secret, token string) (uuid.UUID, error) {
+	idPart, macPart, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidNewsletterToken
+	}
+
+	rawID, err := base64.RawURLEncoding.DecodeString(idPart)
+	if err != nil {
+		return uuid.Nil, ErrInvalidNewsletterToken
+	}
+	recipientID, err := uuid.FromBytes(rawID)
+	if err != nil {
+		return uuid.Nil, ErrInvalidNewsletterToken
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(macPart)
+	if err != nil || !hmac.Equal(mac, newsletterTokenMAC(secret, recipientID)) {
+		return uuid.Nil, ErrInvalidNewsletterToken
+	}
+	return recipientID, nil
+}
+
+func newsletterTokenMAC(secret string, recipientID uuid.UUID) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("newsletter:"))
+	mac.Write(recipientID[:])
+	return mac.Sum(nil)
+}
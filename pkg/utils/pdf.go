@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Standard page sizes in points
+const (
+	PDFA4Width  = 595.28
+	PDFA4Height = 841.89
+)
+
+// PDF builds simple PDF documents: text in the standard Helvetica fonts, filled rectangles and QR
+// codes. Coordinates are in points from the top left corner of the page.
+type PDF struct {
+	width  float64
+	height float64
+	pages  []*bytes.Buffer
+}
+
+// NewPDF creates a document whose pages have the given size in points
+func NewPDF(width, height float64) *PDF {
+	return &PDF{width: width, height: height}
+}
+
+// AddPage starts a new page; drawing goes to the last page added
+func (p *PDF) AddPage() {
+	p.pages = append(p.pages, &bytes.Buffer{})
+}
+
+// SetColor sets the color of the rectangles, QR codes and text drawn next, as RGB components
+// from 0 to 255
+func (p *PDF) SetColor(r, g, b int) {
+	fmt.Fprintf(p.page(), "%s %s %s rg\n", pdfNumber(float64(r)/255), pdfNumber(float64(g)/255), pdfNumber(float64(b)/255))
+}
+
+// Rect draws a filled rectangle
+func (p *PDF) Rect(x, y, width, height float64) {
+	fmt.Fprintf(p.page(), "%s %s %s %s re f\n", pdfNumber(x), pdfNumber(p.height-y-height), pdfNumber(width), pdfNumber(height))
+}
+
+// Text draws a line of text with its baseline at y. Characters outside Windows-1252 are drawn as
+// question marks.
+func (p *PDF) Text(x, y, size float64, bold bool, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(p.page(), "BT /%s %s Tf %s %s Td (%s) Tj ET\n", font, pdfNumber(size), pdfNumber(x), pdfNumber(p.height-y), pdfString(text))
+}
+
+// QRCode draws the dark modules of a QR code with its top left corner at x, y. Callers leave a
+// quiet zone of four modules around it.
+func (p *PDF) QRCode(x, y, moduleSize float64, code *QRCode) {
+	for row, modules := range code.Modules {
+		for col := 0; col < code.Size; col++ {
+			if !modules[col] {
+				continue
+			}
+			run := col
+			for run+1 < code.Size && modules[run+1] {
+				run++
+			}
+			p.Rect(x+float64(col)*moduleSize, y+float64(row)*moduleSize, float64(run-col+1)*moduleSize, moduleSize)
+			col = run
+		}
+	}
+}
+
+// Bytes returns the encoded document
+func (p *PDF) Bytes() ([]byte, error) {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+
+	var objects []string
+	kids := make([]string, len(p.pages))
+	for i := range p.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(p.pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	)
+	for i, page := range p.pages {
+		var content bytes.Buffer
+		w := zlib.NewWriter(&content)
+		if _, err := w.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfNumber(p.width), pdfNumber(p.height), 6+i*2),
+			fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	var doc bytes.Buffer
+	doc.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = doc.Len()
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return doc.Bytes(), nil
+}
+
+// page returns the content of the current page
+func (p *PDF) page() *bytes.Buffer {
+	if len(p.pages) == 0 {
+		p.AddPage()
+	}
+	return p.pages[len(p.pages)-1]
+}
+
+// WrapPDFText splits text into lines that fit a width at a font size, breaking between words. Widths
+// are estimated from the average Helvetica character, so lines are kept a little short.
+func WrapPDFText(text string, size, width float64) []string {
+	perLine := int(width / (size * 0.55))
+	if perLine < 1 {
+		perLine = 1
+	}
+
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(text) {
+		for len([]rune(word)) > perLine {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:perLine]))
+			word = string(runes[perLine:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case len([]rune(line))+1+len([]rune(word)) <= perLine:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfNumber formats a number with at most two decimals
+func pdfNumber(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
+
+// pdfString encodes text as the body of a literal string in WinAnsiEncoding
+func pdfString(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if unicode.IsControl(r) {
+			r = ' '
+		}
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		if c == '(' || c == ')' || c == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,452 @@
+package utils
+
+import (
+	"errors"
+)
+
+// ErrQRPayloadTooLong is returned for payloads that do not fit the largest supported QR version
+var ErrQRPayloadTooLong = errors.New("QR code payload is too long")
+
+// qrVersion describes the layout of a QR code version at error correction level M
+type qrVersion struct {
+	ecPerBlock int   // Error correction codewords of each block
+	blocks     []int // Data codewords of each block
+	alignment  []int // Row and column centers of the alignment patterns
+}
+
+// qrVersions are versions 1 to 10 at error correction level M, which hold up to 213 bytes and
+// survive about 15% of the code being damaged
+var qrVersions = []qrVersion{
+	{10, []int{16}, nil},
+	{16, []int{28}, []int{6, 18}},
+	{26, []int{44}, []int{6, 22}},
+	{18, []int{32, 32}, []int{6, 26}},
+	{24, []int{43, 43}, []int{6, 30}},
+	{16, []int{27, 27, 27, 27}, []int{6, 34}},
+	{18, []int{31, 31, 31, 31}, []int{6, 22, 38}},
+	{22, []int{38, 38, 39, 39}, []int{6, 24, 42}},
+	{22, []int{36, 36, 36, 37, 37}, []int{6, 26, 46}},
+	{26, []int{43, 43, 43, 43, 44}, []int{6, 28, 50}},
+}
+
+// QRCode is an encoded QR code; Modules[row][col] is true for dark modules. Renderers add a quiet
+// zone of four light modules around it.
+type QRCode struct {
+	Size    int
+	Modules [][]bool
+}
+
+// EncodeQR encodes a payload in byte mode at error correction level M, using the smallest version
+// it fits and the mask with the lowest penalty
+func EncodeQR(payload string) (*QRCode, error) {
+	data := []byte(payload)
+
+	number := 0
+	for i, v := range qrVersions {
+		capacity := 0
+		for _, n := range v.blocks {
+			capacity += n
+		}
+		countBits := 8
+		if i+1 >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+len(data)*8 <= capacity*8 {
+			number = i + 1
+			break
+		}
+	}
+	if number == 0 {
+		return nil, ErrQRPayloadTooLong
+	}
+
+	q := newQRMatrix(number)
+	q.drawFunctionPatterns()
+	q.drawCodewords(qrCodewords(number, data))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // Masks are XORs, so applying one again undoes it
+	}
+	q.applyMask(best)
+	q.drawFormatBits(best)
+
+	return &QRCode{Size: q.size, Modules: q.modules}, nil
+}
+
+// qrCodewords returns the data and error correction codewords of a payload, interleaved across
+// the version's blocks in transmission order
+func qrCodewords(number int, data []byte) []byte {
+	v := qrVersions[number-1]
+	capacity := 0
+	for _, n := range v.blocks {
+		capacity += n
+	}
+
+	var bits qrBitBuffer
+	bits.append(0x4, 4) // Byte mode
+	if number >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	bits.append(0, min(4, capacity*8-len(bits))) // Terminator
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity*8; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := bits.bytes()
+
+	generator := rsGenerator(v.ecPerBlock)
+	dataBlocks := make([][]byte, len(v.blocks))
+	ecBlocks := make([][]byte, len(v.blocks))
+	offset := 0
+	for i, n := range v.blocks {
+		dataBlocks[i] = codewords[offset : offset+n]
+		ecBlocks[i] = rsRemainder(dataBlocks[i], generator)
+		offset += n
+	}
+
+	result := make([]byte, 0, capacity+v.ecPerBlock*len(v.blocks))
+	for i := 0; i < v.blocks[len(v.blocks)-1]; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				result = append(result, block[i])
+			}
+		}
+	}
+	for i := 0; i < v.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			result = append(result, block[i])
+		}
+	}
+	return result
+}
+
+// qrBitBuffer accumulates bits most significant first
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(value, length int) {
+	for i := length - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b qrBitBuffer) bytes() []byte {
+	result := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			result[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return result
+}
+
+// gfMultiply multiplies in GF(256) with the QR code polynomial x^8 + x^4 + x^3 + x^2 + 1
+func gfMultiply(x, y byte) byte {
+	var z byte
+	for i := 7; i >= 0; i-- {
+		carry := z & 0x80
+		z <<= 1
+		if carry != 0 {
+			z ^= 0x1D
+		}
+		if (y>>i)&1 == 1 {
+			z ^= x
+		}
+	}
+	return z
+}
+
+// rsGenerator returns the coefficients of the Reed-Solomon generator polynomial of a degree, highest
+// power first with the leading 1 left out
+func rsGenerator(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+	return result
+}
+
+// rsRemainder returns the Reed-Solomon error correction codewords of data
+func rsRemainder(data, generator []byte) []byte {
+	result := make([]byte, len(generator))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coefficient := range generator {
+			result[i] ^= gfMultiply(coefficient, factor)
+		}
+	}
+	return result
+}
+
+// qrMatrix is a QR code being drawn; function modules hold the patterns that are not masked
+type qrMatrix struct {
+	version  int
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+func newQRMatrix(version int) *qrMatrix {
+	size := version*4 + 17
+	q := &qrMatrix{version: version, size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for i := range q.modules {
+		q.modules[i] = make([]bool, size)
+		q.function[i] = make([]bool, size)
+	}
+	return q
+}
+
+func (q *qrMatrix) setFunction(row, col int, dark bool) {
+	q.modules[row][col] = dark
+	q.function[row][col] = true
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns and reserves the format
+// and version areas
+func (q *qrMatrix) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinder(3, 3)
+	q.drawFinder(3, q.size-4)
+	q.drawFinder(q.size-4, 3)
+
+	centers := qrVersions[q.version-1].alignment
+	last := len(centers) - 1
+	for i, row := range centers {
+		for j, col := range centers {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // Overlaps a finder pattern
+			}
+			for dr := -2; dr <= 2; dr++ {
+				for dc := -2; dc <= 2; dc++ {
+					q.setFunction(row+dr, col+dc, max(abs(dr), abs(dc)) != 1)
+				}
+			}
+		}
+	}
+
+	q.drawFormatBits(0)
+	q.drawVersionBits()
+}
+
+// drawFinder draws a finder pattern and its separator around a center
+func (q *qrMatrix) drawFinder(row, col int) {
+	for dr := -4; dr <= 4; dr++ {
+		for dc := -4; dc <= 4; dc++ {
+			r, c := row+dr, col+dc
+			if r < 0 || r >= q.size || c < 0 || c >= q.size {
+				continue
+			}
+			distance := max(abs(dr), abs(dc))
+			q.setFunction(r, c, distance != 2 && distance != 4)
+		}
+	}
+}
+
+// drawFormatBits draws both copies of the format information of error correction level M and a
+// mask, and the dark module
+func (q *qrMatrix) drawFormatBits(mask int) {
+	data := mask // Level M is 00
+	remainder := data
+	for i := 0; i < 10; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 9) * 0x537)
+	}
+	bits := (data<<10 | remainder) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		q.setFunction(i, 8, bit(i))
+	}
+	q.setFunction(7, 8, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(8, 7, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(8, 14-i, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		q.setFunction(8, q.size-1-i, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(q.size-15+i, 8, bit(i))
+	}
+	q.setFunction(q.size-8, 8, true)
+}
+
+// drawVersionBits draws both copies of the version information of versions 7 and up
+func (q *qrMatrix) drawVersionBits() {
+	if q.version < 7 {
+		return
+	}
+	remainder := q.version
+	for i := 0; i < 12; i++ {
+		remainder = (remainder << 1) ^ ((remainder >> 11) * 0x1F25)
+	}
+	bits := q.version<<12 | remainder
+
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 == 1
+		a, b := q.size-11+i%3, i/3
+		q.setFunction(b, a, dark)
+		q.setFunction(a, b, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order of the standard, two columns at a time
+// from the bottom right, leaving remainder modules light
+func (q *qrMatrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skip the vertical timing pattern
+		}
+		upward := (right+1)&2 == 0
+		for vertical := 0; vertical < q.size; vertical++ {
+			row := vertical
+			if upward {
+				row = q.size - 1 - vertical
+			}
+			for j := 0; j < 2; j++ {
+				col := right - j
+				if q.function[row][col] {
+					continue
+				}
+				if i < len(codewords)*8 {
+					q.modules[row][col] = (codewords[i/8]>>(7-i%8))&1 == 1
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask flips the data modules selected by a mask pattern
+func (q *qrMatrix) applyMask(mask int) {
+	for row := 0; row < q.size; row++ {
+		for col := 0; col < q.size; col++ {
+			if q.function[row][col] {
+				continue
+			}
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (row+col)%2 == 0
+			case 1:
+				flip = row%2 == 0
+			case 2:
+				flip = col%3 == 0
+			case 3:
+				flip = (row+col)%3 == 0
+			case 4:
+				flip = (row/2+col/3)%2 == 0
+			case 5:
+				flip = row*col%2+row*col%3 == 0
+			case 6:
+				flip = (row*col%2+row*col%3)%2 == 0
+			case 7:
+				flip = ((row+col)%2+row*col%3)%2 == 0
+			}
+			if flip {
+				q.modules[row][col] = !q.modules[row][col]
+			}
+		}
+	}
+}
+
+// penalty scores how hard the code is to read, following the four rules of the standard: long
+// runs, 2x2 blocks, finder-like patterns and an unbalanced share of dark modules
+func (q *qrMatrix) penalty() int {
+	score := 0
+	dark := 0
+	finderLike := [][]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	line := make([]bool, q.size)
+	for direction := 0; direction < 2; direction++ {
+		for i := 0; i < q.size; i++ {
+			for j := 0; j < q.size; j++ {
+				if direction == 0 {
+					line[j] = q.modules[i][j]
+				} else {
+					line[j] = q.modules[j][i]
+				}
+			}
+
+			run := 1
+			for j := 1; j <= q.size; j++ {
+				if j < q.size && line[j] == line[j-1] {
+					run++
+					continue
+				}
+				if run >= 5 {
+					score += run - 2
+				}
+				run = 1
+			}
+
+			for j := 0; j+11 <= q.size; j++ {
+				for _, pattern := range finderLike {
+					matches := true
+					for k, module := range pattern {
+						if line[j+k] != module {
+							matches = false
+							break
+						}
+					}
+					if matches {
+						score += 40
+					}
+				}
+			}
+		}
+	}
+
+	for row := 0; row < q.size; row++ {
+		for col := 0; col < q.size; col++ {
+			module := q.modules[row][col]
+			if module {
+				dark++
+			}
+			if row+1 < q.size && col+1 < q.size &&
+				module == q.modules[row][col+1] && module == q.modules[row+1][col] && module == q.modules[row+1][col+1] {
+				score += 3
+			}
+		}
+	}
+
+	percent := dark * 100 / (q.size * q.size)
+	score += abs(percent-50) / 5 * 10
+	return score
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -1,9 +1,12 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"time"
 
+	"event-ticketing-backend/internal/models"
 	"event-ticketing-backend/internal/validators"
 
 	"github.com/gin-gonic/gin"
@@ -11,12 +14,13 @@ import (
 
 // Response represents the standard API response structure
 type Response struct {
-	Success   bool        `json:"success"`
-	Message   string      `json:"message"`
-	Data      interface{} `json:"data,omitempty"`
-	Error     *ErrorInfo  `json:"error,omitempty"`
-	Timestamp string      `json:"timestamp"`
-	RequestID string      `json:"request_id,omitempty"`
+	Success   bool             `json:"success"`
+	Message   string           `json:"message"`
+	Data      interface{}      `json:"data,omitempty"`
+	Meta      *models.PageMeta `json:"meta,omitempty"` // Pagination of list responses
+	Error     *ErrorInfo       `json:"error,omitempty"`
+	Timestamp string           `json:"timestamp"`
+	RequestID string           `json:"request_id,omitempty"`
 }
 
 // ErrorInfo provides detailed error information
@@ -37,6 +41,18 @@ func SuccessResponse(c *gin.Context, statusCode int, message string, data interf
 	})
 }
 
+// PaginatedResponse sends one page of a list with its pagination metadata
+func PaginatedResponse(c *gin.Context, message string, data interface{}, meta *models.PageMeta) {
+	c.JSON(http.StatusOK, Response{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		Meta:      meta,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		RequestID: getRequestID(c),
+	})
+}
+
 // ErrorResponse sends a generic error response
 func ErrorResponse(c *gin.Context, statusCode int, message string, err error) {
 	errorInfo := &ErrorInfo{
@@ -111,6 +127,24 @@ func BadRequestErrorResponse(c *gin.Context, message string, err error) {
 	})
 }
 
+// InvalidIDErrorResponse sends a bad request error response for a path parameter that is not a
+// valid UUID
+func InvalidIDErrorResponse(c *gin.Context, param string) {
+	errorInfo := &ErrorInfo{
+		Code:    "INVALID_ID",
+		Details: param + " must be a valid UUID",
+		Fields:  map[string]interface{}{param: "must be a valid UUID"},
+	}
+
+	c.JSON(http.StatusBadRequest, Response{
+		Success:   false,
+		Message:   "Invalid ID",
+		Error:     errorInfo,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		RequestID: getRequestID(c),
+	})
+}
+
 // UnauthorizedErrorResponse sends an unauthorized error response
 func UnauthorizedErrorResponse(c *gin.Context, message string, err error) {
 	errorInfo := &ErrorInfo{
@@ -193,6 +227,11 @@ func ConflictErrorResponse(c *gin.Context, message string, err error) {
 
 // InternalServerErrorResponse sends an internal server error response
 func InternalServerErrorResponse(c *gin.Context, message string, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		HandleAppError(c, NewTimeoutError("Request"))
+		return
+	}
+
 	errorInfo := &ErrorInfo{
 		Code:    "INTERNAL_SERVER_ERROR",
 		Details: "An unexpected error occurred on the server",
@@ -231,6 +270,11 @@ func ValidationErrorWithFieldsResponse(c *gin.Context, message string, fields in
 
 // DatabaseErrorResponse sends a database error response
 func DatabaseErrorResponse(c *gin.Context, message string, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		HandleAppError(c, NewTimeoutError("Request"))
+		return
+	}
+
 	errorInfo := &ErrorInfo{
 		Code:    "DATABASE_ERROR",
 		Details: "Database operation failed",